@@ -4,7 +4,6 @@ package agent
 import (
 	"context"
 	_ "embed"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -37,6 +36,9 @@ type OutputMessages interface {
 
 var (
 	ErrParsingResponse = errors.New("error parsing response")
+	// ErrMaxIterations is returned by Query when the model still requests
+	// tools after the session's maximum number of LLM round-trips.
+	ErrMaxIterations = errors.New("agent exceeded max iterations")
 )
 
 type Session struct {
@@ -47,6 +49,17 @@ type Session struct {
 	returnOnlyLastMessage bool
 	customInstructions    string
 	llmResponses          []LLMResponse
+	maxIterations         int
+	// store, when set, holds the conversation; storeLoaded records that it
+	// has been read into messages.
+	store       ConversationStore
+	storeLoaded bool
+	// loadLoggedConversation reads the conversation from the local log
+	// storage when no store is set.
+	loadLoggedConversation func() error
+	// queryStart is the index in llmResponses of the latest Query's first
+	// response.
+	queryStart int
 }
 
 type Option func(*Session) error
@@ -58,40 +71,41 @@ func WithToolManager(toolManager ToolManager) Option {
 	}
 }
 
+// WithConversationID continues the conversation with the given id. Its
+// messages come from the session's ConversationStore, or from the local log
+// storage when the session has none.
 func WithConversationID(ctx context.Context, id string) Option {
 	return func(a *Session) error {
 		if id == "" {
 			return fmt.Errorf("conversationID can not be empty")
 		}
 		a.conversationID = id
-		messages, err := storage.GetLogEntriesByPrefix(conversationStoragePrefix+id, func(data []byte) (any, error) {
-			var msg Message
-			err := json.Unmarshal(data, &msg)
+		a.loadLoggedConversation = func() error {
+			messages, err := storage.GetLogEntriesByPrefix(conversationStoragePrefix+id, func(data []byte) (any, error) {
+				msg, err := decodeMessage(data)
+				if err == nil && msg == nil {
+					logging.FromContext(ctx).Warn("invalid type in log storage")
+				}
+				return msg, err
+			})
 			if err != nil {
-				return nil, err
-			}
-			switch msg.Type {
-			case MessageTypeText:
-				var contentMessage MessageTypeContent
-				err = json.Unmarshal(data, &contentMessage)
-				return contentMessage, err
-			case MessageTypeToolResponse:
-				var toolResponse MessageToolCallResponse
-				err = json.Unmarshal(data, &toolResponse)
-				return toolResponse, err
-			case MessageTypeToolCall:
-				var toolCall MessageToolCall
-				err = json.Unmarshal(data, &toolCall)
-				return toolCall, err
-			default:
-				logging.FromContext(ctx).Warn("invalid type in log storage", zap.Int("type", int(msg.Type)))
+				return err
 			}
-			return nil, nil
-		})
-		if err != nil {
-			return err
+			a.messages = append(a.messages, messages...)
+			return nil
 		}
-		a.messages = append(a.messages, messages...)
+		return nil
+	}
+}
+
+// WithConversationStore keeps the conversation named by WithConversationID in
+// store instead of the local log storage.
+func WithConversationStore(store ConversationStore) Option {
+	return func(a *Session) error {
+		if store == nil {
+			return fmt.Errorf("conversation store can not be nil")
+		}
+		a.store = store
 		return nil
 	}
 }
@@ -103,6 +117,18 @@ func WithReturnOnlyLastMessage() Option {
 	}
 }
 
+// WithMaxIterations caps the number of LLM round-trips a Query makes. Defaults
+// to defaultMaxIterations.
+func WithMaxIterations(n int) Option {
+	return func(a *Session) error {
+		if n < 1 {
+			return fmt.Errorf("max iterations must be at least 1, got %d", n)
+		}
+		a.maxIterations = n
+		return nil
+	}
+}
+
 func WithInstructions(instructions string) Option {
 	return func(a *Session) error {
 		a.customInstructions = instructions
@@ -112,9 +138,10 @@ func WithInstructions(instructions string) Option {
 
 func NewSession(developerInstructions string, llm LLmProvider, options ...Option) (*Session, error) {
 	agent := &Session{
-		llm:          llm,
-		messages:     make([]any, 0),
-		llmResponses: make([]LLMResponse, 0),
+		llm:           llm,
+		messages:      make([]any, 0),
+		llmResponses:  make([]LLMResponse, 0),
+		maxIterations: defaultMaxIterations,
 	}
 	agent.customInstructions = developerInstructions
 
@@ -123,6 +150,11 @@ func NewSession(developerInstructions string, llm LLmProvider, options ...Option
 			return nil, err
 		}
 	}
+	if agent.store == nil && agent.loadLoggedConversation != nil {
+		if err := agent.loadLoggedConversation(); err != nil {
+			return nil, err
+		}
+	}
 
 	return agent, nil
 }
@@ -134,6 +166,10 @@ type agentOutput struct {
 
 func (a *Session) Query(ctx context.Context, query string, file *requestctx.FileValue) (string, error) {
 	logger := logging.WithContextEnriched(ctx).With(zap.String("module", "agent"))
+	a.queryStart = len(a.llmResponses)
+	if err := a.loadConversation(ctx); err != nil {
+		return "", err
+	}
 	if query != "" || file != nil {
 		a.addToMessages(logger, MessageTypeContent{
 			Message:     Message{Type: MessageTypeText},
@@ -150,6 +186,10 @@ func (a *Session) Query(ctx context.Context, query string, file *requestctx.File
 	respChan := a.startLoop(ctx)
 	for r := range respChan {
 		if r.err != nil {
+			if errors.Is(r.err, ErrMaxIterations) && a.returnOnlyLastMessage && lastMessage != "" {
+				logger.Warn("returning last message of agent that exceeded max iterations", zap.Error(r.err))
+				return lastMessage, nil
+			}
 			return "", r.err
 		}
 		if a.returnOnlyLastMessage {
@@ -159,6 +199,9 @@ func (a *Session) Query(ctx context.Context, query string, file *requestctx.File
 			strBuilder.WriteString("\n")
 		}
 	}
+	if err := a.saveConversation(ctx); err != nil {
+		return "", err
+	}
 	if a.returnOnlyLastMessage {
 		return lastMessage, nil
 	} else {
@@ -166,23 +209,56 @@ func (a *Session) Query(ctx context.Context, query string, file *requestctx.File
 	}
 }
 
+// loadConversation reads the conversation from the store into the session's
+// messages, once per session.
+func (a *Session) loadConversation(ctx context.Context) error {
+	if a.store == nil || a.conversationID == "" || a.storeLoaded {
+		return nil
+	}
+	messages, err := a.store.Load(ctx, a.conversationID)
+	if err != nil {
+		return err
+	}
+	a.messages = append(messages, a.messages...)
+	a.storeLoaded = true
+	return nil
+}
+
+func (a *Session) saveConversation(ctx context.Context) error {
+	if a.store == nil || a.conversationID == "" {
+		return nil
+	}
+	return a.store.Save(ctx, a.conversationID, a.messages)
+}
+
 // GetMetadata returns the metadata collected during the session
 func (a *Session) GetMetadata() SessionMetadata {
-	var total Usage
-	for _, r := range a.llmResponses {
-		total = total.Add(r.Usage)
-	}
 	return SessionMetadata{
 		LLMResponses: a.llmResponses,
-		TotalUsage:   total,
+		TotalUsage:   sumUsage(a.llmResponses),
 	}
 }
 
-// maxAgentIterations bounds the agent's tool-calling loop so a model that keeps
-// calling tools (e.g. repeatedly requesting non-existent files) cannot run
-// unbounded. On the final permitted iteration the tools are withheld so the
-// model must produce a text answer from what it already has.
-const maxAgentIterations = 40
+// LastUsage returns the tokens used by the latest Query, summed over all of
+// its llm calls, including those of a Query that failed.
+func (a *Session) LastUsage() Usage {
+	return sumUsage(a.llmResponses[a.queryStart:])
+}
+
+func sumUsage(responses []LLMResponse) Usage {
+	var total Usage
+	for _, r := range responses {
+		total = total.Add(r.Usage)
+	}
+	return total
+}
+
+// defaultMaxIterations bounds the agent's tool-calling loop so a model that
+// keeps calling tools (e.g. repeatedly requesting non-existent files) cannot
+// run unbounded. On the final permitted iteration the tools are withheld so the
+// model must produce a text answer from what it already has; if it requests
+// tools anyway the Query fails with ErrMaxIterations.
+const defaultMaxIterations = 40
 
 func (a *Session) startLoop(ctx context.Context) chan agentOutput {
 	logger := logging.FromContext(ctx).With(zap.String("module", "agent"))
@@ -198,11 +274,11 @@ func (a *Session) startLoop(ctx context.Context) chan agentOutput {
 			// On the final permitted iteration, withhold tools so the model has to
 			// answer from what it already gathered rather than calling more tools.
 			reqTools := toolList
-			forceFinish := iterations >= maxAgentIterations
+			forceFinish := iterations >= a.maxIterations
 			if forceFinish {
 				reqTools = nil
 				logger.Warn("agent reached max iterations; forcing a final response without tools",
-					zap.Int("max_iterations", maxAgentIterations))
+					zap.Int("max_iterations", a.maxIterations))
 			}
 			r, err := a.llm.ProvideResponse(ctx, LLMRequest{
 				Tools:         reqTools,
@@ -228,10 +304,14 @@ func (a *Session) startLoop(ctx context.Context) chan agentOutput {
 				}, out)
 			}
 
-			if forceFinish || len(r.Tools) == 0 {
+			if len(r.Tools) == 0 {
 				endTurn = true
 				continue
 			}
+			if forceFinish {
+				out <- agentOutput{err: fmt.Errorf("%w: still requesting tools after %d llm calls", ErrMaxIterations, iterations)}
+				break
+			}
 
 			for _, tool := range r.Tools {
 				a.addToMessages(logger, MessageToolCall{
@@ -304,7 +384,7 @@ func createToolResponseFromMCPContent(callID string, contentList []mcp.Content)
 
 func (a *Session) addToMessages(logger *zap.Logger, message any, output chan agentOutput) {
 	storageKey := ""
-	if a.conversationID != "" {
+	if a.conversationID != "" && a.store == nil {
 		storageKey = conversationStoragePrefix + a.conversationID
 	}
 
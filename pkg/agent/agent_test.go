@@ -626,6 +626,65 @@ func TestSession_WithReturnOnlyLastMessage(t *testing.T) {
 	assert.Contains(t, response2, "The weather is sunny today")
 }
 
+func TestSession_MaxIterations(t *testing.T) {
+	loopingResponse := LLMResponse{
+		Content: []ContentResponse{{Text: "checking again"}},
+		Tools: []ToolResponseObject{
+			{Name: "get_weather", Input: map[string]any{"location": "default"}, ToolID: "loop"},
+		},
+	}
+
+	newSession := func(t *testing.T, options ...Option) (*Session, *MockLLmProvider) {
+		ctrl := gomock.NewController(t)
+		mockToolManager := NewMockToolManager(ctrl)
+		mockLLmHandler := NewMockLLmProvider(ctrl)
+
+		var toolInfoList []ToolInfo
+		require.NoError(t, json.Unmarshal([]byte(toolList), &toolInfoList))
+		mockToolManager.EXPECT().ToolList(gomock.Any()).Return(toolInfoList)
+		mockToolManager.EXPECT().CallTool(gomock.Any(), "get_weather", gomock.Any()).
+			Return([]mcp.Content{mcp.TextContent{Type: "text", Text: "Weather: Sunny"}}, nil).Times(2)
+
+		session, err := NewSession("You are a helpful assistant", mockLLmHandler,
+			append([]Option{WithToolManager(mockToolManager), WithMaxIterations(3)}, options...)...)
+		require.NoError(t, err)
+		return session, mockLLmHandler
+	}
+
+	t.Run("model that always requests tools", func(t *testing.T) {
+		session, llm := newSession(t)
+		var requests []LLMRequest
+		llm.EXPECT().ProvideResponse(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req LLMRequest) (LLMResponse, error) {
+				requests = append(requests, req)
+				return loopingResponse, nil
+			}).Times(3)
+
+		result, err := session.Query(context.Background(), "weather?", nil)
+		require.ErrorIs(t, err, ErrMaxIterations)
+		assert.Contains(t, err.Error(), "after 3 llm calls")
+		assert.Empty(t, result)
+
+		require.Len(t, requests, 3)
+		assert.NotEmpty(t, requests[1].Tools)
+		assert.Empty(t, requests[2].Tools, "tools are withheld on the final iteration")
+	})
+
+	t.Run("returns last message", func(t *testing.T) {
+		session, llm := newSession(t, WithReturnOnlyLastMessage())
+		llm.EXPECT().ProvideResponse(gomock.Any(), gomock.Any()).Return(loopingResponse, nil).Times(3)
+
+		result, err := session.Query(context.Background(), "weather?", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "checking again", result)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, err := NewSession("", nil, WithMaxIterations(0))
+		assert.Error(t, err)
+	})
+}
+
 func TestCreateToolResponseFromMCPContent(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -834,3 +893,45 @@ func TestSession_GetMetadata(t *testing.T) {
 	assert.Equal(t, firstResponse, metadata.LLMResponses[0])
 	assert.Equal(t, secondResponse, metadata.LLMResponses[1])
 }
+
+func TestSession_LastUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockLLmHandler := NewMockLLmProvider(ctrl)
+	mockToolManager := NewMockToolManager(ctrl)
+
+	var toolInfoList []ToolInfo
+	require.NoError(t, json.Unmarshal([]byte(toolList), &toolInfoList))
+	mockToolManager.EXPECT().ToolList(gomock.Any()).Return(toolInfoList).Times(2)
+	mockToolManager.EXPECT().
+		CallTool(gomock.Any(), "get_weather", map[string]any{"location": "lagos"}).
+		Return([]mcp.Content{mcp.TextContent{Type: "text", Text: "Sunny, 28C"}}, nil)
+
+	gomock.InOrder(
+		mockLLmHandler.EXPECT().ProvideResponse(gomock.Any(), gomock.Any()).Return(LLMResponse{
+			Tools: []ToolResponseObject{{Name: "get_weather", Input: map[string]any{"location": "lagos"}, ToolID: "tool-1"}},
+			Usage: Usage{InputTokens: 100, OutputTokens: 20, TotalTokens: 120},
+		}, nil),
+		mockLLmHandler.EXPECT().ProvideResponse(gomock.Any(), gomock.Any()).Return(LLMResponse{
+			Content: []ContentResponse{{Text: "The weather is sunny"}},
+			Usage:   Usage{InputTokens: 150, OutputTokens: 10, TotalTokens: 160},
+		}, nil),
+		mockLLmHandler.EXPECT().ProvideResponse(gomock.Any(), gomock.Any()).Return(LLMResponse{
+			Content: []ContentResponse{{Text: "You're welcome"}},
+			Usage:   Usage{InputTokens: 170, OutputTokens: 5, TotalTokens: 175},
+		}, nil),
+	)
+
+	session, err := NewSession("Test system", mockLLmHandler, WithToolManager(mockToolManager))
+	require.NoError(t, err)
+	assert.Equal(t, Usage{}, session.LastUsage())
+
+	_, err = session.Query(context.Background(), "What's the weather?", nil)
+	require.NoError(t, err)
+	assert.Equal(t, Usage{InputTokens: 250, OutputTokens: 30, TotalTokens: 280}, session.LastUsage())
+
+	_, err = session.Query(context.Background(), "Thanks", nil)
+	require.NoError(t, err)
+	assert.Equal(t, Usage{InputTokens: 170, OutputTokens: 5, TotalTokens: 175}, session.LastUsage())
+	assert.Equal(t, Usage{InputTokens: 420, OutputTokens: 35, TotalTokens: 455}, session.GetMetadata().TotalUsage)
+}
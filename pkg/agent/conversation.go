@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+)
+
+// ConversationStore keeps the messages of a conversation between sessions.
+// A session given one with WithConversationStore loads the conversation when
+// a Query starts and saves all of it when the Query completes.
+type ConversationStore interface {
+	Load(ctx context.Context, conversationID string) ([]any, error)
+	Save(ctx context.Context, conversationID string, messages []any) error
+}
+
+// MemoryConversationStore is a ConversationStore that lives as long as the
+// process, e.g. for tests or a single-instance deployment.
+type MemoryConversationStore struct {
+	mu            sync.Mutex
+	conversations map[string][]any
+}
+
+func NewMemoryConversationStore() *MemoryConversationStore {
+	return &MemoryConversationStore{conversations: make(map[string][]any)}
+}
+
+func (m *MemoryConversationStore) Load(_ context.Context, conversationID string) ([]any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]any(nil), m.conversations[conversationID]...), nil
+}
+
+func (m *MemoryConversationStore) Save(_ context.Context, conversationID string, messages []any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conversations[conversationID] = append([]any(nil), messages...)
+	return nil
+}
+
+const (
+	conversationIDColumn       = "conversation_id"
+	conversationMessagesColumn = "messages"
+)
+
+// ConversationTable is the part of a datasource integration, such as sql or
+// mongo, that SQLConversationStore needs.
+type ConversationTable interface {
+	Fetch(ctx context.Context, options map[string]string, filters ...filters.Filter) ([]map[string]interface{}, error)
+	Store(ctx context.Context, item map[string]interface{}, options map[string]string) error
+}
+
+// SQLConversationStore keeps conversations in a table of a datasource
+// integration, one row per conversation: conversation_id, the key, and
+// messages, the JSON-encoded message list. Saving replaces the row through
+// the integration's upsertOn option.
+type SQLConversationStore struct {
+	db    ConversationTable
+	table string
+}
+
+func NewSQLConversationStore(db ConversationTable, table string) (*SQLConversationStore, error) {
+	if db == nil {
+		return nil, errors.New("conversation store requires an integration")
+	}
+	if table == "" {
+		return nil, errors.New("conversation store requires a table")
+	}
+	return &SQLConversationStore{db: db, table: table}, nil
+}
+
+func (s *SQLConversationStore) Load(ctx context.Context, conversationID string) ([]any, error) {
+	rows, err := s.db.Fetch(ctx, map[string]string{"table": s.table, filters.LimitOption: "1"},
+		filters.Filter{Field: conversationIDColumn, Operation: filters.Equals, Comparator: conversationID})
+	if err != nil {
+		return nil, fmt.Errorf("error loading conversation: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	var raw []byte
+	switch v := rows[0][conversationMessagesColumn].(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return nil, fmt.Errorf("conversation %s has messages of type %T", conversationID, v)
+	}
+	return decodeMessages(raw)
+}
+
+func (s *SQLConversationStore) Save(ctx context.Context, conversationID string, messages []any) error {
+	raw, err := json.Marshal(messages)
+	if err != nil {
+		return fmt.Errorf("error encoding conversation: %w", err)
+	}
+	item := map[string]interface{}{
+		conversationIDColumn:       conversationID,
+		conversationMessagesColumn: string(raw),
+	}
+	if err := s.db.Store(ctx, item, map[string]string{"table": s.table, filters.UpsertOnOption: conversationIDColumn}); err != nil {
+		return fmt.Errorf("error saving conversation: %w", err)
+	}
+	return nil
+}
+
+// decodeMessages reverses json.Marshal of a message list, restoring each
+// message to its type.
+func decodeMessages(raw []byte) ([]any, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, fmt.Errorf("error decoding conversation: %w", err)
+	}
+	messages := make([]any, 0, len(items))
+	for _, item := range items {
+		msg, err := decodeMessage(item)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding conversation: %w", err)
+		}
+		if msg != nil {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+// decodeMessage restores one stored message to its type. It returns nil for
+// a message of unknown type.
+func decodeMessage(data []byte) (any, error) {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	switch msg.Type {
+	case MessageTypeText:
+		var contentMessage MessageTypeContent
+		err := json.Unmarshal(data, &contentMessage)
+		return contentMessage, err
+	case MessageTypeToolResponse:
+		var toolResponse MessageToolCallResponse
+		err := json.Unmarshal(data, &toolResponse)
+		return toolResponse, err
+	case MessageTypeToolCall:
+		var toolCall MessageToolCall
+		err := json.Unmarshal(data, &toolCall)
+		return toolCall, err
+	}
+	return nil, nil
+}
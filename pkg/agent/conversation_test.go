@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeTable keeps rows keyed by their upsertOn column, as a datasource
+// integration would.
+type fakeTable struct {
+	rows map[string]map[string]interface{}
+}
+
+func (f *fakeTable) Fetch(_ context.Context, options map[string]string, fs ...filters.Filter) ([]map[string]interface{}, error) {
+	if row, ok := f.rows[options["table"]+":"+fs[0].Comparator.(string)]; ok {
+		return []map[string]interface{}{row}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeTable) Store(_ context.Context, item map[string]interface{}, options map[string]string) error {
+	key := options[filters.UpsertOnOption]
+	f.rows[options["table"]+":"+item[key].(string)] = item
+	return nil
+}
+
+func TestSession_ConversationStore(t *testing.T) {
+	stores := map[string]func(t *testing.T) ConversationStore{
+		"memory": func(t *testing.T) ConversationStore {
+			return NewMemoryConversationStore()
+		},
+		"sql": func(t *testing.T) ConversationStore {
+			store, err := NewSQLConversationStore(&fakeTable{rows: map[string]map[string]interface{}{}}, "conversations")
+			require.NoError(t, err)
+			return store
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := newStore(t)
+
+			var toolInfoList []ToolInfo
+			require.NoError(t, json.Unmarshal([]byte(toolList), &toolInfoList))
+			toolManager := NewMockToolManager(ctrl)
+			toolManager.EXPECT().ToolList(gomock.Any()).Return(toolInfoList).Times(2)
+			toolManager.EXPECT().CallTool(gomock.Any(), "get_weather", map[string]any{"location": "Lagos"}).
+				Return([]mcp.Content{mcp.TextContent{Type: "text", Text: "Sunny"}}, nil)
+
+			llm := NewMockLLmProvider(ctrl)
+			var secondRequest LLMRequest
+			gomock.InOrder(
+				llm.EXPECT().ProvideResponse(gomock.Any(), gomock.Any()).Return(LLMResponse{
+					Tools: []ToolResponseObject{{Name: "get_weather", ToolID: "call_1", Input: map[string]any{"location": "Lagos"}}},
+				}, nil),
+				llm.EXPECT().ProvideResponse(gomock.Any(), gomock.Any()).Return(LLMResponse{
+					Content: []ContentResponse{{Text: "It is sunny in Lagos"}},
+				}, nil),
+				llm.EXPECT().ProvideResponse(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, req LLMRequest) (LLMResponse, error) {
+						secondRequest = req
+						return LLMResponse{Content: []ContentResponse{{Text: "You asked about Lagos"}}}, nil
+					}),
+			)
+
+			first, err := NewSession("", llm, WithToolManager(toolManager),
+				WithConversationID(context.Background(), "conv-1"), WithConversationStore(store))
+			require.NoError(t, err)
+			_, err = first.Query(context.Background(), "Weather in Lagos?", nil)
+			require.NoError(t, err)
+
+			second, err := NewSession("", llm, WithToolManager(toolManager),
+				WithConversationStore(store), WithConversationID(context.Background(), "conv-1"))
+			require.NoError(t, err)
+			resp, err := second.Query(context.Background(), "Which city did I ask about?", nil)
+			require.NoError(t, err)
+			assert.Equal(t, "You asked about Lagos\n", resp)
+
+			assert.Equal(t, []any{
+				MessageTypeContent{Message: Message{Type: MessageTypeText}, Role: RoleTypeUser, Content: "Weather in Lagos?"},
+				MessageToolCall{Message: Message{Type: MessageTypeToolCall}, ID: "call_1", Name: "get_weather", Arguments: map[string]any{"location": "Lagos"}},
+				MessageToolCallResponse{Message: Message{Type: MessageTypeToolResponse}, ToolResponseType: ToolResponseTypeText, ID: "call_1", Text: "Sunny"},
+				MessageTypeContent{Message: Message{Type: MessageTypeText}, Role: RoleTypeAssistant, Content: "It is sunny in Lagos"},
+				MessageTypeContent{Message: Message{Type: MessageTypeText}, Role: RoleTypeUser, Content: "Which city did I ask about?"},
+			}, secondRequest.Messages)
+
+			saved, err := store.Load(context.Background(), "conv-1")
+			require.NoError(t, err)
+			assert.Len(t, saved, 6)
+
+			other, err := store.Load(context.Background(), "conv-2")
+			require.NoError(t, err)
+			assert.Empty(t, other)
+		})
+	}
+}
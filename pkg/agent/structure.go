@@ -205,6 +205,26 @@ type ContentResponse struct {
 	Text string `json:"text"`
 }
 
+// StreamChunk is one increment of a streamed LLM response. Text and ToolCall
+// carry deltas as they arrive. The last chunk either has Err set or has Done
+// set and holds the complete Response, with streamed tool calls reassembled.
+type StreamChunk struct {
+	Text     string
+	ToolCall *ToolCallDelta
+	Done     bool
+	Response *LLMResponse
+	Err      error
+}
+
+// ToolCallDelta is a fragment of a streamed tool call. The first fragment of
+// a call carries its ToolID and Name, later ones the next part of its JSON
+// arguments.
+type ToolCallDelta struct {
+	ToolID    string
+	Name      string
+	Arguments string
+}
+
 // SessionMetadata contains metadata collected during an agent session
 type SessionMetadata struct {
 	LLMResponses []LLMResponse `json:"llmResponses"`
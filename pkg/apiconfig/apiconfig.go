@@ -28,9 +28,18 @@ type APIConfig struct {
 	Actions      map[string]Action            `json:"actions,omitempty" yaml:"actions,omitempty"`
 	Conditionals map[string]Conditional       `json:"conditionals,omitempty" yaml:"conditionals,omitempty"`
 	Responses    map[string]ResponseConfig    `json:"responses,omitempty" yaml:"responses,omitempty"`
+	Joins        map[string]Join              `json:"joins,omitempty" yaml:"joins,omitempty"`
 	HttpConfig   HttpConfig                   `json:"http" yaml:"http"`
 	McpTool      MCPToolConfig                `json:"mcpTool" yaml:"mcpTool"`
 	Integrations map[string]IntegrationConfig `json:"integrations,omitempty" yaml:"integrations,omitempty"`
+	// ValidationResponse is written when a conditional without an onFalse
+	// fails with validation errors. It overrides the engine's default, a 422
+	// listing the errors.
+	ValidationResponse *ResponseConfig `json:"validationResponse,omitempty" yaml:"validationResponse,omitempty"`
+	// DefaultFail is the step, e.g. "response.error", that an action without
+	// a fail routes to when it fails with any error, with the error under
+	// {{ .error }}. Empty ends such requests with a 500 as before.
+	DefaultFail string `json:"defaultFail,omitempty" yaml:"defaultFail,omitempty"`
 }
 
 func (a *APIConfig) IsMCPConfig() bool {
@@ -42,6 +51,10 @@ type HttpConfig struct {
 	Method             string   `json:"method" yaml:"method"`
 	Next               string   `json:"next" yaml:"next"`
 	CORSAllowedOrigins []string `json:"corsAllowedOrigins,omitempty" yaml:"corsAllowedOrigins,omitempty"`
+	// Host optionally restricts the route to requests for a matching Host
+	// header. It may contain {name} segments, e.g. "{tenant}.example.com";
+	// captured values are available through urlparam like path params.
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
 	// Handler names a registered HTTP entry handler (see pkg/engine/entryhandlers).
 	// When set, its middleware wraps the request after the standard request
 	// prerequisites and before the workflow plan runs. Empty means no handler.
@@ -50,6 +63,49 @@ type HttpConfig struct {
 	// templates (e.g. {"secret": "{{ secret \"github\" }}"}) which the handler
 	// resolves at request time.
 	HandlerConfig map[string]interface{} `json:"handlerConfig,omitempty" yaml:"handlerConfig,omitempty"`
+	// Shadow mirrors requests to another config, e.g. a new version of this
+	// flow, without affecting the response the client gets.
+	Shadow *ShadowConfig `json:"shadow,omitempty" yaml:"shadow,omitempty"`
+	// RequestSchema is the JSON schema of the request body, published in the
+	// engine's OpenAPI document. It is not enforced.
+	RequestSchema map[string]interface{} `json:"requestSchema,omitempty" yaml:"requestSchema,omitempty"`
+	// Timeouts bounds the time the endpoint spends on a request.
+	Timeouts *TimeoutsConfig `json:"timeouts,omitempty" yaml:"timeouts,omitempty"`
+	// BufferBody keeps the request body in memory so several actions can
+	// read it (requestctx.GetRawBody). Off by default, leaving the body to
+	// stream to the action that reads it.
+	BufferBody bool `json:"bufferBody,omitempty" yaml:"bufferBody,omitempty"`
+	// MaxBodySize caps the buffered body in bytes; larger requests are
+	// answered with 413. Zero uses requestctx.DefaultMaxBodySize. It only
+	// applies with BufferBody.
+	MaxBodySize int64 `json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+}
+
+// TimeoutsConfig holds an endpoint's timeouts as durations, e.g. "2s".
+type TimeoutsConfig struct {
+	// Connect bounds connecting to upstreams, TLS handshake included, for the
+	// endpoint's http actions that set no connectTimeout of their own. A
+	// connect timeout is answered with 408.
+	Connect string `json:"connect,omitempty" yaml:"connect,omitempty"`
+	// Total bounds running the whole flow. Exceeding it, or an action's own
+	// timeout, is answered with 504.
+	Total string `json:"total,omitempty" yaml:"total,omitempty"`
+}
+
+// ShadowConfig sends a copy of a config's traffic to a secondary config. The
+// secondary runs after the primary has responded; its response is compared
+// with the primary's and logged, then discarded. Its actions still run, so a
+// secondary with actions that have side effects, e.g. save or email, is only
+// shadowed when AllowSideEffects is set.
+type ShadowConfig struct {
+	// Config is the id of the config that receives the copies.
+	Config string `json:"config" yaml:"config"`
+	// Percentage of requests (0-100) that are mirrored. Unset mirrors all.
+	Percentage float64 `json:"percentage,omitempty" yaml:"percentage,omitempty"`
+	// AllowSideEffects shadows a secondary with actions that have side
+	// effects. Each mirrored request runs them again, so the secondary should
+	// use its own integrations.
+	AllowSideEffects bool `json:"allowSideEffects,omitempty" yaml:"allowSideEffects,omitempty"`
 }
 
 type McpConfig struct {
@@ -87,6 +143,39 @@ type Action struct {
 	Fail       string                 `json:"fail" yaml:"fail"`
 	UseReplica bool                   `json:"useReplica,omitempty" yaml:"useReplica,omitempty"`
 	Dispatch   []string               `json:"dispatch,omitempty" yaml:"dispatch,omitempty"`
+	// Fallback lets a non-critical action degrade instead of failing the
+	// flow: when it fails, a cached or default output is used and the flow
+	// continues to Next.
+	Fallback *Fallback `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+	// DependsOn lists actions, as "action.<id>", whose outputs this action
+	// needs. Any that have not run yet in the request run first, in
+	// dependency order, so converging branches need not be chained by Next.
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	// Once runs the action at most once per process, e.g. to warm a cache or
+	// create an index. Later requests, in any config reload, reuse the output
+	// of its first successful run.
+	Once bool `json:"once,omitempty" yaml:"once,omitempty"`
+	// When is a template expression guarding the action: unless it renders
+	// "true", the action is skipped and the flow continues to Next.
+	When string `json:"when,omitempty" yaml:"when,omitempty"`
+}
+
+// Fallback configures the output substituted for a failed action. Fatal
+// failures (see plan.ErrFatal) are never replaced.
+type Fallback struct {
+	// Value is the output used when the action fails and no cached output
+	// is available.
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	// Cache keeps the action's last successful output in the shared cache and
+	// prefers it over Value.
+	Cache bool `json:"cache,omitempty" yaml:"cache,omitempty"`
+	// CacheTTL bounds how stale a cached output may be, e.g. "10m". Empty
+	// keeps it until the cache evicts it.
+	CacheTTL string `json:"cacheTTL,omitempty" yaml:"cacheTTL,omitempty"`
+	// CacheKey is a template resolved per request that decides which
+	// requests share a cached output, e.g. "{{ .user_id }}". Empty keys the
+	// output by the action's resolved config.
+	CacheKey string `json:"cacheKey,omitempty" yaml:"cacheKey,omitempty"`
 }
 
 type Conditional struct {
@@ -98,11 +187,26 @@ type Conditional struct {
 	Structure  [][]ConditionItem `json:"structure,omitempty" yaml:"structure,omitempty"`
 }
 
+// Join is a convergence point for branches started by a parallel action. Every
+// branch may lead to the same join; the join only continues to Next once all
+// the actions listed in WaitFor have completed, and it does so exactly once.
+type Join struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty" jsonschema:"required"`
+	// WaitFor lists the upstream action references (e.g. "action.fetchUser")
+	// whose outputs must be available before the join continues.
+	WaitFor []string `json:"waitFor" yaml:"waitFor"`
+	Next    string   `json:"next" yaml:"next"`
+}
+
 type ConditionItem struct {
 	Content    string `json:"content" yaml:"content"`
 	Comparison string `json:"comparison,omitempty" yaml:"comparison,omitempty"`
 	Function   string `json:"function" yaml:"function"`
 	Title      string `json:"title" yaml:"title"`
+	// Message replaces the default validation error pushed into the error
+	// variable when the item fails. Only the validating functions (email,
+	// empty, notempty and bcrypt) report errors.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
 }
 
 type ResponseConfig struct {
@@ -117,11 +221,71 @@ type ResponseConfig struct {
 	Type     string         `json:"type" yaml:"type"`
 	Object   ResponseObject `json:"responseObject" yaml:"responseObject"`
 	File     FileInput      `json:"file" yaml:"file"`
+	// Format is "compact" or "pretty" to re-encode a JSON body without
+	// whitespace or indented for debugging. Empty leaves the body as built.
+	// "yaml" or "xml" writes a response object in that encoding instead;
+	// without them a response object follows the request's Accept header.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// ContentType sets the Content-Type of a template response. Empty infers
+	// it from the rendered body: JSON, HTML, or plain text. For a multipart
+	// response it is multipart/mixed, the default, or multipart/related.
+	ContentType string `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+	// KeyCase is "snake", "camel" or "pascal" to rename the keys of a JSON
+	// body, at every depth, to that case, e.g. user_id to userId. Empty keeps
+	// them as built.
+	KeyCase string `json:"keyCase,omitempty" yaml:"keyCase,omitempty"`
+	// Schema is the JSON schema of the body, published in the engine's
+	// OpenAPI document.
+	Schema map[string]interface{} `json:"schema,omitempty" yaml:"schema,omitempty"`
+	// SSE configures an "sse" response, a body of server-sent events.
+	SSE SSEConfig `json:"sse,omitempty" yaml:"sse,omitempty"`
+	// Filename, for a csv response, is sent in Content-Disposition so
+	// clients save the body as an attachment with that name. It may be a
+	// template.
+	Filename string `json:"filename,omitempty" yaml:"filename,omitempty"`
+	// Passthrough configures a "passthrough" response, which writes an
+	// upstream response captured by the http action back as it was.
+	Passthrough PassthroughConfig `json:"passthrough,omitempty" yaml:"passthrough,omitempty"`
+}
+
+// PassthroughConfig selects the captured upstream response to return.
+type PassthroughConfig struct {
+	// Response renders to the output of an http action with captureResponse
+	// set, e.g. {{ .proxy }}. Its status is the status of the response; Code
+	// is only used when it has none.
+	Response string `json:"response" yaml:"response"`
+	// Headers lists the upstream headers copied to the response. Empty copies
+	// only Content-Type. Hop-by-hop headers such as Connection are never
+	// copied.
+	Headers []string `json:"headers,omitempty" yaml:"headers,omitempty"`
+}
+
+// SSEConfig builds server-sent events from a list. Every event carries an id,
+// and a client reconnecting with a Last-Event-ID header is only sent the
+// events after that id.
+type SSEConfig struct {
+	// Events renders to a JSON array; each element is the data of one event.
+	Events string `json:"events" yaml:"events"`
+	// Event is the event type of every event. Empty sends message events.
+	Event string `json:"event,omitempty" yaml:"event,omitempty"`
+	// IDField is the field of each element holding its event id, an integer
+	// that must increase from one element to the next. Empty numbers the
+	// events 1, 2, 3 and so on in order, which suits sources that always
+	// list every event; a source that can start after an id, read with
+	// {{ header "Last-Event-ID" }}, should set it.
+	IDField string `json:"idField,omitempty" yaml:"idField,omitempty"`
+	// Retry, in milliseconds, tells clients how long to wait before
+	// reconnecting. Zero leaves it to the client.
+	Retry int `json:"retry,omitempty" yaml:"retry,omitempty"`
 }
 
 type ResponseObject struct {
 	Value  string                    `json:"value" yaml:"value"`
 	Fields map[string]ResponseObject `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// OmitEmpty drops the fields of the object, and of every object nested in
+	// it, that resolve to null or an empty string. Nested objects left with no
+	// fields are dropped too.
+	OmitEmpty bool `json:"omitEmpty,omitempty" yaml:"omitEmpty,omitempty"`
 }
 
 func (o *ResponseObject) ToProto() *proto.ResponseObject {
@@ -142,6 +306,29 @@ type IntegrationConfig struct {
 	Config   map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
 	Type     string                 `json:"type" yaml:"type"`
 	LazyLoad bool                   `json:"lazyLoad" yaml:"lazyLoad"`
+
+	// WriteBatch buffers the integration's inserts and writes them in batches.
+	// It requires an integration with StoreMany and cannot be lazy loaded.
+	WriteBatch *WriteBatchConfig `json:"writeBatch,omitempty" yaml:"writeBatch,omitempty"`
+	// ReadCache serves the integration's fetches from the shared cache.
+	ReadCache *ReadCacheConfig `json:"readCache,omitempty" yaml:"readCache,omitempty"`
+}
+
+// WriteBatchConfig sets when buffered inserts are flushed: Window after the
+// first insert of a batch, e.g. "100ms", or once MaxSize inserts are pending,
+// whichever comes first. They default to 100ms and 100.
+type WriteBatchConfig struct {
+	Window  string `json:"window,omitempty" yaml:"window,omitempty"`
+	MaxSize int    `json:"maxSize,omitempty" yaml:"maxSize,omitempty"`
+}
+
+// ReadCacheConfig sets how long fetch results are cached: TTL for results
+// with rows, e.g. "1m", the default, and NegativeTTL for empty results, which
+// are not cached when it is empty. Writes do not invalidate cached results,
+// so TTL bounds how stale a read can be.
+type ReadCacheConfig struct {
+	TTL         string `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	NegativeTTL string `json:"negativeTTL,omitempty" yaml:"negativeTTL,omitempty"`
 }
 
 //	func (d *IntegrationConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -7,12 +7,13 @@ import (
 
 // Step-reference prefixes. A workflow step refers to the next step to run by a
 // prefixed string id, e.g. "action.createUser", "conditional.isValid",
-// "response.ok". These constants are the canonical home for those prefixes;
+// "response.ok", "join.merge". These constants are the canonical home for those prefixes;
 // requestctx aliases them for backwards compatibility.
 const (
 	ActionConfigPrefix      = "action."
 	ConditionalConfigPrefix = "conditional."
 	ResponsesConfigPrefix   = "response."
+	JoinConfigPrefix        = "join."
 )
 
 // StepKind identifies which map a step reference resolves into.
@@ -23,6 +24,7 @@ const (
 	StepKindAction
 	StepKindConditional
 	StepKindResponse
+	StepKindJoin
 )
 
 func (k StepKind) String() string {
@@ -33,6 +35,8 @@ func (k StepKind) String() string {
 		return "conditional"
 	case StepKindResponse:
 		return "response"
+	case StepKindJoin:
+		return "join"
 	default:
 		return "unknown"
 	}
@@ -59,10 +63,12 @@ func ParseStepRef(raw string) (kind StepKind, id string, terminal bool, err erro
 		return StepKindConditional, strings.TrimPrefix(s, ConditionalConfigPrefix), false, nil
 	case strings.HasPrefix(s, ResponsesConfigPrefix):
 		return StepKindResponse, strings.TrimPrefix(s, ResponsesConfigPrefix), false, nil
+	case strings.HasPrefix(s, JoinConfigPrefix):
+		return StepKindJoin, strings.TrimPrefix(s, JoinConfigPrefix), false, nil
 	default:
 		return StepKindUnknown, s, false, fmt.Errorf(
-			"invalid step reference %q: must start with %q, %q, or %q",
-			raw, ActionConfigPrefix, ConditionalConfigPrefix, ResponsesConfigPrefix)
+			"invalid step reference %q: must start with %q, %q, %q, or %q",
+			raw, ActionConfigPrefix, ConditionalConfigPrefix, ResponsesConfigPrefix, JoinConfigPrefix)
 	}
 }
 
@@ -0,0 +1,77 @@
+// Package cache is the key-value cache shared by engine features such as rate
+// limiting, idempotency keys, token caching and query caching, so each does
+// not build its own. The engine installs one process-wide cache (see
+// SetDefault); features read it with Default.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cache stores byte values under string keys with an optional TTL.
+type Cache interface {
+	// Get returns the value for key and whether it was found. Expired keys
+	// are not found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key. A ttl of zero or less never expires.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// Close releases the cache's resources.
+	Close() error
+}
+
+const (
+	TypeMemory = "memory"
+	TypeRedis  = "redis"
+)
+
+// Config selects and configures a cache implementation.
+type Config struct {
+	// Type is memory (the default) or redis.
+	Type     string `json:"type" yaml:"type"`
+	Address  string `json:"address" yaml:"address"`
+	Password string `json:"password" yaml:"password"`
+	DB       int    `json:"db" yaml:"db"`
+	// Prefix is prepended to every key, so several deployments can share one
+	// redis database.
+	Prefix string `json:"prefix" yaml:"prefix"`
+}
+
+// New builds the cache described by cfg.
+func New(cfg Config) (Cache, error) {
+	switch cfg.Type {
+	case "", TypeMemory:
+		return NewMemory(), nil
+	case TypeRedis:
+		return NewRedis(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported cache type: %s", cfg.Type)
+	}
+}
+
+var (
+	defaultCache Cache
+	defaultMu    sync.Mutex
+)
+
+// SetDefault installs c as the process-wide cache returned by Default.
+func SetDefault(c Cache) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultCache = c
+}
+
+// Default returns the process-wide cache, creating an in-memory one if none
+// was installed.
+func Default() Cache {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultCache == nil {
+		defaultCache = NewMemory()
+	}
+	return defaultCache
+}
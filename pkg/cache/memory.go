@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process Cache. Expired entries are dropped when read and
+// swept whenever the number of entries has doubled since the last sweep, so
+// abandoned keys do not accumulate.
+type Memory struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	sweepSize int
+	now       func() time.Time
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+const minSweepSize = 64
+
+func NewMemory() *Memory {
+	return &Memory{
+		entries:   make(map[string]memoryEntry),
+		sweepSize: minSweepSize,
+		now:       time.Now,
+	}
+}
+
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if e.expired(m.now()) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	return append([]byte(nil), e.value...), true, nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := memoryEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		e.expiresAt = m.now().Add(ttl)
+	}
+	m.entries[key] = e
+	if len(m.entries) >= m.sweepSize {
+		m.sweep()
+	}
+	return nil
+}
+
+// sweep removes expired entries. Callers must hold m.mu.
+func (m *Memory) sweep() {
+	now := m.now()
+	for k, e := range m.entries {
+		if e.expired(now) {
+			delete(m.entries, k)
+		}
+	}
+	m.sweepSize = max(2*len(m.entries), minSweepSize)
+}
+
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = make(map[string]memoryEntry)
+	return nil
+}
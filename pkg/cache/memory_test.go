@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	t.Run("set and get", func(t *testing.T) {
+		require.NoError(t, m.Set(ctx, "k", []byte("v"), 0))
+		v, ok, err := m.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []byte("v"), v)
+
+		_, ok, err = m.Get(ctx, "missing")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("ttl expiry", func(t *testing.T) {
+		require.NoError(t, m.Set(ctx, "session", []byte("s"), time.Minute))
+		_, ok, _ := m.Get(ctx, "session")
+		assert.True(t, ok)
+
+		now = now.Add(time.Minute)
+		_, ok, _ = m.Get(ctx, "session")
+		assert.False(t, ok)
+
+		_, ok, _ = m.Get(ctx, "k")
+		assert.True(t, ok, "entries without a ttl never expire")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, m.Set(ctx, "gone", []byte("x"), 0))
+		require.NoError(t, m.Delete(ctx, "gone"))
+		_, ok, _ := m.Get(ctx, "gone")
+		assert.False(t, ok)
+		assert.NoError(t, m.Delete(ctx, "gone"), "deleting a missing key is not an error")
+	})
+
+	t.Run("stored values are copied", func(t *testing.T) {
+		value := []byte("abc")
+		require.NoError(t, m.Set(ctx, "copy", value, 0))
+		value[0] = 'x'
+		got, _, _ := m.Get(ctx, "copy")
+		assert.Equal(t, []byte("abc"), got)
+	})
+
+	t.Run("expired entries are swept", func(t *testing.T) {
+		for i := 0; i < minSweepSize; i++ {
+			require.NoError(t, m.Set(ctx, fmt.Sprintf("tmp-%d", i), []byte("x"), time.Second))
+		}
+		now = now.Add(time.Second)
+		require.NoError(t, m.Set(ctx, "trigger", []byte("x"), 0))
+		for i := 0; i < minSweepSize; i++ {
+			require.NoError(t, m.Set(ctx, fmt.Sprintf("keep-%d", i), []byte("x"), 0))
+		}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for k := range m.entries {
+			assert.NotContains(t, k, "tmp-")
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(Config{})
+	require.NoError(t, err)
+	assert.IsType(t, &Memory{}, c)
+
+	_, err = New(Config{Type: "memcached"})
+	assert.Error(t, err)
+
+	_, err = New(Config{Type: TypeRedis})
+	assert.ErrorContains(t, err, "address")
+}
+
+func TestDefault(t *testing.T) {
+	defer SetDefault(nil)
+
+	SetDefault(nil)
+	assert.IsType(t, &Memory{}, Default())
+
+	m := NewMemory()
+	SetDefault(m)
+	assert.Same(t, m, Default())
+}
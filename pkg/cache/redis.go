@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Cache backed by a redis server, shared by every engine instance
+// pointing at it.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedis(cfg Config) (*Redis, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("redis cache requires an address")
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Address,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("error connecting to redis: %w", err)
+	}
+	return &Redis{client: client, prefix: cfg.Prefix}, nil
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+
+	c, err := New(Config{Type: TypeRedis, Address: srv.Addr(), Prefix: "servflow:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+
+	t.Run("set and get", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "k", []byte("v"), 0))
+		v, ok, err := c.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []byte("v"), v)
+
+		stored, err := srv.Get("servflow:k")
+		require.NoError(t, err)
+		assert.Equal(t, "v", stored, "keys are prefixed")
+
+		_, ok, err = c.Get(ctx, "missing")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("ttl expiry", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "session", []byte("s"), time.Minute))
+		_, ok, _ := c.Get(ctx, "session")
+		assert.True(t, ok)
+
+		srv.FastForward(time.Minute)
+		_, ok, err := c.Get(ctx, "session")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		_, ok, _ = c.Get(ctx, "k")
+		assert.True(t, ok, "entries without a ttl never expire")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "gone", []byte("x"), 0))
+		require.NoError(t, c.Delete(ctx, "gone"))
+		_, ok, _ := c.Get(ctx, "gone")
+		assert.False(t, ok)
+		assert.NoError(t, c.Delete(ctx, "gone"))
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		_, err := NewRedis(Config{Address: "127.0.0.1:1"})
+		assert.Error(t, err)
+	})
+}
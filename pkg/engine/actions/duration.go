@@ -0,0 +1,22 @@
+package actions
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseDuration parses a duration field of an action config, returning def
+// when s is empty. Negative durations are rejected.
+func ParseDuration(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration must not be negative: %s", s)
+	}
+	return d, nil
+}
@@ -25,7 +25,33 @@ type Config struct {
 	ConversationID    string              `json:"conversationID" yaml:"conversationID"`
 	ReturnLastMessage bool                `json:"returnLastMessage" yaml:"returnLastMessage"`
 	FileUpload        apiconfig.FileInput `json:"fileUpload" yaml:"fileUpload"`
+	// MaxIterations caps the LLM round-trips of one run. Zero uses the agent
+	// default.
+	MaxIterations int `json:"maxIterations,omitempty" yaml:"maxIterations,omitempty"`
+	// ConversationStore keeps the conversation named by ConversationID in a
+	// table of a datasource integration instead of local storage.
+	ConversationStore *ConversationStoreConfig `json:"conversationStore,omitempty" yaml:"conversationStore,omitempty"`
 }
+
+// ConversationStoreConfig names the integration and table holding
+// conversations, see agent.SQLConversationStore.
+type ConversationStoreConfig struct {
+	IntegrationID string `json:"integrationID" yaml:"integrationID"`
+	Table         string `json:"table" yaml:"table"`
+}
+
+func newConversationStore(ctx context.Context, cfg *ConversationStoreConfig) (agent.ConversationStore, error) {
+	i, err := integration.GetIntegration(ctx, cfg.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	table, ok := i.(agent.ConversationTable)
+	if !ok {
+		return nil, fmt.Errorf("integration %s can not store conversations", cfg.IntegrationID)
+	}
+	return agent.NewSQLConversationStore(table, cfg.Table)
+}
+
 type MCPServerConfig struct {
 	Endpoint string   `json:"endpoint" yaml:"endpoint"`
 	Tools    []string `json:"tools" yaml:"tools"`
@@ -68,10 +94,20 @@ func (a *Agent) Execute(ctx context.Context, modifiedConfig string) (interface{}
 	options := []agent.Option{agent.WithToolManager(a.toolManager)}
 	if newConfig.ConversationID != "" {
 		options = append(options, agent.WithConversationID(ctx, newConfig.ConversationID))
+		if newConfig.ConversationStore != nil {
+			store, err := newConversationStore(ctx, newConfig.ConversationStore)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: %v", actions.ErrorFatal, err)
+			}
+			options = append(options, agent.WithConversationStore(store))
+		}
 	}
 	if newConfig.ReturnLastMessage {
 		options = append(options, agent.WithReturnOnlyLastMessage())
 	}
+	if newConfig.MaxIterations > 0 {
+		options = append(options, agent.WithMaxIterations(newConfig.MaxIterations))
+	}
 	session, err := agent.NewSession(
 		newConfig.SystemPrompt,
 		a.integration,
@@ -198,11 +234,18 @@ func init() {
 			Required:    false,
 			Default:     false,
 		},
+		"maxIterations": {
+			Type:        actions.FieldTypeNumber,
+			Label:       "Max Iterations",
+			Placeholder: "Maximum LLM calls per run",
+			Required:    false,
+		},
 	}
 
 	if err := actions.RegisterAction("agent", actions.ActionRegistrationInfo{
 		Name:        "AI Agent",
 		Description: "Interacts with AI models to process queries and execute tool functions",
+		SideEffects: true,
 		Fields:      fields,
 		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
 			var cfg Config
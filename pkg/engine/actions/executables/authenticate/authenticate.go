@@ -20,6 +20,20 @@ type Config struct {
 	Token           string `json:"token" yaml:"token"`
 	Collection      string `json:"collection" yaml:"collection"`
 	FailOnAuthError bool   `json:"failOnAuthError" yaml:"failOnAuthError"`
+
+	// Mode is "" to authenticate Token as an access token, or "refresh" to
+	// exchange Token, a refresh token, for a new token pair.
+	Mode string `json:"mode" yaml:"mode"`
+	// IssueTokens makes a successful authentication return a new access and
+	// refresh token pair instead of the subject. Refresh mode always does.
+	IssueTokens bool `json:"issueTokens" yaml:"issueTokens"`
+	// AccessTokenTTL and RefreshTokenTTL are the lifetimes of issued tokens,
+	// e.g. "15m". They default to 15m and 720h.
+	AccessTokenTTL  string `json:"accessTokenTTL" yaml:"accessTokenTTL"`
+	RefreshTokenTTL string `json:"refreshTokenTTL" yaml:"refreshTokenTTL"`
+	// RefreshCollection is where issued refresh tokens are recorded. Defaults
+	// to refresh_tokens.
+	RefreshCollection string `json:"refreshCollection" yaml:"refreshCollection"`
 }
 
 type fetchImplementation interface {
@@ -30,9 +44,13 @@ type fetchImplementation interface {
 type Action struct {
 	fetchImplementation fetchImplementation
 	cfg                 Config
+
+	// tokens is set when the action issues tokens.
+	tokens *tokenIssuer
 }
 
 func New(config Config) (*Action, error) {
+	config.IntegrationID = integration.ResolveID(config.IntegrationID)
 	integrationID := config.IntegrationID
 	databaseField := config.DatabaseField
 
@@ -54,9 +72,22 @@ func New(config Config) (*Action, error) {
 		return nil, errors.New("integration is not a fetch implementation")
 	}
 
+	var tokens *tokenIssuer
+	switch config.Mode {
+	case "", modeRefresh:
+	default:
+		return nil, fmt.Errorf("unknown mode %q", config.Mode)
+	}
+	if config.IssueTokens || config.Mode == modeRefresh {
+		if tokens, err = newTokenIssuer(i, config); err != nil {
+			return nil, err
+		}
+	}
+
 	return &Action{
 		cfg:                 config,
 		fetchImplementation: u,
+		tokens:              tokens,
 	}, nil
 }
 
@@ -71,19 +102,17 @@ func (a *Action) Execute(ctx context.Context, modifiedConfig string) (interface{
 	if err := json.Unmarshal([]byte(modifiedConfig), &cfg); err != nil {
 		return nil, nil, err
 	}
+	if cfg.Mode == modeRefresh {
+		return a.refresh(ctx, cfg)
+	}
 
-	token, err := jwt.Parse(cfg.Token, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(cfg.JWTKey), nil
-	})
+	token, err := jwt.Parse(cfg.Token, hmacKey(cfg.JWTKey))
 	if err != nil {
 		return nil, nil, err
 	}
 
 	subject := ""
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid && claims[typeClaim] != refreshTokenType {
 		if sub, ok := claims["sub"].(string); ok {
 			subject = sub
 		}
@@ -95,22 +124,36 @@ func (a *Action) Execute(ctx context.Context, modifiedConfig string) (interface{
 		return nil, nil, errors.New("token subject is invalid")
 	}
 
+	if err := a.lookupUser(ctx, cfg, subject); err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.IssueTokens {
+		pair, err := a.tokens.issue(ctx, cfg, subject, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		return pair, nil, nil
+	}
+	return subject, nil, nil
+}
+
+func (a *Action) lookupUser(ctx context.Context, cfg Config, subject string) error {
 	resp, err := a.fetchImplementation.Fetch(ctx, map[string]string{"collection": cfg.Collection}, filters.Filter{
 		Field:      cfg.DatabaseField,
 		Operation:  filters.Equals,
 		Comparator: subject,
 	})
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 	if len(resp) < 1 {
 		if cfg.FailOnAuthError {
-			return nil, nil, fmt.Errorf("%w: authentication failed - user not found", plan.ErrFailure)
+			return fmt.Errorf("%w: authentication failed - user not found", plan.ErrFailure)
 		}
-		return nil, nil, errors.New("token subject is invalid")
+		return errors.New("token subject is invalid")
 	}
-
-	return subject, nil, nil
+	return nil
 }
 
 func (a *Action) Type() string {
@@ -160,11 +203,43 @@ func init() {
 			Required:    false,
 			Default:     true,
 		},
+		"mode": {
+			Type:        actions.FieldTypeString,
+			Label:       "Mode",
+			Placeholder: `Empty to authenticate an access token, or "refresh" to rotate a refresh token`,
+			Required:    false,
+		},
+		"issueTokens": {
+			Type:        actions.FieldTypeBoolean,
+			Label:       "Issue Tokens",
+			Placeholder: "Return a new access and refresh token pair on success",
+			Required:    false,
+			Default:     false,
+		},
+		"accessTokenTTL": {
+			Type:        actions.FieldTypeString,
+			Label:       "Access Token TTL",
+			Placeholder: "15m",
+			Required:    false,
+		},
+		"refreshTokenTTL": {
+			Type:        actions.FieldTypeString,
+			Label:       "Refresh Token TTL",
+			Placeholder: "720h",
+			Required:    false,
+		},
+		"refreshCollection": {
+			Type:        actions.FieldTypeString,
+			Label:       "Refresh Collection",
+			Placeholder: "refresh_tokens",
+			Required:    false,
+		},
 	}
 
 	if err := actions.RegisterAction("authenticate", actions.ActionRegistrationInfo{
 		Name:        "Authenticate",
 		Description: "Validates JWT tokens and authenticates users against database records",
+		SideEffects: true,
 		Fields:      fields,
 		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
 			var cfg Config
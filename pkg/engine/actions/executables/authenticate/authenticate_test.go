@@ -85,6 +85,28 @@ func TestAuthenticate_New(t *testing.T) {
 		require.NoError(t, err)
 		assert.NotNil(t, auth)
 	})
+
+	t.Run("default integration", func(t *testing.T) {
+		ctr := gomock.NewController(t)
+		defer ctr.Finish()
+
+		mockIntegration := NewMockfetchImplementation(ctr)
+		integration.ReplaceIntegrationType("mockdefault", func(m map[string]any) (integration.Integration, error) {
+			return mockIntegration, nil
+		})
+		require.NoError(t, integration.InitializeIntegration("mockdefault", "defaultds", nil, false))
+
+		integration.SetDefaultIntegration("defaultds")
+		defer integration.SetDefaultIntegration("")
+
+		auth, err := New(Config{DatabaseField: "email", JWTKey: "secret"})
+		require.NoError(t, err)
+		assert.Equal(t, mockIntegration, auth.fetchImplementation)
+
+		// an explicit id still wins
+		_, err = New(Config{IntegrationID: "nonexistent", DatabaseField: "email"})
+		assert.ErrorContains(t, err, "nonexistent")
+	})
 }
 
 func TestAuthenticate_Execute(t *testing.T) {
@@ -0,0 +1,248 @@
+package authenticate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	modeRefresh = "refresh"
+
+	// typeClaim marks refresh tokens so they cannot be used as access tokens.
+	typeClaim        = "typ"
+	refreshTokenType = "refresh"
+	familyClaim      = "fam"
+
+	defaultAccessTokenTTL    = 15 * time.Minute
+	defaultRefreshTokenTTL   = 30 * 24 * time.Hour
+	defaultRefreshCollection = "refresh_tokens"
+
+	tokenIDField   = "token_id"
+	subjectField   = "subject"
+	familyField    = "family"
+	usedField      = "used"
+	expiresAtField = "expires_at"
+)
+
+type storeImplementation interface {
+	Fetch(ctx context.Context, options map[string]string, filters ...filters.Filter) ([]map[string]interface{}, error)
+	Store(ctx context.Context, item map[string]interface{}, options map[string]string) error
+	Update(ctx context.Context, fields map[string]interface{}, options map[string]string, filters ...filters.Filter) (string, error)
+}
+
+// tokenIssuer issues access and refresh token pairs and records each refresh
+// token, one record per token: token_id, subject, family, used and
+// expires_at. All refresh tokens descending from one authentication share a
+// family, so presenting a token that was already rotated, a sign that it
+// leaked, revokes the whole family.
+type tokenIssuer struct {
+	store      storeImplementation
+	collection string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func newTokenIssuer(i interface{}, config Config) (*tokenIssuer, error) {
+	store, ok := i.(storeImplementation)
+	if !ok {
+		return nil, errors.New("integration is not a store implementation")
+	}
+	t := &tokenIssuer{
+		store:      store,
+		collection: config.RefreshCollection,
+		accessTTL:  defaultAccessTokenTTL,
+		refreshTTL: defaultRefreshTokenTTL,
+	}
+	if t.collection == "" {
+		t.collection = defaultRefreshCollection
+	}
+	var err error
+	if t.accessTTL, err = parseTTL("accessTokenTTL", config.AccessTokenTTL, t.accessTTL); err != nil {
+		return nil, err
+	}
+	if t.refreshTTL, err = parseTTL("refreshTokenTTL", config.RefreshTokenTTL, t.refreshTTL); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func parseTTL(field, value string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", field, value)
+	}
+	return d, nil
+}
+
+// issue returns a new token pair for subject. An empty family starts a new
+// one.
+func (t *tokenIssuer) issue(ctx context.Context, cfg Config, subject, family string) (map[string]interface{}, error) {
+	if family == "" {
+		family = uuid.NewString()
+	}
+	now := time.Now()
+	tokenID := uuid.NewString()
+
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": subject,
+		"iat": now.Unix(),
+		"exp": now.Add(t.accessTTL).Unix(),
+	}).SignedString([]byte(cfg.JWTKey))
+	if err != nil {
+		return nil, fmt.Errorf("error signing access token: %w", err)
+	}
+	refresh, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":       subject,
+		"jti":       tokenID,
+		familyClaim: family,
+		typeClaim:   refreshTokenType,
+		"iat":       now.Unix(),
+		"exp":       now.Add(t.refreshTTL).Unix(),
+	}).SignedString([]byte(cfg.JWTKey))
+	if err != nil {
+		return nil, fmt.Errorf("error signing refresh token: %w", err)
+	}
+
+	record := map[string]interface{}{
+		tokenIDField:   tokenID,
+		subjectField:   subject,
+		familyField:    family,
+		usedField:      false,
+		expiresAtField: now.Add(t.refreshTTL).UTC().Format(time.RFC3339),
+	}
+	if err := t.store.Store(ctx, record, map[string]string{"collection": t.collection}); err != nil {
+		return nil, fmt.Errorf("error recording refresh token: %w", err)
+	}
+
+	return map[string]interface{}{
+		"subject":      subject,
+		"accessToken":  access,
+		"refreshToken": refresh,
+		"expiresIn":    int64(t.accessTTL.Seconds()),
+	}, nil
+}
+
+// markUsed records that record's token was rotated.
+func (t *tokenIssuer) markUsed(ctx context.Context, record map[string]interface{}) error {
+	used := make(map[string]interface{}, len(record))
+	for k, v := range record {
+		used[k] = v
+	}
+	used[usedField] = true
+	return t.store.Store(ctx, used, map[string]string{"collection": t.collection, filters.UpsertOnOption: tokenIDField})
+}
+
+// rotate marks the unused refresh token tokenID as used in a single
+// conditional update. It reports false when the token was already used, so of
+// several concurrent refreshes with one token only the first succeeds.
+func (t *tokenIssuer) rotate(ctx context.Context, tokenID string) (bool, error) {
+	_, err := t.store.Update(ctx, map[string]interface{}{usedField: true}, map[string]string{"collection": t.collection},
+		filters.Filter{Field: tokenIDField, Operation: filters.Equals, Comparator: tokenID},
+		filters.Filter{Field: usedField, Operation: filters.Equals, Comparator: false})
+	if errors.Is(err, filters.ErrNoMatch) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// revokeFamily marks every refresh token of family as used, so none of them
+// can be rotated again.
+func (t *tokenIssuer) revokeFamily(ctx context.Context, family string) error {
+	records, err := t.store.Fetch(ctx, map[string]string{"collection": t.collection},
+		filters.Filter{Field: familyField, Operation: filters.Equals, Comparator: family})
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if isUsed(record[usedField]) {
+			continue
+		}
+		if err := t.markUsed(ctx, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isUsed reads the used field as stored by a datasource, which may return a
+// bool, a number or a string.
+func isUsed(v interface{}) bool {
+	switch fmt.Sprint(v) {
+	case "true", "1":
+		return true
+	}
+	return false
+}
+
+// refresh exchanges the refresh token in cfg.Token for a new token pair. The
+// presented token is rotated: it cannot be used again.
+func (a *Action) refresh(ctx context.Context, cfg Config) (interface{}, map[string]string, error) {
+	token, err := jwt.Parse(cfg.Token, hmacKey(cfg.JWTKey))
+	if err != nil {
+		return nil, nil, authFailure(cfg, fmt.Sprintf("invalid refresh token: %v", err))
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	subject, _ := claims["sub"].(string)
+	tokenID, _ := claims["jti"].(string)
+	family, _ := claims[familyClaim].(string)
+	if claims[typeClaim] != refreshTokenType || subject == "" || tokenID == "" || family == "" {
+		return nil, nil, authFailure(cfg, "invalid refresh token")
+	}
+
+	records, err := a.tokens.store.Fetch(ctx, map[string]string{"collection": a.tokens.collection, filters.LimitOption: "1"},
+		filters.Filter{Field: tokenIDField, Operation: filters.Equals, Comparator: tokenID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error looking up refresh token: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, authFailure(cfg, "refresh token is not recognised")
+	}
+	rotated, err := a.tokens.rotate(ctx, tokenID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error rotating refresh token: %w", err)
+	}
+	if !rotated {
+		if err := a.tokens.revokeFamily(ctx, family); err != nil {
+			return nil, nil, fmt.Errorf("error revoking refresh tokens: %w", err)
+		}
+		return nil, nil, authFailure(cfg, "refresh token reuse detected")
+	}
+
+	if err := a.lookupUser(ctx, cfg, subject); err != nil {
+		return nil, nil, err
+	}
+	pair, err := a.tokens.issue(ctx, cfg, subject, family)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, nil, nil
+}
+
+func hmacKey(key string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(key), nil
+	}
+}
+
+func authFailure(cfg Config, msg string) error {
+	if cfg.FailOnAuthError {
+		return fmt.Errorf("%w: %s", plan.ErrFailure, msg)
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,198 @@
+package authenticate
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTable is an in-memory datasource keyed by collection, supporting the
+// equality filters and upsertOn option the action uses.
+type memoryTable struct {
+	mu   sync.Mutex
+	rows map[string][]map[string]interface{}
+	// onFetch, when set, is called with the first filter of every Fetch once
+	// it has read the rows.
+	onFetch func(filters.Filter)
+}
+
+func (m *memoryTable) Type() string { return "memory" }
+
+func (m *memoryTable) Fetch(_ context.Context, options map[string]string, f ...filters.Filter) ([]map[string]interface{}, error) {
+	m.mu.Lock()
+	var out []map[string]interface{}
+	for _, row := range m.rows[options["collection"]] {
+		if row[f[0].Field] == f[0].Comparator {
+			out = append(out, row)
+		}
+	}
+	m.mu.Unlock()
+	if m.onFetch != nil {
+		m.onFetch(f[0])
+	}
+	return out, nil
+}
+
+func (m *memoryTable) Store(_ context.Context, item map[string]interface{}, options map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	collection := options["collection"]
+	if key := options[filters.UpsertOnOption]; key != "" {
+		for i, row := range m.rows[collection] {
+			if row[key] == item[key] {
+				m.rows[collection][i] = item
+				return nil
+			}
+		}
+	}
+	m.rows[collection] = append(m.rows[collection], item)
+	return nil
+}
+
+func (m *memoryTable) Update(_ context.Context, fields map[string]interface{}, options map[string]string, f ...filters.Filter) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	collection := options["collection"]
+	for i, row := range m.rows[collection] {
+		if !slices.ContainsFunc(f, func(f filters.Filter) bool { return row[f.Field] != f.Comparator }) {
+			updated := maps.Clone(row)
+			maps.Copy(updated, fields)
+			m.rows[collection][i] = updated
+			return "", nil
+		}
+	}
+	return "", filters.ErrNoMatch
+}
+
+func TestAuthenticate_Refresh(t *testing.T) {
+	const (
+		jwtKey = "test-secret-key"
+		email  = "user@example.com"
+	)
+	table := &memoryTable{rows: map[string][]map[string]interface{}{
+		"users": {{"email": email}},
+	}}
+	integration.ReplaceIntegrationType("memory", func(map[string]any) (integration.Integration, error) {
+		return table, nil
+	})
+	require.NoError(t, integration.InitializeIntegration("memory", "memoryds", nil, false))
+
+	newAction := func(mode string) *Action {
+		a, err := New(Config{
+			IntegrationID:   "memoryds",
+			DatabaseField:   "email",
+			Collection:      "users",
+			Mode:            mode,
+			IssueTokens:     true,
+			FailOnAuthError: true,
+		})
+		require.NoError(t, err)
+		return a
+	}
+	execute := func(a *Action, token string) (map[string]interface{}, error) {
+		cfg, err := json.Marshal(Config{
+			DatabaseField:   "email",
+			JWTKey:          jwtKey,
+			Token:           token,
+			Collection:      "users",
+			Mode:            a.cfg.Mode,
+			IssueTokens:     true,
+			FailOnAuthError: true,
+		})
+		require.NoError(t, err)
+		res, _, err := a.Execute(context.Background(), string(cfg))
+		if err != nil {
+			return nil, err
+		}
+		return res.(map[string]interface{}), nil
+	}
+
+	login, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": email}).SignedString([]byte(jwtKey))
+	require.NoError(t, err)
+	first, err := execute(newAction(""), login)
+	require.NoError(t, err)
+	assert.Equal(t, email, first["subject"])
+	require.Len(t, table.rows[defaultRefreshCollection], 1)
+
+	refresher := newAction(modeRefresh)
+
+	t.Run("successful refresh rotates the token", func(t *testing.T) {
+		second, err := execute(refresher, first["refreshToken"].(string))
+		require.NoError(t, err)
+		assert.Equal(t, email, second["subject"])
+		assert.NotEqual(t, first["refreshToken"], second["refreshToken"])
+
+		rows := table.rows[defaultRefreshCollection]
+		require.Len(t, rows, 2)
+		assert.Equal(t, true, rows[0][usedField])
+		assert.Equal(t, false, rows[1][usedField])
+		assert.Equal(t, rows[0][familyField], rows[1][familyField])
+
+		// the new access token authenticates
+		_, err = execute(newAction(""), second["accessToken"].(string))
+		assert.NoError(t, err)
+	})
+
+	t.Run("reusing a rotated token revokes its family", func(t *testing.T) {
+		_, err := execute(refresher, first["refreshToken"].(string))
+		require.ErrorIs(t, err, plan.ErrFailure)
+		assert.Contains(t, err.Error(), "reuse detected")
+
+		family := table.rows[defaultRefreshCollection][0][familyField]
+		for _, row := range table.rows[defaultRefreshCollection] {
+			if row[familyField] == family {
+				assert.Equal(t, true, row[usedField])
+			}
+		}
+	})
+
+	t.Run("concurrent refreshes with one token rotate it once", func(t *testing.T) {
+		pair, err := execute(newAction(""), login)
+		require.NoError(t, err)
+
+		// every refresh looks the token up before any of them rotates it
+		const refreshes = 8
+		var (
+			wg, fetched sync.WaitGroup
+			succeeded   atomic.Int32
+		)
+		fetched.Add(refreshes)
+		table.onFetch = func(f filters.Filter) {
+			if f.Field == tokenIDField {
+				fetched.Done()
+				fetched.Wait()
+			}
+		}
+		defer func() { table.onFetch = nil }()
+
+		for range refreshes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := execute(refresher, pair["refreshToken"].(string)); err == nil {
+					succeeded.Add(1)
+				} else {
+					assert.Contains(t, err.Error(), "reuse detected")
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), succeeded.Load())
+	})
+
+	t.Run("a refresh token is not an access token", func(t *testing.T) {
+		_, err := execute(newAction(""), first["refreshToken"].(string))
+		assert.ErrorIs(t, err, plan.ErrFailure)
+	})
+}
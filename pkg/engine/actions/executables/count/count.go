@@ -0,0 +1,143 @@
+//go:generate mockgen -source count.go -destination count_mock.go -package count
+package count
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// Count returns how many records in a table match its filters, e.g. to build
+// pagination metadata next to a fetch.
+type Count struct {
+	cfg              *Config
+	countIntegration countImplementation
+}
+
+func (c *Count) Type() string {
+	return "count"
+}
+
+func (c *Count) SupportsReplica() bool {
+	return true
+}
+
+type countImplementation interface {
+	integration.Integration
+	Count(ctx context.Context, options map[string]string, filters ...filters.Filter) (int64, error)
+}
+
+type Config struct {
+	IntegrationID     string            `json:"integrationID" yaml:"integrationID"`
+	Filters           []filters.Filter  `json:"filters" yaml:"filters"`
+	Table             string            `json:"table" yaml:"table"`
+	DatasourceOptions map[string]string `json:"datasourceOptions" yaml:"datasourceOptions"`
+}
+
+func New(config Config) (*Count, error) {
+	config.IntegrationID = integration.ResolveID(config.IntegrationID)
+	if config.IntegrationID == "" {
+		return nil, errors.New("datasource is required")
+	}
+	if config.Table == "" {
+		return nil, errors.New("table is required")
+	}
+	i, err := integration.GetIntegration(context.Background(), config.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	u, ok := i.(countImplementation)
+	if !ok {
+		return nil, errors.New("integration is not of type countImplementation")
+	}
+	return &Count{
+		cfg:              &config,
+		countIntegration: u,
+	}, nil
+}
+
+func (c *Count) Config() string {
+	filtersStr, err := json.Marshal(c.cfg.Filters)
+	if err != nil {
+		return ""
+	}
+	return string(filtersStr)
+}
+
+func (c *Count) Execute(ctx context.Context, modifiedConfig string) (interface{}, map[string]string, error) {
+	logger := logging.FromContext(ctx).With(zap.String("execution_type", c.Type()))
+	ctx = logging.WithLogger(ctx, logger)
+
+	var filters []filters.Filter
+	if err := json.Unmarshal([]byte(modifiedConfig), &filters); err != nil {
+		return "", nil, err
+	}
+
+	options := map[string]string{"collection": c.cfg.Table}
+	for k, v := range c.cfg.DatasourceOptions {
+		if k != "collection" {
+			options[k] = v
+		}
+	}
+
+	total, err := c.countIntegration.Count(ctx, options, filters...)
+	if err != nil {
+		return "", nil, fmt.Errorf("count with filters: %v", err)
+	}
+	return total, nil, nil
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"integrationID": {
+			Type:        actions.FieldTypeIntegration,
+			Label:       "Integration ID",
+			Placeholder: "Database integration identifier",
+			Required:    true,
+		},
+		"filters": {
+			Type:        actions.FieldTypeMap,
+			Label:       "Filters",
+			Placeholder: "Query filters",
+			Required:    false,
+			Metadata: map[string]string{
+				"type": "filter",
+			},
+		},
+		"table": {
+			Type:        actions.FieldTypeString,
+			Label:       "Table",
+			Placeholder: "Database table name",
+			Required:    true,
+		},
+		"datasourceOptions": {
+			Type:        actions.FieldTypeMap,
+			Label:       "Datasource Options",
+			Placeholder: "Additional datasource options",
+			Required:    false,
+		},
+	}
+
+	if err := actions.RegisterAction("count", actions.ActionRegistrationInfo{
+		Name:        "Count Data",
+		Description: "Counts the records in a database table that match the given filters",
+		Fields:      fields,
+		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
+			var cfg Config
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating count action: %v", err)
+			}
+			return New(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,66 @@
+package count
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCount_Execute(t *testing.T) {
+	t.Run("returns the count", func(t *testing.T) {
+		ctr := gomock.NewController(t)
+		defer ctr.Finish()
+
+		mockIntegration := NewMockcountImplementation(ctr)
+		mockIntegration.EXPECT().Count(gomock.Any(), map[string]string{"collection": "users", "idFormat": "id"}, filters.Filter{Field: "active", Comparator: true}).
+			Return(int64(42), nil)
+		integration.ReplaceIntegrationType("mock", func(m map[string]any) (integration.Integration, error) {
+			return mockIntegration, nil
+		})
+		require.NoError(t, integration.InitializeIntegration("mock", "mockds", nil, false))
+
+		count, err := New(Config{
+			IntegrationID:     "mockds",
+			Table:             "users",
+			Filters:           []filters.Filter{{Field: "active", Comparator: true}},
+			DatasourceOptions: map[string]string{"idFormat": "id", "collection": "ignored"},
+		})
+		require.NoError(t, err)
+
+		resp, _, err := count.Execute(context.Background(), count.Config())
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), resp)
+	})
+
+	t.Run("integration error", func(t *testing.T) {
+		ctr := gomock.NewController(t)
+		defer ctr.Finish()
+
+		mockIntegration := NewMockcountImplementation(ctr)
+		mockIntegration.EXPECT().Count(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("connection refused"))
+		integration.ReplaceIntegrationType("mock", func(m map[string]any) (integration.Integration, error) {
+			return mockIntegration, nil
+		})
+		require.NoError(t, integration.InitializeIntegration("mock", "mockds", nil, false))
+
+		count, err := New(Config{IntegrationID: "mockds", Table: "users"})
+		require.NoError(t, err)
+
+		_, _, err = count.Execute(context.Background(), count.Config())
+		assert.ErrorContains(t, err, "connection refused")
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Table: "users"})
+	assert.EqualError(t, err, "datasource is required")
+
+	_, err = New(Config{IntegrationID: "mockds"})
+	assert.EqualError(t, err, "table is required")
+}
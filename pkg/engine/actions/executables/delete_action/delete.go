@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/Servflow/servflow/pkg/engine/actions"
 	"github.com/Servflow/servflow/pkg/engine/integration"
@@ -38,6 +39,11 @@ type Config struct {
 	Filters           []filters.Filter  `json:"filters"`
 	Table             string            `json:"table"`
 	DatasourceOptions map[string]string `json:"datasourceOptions"`
+	// AllowFullDelete confirms that a delete without filters may remove every
+	// record; without it such a delete fails.
+	AllowFullDelete bool `json:"allowFullDelete"`
+	// BatchSize, when set, deletes matching records in batches of this size.
+	BatchSize int `json:"batchSize"`
 }
 
 type deleteImplementation interface {
@@ -46,6 +52,7 @@ type deleteImplementation interface {
 }
 
 func New(config Config) (*Delete, error) {
+	config.IntegrationID = integration.ResolveID(config.IntegrationID)
 	if config.IntegrationID == "" {
 		return nil, errors.New("datasource is required")
 	}
@@ -74,13 +81,24 @@ func (d *Delete) Execute(ctx context.Context, modifiedConfig string) (interface{
 	}
 
 	var ret interface{}
-	err := d.deleteIntegration.Delete(ctx, map[string]string{"collection": d.cfg.Table}, filters...)
+	err := d.deleteIntegration.Delete(ctx, d.options(), filters...)
 	if err != nil {
 		return "", nil, fmt.Errorf("delete with filters: %v", err)
 	}
 	return ret, nil, nil
 }
 
+func (d *Delete) options() map[string]string {
+	opts := map[string]string{"collection": d.cfg.Table}
+	if d.cfg.AllowFullDelete {
+		opts[filters.AllowFullDeleteOption] = "true"
+	}
+	if d.cfg.BatchSize > 0 {
+		opts[filters.BatchSizeOption] = strconv.Itoa(d.cfg.BatchSize)
+	}
+	return opts
+}
+
 func init() {
 	fields := map[string]actions.FieldInfo{
 		"integrationID": {
@@ -110,11 +128,24 @@ func init() {
 			Placeholder: "Additional datasource options",
 			Required:    false,
 		},
+		"allowFullDelete": {
+			Type:        actions.FieldTypeBoolean,
+			Label:       "Allow Full Delete",
+			Placeholder: "Allow deleting every record when no filters are set",
+			Required:    false,
+		},
+		"batchSize": {
+			Type:        actions.FieldTypeNumber,
+			Label:       "Batch Size",
+			Placeholder: "Delete matching records in batches of this size",
+			Required:    false,
+		},
 	}
 
 	if err := actions.RegisterAction("delete", actions.ActionRegistrationInfo{
 		Name:        "Delete Data",
 		Description: "Deletes records from database tables based on specified filters",
+		SideEffects: true,
 		Fields:      fields,
 		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
 			var cfg Config
@@ -124,6 +124,37 @@ func TestDelete_Execute(t *testing.T) {
 		assert.Contains(t, err.Error(), "delete with filters")
 	})
 
+	t.Run("passes full delete and batch options", func(t *testing.T) {
+		ctr := gomock.NewController(t)
+		defer ctr.Finish()
+
+		mockIntegration := NewMockdeleteImplementation(ctr)
+		mockIntegration.EXPECT().Delete(
+			gomock.Any(),
+			map[string]string{
+				"collection":                  "mock_table",
+				filters.AllowFullDeleteOption: "true",
+				filters.BatchSizeOption:       "500",
+			},
+		).Return(nil)
+
+		integration.ReplaceIntegrationType("mock", func(m map[string]any) (integration.Integration, error) {
+			return mockIntegration, nil
+		})
+		require.NoError(t, integration.InitializeIntegration("mock", "mockds", nil, false))
+
+		d, err := New(Config{
+			IntegrationID:   "mockds",
+			Table:           "mock_table",
+			AllowFullDelete: true,
+			BatchSize:       500,
+		})
+		require.NoError(t, err)
+
+		_, _, err = d.Execute(context.Background(), `[]`)
+		require.NoError(t, err)
+	})
+
 	t.Run("invalid config JSON", func(t *testing.T) {
 		ctr := gomock.NewController(t)
 		defer ctr.Finish()
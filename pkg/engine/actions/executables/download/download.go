@@ -134,6 +134,7 @@ func init() {
 	if err := actions.RegisterAction("download", actions.ActionRegistrationInfo{
 		Name:        "Download File",
 		Description: "Saves a file from the request or action output to a specified path",
+		SideEffects: true,
 		Fields:      fields,
 		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
 			var cfg Config
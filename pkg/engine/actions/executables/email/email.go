@@ -132,6 +132,7 @@ func init() {
 	if err := actions.RegisterAction("email", actions.ActionRegistrationInfo{
 		Name:        "Send Email",
 		Description: "Sends email messages via SMTP server",
+		SideEffects: true,
 		Fields:      fields,
 		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
 			var cfg Config
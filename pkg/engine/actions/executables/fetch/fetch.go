@@ -32,15 +32,29 @@ type fetchImplementation interface {
 	Fetch(ctx context.Context, options map[string]string, filters ...filters.Filter) ([]map[string]interface{}, error)
 }
 
+// pageFetcher is implemented by integrations that can report the total
+// number of matches alongside a page of results.
+type pageFetcher interface {
+	FetchPage(ctx context.Context, options map[string]string, filters ...filters.Filter) ([]map[string]interface{}, int64, error)
+}
+
 type Config struct {
 	IntegrationID string           `json:"integrationID" yaml:"integrationID"`
 	Filters       []filters.Filter `json:"filters" yaml:"filters"`
 	Table         string           `json:"table" yaml:"table"`
 	Single        bool             `json:"single" yaml:"single"`
 	FailIfEmpty   bool             `json:"failIfEmpty" yaml:"failIfEmpty"`
+	// DatasourceOptions are passed through to the integration alongside the
+	// table, e.g. {"idFormat": "id"} for mongo.
+	DatasourceOptions map[string]string `json:"datasourceOptions" yaml:"datasourceOptions"`
+	// IncludeTotal returns {"items": [...], "total": n}, where total counts
+	// every match regardless of the limit and offset datasource options.
+	// Single is ignored when it is set.
+	IncludeTotal bool `json:"includeTotal" yaml:"includeTotal"`
 }
 
 func New(config Config) (*Fetch, error) {
+	config.IntegrationID = integration.ResolveID(config.IntegrationID)
 	if config.IntegrationID == "" {
 		return nil, errors.New("datasource is required")
 	}
@@ -56,6 +70,9 @@ func New(config Config) (*Fetch, error) {
 	if !ok {
 		return nil, errors.New("integration is not of type fetchImplementation")
 	}
+	if _, ok := i.(pageFetcher); config.IncludeTotal && !ok {
+		return nil, errors.New("integration does not support includeTotal")
+	}
 	return &Fetch{
 		cfg:               &config,
 		fetchIntegrations: u,
@@ -79,8 +96,23 @@ func (f *Fetch) Execute(ctx context.Context, modifiedConfig string) (interface{}
 		return "", nil, err
 	}
 
+	options := map[string]string{"collection": f.cfg.Table}
+	for k, v := range f.cfg.DatasourceOptions {
+		if k != "collection" {
+			options[k] = v
+		}
+	}
+
+	if f.cfg.IncludeTotal {
+		return f.fetchPage(ctx, options, filters)
+	}
+
 	var ret interface{}
-	resp, err := f.fetchIntegrations.Fetch(ctx, map[string]string{"collection": f.cfg.Table}, filters...)
+	fetch := f.fetchIntegrations.Fetch
+	if c, ok := integration.GetReadCache(f.cfg.IntegrationID); ok {
+		fetch = c.Fetch
+	}
+	resp, err := fetch(ctx, options, filters...)
 	if err != nil {
 		return "", nil, fmt.Errorf("fetch with filters: %v", err)
 	}
@@ -97,6 +129,17 @@ func (f *Fetch) Execute(ctx context.Context, modifiedConfig string) (interface{}
 	return ret, nil, nil
 }
 
+func (f *Fetch) fetchPage(ctx context.Context, options map[string]string, fs []filters.Filter) (interface{}, map[string]string, error) {
+	items, total, err := f.fetchIntegrations.(pageFetcher).FetchPage(ctx, options, fs...)
+	if err != nil {
+		return "", nil, fmt.Errorf("fetch with filters: %v", err)
+	}
+	if len(items) < 1 && f.cfg.FailIfEmpty {
+		return nil, nil, fmt.Errorf("%w: no data found", plan.ErrFailure)
+	}
+	return map[string]interface{}{"items": items, "total": total}, nil, nil
+}
+
 func init() {
 	fields := map[string]actions.FieldInfo{
 		"integrationID": {
@@ -147,6 +190,13 @@ func init() {
 			Required:    false,
 			Default:     true,
 		},
+		"includeTotal": {
+			Type:        actions.FieldTypeBoolean,
+			Label:       "Include Total",
+			Placeholder: "Return the page with the total number of matches",
+			Required:    false,
+			Default:     false,
+		},
 	}
 
 	if err := actions.RegisterAction("fetch", actions.ActionRegistrationInfo{
@@ -3,17 +3,49 @@ package fetch
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
 	"github.com/Servflow/servflow/pkg/engine/integration"
 	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
 	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	_ "github.com/Servflow/servflow/pkg/engine/responses/http"
+	"github.com/Servflow/servflow/pkg/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
 func TestFetch_Execute(t *testing.T) {
+	t.Run("passes datasource options", func(t *testing.T) {
+		ctr := gomock.NewController(t)
+		defer ctr.Finish()
+
+		mockIntegration := NewMockfetchImplementation(ctr)
+		mockIntegration.EXPECT().Fetch(gomock.Any(), map[string]string{"collection": "mock", "idFormat": "id"}, filters.Filter{Field: "id", Comparator: "1"}).
+			Return([]map[string]interface{}{{"id": "1"}}, nil)
+		integration.ReplaceIntegrationType("mock", func(m map[string]any) (integration.Integration, error) {
+			return mockIntegration, nil
+		})
+
+		err := integration.InitializeIntegration("mock", "mockds", nil, false)
+		require.NoError(t, err)
+
+		fetch, err := New(Config{
+			Table:             "mock",
+			IntegrationID:     "mockds",
+			Filters:           []filters.Filter{{Field: "id", Comparator: "1"}},
+			DatasourceOptions: map[string]string{"idFormat": "id", "collection": "ignored"},
+		})
+		require.NoError(t, err)
+
+		_, _, err = fetch.Execute(context.Background(), fetch.Config())
+		require.NoError(t, err)
+	})
+
 	t.Run("successful run", func(t *testing.T) {
 		ctr := gomock.NewController(t)
 		defer ctr.Finish()
@@ -106,3 +138,95 @@ func TestFetch_Execute(t *testing.T) {
 		assert.True(t, errors.Is(err, plan.ErrFailure), "Expected failure error to be wrapped with plan.ErrFailure")
 	})
 }
+
+type pagedIntegration struct {
+	*MockfetchImplementation
+	options map[string]string
+}
+
+func (p *pagedIntegration) FetchPage(_ context.Context, options map[string]string, _ ...filters.Filter) ([]map[string]interface{}, int64, error) {
+	p.options = options
+	return []map[string]interface{}{{"id": "3"}, {"id": "4"}}, 7, nil
+}
+
+func TestFetch_IncludeTotal(t *testing.T) {
+	ctr := gomock.NewController(t)
+	defer ctr.Finish()
+
+	paged := &pagedIntegration{MockfetchImplementation: NewMockfetchImplementation(ctr)}
+	integration.ReplaceIntegrationType("paged", func(m map[string]any) (integration.Integration, error) {
+		return paged, nil
+	})
+	require.NoError(t, integration.InitializeIntegration("paged", "pagedds", nil, false))
+
+	fetch, err := New(Config{
+		Table:             "users",
+		IntegrationID:     "pagedds",
+		IncludeTotal:      true,
+		DatasourceOptions: map[string]string{"limit": "2", "offset": "2"},
+	})
+	require.NoError(t, err)
+
+	resp, _, err := fetch.Execute(context.Background(), fetch.Config())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"items": []map[string]interface{}{{"id": "3"}, {"id": "4"}},
+		"total": int64(7),
+	}, resp)
+	assert.Equal(t, map[string]string{"collection": "users", "limit": "2", "offset": "2"}, paged.options)
+
+	t.Run("integration without FetchPage", func(t *testing.T) {
+		mockIntegration := NewMockfetchImplementation(ctr)
+		integration.ReplaceIntegrationType("mock", func(m map[string]any) (integration.Integration, error) {
+			return mockIntegration, nil
+		})
+		require.NoError(t, integration.InitializeIntegration("mock", "plainds", nil, false))
+
+		_, err := New(Config{Table: "users", IntegrationID: "plainds", IncludeTotal: true})
+		assert.ErrorContains(t, err, "does not support includeTotal")
+	})
+}
+
+func TestFetch_Fallback(t *testing.T) {
+	ctr := gomock.NewController(t)
+	defer ctr.Finish()
+
+	mockIntegration := NewMockfetchImplementation(ctr)
+	mockIntegration.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("connection refused"))
+	integration.ReplaceIntegrationType("mock", func(m map[string]any) (integration.Integration, error) {
+		return mockIntegration, nil
+	})
+	require.NoError(t, integration.InitializeIntegration("mock", "mockds", nil, false))
+
+	planner := plan.NewPlannerV2(plan.PlannerConfig{
+		Actions: map[string]apiconfig.Action{
+			"recommendations": {
+				Name:   "recommendations",
+				Type:   "fetch",
+				Config: map[string]interface{}{"integrationID": "mockds", "table": "recommendations"},
+				Next:   "response.ok",
+				Fallback: &apiconfig.Fallback{
+					Value: []interface{}{map[string]interface{}{"id": "bestseller"}},
+				},
+			},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"ok": {
+				Name:     "ok",
+				Code:     200,
+				Type:     "template",
+				Template: `{{ (index .variable_actions_recommendations 0).id }}`,
+			},
+		},
+	}, logging.GetNewLogger())
+	p, err := planner.Plan()
+	require.NoError(t, err)
+
+	resp, err := p.Execute(requestctx.NewTestContext(), apiconfig.ActionConfigPrefix+"recommendations")
+	require.NoError(t, err)
+	sfResp, ok := resp.(*sfhttp.SfResponse)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusOK, sfResp.Code)
+	assert.Equal(t, "bestseller", string(sfResp.Body))
+}
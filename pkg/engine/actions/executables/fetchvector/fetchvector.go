@@ -67,6 +67,7 @@ func (f FetchVector) Execute(ctx context.Context, modifiedConfig string) (interf
 }
 
 func New(config Config) (*FetchVector, error) {
+	config.IntegrationID = integration.ResolveID(config.IntegrationID)
 	if config.IntegrationID == "" {
 		return nil, fmt.Errorf("no integration ID provided")
 	}
@@ -85,3 +85,21 @@ func TestFetchVector_Execute(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestNew_DefaultIntegration(t *testing.T) {
+	ctr := gomock.NewController(t)
+	defer ctr.Finish()
+
+	mockIntegration := NewMockfetchVectorIntegration(ctr)
+	integration.ReplaceIntegrationType("mockdefault", func(m map[string]any) (integration.Integration, error) {
+		return mockIntegration, nil
+	})
+	require.NoError(t, integration.InitializeIntegration("mockdefault", "defaultvectors", nil, false))
+
+	integration.SetDefaultIntegration("defaultvectors")
+	defer integration.SetDefaultIntegration("")
+
+	f, err := New(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, mockIntegration, f.fetchIntegration)
+}
@@ -98,6 +98,7 @@ func init() {
 	if err := actions.RegisterAction("firestore", actions.ActionRegistrationInfo{
 		Name:        "Firestore",
 		Description: "Stores documents in Google Cloud Firestore database",
+		SideEffects: true,
 		Fields:      fields,
 		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
 			var cfg Config
@@ -0,0 +1,110 @@
+package generateid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/google/uuid"
+)
+
+const (
+	FormatUUID   = "uuid"
+	FormatRandom = "random"
+
+	defaultLength = 32
+)
+
+type Config struct {
+	// Format is either "uuid" (the default) or "random" for a URL-safe random
+	// string.
+	Format string `json:"format" yaml:"format"`
+	// Length is the number of characters of a random id. Ignored for uuids.
+	Length int `json:"length" yaml:"length"`
+}
+
+// GenerateID produces a fresh identifier or token each time it runs. The
+// value is the action's output, so later steps read it as
+// {{ .variable_actions_<id> }}.
+type GenerateID struct {
+	format string
+	length int
+}
+
+func (g *GenerateID) Type() string {
+	return "generateid"
+}
+
+func (g *GenerateID) SupportsReplica() bool {
+	return true
+}
+
+func New(cfg Config) (*GenerateID, error) {
+	g := &GenerateID{format: cfg.Format, length: cfg.Length}
+	if g.format == "" {
+		g.format = FormatUUID
+	}
+	switch g.format {
+	case FormatUUID:
+	case FormatRandom:
+		if g.length == 0 {
+			g.length = defaultLength
+		}
+		if g.length < 0 {
+			return nil, fmt.Errorf("length must be positive, got %d", g.length)
+		}
+		if g.length > requestctx.MaxRandomStringLength {
+			return nil, fmt.Errorf("length must be at most %d, got %d", requestctx.MaxRandomStringLength, g.length)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported id format: %s", g.format)
+	}
+	return g, nil
+}
+
+func (g *GenerateID) Execute(ctx context.Context) (interface{}, map[string]string, error) {
+	if g.format == FormatUUID {
+		return uuid.NewString(), nil, nil
+	}
+	id, err := requestctx.RandomString(g.length)
+	if err != nil {
+		return nil, nil, err
+	}
+	return id, nil, nil
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"format": {
+			Type:        actions.FieldTypeString,
+			Label:       "Format",
+			Placeholder: "uuid or random",
+			Default:     FormatUUID,
+			Values:      []string{FormatUUID, FormatRandom},
+		},
+		"length": {
+			Type:        actions.FieldTypeNumber,
+			Label:       "Length",
+			Placeholder: "Number of characters for random ids",
+			Default:     defaultLength,
+		},
+	}
+
+	if err := actions.RegisterAction("generateid", actions.ActionRegistrationInfo{
+		Name:        "Generate ID",
+		Description: "Generates a UUID or a cryptographically random URL-safe string for use as an identifier or token",
+		Fields:      fields,
+		UseV2:       true,
+		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
+			var cfg Config
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating generateid action: %v", err)
+			}
+			return New(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
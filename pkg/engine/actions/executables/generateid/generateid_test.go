@@ -0,0 +1,61 @@
+package generateid
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateID_Execute(t *testing.T) {
+	t.Run("uuid by default", func(t *testing.T) {
+		g, err := New(Config{})
+		require.NoError(t, err)
+
+		first, _, err := g.Execute(context.Background())
+		require.NoError(t, err)
+		second, _, err := g.Execute(context.Background())
+		require.NoError(t, err)
+
+		_, err = uuid.Parse(first.(string))
+		assert.NoError(t, err)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("random string of requested length", func(t *testing.T) {
+		g, err := New(Config{Format: FormatRandom, Length: 20})
+		require.NoError(t, err)
+
+		first, _, err := g.Execute(context.Background())
+		require.NoError(t, err)
+		second, _, err := g.Execute(context.Background())
+		require.NoError(t, err)
+
+		assert.Len(t, first, 20)
+		assert.Regexp(t, regexp.MustCompile(`^[A-Za-z0-9_-]+$`), first)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("random string default length", func(t *testing.T) {
+		g, err := New(Config{Format: FormatRandom})
+		require.NoError(t, err)
+		id, _, err := g.Execute(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, id, defaultLength)
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Format: "snowflake"})
+	assert.ErrorContains(t, err, "unsupported id format")
+
+	_, err = New(Config{Format: FormatRandom, Length: -1})
+	assert.ErrorContains(t, err, "length must be positive")
+
+	_, err = New(Config{Format: FormatRandom, Length: requestctx.MaxRandomStringLength + 1})
+	assert.ErrorContains(t, err, "length must be at most")
+}
@@ -1,18 +1,24 @@
 package http
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Servflow/servflow/pkg/engine/actions"
 	"github.com/Servflow/servflow/pkg/engine/plan"
 	"github.com/Servflow/servflow/pkg/engine/requestctx"
 	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/Servflow/servflow/pkg/tracing"
 	"go.uber.org/zap"
 
 	"github.com/tidwall/gjson"
@@ -53,6 +59,36 @@ type Config struct {
 	ResponsePath         string            `json:"responsePath" yaml:"responsePath"`
 	ExpectedResponseCode string            `json:"expectedResponseCode" yaml:"expectedResponseCode"`
 	FailIfResponseEmpty  bool              `json:"failIfResponseEmpty" yaml:"failIfResponseEmpty"`
+	// Retry, when set, sends the request again on failures it classifies as
+	// retriable.
+	Retry *RetryConfig `json:"retry,omitempty" yaml:"retry,omitempty"`
+	// ConnectTimeout bounds connecting to the upstream, TLS handshake
+	// included, e.g. "2s"; it fails with plan.ErrConnectTimeout. Unset uses
+	// the endpoint's connect timeout, if any.
+	ConnectTimeout string `json:"connectTimeout,omitempty" yaml:"connectTimeout,omitempty"`
+	// Timeout bounds the whole exchange, retries and reading the response
+	// included, e.g. "30s"; it fails with plan.ErrTimeout.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// CaptureResponse returns the whole response instead of its body: an
+	// object of status, headers (each a list of values) and body, the raw
+	// text. A passthrough response writes it back to the client unchanged.
+	// ResponsePath is ignored.
+	CaptureResponse bool `json:"captureResponse,omitempty" yaml:"captureResponse,omitempty"`
+}
+
+func (c *Config) validate() error {
+	for field, v := range map[string]string{"connectTimeout": c.ConnectTimeout, "timeout": c.Timeout} {
+		if v == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+			return fmt.Errorf("invalid %s %q: must be a positive duration", field, v)
+		}
+	}
+	if c.Retry != nil {
+		return c.Retry.validate()
+	}
+	return nil
 }
 
 func New(cfg Config) *Http {
@@ -111,37 +147,37 @@ func (h *Http) Execute(ctx context.Context) (interface{}, map[string]string, err
 		cfg.Headers = headers
 	}
 
-	var body io.Reader
+	var body string
 	if hasBody {
-		body = bytes.NewBufferString(next())
+		body = next()
 	}
 
-	req, err := http.NewRequestWithContext(ctx, cfg.Method, cfg.URL, body)
-	if err != nil {
-		return nil, nil, err
+	parent := ctx
+	if h.cfg.Timeout != "" {
+		timeout, _ := time.ParseDuration(h.cfg.Timeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	if len(cfg.Headers) > 0 {
-		for k, v := range cfg.Headers {
-			req.Header.Set(k, v)
-		}
-	}
-
-	resp, err := h.client.Do(req)
+	resp, attempts, err := h.do(ctx, cfg, hasBody, body)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, timeoutError(parent, ctx, err)
 	}
 
 	defer resp.Body.Close()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, timeoutError(parent, ctx, err)
 	}
 
 	fields := map[string]string{}
 	fields["status_code"] = strconv.Itoa(resp.StatusCode)
 	fields["response_body"] = string(bodyBytes)
+	if cfg.Retry != nil {
+		fields["attempts"] = strconv.Itoa(attempts)
+	}
 
 	// Scrub the URL explicitly (a secret can ride in a query param); the
 	// context logger's scrub core also covers the body if it echoes a secret.
@@ -158,6 +194,10 @@ func (h *Http) Execute(ctx context.Context) (interface{}, map[string]string, err
 		return nil, fields, fmt.Errorf("%w: response body is empty", plan.ErrFailure)
 	}
 
+	if cfg.CaptureResponse {
+		return capturedResponse(resp, bodyBytes), fields, nil
+	}
+
 	if cfg.ResponsePath == "" {
 		var result interface{}
 		if err := json.Unmarshal(bodyBytes, &result); err != nil {
@@ -178,6 +218,115 @@ func (h *Http) Execute(ctx context.Context) (interface{}, map[string]string, err
 	return value.Value(), fields, nil
 }
 
+// capturedResponse is the output of an action with CaptureResponse set.
+func capturedResponse(resp *http.Response, body []byte) map[string]interface{} {
+	headers := make(map[string]interface{}, len(resp.Header))
+	for k, values := range resp.Header {
+		list := make([]interface{}, len(values))
+		for i, v := range values {
+			list[i] = v
+		}
+		headers[k] = list
+	}
+	return map[string]interface{}{
+		"status":  resp.StatusCode,
+		"headers": headers,
+		"body":    string(body),
+	}
+}
+
+// timeoutError marks err with plan.ErrTimeout when ctx, the context the
+// request ran under, hit the action's own deadline rather than being canceled
+// through parent.
+func timeoutError(parent, ctx context.Context, err error) error {
+	if errors.Is(err, plan.ErrConnectTimeout) {
+		return err
+	}
+	if parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", plan.ErrTimeout, err)
+	}
+	return err
+}
+
+// connectTimeoutError marks a timeout that happened before a connection to
+// the upstream was established, and not because ctx expired, with
+// plan.ErrConnectTimeout.
+func connectTimeoutError(ctx context.Context, connected bool, err error) error {
+	var netErr net.Error
+	if connected || ctx.Err() != nil || !errors.As(err, &netErr) || !netErr.Timeout() {
+		return err
+	}
+	return fmt.Errorf("%w: %w", plan.ErrConnectTimeout, err)
+}
+
+// clientFor returns the client that enforces the connect timeout in effect
+// for ctx.
+func (h *Http) clientFor(ctx context.Context) *http.Client {
+	timeout := plan.ConnectTimeoutFromContext(ctx)
+	if h.cfg.ConnectTimeout != "" {
+		timeout, _ = time.ParseDuration(h.cfg.ConnectTimeout)
+	}
+	if timeout <= 0 {
+		return h.client
+	}
+	if c, ok := connectClients.Load(timeout); ok {
+		return c.(*http.Client)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = timeout
+	c, _ := connectClients.LoadOrStore(timeout, &http.Client{Transport: transport})
+	return c.(*http.Client)
+}
+
+// connectClients holds a client per connect timeout, so connections are
+// pooled across requests.
+var connectClients sync.Map
+
+// do sends the request, retrying as cfg.Retry allows, and returns the last
+// response along with the number of attempts made.
+func (h *Http) do(ctx context.Context, cfg Config, hasBody bool, body string) (*http.Response, int, error) {
+	logger := logging.FromContext(ctx)
+	client := h.clientFor(ctx)
+	for attempt := 1; ; attempt++ {
+		var reqBody io.Reader
+		if hasBody {
+			reqBody = strings.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, cfg.Method, cfg.URL, reqBody)
+		if err != nil {
+			return nil, attempt, err
+		}
+		for k, v := range cfg.Headers {
+			req.Header.Set(k, v)
+		}
+		tracing.InjectHTTPHeaders(ctx, req.Header)
+
+		var connected bool
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+			GotConn: func(httptrace.GotConnInfo) { connected = true },
+		}))
+		resp, err := client.Do(req)
+		if err != nil {
+			err = connectTimeoutError(ctx, connected, err)
+		}
+		if cfg.Retry == nil || attempt >= cfg.Retry.MaxAttempts || !cfg.Retry.retriable(ctx, resp, err) {
+			return resp, attempt, err
+		}
+
+		if err != nil {
+			logger.Debug("retrying http request", zap.Int("attempt", attempt), zap.Error(err))
+		} else {
+			logger.Debug("retrying http request", zap.Int("attempt", attempt), zap.Int("status", resp.StatusCode))
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := cfg.Retry.wait(ctx, attempt); err != nil {
+			return nil, attempt, err
+		}
+	}
+}
+
 func init() {
 	fields := map[string]actions.FieldInfo{
 		"url": {
@@ -222,6 +371,30 @@ func init() {
 			Required:    false,
 			Default:     "",
 		},
+		"retry": {
+			Type:        actions.FieldTypeMap,
+			Label:       "Retry",
+			Placeholder: "maxAttempts, backoff, statusCodes and errors to retry",
+			Required:    false,
+		},
+		"connectTimeout": {
+			Type:        actions.FieldTypeString,
+			Label:       "Connect Timeout",
+			Placeholder: "Time allowed to connect to the upstream, e.g. 2s",
+			Required:    false,
+		},
+		"timeout": {
+			Type:        actions.FieldTypeString,
+			Label:       "Timeout",
+			Placeholder: "Time allowed for the whole request, e.g. 30s",
+			Required:    false,
+		},
+		"captureResponse": {
+			Type:        actions.FieldTypeBoolean,
+			Label:       "Capture Response",
+			Placeholder: "Return the status, headers and body for a passthrough response",
+			Required:    false,
+		},
 		"failIfResponseEmpty": {
 			Type:        actions.FieldTypeBoolean,
 			Label:       "Fail if Response Empty",
@@ -234,6 +407,7 @@ func init() {
 	if err := actions.RegisterAction("http", actions.ActionRegistrationInfo{
 		Name:        "HTTP Request",
 		Description: "Makes HTTP requests to external APIs and returns the response",
+		SideEffects: true,
 		Fields:      fields,
 		UseV2:       true,
 		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
@@ -241,6 +415,9 @@ func init() {
 			if err := json.Unmarshal(config, &cfg); err != nil {
 				return nil, fmt.Errorf("error creating http action: %v", err)
 			}
+			if err := cfg.validate(); err != nil {
+				return nil, fmt.Errorf("error creating http action: %v", err)
+			}
 			return New(cfg), nil
 		},
 	}); err != nil {
@@ -6,10 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	sfhttp "github.com/Servflow/servflow/internal/http"
 	"github.com/Servflow/servflow/pkg/apiconfig"
 	"github.com/Servflow/servflow/pkg/engine/plan"
 	"github.com/Servflow/servflow/pkg/engine/requestctx"
@@ -511,6 +514,26 @@ func TestHeaderPairing(t *testing.T) {
 	}
 }
 
+func TestRequestIDHeader(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	defer srv.Close()
+
+	ctx, rc := requestctx.Start(context.Background(), requestctx.Options{ID: "request_42"})
+	defer rc.Done()
+
+	_, _, err := New(Config{URL: srv.URL, Method: "GET"}).Execute(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "request_42", got.Get("X-Request-ID"))
+
+	_, _, err = New(Config{URL: srv.URL, Method: "GET", Headers: map[string]string{"X-Request-ID": "upstream"}}).Execute(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "upstream", got.Get("X-Request-ID"), "a configured header is kept")
+}
+
 // TestHTTPActionSecretsOnWireTrackedForScrubbing is the end-to-end check for
 // the scrub-gateway secret model: the outbound request (URL query, header,
 // body) carries the REAL secret value, and from the moment of resolution the
@@ -559,3 +582,199 @@ func TestHTTPActionSecretsOnWireTrackedForScrubbing(t *testing.T) {
 	// (the PLAN runner scrubs before storing); sanity-check shape only.
 	require.IsType(t, map[string]interface{}{}, resp)
 }
+
+func TestHttp_Retry(t *testing.T) {
+	// flaky answers with failStatus until it has been called failures times.
+	flaky := func(t *testing.T, failStatus, failures int) (string, *int) {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			bod, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"foo": "bar"}`, string(bod), "body is resent on every attempt")
+			if calls <= failures {
+				w.WriteHeader(failStatus)
+				return
+			}
+			w.Write([]byte(`{"ok": true}`))
+		}))
+		t.Cleanup(srv.Close)
+		return srv.URL, &calls
+	}
+
+	t.Run("configured retriable 409 is retried", func(t *testing.T) {
+		url, calls := flaky(t, http.StatusConflict, 2)
+		h := New(Config{
+			URL: url, Method: http.MethodPost, Body: json.RawMessage(`{"foo":"bar"}`),
+			ExpectedResponseCode: "200",
+			Retry:                &RetryConfig{MaxAttempts: 3, Backoff: "1ms", StatusCodes: []int{409}},
+		})
+
+		resp, _, err := h.Execute(requestctx.NewTestContext())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"ok": true}, resp)
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("unclassified 500 is not retried", func(t *testing.T) {
+		url, calls := flaky(t, http.StatusInternalServerError, 2)
+		h := New(Config{
+			URL: url, Method: http.MethodPost, Body: json.RawMessage(`{"foo":"bar"}`),
+			ExpectedResponseCode: "200",
+			Retry:                &RetryConfig{MaxAttempts: 3, Backoff: "1ms", StatusCodes: []int{409}},
+		})
+
+		_, fields, err := h.Execute(requestctx.NewTestContext())
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plan.ErrFailure))
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, "1", fields["attempts"])
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		url, calls := flaky(t, http.StatusServiceUnavailable, 5)
+		h := New(Config{
+			URL: url, Method: http.MethodPost, Body: json.RawMessage(`{"foo":"bar"}`),
+			ExpectedResponseCode: "200",
+			Retry:                &RetryConfig{MaxAttempts: 2, Backoff: "1ms"},
+		})
+
+		_, fields, err := h.Execute(requestctx.NewTestContext())
+		require.Error(t, err)
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, "2", fields["attempts"])
+		assert.Equal(t, "503", fields["status_code"])
+	})
+
+	t.Run("transport errors match configured substrings", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		url := srv.URL
+		srv.Close()
+
+		retry := &RetryConfig{MaxAttempts: 3, Backoff: "1ms", Errors: []string{"connection refused"}}
+		_, err := http.Get(url)
+		require.Error(t, err)
+		assert.True(t, retry.retriable(context.Background(), nil, err))
+		assert.False(t, (&RetryConfig{MaxAttempts: 3, Errors: []string{"timeout"}}).retriable(context.Background(), nil, err))
+	})
+
+	t.Run("invalid retry config is rejected", func(t *testing.T) {
+		assert.ErrorContains(t, (&RetryConfig{}).validate(), "maxAttempts")
+		assert.ErrorContains(t, (&RetryConfig{MaxAttempts: 2, Backoff: "soon"}).validate(), "invalid retry backoff")
+	})
+}
+
+// stalledTLSServer accepts TCP connections but never answers the TLS
+// handshake, like an upstream that is slow to connect.
+func stalledTLSServer(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+		ln.Close()
+	})
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				<-done
+				conn.Close()
+			}()
+		}
+	}()
+	return "https://" + ln.Addr().String()
+}
+
+func TestHttp_Timeouts(t *testing.T) {
+	ctx, rc := requestctx.Start(context.Background(), requestctx.Options{})
+	defer rc.Done()
+
+	t.Run("slow to connect fails with a connect timeout", func(t *testing.T) {
+		_, _, err := New(Config{URL: stalledTLSServer(t), Method: "GET", ConnectTimeout: "50ms", Timeout: "5s"}).Execute(ctx)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, plan.ErrConnectTimeout)
+		assert.NotErrorIs(t, err, plan.ErrTimeout)
+	})
+
+	t.Run("the endpoint's connect timeout applies when unset", func(t *testing.T) {
+		_, _, err := New(Config{URL: stalledTLSServer(t), Method: "GET"}).Execute(plan.WithConnectTimeout(ctx, 50*time.Millisecond))
+		assert.ErrorIs(t, err, plan.ErrConnectTimeout)
+	})
+
+	t.Run("slow to respond fails with a total timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}))
+		defer srv.Close()
+
+		_, _, err := New(Config{URL: srv.URL, Method: "GET", ConnectTimeout: "1s", Timeout: "50ms"}).Execute(ctx)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, plan.ErrTimeout)
+		assert.NotErrorIs(t, err, plan.ErrConnectTimeout)
+	})
+
+	t.Run("invalid durations are rejected", func(t *testing.T) {
+		assert.Error(t, (&Config{Timeout: "soon"}).validate())
+		assert.Error(t, (&Config{ConnectTimeout: "-1s"}).validate())
+	})
+}
+
+func TestHTTPActionPassthroughViaPlanExecute(t *testing.T) {
+	upstreamBody := "<p>not json</p>\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("X-Upstream", "yes")
+		w.Header().Set("X-Internal", "secret")
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(upstreamBody))
+	}))
+	defer srv.Close()
+
+	planner := plan.NewPlannerV2(plan.PlannerConfig{
+		Actions: map[string]apiconfig.Action{
+			"proxy": {
+				Type: "http",
+				Config: map[string]interface{}{
+					"url":             srv.URL,
+					"method":          "GET",
+					"captureResponse": true,
+				},
+				Next: "response.passthrough",
+			},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"passthrough": {
+				Name: "passthrough",
+				Code: http.StatusOK,
+				Type: "passthrough",
+				Passthrough: apiconfig.PassthroughConfig{
+					Response: "{{ .proxy }}",
+					Headers:  []string{"content-type", "X-Upstream", "Set-Cookie", "Connection"},
+				},
+			},
+		},
+	}, logging.GetNewLogger())
+	p, err := planner.Plan()
+	require.NoError(t, err)
+
+	result, err := p.Execute(requestctx.NewTestContext(), apiconfig.ActionConfigPrefix+"proxy")
+	require.NoError(t, err)
+
+	resp, ok := result.(*sfhttp.SfResponse)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusTeapot, resp.Code)
+	assert.Equal(t, upstreamBody, string(resp.Body))
+	assert.Equal(t, "text/html", resp.Headers.Get("Content-Type"))
+	assert.Equal(t, "yes", resp.Headers.Get("X-Upstream"))
+	assert.Equal(t, []string{"a=1", "b=2"}, resp.Headers.Values("Set-Cookie"))
+	assert.Empty(t, resp.Headers.Get("X-Internal"), "unselected headers are not passed through")
+	assert.Empty(t, resp.Headers.Get("Connection"))
+}
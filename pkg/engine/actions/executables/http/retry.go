@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultRetryBackoff = 200 * time.Millisecond
+
+// defaultRetryStatusCodes are retried when a retry config lists neither
+// status codes nor errors.
+var defaultRetryStatusCodes = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// RetryConfig controls which failed requests are sent again. Only what it
+// classifies as retriable is retried: a 409 can be made retriable for a flow
+// that races on optimistic locks, while a 500 that is not listed fails at
+// once. When neither StatusCodes nor Errors is set, 429, 502, 503, 504 and
+// all transport errors are retried.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts, the first included.
+	MaxAttempts int `json:"maxAttempts" yaml:"maxAttempts"`
+	// Backoff is the wait before the first retry, doubled for each retry
+	// after it. Defaults to 200ms.
+	Backoff string `json:"backoff" yaml:"backoff"`
+	// StatusCodes are the response codes that are retried.
+	StatusCodes []int `json:"statusCodes" yaml:"statusCodes"`
+	// Errors are substrings of transport errors (e.g. "connection refused",
+	// "EOF") that are retried.
+	Errors []string `json:"errors" yaml:"errors"`
+}
+
+func (r *RetryConfig) validate() error {
+	if r.MaxAttempts < 1 {
+		return fmt.Errorf("retry maxAttempts must be at least 1, got %d", r.MaxAttempts)
+	}
+	if r.Backoff != "" {
+		if d, err := time.ParseDuration(r.Backoff); err != nil || d < 0 {
+			return fmt.Errorf("invalid retry backoff %q", r.Backoff)
+		}
+	}
+	return nil
+}
+
+func (r *RetryConfig) backoff(attempt int) time.Duration {
+	base := defaultRetryBackoff
+	if r.Backoff != "" {
+		base, _ = time.ParseDuration(r.Backoff)
+	}
+	return base << (attempt - 1)
+}
+
+// retriable reports whether the outcome of an attempt is classified as
+// retriable. Cancellation of the request itself never is.
+func (r *RetryConfig) retriable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			return false
+		}
+		if len(r.StatusCodes) == 0 && len(r.Errors) == 0 {
+			return true
+		}
+		return slices.ContainsFunc(r.Errors, func(s string) bool {
+			return strings.Contains(err.Error(), s)
+		})
+	}
+	codes := r.StatusCodes
+	if len(codes) == 0 && len(r.Errors) == 0 {
+		codes = defaultRetryStatusCodes
+	}
+	return slices.Contains(codes, resp.StatusCode)
+}
+
+// wait sleeps before retry number attempt, returning early with ctx's error
+// when the request is canceled.
+func (r *RetryConfig) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(r.backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
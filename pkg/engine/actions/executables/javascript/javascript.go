@@ -122,7 +122,11 @@ func getRequestBodyAndParams(ctx context.Context) (string, map[string]string) {
 		return requestBody, params
 	}
 
-	requestBody = requestctx.ReadAndRestoreBody(req)
+	if body, ok := requestctx.GetRawBody(ctx); ok {
+		requestBody = string(body)
+	} else {
+		requestBody = requestctx.ReadAndRestoreBody(req)
+	}
 	params = getRequestParams(req)
 
 	return requestBody, params
@@ -0,0 +1,222 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+type AuthConfig struct {
+	// Key is the HMAC secret or PEM-encoded public key tokens are signed
+	// with, usually {{ secret "jwt_key" }}.
+	Key string `json:"key" yaml:"key"`
+	// JwksURL is the JSON Web Key Set of an external identity provider, used
+	// instead of Key. The key is picked by the token's kid.
+	JwksURL string `json:"jwksURL" yaml:"jwksURL"`
+	// Issuer and Audience, when set, must match the token's iss and aud.
+	Issuer   string `json:"issuer" yaml:"issuer"`
+	Audience string `json:"audience" yaml:"audience"`
+	// Header carries the token as "Bearer <token>". Defaults to Authorization.
+	Header string `json:"header" yaml:"header"`
+	// Algorithm is the only one tokens may be signed with, e.g. "RS384".
+	// Empty infers it from Key: RS256, ES256/384/512 or EdDSA for a public
+	// key, else HS256. With JwksURL it defaults to any asymmetric algorithm.
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+}
+
+// asymmetricAlgorithms are accepted for JWKS keys when no algorithm is set.
+var asymmetricAlgorithms = []string{
+	"RS256", "RS384", "RS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// errRefreshToken rejects the refresh tokens the authenticate action issues,
+// which carry typ "refresh": only access tokens may authenticate a request.
+var errRefreshToken = errors.New("refresh tokens cannot be used as access tokens")
+
+// isRefreshToken reports whether claims belong to a refresh token.
+func isRefreshToken(claims jwt.MapClaims) bool {
+	return claims["typ"] == "refresh"
+}
+
+// Auth validates the bearer token of the incoming request and outputs its
+// claims. A missing, expired or otherwise invalid token, or one without an
+// exp claim, fails the action so the flow takes its fail branch.
+type Auth struct {
+	cfg AuthConfig
+}
+
+func (a *Auth) Type() string {
+	return "jwtauth"
+}
+
+func (a *Auth) SupportsReplica() bool {
+	return false
+}
+
+func NewAuth(cfg AuthConfig) (*Auth, error) {
+	if cfg.Key == "" && cfg.JwksURL == "" {
+		return nil, errors.New("either key or jwksURL is required")
+	}
+	if cfg.Header == "" {
+		cfg.Header = "Authorization"
+	}
+	if cfg.Algorithm != "" {
+		if _, err := parseAlgorithm(cfg.Algorithm); err != nil {
+			return nil, err
+		}
+	}
+	return &Auth{cfg: cfg}, nil
+}
+
+func (a *Auth) Execute(ctx context.Context) (interface{}, map[string]string, error) {
+	logger := logging.FromContext(ctx).With(zap.String("execution_type", a.Type()))
+
+	keyFunc, methods, err := a.keyFunc(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := plan.RequestFromContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokenString, err := bearerToken(req, a.cfg.Header)
+	if err != nil {
+		logger.Debug("bearer token rejected", zap.Error(err))
+		return nil, nil, fmt.Errorf("%w: %v", plan.ErrFailure, err)
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if a.cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.cfg.Issuer))
+	}
+	if a.cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(a.cfg.Audience))
+	}
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(tokenString, claims, keyFunc, opts...); err != nil {
+		logger.Debug("bearer token rejected", zap.Error(err))
+		return nil, nil, fmt.Errorf("%w: %v", plan.ErrFailure, err)
+	}
+	if isRefreshToken(claims) {
+		logger.Debug("bearer token rejected", zap.Error(errRefreshToken))
+		return nil, nil, fmt.Errorf("%w: %v", plan.ErrFailure, errRefreshToken)
+	}
+	return map[string]interface{}(claims), nil, nil
+}
+
+// keyFunc returns the key for verifying tokens and the algorithms they may be
+// signed with.
+func (a *Auth) keyFunc(ctx context.Context) (jwt.Keyfunc, []string, error) {
+	if a.cfg.JwksURL != "" {
+		keyFunc, err := jwksKeyfunc(a.cfg.JwksURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		if a.cfg.Algorithm != "" {
+			return keyFunc, []string{a.cfg.Algorithm}, nil
+		}
+		return keyFunc, asymmetricAlgorithms, nil
+	}
+	rc, err := requestctx.FromContextOrError(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get request context: %w", err)
+	}
+	key, err := rc.Resolve(ctx, a.cfg.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve jwtauth key: %w", err)
+	}
+	if key == "" {
+		return nil, nil, errors.New("key resolved to an empty value")
+	}
+	alg := a.cfg.Algorithm
+	if alg == "" {
+		if alg, err = publicKeyAlgorithm([]byte(key)); err != nil {
+			return nil, nil, err
+		}
+	}
+	return verificationKey([]byte(key), alg, true), []string{alg}, nil
+}
+
+// bearerToken returns the token of a "Bearer <token>" header.
+func bearerToken(req *http.Request, header string) (string, error) {
+	value := req.Header.Get(header)
+	if value == "" {
+		return "", fmt.Errorf("missing %s header", header)
+	}
+	scheme, token, ok := strings.Cut(value, " ")
+	token = strings.TrimSpace(token)
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", fmt.Errorf("%s header is not a bearer token", header)
+	}
+	return token, nil
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"key": {
+			Type:        actions.FieldTypeString,
+			Label:       "Key",
+			Placeholder: `{{ secret "jwt_key" }}`,
+			Required:    false,
+		},
+		"jwksURL": {
+			Type:        actions.FieldTypeString,
+			Label:       "JWKS URL",
+			Placeholder: "URL to fetch JSON Web Key Set for verification",
+			Required:    false,
+		},
+		"issuer": {
+			Type:        actions.FieldTypeString,
+			Label:       "Issuer",
+			Placeholder: "Expected iss claim",
+			Required:    false,
+		},
+		"audience": {
+			Type:        actions.FieldTypeString,
+			Label:       "Audience",
+			Placeholder: "Expected aud claim",
+			Required:    false,
+		},
+		"header": {
+			Type:        actions.FieldTypeString,
+			Label:       "Header",
+			Placeholder: "Authorization",
+			Required:    false,
+		},
+		"algorithm": {
+			Type:        actions.FieldTypeString,
+			Label:       "Algorithm",
+			Placeholder: "HS256, RS256, ES256, EdDSA... (inferred from the key when empty)",
+			Required:    false,
+		},
+	}
+
+	if err := actions.RegisterAction("jwtauth", actions.ActionRegistrationInfo{
+		Name:        "JWT Authentication",
+		Description: "Validates the bearer token of the request and outputs its claims",
+		Fields:      fields,
+		UseV2:       true,
+		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
+			var cfg AuthConfig
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating jwtauth action: %v", err)
+			}
+			return NewAuth(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
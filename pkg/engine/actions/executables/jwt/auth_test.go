@@ -0,0 +1,137 @@
+package jwt
+
+import (
+	"crypto/elliptic"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth_Execute(t *testing.T) {
+	_, err := NewAuth(AuthConfig{})
+	assert.EqualError(t, err, "either key or jwksURL is required")
+
+	sign := func(claims jwt.MapClaims, key string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+		require.NoError(t, err)
+		return token
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := map[string]struct {
+		header string
+		token  string
+		err    bool
+	}{
+		"valid": {
+			token: sign(jwt.MapClaims{"sub": "user-1", "iss": "servflow", "aud": "api", "exp": exp}, "s3cret"),
+		},
+		"custom header, lower-case scheme": {
+			header: "X-Access-Token",
+			token:  sign(jwt.MapClaims{"sub": "user-1", "iss": "servflow", "aud": "api", "exp": exp}, "s3cret"),
+		},
+		"wrong issuer": {
+			token: sign(jwt.MapClaims{"sub": "user-1", "iss": "other", "aud": "api", "exp": exp}, "s3cret"),
+			err:   true,
+		},
+		"wrong audience": {
+			token: sign(jwt.MapClaims{"sub": "user-1", "iss": "servflow", "aud": "admin", "exp": exp}, "s3cret"),
+			err:   true,
+		},
+		"no expiry": {
+			token: sign(jwt.MapClaims{"sub": "user-1", "iss": "servflow", "aud": "api"}, "s3cret"),
+			err:   true,
+		},
+		"refresh token": {
+			token: sign(jwt.MapClaims{"sub": "user-1", "iss": "servflow", "aud": "api", "exp": exp, "typ": "refresh"}, "s3cret"),
+			err:   true,
+		},
+		"wrong key": {
+			token: sign(jwt.MapClaims{"sub": "user-1", "iss": "servflow", "aud": "api", "exp": exp}, "other"),
+			err:   true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := requestctx.NewTestContext()
+			require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"key": "s3cret"}, ""))
+			req := httptest.NewRequest("GET", "/", nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, "bearer "+tc.token)
+			} else {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			ctx = plan.WithRequest(ctx, req)
+
+			auth, err := NewAuth(AuthConfig{Key: "{{ .key }}", Issuer: "servflow", Audience: "api", Header: tc.header})
+			require.NoError(t, err)
+			out, _, err := auth.Execute(ctx)
+			if tc.err {
+				assert.ErrorIs(t, err, plan.ErrFailure)
+				return
+			}
+			require.NoError(t, err)
+			claims := out.(map[string]interface{})
+			assert.Equal(t, "user-1", claims["sub"])
+			assert.Equal(t, "api", claims["aud"])
+		})
+	}
+}
+
+func TestAuth_Execute_PublicKey(t *testing.T) {
+	rsaPrivate, rsaPublic, err := generateRSAKeyPair()
+	require.NoError(t, err)
+	ecPrivate, ecPublic := generateECKeyPair(t, elliptic.P384())
+	claims := jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()}
+
+	sign := func(method jwt.SigningMethod, pemKey string) string {
+		key, err := signingKey(method, []byte(pemKey))
+		require.NoError(t, err)
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return token
+	}
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(rsaPublic))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		key       string
+		algorithm string
+		token     string
+		err       bool
+	}{
+		"rsa, inferred":          {key: rsaPublic, token: sign(jwt.SigningMethodRS256, rsaPrivate)},
+		"ec, inferred":           {key: ecPublic, token: sign(jwt.SigningMethodES384, ecPrivate)},
+		"rsa, explicit":          {key: rsaPublic, algorithm: "RS512", token: sign(jwt.SigningMethodRS512, rsaPrivate)},
+		"rsa, other algorithm":   {key: rsaPublic, algorithm: "RS512", token: sign(jwt.SigningMethodRS256, rsaPrivate), err: true},
+		"hs256 with public key":  {key: rsaPublic, token: forged, err: true},
+		"hs256 with explicit rs": {key: rsaPublic, algorithm: "RS256", token: forged, err: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := requestctx.NewTestContext()
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			ctx = plan.WithRequest(ctx, req)
+
+			auth, err := NewAuth(AuthConfig{Key: tc.key, Algorithm: tc.algorithm})
+			require.NoError(t, err)
+			out, _, err := auth.Execute(ctx)
+			if tc.err {
+				assert.ErrorIs(t, err, plan.ErrFailure)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", out.(map[string]interface{})["sub"])
+		})
+	}
+
+	_, err = NewAuth(AuthConfig{Key: rsaPublic, Algorithm: "none"})
+	assert.Error(t, err)
+}
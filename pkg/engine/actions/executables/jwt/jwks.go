@@ -0,0 +1,58 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	jwksMu sync.Mutex
+	// jwksCache holds a key set per JWKS URL until ResetJWKS, so keys are
+	// fetched once rather than on every request. Each set is refreshed
+	// hourly, and at most every five minutes when a token names a kid it does
+	// not have, e.g. after the IdP rotates its keys.
+	jwksCache = make(map[string]keyfunc.Keyfunc)
+	// jwksCtx scopes the refresh goroutines of the cached key sets, and
+	// jwksCancel stops them.
+	jwksCtx    context.Context
+	jwksCancel context.CancelFunc
+)
+
+// jwksKeyfunc returns a jwt.Keyfunc that picks the key named by a token's kid
+// from the JWKS at url.
+func jwksKeyfunc(url string) (jwt.Keyfunc, error) {
+	jwksMu.Lock()
+	defer jwksMu.Unlock()
+
+	if k, ok := jwksCache[url]; ok {
+		return k.Keyfunc, nil
+	}
+	// the refresh goroutine outlives the request that first uses the set and
+	// runs until ResetJWKS
+	if jwksCtx == nil {
+		jwksCtx, jwksCancel = context.WithCancel(context.Background())
+	}
+	k, err := keyfunc.NewDefaultCtx(jwksCtx, []string{url})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create keyfunc from JWKS URL: %w", err)
+	}
+	jwksCache[url] = k
+	return k.Keyfunc, nil
+}
+
+// ResetJWKS stops refreshing the cached key sets and drops them, so the next
+// token fetches its key set again. The engine calls it when it stops.
+func ResetJWKS() {
+	jwksMu.Lock()
+	defer jwksMu.Unlock()
+
+	if jwksCancel != nil {
+		jwksCancel()
+		jwksCtx, jwksCancel = nil, nil
+	}
+	jwksCache = make(map[string]keyfunc.Keyfunc)
+}
@@ -0,0 +1,148 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jwksServer serves the public halves of its keys as a JWKS and counts the
+// fetches.
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches atomic.Int32
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	s := &jwksServer{keys: make(map[string]*rsa.PrivateKey)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		keys := make([]map[string]interface{}, 0, len(s.keys))
+		for kid, key := range s.keys {
+			keys = append(keys, map[string]interface{}{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// addKey publishes a new key under kid and returns it.
+func (s *jwksServer) addKey(t *testing.T, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[kid] = key
+	return key
+}
+
+func signWithKID(t *testing.T, key *rsa.PrivateKey, kid, subject string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": subject,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = kid
+	tokenString, err := token.SignedString(key)
+	require.NoError(t, err)
+	return tokenString
+}
+
+func TestJWT_Execute_Decode_JwksCache(t *testing.T) {
+	server := newJWKSServer(t)
+	first := server.addKey(t, "key-1")
+
+	decode := New(Config{Mode: "decode", JwksURL: server.URL, FailOnValidationError: true})
+
+	t.Run("known kid is verified with a cached key set", func(t *testing.T) {
+		for range 3 {
+			result, _, err := decode.Execute(context.Background(), signWithKID(t, first, "key-1", "user-1"))
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", result)
+		}
+		assert.Equal(t, int32(1), server.fetches.Load())
+	})
+
+	t.Run("rotated key is fetched on unknown kid", func(t *testing.T) {
+		second := server.addKey(t, "key-2")
+		result, _, err := decode.Execute(context.Background(), signWithKID(t, second, "key-2", "user-2"))
+		require.NoError(t, err)
+		assert.Equal(t, "user-2", result)
+		assert.Equal(t, int32(2), server.fetches.Load())
+	})
+
+	t.Run("unknown kid is rejected", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		_, _, err = decode.Execute(context.Background(), signWithKID(t, other, "key-3", "intruder"))
+		assert.ErrorIs(t, err, plan.ErrFailure)
+	})
+
+	t.Run("known kid signed by another key is rejected", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		_, _, err = decode.Execute(context.Background(), signWithKID(t, other, "key-1", "intruder"))
+		assert.ErrorIs(t, err, plan.ErrFailure)
+	})
+}
+
+func TestAuth_Execute_Jwks(t *testing.T) {
+	server := newJWKSServer(t)
+	key := server.addKey(t, "key-1")
+
+	auth, err := NewAuth(AuthConfig{JwksURL: server.URL})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signWithKID(t, key, "key-1", "user-1"))
+	out, _, err := auth.Execute(plan.WithRequest(requestctx.NewTestContext(), req))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", out.(map[string]interface{})["sub"])
+}
+
+func TestResetJWKS(t *testing.T) {
+	server := newJWKSServer(t)
+	key := server.addKey(t, "key-1")
+	decode := New(Config{Mode: "decode", JwksURL: server.URL, FailOnValidationError: true})
+
+	_, _, err := decode.Execute(context.Background(), signWithKID(t, key, "key-1", "user-1"))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), server.fetches.Load())
+	refreshCtx := jwksCtx
+
+	ResetJWKS()
+	assert.Empty(t, jwksCache)
+	assert.ErrorIs(t, refreshCtx.Err(), context.Canceled, "refresh goroutines are stopped")
+
+	result, _, err := decode.Execute(context.Background(), signWithKID(t, key, "key-1", "user-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", result)
+	assert.Equal(t, int32(2), server.fetches.Load(), "key set is fetched again")
+}
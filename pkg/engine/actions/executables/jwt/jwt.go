@@ -2,14 +2,12 @@ package jwt
 
 import (
 	"context"
-	"crypto/rsa"
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"time"
 
-	"github.com/MicahParks/keyfunc/v3"
 	"github.com/Servflow/servflow/pkg/engine/actions"
 	"github.com/Servflow/servflow/pkg/engine/plan"
 	"github.com/Servflow/servflow/pkg/logging"
@@ -24,6 +22,66 @@ type Config struct {
 	JwksURL               string                 `json:"jwksURL" yaml:"jwksURL"`
 	Claims                map[string]interface{} `json:"claims" yaml:"claims"`
 	FailOnValidationError bool                   `json:"failOnValidationError" yaml:"failOnValidationError"`
+
+	// Algorithm, e.g. "ES256" or "EdDSA", selects how tokens are signed and
+	// is the only one accepted when decoding. Empty signs with HS256, or
+	// RS256 for a PEM key, and accepts any supported algorithm.
+	Algorithm string `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	// ExpiresIn is how long an encoded token is valid, e.g. "15m". Defaults
+	// to 72h.
+	ExpiresIn string `json:"expiresIn,omitempty" yaml:"expiresIn,omitempty"`
+	// NotBefore delays when an encoded token becomes valid, e.g. "0s" for
+	// immediately. Empty leaves out nbf.
+	NotBefore string `json:"notBefore,omitempty" yaml:"notBefore,omitempty"`
+	// Issuer and Audience are set as iss and aud when encoding, and must
+	// match the token's when decoding.
+	Issuer   string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	Audience string `json:"audience,omitempty" yaml:"audience,omitempty"`
+}
+
+const defaultExpiresIn = 72 * time.Hour
+
+func (c Config) validate() error {
+	if c.Algorithm != "" {
+		if _, err := parseAlgorithm(c.Algorithm); err != nil {
+			return err
+		}
+	}
+	if _, err := c.expiresIn(); err != nil {
+		return err
+	}
+	if c.NotBefore != "" {
+		if _, err := time.ParseDuration(c.NotBefore); err != nil {
+			return fmt.Errorf("invalid notBefore: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c Config) expiresIn() (time.Duration, error) {
+	if c.ExpiresIn == "" {
+		return defaultExpiresIn, nil
+	}
+	d, err := time.ParseDuration(c.ExpiresIn)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expiresIn: %w", err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("expiresIn must be positive: %s", c.ExpiresIn)
+	}
+	return d, nil
+}
+
+// parserOptions checks iss and aud when decoding, if configured.
+func (c Config) parserOptions() []jwt.ParserOption {
+	var opts []jwt.ParserOption
+	if c.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(c.Issuer))
+	}
+	if c.Audience != "" {
+		opts = append(opts, jwt.WithAudience(c.Audience))
+	}
+	return opts
 }
 
 type JWT struct {
@@ -77,7 +135,16 @@ func (a *JWT) encode(ctx context.Context, payload string) (interface{}, error) {
 
 	secret := []byte(a.config.Key)
 
-	if block, _ := pem.Decode(secret); block != nil {
+	if a.config.Algorithm != "" {
+		method, err := parseAlgorithm(a.config.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		if key, err = signingKey(method, secret); err != nil {
+			return nil, err
+		}
+		signingMethod = method
+	} else if block, _ := pem.Decode(secret); block != nil {
 		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse private key: %s", err)
@@ -90,9 +157,14 @@ func (a *JWT) encode(ctx context.Context, payload string) (interface{}, error) {
 		key = secret
 	}
 
+	expiresIn, err := a.config.expiresIn()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"sub": payload,
-		"exp": time.Now().Add(time.Hour * 72).Unix(),
+		"exp": now.Add(expiresIn).Unix(),
 	}
 
 	for key, value := range a.config.Claims {
@@ -101,6 +173,20 @@ func (a *JWT) encode(ctx context.Context, payload string) (interface{}, error) {
 		}
 	}
 
+	if a.config.NotBefore != "" {
+		notBefore, err := time.ParseDuration(a.config.NotBefore)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notBefore: %w", err)
+		}
+		claims["nbf"] = now.Add(notBefore).Unix()
+	}
+	if a.config.Issuer != "" {
+		claims["iss"] = a.config.Issuer
+	}
+	if a.config.Audience != "" {
+		claims["aud"] = a.config.Audience
+	}
+
 	token := jwt.NewWithClaims(signingMethod, claims)
 
 	tokenString, err := token.SignedString(key)
@@ -124,20 +210,26 @@ func (a *JWT) decode(ctx context.Context, tokenString string) (interface{}, erro
 }
 
 func (a *JWT) decodeWithJwks(ctx context.Context, tokenString string) (interface{}, error) {
-	k, err := keyfunc.NewDefaultCtx(ctx, []string{a.config.JwksURL})
+	k, err := jwksKeyfunc(a.config.JwksURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create keyfunc from JWKS URL: %w", err)
+		return nil, err
 	}
 
-	token, err := jwt.Parse(tokenString, k.Keyfunc)
+	token, err := jwt.Parse(tokenString, k, a.config.parserOptions()...)
 	if err != nil {
 		if a.config.FailOnValidationError {
-			return nil, fmt.Errorf("%w: %v", plan.ErrFailure, err)
+			return nil, fmt.Errorf("%w: %w", plan.ErrFailure, err)
 		}
 		return nil, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		if isRefreshToken(claims) {
+			if a.config.FailOnValidationError {
+				return nil, fmt.Errorf("%w: %w", plan.ErrFailure, errRefreshToken)
+			}
+			return nil, errRefreshToken
+		}
 		if sub, ok := claims["sub"].(string); ok {
 			return sub, nil
 		}
@@ -154,51 +246,18 @@ func (a *JWT) decodeWithJwks(ctx context.Context, tokenString string) (interface
 }
 
 func (a *JWT) decodeWithKey(ctx context.Context, tokenString string) (interface{}, error) {
-	secret := []byte(a.config.Key)
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		alg := token.Header["alg"]
-
-		switch alg {
-		case "HS256", "HS384", "HS512":
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", alg)
-			}
-			return secret, nil
-
-		case "RS256", "RS384", "RS512":
-			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", alg)
-			}
-
-			block, _ := pem.Decode(secret)
-			if block == nil {
-				return nil, fmt.Errorf("failed to parse PEM block containing public key")
-			}
-
-			pub, err := x509.ParsePKIXPublicKey(block.Bytes)
-			if err != nil {
-				if a.config.FailOnValidationError {
-					return nil, fmt.Errorf("%w: invalid token - %v", plan.ErrFailure, err)
-				}
-				return nil, err
-			}
-
-			rsaKey, ok := pub.(*rsa.PublicKey)
-			if !ok {
-				return nil, fmt.Errorf("not an RSA public key")
-			}
-			return rsaKey, nil
-		default:
-			return nil, fmt.Errorf("unsupported signing method: %v", alg)
-		}
-	})
-
+	token, err := jwt.Parse(tokenString, verificationKey([]byte(a.config.Key), a.config.Algorithm, a.config.FailOnValidationError), a.config.parserOptions()...)
 	if err != nil {
 		return nil, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		if isRefreshToken(claims) {
+			if a.config.FailOnValidationError {
+				return nil, fmt.Errorf("%w: %w", plan.ErrFailure, errRefreshToken)
+			}
+			return nil, errRefreshToken
+		}
 		if sub, ok := claims["sub"].(string); ok {
 			return sub, nil
 		}
@@ -213,6 +272,34 @@ func (a *JWT) decodeWithKey(ctx context.Context, tokenString string) (interface{
 	return nil, fmt.Errorf("invalid token")
 }
 
+// verificationKey returns the key for checking a token's signature: secret
+// itself for HMAC tokens, or the public key PEM-encoded in it. When algorithm
+// is set, tokens signed with any other are rejected.
+func verificationKey(secret []byte, algorithm string, failOnValidationError bool) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		alg := token.Header["alg"]
+		if algorithm != "" && alg != algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v, expected %s", alg, algorithm)
+		}
+
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			return secret, nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519:
+			key, err := publicKey(token.Method, secret)
+			if err != nil {
+				if failOnValidationError {
+					return nil, fmt.Errorf("%w: invalid token - %v", plan.ErrFailure, err)
+				}
+				return nil, err
+			}
+			return key, nil
+		default:
+			return nil, fmt.Errorf("unsupported signing method: %v", alg)
+		}
+	}
+}
+
 func init() {
 	fields := map[string]actions.FieldInfo{
 		"mode": {
@@ -233,6 +320,36 @@ func init() {
 			Placeholder: "JWT signing/verification key (PEM or secret)",
 			Required:    false,
 		},
+		"algorithm": {
+			Type:        actions.FieldTypeString,
+			Label:       "Algorithm",
+			Placeholder: "HS256, RS256, ES256, EdDSA... (inferred from the key when empty)",
+			Required:    false,
+		},
+		"expiresIn": {
+			Type:        actions.FieldTypeString,
+			Label:       "Expires In",
+			Placeholder: "Token lifetime, e.g. 15m (defaults to 72h)",
+			Required:    false,
+		},
+		"notBefore": {
+			Type:        actions.FieldTypeString,
+			Label:       "Not Before",
+			Placeholder: "Delay before the token is valid, e.g. 0s",
+			Required:    false,
+		},
+		"issuer": {
+			Type:        actions.FieldTypeString,
+			Label:       "Issuer",
+			Placeholder: "iss claim to set, and to require when decoding",
+			Required:    false,
+		},
+		"audience": {
+			Type:        actions.FieldTypeString,
+			Label:       "Audience",
+			Placeholder: "aud claim to set, and to require when decoding",
+			Required:    false,
+		},
 		"jwksURL": {
 			Type:        actions.FieldTypeString,
 			Label:       "JWKS URL",
@@ -263,6 +380,9 @@ func init() {
 			if err := json.Unmarshal(config, &cfg); err != nil {
 				return nil, fmt.Errorf("error creating jwt action: %v", err)
 			}
+			if err := cfg.validate(); err != nil {
+				return nil, fmt.Errorf("error creating jwt action: %v", err)
+			}
 			return New(cfg), nil
 		},
 	}); err != nil {
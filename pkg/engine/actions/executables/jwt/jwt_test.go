@@ -2,6 +2,9 @@ package jwt
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -16,6 +19,8 @@ import (
 
 	"encoding/base64"
 
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/plan"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -62,6 +67,28 @@ func generateRSAKeyPair() (string, string, error) {
 	return string(privateKeyPEM), string(publicKeyPEM), nil
 }
 
+// encodeKeyPair PEM-encodes a private key, as PKCS8, and its public key.
+func encodeKeyPair(t *testing.T, privateKey, publicKey interface{}) (string, string) {
+	privateBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	publicBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes}))
+}
+
+func generateECKeyPair(t *testing.T, curve elliptic.Curve) (string, string) {
+	privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	return encodeKeyPair(t, privateKey, &privateKey.PublicKey)
+}
+
+func generateEd25519KeyPair(t *testing.T) (string, string) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return encodeKeyPair(t, privateKey, publicKey)
+}
+
 func TestJWT_Execute_Encode(t *testing.T) {
 	// HMAC-based tests
 	t.Run("EncodeWithSecret", func(t *testing.T) {
@@ -231,6 +258,23 @@ func TestJWT_Execute_Decode(t *testing.T) {
 		assert.Contains(t, err.Error(), "expired")
 	})
 
+	t.Run("DecodeRejectsRefreshToken", func(t *testing.T) {
+		refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "decodedSubject",
+			"typ": "refresh",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("testSecret"))
+		require.NoError(t, err)
+
+		jwtAction := New(Config{Mode: "decode", Key: "testSecret"})
+		_, _, err = jwtAction.Execute(context.Background(), refreshToken)
+		assert.ErrorIs(t, err, errRefreshToken)
+
+		jwtAction = New(Config{Mode: "decode", Key: "testSecret", FailOnValidationError: true})
+		_, _, err = jwtAction.Execute(context.Background(), refreshToken)
+		assert.ErrorIs(t, err, plan.ErrFailure)
+	})
+
 	// Test with RSA keys
 	t.Run("RSAKeyPair", func(t *testing.T) {
 		privateKeyPEM, publicKeyPEM, err := generateRSAKeyPair()
@@ -525,3 +569,139 @@ func TestJWT_Execute_Encode_RequiresKey(t *testing.T) {
 		assert.Contains(t, err.Error(), "key is required for encoding")
 	})
 }
+
+func TestJWT_Execute_Algorithm(t *testing.T) {
+	ecPrivate, ecPublic := generateECKeyPair(t, elliptic.P256())
+	ec384Private, ec384Public := generateECKeyPair(t, elliptic.P384())
+	edPrivate, edPublic := generateEd25519KeyPair(t)
+	rsaPrivate, rsaPublic, err := generateRSAKeyPair()
+	require.NoError(t, err)
+
+	roundTrip := map[string]struct {
+		privateKey, publicKey string
+	}{
+		"ES256": {ecPrivate, ecPublic},
+		"ES384": {ec384Private, ec384Public},
+		"EdDSA": {edPrivate, edPublic},
+		"RS256": {rsaPrivate, rsaPublic},
+	}
+	for alg, keys := range roundTrip {
+		t.Run("EncodeAndDecodeWith"+alg, func(t *testing.T) {
+			encodeAction := New(Config{Mode: "encode", Key: keys.privateKey, Algorithm: alg})
+			tokenResult, _, err := encodeAction.Execute(context.Background(), "keySubject")
+			require.NoError(t, err)
+			tokenString := tokenResult.(string)
+
+			token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, alg, token.Header["alg"])
+
+			decodeAction := New(Config{Mode: "decode", Key: keys.publicKey, Algorithm: alg})
+			result, _, err := decodeAction.Execute(context.Background(), tokenString)
+			require.NoError(t, err)
+			assert.Equal(t, "keySubject", result)
+
+			// without an algorithm the token's own is used
+			result, _, err = New(Config{Mode: "decode", Key: keys.publicKey}).Execute(context.Background(), tokenString)
+			require.NoError(t, err)
+			assert.Equal(t, "keySubject", result)
+		})
+	}
+
+	t.Run("EncodeWithMismatchedKey", func(t *testing.T) {
+		_, _, err := New(Config{Mode: "encode", Key: rsaPrivate, Algorithm: "ES256"}).Execute(context.Background(), "keySubject")
+		assert.EqualError(t, err, "key of type *rsa.PrivateKey does not match algorithm ES256")
+
+		_, _, err = New(Config{Mode: "encode", Key: ec384Private, Algorithm: "ES256"}).Execute(context.Background(), "keySubject")
+		assert.EqualError(t, err, "key of type *ecdsa.PrivateKey does not match algorithm ES256")
+
+		_, _, err = New(Config{Mode: "encode", Key: "testSecret", Algorithm: "EdDSA"}).Execute(context.Background(), "keySubject")
+		assert.EqualError(t, err, "failed to parse PEM block containing private key")
+	})
+
+	t.Run("DecodeRejectsOtherAlgorithm", func(t *testing.T) {
+		tokenResult, _, err := New(Config{Mode: "encode", Key: edPrivate, Algorithm: "EdDSA"}).Execute(context.Background(), "keySubject")
+		require.NoError(t, err)
+
+		_, _, err = New(Config{Mode: "decode", Key: ecPublic, Algorithm: "ES256"}).Execute(context.Background(), tokenResult.(string))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected signing method: EdDSA, expected ES256")
+	})
+
+	t.Run("DecodeWithMismatchedKey", func(t *testing.T) {
+		tokenResult, _, err := New(Config{Mode: "encode", Key: ecPrivate, Algorithm: "ES256"}).Execute(context.Background(), "keySubject")
+		require.NoError(t, err)
+
+		_, _, err = New(Config{Mode: "decode", Key: edPublic, Algorithm: "ES256", FailOnValidationError: true}).Execute(context.Background(), tokenResult.(string))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "key of type ed25519.PublicKey does not match algorithm ES256")
+	})
+
+	t.Run("UnsupportedAlgorithm", func(t *testing.T) {
+		_, err := actions.GetActionExecutable("jwt", json.RawMessage(`{"mode": "encode", "key": "k", "algorithm": "none"}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unsupported algorithm "none"`)
+	})
+}
+
+func TestJWT_Execute_StandardClaims(t *testing.T) {
+	encode := New(Config{
+		Mode:      "encode",
+		Key:       "testSecret",
+		ExpiresIn: "15m",
+		NotBefore: "0s",
+		Issuer:    "servflow",
+		Audience:  "api",
+		Claims:    map[string]interface{}{"iss": "shouldBeOverridden"},
+	})
+	before := time.Now()
+	result, _, err := encode.Execute(context.Background(), "claimsSubject")
+	require.NoError(t, err)
+	tokenString := result.(string)
+
+	t.Run("EncodedTokenCarriesClaims", func(t *testing.T) {
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte("testSecret"), nil
+		})
+		require.NoError(t, err)
+
+		exp, err := claims.GetExpirationTime()
+		require.NoError(t, err)
+		assert.WithinDuration(t, before.Add(15*time.Minute), exp.Time, 2*time.Second)
+		nbf, err := claims.GetNotBefore()
+		require.NoError(t, err)
+		assert.WithinDuration(t, before, nbf.Time, 2*time.Second)
+		assert.Equal(t, "servflow", claims["iss"])
+		assert.Equal(t, "api", claims["aud"])
+	})
+
+	t.Run("DecodeChecksIssuerAndAudience", func(t *testing.T) {
+		result, _, err := New(Config{Mode: "decode", Key: "testSecret", Issuer: "servflow", Audience: "api"}).
+			Execute(context.Background(), tokenString)
+		require.NoError(t, err)
+		assert.Equal(t, "claimsSubject", result)
+
+		_, _, err = New(Config{Mode: "decode", Key: "testSecret", Audience: "admin"}).Execute(context.Background(), tokenString)
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+
+		_, _, err = New(Config{Mode: "decode", Key: "testSecret", Issuer: "other"}).Execute(context.Background(), tokenString)
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+	})
+
+	t.Run("NotYetValid", func(t *testing.T) {
+		result, _, err := New(Config{Mode: "encode", Key: "testSecret", NotBefore: "1h"}).Execute(context.Background(), "claimsSubject")
+		require.NoError(t, err)
+
+		_, _, err = New(Config{Mode: "decode", Key: "testSecret"}).Execute(context.Background(), result.(string))
+		assert.ErrorIs(t, err, jwt.ErrTokenNotValidYet)
+	})
+
+	t.Run("InvalidDurations", func(t *testing.T) {
+		_, err := actions.GetActionExecutable("jwt", json.RawMessage(`{"mode": "encode", "key": "k", "expiresIn": "-1m"}`))
+		assert.ErrorContains(t, err, "expiresIn must be positive: -1m")
+
+		_, err = actions.GetActionExecutable("jwt", json.RawMessage(`{"mode": "encode", "key": "k", "notBefore": "soon"}`))
+		assert.ErrorContains(t, err, "invalid notBefore")
+	})
+}
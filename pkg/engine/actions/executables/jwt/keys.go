@@ -0,0 +1,142 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// algorithms are the values accepted for Config.Algorithm.
+var algorithms = []string{
+	"HS256", "HS384", "HS512",
+	"RS256", "RS384", "RS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+func parseAlgorithm(alg string) (jwt.SigningMethod, error) {
+	if !slices.Contains(algorithms, alg) {
+		return nil, fmt.Errorf("unsupported algorithm %q, expected one of %v", alg, algorithms)
+	}
+	return jwt.GetSigningMethod(alg), nil
+}
+
+// signingKey parses the key that signs tokens with method: the secret itself
+// for HMAC, else a PEM-encoded private key of the method's type.
+func signingKey(method jwt.SigningMethod, secret []byte) (interface{}, error) {
+	if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+		return secret, nil
+	}
+
+	block, _ := pem.Decode(secret)
+	if block == nil {
+		return nil, errors.New("failed to parse PEM block containing private key")
+	}
+	var (
+		key interface{}
+		err error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %s", err)
+	}
+	if err := checkKey(method, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// publicKey parses the PEM-encoded public key that verifies tokens signed with
+// method.
+func publicKey(method jwt.SigningMethod, secret []byte) (interface{}, error) {
+	block, _ := pem.Decode(secret)
+	if block == nil {
+		return nil, errors.New("failed to parse PEM block containing public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKey(method, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// checkKey rejects a key that cannot be used with method, e.g. an RSA key, or
+// an EC key on a curve other than P-256, for ES256.
+func checkKey(method jwt.SigningMethod, key interface{}) error {
+	var ok bool
+	switch m := method.(type) {
+	case *jwt.SigningMethodRSA:
+		switch key.(type) {
+		case *rsa.PrivateKey, *rsa.PublicKey:
+			ok = true
+		}
+	case *jwt.SigningMethodECDSA:
+		var curveBits int
+		switch k := key.(type) {
+		case *ecdsa.PrivateKey:
+			curveBits = k.Curve.Params().BitSize
+		case *ecdsa.PublicKey:
+			curveBits = k.Curve.Params().BitSize
+		}
+		ok = curveBits == m.CurveBits
+	case *jwt.SigningMethodEd25519:
+		switch key.(type) {
+		case ed25519.PrivateKey, ed25519.PublicKey:
+			ok = true
+		}
+	}
+	if !ok {
+		return fmt.Errorf("key of type %T does not match algorithm %s", key, method.Alg())
+	}
+	return nil
+}
+
+// publicKeyAlgorithm returns the algorithm tokens verified with secret must be
+// signed with: the family of a PEM-encoded public key, e.g. ES384 for a P-384
+// key, or HS256 for anything else. Accepting only that algorithm stops a token
+// signed with HS256, using the public key text as its secret, from passing.
+func publicKeyAlgorithm(secret []byte) (string, error) {
+	block, _ := pem.Decode(secret)
+	if block == nil {
+		return "HS256", nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse public key: %w", err)
+	}
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return "RS256", nil
+	case *ecdsa.PublicKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			return "ES256", nil
+		case 384:
+			return "ES384", nil
+		case 521:
+			return "ES512", nil
+		}
+		return "", fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return "EdDSA", nil
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", key)
+	}
+}
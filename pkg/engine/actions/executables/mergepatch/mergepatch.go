@@ -0,0 +1,153 @@
+package mergepatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// defaultPatch patches with the request body.
+const defaultPatch = `"{{ body "" }}"`
+
+type Config struct {
+	// Target resolves to the JSON object being patched, usually a fetched
+	// record: {{ jsonraw .variable_actions_<fetch> }}.
+	Target string `json:"target" yaml:"target"`
+	// Patch resolves to the RFC 7386 merge patch. It may be a JSON object or
+	// a JSON string holding one, as produced by "{{ body "" }}". Defaults to
+	// the request body.
+	Patch string `json:"patch" yaml:"patch"`
+}
+
+// MergePatch applies a JSON merge patch (RFC 7386) onto a record and returns
+// the merged record, ready for an update or save action. Keys in the patch
+// replace those in the record, nested objects are merged, and null removes a
+// key.
+type MergePatch struct {
+	target string
+	patch  string
+}
+
+func (m *MergePatch) Type() string {
+	return "mergepatch"
+}
+
+func (m *MergePatch) SupportsReplica() bool {
+	return true
+}
+
+func New(cfg Config) (*MergePatch, error) {
+	if cfg.Target == "" {
+		return nil, errors.New("target is required")
+	}
+	if cfg.Patch == "" {
+		cfg.Patch = defaultPatch
+	}
+	return &MergePatch{target: cfg.Target, patch: cfg.Patch}, nil
+}
+
+func (m *MergePatch) Execute(ctx context.Context) (interface{}, map[string]string, error) {
+	logger := logging.FromContext(ctx).With(zap.String("execution_type", m.Type()))
+
+	rc, err := requestctx.FromContextOrError(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get request context: %w", err)
+	}
+	resolved, err := rc.ResolveBatch(ctx, m.target, m.patch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve merge patch: %w", err)
+	}
+
+	var target map[string]interface{}
+	if err := json.Unmarshal([]byte(resolved[0]), &target); err != nil || target == nil {
+		return nil, nil, fmt.Errorf("%w: target is not a JSON object", plan.ErrFailure)
+	}
+	patch, err := decodePatch(resolved[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", plan.ErrFailure, err)
+	}
+
+	logger.Debug("applying merge patch", zap.Int("patch_keys", len(patch)))
+	return Apply(target, patch), nil, nil
+}
+
+// decodePatch parses a merge patch, unwrapping one level of JSON string so
+// the escaped request body can be passed through a template.
+func decodePatch(s string) (map[string]interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, errors.New("patch is not valid JSON")
+	}
+	if str, ok := v.(string); ok {
+		if err := json.Unmarshal([]byte(str), &v); err != nil {
+			return nil, errors.New("patch is not valid JSON")
+		}
+	}
+	patch, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("patch must be a JSON object")
+	}
+	return patch, nil
+}
+
+// Apply merges patch into target following RFC 7386. target is not modified.
+// A patch that is not an object replaces target entirely.
+func Apply(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, _ := target.(map[string]interface{})
+	merged := make(map[string]interface{}, len(t)+len(p))
+	for k, v := range t {
+		merged[k] = v
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		merged[k] = Apply(merged[k], v)
+	}
+	return merged
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"target": {
+			Type:        actions.FieldTypeString,
+			Label:       "Target",
+			Placeholder: "{{ jsonraw .variable_actions_fetch }}",
+			Required:    true,
+		},
+		"patch": {
+			Type:        actions.FieldTypeString,
+			Label:       "Patch",
+			Placeholder: "Merge patch, defaults to the request body",
+			Default:     defaultPatch,
+		},
+	}
+
+	if err := actions.RegisterAction("mergepatch", actions.ActionRegistrationInfo{
+		Name:        "JSON Merge Patch",
+		Description: "Applies an RFC 7386 JSON merge patch onto a record for partial updates; null removes a field",
+		Fields:      fields,
+		UseV2:       true,
+		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
+			var cfg Config
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating mergepatch action: %v", err)
+			}
+			return New(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
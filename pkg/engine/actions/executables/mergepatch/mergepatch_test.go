@@ -0,0 +1,124 @@
+package mergepatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	record := map[string]interface{}{
+		"name":    "ada",
+		"email":   "ada@example.com",
+		"address": map[string]interface{}{"city": "London", "zip": "N1"},
+	}
+
+	testCases := []struct {
+		name     string
+		patch    interface{}
+		expected interface{}
+	}{
+		{
+			name:  "adds a field",
+			patch: map[string]interface{}{"role": "admin"},
+			expected: map[string]interface{}{
+				"name": "ada", "email": "ada@example.com", "role": "admin",
+				"address": map[string]interface{}{"city": "London", "zip": "N1"},
+			},
+		},
+		{
+			name:  "overwrites a field",
+			patch: map[string]interface{}{"email": "ada@lovelace.dev"},
+			expected: map[string]interface{}{
+				"name": "ada", "email": "ada@lovelace.dev",
+				"address": map[string]interface{}{"city": "London", "zip": "N1"},
+			},
+		},
+		{
+			name:  "null removes a field",
+			patch: map[string]interface{}{"email": nil},
+			expected: map[string]interface{}{
+				"name":    "ada",
+				"address": map[string]interface{}{"city": "London", "zip": "N1"},
+			},
+		},
+		{
+			name:  "nested objects are merged",
+			patch: map[string]interface{}{"address": map[string]interface{}{"zip": nil, "country": "UK"}},
+			expected: map[string]interface{}{
+				"name": "ada", "email": "ada@example.com",
+				"address": map[string]interface{}{"city": "London", "country": "UK"},
+			},
+		},
+		{
+			name:     "non-object patch replaces the target",
+			patch:    []interface{}{"a"},
+			expected: []interface{}{"a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Apply(record, tc.patch))
+		})
+	}
+
+	assert.Equal(t, "ada@example.com", record["email"], "target must not be modified")
+}
+
+func TestMergePatch_Execute(t *testing.T) {
+	newContext := func(t *testing.T) context.Context {
+		t.Helper()
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{
+			"user": map[string]interface{}{"id": "u1", "name": "ada", "email": "ada@example.com"},
+		}, ""))
+		return ctx
+	}
+
+	t.Run("patches the fetched record", func(t *testing.T) {
+		ctx := newContext(t)
+		m, err := New(Config{
+			Target: "{{ jsonraw .variable_actions_user }}",
+			Patch:  `{"name": "Ada Lovelace", "email": null, "role": "admin"}`,
+		})
+		require.NoError(t, err)
+
+		resp, _, err := m.Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"id": "u1", "name": "Ada Lovelace", "role": "admin"}, resp)
+	})
+
+	t.Run("patch passed as an escaped string", func(t *testing.T) {
+		ctx := newContext(t)
+		m, err := New(Config{
+			Target: "{{ jsonraw .variable_actions_user }}",
+			Patch:  `"{\"name\": \"Ada\"}"`,
+		})
+		require.NoError(t, err)
+
+		resp, _, err := m.Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"id": "u1", "name": "Ada", "email": "ada@example.com"}, resp)
+	})
+
+	t.Run("invalid patch is a failure", func(t *testing.T) {
+		ctx := newContext(t)
+		m, err := New(Config{Target: "{{ jsonraw .variable_actions_user }}", Patch: `[1, 2]`})
+		require.NoError(t, err)
+
+		_, _, err = m.Execute(ctx)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plan.ErrFailure))
+	})
+
+	t.Run("target is required", func(t *testing.T) {
+		_, err := New(Config{})
+		assert.ErrorContains(t, err, "target is required")
+	})
+}
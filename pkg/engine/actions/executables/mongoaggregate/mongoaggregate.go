@@ -0,0 +1,139 @@
+package mongoaggregate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+)
+
+type Config struct {
+	Collection string `json:"collection" yaml:"collection"`
+	// Pipeline is a JSON array of aggregation stages, e.g.
+	// [{"$match": {"status": "paid"}}, {"$group": {"_id": "$user", "total": {"$sum": "$amount"}}}].
+	Pipeline      string `json:"pipeline" yaml:"pipeline"`
+	IntegrationID string `json:"integrationID" yaml:"integrationID"`
+	FailIfEmpty   bool   `json:"failIfEmpty" yaml:"failIfEmpty"`
+}
+
+type aggregateIntegration interface {
+	Aggregate(ctx context.Context, collection string, pipeline string) ([]map[string]interface{}, error)
+}
+
+// MongoAggregate runs an aggregation pipeline, for $group, $lookup and $unwind
+// queries that mongoquery cannot express.
+type MongoAggregate struct {
+	config Config
+	i      aggregateIntegration
+}
+
+func (m *MongoAggregate) Config() string {
+	b, err := json.Marshal(m.config)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func New(config Config) (*MongoAggregate, error) {
+	config.IntegrationID = integration.ResolveID(config.IntegrationID)
+	if config.IntegrationID == "" {
+		return nil, errors.New("IntegrationID is required")
+	}
+	if config.Collection == "" {
+		return nil, errors.New("collection is required")
+	}
+	if config.Pipeline == "" {
+		return nil, errors.New("pipeline is required")
+	}
+
+	i, err := integration.GetIntegration(context.Background(), config.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	u, ok := i.(aggregateIntegration)
+	if !ok {
+		return nil, errors.New("integration does not implement aggregateIntegration")
+	}
+
+	return &MongoAggregate{
+		config: config,
+		i:      u,
+	}, nil
+}
+
+func (m *MongoAggregate) Execute(ctx context.Context, modifiedConfig string) (interface{}, map[string]string, error) {
+	var cfg Config
+	if err := json.Unmarshal([]byte(modifiedConfig), &cfg); err != nil {
+		return nil, nil, err
+	}
+
+	result, err := m.i.Aggregate(ctx, cfg.Collection, cfg.Pipeline)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error executing integration: %v", err)
+	}
+
+	if len(result) == 0 && cfg.FailIfEmpty {
+		return nil, nil, fmt.Errorf("%w: no documents found", plan.ErrFailure)
+	}
+
+	return result, nil, nil
+}
+
+func (m *MongoAggregate) Type() string {
+	return "mongoaggregate"
+}
+
+func (m *MongoAggregate) SupportsReplica() bool {
+	return true
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"collection": {
+			Type:        actions.FieldTypeString,
+			Label:       "Collection",
+			Placeholder: "MongoDB collection name",
+			Required:    true,
+		},
+		"pipeline": {
+			Type:        actions.FieldTypeTextArea,
+			Label:       "Pipeline",
+			Placeholder: `[{"$group": {"_id": "$team", "count": {"$sum": 1}}}]`,
+			Required:    true,
+		},
+		"integrationID": {
+			Type:        actions.FieldTypeIntegration,
+			Label:       "Integration ID",
+			Placeholder: "MongoDB integration identifier",
+			Required:    true,
+		},
+		"failIfEmpty": {
+			Type:        actions.FieldTypeBoolean,
+			Label:       "Fail if Empty",
+			Placeholder: "Treat no results as failure",
+			Required:    false,
+			Default:     false,
+		},
+	}
+
+	if err := actions.RegisterAction("mongoaggregate", actions.ActionRegistrationInfo{
+		Name:        "MongoDB Aggregate",
+		Description: "Runs a MongoDB aggregation pipeline such as $group, $lookup or $unwind",
+		Fields:      fields,
+		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
+			var cfg Config
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating mongoaggregate action: %v", err)
+			}
+			return New(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,52 @@
+package mongoaggregate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMongo struct {
+	collection, pipeline string
+	results              []map[string]interface{}
+}
+
+func (f *fakeMongo) Type() string { return "fakemongo" }
+
+func (f *fakeMongo) Aggregate(_ context.Context, collection string, pipeline string) ([]map[string]interface{}, error) {
+	f.collection, f.pipeline = collection, pipeline
+	return f.results, nil
+}
+
+func TestMongoAggregate_Execute(t *testing.T) {
+	fake := &fakeMongo{results: []map[string]interface{}{{"_id": "red", "count": int32(2)}}}
+	integration.ReplaceIntegrationType("fakemongo", func(map[string]any) (integration.Integration, error) {
+		return fake, nil
+	})
+	require.NoError(t, integration.InitializeIntegration("fakemongo", "mongods", nil, false))
+
+	agg, err := New(Config{
+		IntegrationID: "mongods",
+		Collection:    "users",
+		Pipeline:      `[{"$group": {"_id": "$team", "count": {"$sum": 1}}}]`,
+		FailIfEmpty:   true,
+	})
+	require.NoError(t, err)
+
+	out, _, err := agg.Execute(context.Background(), agg.Config())
+	require.NoError(t, err)
+	assert.Equal(t, fake.results, out)
+	assert.Equal(t, "users", fake.collection)
+	assert.JSONEq(t, `[{"$group": {"_id": "$team", "count": {"$sum": 1}}}]`, fake.pipeline)
+
+	fake.results = nil
+	_, _, err = agg.Execute(context.Background(), agg.Config())
+	assert.ErrorIs(t, err, plan.ErrFailure)
+
+	_, err = New(Config{IntegrationID: "mongods", Collection: "users"})
+	assert.EqualError(t, err, "pipeline is required")
+}
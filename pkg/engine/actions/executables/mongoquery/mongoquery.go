@@ -8,6 +8,7 @@ import (
 
 	"github.com/Servflow/servflow/pkg/engine/actions"
 	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/mongo"
 	"github.com/Servflow/servflow/pkg/engine/plan"
 )
 
@@ -15,12 +16,16 @@ type Config struct {
 	Collection    string `json:"collection" yaml:"collection"`
 	FilterQuery   string `json:"filterQuery" yaml:"filterQuery"`
 	Projection    string `json:"projection" yaml:"projection"`
+	Limit         string `json:"limit" yaml:"limit"`
+	Skip          string `json:"skip" yaml:"skip"`
+	Sort          string `json:"sort" yaml:"sort"`
+	IDFormat      string `json:"idFormat" yaml:"idFormat"`
 	IntegrationID string `json:"integrationID" yaml:"integrationID"`
 	FailIfEmpty   bool   `json:"failIfEmpty" yaml:"failIfEmpty"`
 }
 
 type mongoDBIntegration interface {
-	ExecuteQuery(ctx context.Context, collection string, filterQuery string, projectionQuery string) ([]map[string]interface{}, error)
+	ExecuteQuery(ctx context.Context, collection string, filterQuery string, projectionQuery string, queryOpts mongo.QueryOptions) ([]map[string]interface{}, error)
 }
 
 type MGOQuery struct {
@@ -37,6 +42,7 @@ func (m *MGOQuery) Config() string {
 }
 
 func New(config Config) (*MGOQuery, error) {
+	config.IntegrationID = integration.ResolveID(config.IntegrationID)
 	if config.IntegrationID == "" {
 		return nil, errors.New("IntegrationID is required")
 	}
@@ -67,7 +73,12 @@ func (m *MGOQuery) Execute(ctx context.Context, modifiedConfig string) (interfac
 	}
 	m.config = cfg
 
-	result, err := m.i.ExecuteQuery(ctx, cfg.Collection, cfg.FilterQuery, cfg.Projection)
+	result, err := m.i.ExecuteQuery(ctx, cfg.Collection, cfg.FilterQuery, cfg.Projection, mongo.QueryOptions{
+		Limit:    cfg.Limit,
+		Skip:     cfg.Skip,
+		Sort:     cfg.Sort,
+		IDFormat: cfg.IDFormat,
+	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("error executing integration: %v", err)
 	}
@@ -108,6 +119,32 @@ func init() {
 			Placeholder: "MongoDB projection query",
 			Required:    false,
 		},
+		"limit": {
+			Type:        actions.FieldTypeString,
+			Label:       "Limit",
+			Placeholder: "Maximum number of documents to return",
+			Required:    false,
+		},
+		"skip": {
+			Type:        actions.FieldTypeString,
+			Label:       "Skip",
+			Placeholder: "Number of documents to skip",
+			Required:    false,
+		},
+		"sort": {
+			Type:        actions.FieldTypeString,
+			Label:       "Sort",
+			Placeholder: "MongoDB sort document, e.g. {\"age\": -1}",
+			Required:    false,
+		},
+		"idFormat": {
+			Type:        actions.FieldTypeString,
+			Label:       "ID Format",
+			Placeholder: "How _id is returned",
+			Required:    false,
+			Default:     mongo.IDFormatString,
+			Values:      []string{mongo.IDFormatString, mongo.IDFormatID, mongo.IDFormatRaw},
+		},
 		"integrationID": {
 			Type:        actions.FieldTypeIntegration,
 			Label:       "Integration ID",
@@ -125,7 +162,7 @@ func init() {
 
 	if err := actions.RegisterAction("mongoquery", actions.ActionRegistrationInfo{
 		Name:        "MongoDB Query",
-		Description: "Executes queries against MongoDB collections with filtering and projection",
+		Description: "Executes queries against MongoDB collections with filtering, projection, sorting and pagination",
 		Fields:      fields,
 		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
 			var cfg Config
@@ -0,0 +1,215 @@
+package ndjsonimport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBatchSize = 100
+	// maxLineSize bounds a single record so one oversized line cannot exhaust
+	// memory; the body as a whole is never buffered.
+	maxLineSize = 1 << 20
+)
+
+type storeIntegration interface {
+	integration.Integration
+	Store(ctx context.Context, data map[string]interface{}, options map[string]string) error
+}
+
+// batchStoreIntegration is implemented by integrations that can insert many
+// records in one round trip. Others are written to record by record.
+type batchStoreIntegration interface {
+	StoreMany(ctx context.Context, items []map[string]interface{}, options map[string]string) error
+}
+
+type Config struct {
+	IntegrationID     string            `json:"integrationID" yaml:"integrationID"`
+	Table             string            `json:"table" yaml:"table"`
+	DatasourceOptions map[string]string `json:"datasourceOptions" yaml:"datasourceOptions"`
+	// BatchSize is how many records are stored per write. Defaults to 100.
+	BatchSize int `json:"batchSize" yaml:"batchSize"`
+}
+
+// Import reads the request body as newline-delimited JSON, one object per
+// line, and stores the records in batches as they arrive, so imports of any
+// size run in constant memory. Blank lines are skipped. A malformed line
+// stops the import with its line number; batches before it are already
+// stored.
+type Import struct {
+	cfg       Config
+	i         storeIntegration
+	batchSize int
+}
+
+func (n *Import) Type() string {
+	return "ndjsonimport"
+}
+
+func (n *Import) SupportsReplica() bool {
+	return false
+}
+
+func New(cfg Config) (*Import, error) {
+	cfg.IntegrationID = integration.ResolveID(cfg.IntegrationID)
+	if cfg.IntegrationID == "" {
+		return nil, errors.New("integrationID is required")
+	}
+	if cfg.Table == "" {
+		return nil, errors.New("table is required")
+	}
+	if cfg.BatchSize < 0 {
+		return nil, fmt.Errorf("batchSize must be positive, got %d", cfg.BatchSize)
+	}
+
+	i, err := integration.GetIntegration(context.Background(), cfg.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	si, ok := i.(storeIntegration)
+	if !ok {
+		return nil, errors.New("integration does not support store operations")
+	}
+
+	batchSize := cfg.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+	return &Import{cfg: cfg, i: si, batchSize: batchSize}, nil
+}
+
+func (n *Import) Execute(ctx context.Context) (interface{}, map[string]string, error) {
+	logger := logging.FromContext(ctx).With(zap.String("execution_type", n.Type()))
+
+	req, err := plan.RequestFromContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Body == nil {
+		return nil, nil, fmt.Errorf("%w: request has no body", plan.ErrFailure)
+	}
+
+	options := map[string]string{"collection": n.cfg.Table}
+	for k, v := range n.cfg.DatasourceOptions {
+		options[k] = v
+	}
+
+	var (
+		batch   = make([]map[string]interface{}, 0, n.batchSize)
+		stored  int
+		batches int
+		line    int
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := n.store(ctx, batch, options); err != nil {
+			return fmt.Errorf("error storing records %d-%d: %w", stored+1, stored+len(batch), err)
+		}
+		stored += len(batch)
+		batches++
+		batch = make([]map[string]interface{}, 0, n.batchSize)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(req.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal(raw, &record); err != nil || record == nil {
+			return nil, nil, fmt.Errorf("%w: line %d is not a JSON object (%d records stored)", plan.ErrFailure, line, stored)
+		}
+		batch = append(batch, record)
+		if len(batch) == n.batchSize {
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, nil, fmt.Errorf("%w: line %d exceeds %d bytes (%d records stored)", plan.ErrFailure, line+1, maxLineSize, stored)
+		}
+		return nil, nil, fmt.Errorf("error reading request body: %w", err)
+	}
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+
+	logger.Debug("ndjson import finished", zap.Int("records", stored), zap.Int("batches", batches))
+	return map[string]interface{}{"stored": stored, "batches": batches}, nil, nil
+}
+
+func (n *Import) store(ctx context.Context, batch []map[string]interface{}, options map[string]string) error {
+	if bi, ok := n.i.(batchStoreIntegration); ok {
+		return bi.StoreMany(ctx, batch, options)
+	}
+	for _, record := range batch {
+		if err := n.i.Store(ctx, record, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"integrationID": {
+			Type:        actions.FieldTypeIntegration,
+			Label:       "Integration ID",
+			Placeholder: "Database integration identifier",
+			Required:    true,
+		},
+		"table": {
+			Type:        actions.FieldTypeString,
+			Label:       "Table",
+			Placeholder: "Database table name",
+			Required:    true,
+		},
+		"datasourceOptions": {
+			Type:        actions.FieldTypeMap,
+			Label:       "Datasource Options",
+			Placeholder: "Additional datasource options",
+			Required:    false,
+		},
+		"batchSize": {
+			Type:        actions.FieldTypeNumber,
+			Label:       "Batch Size",
+			Placeholder: "Records stored per write",
+			Default:     defaultBatchSize,
+		},
+	}
+
+	if err := actions.RegisterAction("ndjsonimport", actions.ActionRegistrationInfo{
+		Name:        "NDJSON Import",
+		Description: "Streams a newline-delimited JSON request body into an integration in batches",
+		SideEffects: true,
+		Fields:      fields,
+		UseV2:       true,
+		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
+			var cfg Config
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating ndjsonimport action: %v", err)
+			}
+			return New(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
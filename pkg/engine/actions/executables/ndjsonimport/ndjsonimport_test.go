@@ -0,0 +1,137 @@
+package ndjsonimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordStore struct {
+	records []map[string]interface{}
+	options map[string]string
+}
+
+func (r *recordStore) Type() string { return "records" }
+
+func (r *recordStore) Store(_ context.Context, data map[string]interface{}, options map[string]string) error {
+	r.records = append(r.records, data)
+	r.options = options
+	return nil
+}
+
+type batchStore struct {
+	recordStore
+	batches []int
+}
+
+func (b *batchStore) StoreMany(_ context.Context, items []map[string]interface{}, options map[string]string) error {
+	b.batches = append(b.batches, len(items))
+	b.records = append(b.records, items...)
+	b.options = options
+	return nil
+}
+
+func register(t *testing.T, id string, i integration.Integration) {
+	t.Helper()
+	integration.ReplaceIntegrationType("records", func(map[string]any) (integration.Integration, error) {
+		return i, nil
+	})
+	require.NoError(t, integration.InitializeIntegration("records", id, nil, false))
+}
+
+func requestContext(body io.Reader) context.Context {
+	req := httptest.NewRequest(http.MethodPost, "/import", body)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	return plan.WithRequest(context.Background(), req)
+}
+
+// ndjson streams n records through a pipe so the body is never held in full.
+func ndjson(n int) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		for i := 1; i <= n; i++ {
+			if _, err := fmt.Fprintf(pw, `{"seq": %d, "name": "user-%d"}`+"\n", i, i); err != nil {
+				return
+			}
+		}
+		pw.Close()
+	}()
+	return pr
+}
+
+func TestImport_Execute(t *testing.T) {
+	t.Run("stores records in batches", func(t *testing.T) {
+		store := &batchStore{}
+		register(t, "batchdb", store)
+		imp, err := New(Config{IntegrationID: "batchdb", Table: "users", BatchSize: 100})
+		require.NoError(t, err)
+
+		resp, _, err := imp.Execute(requestContext(ndjson(1050)))
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]interface{}{"stored": 1050, "batches": 11}, resp)
+		assert.Equal(t, []int{100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 50}, store.batches)
+		require.Len(t, store.records, 1050)
+		assert.EqualValues(t, 1, store.records[0]["seq"])
+		assert.EqualValues(t, 1050, store.records[1049]["seq"])
+		assert.Equal(t, "users", store.options["collection"])
+	})
+
+	t.Run("integrations without StoreMany store record by record", func(t *testing.T) {
+		store := &recordStore{}
+		register(t, "plaindb", store)
+		imp, err := New(Config{IntegrationID: "plaindb", Table: "users", BatchSize: 2})
+		require.NoError(t, err)
+
+		body := strings.NewReader("{\"a\": 1}\n\n{\"a\": 2}\r\n{\"a\": 3}")
+		resp, _, err := imp.Execute(requestContext(body))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"stored": 3, "batches": 2}, resp)
+		assert.Len(t, store.records, 3)
+	})
+
+	t.Run("malformed line is reported with its number", func(t *testing.T) {
+		store := &batchStore{}
+		register(t, "baddb", store)
+		imp, err := New(Config{IntegrationID: "baddb", Table: "users", BatchSize: 2})
+		require.NoError(t, err)
+
+		body := strings.NewReader("{\"a\": 1}\n{\"a\": 2}\n{\"a\": 3}\n{\"a\": \n{\"a\": 5}\n")
+		_, _, err = imp.Execute(requestContext(body))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plan.ErrFailure))
+		assert.ErrorContains(t, err, "line 4 is not a JSON object (2 records stored)")
+		assert.Equal(t, []int{2}, store.batches)
+	})
+
+	t.Run("oversized line is rejected", func(t *testing.T) {
+		register(t, "bigdb", &batchStore{})
+		imp, err := New(Config{IntegrationID: "bigdb", Table: "users"})
+		require.NoError(t, err)
+
+		body := strings.NewReader(`{"a": 1}` + "\n" + `{"blob": "` + strings.Repeat("x", maxLineSize) + `"}`)
+		_, _, err = imp.Execute(requestContext(body))
+		assert.ErrorContains(t, err, "line 2 exceeds")
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Table: "users"})
+	assert.ErrorContains(t, err, "integrationID is required")
+
+	_, err = New(Config{IntegrationID: "db"})
+	assert.ErrorContains(t, err, "table is required")
+
+	_, err = New(Config{IntegrationID: "db", Table: "users", BatchSize: -1})
+	assert.ErrorContains(t, err, "batchSize must be positive")
+}
@@ -0,0 +1,282 @@
+package paginateupstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/Servflow/servflow/pkg/tracing"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultCursorParam = "cursor"
+	defaultMaxPages    = 10
+)
+
+type Config struct {
+	URL     string            `json:"url" yaml:"url"`
+	Method  string            `json:"method" yaml:"method"`
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	// ItemsPath is the path of the list of items in every page, e.g.
+	// "data.items". Empty takes the whole page as the list.
+	ItemsPath string `json:"itemsPath" yaml:"itemsPath"`
+	// NextPath is the path of the next-page cursor in every page, e.g.
+	// "meta.next_cursor". Paging stops once it is missing, null or empty.
+	NextPath string `json:"nextPath" yaml:"nextPath"`
+	// CursorParam is the query parameter the cursor is sent in. A cursor that
+	// is an absolute URL is requested as it is instead.
+	CursorParam string `json:"cursorParam" yaml:"cursorParam"`
+	// MaxPages bounds the number of pages requested. Paging stops there with
+	// the items gathered so far, whether or not there are more pages.
+	MaxPages int `json:"maxPages" yaml:"maxPages"`
+}
+
+// PaginateUpstream requests the pages of a paginated upstream API one after
+// the other, following the cursor each page returns, and concatenates their
+// items into one list.
+type PaginateUpstream struct {
+	client *http.Client
+	cfg    Config
+}
+
+func (p *PaginateUpstream) Type() string {
+	return "paginateupstream"
+}
+
+func (p *PaginateUpstream) SupportsReplica() bool {
+	return true
+}
+
+func New(cfg Config) (*PaginateUpstream, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("url is required")
+	}
+	if cfg.NextPath == "" {
+		return nil, errors.New("nextPath is required")
+	}
+	if cfg.MaxPages < 0 {
+		return nil, fmt.Errorf("maxPages must not be negative, got %d", cfg.MaxPages)
+	}
+	if cfg.Method == "" {
+		cfg.Method = http.MethodGet
+	}
+	if cfg.CursorParam == "" {
+		cfg.CursorParam = defaultCursorParam
+	}
+	if cfg.MaxPages == 0 {
+		cfg.MaxPages = defaultMaxPages
+	}
+	return &PaginateUpstream{
+		client: &http.Client{},
+		cfg:    cfg,
+	}, nil
+}
+
+func (p *PaginateUpstream) Execute(ctx context.Context) (interface{}, map[string]string, error) {
+	logger := logging.FromContext(ctx).With(zap.String("execution_type", p.Type()))
+	ctx = logging.WithLogger(ctx, logger)
+
+	rc, err := requestctx.FromContextOrError(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get request context: %w", err)
+	}
+
+	// the URL and headers resolve once; only the cursor changes between pages
+	batch := []string{p.cfg.URL}
+	for k, v := range p.cfg.Headers {
+		batch = append(batch, k, v)
+	}
+	resolved, err := rc.ResolveBatch(ctx, batch...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve paginateupstream config: %w", err)
+	}
+	firstURL := resolved[0]
+	headers := make(map[string]string, len(p.cfg.Headers))
+	for i := 1; i < len(resolved); i += 2 {
+		headers[resolved[i]] = resolved[i+1]
+	}
+
+	items := []interface{}{}
+	pageURL := firstURL
+	pages := 0
+	hasMore := false
+	for pages < p.cfg.MaxPages {
+		body, err := p.get(ctx, pageURL, headers)
+		if err != nil {
+			return nil, nil, err
+		}
+		pages++
+
+		pageItems, err := p.items(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("page %d: %w", pages, err)
+		}
+		items = append(items, pageItems...)
+
+		cursor := nextCursor(body, p.cfg.NextPath)
+		logger.Debug("fetched page", zap.Int("page", pages), zap.Int("items", len(pageItems)), zap.Bool("has_next", cursor != ""))
+		if hasMore = cursor != ""; !hasMore {
+			break
+		}
+		if pageURL, err = p.nextURL(firstURL, cursor); err != nil {
+			return nil, nil, err
+		}
+	}
+	if hasMore {
+		logger.Warn("stopped paging at the page limit", zap.Int("max_pages", p.cfg.MaxPages))
+	}
+
+	fields := map[string]string{
+		"pages":     strconv.Itoa(pages),
+		"truncated": strconv.FormatBool(hasMore),
+	}
+	return items, fields, nil
+}
+
+// get requests one page. Only a 2xx response with a JSON body is a page.
+func (p *PaginateUpstream) get(ctx context.Context, pageURL string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, p.cfg.Method, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	tracing.InjectHTTPHeaders(ctx, req.Header)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: unexpected response code %d", plan.ErrFailure, resp.StatusCode)
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("%w: invalid JSON response", plan.ErrFailure)
+	}
+	return body, nil
+}
+
+func (p *PaginateUpstream) items(body []byte) ([]interface{}, error) {
+	value := gjson.ParseBytes(body)
+	if p.cfg.ItemsPath != "" {
+		value = gjson.GetBytes(body, p.cfg.ItemsPath)
+	}
+	switch {
+	case !value.Exists() || value.Type == gjson.Null:
+		return nil, nil
+	case !value.IsArray():
+		return nil, fmt.Errorf("%w: items at '%s' are not a list", plan.ErrFailure, p.cfg.ItemsPath)
+	}
+	items, _ := value.Value().([]interface{})
+	return items, nil
+}
+
+// nextCursor returns the cursor at path in body, "" when there is none.
+func nextCursor(body []byte, path string) string {
+	value := gjson.GetBytes(body, path)
+	if !value.Exists() || value.Type == gjson.Null || value.Type == gjson.False {
+		return ""
+	}
+	return value.String()
+}
+
+// nextURL returns the URL of the page after cursor: the cursor itself when it
+// is an absolute URL, otherwise firstURL with the cursor parameter set.
+func (p *PaginateUpstream) nextURL(firstURL, cursor string) (string, error) {
+	if strings.HasPrefix(cursor, "http://") || strings.HasPrefix(cursor, "https://") {
+		return cursor, nil
+	}
+	u, err := url.Parse(firstURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set(p.cfg.CursorParam, cursor)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"url": {
+			Type:        actions.FieldTypeString,
+			Label:       "URL",
+			Placeholder: "https://api.example.com/items",
+			Required:    true,
+		},
+		"method": {
+			Type:        actions.FieldTypeString,
+			Label:       "HTTP Method",
+			Placeholder: "GET or POST",
+			Required:    false,
+			Default:     http.MethodGet,
+			Values:      []string{http.MethodGet, http.MethodPost},
+		},
+		"headers": {
+			Type:        actions.FieldTypeMap,
+			Label:       "Headers",
+			Placeholder: "HTTP headers as key-value pairs",
+			Required:    false,
+		},
+		"itemsPath": {
+			Type:        actions.FieldTypeString,
+			Label:       "Items Path",
+			Placeholder: "Path of the list in each page (e.g. data.items)",
+			Required:    false,
+		},
+		"nextPath": {
+			Type:        actions.FieldTypeString,
+			Label:       "Next Cursor Path",
+			Placeholder: "Path of the next-page cursor in each page (e.g. meta.next)",
+			Required:    true,
+		},
+		"cursorParam": {
+			Type:        actions.FieldTypeString,
+			Label:       "Cursor Parameter",
+			Placeholder: "Query parameter the cursor is sent in",
+			Required:    false,
+			Default:     defaultCursorParam,
+		},
+		"maxPages": {
+			Type:        actions.FieldTypeNumber,
+			Label:       "Max Pages",
+			Placeholder: "Maximum number of pages to request",
+			Required:    false,
+			Default:     defaultMaxPages,
+		},
+	}
+
+	if err := actions.RegisterAction("paginateupstream", actions.ActionRegistrationInfo{
+		Name:        "Paginate Upstream",
+		Description: "Requests every page of a paginated API by following its next-page cursor and returns their items as one list",
+		Fields:      fields,
+		UseV2:       true,
+		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
+			var cfg Config
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating paginateupstream action: %v", err)
+			}
+			return New(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,118 @@
+package paginateupstream
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedAPI serves three pages of two items, linked by the cursor in
+// meta.next, and counts the requests it gets.
+func pagedAPI(t *testing.T) (*httptest.Server, *int) {
+	pages := map[string]string{
+		"":   `{"data": [{"id": 1}, {"id": 2}], "meta": {"next": "p2"}}`,
+		"p2": `{"data": [{"id": 3}, {"id": 4}], "meta": {"next": "p3"}}`,
+		"p3": `{"data": [{"id": 5}, {"id": 6}], "meta": {"next": null}}`,
+	}
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		assert.Equal(t, "20", r.URL.Query().Get("limit"), "the query of the first URL is kept")
+		page, ok := pages[r.URL.Query().Get("after")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestPaginateUpstream_Execute(t *testing.T) {
+	newAction := func(t *testing.T, maxPages int) *PaginateUpstream {
+		p, err := New(Config{
+			URL:         "{{ .base }}?limit=20",
+			Headers:     map[string]string{"X-Api-Key": "{{ .key }}"},
+			ItemsPath:   "data",
+			NextPath:    "meta.next",
+			CursorParam: "after",
+			MaxPages:    maxPages,
+		})
+		require.NoError(t, err)
+		return p
+	}
+
+	t.Run("aggregates every page", func(t *testing.T) {
+		srv, calls := pagedAPI(t)
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"base": srv.URL, "key": "secret"}, ""))
+
+		items, fields, err := newAction(t, 0).Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"id": float64(1)},
+			map[string]interface{}{"id": float64(2)},
+			map[string]interface{}{"id": float64(3)},
+			map[string]interface{}{"id": float64(4)},
+			map[string]interface{}{"id": float64(5)},
+			map[string]interface{}{"id": float64(6)},
+		}, items)
+		assert.Equal(t, map[string]string{"pages": "3", "truncated": "false"}, fields)
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("stops at max pages", func(t *testing.T) {
+		srv, calls := pagedAPI(t)
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"base": srv.URL, "key": "secret"}, ""))
+
+		items, fields, err := newAction(t, 2).Execute(ctx)
+		require.NoError(t, err)
+		assert.Len(t, items, 4)
+		assert.Equal(t, map[string]string{"pages": "2", "truncated": "true"}, fields)
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("failed page", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"base": srv.URL}, ""))
+
+		_, _, err := newAction(t, 0).Execute(ctx)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plan.ErrFailure))
+	})
+}
+
+func TestPaginateUpstream_NextURL(t *testing.T) {
+	p, err := New(Config{URL: "https://api.example.com/items", NextPath: "next"})
+	require.NoError(t, err)
+
+	next, err := p.nextURL("https://api.example.com/items?limit=5", "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/items?cursor=abc&limit=5", next)
+
+	next, err = p.nextURL("https://api.example.com/items", "https://api.example.com/items?page=2")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/items?page=2", next)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{NextPath: "next"})
+	assert.Error(t, err)
+	_, err = New(Config{URL: "https://api.example.com"})
+	assert.Error(t, err)
+	_, err = New(Config{URL: "https://api.example.com", NextPath: "next", MaxPages: -1})
+	assert.Error(t, err)
+}
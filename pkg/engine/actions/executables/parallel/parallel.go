@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/Servflow/servflow/pkg/apiconfig"
 	"github.com/Servflow/servflow/pkg/engine/actions"
 	"github.com/Servflow/servflow/pkg/engine/plan"
+	requestctx "github.com/Servflow/servflow/pkg/engine/requestctx"
 	"github.com/Servflow/servflow/pkg/logging"
 	"go.uber.org/zap"
 )
@@ -17,8 +21,22 @@ import (
 type Config struct {
 	Steps         []string `json:"steps" yaml:"steps"`
 	StopOnFailure bool     `json:"stopOnFailure" yaml:"stopOnFailure"`
+
+	// MaxConcurrency caps how many steps run at once. Zero runs them all at
+	// once.
+	MaxConcurrency int `json:"maxConcurrency" yaml:"maxConcurrency"`
+	// Timeout bounds the whole group, e.g. "5s". Steps still running when it
+	// passes are cancelled and fail with ErrStepTimeout.
+	Timeout string `json:"timeout" yaml:"timeout"`
+	// CollectResults returns the output of every action step that succeeds,
+	// keyed by its action key, as the output of the parallel action. Other
+	// steps, e.g. conditionals, have no output and are left out.
+	CollectResults bool `json:"collectResults" yaml:"collectResults"`
 }
 
+// ErrStepTimeout is the error of a step cut off by the group's Timeout.
+var ErrStepTimeout = errors.New("parallel step timed out")
+
 type Exec struct {
 	config Config
 }
@@ -27,6 +45,9 @@ func (e *Exec) Config() string {
 	return ""
 }
 
+// groupError collects the failures of the steps of a parallel run, keyed by
+// step id. Error() joins them for logs; StepErrors and errors.Is/As give
+// callers the individual failures.
 type groupError struct {
 	errors               map[string]error
 	count                int
@@ -36,12 +57,63 @@ type groupError struct {
 
 func (e *groupError) Error() string {
 	var errorsList []string
-	for step, err := range e.errors {
-		errorsList = append(errorsList, fmt.Sprintf("error in %s: %v", step, err))
+	for _, step := range e.Steps() {
+		errorsList = append(errorsList, fmt.Sprintf("error in %s: %v", step, e.StepError(step)))
 	}
 	return strings.Join(errorsList, ", ")
 }
 
+// Steps returns the ids of the failed steps in sorted order.
+func (e *groupError) Steps() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	steps := make([]string, 0, len(e.errors))
+	for step := range e.errors {
+		steps = append(steps, step)
+	}
+	sort.Strings(steps)
+	return steps
+}
+
+// StepError returns the error step failed with, or nil if it did not fail.
+func (e *groupError) StepError(step string) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.errors[step]
+}
+
+// StepErrors returns a copy of the failures keyed by step id.
+func (e *groupError) StepErrors() map[string]error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	out := make(map[string]error, len(e.errors))
+	for step, err := range e.errors {
+		out[step] = err
+	}
+	return out
+}
+
+// Unwrap exposes every step's error to errors.Is and errors.As.
+func (e *groupError) Unwrap() []error {
+	steps := e.Steps()
+	errs := make([]error, len(steps))
+	for i, step := range steps {
+		errs[i] = e.StepError(step)
+	}
+	return errs
+}
+
+// StepErrors returns the per-step failures of a parallel run found in err's
+// chain, keyed by step id, or nil when err did not come from a parallel run
+// of several failing steps.
+func StepErrors(err error) map[string]error {
+	var ge *groupError
+	if !errors.As(err, &ge) {
+		return nil
+	}
+	return ge.StepErrors()
+}
+
 func (e *groupError) add(step string, err error) {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
@@ -72,53 +144,77 @@ func (e *groupError) firstError() error {
 	return err
 }
 
+// Execute runs every step concurrently, at most MaxConcurrency at a time when
+// it is set. With StopOnFailure the first failure cancels the context the
+// other steps run under, so in-flight siblings stop promptly instead of
+// running to completion and steps still waiting for a slot do not start;
+// errors they return because of that cancellation are not reported.
+// Cancellation of ctx itself (e.g. the request's deadline) is still reported,
+// as is every step cut off by Timeout.
 func (e *Exec) Execute(ctx context.Context, modifiedConfig string) (interface{}, map[string]string, error) {
-	newCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	type customError struct {
-		err  error
-		step string
+	groupCtx := ctx
+	if e.config.Timeout != "" {
+		timeout, err := parseTimeout(e.config.Timeout)
+		if err != nil {
+			return nil, nil, err
+		}
+		var cancelGroup context.CancelFunc
+		groupCtx, cancelGroup = context.WithTimeout(ctx, timeout)
+		defer cancelGroup()
 	}
+	stepCtx, cancel := context.WithCancelCause(groupCtx)
+	defer cancel(nil)
+	timedOut := func() bool {
+		return ctx.Err() == nil && errors.Is(groupCtx.Err(), context.DeadlineExceeded)
+	}
+	timeoutErr := fmt.Errorf("%w after %s", ErrStepTimeout, e.config.Timeout)
 
-	errChan := make(chan customError, len(e.config.Steps))
-	var allErrors groupError
-
-	var wg sync.WaitGroup
+	var (
+		allErrors groupError
+		wg        sync.WaitGroup
+		slots     chan struct{}
+		resultsMu sync.Mutex
+		results   = map[string]interface{}{}
+	)
+	if e.config.MaxConcurrency > 0 {
+		slots = make(chan struct{}, e.config.MaxConcurrency)
+	}
 	for _, step := range e.config.Steps {
 		wg.Add(1)
 		go func(s string) {
 			defer wg.Done()
-			logging.FromContext(newCtx).Debug("executing parallel step", zap.String("step", s))
-			_, err := plan.ExecuteFromContext(newCtx, s)
-			if err != nil {
+			if slots != nil {
 				select {
-				case errChan <- customError{err: err, step: s}:
-				case <-newCtx.Done():
-					// Context canceled, don't block
+				case slots <- struct{}{}:
+					defer func() { <-slots }()
+				case <-stepCtx.Done():
+					if timedOut() {
+						allErrors.add(s, timeoutErr)
+					} else if ctx.Err() != nil {
+						allErrors.add(s, ctx.Err())
+					}
+					return
 				}
 			}
-		}(step)
-	}
-
-	// Process errors in a separate goroutine
-	errorsDone := make(chan struct{})
-	go func() {
-		defer close(errorsDone)
-		for err := range errChan {
-			if err.err != nil && !isContextCancellationError(err.err) {
-				allErrors.add(err.step, err.err)
-				if e.config.StopOnFailure {
-					cancel()
+			logging.FromContext(stepCtx).Debug("executing parallel step", zap.String("step", s))
+			_, err := plan.ExecuteFromContext(stepCtx, s)
+			if err == nil && e.config.CollectResults && strings.HasPrefix(s, apiconfig.ActionConfigPrefix) {
+				if err = collectResult(stepCtx, s, &resultsMu, results); err == nil {
+					return
 				}
 			}
-		}
-	}()
-
+			if err != nil && timedOut() {
+				err = timeoutErr
+			} else if err == nil || isContextCancellationError(err) || canceledBySibling(ctx, stepCtx, err) {
+				return
+			}
+			allErrors.add(s, err)
+			if e.config.StopOnFailure {
+				cancel(fmt.Errorf("parallel step %s failed: %w", s, err))
+			}
+		}(step)
+	}
 	wg.Wait()
-	close(errChan)
-
-	<-errorsDone
 
 	if allErrors.Count() > 0 {
 		if e.config.StopOnFailure {
@@ -127,9 +223,40 @@ func (e *Exec) Execute(ctx context.Context, modifiedConfig string) (interface{},
 			return nil, nil, &allErrors
 		}
 	}
+	if e.config.CollectResults {
+		return results, nil, nil
+	}
 	return nil, nil, nil
 }
 
+// collectResult copies the output action step stored under its action key
+// into results.
+func collectResult(ctx context.Context, step string, mu *sync.Mutex, results map[string]interface{}) error {
+	key := strings.TrimPrefix(step, apiconfig.ActionConfigPrefix)
+	out, err := requestctx.GetRequestVariable(ctx, key)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	results[key] = out
+	return nil
+}
+
+func parseTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid parallel timeout %q: must be a positive duration", s)
+	}
+	return d, nil
+}
+
+// canceledBySibling reports whether err is the fallout of a sibling's failure
+// canceling stepCtx rather than a failure of its own.
+func canceledBySibling(parent, stepCtx context.Context, err error) bool {
+	return parent.Err() == nil && stepCtx.Err() != nil && errors.Is(err, context.Canceled)
+}
+
 func (e *Exec) Type() string {
 	return "parallel"
 }
@@ -154,6 +281,20 @@ func init() {
 			Default: true,
 			Label:   "Stop On Failure",
 		},
+		"maxConcurrency": {
+			Type:        actions.FieldTypeNumber,
+			Label:       "Max Concurrency",
+			Placeholder: "Maximum steps running at once, 0 for no limit",
+		},
+		"timeout": {
+			Type:        actions.FieldTypeString,
+			Label:       "Timeout",
+			Placeholder: "Time allowed for the whole group, e.g. 5s",
+		},
+		"collectResults": {
+			Type:  actions.FieldTypeBoolean,
+			Label: "Collect Results",
+		},
 	}
 
 	if err := actions.RegisterAction("parallel", actions.ActionRegistrationInfo{
@@ -165,6 +306,14 @@ func init() {
 			if err := json.Unmarshal(config, &cfg); err != nil {
 				return nil, fmt.Errorf("error creating parallel action: %v", err)
 			}
+			if cfg.MaxConcurrency < 0 {
+				return nil, fmt.Errorf("error creating parallel action: maxConcurrency must not be negative")
+			}
+			if cfg.Timeout != "" {
+				if _, err := parseTimeout(cfg.Timeout); err != nil {
+					return nil, fmt.Errorf("error creating parallel action: %v", err)
+				}
+			}
 			return &Exec{cfg}, nil
 		},
 	}); err != nil {
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-
+	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/Servflow/servflow/pkg/apiconfig"
 	"github.com/Servflow/servflow/pkg/engine/actions"
@@ -219,7 +221,8 @@ func TestParallelExec_Execute(t *testing.T) {
 		error2 := errors.New("action2 failed")
 		error3 := errors.New("action3 failed")
 
-		mockAction1.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, error1)
+		// whichever fails first cancels the others, which may then never run
+		mockAction1.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, error1).AnyTimes()
 		mockAction2.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, error2).AnyTimes()
 		mockAction3.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, error3).AnyTimes()
 
@@ -389,3 +392,331 @@ func TestParallelExec_Execute(t *testing.T) {
 		assert.Nil(t, result)
 	})
 }
+
+func TestParallelExec_Join(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	branchA := plan.NewMockActionExecutable(ctrl)
+	branchB := plan.NewMockActionExecutable(ctrl)
+	merged := plan.NewMockActionExecutable(ctrl)
+
+	registry := actions.NewRegistry()
+	for id, exec := range map[string]*plan.MockActionExecutable{"branchA": branchA, "branchB": branchB, "merged": merged} {
+		registry.ReplaceActionType(id+"_type", func(config json.RawMessage) (actions.ActionExecutable, error) {
+			return exec, nil
+		})
+		exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+		exec.EXPECT().Type().Return("mock").AnyTimes()
+	}
+	branchA.EXPECT().Config().Return("").AnyTimes()
+	branchB.EXPECT().Config().Return("").AnyTimes()
+	branchA.EXPECT().Execute(gomock.Any(), gomock.Any()).Return("outA", nil, nil)
+	branchB.EXPECT().Execute(gomock.Any(), gomock.Any()).Return("outB", nil, nil)
+
+	// the step after the join reads both branches' outputs and must run once
+	merged.EXPECT().Config().Return(`{{ .variable_actions_branchA }}+{{ .variable_actions_branchB }}`).AnyTimes()
+	merged.EXPECT().Execute(gomock.Any(), "outA+outB").Return("done", nil, nil).Times(1)
+
+	planner := plan.NewPlannerV2(plan.PlannerConfig{
+		Actions: map[string]apiconfig.Action{
+			"branchA": {Name: "branchA", Type: "branchA_type", Next: "join.merge"},
+			"branchB": {Name: "branchB", Type: "branchB_type", Next: "join.merge"},
+			"merged":  {Name: "merged", Type: "merged_type"},
+		},
+		Joins: map[string]apiconfig.Join{
+			"merge": {
+				Name:    "merge",
+				WaitFor: []string{"action.branchA", "action.branchB"},
+				Next:    "action.merged",
+			},
+		},
+		CustomRegistry: registry,
+	}, logging.GetNewLogger())
+	testPlan, err := planner.Plan()
+	require.NoError(t, err)
+
+	ctx := requestctx.NewTestContext()
+	ctx = context.WithValue(ctx, plan.ContextKey, testPlan)
+
+	parallelExec := &Exec{
+		config: Config{
+			Steps:         []string{"action.branchA", "action.branchB"},
+			StopOnFailure: true,
+		},
+	}
+	_, _, err = parallelExec.Execute(ctx, "")
+	require.NoError(t, err)
+
+	out, err := requestctx.GetRequestVariable(ctx, "merged")
+	require.NoError(t, err)
+	assert.Equal(t, "done", out)
+}
+
+func TestParallelExec_StopOnFailureCancelsSiblings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fast := plan.NewMockActionExecutable(ctrl)
+	slow := plan.NewMockActionExecutable(ctrl)
+
+	registry := actions.NewRegistry()
+	for id, exec := range map[string]*plan.MockActionExecutable{"fast": fast, "slow": slow} {
+		registry.ReplaceActionType(id+"_type", func(config json.RawMessage) (actions.ActionExecutable, error) {
+			return exec, nil
+		})
+		exec.EXPECT().Config().Return("").AnyTimes()
+		exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+		exec.EXPECT().Type().Return("mock").AnyTimes()
+	}
+
+	started := make(chan struct{})
+	canceled := make(chan error, 1)
+	slow.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ string) (interface{}, map[string]string, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			canceled <- context.Cause(ctx)
+			return nil, nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return "too late", nil, nil
+		}
+	})
+	fastErr := errors.New("fast failed")
+	fast.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ string) (interface{}, map[string]string, error) {
+		<-started
+		return nil, nil, fastErr
+	})
+
+	planner := plan.NewPlannerV2(plan.PlannerConfig{
+		Actions: map[string]apiconfig.Action{
+			"fast": {Name: "fast", Type: "fast_type"},
+			"slow": {Name: "slow", Type: "slow_type"},
+		},
+		CustomRegistry: registry,
+	}, logging.GetNewLogger())
+	testPlan, err := planner.Plan()
+	require.NoError(t, err)
+
+	ctx := requestctx.NewTestContext()
+	ctx = context.WithValue(ctx, plan.ContextKey, testPlan)
+
+	parallelExec := &Exec{config: Config{Steps: []string{"action.fast", "action.slow"}, StopOnFailure: true}}
+
+	start := time.Now()
+	_, _, err = parallelExec.Execute(ctx, "")
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second, "slow sibling should stop on cancellation")
+	assert.ErrorIs(t, err, fastErr)
+	assert.NotContains(t, err.Error(), "context canceled", "the canceled sibling is not reported")
+
+	select {
+	case cause := <-canceled:
+		assert.ErrorIs(t, cause, fastErr, "the cancellation cause names the failed sibling")
+	default:
+		t.Fatal("slow sibling did not observe cancellation")
+	}
+}
+
+type stepStatusError struct {
+	status int
+}
+
+func (e *stepStatusError) Error() string {
+	return fmt.Sprintf("status %d", e.status)
+}
+
+func TestGroupError(t *testing.T) {
+	notFound := errors.New("not found")
+	var group groupError
+	group.add("action.lookup", fmt.Errorf("error executing step: %w", notFound))
+	group.add("action.charge", fmt.Errorf("error executing step: %w", &stepStatusError{status: 402}))
+
+	t.Run("individual step errors are retrievable", func(t *testing.T) {
+		assert.Equal(t, []string{"action.charge", "action.lookup"}, group.Steps())
+		assert.ErrorIs(t, group.StepError("action.lookup"), notFound)
+		assert.Nil(t, group.StepError("action.other"))
+
+		stepErrs := StepErrors(fmt.Errorf("flow failed: %w", &group))
+		require.Len(t, stepErrs, 2)
+		assert.ErrorIs(t, stepErrs["action.lookup"], notFound)
+		assert.Nil(t, StepErrors(notFound))
+	})
+
+	t.Run("errors.Is and errors.As see wrapped step errors", func(t *testing.T) {
+		var err error = fmt.Errorf("flow failed: %w", &group)
+		assert.ErrorIs(t, err, notFound)
+
+		var statusErr *stepStatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, 402, statusErr.status)
+	})
+
+	t.Run("message lists every step in a stable order", func(t *testing.T) {
+		assert.Equal(t,
+			"error in action.charge: error executing step: status 402, error in action.lookup: error executing step: not found",
+			group.Error())
+	})
+}
+
+func TestParallelExec_MaxConcurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const (
+		stepCount = 12
+		limit     = 3
+	)
+	var (
+		running atomic.Int32
+		peak    atomic.Int32
+	)
+	registry := actions.NewRegistry()
+	cfgActions := make(map[string]apiconfig.Action, stepCount)
+	steps := make([]string, 0, stepCount)
+	for i := 0; i < stepCount; i++ {
+		id := fmt.Sprintf("step%d", i)
+		exec := plan.NewMockActionExecutable(ctrl)
+		exec.EXPECT().Config().Return("").AnyTimes()
+		exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+		exec.EXPECT().Type().Return("mock").AnyTimes()
+		exec.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ string) (interface{}, map[string]string, error) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return nil, nil, nil
+		})
+		registry.ReplaceActionType(id+"_type", func(config json.RawMessage) (actions.ActionExecutable, error) {
+			return exec, nil
+		})
+		cfgActions[id] = apiconfig.Action{Name: id, Type: id + "_type"}
+		steps = append(steps, "action."+id)
+	}
+
+	planner := plan.NewPlannerV2(plan.PlannerConfig{
+		Actions:        cfgActions,
+		CustomRegistry: registry,
+	}, logging.GetNewLogger())
+	testPlan, err := planner.Plan()
+	require.NoError(t, err)
+
+	ctx := requestctx.NewTestContext()
+	ctx = context.WithValue(ctx, plan.ContextKey, testPlan)
+
+	parallelExec := &Exec{config: Config{Steps: steps, MaxConcurrency: limit}}
+	_, _, err = parallelExec.Execute(ctx, "")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, peak.Load(), int32(limit))
+	assert.Greater(t, peak.Load(), int32(1), "steps still run concurrently")
+}
+
+func TestParallelExec_Timeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fast := plan.NewMockActionExecutable(ctrl)
+	slow := plan.NewMockActionExecutable(ctrl)
+
+	registry := actions.NewRegistry()
+	for id, exec := range map[string]*plan.MockActionExecutable{"fast": fast, "slow": slow} {
+		registry.ReplaceActionType(id+"_type", func(config json.RawMessage) (actions.ActionExecutable, error) {
+			return exec, nil
+		})
+		exec.EXPECT().Config().Return("").AnyTimes()
+		exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+		exec.EXPECT().Type().Return("mock").AnyTimes()
+	}
+	fast.EXPECT().Execute(gomock.Any(), gomock.Any()).Return("done", nil, nil)
+	slow.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ string) (interface{}, map[string]string, error) {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return "too late", nil, nil
+		}
+	})
+
+	planner := plan.NewPlannerV2(plan.PlannerConfig{
+		Actions: map[string]apiconfig.Action{
+			"fast": {Name: "fast", Type: "fast_type"},
+			"slow": {Name: "slow", Type: "slow_type"},
+		},
+		CustomRegistry: registry,
+	}, logging.GetNewLogger())
+	testPlan, err := planner.Plan()
+	require.NoError(t, err)
+
+	ctx := requestctx.NewTestContext()
+	ctx = context.WithValue(ctx, plan.ContextKey, testPlan)
+
+	parallelExec := &Exec{config: Config{Steps: []string{"action.fast", "action.slow"}, Timeout: "50ms"}}
+
+	start := time.Now()
+	_, _, err = parallelExec.Execute(ctx, "")
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second, "the slow step is cut off")
+	assert.ErrorIs(t, err, ErrStepTimeout)
+	stepErrs := StepErrors(err)
+	assert.Len(t, stepErrs, 1, "only the slow step timed out")
+	assert.ErrorIs(t, stepErrs["action.slow"], ErrStepTimeout)
+}
+
+func TestParallelExec_CollectResults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	registry := actions.NewRegistry()
+	cfgActions := make(map[string]apiconfig.Action)
+	var steps []string
+	for _, id := range []string{"action1", "action2", "action3"} {
+		exec := plan.NewMockActionExecutable(ctrl)
+		exec.EXPECT().Config().Return("").AnyTimes()
+		exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+		exec.EXPECT().Type().Return("mock").AnyTimes()
+		exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(map[string]interface{}{"from": id}, nil, nil)
+		registry.ReplaceActionType(id+"_type", func(config json.RawMessage) (actions.ActionExecutable, error) {
+			return exec, nil
+		})
+		cfgActions[id] = apiconfig.Action{Name: id, Type: id + "_type"}
+		steps = append(steps, apiconfig.ActionConfigPrefix+id)
+	}
+	// A conditional step has no output to collect.
+	steps = append(steps, apiconfig.ConditionalConfigPrefix+"check")
+
+	planner := plan.NewPlannerV2(plan.PlannerConfig{
+		Actions:        cfgActions,
+		Conditions:     map[string]apiconfig.Conditional{"check": {Name: "check", Expression: "true"}},
+		CustomRegistry: registry,
+	}, logging.GetNewLogger())
+	testPlan, err := planner.Plan()
+	require.NoError(t, err)
+
+	ctx := requestctx.NewTestContext()
+	ctx = context.WithValue(ctx, plan.ContextKey, testPlan)
+
+	parallelExec := &Exec{config: Config{Steps: steps, CollectResults: true}}
+	result, _, err := parallelExec.Execute(ctx, "")
+	require.NoError(t, err)
+
+	collected, ok := result.(map[string]interface{})
+	require.True(t, ok)
+	assert.Len(t, collected, 3)
+	for _, id := range []string{"action1", "action2", "action3"} {
+		want := map[string]interface{}{"from": id}
+		assert.Equal(t, want, collected[id])
+
+		got, err := requestctx.GetRequestVariable(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+
+		rendered, err := requestctx.ExecuteTemplateString(ctx, fmt.Sprintf("{{ .variable_actions_%s.from }}", id))
+		require.NoError(t, err)
+		assert.Equal(t, id, rendered)
+	}
+}
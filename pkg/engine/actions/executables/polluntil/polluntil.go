@@ -0,0 +1,244 @@
+package polluntil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultInitialInterval = time.Second
+	defaultMaxInterval     = 30 * time.Second
+	defaultMultiplier      = 2.0
+	defaultMaxAttempts     = 10
+)
+
+type Config struct {
+	// Step is the step reference run on every attempt, e.g. "action.checkStatus".
+	Step string `json:"step" yaml:"step"`
+	// Condition is a template evaluated after every attempt; polling stops once
+	// it resolves to "true".
+	Condition string `json:"condition" yaml:"condition"`
+	// InitialInterval is the wait before the second attempt, e.g. "500ms".
+	InitialInterval string `json:"initialInterval" yaml:"initialInterval"`
+	// MaxInterval caps the wait between two attempts.
+	MaxInterval string `json:"maxInterval" yaml:"maxInterval"`
+	// Multiplier grows the interval after every attempt.
+	Multiplier float64 `json:"multiplier" yaml:"multiplier"`
+	// MaxAttempts bounds the number of attempts. Zero means no bound when
+	// MaxDuration is set, and defaultMaxAttempts otherwise.
+	MaxAttempts int `json:"maxAttempts" yaml:"maxAttempts"`
+	// MaxDuration bounds the total time spent polling, e.g. "2m".
+	MaxDuration string `json:"maxDuration" yaml:"maxDuration"`
+}
+
+// PollUntil runs a step repeatedly, backing off exponentially between attempts,
+// until a condition on its output holds or the attempt/duration budget runs
+// out. Running out of budget is an ErrFailure so the flow routes to Fail.
+type PollUntil struct {
+	step            string
+	outputKey       string
+	condition       string
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	multiplier      float64
+	maxAttempts     int
+	maxDuration     time.Duration
+}
+
+func (p *PollUntil) Type() string {
+	return "pollUntil"
+}
+
+func (p *PollUntil) SupportsReplica() bool {
+	return false
+}
+
+func New(cfg Config) (*PollUntil, error) {
+	if cfg.Step == "" {
+		return nil, errors.New("step is required")
+	}
+	if cfg.Condition == "" {
+		return nil, errors.New("condition is required")
+	}
+	kind, id, _, err := apiconfig.ParseStepRef(cfg.Step)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PollUntil{
+		step:            cfg.Step,
+		condition:       cfg.Condition,
+		initialInterval: defaultInitialInterval,
+		maxInterval:     defaultMaxInterval,
+		multiplier:      defaultMultiplier,
+		maxAttempts:     cfg.MaxAttempts,
+	}
+	// only actions store an output the poll can hand back
+	if kind == apiconfig.StepKindAction {
+		p.outputKey = id
+	}
+
+	if p.initialInterval, err = actions.ParseDuration(cfg.InitialInterval, defaultInitialInterval); err != nil {
+		return nil, fmt.Errorf("invalid initialInterval: %w", err)
+	}
+	if p.maxInterval, err = actions.ParseDuration(cfg.MaxInterval, defaultMaxInterval); err != nil {
+		return nil, fmt.Errorf("invalid maxInterval: %w", err)
+	}
+	if p.maxDuration, err = actions.ParseDuration(cfg.MaxDuration, 0); err != nil {
+		return nil, fmt.Errorf("invalid maxDuration: %w", err)
+	}
+	if cfg.Multiplier != 0 {
+		if cfg.Multiplier < 1 {
+			return nil, fmt.Errorf("multiplier must be at least 1, got %v", cfg.Multiplier)
+		}
+		p.multiplier = cfg.Multiplier
+	}
+	if p.maxAttempts < 0 {
+		return nil, fmt.Errorf("maxAttempts must not be negative, got %d", p.maxAttempts)
+	}
+	if p.maxAttempts == 0 && p.maxDuration == 0 {
+		p.maxAttempts = defaultMaxAttempts
+	}
+
+	return p, nil
+}
+
+// Execute runs the configured step until the condition resolves to true. It
+// returns the step's output from the successful attempt.
+func (p *PollUntil) Execute(ctx context.Context) (interface{}, map[string]string, error) {
+	logger := logging.FromContext(ctx).With(zap.String("execution_type", p.Type()))
+	ctx = logging.WithLogger(ctx, logger)
+
+	rc, err := requestctx.FromContextOrError(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get request context: %w", err)
+	}
+
+	var deadline time.Time
+	if p.maxDuration > 0 {
+		deadline = time.Now().Add(p.maxDuration)
+	}
+
+	interval := p.initialInterval
+	for attempt := 1; ; attempt++ {
+		if _, err := plan.ExecuteFromContext(ctx, p.step); err != nil {
+			return nil, nil, fmt.Errorf("error executing step %s: %w", p.step, err)
+		}
+
+		resolved, err := rc.Resolve(ctx, p.condition)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve condition: %w", err)
+		}
+		logger.Debug("poll attempt finished", zap.Int("attempt", attempt), zap.String("condition", resolved))
+		if strings.TrimSpace(resolved) == "true" {
+			fields := map[string]string{"attempts": fmt.Sprintf("%d", attempt)}
+			if p.outputKey == "" {
+				return nil, fields, nil
+			}
+			out, err := requestctx.GetRequestVariable(ctx, p.outputKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			return out, fields, nil
+		}
+
+		if p.maxAttempts > 0 && attempt >= p.maxAttempts {
+			return nil, nil, fmt.Errorf("%w: condition not met after %d attempts", plan.ErrFailure, attempt)
+		}
+
+		wait := interval
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return nil, nil, fmt.Errorf("%w: condition not met within %s", plan.ErrFailure, p.maxDuration)
+			}
+			if wait > remaining {
+				wait = remaining
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		interval = time.Duration(float64(interval) * p.multiplier)
+		if interval > p.maxInterval {
+			interval = p.maxInterval
+		}
+	}
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"step": {
+			Type:        actions.FieldTypeString,
+			Label:       "Step",
+			Placeholder: "Step to run on every attempt (e.g. action.checkStatus)",
+			Required:    true,
+		},
+		"condition": {
+			Type:        actions.FieldTypeString,
+			Label:       "Condition",
+			Placeholder: "Template that resolves to true once polling is done",
+			Required:    true,
+		},
+		"initialInterval": {
+			Type:        actions.FieldTypeString,
+			Label:       "Initial Interval",
+			Placeholder: "Wait before the second attempt (e.g. 1s)",
+			Default:     defaultInitialInterval.String(),
+		},
+		"maxInterval": {
+			Type:        actions.FieldTypeString,
+			Label:       "Max Interval",
+			Placeholder: "Longest wait between attempts (e.g. 30s)",
+			Default:     defaultMaxInterval.String(),
+		},
+		"multiplier": {
+			Type:        actions.FieldTypeNumber,
+			Label:       "Backoff Multiplier",
+			Placeholder: "Factor the interval grows by after each attempt",
+			Default:     defaultMultiplier,
+		},
+		"maxAttempts": {
+			Type:        actions.FieldTypeNumber,
+			Label:       "Max Attempts",
+			Placeholder: "Maximum number of attempts",
+			Default:     defaultMaxAttempts,
+		},
+		"maxDuration": {
+			Type:        actions.FieldTypeString,
+			Label:       "Max Duration",
+			Placeholder: "Maximum total polling time (e.g. 2m)",
+		},
+	}
+
+	if err := actions.RegisterAction("pollUntil", actions.ActionRegistrationInfo{
+		Name:        "Poll Until",
+		Description: "Repeatedly runs a step with exponential backoff until a condition on its output is met, failing once the maximum attempts or duration is reached",
+		Fields:      fields,
+		UseV2:       true,
+		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
+			var cfg Config
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating pollUntil action: %v", err)
+			}
+			return New(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,170 @@
+package polluntil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupPlan(t *testing.T, ctrl *gomock.Controller) (context.Context, *plan.MockActionExecutable) {
+	mockExec := plan.NewMockActionExecutable(ctrl)
+	mockExec.EXPECT().Config().Return("").AnyTimes()
+	mockExec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+	mockExec.EXPECT().Type().Return("mock").AnyTimes()
+
+	registry := actions.NewRegistry()
+	registry.ReplaceActionType("check_type", func(config json.RawMessage) (actions.ActionExecutable, error) {
+		return mockExec, nil
+	})
+
+	planner := plan.NewPlannerV2(plan.PlannerConfig{
+		Actions: map[string]apiconfig.Action{
+			"check": {Name: "check", Type: "check_type"},
+		},
+		CustomRegistry: registry,
+	}, logging.GetNewLogger())
+	testPlan, err := planner.Plan()
+	require.NoError(t, err)
+
+	ctx := requestctx.NewTestContext()
+	ctx = context.WithValue(ctx, plan.ContextKey, testPlan)
+	return ctx, mockExec
+}
+
+func TestPollUntil_Execute(t *testing.T) {
+	condition := `{{ eq .variable_actions_check "done" }}`
+
+	t.Run("terminates once the condition is met", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx, mockExec := setupPlan(t, ctrl)
+
+		gomock.InOrder(
+			mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return("pending", nil, nil),
+			mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return("pending", nil, nil),
+			mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return("done", nil, nil),
+		)
+
+		p, err := New(Config{
+			Step:            "action.check",
+			Condition:       condition,
+			InitialInterval: "1ms",
+			MaxAttempts:     5,
+		})
+		require.NoError(t, err)
+
+		resp, fields, err := p.Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "done", resp)
+		assert.Equal(t, "3", fields["attempts"])
+	})
+
+	t.Run("fails after max attempts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx, mockExec := setupPlan(t, ctrl)
+
+		mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return("pending", nil, nil).Times(3)
+
+		p, err := New(Config{
+			Step:            "action.check",
+			Condition:       condition,
+			InitialInterval: "1ms",
+			MaxAttempts:     3,
+		})
+		require.NoError(t, err)
+
+		_, _, err = p.Execute(ctx)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plan.ErrFailure))
+		assert.Contains(t, err.Error(), "3 attempts")
+	})
+
+	t.Run("fails after max duration", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx, mockExec := setupPlan(t, ctrl)
+
+		mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return("pending", nil, nil).MinTimes(2)
+
+		p, err := New(Config{
+			Step:            "action.check",
+			Condition:       condition,
+			InitialInterval: "5ms",
+			MaxDuration:     "30ms",
+		})
+		require.NoError(t, err)
+
+		start := time.Now()
+		_, _, err = p.Execute(ctx)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plan.ErrFailure))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("step error is fatal", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx, mockExec := setupPlan(t, ctrl)
+
+		mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("boom"))
+
+		p, err := New(Config{Step: "action.check", Condition: condition, InitialInterval: "1ms"})
+		require.NoError(t, err)
+
+		_, _, err = p.Execute(ctx)
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, plan.ErrFailure))
+	})
+
+	t.Run("stops when context is canceled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx, mockExec := setupPlan(t, ctrl)
+		ctx, cancel := context.WithCancel(ctx)
+
+		mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, string) (interface{}, map[string]string, error) {
+				cancel()
+				return "pending", nil, nil
+			})
+
+		p, err := New(Config{Step: "action.check", Condition: condition, InitialInterval: "1h"})
+		require.NoError(t, err)
+
+		_, _, err = p.Execute(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{name: "missing step", cfg: Config{Condition: "{{ true }}"}, wantErr: "step is required"},
+		{name: "missing condition", cfg: Config{Step: "action.check"}, wantErr: "condition is required"},
+		{name: "invalid step reference", cfg: Config{Step: "check", Condition: "{{ true }}"}, wantErr: "invalid step reference"},
+		{name: "invalid interval", cfg: Config{Step: "action.check", Condition: "{{ true }}", InitialInterval: "soon"}, wantErr: "invalid initialInterval"},
+		{name: "multiplier below one", cfg: Config{Step: "action.check", Condition: "{{ true }}", Multiplier: 0.5}, wantErr: "multiplier"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	p, err := New(Config{Step: "action.check", Condition: "{{ true }}"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultMaxAttempts, p.maxAttempts)
+	assert.Equal(t, defaultInitialInterval, p.initialInterval)
+	assert.Equal(t, "check", p.outputKey)
+}
@@ -0,0 +1,168 @@
+package rawquery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	IntegrationID string `json:"integrationID" yaml:"integrationID"`
+	// Query is the SQL statement with ? placeholders. It is not a template:
+	// request values go in Args so they are always bound, never spliced in.
+	Query string `json:"query" yaml:"query"`
+	// Args are templates resolved and bound to the placeholders in order.
+	Args []string `json:"args" yaml:"args"`
+	// ReadOnly rejects statements that could write.
+	ReadOnly    bool `json:"readOnly" yaml:"readOnly"`
+	FailIfEmpty bool `json:"failIfEmpty" yaml:"failIfEmpty"`
+}
+
+type queryImplementation interface {
+	Query(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error)
+}
+
+type readOnlyQueryImplementation interface {
+	QueryReadOnly(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error)
+}
+
+// RawQuery runs a parameterized SQL statement, for joins, aggregates and
+// window functions that fetch cannot express, and returns the rows.
+type RawQuery struct {
+	cfg   Config
+	query func(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error)
+}
+
+func (r *RawQuery) Type() string {
+	return "rawquery"
+}
+
+func (r *RawQuery) SupportsReplica() bool {
+	return r.cfg.ReadOnly
+}
+
+func New(cfg Config) (*RawQuery, error) {
+	cfg.IntegrationID = integration.ResolveID(cfg.IntegrationID)
+	if cfg.IntegrationID == "" {
+		return nil, errors.New("datasource is required")
+	}
+	if strings.TrimSpace(cfg.Query) == "" {
+		return nil, errors.New("query is required")
+	}
+	if strings.Contains(cfg.Query, "{{") {
+		return nil, errors.New("query must not contain templates, pass values through args")
+	}
+
+	i, err := integration.GetIntegration(context.Background(), cfg.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	r := &RawQuery{cfg: cfg}
+	if cfg.ReadOnly {
+		q, ok := i.(readOnlyQueryImplementation)
+		if !ok {
+			return nil, errors.New("integration does not support read-only queries")
+		}
+		r.query = q.QueryReadOnly
+	} else {
+		q, ok := i.(queryImplementation)
+		if !ok {
+			return nil, errors.New("integration does not support raw queries")
+		}
+		r.query = q.Query
+	}
+	return r, nil
+}
+
+func (r *RawQuery) Execute(ctx context.Context) (interface{}, map[string]string, error) {
+	logger := logging.FromContext(ctx).With(zap.String("execution_type", r.Type()))
+	ctx = logging.WithLogger(ctx, logger)
+
+	args := make([]interface{}, len(r.cfg.Args))
+	if len(r.cfg.Args) > 0 {
+		rc, err := requestctx.FromContextOrError(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get request context: %w", err)
+		}
+		resolved, err := rc.ResolveBatch(ctx, r.cfg.Args...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve args: %w", err)
+		}
+		for i, v := range resolved {
+			args[i] = v
+		}
+	}
+
+	logger.Debug("running raw query", zap.Int("args", len(args)), zap.Bool("read_only", r.cfg.ReadOnly))
+	rows, err := r.query(ctx, r.cfg.Query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("raw query: %v", err)
+	}
+	if len(rows) == 0 && r.cfg.FailIfEmpty {
+		return nil, nil, fmt.Errorf("%w: no rows found", plan.ErrFailure)
+	}
+	return rows, nil, nil
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"integrationID": {
+			Type:        actions.FieldTypeIntegration,
+			Label:       "Integration ID",
+			Placeholder: "SQL integration identifier",
+			Required:    true,
+		},
+		"query": {
+			Type:        actions.FieldTypeTextArea,
+			Label:       "Query",
+			Placeholder: "SELECT u.name, COUNT(o.id) FROM users u JOIN orders o ON o.user_id = u.id WHERE u.id = ? GROUP BY u.name",
+			Required:    true,
+		},
+		"args": {
+			Type:        actions.FieldTypeArray,
+			Label:       "Arguments",
+			Placeholder: "Values bound to the ? placeholders, in order",
+			Required:    false,
+		},
+		"readOnly": {
+			Type:        actions.FieldTypeBoolean,
+			Label:       "Read Only",
+			Placeholder: "Reject statements that could write",
+			Required:    false,
+			Default:     false,
+		},
+		"failIfEmpty": {
+			Type:        actions.FieldTypeBoolean,
+			Label:       "Fail if Empty",
+			Placeholder: "Treat no rows as failure",
+			Required:    false,
+			Default:     false,
+		},
+	}
+
+	if err := actions.RegisterAction("rawquery", actions.ActionRegistrationInfo{
+		Name:        "Raw SQL Query",
+		Description: "Runs a parameterized SQL statement, such as a join or aggregate, and returns the rows",
+		SideEffects: true,
+		Fields:      fields,
+		UseV2:       true,
+		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
+			var cfg Config
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating rawquery action: %v", err)
+			}
+			return New(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,115 @@
+package rawquery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type call struct {
+	readOnly bool
+	query    string
+	args     []interface{}
+}
+
+type fakeSQL struct {
+	rows  []map[string]interface{}
+	calls []call
+}
+
+func (f *fakeSQL) Type() string { return "fakesql" }
+
+func (f *fakeSQL) Query(_ context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	f.calls = append(f.calls, call{query: query, args: args})
+	return f.rows, nil
+}
+
+func (f *fakeSQL) QueryReadOnly(_ context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	f.calls = append(f.calls, call{readOnly: true, query: query, args: args})
+	return f.rows, nil
+}
+
+type writeOnly struct{}
+
+func (writeOnly) Type() string { return "writeonly" }
+
+func registerFake(t *testing.T, id string, i integration.Integration) {
+	t.Helper()
+	integration.ReplaceIntegrationType(id, func(map[string]any) (integration.Integration, error) {
+		return i, nil
+	})
+	require.NoError(t, integration.InitializeIntegration(id, id, nil, false))
+}
+
+func TestRawQuery_Execute(t *testing.T) {
+	fake := &fakeSQL{rows: []map[string]interface{}{{"name": "Ada", "orders": int64(2)}}}
+	registerFake(t, "fakesql", fake)
+
+	ctx := requestctx.NewTestContext()
+	require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"min": 10, "name": "Ada' OR '1'='1"}, ""))
+
+	query := "SELECT u.name, COUNT(o.id) AS orders FROM users u JOIN orders o ON o.user_id = u.id WHERE o.total >= ? AND u.name = ? GROUP BY u.name"
+	t.Run("binds resolved args", func(t *testing.T) {
+		rq, err := New(Config{IntegrationID: "fakesql", Query: query, Args: []string{"{{ .min }}", "{{ .name }}"}})
+		require.NoError(t, err)
+
+		out, _, err := rq.Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, fake.rows, out)
+		assert.Equal(t, call{query: query, args: []interface{}{"10", "Ada' OR '1'='1"}}, fake.calls[len(fake.calls)-1])
+		assert.False(t, rq.SupportsReplica())
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		rq, err := New(Config{IntegrationID: "fakesql", Query: query, ReadOnly: true})
+		require.NoError(t, err)
+		_, _, err = rq.Execute(ctx)
+		require.NoError(t, err)
+		assert.True(t, fake.calls[len(fake.calls)-1].readOnly)
+		assert.True(t, rq.SupportsReplica())
+	})
+
+	t.Run("fail if empty", func(t *testing.T) {
+		empty := &fakeSQL{}
+		registerFake(t, "emptysql", empty)
+		rq, err := New(Config{IntegrationID: "emptysql", Query: "SELECT 1 WHERE false", FailIfEmpty: true})
+		require.NoError(t, err)
+		_, _, err = rq.Execute(ctx)
+		assert.ErrorIs(t, err, plan.ErrFailure)
+	})
+}
+
+func TestNew(t *testing.T) {
+	registerFake(t, "writeonly", writeOnly{})
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{name: "missing integration", cfg: Config{Query: "SELECT 1"}, wantErr: "datasource is required"},
+		{name: "missing query", cfg: Config{IntegrationID: "writeonly"}, wantErr: "query is required"},
+		{
+			name:    "templated query",
+			cfg:     Config{IntegrationID: "writeonly", Query: `SELECT * FROM users WHERE id = {{ param "id" }}`},
+			wantErr: "query must not contain templates",
+		},
+		{name: "unsupported integration", cfg: Config{IntegrationID: "writeonly", Query: "SELECT 1"}, wantErr: "does not support raw queries"},
+		{
+			name:    "unsupported read only",
+			cfg:     Config{IntegrationID: "writeonly", Query: "SELECT 1", ReadOnly: true},
+			wantErr: "does not support read-only queries",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(tc.cfg)
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
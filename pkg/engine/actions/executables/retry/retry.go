@@ -0,0 +1,203 @@
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultBackoff     = 100 * time.Millisecond
+	defaultMultiplier  = 2.0
+	defaultMaxBackoff  = 10 * time.Second
+)
+
+type Config struct {
+	// Action is the id of the action to run, e.g. "callApi" or
+	// "action.callApi". It should not have a next step of its own: the retry
+	// action continues the flow with the child's output.
+	Action string `json:"action" yaml:"action"`
+	// MaxAttempts is the number of times the action runs at most, the first
+	// attempt included.
+	MaxAttempts int `json:"maxAttempts" yaml:"maxAttempts"`
+	// Backoff is the wait before the second attempt, e.g. "200ms".
+	Backoff string `json:"backoff" yaml:"backoff"`
+	// Multiplier grows the wait after every attempt.
+	Multiplier float64 `json:"multiplier" yaml:"multiplier"`
+	// MaxBackoff caps the wait between two attempts.
+	MaxBackoff string `json:"maxBackoff" yaml:"maxBackoff"`
+}
+
+// Retry runs an action again when it returns an error, waiting longer after
+// every attempt, and fails with the last error once the attempts run out. A
+// failure the action routes to its own fail step is not an error and is not
+// retried; the config's DefaultFail does not count as its own.
+type Retry struct {
+	step        string
+	outputKey   string
+	maxAttempts int
+	backoff     time.Duration
+	multiplier  float64
+	maxBackoff  time.Duration
+}
+
+func (r *Retry) Type() string {
+	return "retry"
+}
+
+func (r *Retry) SupportsReplica() bool {
+	return false
+}
+
+func New(cfg Config) (*Retry, error) {
+	if cfg.Action == "" {
+		return nil, errors.New("action is required")
+	}
+	step := cfg.Action
+	kind, id, _, err := apiconfig.ParseStepRef(step)
+	if err != nil {
+		// a bare id names an action
+		step = apiconfig.ActionConfigPrefix + cfg.Action
+		kind, id, _, err = apiconfig.ParseStepRef(step)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if kind != apiconfig.StepKindAction {
+		return nil, fmt.Errorf("retry can only wrap an action, got %s", cfg.Action)
+	}
+
+	r := &Retry{
+		step:        step,
+		outputKey:   id,
+		maxAttempts: defaultMaxAttempts,
+		multiplier:  defaultMultiplier,
+	}
+	if cfg.MaxAttempts < 0 {
+		return nil, fmt.Errorf("maxAttempts must not be negative, got %d", cfg.MaxAttempts)
+	}
+	if cfg.MaxAttempts > 0 {
+		r.maxAttempts = cfg.MaxAttempts
+	}
+	if r.backoff, err = actions.ParseDuration(cfg.Backoff, defaultBackoff); err != nil {
+		return nil, fmt.Errorf("invalid backoff: %w", err)
+	}
+	if r.maxBackoff, err = actions.ParseDuration(cfg.MaxBackoff, defaultMaxBackoff); err != nil {
+		return nil, fmt.Errorf("invalid maxBackoff: %w", err)
+	}
+	if cfg.Multiplier != 0 {
+		if cfg.Multiplier < 1 {
+			return nil, fmt.Errorf("multiplier must be at least 1, got %v", cfg.Multiplier)
+		}
+		r.multiplier = cfg.Multiplier
+	}
+	return r, nil
+}
+
+// retriable reports whether an attempt that failed with err may be repeated.
+// Short-circuits and cancellation of the request are not failures of the
+// action.
+func retriable(ctx context.Context, err error) bool {
+	return ctx.Err() == nil &&
+		!errors.Is(err, plan.ErrShortCircuit) &&
+		!errors.Is(err, plan.ErrMaxStepsExceeded)
+}
+
+// Execute runs the action until it succeeds and returns its output.
+func (r *Retry) Execute(ctx context.Context) (interface{}, map[string]string, error) {
+	logger := logging.FromContext(ctx).With(zap.String("execution_type", r.Type()))
+	ctx = logging.WithLogger(ctx, logger)
+
+	wait := r.backoff
+	for attempt := 1; ; attempt++ {
+		// The child's errors must reach the retry, not the config's
+		// DefaultFail step.
+		_, err := plan.ExecuteFromContext(plan.WithoutDefaultFail(ctx, r.outputKey), r.step)
+		if err == nil {
+			out, err := requestctx.GetRequestVariable(ctx, r.outputKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			return out, map[string]string{"attempts": strconv.Itoa(attempt)}, nil
+		}
+		if !retriable(ctx, err) {
+			return nil, nil, err
+		}
+		if attempt >= r.maxAttempts {
+			return nil, nil, fmt.Errorf("%s failed after %d attempts: %w", r.step, attempt, err)
+		}
+		logger.Debug("retrying action", zap.String("step", r.step), zap.Int("attempt", attempt), zap.Duration("wait", wait), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait = time.Duration(float64(wait) * r.multiplier)
+		if wait > r.maxBackoff {
+			wait = r.maxBackoff
+		}
+	}
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"action": {
+			Type:        actions.FieldTypeString,
+			Label:       "Action",
+			Placeholder: "Action to run and retry on error (e.g. callApi)",
+			Required:    true,
+		},
+		"maxAttempts": {
+			Type:        actions.FieldTypeNumber,
+			Label:       "Max Attempts",
+			Placeholder: "Maximum number of attempts, the first included",
+			Default:     defaultMaxAttempts,
+		},
+		"backoff": {
+			Type:        actions.FieldTypeString,
+			Label:       "Backoff",
+			Placeholder: "Wait before the second attempt (e.g. 100ms)",
+			Default:     defaultBackoff.String(),
+		},
+		"multiplier": {
+			Type:        actions.FieldTypeNumber,
+			Label:       "Backoff Multiplier",
+			Placeholder: "Factor the wait grows by after each attempt",
+			Default:     defaultMultiplier,
+		},
+		"maxBackoff": {
+			Type:        actions.FieldTypeString,
+			Label:       "Max Backoff",
+			Placeholder: "Longest wait between attempts (e.g. 10s)",
+			Default:     defaultMaxBackoff.String(),
+		},
+	}
+
+	if err := actions.RegisterAction("retry", actions.ActionRegistrationInfo{
+		Name:        "Retry",
+		Description: "Runs an action again with backoff when it returns an error, failing with the last error once the attempts run out",
+		Fields:      fields,
+		UseV2:       true,
+		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
+			var cfg Config
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating retry action: %v", err)
+			}
+			return New(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
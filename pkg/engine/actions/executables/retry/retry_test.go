@@ -0,0 +1,151 @@
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	_ "github.com/Servflow/servflow/pkg/engine/responses/http"
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupPlan(t *testing.T, ctrl *gomock.Controller) (context.Context, *plan.MockActionExecutable) {
+	return setupPlanWithDefaultFail(t, ctrl, "")
+}
+
+func setupPlanWithDefaultFail(t *testing.T, ctrl *gomock.Controller, defaultFail string) (context.Context, *plan.MockActionExecutable) {
+	mockExec := plan.NewMockActionExecutable(ctrl)
+	mockExec.EXPECT().Config().Return("").AnyTimes()
+	mockExec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+	mockExec.EXPECT().Type().Return("mock").AnyTimes()
+
+	registry := actions.NewRegistry()
+	registry.ReplaceActionType("child_type", func(config json.RawMessage) (actions.ActionExecutable, error) {
+		return mockExec, nil
+	})
+
+	planner := plan.NewPlannerV2(plan.PlannerConfig{
+		Actions: map[string]apiconfig.Action{
+			"child": {Name: "child", Type: "child_type"},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"failed": {Name: "failed", Code: 500},
+		},
+		DefaultFail:    defaultFail,
+		CustomRegistry: registry,
+	}, logging.GetNewLogger())
+	testPlan, err := planner.Plan()
+	require.NoError(t, err)
+
+	ctx := requestctx.NewTestContext()
+	ctx = context.WithValue(ctx, plan.ContextKey, testPlan)
+	return ctx, mockExec
+}
+
+func TestRetry_Execute(t *testing.T) {
+	t.Run("succeeds after two failures", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx, mockExec := setupPlan(t, ctrl)
+
+		gomock.InOrder(
+			mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("connection reset")),
+			mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("connection reset")),
+			mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return("ok", nil, nil),
+		)
+
+		r, err := New(Config{Action: "child", MaxAttempts: 3, Backoff: "1ms"})
+		require.NoError(t, err)
+
+		resp, fields, err := r.Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+		assert.Equal(t, "3", fields["attempts"])
+	})
+
+	t.Run("surfaces the last error once attempts run out", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx, mockExec := setupPlan(t, ctrl)
+
+		gomock.InOrder(
+			mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("first")),
+			mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("last")),
+		)
+
+		r, err := New(Config{Action: "action.child", MaxAttempts: 2, Backoff: "1ms"})
+		require.NoError(t, err)
+
+		_, _, err = r.Execute(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "after 2 attempts")
+		assert.Contains(t, err.Error(), "last")
+	})
+
+	t.Run("retries errors the config would route to its default fail step", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx, mockExec := setupPlanWithDefaultFail(t, ctrl, "response.failed")
+
+		gomock.InOrder(
+			mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("connection reset")),
+			mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return("ok", nil, nil),
+		)
+
+		r, err := New(Config{Action: "child", MaxAttempts: 3, Backoff: "1ms"})
+		require.NoError(t, err)
+
+		resp, fields, err := r.Execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+		assert.Equal(t, "2", fields["attempts"])
+
+		mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("connection reset")).Times(3)
+		_, _, err = r.Execute(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "after 3 attempts")
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx, mockExec := setupPlan(t, ctrl)
+		ctx, cancel := context.WithCancel(ctx)
+
+		mockExec.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ string) (interface{}, map[string]string, error) {
+			cancel()
+			return nil, nil, errors.New("failed")
+		}).Times(1)
+
+		r, err := New(Config{Action: "child", MaxAttempts: 5, Backoff: "1h"})
+		require.NoError(t, err)
+
+		_, _, err = r.Execute(ctx)
+		require.Error(t, err)
+	})
+}
+
+func TestNew(t *testing.T) {
+	r, err := New(Config{Action: "child"})
+	require.NoError(t, err)
+	assert.Equal(t, "action.child", r.step)
+	assert.Equal(t, "child", r.outputKey)
+	assert.Equal(t, defaultMaxAttempts, r.maxAttempts)
+	assert.Equal(t, defaultBackoff, r.backoff)
+
+	_, err = New(Config{})
+	assert.Error(t, err)
+
+	_, err = New(Config{Action: "conditional.check"})
+	assert.Error(t, err)
+
+	_, err = New(Config{Action: "child", Backoff: "soon"})
+	assert.Error(t, err)
+
+	_, err = New(Config{Action: "child", Multiplier: 0.5})
+	assert.Error(t, err)
+}
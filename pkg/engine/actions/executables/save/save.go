@@ -44,6 +44,7 @@ func (s *Save) SupportsReplica() bool {
 }
 
 func New(config Config) (*Save, error) {
+	config.IntegrationID = integration.ResolveID(config.IntegrationID)
 	if config.IntegrationID == "" {
 		return nil, errors.New("integrationID is required")
 	}
@@ -105,7 +106,11 @@ func (s *Save) executeInsert(ctx context.Context, rc *requestctx.RequestContext,
 
 	logger.Debug("save action executing insert", zap.Any("id", id))
 
-	err := s.i.Store(ctx, fields, options)
+	store := s.i.Store
+	if batcher, ok := integration.GetWriteBatcher(s.cfg.IntegrationID); ok {
+		store = batcher.Store
+	}
+	err := store(ctx, fields, options)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error storing: %w", err)
 	}
@@ -216,6 +221,7 @@ func init() {
 	if err := actions.RegisterAction("save", actions.ActionRegistrationInfo{
 		Name:        "Save Data",
 		Description: "Inserts new records or updates existing records in database tables. When filters are provided, updates matching records; otherwise inserts a new record.",
+		SideEffects: true,
 		Fields:      fields,
 		UseV2:       true,
 		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
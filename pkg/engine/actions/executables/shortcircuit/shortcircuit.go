@@ -0,0 +1,109 @@
+package shortcircuit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	// Response is the response step the flow returns with, e.g. "response.cached".
+	Response string `json:"response" yaml:"response"`
+	// When is an optional template; the flow is only short-circuited when it
+	// resolves to "true". An empty When always short-circuits.
+	When string `json:"when" yaml:"when"`
+}
+
+// ShortCircuit ends the flow early with a named response, skipping every
+// remaining step, including those of enclosing chains such as parallel
+// branches. When its condition does not hold the flow continues to Next.
+type ShortCircuit struct {
+	response string
+	when     string
+}
+
+func (s *ShortCircuit) Type() string {
+	return "shortcircuit"
+}
+
+func (s *ShortCircuit) SupportsReplica() bool {
+	return false
+}
+
+func New(cfg Config) (*ShortCircuit, error) {
+	if cfg.Response == "" {
+		return nil, errors.New("response is required")
+	}
+	kind, _, _, err := apiconfig.ParseStepRef(cfg.Response)
+	if err != nil {
+		return nil, err
+	}
+	if kind != apiconfig.StepKindResponse {
+		return nil, fmt.Errorf("response must reference a response step, got %q", cfg.Response)
+	}
+	return &ShortCircuit{response: cfg.Response, when: cfg.When}, nil
+}
+
+// Execute evaluates the condition and short-circuits the flow when it holds.
+func (s *ShortCircuit) Execute(ctx context.Context) (interface{}, map[string]string, error) {
+	logger := logging.FromContext(ctx).With(zap.String("execution_type", s.Type()))
+
+	if s.when != "" {
+		rc, err := requestctx.FromContextOrError(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get request context: %w", err)
+		}
+		resolved, err := rc.Resolve(ctx, s.when)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve condition: %w", err)
+		}
+		if strings.TrimSpace(resolved) != "true" {
+			logger.Debug("short-circuit condition not met, continuing flow")
+			return false, nil, nil
+		}
+	}
+
+	logger.Debug("short-circuiting flow", zap.String("response", s.response))
+	return nil, nil, plan.ShortCircuit(s.response)
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"response": {
+			Type:        actions.FieldTypeString,
+			Label:       "Response",
+			Placeholder: "Response to return (e.g. response.cached)",
+			Required:    true,
+		},
+		"when": {
+			Type:        actions.FieldTypeString,
+			Label:       "When",
+			Placeholder: "Optional condition; leave empty to always return",
+		},
+	}
+
+	if err := actions.RegisterAction("shortcircuit", actions.ActionRegistrationInfo{
+		Name:        "Return Early",
+		Description: "Ends the flow immediately with the selected response, skipping all remaining steps. An optional condition limits when the flow returns early",
+		Fields:      fields,
+		UseV2:       true,
+		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
+			var cfg Config
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating shortcircuit action: %v", err)
+			}
+			return New(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
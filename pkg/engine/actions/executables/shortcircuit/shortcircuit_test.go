@@ -0,0 +1,54 @@
+package shortcircuit
+
+import (
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortCircuit_Execute(t *testing.T) {
+	tests := []struct {
+		name        string
+		when        string
+		vars        map[string]interface{}
+		wantShorted bool
+	}{
+		{name: "no condition always short-circuits", wantShorted: true},
+		{name: "condition met", when: `{{ eq .hit "yes" }}`, vars: map[string]interface{}{"hit": "yes"}, wantShorted: true},
+		{name: "condition not met", when: `{{ eq .hit "yes" }}`, vars: map[string]interface{}{"hit": "no"}, wantShorted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := requestctx.NewTestContext()
+			require.NoError(t, requestctx.AddRequestVariables(ctx, tt.vars, ""))
+
+			s, err := New(Config{Response: "response.cached", When: tt.when})
+			require.NoError(t, err)
+
+			resp, _, err := s.Execute(ctx)
+			if !tt.wantShorted {
+				require.NoError(t, err)
+				assert.Equal(t, false, resp)
+				return
+			}
+			var sc *plan.ShortCircuitError
+			require.ErrorAs(t, err, &sc)
+			assert.Equal(t, "response.cached", sc.Response)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.ErrorContains(t, err, "response is required")
+
+	_, err = New(Config{Response: "action.other"})
+	assert.ErrorContains(t, err, "must reference a response step")
+
+	_, err = New(Config{Response: "cached"})
+	assert.ErrorContains(t, err, "invalid step reference")
+}
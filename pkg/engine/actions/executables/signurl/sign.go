@@ -0,0 +1,83 @@
+package signurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// ExpiresParam holds the unix time after which a signed URL is rejected.
+	ExpiresParam = "expires"
+	// SignatureParam holds the hex HMAC-SHA256 of the rest of the URL.
+	SignatureParam = "signature"
+)
+
+var (
+	ErrExpired          = errors.New("signed url has expired")
+	ErrInvalidSignature = errors.New("signed url has an invalid signature")
+)
+
+// Sign adds the ExpiresParam and SignatureParam query parameters to rawURL.
+// The signature covers the path and every other query parameter, so changing
+// any of them, or the expiry, invalidates the URL. The host is not signed so
+// the URL stays valid behind proxies that rewrite it.
+func Sign(rawURL string, secret []byte, expires time.Time) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	q := u.Query()
+	q.Del(SignatureParam)
+	q.Set(ExpiresParam, strconv.FormatInt(expires.Unix(), 10))
+	q.Set(SignatureParam, signature(u.EscapedPath(), q, secret))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Verify checks that rawURL was produced by Sign with secret and has not
+// expired at now.
+func Verify(rawURL string, secret []byte, now time.Time) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	q := u.Query()
+	got, err := hex.DecodeString(q.Get(SignatureParam))
+	if err != nil || len(got) == 0 {
+		return ErrInvalidSignature
+	}
+	want, _ := hex.DecodeString(signature(u.EscapedPath(), q, secret))
+	if !hmac.Equal(got, want) {
+		return ErrInvalidSignature
+	}
+
+	expires, err := strconv.ParseInt(q.Get(ExpiresParam), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !now.Before(time.Unix(expires, 0)) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// signature is the hex HMAC-SHA256 of path and the query without its
+// signature. url.Values.Encode sorts the keys, so parameter order does not
+// matter.
+func signature(path string, q url.Values, secret []byte) string {
+	unsigned := url.Values{}
+	for k, v := range q {
+		if k != SignatureParam {
+			unsigned[k] = v
+		}
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(path + "?" + unsigned.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,97 @@
+package signurl
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Unix(1_700_000_000, 0)
+
+	signed, err := Sign("https://files.example.com/reports/42?download=true", secret, now.Add(time.Hour))
+	require.NoError(t, err)
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/reports/42", u.Path)
+	assert.Equal(t, "true", u.Query().Get("download"))
+	assert.Equal(t, "1700003600", u.Query().Get(ExpiresParam))
+
+	t.Run("round trip", func(t *testing.T) {
+		assert.NoError(t, Verify(signed, secret, now))
+		assert.NoError(t, Verify(signed, secret, now.Add(59*time.Minute)))
+	})
+
+	t.Run("other host", func(t *testing.T) {
+		u, _ := url.Parse(signed)
+		u.Host = "internal:8080"
+		assert.NoError(t, Verify(u.String(), secret, now))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		assert.ErrorIs(t, Verify(signed, secret, now.Add(time.Hour)), ErrExpired)
+	})
+
+	tamper := func(fn func(u *url.URL, q url.Values)) string {
+		u, _ := url.Parse(signed)
+		q := u.Query()
+		fn(u, q)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	tests := map[string]string{
+		"changed path":      tamper(func(u *url.URL, q url.Values) { u.Path = "/reports/43" }),
+		"changed param":     tamper(func(u *url.URL, q url.Values) { q.Set("download", "false") }),
+		"added param":       tamper(func(u *url.URL, q url.Values) { q.Set("admin", "true") }),
+		"extended expiry":   tamper(func(u *url.URL, q url.Values) { q.Set(ExpiresParam, "1800000000") }),
+		"missing signature": tamper(func(u *url.URL, q url.Values) { q.Del(SignatureParam) }),
+		"forged signature":  tamper(func(u *url.URL, q url.Values) { q.Set(SignatureParam, "00ff") }),
+	}
+	for name, tampered := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, Verify(tampered, secret, now), ErrInvalidSignature)
+		})
+	}
+
+	t.Run("wrong secret", func(t *testing.T) {
+		assert.ErrorIs(t, Verify(signed, []byte("other"), now), ErrInvalidSignature)
+	})
+}
+
+func TestSignURL_Actions(t *testing.T) {
+	ctx := requestctx.NewTestContext()
+	require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"id": "42", "key": "s3cret"}, ""))
+	now := time.Unix(1_700_000_000, 0)
+
+	sign, err := New(Config{URL: "/files/{{ .id }}", Secret: "{{ .key }}", TTL: "10m"})
+	require.NoError(t, err)
+	sign.now = func() time.Time { return now }
+	out, _, err := sign.Execute(ctx)
+	require.NoError(t, err)
+	signed := out.(string)
+	assert.Contains(t, signed, "/files/42?expires=1700000600&signature=")
+
+	require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"signed": signed}, ""))
+	verify, err := NewVerify(VerifyConfig{URL: "{{ .signed }}", Secret: "{{ .key }}"})
+	require.NoError(t, err)
+
+	verify.now = func() time.Time { return now.Add(5 * time.Minute) }
+	out, _, err = verify.Execute(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, true, out)
+
+	verify.now = func() time.Time { return now.Add(11 * time.Minute) }
+	_, _, err = verify.Execute(ctx)
+	assert.ErrorIs(t, err, plan.ErrFailure)
+	assert.ErrorContains(t, err, "expired")
+
+	_, err = New(Config{URL: "/files", Secret: "k", TTL: "-1m"})
+	assert.ErrorContains(t, err, "ttl must be positive")
+}
@@ -0,0 +1,103 @@
+package signurl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+type VerifyConfig struct {
+	// URL is the signed link to check, e.g. the request path and query.
+	URL string `json:"url" yaml:"url"`
+	// Secret is the HMAC key the link was signed with.
+	Secret string `json:"secret" yaml:"secret"`
+}
+
+// VerifySignedURL checks a URL produced by signurl. An expired or tampered
+// URL fails the action so the flow takes its fail branch.
+type VerifySignedURL struct {
+	url    string
+	secret string
+	now    func() time.Time
+}
+
+func (v *VerifySignedURL) Type() string {
+	return "verifysignedurl"
+}
+
+func (v *VerifySignedURL) SupportsReplica() bool {
+	return true
+}
+
+func NewVerify(cfg VerifyConfig) (*VerifySignedURL, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("url is required")
+	}
+	if cfg.Secret == "" {
+		return nil, errors.New("secret is required")
+	}
+	return &VerifySignedURL{url: cfg.URL, secret: cfg.Secret, now: time.Now}, nil
+}
+
+func (v *VerifySignedURL) Execute(ctx context.Context) (interface{}, map[string]string, error) {
+	logger := logging.FromContext(ctx).With(zap.String("execution_type", v.Type()))
+
+	rc, err := requestctx.FromContextOrError(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get request context: %w", err)
+	}
+	resolved, err := rc.ResolveBatch(ctx, v.url, v.secret)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve verifysignedurl config: %w", err)
+	}
+	if resolved[1] == "" {
+		return nil, nil, errors.New("secret resolved to an empty value")
+	}
+
+	if err := Verify(resolved[0], []byte(resolved[1]), v.now()); err != nil {
+		logger.Debug("signed url rejected", zap.Error(err))
+		return nil, nil, fmt.Errorf("%w: %v", plan.ErrFailure, err)
+	}
+	return true, nil, nil
+}
+
+func init() {
+	fields := map[string]actions.FieldInfo{
+		"url": {
+			Type:        actions.FieldTypeString,
+			Label:       "URL",
+			Placeholder: "The signed URL to verify",
+			Required:    true,
+		},
+		"secret": {
+			Type:        actions.FieldTypeString,
+			Label:       "Secret",
+			Placeholder: `{{ secret "url_signing_key" }}`,
+			Required:    true,
+		},
+	}
+
+	if err := actions.RegisterAction("verifysignedurl", actions.ActionRegistrationInfo{
+		Name:        "Verify Signed URL",
+		Description: "Fails when a signed URL has expired or been tampered with",
+		Fields:      fields,
+		UseV2:       true,
+		ConstructorV2: func(config json.RawMessage) (actions.ActionExecutableV2, error) {
+			var cfg VerifyConfig
+			if err := json.Unmarshal(config, &cfg); err != nil {
+				return nil, fmt.Errorf("error creating verifysignedurl action: %v", err)
+			}
+			return NewVerify(cfg)
+		},
+	}); err != nil {
+		panic(err)
+	}
+}
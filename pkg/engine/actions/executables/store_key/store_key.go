@@ -84,6 +84,7 @@ func init() {
 	if err := actions.RegisterAction("store_key", actions.ActionRegistrationInfo{
 		Name:        "Store Key",
 		Description: "Stores a key-value pair in persistent storage",
+		SideEffects: true,
 		Fields:      fields,
 		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
 			var cfg Config
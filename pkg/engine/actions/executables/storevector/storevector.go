@@ -68,6 +68,7 @@ func (s StoreVectors) Execute(ctx context.Context, modifiedConfig string) (inter
 }
 
 func New(config Config) (*StoreVectors, error) {
+	config.IntegrationID = integration.ResolveID(config.IntegrationID)
 	if config.IntegrationID == "" {
 		return nil, fmt.Errorf("no integration ID provided")
 	}
@@ -118,6 +119,7 @@ func init() {
 	if err := actions.RegisterAction("storevector", actions.ActionRegistrationInfo{
 		Name:        "Store Vectors",
 		Description: "Stores vector embeddings into vector databases for similarity search",
+		SideEffects: true,
 		Fields:      fields,
 		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
 			var cfg Config
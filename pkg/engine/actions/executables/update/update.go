@@ -22,6 +22,10 @@ type Config struct {
 	Table             string                 `json:"table"`
 	DatasourceOptions map[string]string      `json:"datasourceOptions"`
 	Fields            map[string]interface{} `json:"fields"`
+	// Version enables optimistic locking: the update only applies if the
+	// record's version field still equals this value, and fails with
+	// filters.ErrVersionConflict otherwise.
+	Version string `json:"version"`
 }
 
 type Update struct {
@@ -38,6 +42,7 @@ func (u *Update) SupportsReplica() bool {
 }
 
 func New(config Config) (*Update, error) {
+	config.IntegrationID = integration.ResolveID(config.IntegrationID)
 	if config.IntegrationID == "" {
 		return nil, errors.New("datasource is required")
 	}
@@ -74,7 +79,11 @@ func (u *Update) Execute(ctx context.Context, modifiedConfig string) (interface{
 		return nil, nil, err
 	}
 
-	id, err := u.i.Update(ctx, cfg.Fields, map[string]string{"collection": u.cfg.Table}, cfg.Filters...)
+	opts := map[string]string{"collection": u.cfg.Table}
+	if cfg.Version != "" {
+		opts[filters.VersionOption] = cfg.Version
+	}
+	id, err := u.i.Update(ctx, cfg.Fields, opts, cfg.Filters...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("update operation failed: %w", err)
 	}
@@ -116,11 +125,18 @@ func init() {
 			Placeholder: "Data fields to update",
 			Required:    true,
 		},
+		"version": {
+			Type:        actions.FieldTypeString,
+			Label:       "Version",
+			Placeholder: "Expected record version for optimistic locking",
+			Required:    false,
+		},
 	}
 
 	if err := actions.RegisterAction("update", actions.ActionRegistrationInfo{
 		Name:        "Update Data",
 		Description: "Updates existing records in database tables using filters and field mappings",
+		SideEffects: true,
 		Fields:      fields,
 		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
 			var cfg Config
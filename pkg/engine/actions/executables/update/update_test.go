@@ -82,6 +82,35 @@ func TestUpdate_Execute(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("passes version for optimistic locking", func(t *testing.T) {
+		ctr := gomock.NewController(t)
+		defer ctr.Finish()
+
+		fields := map[string]interface{}{"name": "test"}
+		filter := filters.Filter{Field: "id", Comparator: "1", Operation: "=="}
+
+		mockIntegration := NewMockupdateIntegration(ctr)
+		mockIntegration.EXPECT().
+			Update(gomock.Any(), fields, map[string]string{"collection": "mock_table", filters.VersionOption: "3"}, filter).
+			Return("", filters.ErrVersionConflict)
+		integration.ReplaceIntegrationType("mock", func(m map[string]any) (integration.Integration, error) {
+			return mockIntegration, nil
+		})
+		require.NoError(t, integration.InitializeIntegration("mock", "mockds", nil, false))
+
+		update, err := New(Config{
+			IntegrationID: "mockds",
+			Table:         "mock_table",
+			Filters:       []filters.Filter{filter},
+			Fields:        fields,
+			Version:       "3",
+		})
+		require.NoError(t, err)
+
+		_, _, err = update.Execute(context.Background(), update.Config())
+		assert.ErrorIs(t, err, filters.ErrVersionConflict)
+	})
+
 	t.Run("missing table", func(t *testing.T) {
 		_, err := New(Config{
 			IntegrationID:     "mockds",
@@ -40,6 +40,7 @@ func (s *Write) SupportsReplica() bool {
 }
 
 func New(config Config) (*Write, error) {
+	config.IntegrationID = integration.ResolveID(config.IntegrationID)
 	if config.IntegrationID == "" {
 		return nil, errors.New("datasource is required")
 	}
@@ -124,6 +125,7 @@ func init() {
 	if err := actions.RegisterAction("store", actions.ActionRegistrationInfo{
 		Name:        "Write Data",
 		Description: "Stores data records into database tables with field mapping",
+		SideEffects: true,
 		Fields:      fields,
 		Constructor: func(config json.RawMessage) (actions.ActionExecutable, error) {
 			var cfg Config
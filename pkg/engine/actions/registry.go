@@ -19,6 +19,9 @@ type ActionRegistrationInfo struct {
 	Constructor   factoryFunc          `json:"-"`
 	ConstructorV2 factoryFuncV2        `json:"-"` // V2 constructor (used when UseV2 is true)
 	UseV2         bool                 `json:"-"` // If true, use V2 interface (action handles own template resolution)
+	// SideEffects marks actions that change state outside the request, e.g.
+	// writing records or sending email, so running them again is not safe.
+	SideEffects bool `json:"sideEffects"`
 }
 
 type FieldType string
@@ -33,6 +36,7 @@ const (
 	FieldTypeFile        FieldType = "file"
 	FieldTypeTextArea    FieldType = "text_area"
 	FieldTypeArray       FieldType = "array"
+	FieldTypeNumber      FieldType = "number"
 )
 
 type FieldInfo struct {
@@ -92,6 +96,7 @@ func (r *Registry) ReplaceActionType(actionType string, constructor factoryFunc)
 		Name:        existing.Name,
 		Description: existing.Description,
 		Fields:      existing.Fields,
+		SideEffects: existing.SideEffects,
 	}
 }
 
@@ -151,6 +156,13 @@ func (r *Registry) IsV2Action(actionType string) bool {
 	return registration.UseV2
 }
 
+// HasSideEffects reports whether the action type is registered with
+// SideEffects set.
+func (r *Registry) HasSideEffects(actionType string) bool {
+	registration, ok := r.availableConstructors[actionType]
+	return ok && registration.SideEffects
+}
+
 func RegisterAction(actionType string, registration ActionRegistrationInfo) error {
 	return actionManager.RegisterAction(actionType, registration)
 }
@@ -177,6 +189,12 @@ func IsV2Action(actionType string) bool {
 	return actionManager.IsV2Action(actionType)
 }
 
+// HasSideEffects reports whether the action type is registered with
+// SideEffects set.
+func HasSideEffects(actionType string) bool {
+	return actionManager.HasSideEffects(actionType)
+}
+
 // GetActionExecutableV2 returns a V2 action executable for the given type.
 func GetActionExecutableV2(actionType string, config json.RawMessage) (ActionExecutableV2, error) {
 	return actionManager.GetActionExecutableV2(actionType, config)
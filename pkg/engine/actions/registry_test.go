@@ -84,6 +84,7 @@ func TestReplaceActionType(t *testing.T) {
 	err := RegisterAction("replaceable-action-unique", ActionRegistrationInfo{
 		Constructor: originalConstructor,
 		Fields:      map[string]FieldInfo{},
+		SideEffects: true,
 	})
 	require.NoError(t, err)
 
@@ -97,6 +98,8 @@ func TestReplaceActionType(t *testing.T) {
 	executable, err := GetActionExecutable("replaceable-action-unique", json.RawMessage(`{}`))
 	require.NoError(t, err)
 	assert.Equal(t, "replaced", executable.Config())
+	assert.True(t, HasSideEffects("replaceable-action-unique"))
+	assert.False(t, HasSideEffects("unregistered-action-unique"))
 }
 
 func TestGetActionExecutable(t *testing.T) {
@@ -0,0 +1,269 @@
+// Package fieldencryption provides the "field_encryption" entry handler, which
+// decrypts client-encrypted request body fields before the workflow runs and
+// encrypts designated response fields on the way out.
+//
+// Fields are encrypted with AES-GCM and encoded as base64(nonce || ciphertext).
+// Handler config:
+//
+//	{
+//	  "key": "{{ secret \"pii_key\" }}",   // base64 AES key (16, 24 or 32 bytes)
+//	  "requestFields": ["ssn", "card.number"],
+//	  "responseFields": ["ssn"]
+//	}
+//
+// Field names are gjson paths into the JSON body. Each decrypted request field
+// is written back into the body in plaintext, so {{ body "ssn" }} sees the
+// decrypted value, and is also stored as the request variable
+// "decrypted_<path>" with dots replaced by underscores (e.g.
+// {{ .decrypted_card_number }}).
+package fieldencryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/engine/entryhandlers"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+const (
+	HandlerType = "field_encryption"
+
+	// VariablePrefix prefixes the request variables decrypted fields are
+	// stored under.
+	VariablePrefix = "decrypted_"
+)
+
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+type config struct {
+	key            []byte
+	requestFields  []string
+	responseFields []string
+}
+
+func parseConfig(raw map[string]interface{}) (*config, error) {
+	keyStr, _ := raw["key"].(string)
+	key, err := ParseKey(keyStr)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &config{key: key}
+	if cfg.requestFields, err = stringList(raw, "requestFields"); err != nil {
+		return nil, err
+	}
+	if cfg.responseFields, err = stringList(raw, "responseFields"); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func stringList(raw map[string]interface{}, field string) ([]string, error) {
+	switch v := raw[field].(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", field)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%s must be a list of strings", field)
+	}
+}
+
+// ParseKey decodes a base64 AES key, which must be 16, 24 or 32 bytes long.
+func ParseKey(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("encryption key is required")
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key must be base64 encoded: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("encryption key must be 16, 24 or 32 bytes, got %d", len(key))
+	}
+}
+
+// Encrypt seals plaintext with AES-GCM and returns base64(nonce || ciphertext).
+func Encrypt(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt.
+func Decrypt(key []byte, encoded string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("%w: too short", ErrInvalidCiphertext)
+	}
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	return string(plain), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// VariableName returns the request variable a decrypted request field is
+// stored under.
+func VariableName(path string) string {
+	return VariablePrefix + strings.ReplaceAll(path, ".", "_")
+}
+
+// Middleware decrypts the configured request fields before calling next and
+// encrypts the configured response fields in whatever next writes.
+func Middleware(raw map[string]interface{}, next http.Handler) http.Handler {
+	cfg, cfgErr := parseConfig(raw)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+		if cfgErr != nil {
+			logger.Error("invalid field encryption config", zap.Error(cfgErr))
+			http.Error(w, "error completing request, please reach out to admin", http.StatusInternalServerError)
+			return
+		}
+
+		if len(cfg.requestFields) > 0 {
+			if err := decryptRequest(r, cfg); err != nil {
+				logger.Debug("could not decrypt request fields", zap.Error(err))
+				http.Error(w, "invalid encrypted field", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if len(cfg.responseFields) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buf := &bufferedWriter{header: w.Header(), code: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		body, err := encryptResponse(buf.body.Bytes(), cfg)
+		if err != nil {
+			logger.Error("could not encrypt response fields", zap.Error(err))
+			http.Error(w, "error completing request, please reach out to admin", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Del("Content-Length")
+		w.WriteHeader(buf.code)
+		w.Write(body)
+	})
+}
+
+func decryptRequest(r *http.Request, cfg *config) error {
+	body := []byte(requestctx.ReadAndRestoreBody(r))
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return errors.New("request body is not valid JSON")
+	}
+
+	vars := make(map[string]interface{}, len(cfg.requestFields))
+	for _, path := range cfg.requestFields {
+		res := gjson.GetBytes(body, path)
+		if !res.Exists() {
+			continue
+		}
+		plain, err := Decrypt(cfg.key, res.String())
+		if err != nil {
+			return fmt.Errorf("field %s: %w", path, err)
+		}
+		if body, err = sjson.SetBytes(body, path, plain); err != nil {
+			return fmt.Errorf("field %s: %w", path, err)
+		}
+		vars[VariableName(path)] = plain
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return requestctx.AddRequestVariables(r.Context(), vars, "")
+}
+
+func encryptResponse(body []byte, cfg *config) ([]byte, error) {
+	if !gjson.ValidBytes(body) {
+		return body, nil
+	}
+	for _, path := range cfg.responseFields {
+		res := gjson.GetBytes(body, path)
+		if !res.Exists() {
+			continue
+		}
+		sealed, err := Encrypt(cfg.key, res.String())
+		if err != nil {
+			return nil, err
+		}
+		if body, err = sjson.SetBytes(body, path, sealed); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// bufferedWriter captures the downstream response so fields can be encrypted
+// before anything reaches the client. Headers are shared with the real writer.
+type bufferedWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (b *bufferedWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedWriter) WriteHeader(code int) {
+	b.code = code
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+func init() {
+	entryhandlers.Register(HandlerType, Middleware)
+}
@@ -0,0 +1,115 @@
+package fieldencryption
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"key":            base64.StdEncoding.EncodeToString(testKey),
+		"requestFields":  []interface{}{"ssn", "card.number"},
+		"responseFields": []interface{}{"ssn"},
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	sealed, err := Encrypt(testKey, "123-45-6789")
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "123-45-6789")
+
+	plain, err := Decrypt(testKey, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "123-45-6789", plain)
+
+	_, err = Decrypt([]byte("fedcba9876543210fedcba9876543210"), sealed)
+	assert.True(t, errors.Is(err, ErrInvalidCiphertext))
+
+	_, err = Decrypt(testKey, "not base64!")
+	assert.True(t, errors.Is(err, ErrInvalidCiphertext))
+}
+
+func TestParseKey(t *testing.T) {
+	_, err := ParseKey("")
+	assert.ErrorContains(t, err, "required")
+
+	_, err = ParseKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.ErrorContains(t, err, "16, 24 or 32 bytes")
+
+	key, err := ParseKey(base64.StdEncoding.EncodeToString(testKey))
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("decrypts request fields and encrypts response fields", func(t *testing.T) {
+		ssn, err := Encrypt(testKey, "123-45-6789")
+		require.NoError(t, err)
+		card, err := Encrypt(testKey, "4111111111111111")
+		require.NoError(t, err)
+
+		var gotBody string
+		var gotSSN, gotCard string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			gotBody = string(b)
+			gotSSN, _ = requestctx.GetStringVariable(r.Context(), "decrypted_ssn")
+			gotCard, _ = requestctx.GetStringVariable(r.Context(), "decrypted_card_number")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"ssn":"123-45-6789","name":"jane"}`))
+		})
+
+		body := `{"name":"jane","ssn":"` + ssn + `","card":{"number":"` + card + `"}}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req = req.WithContext(requestctx.NewTestContext())
+		w := httptest.NewRecorder()
+		Middleware(testConfig(), next).ServeHTTP(w, req)
+
+		assert.Equal(t, "123-45-6789", gotSSN)
+		assert.Equal(t, "4111111111111111", gotCard)
+		assert.JSONEq(t, `{"name":"jane","ssn":"123-45-6789","card":{"number":"4111111111111111"}}`, gotBody)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "jane", gjson.Get(w.Body.String(), "name").String())
+		sealed := gjson.Get(w.Body.String(), "ssn").String()
+		assert.NotEqual(t, "123-45-6789", sealed)
+		plain, err := Decrypt(testKey, sealed)
+		require.NoError(t, err)
+		assert.Equal(t, "123-45-6789", plain)
+	})
+
+	t.Run("rejects tampered ciphertext", func(t *testing.T) {
+		called := false
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"ssn":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}`))
+		req = req.WithContext(requestctx.NewTestContext())
+		w := httptest.NewRecorder()
+		Middleware(testConfig(), next).ServeHTTP(w, req)
+
+		assert.False(t, called)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid key is a server error", func(t *testing.T) {
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+		req = req.WithContext(requestctx.NewTestContext())
+		w := httptest.NewRecorder()
+		Middleware(map[string]interface{}{"key": "bad"}, next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
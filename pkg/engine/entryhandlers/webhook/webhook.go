@@ -0,0 +1,284 @@
+// Package webhook provides the "webhook" entry handler, which processes
+// signed webhook deliveries at most once per event.
+//
+// Each delivery must carry a hex HMAC-SHA256 of its raw body in the signature
+// header, optionally prefixed with "sha256=" as GitHub and others send it.
+// Deliveries with a missing or wrong signature are rejected with 401.
+//
+// The event id is read from a header or, when eventIdField is set, from the
+// JSON body, and recorded in a datasource integration before the workflow
+// runs. A delivery whose event id is already recorded is acknowledged with
+// 200 without running the workflow, so replays of a processed delivery are
+// never processed twice. When the workflow responds with anything but 2xx,
+// the record is deleted again so the sender's retry is processed. A delivery
+// of an event still being processed by this instance gets 409. Handler config:
+//
+//	{
+//	  "secret": "{{ secret \"webhook_secret\" }}",
+//	  "signatureHeader": "X-Signature",   // default X-Signature
+//	  "eventIdHeader": "X-Event-ID",      // default X-Event-ID
+//	  "eventIdField": "id",               // gjson path into the body, overrides eventIdHeader
+//	  "integrationID": "db",
+//	  "collection": "webhook_events"      // default webhook_events
+//	}
+//
+// Events are recorded as {"event_id": ..., "received_at": ...}. Across
+// several instances, give event_id a unique index so that a concurrent
+// delivery of the same event fails to record it instead of running twice.
+// The event id is available to the workflow as {{ .webhook_event_id }}.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/engine/entryhandlers"
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+const (
+	HandlerType = "webhook"
+
+	// EventIDVariable is the request variable the event id is stored under.
+	EventIDVariable = "webhook_event_id"
+
+	defaultSignatureHeader = "X-Signature"
+	defaultEventIDHeader   = "X-Event-ID"
+	defaultCollection      = "webhook_events"
+
+	eventIDColumn    = "event_id"
+	receivedAtColumn = "received_at"
+)
+
+// eventTable is the part of a datasource integration the handler records
+// events in.
+type eventTable interface {
+	Fetch(ctx context.Context, options map[string]string, filters ...filters.Filter) ([]map[string]interface{}, error)
+	Store(ctx context.Context, item map[string]interface{}, options map[string]string) error
+	Delete(ctx context.Context, options map[string]string, filters ...filters.Filter) error
+}
+
+type config struct {
+	secret          []byte
+	signatureHeader string
+	eventIDHeader   string
+	eventIDField    string
+	integrationID   string
+	collection      string
+}
+
+func parseConfig(raw map[string]interface{}) (*config, error) {
+	cfg := &config{
+		signatureHeader: defaultSignatureHeader,
+		eventIDHeader:   defaultEventIDHeader,
+		collection:      defaultCollection,
+	}
+	secret, _ := raw["secret"].(string)
+	if secret == "" {
+		return nil, errors.New("webhook secret is required")
+	}
+	cfg.secret = []byte(secret)
+	if cfg.integrationID, _ = raw["integrationID"].(string); cfg.integrationID == "" {
+		return nil, errors.New("webhook integrationID is required")
+	}
+	if v, _ := raw["signatureHeader"].(string); v != "" {
+		cfg.signatureHeader = v
+	}
+	if v, _ := raw["eventIdHeader"].(string); v != "" {
+		cfg.eventIDHeader = v
+	}
+	if v, _ := raw["collection"].(string); v != "" {
+		cfg.collection = v
+	}
+	cfg.eventIDField, _ = raw["eventIdField"].(string)
+	return cfg, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body under secret, the value expected
+// in the signature header.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validSignature(secret, body []byte, header string) bool {
+	got, err := hex.DecodeString(strings.TrimPrefix(header, "sha256="))
+	if err != nil || len(got) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// inFlight holds the events being processed in this process, so that a
+// concurrent delivery of one event neither passes the lookup before the event
+// is recorded nor is acknowledged while the first delivery may still fail.
+var inFlight = struct {
+	sync.Mutex
+	events map[string]struct{}
+}{events: make(map[string]struct{})}
+
+var errInFlight = errors.New("webhook event is already being processed")
+
+// claim records the event unless it was already recorded, reporting false for
+// a duplicate and errInFlight while another delivery of it is processed. The
+// returned release must be called once the event is processed; it deletes the
+// record again unless processed is true.
+func claim(ctx context.Context, cfg *config, eventID string) (first bool, release func(processed bool), err error) {
+	key := cfg.integrationID + ":" + cfg.collection + ":" + eventID
+	inFlight.Lock()
+	if _, ok := inFlight.events[key]; ok {
+		inFlight.Unlock()
+		return false, nil, errInFlight
+	}
+	inFlight.events[key] = struct{}{}
+	inFlight.Unlock()
+	done := func() {
+		inFlight.Lock()
+		delete(inFlight.events, key)
+		inFlight.Unlock()
+	}
+	defer func() {
+		if release == nil {
+			done()
+		}
+	}()
+
+	i, err := integration.GetIntegration(ctx, cfg.integrationID)
+	if err != nil {
+		return false, nil, err
+	}
+	table, ok := i.(eventTable)
+	if !ok {
+		return false, nil, fmt.Errorf("integration %s does not support fetch, store and delete", cfg.integrationID)
+	}
+
+	options := map[string]string{"collection": cfg.collection, filters.LimitOption: "1"}
+	rows, err := table.Fetch(ctx, options, filters.Filter{Field: eventIDColumn, Operation: filters.Equals, Comparator: eventID})
+	if err != nil {
+		return false, nil, fmt.Errorf("error looking up webhook event: %w", err)
+	}
+	if len(rows) > 0 {
+		return false, nil, nil
+	}
+	item := map[string]interface{}{
+		eventIDColumn:    eventID,
+		receivedAtColumn: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := table.Store(ctx, item, map[string]string{"collection": cfg.collection}); err != nil {
+		return false, nil, fmt.Errorf("error recording webhook event: %w", err)
+	}
+
+	release = func(processed bool) {
+		defer done()
+		if processed {
+			return
+		}
+		// The request may have been cancelled along with the failed flow.
+		ctx := context.WithoutCancel(ctx)
+		if err := table.Delete(ctx, map[string]string{"collection": cfg.collection},
+			filters.Filter{Field: eventIDColumn, Operation: filters.Equals, Comparator: eventID}); err != nil {
+			logging.FromContext(ctx).Error("could not release failed webhook event", zap.String("event_id", eventID), zap.Error(err))
+		}
+	}
+	return true, release, nil
+}
+
+// statusWriter records the status code of the response it passes through.
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (s *statusWriter) WriteHeader(code int) {
+	if s.code == 0 {
+		s.code = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusWriter) Write(b []byte) (int, error) {
+	if s.code == 0 {
+		s.code = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Middleware verifies the delivery's signature and runs next only for the
+// first delivery of each event.
+func Middleware(raw map[string]interface{}, next http.Handler) http.Handler {
+	cfg, cfgErr := parseConfig(raw)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+		if cfgErr != nil {
+			logger.Error("invalid webhook config", zap.Error(cfgErr))
+			http.Error(w, "error completing request, please reach out to admin", http.StatusInternalServerError)
+			return
+		}
+
+		body := []byte(requestctx.ReadAndRestoreBody(r))
+		if !validSignature(cfg.secret, body, r.Header.Get(cfg.signatureHeader)) {
+			logger.Debug("webhook signature mismatch")
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		eventID := r.Header.Get(cfg.eventIDHeader)
+		if cfg.eventIDField != "" {
+			eventID = gjson.GetBytes(body, cfg.eventIDField).String()
+		}
+		if eventID == "" {
+			http.Error(w, "missing event id", http.StatusBadRequest)
+			return
+		}
+
+		first, release, err := claim(r.Context(), cfg, eventID)
+		if errors.Is(err, errInFlight) {
+			logger.Debug("webhook event is already being processed", zap.String("event_id", eventID))
+			http.Error(w, "event is being processed", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			logger.Error("could not record webhook event", zap.String("event_id", eventID), zap.Error(err))
+			http.Error(w, "error completing request, please reach out to admin", http.StatusInternalServerError)
+			return
+		}
+		if !first {
+			logger.Debug("acknowledging duplicate webhook event", zap.String("event_id", eventID))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"status":"duplicate"}`))
+			return
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+		processed := false
+		defer func() { release(processed) }()
+		_ = requestctx.AddRequestVariables(r.Context(), map[string]interface{}{EventIDVariable: eventID}, "")
+		next.ServeHTTP(sw, r)
+		processed = sw.code == 0 || sw.code >= 200 && sw.code < 300
+	})
+}
+
+func init() {
+	entryhandlers.Register(HandlerType, Middleware)
+}
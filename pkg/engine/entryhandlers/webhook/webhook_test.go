@@ -0,0 +1,33 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidSignature(t *testing.T) {
+	secret, body := []byte("s3cret"), []byte(`{"id":"evt-1"}`)
+	sig := Sign(secret, body)
+
+	assert.True(t, validSignature(secret, body, sig))
+	assert.True(t, validSignature(secret, body, "sha256="+sig))
+	assert.False(t, validSignature(secret, []byte(`{"id":"evt-2"}`), sig))
+	assert.False(t, validSignature([]byte("other"), body, sig))
+	assert.False(t, validSignature(secret, body, ""))
+	assert.False(t, validSignature(secret, body, "not-hex"))
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := parseConfig(map[string]interface{}{"secret": "s", "integrationID": "db", "eventIdField": "id"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultSignatureHeader, cfg.signatureHeader)
+	assert.Equal(t, defaultCollection, cfg.collection)
+	assert.Equal(t, "id", cfg.eventIDField)
+
+	_, err = parseConfig(map[string]interface{}{"integrationID": "db"})
+	assert.Error(t, err)
+	_, err = parseConfig(map[string]interface{}{"secret": "s"})
+	assert.Error(t, err)
+}
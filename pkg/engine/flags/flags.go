@@ -0,0 +1,179 @@
+// Package flags evaluates feature flags so a flow can send a percentage or an
+// allowlist of requests down a different branch, e.g. in a conditional:
+//
+//	{{ flag "new_checkout" (param "user_id") }}
+//
+// Flags are looked up in the registered sources in order (environment first,
+// then engine config, then an integration); the first source that knows the
+// flag wins.
+package flags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EnvPrefix is prepended to the upper-cased flag name to find its environment
+// override: SERVFLOW_FLAG_NEW_CHECKOUT=25% enables new_checkout for a quarter
+// of keys, =true or =false switches it fully on or off.
+const EnvPrefix = "SERVFLOW_FLAG_"
+
+// buckets is the resolution of percentage rollouts: 10000 buckets allow two
+// decimal places, e.g. 0.25%.
+const buckets = 10000
+
+// Flag decides which keys take the flagged branch.
+type Flag struct {
+	// Enabled switches the flag off for everyone when false.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Percentage of keys (0-100) the flag is on for. Unset means every key,
+	// unless Allow is set.
+	Percentage *float64 `json:"percentage,omitempty" yaml:"percentage,omitempty"`
+	// Allow lists keys the flag is always on for. With no Percentage the flag
+	// is on for these keys only.
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+}
+
+// On reports whether the flag named name is on for key. Percentage rollouts
+// are stable: the same name and key always land in the same bucket, and
+// raising the percentage only ever adds keys.
+func (f Flag) On(name, key string) bool {
+	if !f.Enabled {
+		return false
+	}
+	for _, allowed := range f.Allow {
+		if allowed == key {
+			return true
+		}
+	}
+	if f.Percentage == nil {
+		return len(f.Allow) == 0
+	}
+	return float64(Bucket(name, key)) < *f.Percentage*buckets/100
+}
+
+// Bucket maps key to one of 10000 buckets. The flag name salts the hash so
+// that keys in the first 10% of one flag are not also the first 10% of every
+// other flag.
+func Bucket(name, key string) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum64() % buckets)
+}
+
+// Source looks up flags by name. ok is false when the source does not define
+// the flag, so the next source is asked.
+type Source interface {
+	Lookup(ctx context.Context, name string) (flag Flag, ok bool, err error)
+}
+
+// Static is a Source backed by a fixed set of flags, usually from the engine
+// config.
+type Static map[string]Flag
+
+func (s Static) Lookup(_ context.Context, name string) (Flag, bool, error) {
+	f, ok := s[name]
+	return f, ok, nil
+}
+
+type envSource struct{}
+
+// NewEnvSource returns the Source reading EnvPrefix environment variables.
+func NewEnvSource() Source {
+	return envSource{}
+}
+
+func (envSource) Lookup(_ context.Context, name string) (Flag, bool, error) {
+	v, ok := os.LookupEnv(EnvPrefix + strings.ToUpper(name))
+	if !ok {
+		return Flag{}, false, nil
+	}
+	f, err := ParseFlag(v)
+	if err != nil {
+		return Flag{}, false, fmt.Errorf("invalid %s%s: %w", EnvPrefix, strings.ToUpper(name), err)
+	}
+	return f, true, nil
+}
+
+// ParseFlag parses the short form of a flag used by the environment and by
+// integration rows with a plain value: "true"/"false", or a percentage such
+// as "25" or "25%". Other boolean spellings are not accepted, so "1" is a 1%
+// rollout rather than on.
+func ParseFlag(v string) (Flag, error) {
+	v = strings.TrimSpace(v)
+	switch {
+	case strings.EqualFold(v, "true"):
+		return Flag{Enabled: true}, nil
+	case strings.EqualFold(v, "false"):
+		return Flag{Enabled: false}, nil
+	}
+	pct, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+	if err != nil || pct < 0 || pct > 100 {
+		return Flag{}, fmt.Errorf("%q is neither a boolean nor a percentage between 0 and 100", v)
+	}
+	return Flag{Enabled: true, Percentage: &pct}, nil
+}
+
+// Manager evaluates flags against its sources.
+type Manager struct {
+	mu      sync.RWMutex
+	sources []Source
+}
+
+var (
+	manager *Manager
+	once    sync.Once
+)
+
+// GetManager returns the singleton Manager, which reads the environment until
+// SetSources is called.
+func GetManager() *Manager {
+	once.Do(func() {
+		manager = &Manager{sources: []Source{NewEnvSource()}}
+	})
+	return manager
+}
+
+// SetSources replaces the sources flags are looked up in, in priority order.
+func (m *Manager) SetSources(sources ...Source) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sources = sources
+}
+
+// Evaluate reports whether the flag name is on for key. An unknown flag is
+// off.
+func (m *Manager) Evaluate(ctx context.Context, name, key string) (bool, error) {
+	m.mu.RLock()
+	sources := m.sources
+	m.mu.RUnlock()
+
+	for _, s := range sources {
+		f, ok, err := s.Lookup(ctx, name)
+		if err != nil {
+			return false, fmt.Errorf("flag %s: %w", name, err)
+		}
+		if ok {
+			return f.On(name, key), nil
+		}
+	}
+	return false, nil
+}
+
+// Evaluate is a convenience function that uses the global manager.
+func Evaluate(ctx context.Context, name, key string) (bool, error) {
+	return GetManager().Evaluate(ctx, name, key)
+}
+
+// Reset resets the manager (useful for testing)
+func Reset() {
+	manager = nil
+	once = sync.Once{}
+}
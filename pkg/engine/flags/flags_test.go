@@ -0,0 +1,194 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func percentage(p float64) *float64 {
+	return &p
+}
+
+func TestBucket(t *testing.T) {
+	t.Run("stable for the same key", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			key := fmt.Sprintf("user-%d", i)
+			assert.Equal(t, Bucket("new_checkout", key), Bucket("new_checkout", key))
+		}
+	})
+
+	t.Run("salted by flag name", func(t *testing.T) {
+		differ := 0
+		for i := 0; i < 100; i++ {
+			key := fmt.Sprintf("user-%d", i)
+			if Bucket("new_checkout", key) != Bucket("dark_mode", key) {
+				differ++
+			}
+		}
+		assert.Greater(t, differ, 90)
+	})
+}
+
+func TestFlag_On(t *testing.T) {
+	t.Run("disabled is off for everyone", func(t *testing.T) {
+		f := Flag{Enabled: false, Allow: []string{"alice"}}
+		assert.False(t, f.On("f", "alice"))
+	})
+
+	t.Run("enabled without rules is on for everyone", func(t *testing.T) {
+		f := Flag{Enabled: true}
+		assert.True(t, f.On("f", "anyone"))
+		assert.True(t, f.On("f", ""))
+	})
+
+	t.Run("allowlist only", func(t *testing.T) {
+		f := Flag{Enabled: true, Allow: []string{"alice", "bob"}}
+		assert.True(t, f.On("f", "alice"))
+		assert.True(t, f.On("f", "bob"))
+		assert.False(t, f.On("f", "carol"))
+	})
+
+	t.Run("allowlist on top of a percentage", func(t *testing.T) {
+		f := Flag{Enabled: true, Percentage: percentage(0), Allow: []string{"alice"}}
+		assert.True(t, f.On("f", "alice"))
+		assert.False(t, f.On("f", "carol"))
+	})
+
+	t.Run("same key always takes the same branch", func(t *testing.T) {
+		f := Flag{Enabled: true, Percentage: percentage(50)}
+		for i := 0; i < 200; i++ {
+			key := fmt.Sprintf("user-%d", i)
+			first := f.On("new_checkout", key)
+			for j := 0; j < 5; j++ {
+				assert.Equal(t, first, f.On("new_checkout", key))
+			}
+		}
+	})
+
+	t.Run("split proportions", func(t *testing.T) {
+		const keys = 20000
+		for _, pct := range []float64{0, 1, 10, 25, 50, 90, 100} {
+			f := Flag{Enabled: true, Percentage: percentage(pct)}
+			on := 0
+			for i := 0; i < keys; i++ {
+				if f.On("new_checkout", fmt.Sprintf("user-%d", i)) {
+					on++
+				}
+			}
+			got := float64(on) * 100 / keys
+			assert.InDelta(t, pct, got, 1.0, "percentage %v", pct)
+		}
+	})
+
+	t.Run("raising the percentage only adds keys", func(t *testing.T) {
+		low := Flag{Enabled: true, Percentage: percentage(10)}
+		high := Flag{Enabled: true, Percentage: percentage(30)}
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("user-%d", i)
+			if low.On("f", key) {
+				assert.True(t, high.On("f", key), key)
+			}
+		}
+	})
+}
+
+func TestParseFlag(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected Flag
+		hasError bool
+	}{
+		{in: "true", expected: Flag{Enabled: true}},
+		{in: "false", expected: Flag{Enabled: false}},
+		{in: "TRUE", expected: Flag{Enabled: true}},
+		{in: "25", expected: Flag{Enabled: true, Percentage: percentage(25)}},
+		{in: "1", expected: Flag{Enabled: true, Percentage: percentage(1)}},
+		{in: "0", expected: Flag{Enabled: true, Percentage: percentage(0)}},
+		{in: "t", hasError: true},
+		{in: " 12.5% ", expected: Flag{Enabled: true, Percentage: percentage(12.5)}},
+		{in: "150%", hasError: true},
+		{in: "sometimes", hasError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			f, err := ParseFlag(tt.in)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, f)
+		})
+	}
+}
+
+type errSource struct{}
+
+func (errSource) Lookup(context.Context, string) (Flag, bool, error) {
+	return Flag{}, false, errors.New("unavailable")
+}
+
+func TestManager_Evaluate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("first source that knows the flag wins", func(t *testing.T) {
+		m := &Manager{}
+		m.SetSources(
+			Static{"a": {Enabled: false}},
+			Static{"a": {Enabled: true}, "b": {Enabled: true}},
+		)
+		on, err := m.Evaluate(ctx, "a", "k")
+		require.NoError(t, err)
+		assert.False(t, on)
+
+		on, err = m.Evaluate(ctx, "b", "k")
+		require.NoError(t, err)
+		assert.True(t, on)
+	})
+
+	t.Run("unknown flag is off", func(t *testing.T) {
+		m := &Manager{}
+		m.SetSources(Static{})
+		on, err := m.Evaluate(ctx, "missing", "k")
+		require.NoError(t, err)
+		assert.False(t, on)
+	})
+
+	t.Run("environment overrides config", func(t *testing.T) {
+		t.Setenv(EnvPrefix+"NEW_CHECKOUT", "false")
+		m := &Manager{}
+		m.SetSources(NewEnvSource(), Static{"new_checkout": {Enabled: true}})
+		on, err := m.Evaluate(ctx, "new_checkout", "k")
+		require.NoError(t, err)
+		assert.False(t, on)
+	})
+
+	t.Run("invalid environment value", func(t *testing.T) {
+		t.Setenv(EnvPrefix+"BROKEN", "maybe")
+		m := &Manager{}
+		m.SetSources(NewEnvSource())
+		_, err := m.Evaluate(ctx, "broken", "k")
+		assert.ErrorContains(t, err, "SERVFLOW_FLAG_BROKEN")
+	})
+
+	t.Run("source errors are returned", func(t *testing.T) {
+		m := &Manager{}
+		m.SetSources(errSource{}, Static{"a": {Enabled: true}})
+		_, err := m.Evaluate(ctx, "a", "k")
+		assert.ErrorContains(t, err, "unavailable")
+	})
+
+	t.Run("global manager reads the environment by default", func(t *testing.T) {
+		Reset()
+		defer Reset()
+		t.Setenv(EnvPrefix+"DARK_MODE", "true")
+		on, err := Evaluate(ctx, "dark_mode", "")
+		require.NoError(t, err)
+		assert.True(t, on)
+	})
+}
@@ -0,0 +1,218 @@
+// Package i18n resolves localized messages for the `t` template function. A
+// Catalog holds messages keyed by locale; lookups walk the request's
+// Accept-Language preferences and fall back to the catalog's default locale.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is used when a Config does not name one.
+const DefaultLocale = "en"
+
+// Config declares the message catalog. Messages are read from one file per
+// locale in Dir (en.yaml, fr.json, pt-BR.yml, ...) and from the inline
+// Messages; inline messages win.
+type Config struct {
+	DefaultLocale string                       `json:"defaultLocale" yaml:"defaultLocale"`
+	Dir           string                       `json:"dir" yaml:"dir"`
+	Messages      map[string]map[string]string `json:"messages" yaml:"messages"`
+}
+
+// Catalog is a set of messages keyed by locale, then by message key.
+type Catalog struct {
+	defaultLocale string
+	messages      map[string]map[string]string
+}
+
+// NewCatalog returns an empty catalog falling back to defaultLocale.
+func NewCatalog(defaultLocale string) *Catalog {
+	if defaultLocale == "" {
+		defaultLocale = DefaultLocale
+	}
+	return &Catalog{
+		defaultLocale: normalizeLocale(defaultLocale),
+		messages:      make(map[string]map[string]string),
+	}
+}
+
+// New builds the catalog described by cfg.
+func New(cfg Config) (*Catalog, error) {
+	c := NewCatalog(cfg.DefaultLocale)
+	if cfg.Dir != "" {
+		if err := c.LoadDir(cfg.Dir); err != nil {
+			return nil, err
+		}
+	}
+	for locale, messages := range cfg.Messages {
+		c.Add(locale, messages)
+	}
+	return c, nil
+}
+
+// Add merges messages into locale, replacing existing keys.
+func (c *Catalog) Add(locale string, messages map[string]string) {
+	locale = normalizeLocale(locale)
+	m, ok := c.messages[locale]
+	if !ok {
+		m = make(map[string]string, len(messages))
+		c.messages[locale] = m
+	}
+	for k, v := range messages {
+		m[k] = v
+	}
+}
+
+// LoadDir adds every .json, .yaml and .yml file in dir, using the file name
+// without its extension as the locale. Nested objects are flattened into
+// dotted keys, so {"errors": {"notFound": "..."}} defines errors.notFound.
+func (c *Catalog) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read messages folder: %w", err)
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		contents, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read messages %s: %w", entry.Name(), err)
+		}
+		var raw map[string]interface{}
+		if ext == ".json" {
+			err = json.Unmarshal(contents, &raw)
+		} else {
+			err = yaml.Unmarshal(contents, &raw)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse messages %s: %w", entry.Name(), err)
+		}
+		messages := make(map[string]string)
+		flatten("", raw, messages)
+		c.Add(strings.TrimSuffix(entry.Name(), ext), messages)
+	}
+	return nil
+}
+
+func flatten(prefix string, in map[string]interface{}, out map[string]string) {
+	for k, v := range in {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		switch t := v.(type) {
+		case map[string]interface{}:
+			flatten(k, t, out)
+		default:
+			out[k] = fmt.Sprint(t)
+		}
+	}
+}
+
+// Translate returns the message for key in the first of locales the catalog
+// has it for, trying each locale's base language too (fr-CA, then fr), and
+// finally the default locale. A missing message resolves to the key itself
+// so gaps in a catalog are visible rather than blank. args, when given, are
+// formatted into the message with fmt.Sprintf.
+func (c *Catalog) Translate(locales []string, key string, args ...interface{}) string {
+	msg, ok := c.lookup(locales, key)
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+func (c *Catalog) lookup(locales []string, key string) (string, bool) {
+	for _, locale := range locales {
+		locale = normalizeLocale(locale)
+		if msg, ok := c.messages[locale][key]; ok {
+			return msg, true
+		}
+		if base, _, found := strings.Cut(locale, "-"); found {
+			if msg, ok := c.messages[base][key]; ok {
+				return msg, true
+			}
+		}
+	}
+	msg, ok := c.messages[c.defaultLocale][key]
+	return msg, ok
+}
+
+// normalizeLocale lower-cases a locale and uses "-" as the separator, so
+// pt_BR, pt-br and PT-BR all match.
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// ParseAcceptLanguage returns the locales of an Accept-Language header in
+// order of preference. Entries with q=0 and the "*" wildcard are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var prefs []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		locale = strings.TrimSpace(locale)
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		prefs = append(prefs, weighted{locale: locale, q: q})
+	}
+	sort.SliceStable(prefs, func(i, j int) bool {
+		return prefs[i].q > prefs[j].q
+	})
+	locales := make([]string, len(prefs))
+	for i, p := range prefs {
+		locales[i] = p.locale
+	}
+	return locales
+}
+
+var (
+	defaultCatalog *Catalog
+	defaultMu      sync.RWMutex
+)
+
+// SetDefault installs c as the catalog used by the `t` template function.
+func SetDefault(c *Catalog) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultCatalog = c
+}
+
+// Default returns the installed catalog, or an empty one that resolves every
+// key to itself.
+func Default() *Catalog {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if defaultCatalog == nil {
+		return NewCatalog(DefaultLocale)
+	}
+	return defaultCatalog
+}
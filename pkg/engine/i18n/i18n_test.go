@@ -0,0 +1,96 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected []string
+	}{
+		{header: "", expected: []string{}},
+		{header: "fr", expected: []string{"fr"}},
+		{header: "fr-CA, fr;q=0.9, en;q=0.8", expected: []string{"fr-CA", "fr", "en"}},
+		{header: "en;q=0.5, de", expected: []string{"de", "en"}},
+		{header: "es;q=0, *, it;q=0.3", expected: []string{"it"}},
+		{header: "pt;q=bad, pt-BR", expected: []string{"pt-BR"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseAcceptLanguage(tt.header))
+		})
+	}
+}
+
+func TestCatalog_Translate(t *testing.T) {
+	c := NewCatalog("en")
+	c.Add("en", map[string]string{"greeting": "Hello", "welcome": "Welcome, %s", "only.en": "English only"})
+	c.Add("fr", map[string]string{"greeting": "Bonjour", "welcome": "Bienvenue, %s"})
+	c.Add("pt_BR", map[string]string{"greeting": "Olá"})
+
+	tests := []struct {
+		name     string
+		locales  []string
+		key      string
+		args     []interface{}
+		expected string
+	}{
+		{name: "exact locale", locales: []string{"fr"}, key: "greeting", expected: "Bonjour"},
+		{name: "regional falls back to base language", locales: []string{"fr-CA"}, key: "greeting", expected: "Bonjour"},
+		{name: "locale matching is case and separator insensitive", locales: []string{"PT-br"}, key: "greeting", expected: "Olá"},
+		{name: "first known preference wins", locales: []string{"de", "fr", "en"}, key: "greeting", expected: "Bonjour"},
+		{name: "missing key in locale uses default", locales: []string{"fr"}, key: "only.en", expected: "English only"},
+		{name: "no preferences uses default", key: "greeting", expected: "Hello"},
+		{name: "arguments", locales: []string{"fr"}, key: "welcome", args: []interface{}{"Ada"}, expected: "Bienvenue, Ada"},
+		{name: "unknown key resolves to itself", locales: []string{"fr"}, key: "missing.key", expected: "missing.key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, c.Translate(tt.locales, tt.key, tt.args...))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en.yaml"), []byte("greeting: Hello\nerrors:\n  notFound: Not found\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"greeting": "Hallo", "errors": {"notFound": "Nicht gefunden"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0644))
+
+	c, err := New(Config{
+		Dir:      dir,
+		Messages: map[string]map[string]string{"de": {"greeting": "Guten Tag"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Not found", c.Translate(nil, "errors.notFound"))
+	assert.Equal(t, "Nicht gefunden", c.Translate([]string{"de"}, "errors.notFound"))
+	assert.Equal(t, "Guten Tag", c.Translate([]string{"de"}, "greeting"), "inline messages override files")
+
+	t.Run("invalid file", func(t *testing.T) {
+		bad := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(bad, "en.json"), []byte("{"), 0644))
+		_, err := New(Config{Dir: bad})
+		assert.ErrorContains(t, err, "en.json")
+	})
+
+	t.Run("missing folder", func(t *testing.T) {
+		_, err := New(Config{Dir: filepath.Join(dir, "missing")})
+		assert.Error(t, err)
+	})
+}
+
+func TestDefault(t *testing.T) {
+	defer SetDefault(nil)
+	assert.Equal(t, "greeting", Default().Translate([]string{"fr"}, "greeting"))
+
+	c := NewCatalog("en")
+	SetDefault(c)
+	assert.Same(t, c, Default())
+}
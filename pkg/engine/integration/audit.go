@@ -0,0 +1,253 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// Audited write operations.
+const (
+	AuditStore  = "store"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+const (
+	defaultAuditCollection = "audit_log"
+	defaultAuditBufferSize = 1024
+	auditWriteTimeout      = 10 * time.Second
+)
+
+// AuditConfig enables the audit log: every successful Store, Update and Delete
+// performed by an integration is recorded into Collection of the Sink
+// integration, which must itself support Store.
+type AuditConfig struct {
+	// Sink is the id of the integration audit records are written to.
+	Sink string `json:"sink" yaml:"sink"`
+	// Collection is the table or collection in the sink. Defaults to audit_log.
+	Collection string `json:"collection" yaml:"collection"`
+	// Identity is a template resolved against the request context when the
+	// write happens, e.g. "{{ .variable_actions_auth.email }}".
+	Identity string `json:"identity" yaml:"identity"`
+	// BufferSize bounds the records queued for the sink. When it is full new
+	// records are dropped rather than slowing down the write being audited.
+	BufferSize int `json:"bufferSize" yaml:"bufferSize"`
+}
+
+// AuditRecord describes one audited write.
+type AuditRecord struct {
+	Time       time.Time
+	Operation  string
+	Collection string
+	Filters    []filters.Filter
+	Fields     map[string]interface{}
+	Identity   string
+}
+
+// toItem flattens the record for the sink. Filters and fields are stored as
+// JSON strings so sinks whose columns only take scalar values, such as SQL
+// tables, can hold them.
+func (r AuditRecord) toItem() (map[string]interface{}, error) {
+	item := map[string]interface{}{
+		"time":       r.Time.UTC().Format(time.RFC3339Nano),
+		"operation":  r.Operation,
+		"collection": r.Collection,
+		"identity":   r.Identity,
+	}
+	if len(r.Filters) > 0 {
+		b, err := json.Marshal(r.Filters)
+		if err != nil {
+			return nil, fmt.Errorf("encoding filters: %w", err)
+		}
+		item["filters"] = string(b)
+	}
+	if len(r.Fields) > 0 {
+		b, err := json.Marshal(r.Fields)
+		if err != nil {
+			return nil, fmt.Errorf("encoding fields: %w", err)
+		}
+		item["fields"] = string(b)
+	}
+	return item, nil
+}
+
+type auditSink interface {
+	Store(ctx context.Context, item map[string]interface{}, options map[string]string) error
+}
+
+// Auditor ships audit records to the sink integration from a single background
+// worker, so recording never blocks the audited operation.
+type Auditor struct {
+	cfg     AuditConfig
+	records chan AuditRecord
+	done    chan struct{}
+	closed  atomic.Bool
+	dropped atomic.Int64
+	// mu guards sending on records against Close closing it.
+	mu sync.RWMutex
+}
+
+func NewAuditor(cfg AuditConfig) (*Auditor, error) {
+	if cfg.Sink == "" {
+		return nil, errors.New("audit sink is required")
+	}
+	if cfg.Collection == "" {
+		cfg.Collection = defaultAuditCollection
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultAuditBufferSize
+	}
+	a := &Auditor{
+		cfg:     cfg,
+		records: make(chan AuditRecord, cfg.BufferSize),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+	return a, nil
+}
+
+// Record queues a write for the audit log. It never blocks: if the buffer is
+// full the record is dropped and counted.
+func (a *Auditor) Record(ctx context.Context, operation, collection string, fields map[string]interface{}, f []filters.Filter) {
+	record := AuditRecord{
+		Time:       time.Now(),
+		Operation:  operation,
+		Collection: collection,
+		Filters:    f,
+		Fields:     copyFields(fields),
+		Identity:   a.identity(ctx),
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed.Load() {
+		return
+	}
+	select {
+	case a.records <- record:
+	default:
+		a.dropped.Add(1)
+		logging.FromContext(ctx).Warn("audit buffer full, dropping record",
+			zap.String("operation", operation), zap.String("collection", collection))
+	}
+}
+
+// copyFields takes a shallow copy so a caller reusing its map after the write
+// cannot change the queued record.
+func copyFields(fields map[string]interface{}) map[string]interface{} {
+	if fields == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		c[k] = v
+	}
+	return c
+}
+
+// Dropped returns how many records were discarded because the buffer was full.
+func (a *Auditor) Dropped() int64 {
+	return a.dropped.Load()
+}
+
+func (a *Auditor) identity(ctx context.Context) string {
+	if a.cfg.Identity == "" {
+		return ""
+	}
+	rc, ok := requestctx.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	identity, err := rc.Resolve(ctx, a.cfg.Identity)
+	if err != nil {
+		logging.FromContext(ctx).Debug("could not resolve audit identity", zap.Error(err))
+		return ""
+	}
+	return identity
+}
+
+func (a *Auditor) run() {
+	defer close(a.done)
+	for record := range a.records {
+		if err := a.write(record); err != nil {
+			logging.FromContext(context.Background()).Error("failed to write audit record",
+				zap.String("operation", record.Operation), zap.String("collection", record.Collection), zap.Error(err))
+		}
+	}
+}
+
+func (a *Auditor) write(record AuditRecord) error {
+	ctx, cancel := context.WithTimeout(withoutAudit(context.Background()), auditWriteTimeout)
+	defer cancel()
+
+	i, err := GetIntegration(ctx, a.cfg.Sink)
+	if err != nil {
+		return err
+	}
+	sink, ok := i.(auditSink)
+	if !ok {
+		return fmt.Errorf("audit sink %s does not support store", a.cfg.Sink)
+	}
+	item, err := record.toItem()
+	if err != nil {
+		return err
+	}
+	return sink.Store(ctx, item, map[string]string{"collection": a.cfg.Collection})
+}
+
+// Close stops accepting records and waits for queued ones to be written, or
+// for ctx to expire.
+func (a *Auditor) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed.Swap(true) {
+		close(a.records)
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var activeAuditor atomic.Pointer[Auditor]
+
+// SetAuditor installs the process-wide auditor used by RecordWrite and returns
+// the previous one, if any. Pass nil to disable auditing.
+func SetAuditor(a *Auditor) *Auditor {
+	return activeAuditor.Swap(a)
+}
+
+type noAuditKey struct{}
+
+// withoutAudit marks ctx so writes made with it are not audited; the sink's
+// own writes would otherwise audit themselves forever.
+func withoutAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noAuditKey{}, true)
+}
+
+// RecordWrite records a completed write with the active auditor. Integrations
+// call it after every successful Store, Update and Delete; it is a no-op when
+// auditing is disabled.
+func RecordWrite(ctx context.Context, operation, collection string, fields map[string]interface{}, f ...filters.Filter) {
+	a := activeAuditor.Load()
+	if a == nil {
+		return
+	}
+	if skip, _ := ctx.Value(noAuditKey{}).(bool); skip {
+		return
+	}
+	a.Record(ctx, operation, collection, fields, f)
+}
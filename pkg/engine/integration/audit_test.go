@@ -0,0 +1,143 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditSinkIntegration stores audit records in memory. Like a real integration
+// it reports its own writes through RecordWrite, which must not loop.
+// Like a SQL table, it only accepts scalar column values.
+type auditSinkIntegration struct {
+	mu      sync.Mutex
+	items   []map[string]interface{}
+	options []map[string]string
+	block   chan struct{}
+}
+
+func (s *auditSinkIntegration) Type() string { return "audit_sink" }
+
+func (s *auditSinkIntegration) Store(ctx context.Context, item map[string]interface{}, options map[string]string) error {
+	if s.block != nil {
+		<-s.block
+	}
+	for column, value := range item {
+		switch value.(type) {
+		case string, bool, int, int64, float64, nil:
+		default:
+			return fmt.Errorf("column %s: unsupported value type %T", column, value)
+		}
+	}
+	s.mu.Lock()
+	s.items = append(s.items, item)
+	s.options = append(s.options, options)
+	s.mu.Unlock()
+	RecordWrite(ctx, AuditStore, options["collection"], item)
+	return nil
+}
+
+func (s *auditSinkIntegration) stored() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]interface{}(nil), s.items...)
+}
+
+func setupAuditSink(t *testing.T, sink *auditSinkIntegration) {
+	t.Helper()
+	ReplaceIntegrationType("audit_sink", func(config map[string]any) (Integration, error) {
+		return sink, nil
+	})
+	require.NoError(t, InitializeIntegration("audit_sink", "audit-sink", nil, false))
+}
+
+func TestAuditor(t *testing.T) {
+	t.Run("records every write type", func(t *testing.T) {
+		sink := &auditSinkIntegration{}
+		setupAuditSink(t, sink)
+
+		auditor, err := NewAuditor(AuditConfig{Sink: "audit-sink", Identity: "{{ .user }}"})
+		require.NoError(t, err)
+		SetAuditor(auditor)
+		defer SetAuditor(nil)
+
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"user": "jane@example.com"}, ""))
+
+		idFilter := filters.Filter{Field: "id", Operation: filters.Equals, Comparator: "42"}
+		RecordWrite(ctx, AuditStore, "users", map[string]interface{}{"name": "jane"})
+		RecordWrite(ctx, AuditUpdate, "users", map[string]interface{}{"name": "janet"}, idFilter)
+		RecordWrite(ctx, AuditDelete, "users", nil, idFilter)
+
+		require.NoError(t, auditor.Close(context.Background()))
+
+		items := sink.stored()
+		require.Len(t, items, 3, "sink writes must not be audited themselves")
+		for i, op := range []string{AuditStore, AuditUpdate, AuditDelete} {
+			assert.Equal(t, op, items[i]["operation"])
+			assert.Equal(t, "users", items[i]["collection"])
+			assert.Equal(t, "jane@example.com", items[i]["identity"])
+			assert.NotEmpty(t, items[i]["time"])
+			assert.Equal(t, "audit_log", sink.options[i]["collection"])
+		}
+		filterJSON, err := json.Marshal([]filters.Filter{idFilter})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name": "jane"}`, items[0]["fields"].(string))
+		assert.NotContains(t, items[0], "filters")
+		assert.JSONEq(t, `{"name": "janet"}`, items[1]["fields"].(string))
+		assert.JSONEq(t, string(filterJSON), items[1]["filters"].(string))
+		assert.NotContains(t, items[2], "fields")
+		assert.JSONEq(t, string(filterJSON), items[2]["filters"].(string))
+	})
+
+	t.Run("does not block when the buffer is full", func(t *testing.T) {
+		sink := &auditSinkIntegration{block: make(chan struct{})}
+		setupAuditSink(t, sink)
+
+		auditor, err := NewAuditor(AuditConfig{Sink: "audit-sink", Collection: "audit", BufferSize: 1})
+		require.NoError(t, err)
+		SetAuditor(auditor)
+		defer SetAuditor(nil)
+
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < 10; i++ {
+				RecordWrite(context.Background(), AuditStore, "users", map[string]interface{}{"i": i})
+			}
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("RecordWrite blocked on a slow sink")
+		}
+
+		// One record is held by the blocked worker and one is buffered.
+		assert.GreaterOrEqual(t, auditor.Dropped(), int64(8))
+
+		close(sink.block)
+		require.NoError(t, auditor.Close(context.Background()))
+		assert.Equal(t, int64(10)-auditor.Dropped(), int64(len(sink.stored())))
+		assert.Equal(t, "audit", sink.options[0]["collection"])
+	})
+
+	t.Run("disabled auditing is a no-op", func(t *testing.T) {
+		SetAuditor(nil)
+		assert.NotPanics(t, func() {
+			RecordWrite(context.Background(), AuditStore, "users", map[string]interface{}{"name": "jane"})
+		})
+	})
+
+	t.Run("requires a sink", func(t *testing.T) {
+		_, err := NewAuditor(AuditConfig{})
+		assert.Error(t, err)
+	})
+}
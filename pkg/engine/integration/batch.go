@@ -0,0 +1,194 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBatchWindow  = 100 * time.Millisecond
+	defaultBatchMaxSize = 100
+	batchFlushTimeout   = 30 * time.Second
+)
+
+var ErrBatcherClosed = errors.New("write batcher is closed")
+
+type batchStorer interface {
+	StoreMany(ctx context.Context, items []map[string]interface{}, options map[string]string) error
+}
+
+// WriteBatcher buffers inserts and writes them with StoreMany, one batch per
+// distinct set of options (i.e. per table or collection). Store returns once
+// the item is buffered; a failed batch write is logged. Batches of one
+// options set are written one at a time, in the order their items arrived.
+type WriteBatcher struct {
+	storer  batchStorer
+	window  time.Duration
+	maxSize int
+
+	mu      sync.Mutex
+	pending map[string]*pendingBatch
+	closed  bool
+
+	// flushMu is held from taking a batch until it is written, so a later
+	// batch cannot overtake an earlier one.
+	flushMu sync.Mutex
+}
+
+type pendingBatch struct {
+	options map[string]string
+	items   []map[string]interface{}
+	timer   *time.Timer
+}
+
+func NewWriteBatcher(storer batchStorer, cfg apiconfig.WriteBatchConfig) (*WriteBatcher, error) {
+	w := &WriteBatcher{
+		storer:  storer,
+		window:  defaultBatchWindow,
+		maxSize: defaultBatchMaxSize,
+		pending: make(map[string]*pendingBatch),
+	}
+	if cfg.Window != "" {
+		d, err := time.ParseDuration(cfg.Window)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid writeBatch window %q: must be a positive duration", cfg.Window)
+		}
+		w.window = d
+	}
+	if cfg.MaxSize < 0 {
+		return nil, fmt.Errorf("invalid writeBatch maxSize %d", cfg.MaxSize)
+	}
+	if cfg.MaxSize > 0 {
+		w.maxSize = cfg.MaxSize
+	}
+	return w, nil
+}
+
+// Store buffers item for the next batch written with options.
+func (w *WriteBatcher) Store(ctx context.Context, item map[string]interface{}, options map[string]string) error {
+	key, err := optionsKey(options)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrBatcherClosed
+	}
+	b, ok := w.pending[key]
+	if !ok {
+		b = &pendingBatch{options: options}
+		logger := logging.FromContext(ctx)
+		b.timer = time.AfterFunc(w.window, func() {
+			if err := w.flush(key, b); err != nil {
+				logger.Error("failed to write batch", zap.Int("items", len(b.items)), zap.Error(err))
+			}
+		})
+		w.pending[key] = b
+	}
+	b.items = append(b.items, item)
+	full := len(b.items) >= w.maxSize
+	w.mu.Unlock()
+
+	if full {
+		return w.flush(key, b)
+	}
+	return nil
+}
+
+// flush writes b unless it was already taken by another flush.
+func (w *WriteBatcher) flush(key string, b *pendingBatch) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	if w.pending[key] != b {
+		w.mu.Unlock()
+		return nil
+	}
+	delete(w.pending, key)
+	b.timer.Stop()
+	w.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), batchFlushTimeout)
+	defer cancel()
+	return w.storer.StoreMany(ctx, b.items, b.options)
+}
+
+// Close writes every pending batch and rejects further stores.
+func (w *WriteBatcher) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	batches := make(map[string]*pendingBatch, len(w.pending))
+	for key, b := range w.pending {
+		batches[key] = b
+	}
+	w.mu.Unlock()
+
+	var errs error
+	for key, b := range batches {
+		errs = errors.Join(errs, w.flush(key, b))
+	}
+	return errs
+}
+
+func optionsKey(options map[string]string) (string, error) {
+	// json.Marshal sorts map keys, so equal options give equal keys.
+	raw, err := json.Marshal(options)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+// EnableWriteBatching buffers the inserts of the initialized integration id
+// in a WriteBatcher, replacing and flushing any it had before.
+func EnableWriteBatching(id string, cfg apiconfig.WriteBatchConfig) error {
+	i, ok := integrationManager.integrations.Load(id)
+	if !ok {
+		return fmt.Errorf("integration %s not registered or lazy loaded", id)
+	}
+	storer, ok := i.(batchStorer)
+	if !ok {
+		return fmt.Errorf("integration %s does not support StoreMany", id)
+	}
+	w, err := NewWriteBatcher(storer, cfg)
+	if err != nil {
+		return err
+	}
+	if old, ok := integrationManager.batchers.Swap(id, w); ok {
+		return old.(*WriteBatcher).Close()
+	}
+	return nil
+}
+
+// GetWriteBatcher returns the batcher of integration id, if it has one.
+func GetWriteBatcher(id string) (*WriteBatcher, bool) {
+	w, ok := integrationManager.batchers.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return w.(*WriteBatcher), true
+}
+
+// closeBatcher flushes and removes the batcher of integration id.
+func (m *Manager) closeBatcher(ctx context.Context, id string) error {
+	w, ok := m.batchers.LoadAndDelete(id)
+	if !ok {
+		return nil
+	}
+	if err := w.(*WriteBatcher).Close(); err != nil {
+		logging.FromContext(ctx).Error("failed to flush write batches", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchSinkIntegration records each StoreMany call as one batch.
+type batchSinkIntegration struct {
+	mu      sync.Mutex
+	batches [][]map[string]interface{}
+}
+
+func (s *batchSinkIntegration) Type() string { return "batch_sink" }
+
+func (s *batchSinkIntegration) StoreMany(_ context.Context, items []map[string]interface{}, _ map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, items)
+	return nil
+}
+
+func (s *batchSinkIntegration) stored() ([][]map[string]interface{}, []interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var seq []interface{}
+	for _, b := range s.batches {
+		for _, item := range b {
+			seq = append(seq, item["seq"])
+		}
+	}
+	return append([][]map[string]interface{}(nil), s.batches...), seq
+}
+
+func setupBatchSink(t *testing.T, cfg apiconfig.WriteBatchConfig) (*batchSinkIntegration, *WriteBatcher) {
+	t.Helper()
+	sink := &batchSinkIntegration{}
+	ReplaceIntegrationType("batch_sink", func(map[string]any) (Integration, error) {
+		return sink, nil
+	})
+	require.NoError(t, InitializeIntegration("batch_sink", "batch-sink", nil, false))
+	require.NoError(t, EnableWriteBatching("batch-sink", cfg))
+	w, ok := GetWriteBatcher("batch-sink")
+	require.True(t, ok)
+	return sink, w
+}
+
+func TestWriteBatcher(t *testing.T) {
+	options := map[string]string{"collection": "events"}
+
+	t.Run("rapid stores are coalesced into batches in order", func(t *testing.T) {
+		sink, w := setupBatchSink(t, apiconfig.WriteBatchConfig{Window: "1h", MaxSize: 10})
+		defer w.Close()
+
+		var want []interface{}
+		for i := 0; i < 25; i++ {
+			require.NoError(t, w.Store(context.Background(), map[string]interface{}{"seq": i}, options))
+			want = append(want, i)
+		}
+		batches, _ := sink.stored()
+		assert.Len(t, batches, 2)
+
+		require.NoError(t, w.Close())
+		batches, seq := sink.stored()
+		assert.Len(t, batches, 3)
+		assert.Equal(t, want, seq)
+	})
+
+	t.Run("a batch is written after the window", func(t *testing.T) {
+		sink, w := setupBatchSink(t, apiconfig.WriteBatchConfig{Window: "10ms"})
+		defer w.Close()
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, w.Store(context.Background(), map[string]interface{}{"seq": i}, options))
+		}
+		assert.Eventually(t, func() bool {
+			batches, _ := sink.stored()
+			return len(batches) == 1 && len(batches[0]) == 5
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("shutdown flushes pending writes", func(t *testing.T) {
+		sink, w := setupBatchSink(t, apiconfig.WriteBatchConfig{Window: "1h"})
+
+		require.NoError(t, w.Store(context.Background(), map[string]interface{}{"seq": 1}, options))
+		require.NoError(t, w.Store(context.Background(), map[string]interface{}{"seq": 2}, map[string]string{"collection": "other"}))
+		batches, _ := sink.stored()
+		assert.Empty(t, batches)
+
+		require.NoError(t, GetManager().Shutdown(context.Background()))
+		batches, _ = sink.stored()
+		assert.Len(t, batches, 2)
+
+		_, ok := GetWriteBatcher("batch-sink")
+		assert.False(t, ok)
+		assert.ErrorIs(t, w.Store(context.Background(), map[string]interface{}{"seq": 3}, options), ErrBatcherClosed)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewWriteBatcher(&batchSinkIntegration{}, apiconfig.WriteBatchConfig{Window: "soon"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/cache"
+	"github.com/Servflow/servflow/pkg/engine/flags"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+)
+
+const (
+	defaultFlagCollection = "feature_flags"
+	defaultFlagCacheTTL   = 30 * time.Second
+	flagLookupTimeout     = 5 * time.Second
+)
+
+// FlagSourceConfig reads feature flags from an integration. Each flag is a
+// record in Collection with a "name" field and either a "value" in the short
+// form accepted by flags.ParseFlag, or the "enabled", "percentage" and
+// "allow" fields of flags.Flag.
+type FlagSourceConfig struct {
+	Integration string `json:"integration" yaml:"integration"`
+	// Collection is the table or collection flags live in. Defaults to
+	// feature_flags.
+	Collection string `json:"collection" yaml:"collection"`
+	// CacheTTL is how long a looked-up flag is reused from the shared cache
+	// before the integration is asked again, e.g. "1m". Defaults to 30s.
+	CacheTTL string `json:"cacheTTL" yaml:"cacheTTL"`
+}
+
+type flagFetcher interface {
+	Fetch(ctx context.Context, options map[string]string, filters ...filters.Filter) ([]map[string]interface{}, error)
+}
+
+// cachedFlag is what the flag source stores in the shared cache; Found
+// records misses too so unknown flags do not hit the integration on every
+// request.
+type cachedFlag struct {
+	Found bool       `json:"found"`
+	Flag  flags.Flag `json:"flag"`
+}
+
+type flagSource struct {
+	integrationID string
+	collection    string
+	ttl           time.Duration
+}
+
+// NewFlagSource returns a flags.Source backed by the integration in cfg.
+func NewFlagSource(cfg FlagSourceConfig) (flags.Source, error) {
+	if cfg.Integration == "" {
+		return nil, errors.New("flag source requires an integration")
+	}
+	s := &flagSource{
+		integrationID: cfg.Integration,
+		collection:    cfg.Collection,
+		ttl:           defaultFlagCacheTTL,
+	}
+	if s.collection == "" {
+		s.collection = defaultFlagCollection
+	}
+	if cfg.CacheTTL != "" {
+		ttl, err := time.ParseDuration(cfg.CacheTTL)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid flag cacheTTL %q", cfg.CacheTTL)
+		}
+		s.ttl = ttl
+	}
+	return s, nil
+}
+
+func (s *flagSource) Lookup(ctx context.Context, name string) (flags.Flag, bool, error) {
+	key := "flags:" + s.integrationID + ":" + s.collection + ":" + name
+	if raw, ok, err := cache.Default().Get(ctx, key); err == nil && ok {
+		var c cachedFlag
+		if err := json.Unmarshal(raw, &c); err == nil {
+			return c.Flag, c.Found, nil
+		}
+	}
+
+	f, found, err := s.fetch(ctx, name)
+	if err != nil {
+		return flags.Flag{}, false, err
+	}
+	if s.ttl > 0 {
+		if raw, err := json.Marshal(cachedFlag{Found: found, Flag: f}); err == nil {
+			_ = cache.Default().Set(ctx, key, raw, s.ttl)
+		}
+	}
+	return f, found, nil
+}
+
+func (s *flagSource) fetch(ctx context.Context, name string) (flags.Flag, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, flagLookupTimeout)
+	defer cancel()
+
+	i, err := GetIntegration(ctx, s.integrationID)
+	if err != nil {
+		return flags.Flag{}, false, err
+	}
+	fetcher, ok := i.(flagFetcher)
+	if !ok {
+		return flags.Flag{}, false, fmt.Errorf("flag source %s does not support fetch", s.integrationID)
+	}
+	items, err := fetcher.Fetch(ctx, map[string]string{"collection": s.collection},
+		filters.Filter{Field: "name", Operation: filters.Equals, Comparator: name})
+	if err != nil {
+		return flags.Flag{}, false, fmt.Errorf("failed to fetch flag: %w", err)
+	}
+	if len(items) == 0 {
+		return flags.Flag{}, false, nil
+	}
+	f, err := flagFromItem(items[0])
+	if err != nil {
+		return flags.Flag{}, false, err
+	}
+	return f, true, nil
+}
+
+func flagFromItem(item map[string]interface{}) (flags.Flag, error) {
+	if v, ok := item["value"]; ok {
+		return flags.ParseFlag(fmt.Sprint(v))
+	}
+	// round-trip through JSON so numeric and list fields decode the same way
+	// whichever driver produced them
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return flags.Flag{}, err
+	}
+	var f flags.Flag
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return flags.Flag{}, fmt.Errorf("invalid flag record: %w", err)
+	}
+	return f, nil
+}
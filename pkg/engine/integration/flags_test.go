@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/cache"
+	"github.com/Servflow/servflow/pkg/engine/flags"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flagStoreIntegration serves flag records by name and counts fetches.
+type flagStoreIntegration struct {
+	records map[string]map[string]interface{}
+	fetches atomic.Int32
+	options map[string]string
+}
+
+func (s *flagStoreIntegration) Type() string { return "flag_store" }
+
+func (s *flagStoreIntegration) Fetch(ctx context.Context, options map[string]string, f ...filters.Filter) ([]map[string]interface{}, error) {
+	s.fetches.Add(1)
+	s.options = options
+	if r, ok := s.records[f[0].Comparator.(string)]; ok {
+		return []map[string]interface{}{r}, nil
+	}
+	return nil, nil
+}
+
+func TestFlagSource(t *testing.T) {
+	store := &flagStoreIntegration{records: map[string]map[string]interface{}{
+		"short":    {"name": "short", "value": "true"},
+		"rollout":  {"name": "rollout", "enabled": true, "percentage": int64(25), "allow": []interface{}{"alice"}},
+		"disabled": {"name": "disabled", "enabled": false},
+	}}
+	ReplaceIntegrationType("flag_store", func(config map[string]any) (Integration, error) {
+		return store, nil
+	})
+	require.NoError(t, InitializeIntegration("flag_store", "flag-store", nil, false))
+	cache.SetDefault(cache.NewMemory())
+	defer cache.SetDefault(nil)
+
+	source, err := NewFlagSource(FlagSourceConfig{Integration: "flag-store"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("short form value", func(t *testing.T) {
+		f, ok, err := source.Lookup(ctx, "short")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, flags.Flag{Enabled: true}, f)
+		assert.Equal(t, map[string]string{"collection": "feature_flags"}, store.options)
+	})
+
+	t.Run("full record", func(t *testing.T) {
+		f, ok, err := source.Lookup(ctx, "rollout")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, f.Enabled)
+		require.NotNil(t, f.Percentage)
+		assert.Equal(t, 25.0, *f.Percentage)
+		assert.Equal(t, []string{"alice"}, f.Allow)
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		_, ok, err := source.Lookup(ctx, "missing")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("lookups are cached", func(t *testing.T) {
+		before := store.fetches.Load()
+		for i := 0; i < 3; i++ {
+			_, ok, err := source.Lookup(ctx, "disabled")
+			require.NoError(t, err)
+			assert.True(t, ok)
+			_, ok, err = source.Lookup(ctx, "missing")
+			require.NoError(t, err)
+			assert.False(t, ok)
+		}
+		assert.Equal(t, before+1, store.fetches.Load())
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewFlagSource(FlagSourceConfig{})
+		assert.Error(t, err)
+		_, err = NewFlagSource(FlagSourceConfig{Integration: "flag-store", CacheTTL: "soon"})
+		assert.Error(t, err)
+	})
+}
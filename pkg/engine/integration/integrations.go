@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"text/template"
 	"time"
 
@@ -60,6 +61,12 @@ type Manager struct {
 	integrations          sync.Map
 	availableConstructors map[string]RegistrationInfo
 	lazyIntegrations      sync.Map
+	// batchers holds the WriteBatcher of each integration with writeBatch.
+	batchers sync.Map
+	// readCaches holds the ReadCache of each integration with readCache.
+	readCaches sync.Map
+	// defaultID is the integration ResolveID falls back to.
+	defaultID atomic.Value
 }
 
 type LazyIntegration struct {
@@ -85,6 +92,14 @@ func (m *Manager) Shutdown(ctx context.Context) error {
 	logger := logging.FromContext(ctx)
 	var shutdownErr error
 
+	// flush buffered writes while their integrations are still up
+	m.batchers.Range(func(key, _ any) bool {
+		if err := m.closeBatcher(ctx, key.(string)); err != nil {
+			shutdownErr = errors.Join(shutdownErr, fmt.Errorf("integration %s: %w", key, err))
+		}
+		return true
+	})
+
 	m.integrations.Range(func(key, value any) bool {
 		id := key.(string)
 		integration := value.(Integration)
@@ -193,6 +208,8 @@ func InitializeIntegration(integrationType, id string, config map[string]any, sh
 // maps also clears stale cross-map entries when an integration switches between
 // eager and lazy loading.
 func (m *Manager) removeIntegration(id string) {
+	_ = m.closeBatcher(context.Background(), id)
+	m.readCaches.Delete(id)
 	if existing, ok := m.integrations.Load(id); ok {
 		if shutdownable, ok := existing.(Shutdownable); ok {
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -207,6 +224,22 @@ func (m *Manager) removeIntegration(id string) {
 	m.lazyIntegrations.Delete(id)
 }
 
+// SetDefaultIntegration sets the integration that datasource actions use when
+// they omit an integration id. Empty clears it.
+func SetDefaultIntegration(id string) {
+	integrationManager.defaultID.Store(id)
+}
+
+// ResolveID returns id, or the default integration when id is empty. It
+// returns "" when neither is set.
+func ResolveID(id string) string {
+	if id != "" {
+		return id
+	}
+	def, _ := integrationManager.defaultID.Load().(string)
+	return def
+}
+
 // TODO the config is being converted to and from json multiple times, fix that
 
 // GetIntegration gets an initialized registration from the list of integration
@@ -323,6 +356,26 @@ func RegisterIntegrationsFromConfig(ctx context.Context, integrationsConfig []ap
 				}
 				return
 			}
+			if dsConfig.WriteBatch != nil {
+				err := errors.New("writeBatch cannot be used with lazyLoad")
+				if !dsConfig.LazyLoad {
+					err = EnableWriteBatching(dsConfig.ID, *dsConfig.WriteBatch)
+				}
+				if err != nil {
+					errChan <- &errorReport{
+						integrationID: config.ID,
+						error:         err,
+					}
+				}
+			}
+			if dsConfig.ReadCache != nil {
+				if err := EnableReadCache(dsConfig.ID, *dsConfig.ReadCache); err != nil {
+					errChan <- &errorReport{
+						integrationID: config.ID,
+						error:         err,
+					}
+				}
+			}
 		}(&dsConfig)
 	}
 
@@ -0,0 +1,47 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrUnfilteredDelete is returned by Delete when no filters are given and the
+// caller has not confirmed the full delete with AllowFullDeleteOption.
+var ErrUnfilteredDelete = errors.New("refusing to delete every record without filters: set " + AllowFullDeleteOption + " to confirm")
+
+const (
+	// AllowFullDeleteOption must be "true" for a Delete without filters, which
+	// removes every record in the table or collection.
+	AllowFullDeleteOption = "allow_full_delete"
+	// BatchSizeOption switches Delete to chunked mode: matching records are
+	// removed at most this many at a time, each batch in its own statement, so
+	// a large delete never holds long locks.
+	BatchSizeOption = "batch_size"
+)
+
+// CheckDeleteScope returns ErrUnfilteredDelete for an unconfirmed delete
+// without filters.
+func CheckDeleteScope(options map[string]string, filters []Filter) error {
+	if len(filters) > 0 {
+		return nil
+	}
+	if allow, _ := strconv.ParseBool(options[AllowFullDeleteOption]); allow {
+		return nil
+	}
+	return ErrUnfilteredDelete
+}
+
+// ParseBatchSize returns the BatchSizeOption value, or 0 when Delete should
+// remove all matches in one statement.
+func ParseBatchSize(options map[string]string) (int, error) {
+	v, ok := options[BatchSizeOption]
+	if !ok || v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive integer", BatchSizeOption, v)
+	}
+	return n, nil
+}
@@ -3,6 +3,9 @@ package filters
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -11,6 +14,46 @@ import (
 // Callers can use errors.Is(err, ErrNoMatch) to check for this condition.
 var ErrNoMatch = errors.New("no documents matched the filter")
 
+// ErrVersionConflict is returned by an optimistic-locking Update (see
+// VersionOption) when the record exists but its version no longer matches the
+// one the caller read: it was modified concurrently and should be re-read.
+var ErrVersionConflict = errors.New("version conflict: record was modified concurrently")
+
+const (
+	// VersionOption is the Update option holding the version the caller last
+	// read. When present the update only applies while VersionField still
+	// holds that value, and increments VersionField in the same write.
+	VersionOption = "version"
+	// VersionField is the column or document field used for optimistic locking.
+	VersionField = "version"
+)
+
+// ParseVersion parses the value of VersionOption.
+func ParseVersion(s string) (int64, error) {
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: must be an integer", VersionOption, s)
+	}
+	return v, nil
+}
+
+// UpdatesVersion reports whether update fields write VersionField, either
+// directly or through an operator such as $inc. Under optimistic locking the
+// version is managed by the integration and must not be set by the caller.
+func UpdatesVersion(fields map[string]interface{}) bool {
+	for k, v := range fields {
+		if k == VersionField {
+			return true
+		}
+		if operand, ok := v.(map[string]interface{}); ok && strings.HasPrefix(k, "$") {
+			if _, ok := operand[VersionField]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type Filter struct {
 	Field      string      `json:"field"`
 	Operation  string      `json:"operation"`
@@ -24,9 +67,35 @@ const (
 	LessThan           = "<"
 	LessThanEqual      = "<="
 	GreaterThanOrEqual = ">="
-	Like               = "like"
+	// Like and ILike match a pattern where % stands for any run of characters
+	// and _ for a single one; \% and \_ match them literally. ILike ignores
+	// case. SQL integrations emit LIKE/ILIKE (ILIKE is PostgreSQL syntax);
+	// Mongo matches with an anchored $regex.
+	Like  = "like"
+	ILike = "ilike"
+	// ElemMatch matches documents whose array field has at least one element
+	// satisfying every sub-condition in Comparator. See elemMatchCondition.
+	ElemMatch = "elemMatch"
+	// In and NotIn match a field against the set of values in Comparator,
+	// a []interface{}. An empty set is allowed: In then matches nothing and
+	// NotIn matches everything.
+	In    = "in"
+	NotIn = "notIn"
+	// Between matches values in the inclusive range given by Comparator, a
+	// two-element list [low, high].
+	Between = "between"
 )
 
+// comparisonOperators maps filter operations to their Mongo query operators.
+var comparisonOperators = map[string]string{
+	Equals:             "$eq",
+	NotEquals:          "$ne",
+	GreaterThan:        "$gt",
+	LessThan:           "$lt",
+	GreaterThanOrEqual: "$gte",
+	LessThanEqual:      "$lte",
+}
+
 func (f *Filter) ToBsonE() (bson.E, error) {
 	switch f.Operation {
 	case Equals:
@@ -41,23 +110,272 @@ func (f *Filter) ToBsonE() (bson.E, error) {
 		return bson.E{Key: f.Field, Value: bson.D{{"$gte", f.Comparator}}}, nil
 	case LessThanEqual:
 		return bson.E{Key: f.Field, Value: bson.D{{"$lte", f.Comparator}}}, nil
+	case Like, ILike:
+		pattern, err := likePattern(f.Comparator)
+		if err != nil {
+			return bson.E{}, fmt.Errorf("invalid %s filter on %s: %w", f.Operation, f.Field, err)
+		}
+		regex := bson.D{{"$regex", likeToRegex(pattern)}}
+		if f.Operation == ILike {
+			regex = append(regex, bson.E{Key: "$options", Value: "i"})
+		}
+		return bson.E{Key: f.Field, Value: regex}, nil
+	case In, NotIn:
+		set, err := setValues(f.Comparator)
+		if err != nil {
+			return bson.E{}, fmt.Errorf("invalid %s filter on %s: %w", f.Operation, f.Field, err)
+		}
+		op := "$in"
+		if f.Operation == NotIn {
+			op = "$nin"
+		}
+		return bson.E{Key: f.Field, Value: bson.D{{op, set}}}, nil
+	case Between:
+		low, high, err := rangeValues(f.Comparator)
+		if err != nil {
+			return bson.E{}, fmt.Errorf("invalid %s filter on %s: %w", f.Operation, f.Field, err)
+		}
+		return bson.E{Key: f.Field, Value: bson.D{{"$gte", low}, {"$lte", high}}}, nil
+	case ElemMatch:
+		cond, err := elemMatchCondition(f.Comparator)
+		if err != nil {
+			return bson.E{}, fmt.Errorf("invalid %s filter on %s: %w", ElemMatch, f.Field, err)
+		}
+		return bson.E{Key: f.Field, Value: bson.D{{"$elemMatch", cond}}}, nil
 	default:
 		return bson.E{}, fmt.Errorf("invalid operation: %s", f.Operation)
 	}
 }
 
+// elemMatchCondition builds the $elemMatch document for an ElemMatch filter.
+// The comparator is either a scalar, matching an element equal to it, or a
+// list of sub-filters (a single filter may be given without the list). For
+// arrays of documents the sub-filters name fields of the element:
+//
+//	{"field": "items", "operation": "elemMatch", "comparator": [
+//	  {"field": "sku", "operation": "==", "comparator": "A1"},
+//	  {"field": "qty", "operation": ">", "comparator": 5}]}
+//
+// For arrays of scalars the sub-filters leave field empty and constrain the
+// element itself, e.g. scores with an element between 80 and 90:
+//
+//	[{"operation": ">=", "comparator": 80}, {"operation": "<", "comparator": 90}]
+//
+// Both forms cannot be mixed in one filter.
+func elemMatchCondition(comparator interface{}) (bson.D, error) {
+	var subs []Filter
+	switch c := comparator.(type) {
+	case nil:
+		return nil, errors.New("comparator is required")
+	case Filter:
+		subs = []Filter{c}
+	case []Filter:
+		subs = c
+	case map[string]interface{}:
+		sub, err := decodeFilter(c)
+		if err != nil {
+			return nil, err
+		}
+		subs = []Filter{sub}
+	case []interface{}:
+		for _, item := range c {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("sub-filters must be objects, got %T", item)
+			}
+			sub, err := decodeFilter(m)
+			if err != nil {
+				return nil, err
+			}
+			subs = append(subs, sub)
+		}
+	default:
+		return bson.D{{"$eq", c}}, nil
+	}
+	if len(subs) == 0 {
+		return nil, errors.New("at least one sub-filter is required")
+	}
+
+	cond := make(bson.D, 0, len(subs))
+	onElement := subs[0].Field == ""
+	for _, sub := range subs {
+		if (sub.Field == "") != onElement {
+			return nil, errors.New("sub-filters must either all name a field or all omit it")
+		}
+		if onElement {
+			op, ok := comparisonOperators[sub.Operation]
+			if !ok {
+				return nil, fmt.Errorf("invalid operation on array element: %s", sub.Operation)
+			}
+			cond = append(cond, bson.E{Key: op, Value: sub.Comparator})
+			continue
+		}
+		e, err := sub.ToBsonE()
+		if err != nil {
+			return nil, err
+		}
+		cond = append(cond, e)
+	}
+	return cond, nil
+}
+
+// decodeFilter converts a filter decoded from JSON config into a Filter.
+func decodeFilter(m map[string]interface{}) (Filter, error) {
+	var f Filter
+	if field, ok := m["field"]; ok {
+		if f.Field, ok = field.(string); !ok {
+			return Filter{}, fmt.Errorf("sub-filter field must be a string, got %T", field)
+		}
+	}
+	op, ok := m["operation"].(string)
+	if !ok {
+		return Filter{}, errors.New("sub-filter operation is required")
+	}
+	f.Operation = op
+	f.Comparator = m["comparator"]
+	return f, nil
+}
+
+// ToSQLComp returns the SQL condition for the filter. Every filter but In and
+// NotIn binds its Comparator to a single placeholder; use ToSQL for the values
+// to bind in all cases.
 func (f *Filter) ToSQLComp() (string, error) {
+	comp, _, err := f.ToSQL()
+	return comp, err
+}
+
+// ToSQL returns the SQL condition for the filter along with the values bound
+// to its placeholders, in order, in PostgreSQL syntax; see ToSQLFor. In and
+// NotIn expand to one placeholder per element; an empty set becomes a
+// constant condition with nothing bound. Between binds its low and high
+// bounds.
+func (f *Filter) ToSQL() (string, []interface{}, error) {
+	return f.ToSQLFor("postgres")
+}
+
+// ToSQLFor is ToSQL for the given SQL driver, e.g. "postgres" or "mysql".
+// Only PostgreSQL has ILIKE: for other drivers ILike compares both sides
+// lower-cased with LIKE.
+func (f *Filter) ToSQLFor(driver string) (string, []interface{}, error) {
 	var op = f.Operation
 	switch f.Operation {
 	case Equals:
 		op = "="
-	case NotEquals, GreaterThan, LessThan, GreaterThanOrEqual, LessThanEqual, Like:
+	case NotEquals, GreaterThan, LessThan, GreaterThanOrEqual, LessThanEqual:
 		op = f.Operation
+	case Like, ILike:
+		if _, err := likePattern(f.Comparator); err != nil {
+			return "", nil, fmt.Errorf("invalid %s filter on %s: %w", f.Operation, f.Field, err)
+		}
+		if f.Operation == ILike && driver != "postgres" {
+			return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", f.Field), []interface{}{f.Comparator}, nil
+		}
+		op = strings.ToUpper(f.Operation)
+	case In, NotIn:
+		set, err := setValues(f.Comparator)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid %s filter on %s: %w", f.Operation, f.Field, err)
+		}
+		if len(set) == 0 {
+			if f.Operation == In {
+				return "1 = 0", []interface{}{}, nil
+			}
+			return "1 = 1", []interface{}{}, nil
+		}
+		op = "IN"
+		if f.Operation == NotIn {
+			op = "NOT IN"
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(set)), ", ")
+		return fmt.Sprintf("%s %s (%s)", f.Field, op, placeholders), set, nil
+	case Between:
+		low, high, err := rangeValues(f.Comparator)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid %s filter on %s: %w", f.Operation, f.Field, err)
+		}
+		return fmt.Sprintf("%s BETWEEN ? AND ?", f.Field), []interface{}{low, high}, nil
+	default:
+		return "", nil, fmt.Errorf("invalid operation: %s", f.Operation)
+	}
+
+	return fmt.Sprintf("%s %s ?", f.Field, op), []interface{}{f.Comparator}, nil
+}
+
+// setValues validates the comparator of an In or NotIn filter.
+func setValues(comparator interface{}) ([]interface{}, error) {
+	switch c := comparator.(type) {
+	case []interface{}:
+		if c == nil {
+			return []interface{}{}, nil
+		}
+		return c, nil
+	case []string:
+		set := make([]interface{}, len(c))
+		for i, v := range c {
+			set[i] = v
+		}
+		return set, nil
 	default:
-		return "", fmt.Errorf("invalid operation: %s", f.Operation)
+		return nil, fmt.Errorf("comparator must be a list of values, got %T", comparator)
 	}
+}
 
-	return fmt.Sprintf("%s %s ?", f.Field, op), nil
+// rangeValues validates the comparator of a Between filter.
+func rangeValues(comparator interface{}) (low, high interface{}, err error) {
+	bounds, err := setValues(comparator)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(bounds) != 2 {
+		return nil, nil, fmt.Errorf("comparator must hold exactly two values [low, high], got %d", len(bounds))
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// likePattern validates the comparator of a Like or ILike filter.
+func likePattern(comparator interface{}) (string, error) {
+	pattern, ok := comparator.(string)
+	if !ok {
+		return "", fmt.Errorf("pattern must be a string, got %T", comparator)
+	}
+	if pattern == "" {
+		return "", errors.New("pattern must not be empty")
+	}
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '\\' {
+			if i == len(pattern)-1 {
+				return "", errors.New("pattern must not end with an unfinished escape")
+			}
+			i++
+		}
+	}
+	return pattern, nil
+}
+
+// likeToRegex converts a LIKE pattern into an anchored regular expression,
+// quoting everything but the wildcards. It works on runes, so a wildcard or
+// escape never splits a multi-byte character.
+func likeToRegex(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
 }
 
 // FiltersToBSON converts an array of Filter structs to a BSON document.
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -38,6 +39,124 @@ func TestFilterToBsonE(t *testing.T) {
 			filter:  Filter{Field: "test", Operation: "invalid", Comparator: "test"},
 			wantErr: true,
 		},
+		{
+			name:     "elemMatch scalar",
+			filter:   Filter{Field: "tags", Operation: ElemMatch, Comparator: "go"},
+			expected: bson.E{Key: "tags", Value: bson.D{{"$elemMatch", bson.D{{"$eq", "go"}}}}},
+		},
+		{
+			name: "elemMatch on element fields",
+			filter: Filter{Field: "items", Operation: ElemMatch, Comparator: []interface{}{
+				map[string]interface{}{"field": "sku", "operation": "==", "comparator": "A1"},
+				map[string]interface{}{"field": "qty", "operation": ">", "comparator": 5.0},
+			}},
+			expected: bson.E{Key: "items", Value: bson.D{{"$elemMatch", bson.D{
+				{"sku", "A1"},
+				{"qty", bson.D{{"$gt", 5.0}}},
+			}}}},
+		},
+		{
+			name: "elemMatch on scalar elements",
+			filter: Filter{Field: "scores", Operation: ElemMatch, Comparator: []Filter{
+				{Operation: GreaterThanOrEqual, Comparator: 80},
+				{Operation: LessThan, Comparator: 90},
+			}},
+			expected: bson.E{Key: "scores", Value: bson.D{{"$elemMatch", bson.D{{"$gte", 80}, {"$lt", 90}}}}},
+		},
+		{
+			name: "elemMatch single sub-filter object",
+			filter: Filter{Field: "items", Operation: ElemMatch, Comparator: map[string]interface{}{
+				"field": "sku", "operation": "!=", "comparator": "B2",
+			}},
+			expected: bson.E{Key: "items", Value: bson.D{{"$elemMatch", bson.D{{"sku", bson.D{{"$ne", "B2"}}}}}}},
+		},
+		{
+			name: "elemMatch mixing element and field sub-filters",
+			filter: Filter{Field: "items", Operation: ElemMatch, Comparator: []Filter{
+				{Operation: GreaterThan, Comparator: 1},
+				{Field: "qty", Operation: GreaterThan, Comparator: 1},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "elemMatch without comparator",
+			filter:  Filter{Field: "items", Operation: ElemMatch},
+			wantErr: true,
+		},
+		{
+			name: "elemMatch with invalid sub-filter",
+			filter: Filter{Field: "items", Operation: ElemMatch, Comparator: []interface{}{
+				map[string]interface{}{"field": "sku"},
+			}},
+			wantErr: true,
+		},
+		{
+			name:     "like becomes an anchored regex",
+			filter:   Filter{Field: "name", Operation: Like, Comparator: "Ada%"},
+			expected: bson.E{Key: "name", Value: bson.D{{"$regex", "^Ada.*$"}}},
+		},
+		{
+			name:     "ilike is case-insensitive",
+			filter:   Filter{Field: "email", Operation: ILike, Comparator: "%@example.com"},
+			expected: bson.E{Key: "email", Value: bson.D{{"$regex", `^.*@example\.com$`}, {"$options", "i"}}},
+		},
+		{
+			name:     "like escapes and single-character wildcard",
+			filter:   Filter{Field: "code", Operation: Like, Comparator: `a_\%(b)`},
+			expected: bson.E{Key: "code", Value: bson.D{{"$regex", `^a.%\(b\)$`}}},
+		},
+		{
+			name:     "like keeps multi-byte characters whole",
+			filter:   Filter{Field: "city", Operation: Like, Comparator: `Zür_ch%\é`},
+			expected: bson.E{Key: "city", Value: bson.D{{"$regex", "^Zür.ch.*é$"}}},
+		},
+		{
+			name:     "ilike matches one rune per wildcard",
+			filter:   Filter{Field: "name", Operation: ILike, Comparator: "_%東京"},
+			expected: bson.E{Key: "name", Value: bson.D{{"$regex", "^..*東京$"}, {"$options", "i"}}},
+		},
+		{
+			name:    "like with non-string pattern",
+			filter:  Filter{Field: "name", Operation: Like, Comparator: 42},
+			wantErr: true,
+		},
+		{
+			name:    "like with unfinished escape",
+			filter:  Filter{Field: "name", Operation: ILike, Comparator: `ada\`},
+			wantErr: true,
+		},
+		{
+			name:     "in",
+			filter:   Filter{Field: "status", Operation: In, Comparator: []interface{}{"active", "pending"}},
+			expected: bson.E{Key: "status", Value: bson.D{{"$in", []interface{}{"active", "pending"}}}},
+		},
+		{
+			name:     "not in",
+			filter:   Filter{Field: "status", Operation: NotIn, Comparator: []interface{}{"banned"}},
+			expected: bson.E{Key: "status", Value: bson.D{{"$nin", []interface{}{"banned"}}}},
+		},
+		{
+			// Mongo already treats an empty $in as matching nothing and an
+			// empty $nin as matching everything.
+			name:     "empty in",
+			filter:   Filter{Field: "status", Operation: In, Comparator: []interface{}{}},
+			expected: bson.E{Key: "status", Value: bson.D{{"$in", []interface{}{}}}},
+		},
+		{
+			name:    "in with scalar comparator",
+			filter:  Filter{Field: "status", Operation: In, Comparator: "active"},
+			wantErr: true,
+		},
+		{
+			name:     "between",
+			filter:   Filter{Field: "age", Operation: Between, Comparator: []interface{}{18, 65}},
+			expected: bson.E{Key: "age", Value: bson.D{{"$gte", 18}, {"$lte", 65}}},
+		},
+		{
+			name:    "between with one bound",
+			filter:  Filter{Field: "age", Operation: Between, Comparator: []interface{}{18}},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -77,6 +196,21 @@ func TestFilterToSQLComp(t *testing.T) {
 			filter:  Filter{Field: "test", Operation: "invalid", Comparator: "test"},
 			wantErr: true,
 		},
+		{
+			name:     "like operator",
+			filter:   Filter{Field: "name", Operation: Like, Comparator: "Ada%"},
+			expected: "name LIKE ?",
+		},
+		{
+			name:     "ilike operator",
+			filter:   Filter{Field: "name", Operation: ILike, Comparator: "%ada%"},
+			expected: "name ILIKE ?",
+		},
+		{
+			name:    "like with empty pattern",
+			filter:  Filter{Field: "name", Operation: Like, Comparator: ""},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -92,6 +226,100 @@ func TestFilterToSQLComp(t *testing.T) {
 	}
 }
 
+func TestFilterToSQLFor_ILike(t *testing.T) {
+	f := Filter{Field: "email", Operation: ILike, Comparator: "%@Example.com"}
+
+	comp, args, err := f.ToSQLFor("postgres")
+	require.NoError(t, err)
+	assert.Equal(t, "email ILIKE ?", comp)
+	assert.Equal(t, []interface{}{"%@Example.com"}, args)
+
+	comp, args, err = f.ToSQLFor("mysql")
+	require.NoError(t, err)
+	assert.Equal(t, "LOWER(email) LIKE LOWER(?)", comp)
+	assert.Equal(t, []interface{}{"%@Example.com"}, args)
+
+	like := Filter{Field: "name", Operation: Like, Comparator: "Ada%"}
+	comp, _, err = like.ToSQLFor("mysql")
+	require.NoError(t, err)
+	assert.Equal(t, "name LIKE ?", comp)
+}
+
+func TestFilterToSQL_Sets(t *testing.T) {
+	tests := []struct {
+		name         string
+		filter       Filter
+		expected     string
+		expectedArgs []interface{}
+		wantErr      bool
+	}{
+		{
+			name:         "in binds one value per element",
+			filter:       Filter{Field: "status", Operation: In, Comparator: []interface{}{"active", "pending", "trial"}},
+			expected:     "status IN (?, ?, ?)",
+			expectedArgs: []interface{}{"active", "pending", "trial"},
+		},
+		{
+			name:         "not in",
+			filter:       Filter{Field: "id", Operation: NotIn, Comparator: []interface{}{1, 2}},
+			expected:     "id NOT IN (?, ?)",
+			expectedArgs: []interface{}{1, 2},
+		},
+		{
+			name:         "empty in matches nothing",
+			filter:       Filter{Field: "status", Operation: In, Comparator: []interface{}{}},
+			expected:     "1 = 0",
+			expectedArgs: []interface{}{},
+		},
+		{
+			name:         "empty not in matches everything",
+			filter:       Filter{Field: "status", Operation: NotIn, Comparator: []interface{}{}},
+			expected:     "1 = 1",
+			expectedArgs: []interface{}{},
+		},
+		{
+			name:         "scalar operations bind the comparator",
+			filter:       Filter{Field: "age", Operation: GreaterThan, Comparator: 18},
+			expected:     "age > ?",
+			expectedArgs: []interface{}{18},
+		},
+		{
+			name:    "in with scalar comparator",
+			filter:  Filter{Field: "status", Operation: NotIn, Comparator: "active"},
+			wantErr: true,
+		},
+		{
+			name:         "between binds both bounds",
+			filter:       Filter{Field: "created", Operation: Between, Comparator: []string{"2024-01-01", "2024-12-31"}},
+			expected:     "created BETWEEN ? AND ?",
+			expectedArgs: []interface{}{"2024-01-01", "2024-12-31"},
+		},
+		{
+			name:    "between with three values",
+			filter:  Filter{Field: "age", Operation: Between, Comparator: []interface{}{1, 2, 3}},
+			wantErr: true,
+		},
+		{
+			name:    "between with scalar comparator",
+			filter:  Filter{Field: "age", Operation: Between, Comparator: 18},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, args, err := tt.filter.ToSQL()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
+
 func TestFiltersToBSON(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -138,3 +366,97 @@ func TestFiltersToBSON(t *testing.T) {
 		})
 	}
 }
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion(" 7 ")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), v)
+
+	_, err = ParseVersion("seven")
+	assert.Error(t, err)
+}
+
+func TestUpdatesVersion(t *testing.T) {
+	assert.True(t, UpdatesVersion(map[string]interface{}{"version": 2}))
+	assert.True(t, UpdatesVersion(map[string]interface{}{"$inc": map[string]interface{}{"version": 1}}))
+	assert.False(t, UpdatesVersion(map[string]interface{}{"name": "x", "$inc": map[string]interface{}{"logins": 1}}))
+	assert.False(t, UpdatesVersion(nil))
+}
+
+func TestCheckDeleteScope(t *testing.T) {
+	byID := []Filter{{Field: "id", Operation: Equals, Comparator: 1}}
+	assert.NoError(t, CheckDeleteScope(nil, byID))
+	assert.ErrorIs(t, CheckDeleteScope(nil, nil), ErrUnfilteredDelete)
+	assert.ErrorIs(t, CheckDeleteScope(map[string]string{AllowFullDeleteOption: "false"}, nil), ErrUnfilteredDelete)
+	assert.NoError(t, CheckDeleteScope(map[string]string{AllowFullDeleteOption: "true"}, nil))
+}
+
+func TestParseBatchSize(t *testing.T) {
+	n, err := ParseBatchSize(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = ParseBatchSize(map[string]string{BatchSizeOption: "100"})
+	assert.NoError(t, err)
+	assert.Equal(t, 100, n)
+
+	for _, v := range []string{"0", "-1", "ten"} {
+		_, err := ParseBatchSize(map[string]string{BatchSizeOption: v})
+		assert.Error(t, err, v)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	sort, err := ParseSort(map[string]string{})
+	assert.NoError(t, err)
+	assert.Nil(t, sort)
+
+	sort, err = ParseSort(map[string]string{SortOption: "created_at:desc, name:ASC,id"})
+	assert.NoError(t, err)
+	assert.Equal(t, []SortField{{Field: "created_at", Desc: true}, {Field: "name"}, {Field: "id"}}, sort)
+
+	_, err = ParseSort(map[string]string{SortOption: "name:sideways"})
+	assert.ErrorContains(t, err, "must be asc or desc")
+
+	_, err = ParseSort(map[string]string{SortOption: "name,,id"})
+	assert.ErrorContains(t, err, "empty field")
+}
+
+func TestParsePage(t *testing.T) {
+	page, err := ParsePage(map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, Page{}, page)
+
+	page, err = ParsePage(map[string]string{LimitOption: "20", OffsetOption: "40"})
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Limit: 20, Offset: 40}, page)
+
+	for _, opts := range []map[string]string{
+		{LimitOption: "-1"},
+		{LimitOption: "0"},
+		{LimitOption: "ten"},
+		{OffsetOption: "-5"},
+		{OffsetOption: "1.5"},
+	} {
+		_, err := ParsePage(opts)
+		assert.Error(t, err, opts)
+	}
+}
+
+func TestParseUpsertOn(t *testing.T) {
+	item := map[string]interface{}{"tenant_id": 1, "email": "a@b.com", "name": "A"}
+
+	keys, err := ParseUpsertOn(map[string]string{}, item)
+	assert.NoError(t, err)
+	assert.Nil(t, keys)
+
+	keys, err = ParseUpsertOn(map[string]string{UpsertOnOption: "tenant_id, email"}, item)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tenant_id", "email"}, keys)
+
+	_, err = ParseUpsertOn(map[string]string{UpsertOnOption: "email,"}, item)
+	assert.ErrorContains(t, err, "empty key")
+
+	_, err = ParseUpsertOn(map[string]string{UpsertOnOption: "id"}, item)
+	assert.ErrorContains(t, err, "item has no id")
+}
@@ -0,0 +1,42 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// LimitOption caps the number of records a Fetch returns.
+	LimitOption = "limit"
+	// OffsetOption skips that many matching records before the first one
+	// returned. Combine it with SortOption for stable pages.
+	OffsetOption = "offset"
+)
+
+// Page is the window of results requested by LimitOption and OffsetOption.
+// A zero Limit returns every record after Offset.
+type Page struct {
+	Limit  int64
+	Offset int64
+}
+
+// ParsePage returns the page requested in options.
+func ParsePage(options map[string]string) (Page, error) {
+	var p Page
+	if v := strings.TrimSpace(options[LimitOption]); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return Page{}, fmt.Errorf("invalid %s %q: must be a positive integer", LimitOption, v)
+		}
+		p.Limit = n
+	}
+	if v := strings.TrimSpace(options[OffsetOption]); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return Page{}, fmt.Errorf("invalid %s %q: must be a non-negative integer", OffsetOption, v)
+		}
+		p.Offset = n
+	}
+	return p, nil
+}
@@ -0,0 +1,46 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortOption orders the results of a Fetch. Its value is a comma-separated
+// list of field:direction pairs, e.g. "created_at:desc,name:asc". The
+// direction is asc or desc and defaults to asc when omitted.
+const SortOption = "sort"
+
+// SortField is one key of a Fetch ordering.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort returns the ordering requested by SortOption, or nil when results
+// may come back in any order. Integrations validate the field names
+// themselves.
+func ParseSort(options map[string]string) ([]SortField, error) {
+	v := strings.TrimSpace(options[SortOption])
+	if v == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	for _, part := range strings.Split(v, ",") {
+		field, dir, _ := strings.Cut(strings.TrimSpace(part), ":")
+		field = strings.TrimSpace(field)
+		if field == "" {
+			return nil, fmt.Errorf("invalid %s %q: empty field", SortOption, v)
+		}
+		sf := SortField{Field: field}
+		switch strings.ToLower(strings.TrimSpace(dir)) {
+		case "", "asc":
+		case "desc":
+			sf.Desc = true
+		default:
+			return nil, fmt.Errorf("invalid %s direction %q for %s: must be asc or desc", SortOption, dir, field)
+		}
+		fields = append(fields, sf)
+	}
+	return fields, nil
+}
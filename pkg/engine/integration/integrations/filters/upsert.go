@@ -0,0 +1,35 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UpsertOnOption turns a Store into an upsert. Its value is a comma-separated
+// list of the keys that identify a record, e.g. "email" or "tenant_id,email".
+// When a record matches the item on all of them it is updated with the item's
+// other fields instead of a new record being inserted. SQL tables need a
+// unique constraint over exactly these columns.
+const UpsertOnOption = "upsertOn"
+
+// ParseUpsertOn returns the keys requested by UpsertOnOption, or nil for a
+// plain insert. Every key must be present in item.
+func ParseUpsertOn(options map[string]string, item map[string]interface{}) ([]string, error) {
+	v := strings.TrimSpace(options[UpsertOnOption])
+	if v == "" {
+		return nil, nil
+	}
+
+	var keys []string
+	for _, part := range strings.Split(v, ",") {
+		key := strings.TrimSpace(part)
+		if key == "" {
+			return nil, fmt.Errorf("invalid %s %q: empty key", UpsertOnOption, v)
+		}
+		if _, ok := item[key]; !ok {
+			return nil, fmt.Errorf("invalid %s: item has no %s", UpsertOnOption, key)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,73 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// writeStages are the aggregation stages that write their output. Aggregate
+// runs on the read database, possibly a secondary, so they are rejected.
+var writeStages = map[string]bool{"$out": true, "$merge": true}
+
+// Aggregate runs a JSON aggregation pipeline, e.g.
+// [{"$match": {"status": "paid"}}, {"$group": {"_id": "$user", "n": {"$sum": 1}}}],
+// against collection and returns the resulting documents with their _id
+// normalized as by Fetch.
+func (m *Mongo) Aggregate(ctx context.Context, collection string, pipeline string) ([]map[string]interface{}, error) {
+	if err := m.ensureConnected(ctx); err != nil {
+		return nil, fmt.Errorf("connection error: %w", err)
+	}
+	if collection == "" {
+		return nil, fmt.Errorf("invalid collection")
+	}
+
+	stages, err := parsePipeline(pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	defer m.observe(ctx, "aggregate", collection, stages, time.Now())
+	cur, err := m.readDB().Collection(collection).Aggregate(ctx, stages)
+	if err != nil {
+		return nil, fmt.Errorf("error running aggregation: %w", err)
+	}
+
+	var docs []bson.M
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("error reading aggregation results: %w", err)
+	}
+
+	results := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		if err := normalizeID(doc, ""); err != nil {
+			return nil, err
+		}
+		results[i] = doc
+	}
+	return results, nil
+}
+
+// parsePipeline decodes an extended JSON array of stages. Each stage must be a
+// document with exactly one stage operator.
+func parsePipeline(pipeline string) (mongo.Pipeline, error) {
+	var stages mongo.Pipeline
+	if err := bson.UnmarshalExtJSON([]byte(pipeline), false, &stages); err != nil {
+		return nil, fmt.Errorf("error processing pipeline: %v", err)
+	}
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("pipeline must have at least one stage")
+	}
+	for i, stage := range stages {
+		if len(stage) != 1 {
+			return nil, fmt.Errorf("pipeline stage %d must have exactly one operator, got %d", i, len(stage))
+		}
+		if writeStages[stage[0].Key] {
+			return nil, fmt.Errorf("pipeline stage %s is not allowed", stage[0].Key)
+		}
+	}
+	return stages, nil
+}
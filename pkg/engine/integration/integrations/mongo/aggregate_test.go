@@ -0,0 +1,79 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParsePipeline(t *testing.T) {
+	stages, err := parsePipeline(`[{"$match": {"age": {"$gte": 30}}}, {"$project": {"name": 1, "_id": 0}}]`)
+	require.NoError(t, err)
+	require.Len(t, stages, 2)
+	assert.Equal(t, "$match", stages[0][0].Key)
+	assert.Equal(t, bson.D{{Key: "age", Value: bson.D{{Key: "$gte", Value: int32(30)}}}}, stages[0][0].Value)
+	assert.Equal(t, "$project", stages[1][0].Key)
+
+	for name, tc := range map[string]struct {
+		pipeline string
+		wantErr  string
+	}{
+		"not json":       {pipeline: `[{"$match": `, wantErr: "error processing pipeline"},
+		"not an array":   {pipeline: `{"$match": {}}`, wantErr: "error processing pipeline"},
+		"empty":          {pipeline: `[]`, wantErr: "at least one stage"},
+		"two operators":  {pipeline: `[{"$match": {}, "$limit": 1}]`, wantErr: "exactly one operator"},
+		"writes results": {pipeline: `[{"$match": {}}, {"$out": "copy"}]`, wantErr: "$out is not allowed"},
+		"merges results": {pipeline: `[{"$merge": {"into": "copy"}}]`, wantErr: "$merge is not allowed"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parsePipeline(tc.pipeline)
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestMongo_Aggregate(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+	require.NoError(t, err)
+
+	for _, doc := range []map[string]interface{}{
+		{"name": "john", "team": "red", "age": int32(30)},
+		{"name": "jane", "team": "blue", "age": int32(25)},
+		{"name": "bob", "team": "red", "age": int32(35)},
+	} {
+		_, cleanup := writeDataAndReturnCleanupFn(mng.client, "servflow", "users", doc)
+		t.Cleanup(cleanup)
+	}
+
+	t.Run("group count", func(t *testing.T) {
+		results, err := mng.Aggregate(context.Background(), "users",
+			`[{"$group": {"_id": "$team", "count": {"$sum": 1}}}, {"$sort": {"_id": 1}}]`)
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]interface{}{
+			{"_id": "blue", "count": int32(1)},
+			{"_id": "red", "count": int32(2)},
+		}, results)
+	})
+
+	t.Run("match and project", func(t *testing.T) {
+		results, err := mng.Aggregate(context.Background(), "users",
+			`[{"$match": {"age": {"$gte": 30}}}, {"$project": {"name": 1}}, {"$sort": {"name": 1}}]`)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for i, name := range []string{"bob", "john"} {
+			assert.Equal(t, name, results[i]["name"])
+			assert.IsType(t, "", results[i]["_id"], "ObjectIDs are returned as hex strings")
+			assert.NotContains(t, results[i], "age")
+		}
+	})
+
+	t.Run("invalid pipeline", func(t *testing.T) {
+		_, err := mng.Aggregate(context.Background(), "users", `[{"$out": "copy"}]`)
+		assert.ErrorContains(t, err, "$out is not allowed")
+	})
+}
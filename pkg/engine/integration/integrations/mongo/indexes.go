@@ -0,0 +1,131 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IndexSpec declares an index the integration creates on startup. Keys are a
+// list rather than a map so compound indexes keep their field order.
+type IndexSpec struct {
+	Collection string     `json:"collection"`
+	Keys       []IndexKey `json:"keys"`
+	Name       string     `json:"name,omitempty"`
+	Unique     bool       `json:"unique,omitempty"`
+	Sparse     bool       `json:"sparse,omitempty"`
+}
+
+// IndexKey is one field of an index. Type is asc (the default), desc, text or
+// 2dsphere.
+type IndexKey struct {
+	Field string `json:"field"`
+	Type  string `json:"type,omitempty"`
+}
+
+func (k IndexKey) value() (interface{}, error) {
+	switch k.Type {
+	case "", "asc", "1":
+		return 1, nil
+	case "desc", "-1":
+		return -1, nil
+	case "text", "2dsphere":
+		return k.Type, nil
+	default:
+		return nil, fmt.Errorf("unsupported index type %q for field %s", k.Type, k.Field)
+	}
+}
+
+func (s IndexSpec) model() (mongo.IndexModel, error) {
+	if s.Collection == "" {
+		return mongo.IndexModel{}, errors.New("index requires a collection")
+	}
+	if len(s.Keys) == 0 {
+		return mongo.IndexModel{}, fmt.Errorf("index on %s requires at least one key", s.Collection)
+	}
+	keys := make(bson.D, 0, len(s.Keys))
+	for _, k := range s.Keys {
+		if k.Field == "" {
+			return mongo.IndexModel{}, fmt.Errorf("index on %s has a key without a field", s.Collection)
+		}
+		v, err := k.value()
+		if err != nil {
+			return mongo.IndexModel{}, err
+		}
+		keys = append(keys, bson.E{Key: k.Field, Value: v})
+	}
+	opts := options.Index().SetUnique(s.Unique).SetSparse(s.Sparse)
+	if s.Name != "" {
+		opts.SetName(s.Name)
+	}
+	return mongo.IndexModel{Keys: keys, Options: opts}, nil
+}
+
+// parseIndexSpecs reads the indexes config value, which is either a list
+// (from YAML) or a JSON string (from the integration form).
+func parseIndexSpecs(v any) ([]IndexSpec, error) {
+	var raw []byte
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if val == "" {
+			return nil, nil
+		}
+		raw = []byte(val)
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		raw = b
+	}
+	var specs []IndexSpec
+	if err := json.Unmarshal(raw, &specs); err != nil {
+		return nil, fmt.Errorf("invalid indexes: %w", err)
+	}
+	return specs, nil
+}
+
+// collectionIndexes are the index models declared for one collection.
+type collectionIndexes struct {
+	collection string
+	models     []mongo.IndexModel
+}
+
+// groupIndexes validates specs and groups them by collection, keeping the
+// order collections were first declared in.
+func groupIndexes(specs []IndexSpec) ([]collectionIndexes, error) {
+	var groups []collectionIndexes
+	pos := make(map[string]int)
+	for _, spec := range specs {
+		model, err := spec.model()
+		if err != nil {
+			return nil, err
+		}
+		i, ok := pos[spec.Collection]
+		if !ok {
+			i = len(groups)
+			pos[spec.Collection] = i
+			groups = append(groups, collectionIndexes{collection: spec.Collection})
+		}
+		groups[i].models = append(groups[i].models, model)
+	}
+	return groups, nil
+}
+
+// ensureIndexes creates the declared indexes. Creating an index that already
+// exists with the same definition is a no-op, so this runs on every startup.
+func (m *Mongo) ensureIndexes(ctx context.Context, groups []collectionIndexes) error {
+	for _, g := range groups {
+		if _, err := m.writeDB().Collection(g.collection).Indexes().CreateMany(ctx, g.models); err != nil {
+			return fmt.Errorf("error creating indexes on %s: %w", g.collection, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrationsCollection records which migrations have been applied.
+const migrationsCollection = "servflow_migrations"
+
+var _ integration.Migrator = (*Mongo)(nil)
+
+// indexMigration is the script format for mongo migrations, in extended JSON
+// so compound index keys keep their order:
+//
+//	{"collection": "users", "indexes": [{"keys": {"email": 1}, "unique": true}]}
+type indexMigration struct {
+	Collection string `bson:"collection"`
+	Indexes    []struct {
+		Keys               bson.D `bson:"keys"`
+		Name               string `bson:"name"`
+		Unique             bool   `bson:"unique"`
+		Sparse             bool   `bson:"sparse"`
+		ExpireAfterSeconds *int32 `bson:"expireAfterSeconds"`
+	} `bson:"indexes"`
+}
+
+func parseIndexMigration(script string) ([]mongo.IndexModel, string, error) {
+	var m indexMigration
+	if err := bson.UnmarshalExtJSON([]byte(script), false, &m); err != nil {
+		return nil, "", fmt.Errorf("invalid index migration: %w", err)
+	}
+	if m.Collection == "" {
+		return nil, "", errors.New("index migration requires a collection")
+	}
+	if len(m.Indexes) == 0 {
+		return nil, "", errors.New("index migration requires at least one index")
+	}
+
+	models := make([]mongo.IndexModel, 0, len(m.Indexes))
+	for _, idx := range m.Indexes {
+		if len(idx.Keys) == 0 {
+			return nil, "", errors.New("index migration has an index without keys")
+		}
+		opts := options.Index().SetUnique(idx.Unique).SetSparse(idx.Sparse)
+		if idx.Name != "" {
+			opts.SetName(idx.Name)
+		}
+		if idx.ExpireAfterSeconds != nil {
+			opts.SetExpireAfterSeconds(*idx.ExpireAfterSeconds)
+		}
+		models = append(models, mongo.IndexModel{Keys: idx.Keys, Options: opts})
+	}
+	return models, m.Collection, nil
+}
+
+func (m *Mongo) AppliedMigrations(ctx context.Context) (map[string]bool, error) {
+	if err := m.ensureConnected(ctx); err != nil {
+		return nil, fmt.Errorf("connection error: %w", err)
+	}
+	cursor, err := m.writeDB().Collection(migrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var records []bson.M
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	applied := make(map[string]bool, len(records))
+	for _, r := range records {
+		if v, ok := r["_id"].(string); ok {
+			applied[v] = true
+		}
+	}
+	return applied, nil
+}
+
+// ApplyMigration creates the indexes described by the migration script and
+// records its version. Creating an index that already exists is a no-op, so a
+// migration interrupted before it was recorded is safe to run again.
+func (m *Mongo) ApplyMigration(ctx context.Context, migration integration.Migration) error {
+	models, collection, err := parseIndexMigration(migration.Script)
+	if err != nil {
+		return err
+	}
+	if err := m.ensureConnected(ctx); err != nil {
+		return fmt.Errorf("connection error: %w", err)
+	}
+	if _, err := m.writeDB().Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+		return err
+	}
+	_, err = m.writeDB().Collection(migrationsCollection).InsertOne(ctx, bson.M{
+		"_id":       migration.Version,
+		"appliedAt": time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	return nil
+}
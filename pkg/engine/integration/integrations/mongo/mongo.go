@@ -2,7 +2,10 @@ package mongo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,21 +13,83 @@ import (
 	dbfilters "github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
 	"github.com/Servflow/servflow/pkg/logging"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
 type Config struct {
 	ConnectionString string `json:"connectionString"`
 	DBName           string `json:"dbName"`
+	// WriteConcern is "majority" or a number of acknowledging nodes ("0",
+	// "1", ...). Empty uses the server default.
+	WriteConcern string `json:"writeConcern"`
+	// ReadPreference is one of primary, primaryPreferred, secondary,
+	// secondaryPreferred or nearest. Empty uses the server default.
+	ReadPreference string `json:"readPreference"`
+	// Indexes are created when the integration starts, if absent.
+	Indexes []IndexSpec `json:"indexes"`
+	// SlowQueryThreshold logs operations taking at least this long, e.g.
+	// "500ms". Empty disables slow-query logging.
+	SlowQueryThreshold string `json:"slowQueryThreshold"`
 }
 
 type Mongo struct {
 	integration.BaseIntegration
-	client *mongo.Client
-	dbName string
-	config Config
-	mu     sync.Mutex
+	client       *mongo.Client
+	dbName       string
+	config       Config
+	writeConcern *writeconcern.WriteConcern
+	readPref     *readpref.ReadPref
+	slowQuery    time.Duration
+	mu           sync.Mutex
+}
+
+func parseWriteConcern(s string) (*writeconcern.WriteConcern, error) {
+	switch s {
+	case "":
+		return nil, nil
+	case "majority":
+		return writeconcern.Majority(), nil
+	}
+	w, err := strconv.Atoi(s)
+	if err != nil || w < 0 {
+		return nil, fmt.Errorf("invalid write concern %q: must be \"majority\" or a non-negative number", s)
+	}
+	return &writeconcern.WriteConcern{W: w}, nil
+}
+
+func parseReadPreference(s string) (*readpref.ReadPref, error) {
+	if s == "" {
+		return nil, nil
+	}
+	mode, err := readpref.ModeFromString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid read preference %q", s)
+	}
+	return readpref.New(mode)
+}
+
+// readDB returns the database handle for queries, carrying the configured
+// read preference.
+func (m *Mongo) readDB() *mongo.Database {
+	opts := options.Database()
+	if m.readPref != nil {
+		opts.SetReadPreference(m.readPref)
+	}
+	return m.client.Database(m.dbName, opts)
+}
+
+// writeDB returns the database handle for inserts, updates and deletes,
+// carrying the configured write concern.
+func (m *Mongo) writeDB() *mongo.Database {
+	opts := options.Database()
+	if m.writeConcern != nil {
+		opts.SetWriteConcern(m.writeConcern)
+	}
+	return m.client.Database(m.dbName, opts)
 }
 
 func (m *Mongo) Shutdown(ctx context.Context) error {
@@ -75,7 +140,57 @@ func (m *Mongo) ensureConnected(ctx context.Context) error {
 	return nil
 }
 
-func (m *Mongo) ExecuteQuery(ctx context.Context, collection string, filterQuery string, projectionQuery string) ([]map[string]interface{}, error) {
+// QueryOptions holds the optional paging and ordering parameters of
+// ExecuteQuery. Each is a JSON document: Limit and Skip are non-negative
+// integers (e.g. "10") and Sort is an object of field to direction, e.g.
+// {"age": -1, "name": 1}. Sort keys are applied in the order written.
+type QueryOptions struct {
+	Limit string
+	Skip  string
+	Sort  string
+	// IDFormat controls how _id is returned; see normalizeID.
+	IDFormat string
+}
+
+func (q QueryOptions) findOptions() (*options.FindOptions, error) {
+	opts := options.Find()
+	if q.Limit != "" {
+		limit, err := parseCount(q.Limit)
+		if err != nil {
+			return nil, fmt.Errorf("error processing limit: %v", err)
+		}
+		opts.SetLimit(limit)
+	}
+	if q.Skip != "" {
+		skip, err := parseCount(q.Skip)
+		if err != nil {
+			return nil, fmt.Errorf("error processing skip: %v", err)
+		}
+		opts.SetSkip(skip)
+	}
+	if q.Sort != "" {
+		var sort bson.D
+		if err := bson.UnmarshalExtJSON([]byte(q.Sort), false, &sort); err != nil {
+			return nil, fmt.Errorf("error processing sort: %v", err)
+		}
+		opts.SetSort(sort)
+	}
+	return opts, nil
+}
+
+// parseCount parses a JSON integer used for limit and skip.
+func parseCount(raw string) (int64, error) {
+	var n int64
+	if err := json.Unmarshal([]byte(raw), &n); err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("must not be negative, got %d", n)
+	}
+	return n, nil
+}
+
+func (m *Mongo) ExecuteQuery(ctx context.Context, collection string, filterQuery string, projectionQuery string, queryOpts QueryOptions) ([]map[string]interface{}, error) {
 	if err := m.ensureConnected(ctx); err != nil {
 		return nil, fmt.Errorf("connection error: %w", err)
 	}
@@ -94,10 +209,15 @@ func (m *Mongo) ExecuteQuery(ctx context.Context, collection string, filterQuery
 		}
 	}
 
-	db := m.client.Database(m.dbName)
-	coll := db.Collection(collection)
-	opts := options.Find().SetProjection(projection)
+	opts, err := queryOpts.findOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts.SetProjection(projection)
 
+	coll := m.readDB().Collection(collection)
+
+	defer m.observe(ctx, "query", collection, filter, time.Now())
 	cur, err := coll.Find(context.Background(), filter, opts)
 	if err != nil {
 		return nil, fmt.Errorf("error executing query: %v", err)
@@ -109,12 +229,52 @@ func (m *Mongo) ExecuteQuery(ctx context.Context, collection string, filterQuery
 	}
 
 	var results []map[string]interface{}
-	for _, m := range r {
-		results = append(results, m)
+	for _, doc := range r {
+		if err := normalizeID(doc, queryOpts.IDFormat); err != nil {
+			return nil, err
+		}
+		results = append(results, doc)
 	}
 	return results, nil
 }
 
+const (
+	// IDFormatString returns an ObjectID _id as its hex string. This is the
+	// default: a raw ObjectID renders as ObjectID("...") in templates.
+	IDFormatString = "string"
+	// IDFormatID additionally moves _id to an "id" key.
+	IDFormatID = "id"
+	// IDFormatRaw leaves _id untouched.
+	IDFormatRaw = "raw"
+)
+
+// normalizeID rewrites a result document's _id according to format so it can
+// be used directly in a JSON response.
+func normalizeID(doc bson.M, format string) error {
+	switch format {
+	case "", IDFormatString, IDFormatID:
+	case IDFormatRaw:
+		return nil
+	default:
+		return fmt.Errorf("invalid id format %q: must be %s, %s or %s", format, IDFormatString, IDFormatID, IDFormatRaw)
+	}
+
+	id, ok := doc["_id"]
+	if !ok {
+		return nil
+	}
+	if oid, ok := id.(primitive.ObjectID); ok {
+		id = oid.Hex()
+	}
+	if format == IDFormatID {
+		delete(doc, "_id")
+		doc["id"] = id
+		return nil
+	}
+	doc["_id"] = id
+	return nil
+}
+
 func (m *Mongo) Delete(ctx context.Context, options map[string]string, filters ...dbfilters.Filter) error {
 	if err := m.ensureConnected(ctx); err != nil {
 		return fmt.Errorf("connection error: %w", err)
@@ -125,25 +285,71 @@ func (m *Mongo) Delete(ctx context.Context, options map[string]string, filters .
 		return fmt.Errorf("invalid collection")
 	}
 
+	if err := dbfilters.CheckDeleteScope(options, filters); err != nil {
+		return err
+	}
+	batchSize, err := dbfilters.ParseBatchSize(options)
+	if err != nil {
+		return err
+	}
+
 	bsonFilter, err := dbfilters.FiltersToBSON(filters)
 	if err != nil {
 		return fmt.Errorf("invalid filters: %w", err)
 	}
 
-	_, err = m.client.Database(m.dbName).Collection(c).DeleteMany(ctx, bsonFilter)
+	defer m.observe(ctx, "delete", c, bsonFilter, time.Now())
+	if batchSize > 0 {
+		err = m.deleteInBatches(ctx, c, bsonFilter, batchSize)
+	} else {
+		_, err = m.writeDB().Collection(c).DeleteMany(ctx, bsonFilter)
+	}
 	if err != nil {
 		return fmt.Errorf("error deleting items: %w", err)
 	}
 
+	integration.RecordWrite(ctx, integration.AuditDelete, c, nil, filters...)
 	return nil
 }
 
+// deleteInBatches deletes matching documents batchSize at a time, selecting
+// each batch's _ids first so no single DeleteMany touches more than a batch.
+func (m *Mongo) deleteInBatches(ctx context.Context, collection string, filter bson.D, batchSize int) error {
+	coll := m.writeDB().Collection(collection)
+	findOpts := options.Find().SetLimit(int64(batchSize)).SetProjection(bson.M{"_id": 1})
+	for {
+		cursor, err := coll.Find(ctx, filter, findOpts)
+		if err != nil {
+			return err
+		}
+		var docs []bson.M
+		if err := cursor.All(ctx, &docs); err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		ids := make(bson.A, 0, len(docs))
+		for _, doc := range docs {
+			ids = append(ids, doc["_id"])
+		}
+		if _, err := coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			return err
+		}
+		if len(docs) < batchSize {
+			return nil
+		}
+	}
+}
+
 func (m *Mongo) Type() string {
 	return "mongo"
 }
 
 var (
 	collectionOption = "collection"
+	// idFormatOption selects how Fetch returns _id, see normalizeID.
+	idFormatOption = "idFormat"
 )
 
 func init() {
@@ -160,6 +366,26 @@ func init() {
 			Placeholder: "mydb",
 			Required:    true,
 		},
+		"writeConcern": {
+			Type:        integration.FieldTypeString,
+			Label:       "Write Concern",
+			Placeholder: "majority, or number of nodes (e.g. 1)",
+		},
+		"readPreference": {
+			Type:        integration.FieldTypeString,
+			Label:       "Read Preference",
+			Placeholder: "primary, primaryPreferred, secondary, secondaryPreferred or nearest",
+		},
+		"indexes": {
+			Type:        integration.FieldTypeString,
+			Label:       "Indexes",
+			Placeholder: `[{"collection": "users", "keys": [{"field": "email"}], "unique": true}]`,
+		},
+		"slowQueryThreshold": {
+			Type:        integration.FieldTypeString,
+			Label:       "Slow Query Threshold",
+			Placeholder: "Log operations slower than this, e.g. 500ms",
+		},
 	}
 
 	if err := integration.RegisterIntegration("mongo", integration.RegistrationInfo{
@@ -168,9 +394,20 @@ func init() {
 		ImageURL:    "https://d2ojax9k5fldtt.cloudfront.net/mongo.svg",
 		Fields:      fields,
 		Constructor: func(m map[string]any) (integration.Integration, error) {
+			writeConcern, _ := m["writeConcern"].(string)
+			readPreference, _ := m["readPreference"].(string)
+			slowQueryThreshold, _ := m["slowQueryThreshold"].(string)
+			indexes, err := parseIndexSpecs(m["indexes"])
+			if err != nil {
+				return nil, err
+			}
 			return newWrapper(Config{
-				ConnectionString: m["connectionString"].(string),
-				DBName:           m["dbName"].(string),
+				ConnectionString:   m["connectionString"].(string),
+				DBName:             m["dbName"].(string),
+				WriteConcern:       writeConcern,
+				ReadPreference:     readPreference,
+				Indexes:            indexes,
+				SlowQueryThreshold: slowQueryThreshold,
 			})
 		},
 	}); err != nil {
@@ -179,9 +416,29 @@ func init() {
 }
 
 func newWrapper(cfg Config) (*Mongo, error) {
+	wc, err := parseWriteConcern(cfg.WriteConcern)
+	if err != nil {
+		return nil, err
+	}
+	rp, err := parseReadPreference(cfg.ReadPreference)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := groupIndexes(cfg.Indexes)
+	if err != nil {
+		return nil, err
+	}
+	slowQuery, err := integration.ParseSlowQueryThreshold(cfg.SlowQueryThreshold)
+	if err != nil {
+		return nil, err
+	}
+
 	m := &Mongo{
-		dbName: cfg.DBName,
-		config: cfg,
+		dbName:       cfg.DBName,
+		config:       cfg,
+		writeConcern: wc,
+		readPref:     rp,
+		slowQuery:    slowQuery,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -191,9 +448,61 @@ func newWrapper(cfg Config) (*Mongo, error) {
 		return nil, err
 	}
 
+	if err := m.ensureIndexes(ctx, indexes); err != nil {
+		_ = m.client.Disconnect(ctx)
+		return nil, err
+	}
+
 	return m, nil
 }
 
+// updateOperators are the operators Update accepts besides plain fields.
+var updateOperators = map[string]bool{
+	"$set":   true,
+	"$inc":   true,
+	"$push":  true,
+	"$pull":  true,
+	"$unset": true,
+}
+
+// buildUpdate turns Update fields into an update document. Plain keys are set
+// with $set; a key naming an operator takes a map of field to operand, so
+//
+//	{"name": "ada", "$inc": {"logins": 1}, "$push": {"tags": "new"}}
+//
+// sets name, increments logins and appends to tags in one atomic write.
+func buildUpdate(fields map[string]interface{}) (bson.M, error) {
+	update := bson.M{}
+	set := bson.M{}
+	for k, v := range fields {
+		if !strings.HasPrefix(k, "$") {
+			set[k] = v
+			continue
+		}
+		if !updateOperators[k] {
+			return nil, fmt.Errorf("unsupported update operator: %s", k)
+		}
+		operand, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("update operator %s expects an object of fields, got %T", k, v)
+		}
+		if len(operand) == 0 {
+			continue
+		}
+		if k == "$set" {
+			for field, val := range operand {
+				set[field] = val
+			}
+			continue
+		}
+		update[k] = operand
+	}
+	if len(set) > 0 || len(update) == 0 {
+		update["$set"] = set
+	}
+	return update, nil
+}
+
 func (m *Mongo) Update(ctx context.Context, fields map[string]interface{}, opts map[string]string, filters ...dbfilters.Filter) (string, error) {
 	if err := m.ensureConnected(ctx); err != nil {
 		return "", fmt.Errorf("connection error: %w", err)
@@ -203,20 +512,53 @@ func (m *Mongo) Update(ctx context.Context, fields map[string]interface{}, opts
 	if !ok {
 		return "", fmt.Errorf("invalid collection")
 	}
-	bsonFilter, err := dbfilters.FiltersToBSON(filters)
+	update, err := buildUpdate(fields)
+	if err != nil {
+		return "", err
+	}
+
+	// Optimistic locking: only match the document while it still has the
+	// version the caller read, bumping it in the same atomic write.
+	writeFilters := filters
+	versionStr, versioned := opts[dbfilters.VersionOption]
+	if versioned {
+		version, err := dbfilters.ParseVersion(versionStr)
+		if err != nil {
+			return "", err
+		}
+		if dbfilters.UpdatesVersion(fields) {
+			return "", fmt.Errorf("%s is managed by optimistic locking and cannot be updated directly", dbfilters.VersionField)
+		}
+		inc := bson.M{dbfilters.VersionField: 1}
+		if existing, ok := update["$inc"].(map[string]interface{}); ok {
+			for k, v := range existing {
+				inc[k] = v
+			}
+		}
+		update["$inc"] = inc
+		writeFilters = append(filters[:len(filters):len(filters)],
+			dbfilters.Filter{Field: dbfilters.VersionField, Operation: dbfilters.Equals, Comparator: version})
+	}
+
+	bsonFilter, err := dbfilters.FiltersToBSON(writeFilters)
 	if err != nil {
 		return "", fmt.Errorf("invalid filters: %w", err)
 	}
 
 	updateOpts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var updatedDoc bson.M
-	err = m.client.Database(m.dbName).Collection(c).FindOneAndUpdate(ctx, bsonFilter, bson.M{"$set": fields}, updateOpts).Decode(&updatedDoc)
+	defer m.observe(ctx, "update", c, bsonFilter, time.Now())
+	err = m.writeDB().Collection(c).FindOneAndUpdate(ctx, bsonFilter, update, updateOpts).Decode(&updatedDoc)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
+			if versioned {
+				return "", m.versionMismatch(ctx, c, filters)
+			}
 			return "", dbfilters.ErrNoMatch
 		}
 		return "", fmt.Errorf("error with update: %w", err)
 	}
+	integration.RecordWrite(ctx, integration.AuditUpdate, c, fields, writeFilters...)
 
 	// Extract ID from updated document
 	var id string
@@ -229,6 +571,59 @@ func (m *Mongo) Update(ctx context.Context, fields map[string]interface{}, opts
 	return id, nil
 }
 
+// versionMismatch explains a versioned update that matched nothing: if a
+// document matches the caller's filters it must have moved to another version.
+func (m *Mongo) versionMismatch(ctx context.Context, collection string, filters []dbfilters.Filter) error {
+	bsonFilter, err := dbfilters.FiltersToBSON(filters)
+	if err != nil {
+		return fmt.Errorf("invalid filters: %w", err)
+	}
+	count, err := m.writeDB().Collection(collection).CountDocuments(ctx, bsonFilter, options.Count().SetLimit(1))
+	if err != nil {
+		return fmt.Errorf("failed to check version: %w", err)
+	}
+	if count > 0 {
+		return dbfilters.ErrVersionConflict
+	}
+	return dbfilters.ErrNoMatch
+}
+
+// fetchOptions builds the find options of a Fetch from its datasource
+// options. The sort option becomes a sort document with 1 for ascending and
+// -1 for descending keys; limit and offset page the cursor.
+func fetchOptions(opts map[string]string) (*options.FindOptions, error) {
+	findOpts := options.Find()
+	page, err := dbfilters.ParsePage(opts)
+	if err != nil {
+		return nil, err
+	}
+	if page.Limit > 0 {
+		findOpts.SetLimit(page.Limit)
+	}
+	if page.Offset > 0 {
+		findOpts.SetSkip(page.Offset)
+	}
+	sort, err := dbfilters.ParseSort(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(sort) > 0 {
+		doc := make(bson.D, len(sort))
+		for i, sf := range sort {
+			if strings.HasPrefix(sf.Field, "$") {
+				return nil, fmt.Errorf("invalid sort field: %q", sf.Field)
+			}
+			dir := 1
+			if sf.Desc {
+				dir = -1
+			}
+			doc[i] = bson.E{Key: sf.Field, Value: dir}
+		}
+		findOpts.SetSort(doc)
+	}
+	return findOpts, nil
+}
+
 func (m *Mongo) Fetch(ctx context.Context, options map[string]string, filters ...dbfilters.Filter) (items []map[string]interface{}, err error) {
 	if err := m.ensureConnected(ctx); err != nil {
 		return nil, fmt.Errorf("connection error: %w", err)
@@ -243,7 +638,12 @@ func (m *Mongo) Fetch(ctx context.Context, options map[string]string, filters ..
 	if err != nil {
 		return nil, fmt.Errorf("invalid filters: %w", err)
 	}
-	cursor, err := m.client.Database(m.dbName).Collection(c).Find(ctx, bsonFilter)
+	findOpts, err := fetchOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	defer m.observe(ctx, "fetch", c, bsonFilter, time.Now())
+	cursor, err := m.readDB().Collection(c).Find(ctx, bsonFilter, findOpts)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching items: %w", err)
 	}
@@ -255,20 +655,106 @@ func (m *Mongo) Fetch(ctx context.Context, options map[string]string, filters ..
 
 	results := make([]map[string]interface{}, len(mResults))
 	for i, res := range mResults {
+		if err := normalizeID(res, options[idFormatOption]); err != nil {
+			return nil, err
+		}
 		results[i] = res
 	}
 
 	return results, nil
 }
 
-func (m *Mongo) Store(ctx context.Context, item map[string]interface{}, options map[string]string) error {
+// FetchPage is Fetch that also returns how many documents match the filters
+// in total, regardless of the limit and offset options, so callers can build
+// pagination metadata.
+func (m *Mongo) FetchPage(ctx context.Context, options map[string]string, filters ...dbfilters.Filter) ([]map[string]interface{}, int64, error) {
+	items, err := m.Fetch(ctx, options, filters...)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := m.Count(ctx, options, filters...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// Count returns the number of documents in the collection matching filters.
+// Limit, offset and sort options are ignored.
+func (m *Mongo) Count(ctx context.Context, options map[string]string, filters ...dbfilters.Filter) (int64, error) {
 	if err := m.ensureConnected(ctx); err != nil {
-		return fmt.Errorf("connection error: %w", err)
+		return 0, fmt.Errorf("connection error: %w", err)
 	}
 
-	_, err := m.client.Database(m.dbName).Collection(options[collectionOption]).InsertOne(ctx, item)
+	c, ok := options[collectionOption]
+	if !ok {
+		return 0, fmt.Errorf("invalid collection")
+	}
+
+	bsonFilter, err := dbfilters.FiltersToBSON(filters)
 	if err != nil {
-		return fmt.Errorf("error inserting item: %w", err)
+		return 0, fmt.Errorf("invalid filters: %w", err)
+	}
+	defer m.observe(ctx, "count", c, bsonFilter, time.Now())
+	total, err := m.readDB().Collection(c).CountDocuments(ctx, bsonFilter)
+	if err != nil {
+		return 0, fmt.Errorf("error counting items: %w", err)
+	}
+	return total, nil
+}
+
+// Store inserts item. With dbfilters.UpsertOnOption set, the document holding
+// the same values for those keys is updated instead, or inserted when there is
+// none.
+func (m *Mongo) Store(ctx context.Context, item map[string]interface{}, opts map[string]string) error {
+	upsertOn, err := dbfilters.ParseUpsertOn(opts, item)
+	if err != nil {
+		return err
+	}
+	if err := m.ensureConnected(ctx); err != nil {
+		return fmt.Errorf("connection error: %w", err)
+	}
+
+	collection := m.writeDB().Collection(opts[collectionOption])
+	if upsertOn != nil {
+		filter := make(bson.D, len(upsertOn))
+		for i, key := range upsertOn {
+			filter[i] = bson.E{Key: key, Value: item[key]}
+		}
+		defer m.observe(ctx, "upsert", opts[collectionOption], filter, time.Now())
+		_, err = collection.UpdateOne(ctx, filter, bson.M{"$set": item}, options.Update().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("error upserting item: %w", err)
+		}
+	} else {
+		defer m.observe(ctx, "store", opts[collectionOption], nil, time.Now())
+		if _, err = collection.InsertOne(ctx, item); err != nil {
+			return fmt.Errorf("error inserting item: %w", err)
+		}
+	}
+	integration.RecordWrite(ctx, integration.AuditStore, opts[collectionOption], item)
+	return nil
+}
+
+// StoreMany inserts items in a single InsertMany round trip.
+func (m *Mongo) StoreMany(ctx context.Context, items []map[string]interface{}, options map[string]string) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if err := m.ensureConnected(ctx); err != nil {
+		return fmt.Errorf("connection error: %w", err)
+	}
+
+	docs := make([]interface{}, len(items))
+	for i, item := range items {
+		docs[i] = item
+	}
+	defer m.observe(ctx, "store_many", options[collectionOption], nil, time.Now())
+	if _, err := m.writeDB().Collection(options[collectionOption]).InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("error inserting items: %w", err)
+	}
+	for _, item := range items {
+		integration.RecordWrite(ctx, integration.AuditStore, options[collectionOption], item)
 	}
 	return nil
 }
@@ -2,19 +2,27 @@ package mongo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"testing"
 	"time"
 
+	"github.com/Servflow/servflow/pkg/engine/integration"
 	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/Servflow/servflow/pkg/logging"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func startMongoContainer(t *testing.T) string {
@@ -88,6 +96,43 @@ func TestMongo_NewWrapper(t *testing.T) {
 	assert.Equal(t, "servflow", mng.dbName)
 }
 
+func TestMongo_NewWrapperInvalidConsistencyOptions(t *testing.T) {
+	_, err := newWrapper(Config{ConnectionString: "mongodb://unused", DBName: "servflow", WriteConcern: "most"})
+	assert.ErrorContains(t, err, "invalid write concern")
+
+	_, err = newWrapper(Config{ConnectionString: "mongodb://unused", DBName: "servflow", WriteConcern: "-1"})
+	assert.ErrorContains(t, err, "invalid write concern")
+
+	_, err = newWrapper(Config{ConnectionString: "mongodb://unused", DBName: "servflow", ReadPreference: "fastest"})
+	assert.ErrorContains(t, err, "invalid read preference")
+}
+
+func TestMongo_ConsistencyOptionsApplied(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{
+		ConnectionString: uri,
+		DBName:           "servflow",
+		WriteConcern:     "majority",
+		ReadPreference:   "primaryPreferred",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, writeconcern.Majority(), mng.writeDB().WriteConcern())
+	assert.Equal(t, readpref.PrimaryPreferredMode, mng.readDB().ReadPreference().Mode())
+
+	// Writes and reads still succeed against a standalone server.
+	require.NoError(t, mng.Store(context.Background(), map[string]interface{}{"name": "wc"}, map[string]string{collectionOption: "users"}))
+	fetched, err := mng.Fetch(context.Background(), map[string]string{collectionOption: "users"},
+		filters.Filter{Field: "name", Operation: filters.Equals, Comparator: "wc"})
+	require.NoError(t, err)
+	assert.Len(t, fetched, 1)
+
+	plain, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow", WriteConcern: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, plain.writeDB().WriteConcern().W)
+}
+
 func TestMongo_ExecuteQuery(t *testing.T) {
 	t.Parallel()
 	runExecuteQuery := func(initialDocs []map[string]interface{}, filterQuery, projectionQuery string, expected []map[string]interface{}) func(t *testing.T) {
@@ -108,7 +153,7 @@ func TestMongo_ExecuteQuery(t *testing.T) {
 			}
 
 			// Execute the query
-			results, err := mng.ExecuteQuery(context.Background(), "users", filterQuery, projectionQuery)
+			results, err := mng.ExecuteQuery(context.Background(), "users", filterQuery, projectionQuery, QueryOptions{})
 			require.NoError(t, err)
 
 			require.Equal(t, len(expected), len(results))
@@ -225,6 +270,67 @@ func TestMongo_ExecuteQuery(t *testing.T) {
 	))
 }
 
+func TestMongo_ExecuteQueryPagination(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+	require.NoError(t, err)
+
+	for _, doc := range []map[string]interface{}{
+		{"name": "amy", "age": int32(41), "team": "a"},
+		{"name": "bob", "age": int32(35), "team": "b"},
+		{"name": "cal", "age": int32(30), "team": "a"},
+		{"name": "dan", "age": int32(25), "team": "a"},
+		{"name": "eve", "age": int32(20), "team": "a"},
+	} {
+		_, cleanup := writeDataAndReturnCleanupFn(mng.client, "servflow", "people", doc)
+		t.Cleanup(cleanup)
+	}
+
+	page := func(skip string) []map[string]interface{} {
+		results, err := mng.ExecuteQuery(context.Background(), "people", `{"team": "a"}`, `{"_id": 0, "name": 1}`, QueryOptions{
+			Sort:  `{"age": 1}`,
+			Limit: "2",
+			Skip:  skip,
+		})
+		require.NoError(t, err)
+		return results
+	}
+
+	assert.Equal(t, []map[string]interface{}{{"name": "eve"}, {"name": "dan"}}, page("0"))
+	assert.Equal(t, []map[string]interface{}{{"name": "cal"}, {"name": "amy"}}, page("2"))
+	assert.Empty(t, page("4"))
+
+	t.Run("invalid options", func(t *testing.T) {
+		for name, opts := range map[string]QueryOptions{
+			"limit": {Limit: "ten"},
+			"skip":  {Skip: "{"},
+			"sort":  {Sort: `{"age": `},
+		} {
+			_, err := mng.ExecuteQuery(context.Background(), "people", "", "", opts)
+			assert.ErrorContains(t, err, "error processing "+name)
+		}
+	})
+}
+
+func TestQueryOptions_findOptions(t *testing.T) {
+	opts, err := QueryOptions{Limit: "5", Skip: "10", Sort: `{"b": -1, "a": 1}`}.findOptions()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), *opts.Limit)
+	assert.Equal(t, int64(10), *opts.Skip)
+	assert.Equal(t, bson.D{{Key: "b", Value: int32(-1)}, {Key: "a", Value: int32(1)}}, opts.Sort)
+
+	opts, err = QueryOptions{}.findOptions()
+	require.NoError(t, err)
+	assert.Nil(t, opts.Limit)
+	assert.Nil(t, opts.Sort)
+
+	_, err = QueryOptions{Limit: "-1"}.findOptions()
+	assert.ErrorContains(t, err, "must not be negative")
+	_, err = QueryOptions{Limit: "1.5"}.findOptions()
+	assert.ErrorContains(t, err, "error processing limit")
+}
+
 func TestMongo_Fetch(t *testing.T) {
 	t.Parallel()
 	runFetch := func(initialDocs, expected []map[string]interface{}, filters ...filters.Filter) func(t *testing.T) {
@@ -284,6 +390,212 @@ func TestMongo_Fetch(t *testing.T) {
 		Operation:  "==",
 		Comparator: "testa",
 	}))
+	t.Run("between", runFetch([]map[string]interface{}{
+		{"name": "young", "age": int32(12)},
+		{"name": "adult", "age": int32(30)},
+		{"name": "edge", "age": int32(65)},
+		{"name": "senior", "age": int32(70)},
+	}, []map[string]interface{}{
+		{"name": "adult", "age": int32(30)},
+		{"name": "edge", "age": int32(65)},
+	}, filters.Filter{
+		Field:      "age",
+		Operation:  filters.Between,
+		Comparator: []interface{}{18, 65},
+	}))
+	t.Run("sort", func(t *testing.T) {
+		t.Parallel()
+		uri := startMongoContainer(t)
+		mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+		require.NoError(t, err)
+
+		for _, doc := range []map[string]interface{}{
+			{"name": "b", "team": "red"},
+			{"name": "c", "team": "blue"},
+			{"name": "a", "team": "red"},
+		} {
+			_, cleanup := writeDataAndReturnCleanupFn(mng.client, "servflow", "users", doc)
+			t.Cleanup(cleanup)
+		}
+
+		names := func(sort string) []string {
+			fetched, err := mng.Fetch(context.Background(), map[string]string{collectionOption: "users", filters.SortOption: sort})
+			require.NoError(t, err)
+			var out []string
+			for _, doc := range fetched {
+				out = append(out, doc["name"].(string))
+			}
+			return out
+		}
+
+		assert.Equal(t, []string{"c", "b", "a"}, names("name:desc"))
+		assert.Equal(t, []string{"a", "b", "c"}, names("name"))
+		assert.Equal(t, []string{"a", "b", "c"}, names("team:desc,name:asc"))
+
+		_, err = mng.Fetch(context.Background(), map[string]string{collectionOption: "users", filters.SortOption: "name:up"})
+		assert.ErrorContains(t, err, "must be asc or desc")
+	})
+	t.Run("paginate", func(t *testing.T) {
+		t.Parallel()
+		uri := startMongoContainer(t)
+		mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+		require.NoError(t, err)
+
+		for i := 1; i <= 5; i++ {
+			_, cleanup := writeDataAndReturnCleanupFn(mng.client, "servflow", "users", map[string]interface{}{"seq": int32(i)})
+			t.Cleanup(cleanup)
+		}
+
+		opts := map[string]string{collectionOption: "users", filters.SortOption: "seq", filters.LimitOption: "2", filters.OffsetOption: "2"}
+		items, total, err := mng.FetchPage(context.Background(), opts, filters.Filter{Field: "seq", Operation: filters.LessThan, Comparator: 5})
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.EqualValues(t, 3, items[0]["seq"])
+		assert.EqualValues(t, 4, items[1]["seq"])
+		assert.Equal(t, int64(4), total)
+
+		_, err = mng.Fetch(context.Background(), map[string]string{collectionOption: "users", filters.LimitOption: "-2"})
+		assert.ErrorContains(t, err, "must be a positive integer")
+	})
+}
+
+func TestMongo_Count(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+	require.NoError(t, err)
+
+	for _, doc := range []map[string]interface{}{
+		{"name": "a", "team": "red"},
+		{"name": "b", "team": "blue"},
+		{"name": "c", "team": "red"},
+	} {
+		_, cleanup := writeDataAndReturnCleanupFn(mng.client, "servflow", "users", doc)
+		t.Cleanup(cleanup)
+	}
+
+	opts := map[string]string{collectionOption: "users"}
+	total, err := mng.Count(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+
+	total, err = mng.Count(context.Background(), opts, filters.Filter{Field: "team", Operation: "==", Comparator: "red"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+
+	total, err = mng.Count(context.Background(), map[string]string{collectionOption: "users", filters.LimitOption: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+
+	_, err = mng.Count(context.Background(), map[string]string{})
+	assert.ErrorContains(t, err, "invalid collection")
+}
+
+func TestMongo_FetchElemMatch(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+	require.NoError(t, err)
+
+	for _, doc := range []map[string]interface{}{
+		{
+			"name":   "first",
+			"tags":   []string{"go", "mongo"},
+			"scores": []int32{70, 95},
+			"items":  []map[string]interface{}{{"sku": "A1", "qty": int32(2)}, {"sku": "B2", "qty": int32(10)}},
+		},
+		{
+			"name":   "second",
+			"tags":   []string{"rust"},
+			"scores": []int32{85},
+			"items":  []map[string]interface{}{{"sku": "A1", "qty": int32(10)}},
+		},
+	} {
+		_, cleanup := writeDataAndReturnCleanupFn(mng.client, "servflow", "orders", doc)
+		t.Cleanup(cleanup)
+	}
+
+	names := func(f filters.Filter) []string {
+		fetched, err := mng.Fetch(context.Background(), map[string]string{collectionOption: "orders"}, f)
+		require.NoError(t, err)
+		var out []string
+		for _, doc := range fetched {
+			out = append(out, doc["name"].(string))
+		}
+		return out
+	}
+
+	// An array of scalars containing a value.
+	assert.Equal(t, []string{"first"}, names(filters.Filter{Field: "tags", Operation: filters.ElemMatch, Comparator: "go"}))
+
+	// A single element must satisfy every condition: "first" has a score
+	// above 80 and one below 90, but none in between.
+	assert.Equal(t, []string{"second"}, names(filters.Filter{Field: "scores", Operation: filters.ElemMatch, Comparator: []interface{}{
+		map[string]interface{}{"operation": ">=", "comparator": 80},
+		map[string]interface{}{"operation": "<", "comparator": 90},
+	}}))
+
+	// Sub-conditions on fields of embedded documents apply to the same
+	// element: only "second" has an A1 line with qty above 5.
+	assert.Equal(t, []string{"second"}, names(filters.Filter{Field: "items", Operation: filters.ElemMatch, Comparator: []interface{}{
+		map[string]interface{}{"field": "sku", "operation": "==", "comparator": "A1"},
+		map[string]interface{}{"field": "qty", "operation": ">", "comparator": 5},
+	}}))
+}
+
+func TestNormalizeID(t *testing.T) {
+	oid := primitive.NewObjectID()
+
+	doc := bson.M{"_id": oid, "name": "a"}
+	require.NoError(t, normalizeID(doc, ""))
+	assert.Equal(t, bson.M{"_id": oid.Hex(), "name": "a"}, doc)
+
+	doc = bson.M{"_id": oid, "name": "a"}
+	require.NoError(t, normalizeID(doc, IDFormatID))
+	assert.Equal(t, bson.M{"id": oid.Hex(), "name": "a"}, doc)
+
+	doc = bson.M{"_id": oid}
+	require.NoError(t, normalizeID(doc, IDFormatRaw))
+	assert.Equal(t, oid, doc["_id"])
+
+	// Non-ObjectID ids are kept as they are.
+	doc = bson.M{"_id": "custom"}
+	require.NoError(t, normalizeID(doc, IDFormatID))
+	assert.Equal(t, bson.M{"id": "custom"}, doc)
+
+	assert.Error(t, normalizeID(bson.M{}, "hex"))
+}
+
+func TestMongo_FetchIDFormat(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+	require.NoError(t, err)
+
+	insertedID, cleanup := writeDataAndReturnCleanupFn(mng.client, "servflow", "users", map[string]interface{}{"name": "ada"})
+	t.Cleanup(cleanup)
+	hex := insertedID.(primitive.ObjectID).Hex()
+
+	fetched, err := mng.Fetch(context.Background(), map[string]string{collectionOption: "users"})
+	require.NoError(t, err)
+	require.Len(t, fetched, 1)
+	assert.Equal(t, hex, fetched[0]["_id"])
+
+	// The normalized document marshals to plain JSON, ready for a response.
+	out, err := json.Marshal(fetched[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"_id":"`+hex+`","name":"ada"}`, string(out))
+
+	fetched, err = mng.Fetch(context.Background(), map[string]string{collectionOption: "users", idFormatOption: IDFormatID})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": hex, "name": "ada"}, fetched[0])
+
+	results, err := mng.ExecuteQuery(context.Background(), "users", "", "", QueryOptions{IDFormat: IDFormatRaw})
+	require.NoError(t, err)
+	assert.Equal(t, insertedID, results[0]["_id"])
+
+	_, err = mng.ExecuteQuery(context.Background(), "users", "", "", QueryOptions{IDFormat: "hex"})
+	assert.ErrorContains(t, err, "invalid id format")
 }
 
 func TestMongo_Store(t *testing.T) {
@@ -326,6 +638,48 @@ func TestMongo_Store(t *testing.T) {
 	}))
 }
 
+func TestMongo_StoreMany(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+	require.NoError(t, err)
+
+	items := make([]map[string]interface{}, 25)
+	for i := range items {
+		items[i] = map[string]interface{}{"seq": int32(i), "name": fmt.Sprintf("user-%d", i)}
+	}
+	require.NoError(t, mng.StoreMany(context.Background(), items, map[string]string{collectionOption: "imports"}))
+	require.NoError(t, mng.StoreMany(context.Background(), nil, map[string]string{collectionOption: "imports"}))
+
+	count, err := mng.client.Database("servflow").Collection("imports").CountDocuments(context.Background(), bson.M{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 25, count)
+}
+
+func TestMongo_StoreUpsert(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+	require.NoError(t, err)
+
+	opts := map[string]string{collectionOption: "upserts", filters.UpsertOnOption: "tenant,email"}
+	require.NoError(t, mng.Store(context.Background(), map[string]interface{}{"tenant": "t1", "email": "a@test.com", "name": "Before"}, opts))
+	require.NoError(t, mng.Store(context.Background(), map[string]interface{}{"tenant": "t1", "email": "a@test.com", "name": "After"}, opts))
+	require.NoError(t, mng.Store(context.Background(), map[string]interface{}{"tenant": "t2", "email": "a@test.com", "name": "Other"}, opts))
+
+	coll := mng.client.Database("servflow").Collection("upserts")
+	count, err := coll.CountDocuments(context.Background(), bson.M{"tenant": "t1"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	var got map[string]interface{}
+	require.NoError(t, coll.FindOne(context.Background(), bson.M{"tenant": "t1"}).Decode(&got))
+	assert.Equal(t, "After", got["name"])
+
+	err = mng.Store(context.Background(), map[string]interface{}{"tenant": "t1", "name": "No email"}, opts)
+	assert.ErrorContains(t, err, "item has no email")
+}
+
 func TestMongo_Update(t *testing.T) {
 	runUpdate := func(initialDoc, expected map[string]interface{}, updateFields map[string]interface{}, filters ...filters.Filter) func(t *testing.T) {
 		return func(t *testing.T) {
@@ -435,6 +789,113 @@ func TestMongo_Update(t *testing.T) {
 	}))
 }
 
+func TestBuildUpdate(t *testing.T) {
+	update, err := buildUpdate(map[string]interface{}{
+		"name":   "ada",
+		"$set":   map[string]interface{}{"email": "ada@example.com"},
+		"$inc":   map[string]interface{}{"logins": 1},
+		"$push":  map[string]interface{}{"tags": "new"},
+		"$pull":  map[string]interface{}{"roles": "guest"},
+		"$unset": map[string]interface{}{"temp": ""},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{
+		"$set":   bson.M{"name": "ada", "email": "ada@example.com"},
+		"$inc":   map[string]interface{}{"logins": 1},
+		"$push":  map[string]interface{}{"tags": "new"},
+		"$pull":  map[string]interface{}{"roles": "guest"},
+		"$unset": map[string]interface{}{"temp": ""},
+	}, update)
+
+	update, err = buildUpdate(map[string]interface{}{"$inc": map[string]interface{}{"count": 1}})
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"$inc": map[string]interface{}{"count": 1}}, update)
+
+	_, err = buildUpdate(map[string]interface{}{"$rename": map[string]interface{}{"a": "b"}})
+	assert.ErrorContains(t, err, "unsupported update operator")
+
+	_, err = buildUpdate(map[string]interface{}{"$inc": 1})
+	assert.ErrorContains(t, err, "expects an object")
+}
+
+func TestMongo_UpdateOperators(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+	require.NoError(t, err)
+
+	docID, cleanup := writeDataAndReturnCleanupFn(mng.client, "servflow", "users", map[string]interface{}{
+		"name":   "ada",
+		"logins": int32(2),
+		"tags":   []string{"a"},
+		"temp":   "x",
+	})
+	t.Cleanup(cleanup)
+
+	_, err = mng.Update(context.Background(), map[string]interface{}{
+		"name":   "ada l",
+		"$inc":   map[string]interface{}{"logins": int32(3)},
+		"$push":  map[string]interface{}{"tags": "b"},
+		"$unset": map[string]interface{}{"temp": ""},
+	}, map[string]string{collectionOption: "users"}, filters.Filter{Field: "name", Operation: filters.Equals, Comparator: "ada"})
+	require.NoError(t, err)
+
+	_, err = mng.Update(context.Background(), map[string]interface{}{
+		"$pull": map[string]interface{}{"tags": "a"},
+	}, map[string]string{collectionOption: "users"}, filters.Filter{Field: "name", Operation: filters.Equals, Comparator: "ada l"})
+	require.NoError(t, err)
+
+	var got bson.M
+	err = mng.client.Database("servflow").Collection("users").FindOne(context.Background(), bson.M{"_id": docID}).Decode(&got)
+	require.NoError(t, err)
+	delete(got, "_id")
+	assert.Equal(t, bson.M{"name": "ada l", "logins": int32(5), "tags": bson.A{"b"}}, got)
+}
+
+func TestMongo_UpdateVersion(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+	require.NoError(t, err)
+
+	docID, cleanup := writeDataAndReturnCleanupFn(mng.client, "servflow", "users", map[string]interface{}{
+		"name":    "ada",
+		"version": int32(1),
+	})
+	t.Cleanup(cleanup)
+
+	byName := filters.Filter{Field: "name", Operation: filters.Equals, Comparator: "ada"}
+	update := func(version string, fields map[string]interface{}) error {
+		_, err := mng.Update(context.Background(), fields,
+			map[string]string{collectionOption: "users", filters.VersionOption: version}, byName)
+		return err
+	}
+
+	require.NoError(t, update("1", map[string]interface{}{"email": "ada@test.com"}))
+
+	// The first writer bumped the version, so a second writer still holding
+	// version 1 must not clobber its change.
+	err = update("1", map[string]interface{}{"email": "stale@test.com"})
+	assert.ErrorIs(t, err, filters.ErrVersionConflict)
+
+	require.NoError(t, update("2", map[string]interface{}{"$inc": map[string]interface{}{"logins": 1}}))
+
+	var got bson.M
+	err = mng.client.Database("servflow").Collection("users").FindOne(context.Background(), bson.M{"_id": docID}).Decode(&got)
+	require.NoError(t, err)
+	assert.Equal(t, "ada@test.com", got["email"])
+	assert.EqualValues(t, 3, got["version"])
+	assert.EqualValues(t, 1, got["logins"])
+
+	_, err = mng.Update(context.Background(), map[string]interface{}{"email": "x"},
+		map[string]string{collectionOption: "users", filters.VersionOption: "3"},
+		filters.Filter{Field: "name", Operation: filters.Equals, Comparator: "missing"})
+	assert.ErrorIs(t, err, filters.ErrNoMatch)
+
+	assert.Error(t, update("3", map[string]interface{}{"version": 10}))
+	assert.Error(t, update("not-a-number", map[string]interface{}{"email": "x"}))
+}
+
 func TestMongo_Delete(t *testing.T) {
 	t.Parallel()
 	runDelete := func(initialDocs []map[string]interface{}, deleteFilters []filters.Filter, expectedRemaining int) func(t *testing.T) {
@@ -463,7 +924,11 @@ func TestMongo_Delete(t *testing.T) {
 			})
 
 			// Execute the delete operation
-			err = mng.Delete(context.Background(), map[string]string{collectionOption: "users"}, deleteFilters...)
+			// These cases delete everything deliberately; see TestMongo_DeleteSafety.
+			err = mng.Delete(context.Background(), map[string]string{
+				collectionOption:              "users",
+				filters.AllowFullDeleteOption: "true",
+			}, deleteFilters...)
 			require.NoError(t, err)
 
 			// Verify the remaining documents count
@@ -563,3 +1028,197 @@ func TestMongo_Delete(t *testing.T) {
 		2,
 	))
 }
+
+func TestMongo_DeleteSafety(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{ConnectionString: uri, DBName: "servflow"})
+	require.NoError(t, err)
+
+	coll := mng.client.Database("servflow").Collection("batched")
+	docs := make([]interface{}, 0, 7)
+	for i := 0; i < 7; i++ {
+		docs = append(docs, bson.M{"n": i, "keep": i == 6})
+	}
+	_, err = coll.InsertMany(context.Background(), docs)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = coll.Drop(context.Background()) })
+
+	err = mng.Delete(context.Background(), map[string]string{collectionOption: "batched"})
+	assert.ErrorIs(t, err, filters.ErrUnfilteredDelete)
+	count, err := coll.CountDocuments(context.Background(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+
+	err = mng.Delete(context.Background(),
+		map[string]string{collectionOption: "batched", filters.BatchSizeOption: "2"},
+		filters.Filter{Field: "keep", Operation: filters.Equals, Comparator: false})
+	require.NoError(t, err)
+
+	var remaining []bson.M
+	cursor, err := coll.Find(context.Background(), bson.M{})
+	require.NoError(t, err)
+	require.NoError(t, cursor.All(context.Background(), &remaining))
+	require.Len(t, remaining, 1)
+	assert.EqualValues(t, 6, remaining[0]["n"])
+}
+
+func TestParseIndexMigration(t *testing.T) {
+	models, collection, err := parseIndexMigration(`{"collection": "users", "indexes": [
+		{"keys": {"lastName": 1, "firstName": -1}, "name": "by_name"},
+		{"keys": {"email": 1}, "unique": true}
+	]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "users", collection)
+	require.Len(t, models, 2)
+	assert.Equal(t, bson.D{{Key: "lastName", Value: int32(1)}, {Key: "firstName", Value: int32(-1)}}, models[0].Keys)
+	assert.Equal(t, "by_name", *models[0].Options.Name)
+	assert.True(t, *models[1].Options.Unique)
+
+	for name, script := range map[string]string{
+		"not json":      `CREATE INDEX`,
+		"no collection": `{"indexes": [{"keys": {"email": 1}}]}`,
+		"no indexes":    `{"collection": "users"}`,
+		"no keys":       `{"collection": "users", "indexes": [{"name": "empty"}]}`,
+	} {
+		_, _, err := parseIndexMigration(script)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestMongo_Migrations(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	mng, err := newWrapper(Config{ConnectionString: uri, DBName: "migrations"})
+	require.NoError(t, err)
+
+	migrations := []integration.Migration{
+		{Version: "0001_users_email", Script: `{"collection": "users", "indexes": [{"keys": {"email": 1}, "unique": true, "name": "email_unique"}]}`},
+	}
+	ran, err := integration.ApplyMigrationsTo(context.Background(), mng, migrations)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0001_users_email"}, ran)
+
+	restarted, err := newWrapper(Config{ConnectionString: uri, DBName: "migrations"})
+	require.NoError(t, err)
+	ran, err = integration.ApplyMigrationsTo(context.Background(), restarted, migrations)
+	require.NoError(t, err)
+	assert.Empty(t, ran)
+
+	users := mng.client.Database("migrations").Collection("users")
+	_, err = users.InsertOne(context.Background(), bson.M{"email": "ada@test.com"})
+	require.NoError(t, err)
+	_, err = users.InsertOne(context.Background(), bson.M{"email": "ada@test.com"})
+	assert.True(t, mongo.IsDuplicateKeyError(err), "unique index should be in place")
+}
+
+func TestGroupIndexes(t *testing.T) {
+	groups, err := groupIndexes([]IndexSpec{
+		{Collection: "users", Keys: []IndexKey{{Field: "lastName"}, {Field: "firstName", Type: "desc"}}, Name: "by_name"},
+		{Collection: "places", Keys: []IndexKey{{Field: "location", Type: "2dsphere"}}},
+		{Collection: "users", Keys: []IndexKey{{Field: "bio", Type: "text"}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "users", groups[0].collection)
+	require.Len(t, groups[0].models, 2)
+	assert.Equal(t, bson.D{{Key: "lastName", Value: 1}, {Key: "firstName", Value: -1}}, groups[0].models[0].Keys)
+	assert.Equal(t, bson.D{{Key: "bio", Value: "text"}}, groups[0].models[1].Keys)
+	assert.Equal(t, "places", groups[1].collection)
+	assert.Equal(t, bson.D{{Key: "location", Value: "2dsphere"}}, groups[1].models[0].Keys)
+
+	for name, spec := range map[string]IndexSpec{
+		"no collection": {Keys: []IndexKey{{Field: "a"}}},
+		"no keys":       {Collection: "users"},
+		"no field":      {Collection: "users", Keys: []IndexKey{{Type: "text"}}},
+		"bad type":      {Collection: "users", Keys: []IndexKey{{Field: "a", Type: "hashed-ish"}}},
+	} {
+		_, err := groupIndexes([]IndexSpec{spec})
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParseIndexSpecs(t *testing.T) {
+	want := []IndexSpec{{Collection: "users", Keys: []IndexKey{{Field: "email"}}, Unique: true}}
+
+	fromYAML, err := parseIndexSpecs([]interface{}{
+		map[string]interface{}{
+			"collection": "users",
+			"keys":       []interface{}{map[string]interface{}{"field": "email"}},
+			"unique":     true,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want, fromYAML)
+
+	fromForm, err := parseIndexSpecs(`[{"collection": "users", "keys": [{"field": "email"}], "unique": true}]`)
+	require.NoError(t, err)
+	assert.Equal(t, want, fromForm)
+
+	none, err := parseIndexSpecs(nil)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	_, err = parseIndexSpecs("not json")
+	assert.Error(t, err)
+}
+
+func TestMongo_DeclaredIndexes(t *testing.T) {
+	t.Parallel()
+	uri := startMongoContainer(t)
+	cfg := Config{
+		ConnectionString: uri,
+		DBName:           "indexed",
+		Indexes: []IndexSpec{
+			{Collection: "users", Keys: []IndexKey{{Field: "lastName"}, {Field: "firstName", Type: "desc"}}, Name: "by_name", Unique: true},
+			{Collection: "users", Keys: []IndexKey{{Field: "bio", Type: "text"}}, Name: "bio_text"},
+			{Collection: "places", Keys: []IndexKey{{Field: "location", Type: "2dsphere"}}, Name: "location_geo"},
+		},
+	}
+	mng, err := newWrapper(cfg)
+	require.NoError(t, err)
+
+	// Starting again with the same declarations is a no-op.
+	_, err = newWrapper(cfg)
+	require.NoError(t, err)
+
+	indexKeys := func(collection string) map[string]bson.D {
+		cursor, err := mng.client.Database("indexed").Collection(collection).Indexes().List(context.Background())
+		require.NoError(t, err)
+		var specs []struct {
+			Name string `bson:"name"`
+			Key  bson.D `bson:"key"`
+		}
+		require.NoError(t, cursor.All(context.Background(), &specs))
+		keys := make(map[string]bson.D, len(specs))
+		for _, s := range specs {
+			keys[s.Name] = s.Key
+		}
+		return keys
+	}
+
+	users := indexKeys("users")
+	assert.Equal(t, bson.D{{Key: "lastName", Value: int32(1)}, {Key: "firstName", Value: int32(-1)}}, users["by_name"])
+	assert.Contains(t, users, "bio_text")
+	assert.Contains(t, indexKeys("places"), "location_geo")
+}
+
+func TestMongo_ObserveSlowQuery(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	ctx := logging.WithLogger(context.Background(), zap.New(core))
+	filter := bson.D{{Key: "name", Value: "ada"}}
+
+	(&Mongo{slowQuery: time.Hour}).observe(ctx, "fetch", "users", filter, time.Now())
+	(&Mongo{}).observe(ctx, "fetch", "users", filter, time.Now().Add(-time.Hour))
+	assert.Zero(t, logs.Len(), "fast queries and disabled logging must not log")
+
+	(&Mongo{slowQuery: time.Millisecond}).observe(ctx, "fetch", "users", filter, time.Now().Add(-time.Second))
+	entries := logs.FilterMessage("slow query").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "mongo", fields["integration"])
+	assert.Equal(t, "fetch", fields["operation"])
+	assert.Equal(t, "users", fields["collection"])
+	assert.Equal(t, `{"name":"ada"}`, fields["filter"])
+	assert.GreaterOrEqual(t, fields["elapsed"], time.Second)
+}
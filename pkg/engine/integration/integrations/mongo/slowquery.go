@@ -0,0 +1,28 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// observe logs an operation begun at start when it exceeded the slow-query
+// threshold. Call it deferred so the arguments are captured up front:
+//
+//	defer m.observe(ctx, "find", collection, filter, time.Now())
+func (m *Mongo) observe(ctx context.Context, operation, collection string, filter interface{}, start time.Time) {
+	elapsed := time.Since(start)
+	if !integration.IsSlowQuery(m.slowQuery, elapsed) {
+		return
+	}
+	fields := []zap.Field{zap.String("collection", collection)}
+	if filter != nil {
+		if b, err := bson.MarshalExtJSON(filter, false, false); err == nil {
+			fields = append(fields, zap.String("filter", string(b)))
+		}
+	}
+	integration.LogSlowQuery(ctx, "mongo", operation, elapsed, fields...)
+}
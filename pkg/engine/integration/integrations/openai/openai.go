@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Servflow/servflow/pkg/agent"
 	"github.com/Servflow/servflow/pkg/engine/integration"
@@ -20,12 +22,39 @@ type Config struct {
 	APIKey         string `json:"api_key"`
 	OrganizationID string `json:"organization_id"`
 	ModelID        string `json:"model_id"`
+	Sampling
+	Retry Retry `json:"retry"`
+}
+
+// Sampling holds the optional model parameters sent with every request. A nil
+// field is left out of the request so the API default applies.
+type Sampling struct {
+	// Temperature is between 0 and 2.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// TopP is between 0 and 1.
+	TopP            *float64 `json:"top_p,omitempty"`
+	MaxOutputTokens *int64   `json:"max_output_tokens,omitempty"`
+}
+
+func (s Sampling) validate() error {
+	if s.Temperature != nil && (*s.Temperature < 0 || *s.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %v", *s.Temperature)
+	}
+	if s.TopP != nil && (*s.TopP < 0 || *s.TopP > 1) {
+		return fmt.Errorf("top_p must be between 0 and 1, got %v", *s.TopP)
+	}
+	if s.MaxOutputTokens != nil && *s.MaxOutputTokens <= 0 {
+		return fmt.Errorf("max_output_tokens must be positive, got %d", *s.MaxOutputTokens)
+	}
+	return nil
 }
 
 type Client struct {
 	integration.BaseIntegration
-	client *openai.Client
-	model  string
+	client   *openai.Client
+	model    string
+	sampling Sampling
+	retry    Retry
 }
 
 func (c *Client) Type() string {
@@ -34,33 +63,51 @@ func (c *Client) Type() string {
 
 var defaultModel = "gpt-4.1"
 
-func New(apiKey string, model string) (*Client, error) {
-	if apiKey == "" {
+func New(cfg Config) (*Client, error) {
+	if cfg.APIKey == "" {
 		return nil, errors.New("no API key provided")
 	}
+	if err := cfg.Sampling.validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Retry.validate(); err != nil {
+		return nil, err
+	}
 
+	model := cfg.ModelID
 	if model == "" {
 		model = defaultModel
 	}
 
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+	// Retries are made by Client so they follow cfg.Retry.
+	opts := []option.RequestOption{option.WithAPIKey(cfg.APIKey), option.WithMaxRetries(0)}
+	if cfg.OrganizationID != "" {
+		opts = append(opts, option.WithOrganization(cfg.OrganizationID))
+	}
+	client := openai.NewClient(opts...)
 
 	return &Client{
-		client: &client,
-		model:  model,
+		client:   &client,
+		model:    model,
+		sampling: cfg.Sampling,
+		retry:    cfg.Retry.withDefaults(),
 	}, nil
 }
 
 func (c *Client) ProvideResponse(ctx context.Context, agentReq agent.LLMRequest) (resp agent.LLMResponse, err error) {
 	logger := logging.WithContextEnriched(ctx)
 
-	params := convertAgentRequestToSDKParams(logger, &agentReq, c.model)
+	params := convertAgentRequestToSDKParams(logger, &agentReq, c.model, c.sampling)
 
 	ctx, inf := tracing.StartInference(ctx, "openai", c.model)
 	defer func() { inf.End(ctx, err) }()
 	inf.SetInput(buildSystemInstructions(agentReq.SystemMessage, agentReq.Instruction), agent.TraceMessages(agentReq.Messages))
 
-	response, err := c.client.Responses.New(ctx, params)
+	var response *responses.Response
+	err = c.retry.do(ctx, logger, func() (err error) {
+		response, err = c.client.Responses.New(ctx, params)
+		return err
+	})
 	if err != nil {
 		logger.Error("error from openai", zap.Error(err))
 		return
@@ -101,6 +148,37 @@ func init() {
 			Required:    false,
 			Default:     defaultModel,
 		},
+		"max_attempts": {
+			Type:        integration.FieldTypeNumber,
+			Label:       "Max Attempts",
+			Placeholder: "Attempts per request, retries included",
+			Required:    false,
+			Default:     defaultMaxAttempts,
+		},
+		"retry_base_delay": {
+			Type:        integration.FieldTypeString,
+			Label:       "Retry Base Delay",
+			Placeholder: "500ms",
+			Required:    false,
+		},
+		"temperature": {
+			Type:        integration.FieldTypeNumber,
+			Label:       "Temperature",
+			Placeholder: "0 to 2",
+			Required:    false,
+		},
+		"top_p": {
+			Type:        integration.FieldTypeNumber,
+			Label:       "Top P",
+			Placeholder: "0 to 1",
+			Required:    false,
+		},
+		"max_output_tokens": {
+			Type:        integration.FieldTypeNumber,
+			Label:       "Max Output Tokens",
+			Placeholder: "Upper bound on generated tokens",
+			Required:    false,
+		},
 	}
 
 	if err := integration.RegisterIntegration("openai", integration.RegistrationInfo{
@@ -117,18 +195,57 @@ func init() {
 			if !ok {
 				model = defaultModel
 			}
-			return New(apikey, model)
+			cfg := Config{APIKey: apikey, ModelID: model}
+			cfg.OrganizationID, _ = m["organization_id"].(string)
+
+			var err error
+			if cfg.Temperature, err = floatField(m, "temperature"); err != nil {
+				return nil, err
+			}
+			if cfg.TopP, err = floatField(m, "top_p"); err != nil {
+				return nil, err
+			}
+			maxTokens, err := floatField(m, "max_output_tokens")
+			if err != nil {
+				return nil, err
+			}
+			if maxTokens != nil {
+				n := int64(*maxTokens)
+				cfg.MaxOutputTokens = &n
+			}
+			maxAttempts, err := floatField(m, "max_attempts")
+			if err != nil {
+				return nil, err
+			}
+			if maxAttempts != nil {
+				cfg.Retry.MaxAttempts = int(*maxAttempts)
+			}
+			if v, _ := m["retry_base_delay"].(string); v != "" {
+				if cfg.Retry.BaseDelay, err = time.ParseDuration(v); err != nil {
+					return nil, fmt.Errorf("invalid retry_base_delay %q: %w", v, err)
+				}
+			}
+			return New(cfg)
 		},
 	}); err != nil {
 		panic(err)
 	}
 }
 
-func convertAgentRequestToSDKParams(logger *zap.Logger, req *agent.LLMRequest, model string) responses.ResponseNewParams {
+func convertAgentRequestToSDKParams(logger *zap.Logger, req *agent.LLMRequest, model string, sampling Sampling) responses.ResponseNewParams {
 	params := responses.ResponseNewParams{
 		Model:        model,
 		Instructions: openai.String(req.SystemMessage),
 	}
+	if sampling.Temperature != nil {
+		params.Temperature = openai.Float(*sampling.Temperature)
+	}
+	if sampling.TopP != nil {
+		params.TopP = openai.Float(*sampling.TopP)
+	}
+	if sampling.MaxOutputTokens != nil {
+		params.MaxOutputTokens = openai.Int(*sampling.MaxOutputTokens)
+	}
 
 	inputItems := make([]responses.ResponseInputItemUnionParam, 0)
 	if req.Instruction != "" {
@@ -22,10 +22,12 @@ import (
 
 func TestNew(t *testing.T) {
 	tests := []struct {
-		name    string
-		apiKey  string
-		model   string
-		wantErr bool
+		name     string
+		apiKey   string
+		model    string
+		sampling Sampling
+		retry    Retry
+		wantErr  bool
 	}{
 		{
 			name:    "valid config with model",
@@ -45,11 +47,40 @@ func TestNew(t *testing.T) {
 			model:   "",
 			wantErr: true,
 		},
+		{
+			name:     "valid sampling",
+			apiKey:   "test-key",
+			sampling: Sampling{Temperature: openai.Ptr(2.0), TopP: openai.Ptr(0.0), MaxOutputTokens: openai.Ptr(int64(256))},
+		},
+		{
+			name:     "temperature out of range",
+			apiKey:   "test-key",
+			sampling: Sampling{Temperature: openai.Ptr(2.5)},
+			wantErr:  true,
+		},
+		{
+			name:     "top_p out of range",
+			apiKey:   "test-key",
+			sampling: Sampling{TopP: openai.Ptr(-0.1)},
+			wantErr:  true,
+		},
+		{
+			name:    "negative max attempts",
+			apiKey:  "test-key",
+			retry:   Retry{MaxAttempts: -1},
+			wantErr: true,
+		},
+		{
+			name:     "max_output_tokens not positive",
+			apiKey:   "test-key",
+			sampling: Sampling{MaxOutputTokens: openai.Ptr(int64(0))},
+			wantErr:  true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			client, err := New(tt.apiKey, tt.model)
+			client, err := New(Config{APIKey: tt.apiKey, ModelID: tt.model, Sampling: tt.sampling, Retry: tt.retry})
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, client)
@@ -276,7 +307,7 @@ func TestConvertAgentRequestToSDKParams(t *testing.T) {
 			Tools: []agent.ToolInfo{},
 		}
 
-		params := convertAgentRequestToSDKParams(logger, req, "gpt-4")
+		params := convertAgentRequestToSDKParams(logger, req, "gpt-4", Sampling{})
 
 		assert.Equal(t, "gpt-4", params.Model)
 		assert.Equal(t, "You are a helpful assistant.", params.Instructions.Value)
@@ -320,7 +351,7 @@ func TestConvertAgentRequestToSDKParams(t *testing.T) {
 			},
 		}
 
-		params := convertAgentRequestToSDKParams(logger, req, "gpt-4")
+		params := convertAgentRequestToSDKParams(logger, req, "gpt-4", Sampling{})
 
 		assert.Equal(t, "gpt-4", params.Model)
 		require.Len(t, params.Tools, 1)
@@ -368,7 +399,7 @@ func TestConvertAgentRequestToSDKParams(t *testing.T) {
 			},
 		}
 
-		params := convertAgentRequestToSDKParams(logger, req, "gpt-4")
+		params := convertAgentRequestToSDKParams(logger, req, "gpt-4", Sampling{})
 
 		require.Len(t, params.Tools, 2)
 		assert.Equal(t, "get_weather", params.Tools[0].OfFunction.Name)
@@ -385,7 +416,7 @@ func TestConvertAgentRequestToSDKParams(t *testing.T) {
 			Tools:         []agent.ToolInfo{},
 		}
 
-		params := convertAgentRequestToSDKParams(logger, req, defaultModel)
+		params := convertAgentRequestToSDKParams(logger, req, defaultModel, Sampling{})
 
 		assert.Equal(t, defaultModel, params.Model)
 		assert.Equal(t, "", params.Instructions.Value)
@@ -405,7 +436,7 @@ func TestConvertAgentRequestToSDKParams(t *testing.T) {
 			},
 		}
 
-		params := convertAgentRequestToSDKParams(logger, req, "gpt-4")
+		params := convertAgentRequestToSDKParams(logger, req, "gpt-4", Sampling{})
 
 		require.Len(t, params.Input.OfInputItemList, 2)
 		require.NotNil(t, params.Input.OfInputItemList[0].OfMessage)
@@ -414,6 +445,29 @@ func TestConvertAgentRequestToSDKParams(t *testing.T) {
 		require.NotNil(t, params.Input.OfInputItemList[1].OfMessage)
 		assert.Equal(t, responses.EasyInputMessageRole("user"), params.Input.OfInputItemList[1].OfMessage.Role)
 	})
+
+	t.Run("sampling parameters are emitted only when set", func(t *testing.T) {
+		req := &agent.LLMRequest{
+			Messages: []any{agent.MessageTypeContent{Role: agent.RoleTypeUser, Content: "Hello"}},
+		}
+
+		body, err := json.Marshal(convertAgentRequestToSDKParams(logger, req, "gpt-4", Sampling{}))
+		require.NoError(t, err)
+		var unset map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &unset))
+		assert.NotContains(t, unset, "temperature")
+		assert.NotContains(t, unset, "top_p")
+		assert.NotContains(t, unset, "max_output_tokens")
+
+		sampling := Sampling{Temperature: openai.Ptr(0.0), TopP: openai.Ptr(0.9), MaxOutputTokens: openai.Ptr(int64(512))}
+		body, err = json.Marshal(convertAgentRequestToSDKParams(logger, req, "gpt-4", sampling))
+		require.NoError(t, err)
+		var set map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &set))
+		assert.Equal(t, 0.0, set["temperature"])
+		assert.Equal(t, 0.9, set["top_p"])
+		assert.Equal(t, 512.0, set["max_output_tokens"])
+	})
 }
 
 func TestBuildMessageInput(t *testing.T) {
@@ -947,7 +1001,7 @@ func TestCompleteConversationFlow(t *testing.T) {
 		Tools: []agent.ToolInfo{},
 	}
 
-	params := convertAgentRequestToSDKParams(logger, req, "gpt-4")
+	params := convertAgentRequestToSDKParams(logger, req, "gpt-4", Sampling{})
 
 	assert.Equal(t, "gpt-4", params.Model)
 	assert.Equal(t, "Handle complete conversation flow.", params.Instructions.Value)
@@ -1002,3 +1056,23 @@ func TestCompleteConversationFlow(t *testing.T) {
 	require.Len(t, assistantMsg.OfOutputMessage.Content, 1)
 	assert.Equal(t, "Weather retrieved for both cities.", assistantMsg.OfOutputMessage.Content[0].OfOutputText.Text)
 }
+
+func TestFloatField(t *testing.T) {
+	m := map[string]any{"json": 0.7, "yaml": 2, "string": "0.5", "empty": "", "bad": "hot", "wrong": true}
+
+	for key, expected := range map[string]float64{"json": 0.7, "yaml": 2, "string": 0.5} {
+		v, err := floatField(m, key)
+		require.NoError(t, err, key)
+		require.NotNil(t, v, key)
+		assert.Equal(t, expected, *v, key)
+	}
+	for _, key := range []string{"missing", "empty"} {
+		v, err := floatField(m, key)
+		require.NoError(t, err, key)
+		assert.Nil(t, v, key)
+	}
+	for _, key := range []string{"bad", "wrong"} {
+		_, err := floatField(m, key)
+		assert.ErrorContains(t, err, "must be a number", key)
+	}
+}
@@ -0,0 +1,117 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultBaseDelay   = 500 * time.Millisecond
+)
+
+// retryStatusCodes are the API responses that are retried. Other 4xx fail at
+// once.
+var retryStatusCodes = []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}
+
+// Retry controls how failed API calls are sent again. Calls answered with 429,
+// 500, 502 or 503 are retried, as are network errors.
+type Retry struct {
+	// MaxAttempts is the total number of attempts, the first included.
+	// Defaults to 3.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// BaseDelay is the wait before the first retry, doubled for each retry
+	// after it. A Retry-After header on the response takes precedence.
+	// Defaults to 500ms.
+	BaseDelay time.Duration `json:"base_delay,omitempty"`
+}
+
+func (r Retry) validate() error {
+	if r.MaxAttempts < 0 {
+		return fmt.Errorf("max_attempts must not be negative, got %d", r.MaxAttempts)
+	}
+	if r.BaseDelay < 0 {
+		return fmt.Errorf("retry base_delay must not be negative, got %s", r.BaseDelay)
+	}
+	return nil
+}
+
+func (r Retry) withDefaults() Retry {
+	if r.MaxAttempts == 0 {
+		r.MaxAttempts = defaultMaxAttempts
+	}
+	if r.BaseDelay == 0 {
+		r.BaseDelay = defaultBaseDelay
+	}
+	return r
+}
+
+// do calls fn until it succeeds, fails with an error that is not retriable or
+// runs out of attempts, and returns its last error. Waits between attempts end
+// early when ctx is canceled.
+func (r Retry) do(ctx context.Context, logger *zap.Logger, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= r.MaxAttempts || !retriable(ctx, err) {
+			return err
+		}
+
+		delay := r.delay(attempt, err)
+		logger.Debug("retrying openai request", zap.Int("attempt", attempt), zap.Duration("delay", delay), zap.Error(err))
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// delay is the wait before retry number attempt: the Retry-After of the
+// failed response when it has one, else the exponential backoff.
+func (r Retry) delay(attempt int, err error) time.Duration {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) && apiErr.Response != nil {
+		if d, ok := parseRetryAfter(apiErr.Response.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+	return r.BaseDelay << (attempt - 1)
+}
+
+// retriable reports whether err is an API error with a retried status code or
+// a network error. Cancellation of the request itself never is.
+func retriable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return slices.Contains(retryStatusCodes, apiErr.StatusCode)
+	}
+	return true
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or as an
+// HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,116 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/agent"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyServer answers the first len(failures) requests with the given
+// status codes and every request after them with a text response.
+func newFlakyServer(t *testing.T, retryAfter string, failures ...int) (*Client, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		w.Header().Set("Content-Type", "application/json")
+		if n <= len(failures) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(failures[n-1])
+			w.Write([]byte(`{"error": {"message": "try again", "type": "server_error"}}`))
+			return
+		}
+		w.Write([]byte(`{"output": [{"type": "message", "content": [{"type": "output_text", "text": "hello"}]}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	sdkClient := openai.NewClient(option.WithAPIKey("test-api-key"), option.WithBaseURL(server.URL), option.WithMaxRetries(0))
+	return &Client{client: &sdkClient, model: "gpt-4", retry: Retry{MaxAttempts: 3, BaseDelay: time.Millisecond}}, &calls
+}
+
+func TestClient_ProvideResponseRetry(t *testing.T) {
+	req := agent.LLMRequest{Messages: []any{agent.MessageTypeContent{Role: agent.RoleTypeUser, Content: "hi"}}}
+
+	t.Run("succeeds after two failures", func(t *testing.T) {
+		client, calls := newFlakyServer(t, "", http.StatusServiceUnavailable, http.StatusTooManyRequests)
+
+		resp, err := client.ProvideResponse(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", resp.Text())
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		client, calls := newFlakyServer(t, "", http.StatusInternalServerError, http.StatusBadGateway, http.StatusInternalServerError)
+
+		_, err := client.ProvideResponse(context.Background(), req)
+		var apiErr *openai.Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("other 4xx fail fast", func(t *testing.T) {
+		client, calls := newFlakyServer(t, "", http.StatusBadRequest)
+
+		_, err := client.ProvideResponse(context.Background(), req)
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("honors Retry-After", func(t *testing.T) {
+		client, calls := newFlakyServer(t, "1", http.StatusTooManyRequests)
+
+		start := time.Now()
+		_, err := client.ProvideResponse(context.Background(), req)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("cancellation stops the wait", func(t *testing.T) {
+		client, calls := newFlakyServer(t, "60", http.StatusServiceUnavailable)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := client.ProvideResponse(ctx, req)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("network errors are retried", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		sdkClient := openai.NewClient(option.WithAPIKey("test-api-key"), option.WithBaseURL(server.URL), option.WithMaxRetries(0))
+		client := &Client{client: &sdkClient, model: "gpt-4", retry: Retry{MaxAttempts: 2, BaseDelay: time.Millisecond}}
+
+		_, err := client.ProvideResponse(context.Background(), req)
+		assert.Error(t, err)
+		assert.True(t, retriable(context.Background(), err))
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	d, ok := parseRetryAfter("3")
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+
+	d, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Zero(t, d)
+
+	for _, v := range []string{"", "soon", "-1"} {
+		_, ok = parseRetryAfter(v)
+		assert.False(t, ok, v)
+	}
+}
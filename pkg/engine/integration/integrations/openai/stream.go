@@ -0,0 +1,209 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/agent"
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/Servflow/servflow/pkg/tracing"
+	"github.com/openai/openai-go/v3/packages/ssestream"
+	"github.com/openai/openai-go/v3/responses"
+	"go.uber.org/zap"
+)
+
+// ProvideResponseStream is ProvideResponse with the output streamed: text and
+// tool call deltas are sent on the returned channel as they arrive, then a
+// final chunk holding the complete response. The channel is closed after the
+// final chunk, or after a chunk with Err set when the stream fails. Cancelling
+// ctx stops the stream.
+func (c *Client) ProvideResponseStream(ctx context.Context, agentReq agent.LLMRequest) (<-chan agent.StreamChunk, error) {
+	logger := logging.WithContextEnriched(ctx)
+
+	params := convertAgentRequestToSDKParams(logger, &agentReq, c.model, c.sampling)
+
+	ctx, inf := tracing.StartInference(ctx, "openai", c.model)
+	inf.SetInput(buildSystemInstructions(agentReq.SystemMessage, agentReq.Instruction), agent.TraceMessages(agentReq.Messages))
+
+	var stream *ssestream.Stream[responses.ResponseStreamEventUnion]
+	err := c.retry.do(ctx, logger, func() error {
+		stream = c.client.Responses.NewStreaming(ctx, params)
+		return stream.Err()
+	})
+	if err != nil {
+		logger.Error("error from openai", zap.Error(err))
+		inf.End(ctx, err)
+		return nil, err
+	}
+
+	chunks := make(chan agent.StreamChunk)
+	go func() {
+		var err error
+		defer close(chunks)
+		defer func() { inf.End(ctx, err) }()
+		defer stream.Close()
+
+		send := func(chunk agent.StreamChunk) bool {
+			select {
+			case chunks <- chunk:
+				return true
+			case <-ctx.Done():
+				err = ctx.Err()
+				return false
+			}
+		}
+
+		acc := newStreamAccumulator()
+		for err == nil && stream.Next() {
+			event := stream.Current()
+			switch event.Type {
+			case "response.output_text.delta":
+				acc.addText(event.OutputIndex, event.ContentIndex, event.Delta)
+				send(agent.StreamChunk{Text: event.Delta})
+			case "response.output_item.added":
+				if event.Item.Type != "function_call" {
+					continue
+				}
+				call := event.Item.AsFunctionCall()
+				acc.startToolCall(event.OutputIndex, call.CallID, call.Name)
+				send(agent.StreamChunk{ToolCall: &agent.ToolCallDelta{ToolID: call.CallID, Name: call.Name}})
+			case "response.function_call_arguments.delta":
+				toolID := acc.addArguments(event.OutputIndex, event.Delta)
+				send(agent.StreamChunk{ToolCall: &agent.ToolCallDelta{ToolID: toolID, Arguments: event.Delta}})
+			case "response.function_call_arguments.done":
+				acc.setArguments(event.OutputIndex, event.Arguments)
+			case "response.completed":
+				inf.SetResponseModel(string(event.Response.Model))
+				acc.setUsage(event.Response.Usage)
+			case "response.failed":
+				err = fmt.Errorf("openai response failed: %s", event.Response.Error.Message)
+			}
+		}
+		if err == nil {
+			err = stream.Err()
+		}
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				logger.Error("error from openai stream", zap.Error(err))
+			}
+			send(agent.StreamChunk{Err: err})
+			return
+		}
+
+		resp := acc.response(logger)
+		inf.RecordUsage(ctx, resp.Usage.InputTokens, resp.Usage.OutputTokens)
+		inf.SetCompletion(resp.Text())
+		send(agent.StreamChunk{Done: true, Response: &resp})
+	}()
+	return chunks, nil
+}
+
+// streamAccumulator rebuilds a complete response from stream events. Output
+// items are kept by their index so the response lists them in the same order
+// as a non-streamed one.
+type streamAccumulator struct {
+	items map[int64]*streamItem
+	usage agent.Usage
+}
+
+type streamItem struct {
+	// text holds the output_text parts of a message, by content index
+	text map[int64]*strings.Builder
+
+	isToolCall bool
+	toolID     string
+	name       string
+	arguments  strings.Builder
+}
+
+func newStreamAccumulator() *streamAccumulator {
+	return &streamAccumulator{items: make(map[int64]*streamItem)}
+}
+
+func (a *streamAccumulator) item(index int64) *streamItem {
+	it, ok := a.items[index]
+	if !ok {
+		it = &streamItem{text: make(map[int64]*strings.Builder)}
+		a.items[index] = it
+	}
+	return it
+}
+
+func (a *streamAccumulator) addText(outputIndex, contentIndex int64, delta string) {
+	it := a.item(outputIndex)
+	b, ok := it.text[contentIndex]
+	if !ok {
+		b = &strings.Builder{}
+		it.text[contentIndex] = b
+	}
+	b.WriteString(delta)
+}
+
+func (a *streamAccumulator) startToolCall(index int64, toolID, name string) {
+	it := a.item(index)
+	it.isToolCall = true
+	it.toolID = toolID
+	it.name = name
+}
+
+// addArguments appends a fragment of a tool call's arguments and returns the
+// call's tool id.
+func (a *streamAccumulator) addArguments(index int64, delta string) string {
+	it := a.item(index)
+	it.arguments.WriteString(delta)
+	return it.toolID
+}
+
+// setArguments replaces the arguments gathered from deltas with the complete
+// ones sent when the call is done.
+func (a *streamAccumulator) setArguments(index int64, arguments string) {
+	it := a.item(index)
+	it.arguments.Reset()
+	it.arguments.WriteString(arguments)
+}
+
+func (a *streamAccumulator) setUsage(u responses.ResponseUsage) {
+	a.usage = agent.Usage{
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+		TotalTokens:  u.TotalTokens,
+	}
+	if a.usage.TotalTokens == 0 {
+		a.usage.TotalTokens = a.usage.InputTokens + a.usage.OutputTokens
+	}
+}
+
+func (a *streamAccumulator) response(logger *zap.Logger) agent.LLMResponse {
+	r := agent.LLMResponse{
+		Content: make([]agent.ContentResponse, 0),
+		Tools:   make([]agent.ToolResponseObject, 0),
+		Usage:   a.usage,
+	}
+	for _, index := range sortedIndexes(a.items) {
+		it := a.items[index]
+		if it.isToolCall {
+			r.Tools = append(r.Tools, agent.ToolResponseObject{
+				Name:   it.name,
+				ToolID: it.toolID,
+				Input:  unmarshalArguments(logger, it.arguments.String()),
+			})
+			continue
+		}
+		for _, ci := range sortedIndexes(it.text) {
+			r.Content = append(r.Content, agent.ContentResponse{Text: it.text[ci].String()})
+		}
+	}
+	return r
+}
+
+func sortedIndexes[V any](m map[int64]V) []int64 {
+	keys := make([]int64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
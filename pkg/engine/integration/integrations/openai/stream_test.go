@@ -0,0 +1,153 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/agent"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamServer(t *testing.T, events []string) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var reqBody map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &reqBody))
+		assert.Equal(t, true, reqBody["stream"])
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		for _, e := range events {
+			var typed struct {
+				Type string `json:"type"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(e), &typed))
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typed.Type, e)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	sdkClient := openai.NewClient(option.WithAPIKey("test-api-key"), option.WithBaseURL(server.URL))
+	return &Client{client: &sdkClient, model: "gpt-4"}
+}
+
+func collectChunks(t *testing.T, ch <-chan agent.StreamChunk) []agent.StreamChunk {
+	var chunks []agent.StreamChunk
+	for c := range ch {
+		chunks = append(chunks, c)
+	}
+	require.NotEmpty(t, chunks)
+	return chunks
+}
+
+func TestClient_ProvideResponseStream(t *testing.T) {
+	request := agent.LLMRequest{
+		SystemMessage: "You are a helpful assistant.",
+		Messages:      []any{agent.MessageTypeContent{Role: agent.RoleTypeUser, Content: "Weather in Paris?"}},
+	}
+
+	t.Run("text and tool call deltas", func(t *testing.T) {
+		client := newStreamServer(t, []string{
+			`{"type":"response.created","response":{"id":"resp_1","output":[]}}`,
+			`{"type":"response.output_item.added","output_index":0,"item":{"type":"message","id":"msg_1","role":"assistant","content":[]}}`,
+			`{"type":"response.output_text.delta","output_index":0,"content_index":0,"item_id":"msg_1","delta":"Let me "}`,
+			`{"type":"response.output_text.delta","output_index":0,"content_index":0,"item_id":"msg_1","delta":"check."}`,
+			`{"type":"response.output_item.added","output_index":1,"item":{"type":"function_call","id":"fc_1","call_id":"call_1","name":"get_weather","arguments":""}}`,
+			`{"type":"response.function_call_arguments.delta","output_index":1,"item_id":"fc_1","delta":"{\"city\":"}`,
+			`{"type":"response.function_call_arguments.delta","output_index":1,"item_id":"fc_1","delta":"\"Paris\"}"}`,
+			`{"type":"response.completed","response":{"id":"resp_1","model":"gpt-4","output":[],"usage":{"input_tokens":12,"output_tokens":8,"total_tokens":20}}}`,
+		})
+
+		ch, err := client.ProvideResponseStream(context.Background(), request)
+		require.NoError(t, err)
+		chunks := collectChunks(t, ch)
+
+		var text strings.Builder
+		var deltas []agent.ToolCallDelta
+		for _, c := range chunks[:len(chunks)-1] {
+			require.NoError(t, c.Err)
+			text.WriteString(c.Text)
+			if c.ToolCall != nil {
+				deltas = append(deltas, *c.ToolCall)
+			}
+		}
+		assert.Equal(t, "Let me check.", text.String())
+		assert.Equal(t, []agent.ToolCallDelta{
+			{ToolID: "call_1", Name: "get_weather"},
+			{ToolID: "call_1", Arguments: `{"city":`},
+			{ToolID: "call_1", Arguments: `"Paris"}`},
+		}, deltas)
+
+		last := chunks[len(chunks)-1]
+		require.True(t, last.Done)
+		require.NotNil(t, last.Response)
+		assert.Equal(t, agent.LLMResponse{
+			Content: []agent.ContentResponse{{Text: "Let me check."}},
+			Tools: []agent.ToolResponseObject{{
+				Name:   "get_weather",
+				ToolID: "call_1",
+				Input:  map[string]interface{}{"city": "Paris"},
+			}},
+			Usage: agent.Usage{InputTokens: 12, OutputTokens: 8, TotalTokens: 20},
+		}, *last.Response)
+	})
+
+	t.Run("complete arguments replace the deltas", func(t *testing.T) {
+		client := newStreamServer(t, []string{
+			`{"type":"response.output_item.added","output_index":0,"item":{"type":"function_call","id":"fc_1","call_id":"call_1","name":"get_weather","arguments":""}}`,
+			`{"type":"response.function_call_arguments.delta","output_index":0,"item_id":"fc_1","delta":"{\"ci"}`,
+			`{"type":"response.function_call_arguments.done","output_index":0,"item_id":"fc_1","arguments":"{\"city\":\"Oslo\"}"}`,
+			`{"type":"response.completed","response":{"id":"resp_1","output":[],"usage":{"input_tokens":1,"output_tokens":2}}}`,
+		})
+
+		ch, err := client.ProvideResponseStream(context.Background(), request)
+		require.NoError(t, err)
+		chunks := collectChunks(t, ch)
+
+		last := chunks[len(chunks)-1]
+		require.True(t, last.Done)
+		assert.Equal(t, map[string]interface{}{"city": "Oslo"}, last.Response.Tools[0].Input)
+		assert.Equal(t, agent.Usage{InputTokens: 1, OutputTokens: 2, TotalTokens: 3}, last.Response.Usage)
+	})
+
+	t.Run("failed response ends with an error", func(t *testing.T) {
+		client := newStreamServer(t, []string{
+			`{"type":"response.output_text.delta","output_index":0,"content_index":0,"item_id":"msg_1","delta":"Hi"}`,
+			`{"type":"response.failed","response":{"id":"resp_1","output":[],"error":{"code":"server_error","message":"boom"}}}`,
+		})
+
+		ch, err := client.ProvideResponseStream(context.Background(), request)
+		require.NoError(t, err)
+		chunks := collectChunks(t, ch)
+
+		assert.Equal(t, "Hi", chunks[0].Text)
+		last := chunks[len(chunks)-1]
+		assert.False(t, last.Done)
+		assert.ErrorContains(t, last.Err, "boom")
+	})
+
+	t.Run("http error is returned", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"message": "bad request"}}`))
+		}))
+		defer server.Close()
+		sdkClient := openai.NewClient(option.WithAPIKey("test-api-key"), option.WithBaseURL(server.URL), option.WithMaxRetries(0))
+		client := &Client{client: &sdkClient, model: "gpt-4"}
+
+		_, err := client.ProvideResponseStream(context.Background(), request)
+		assert.Error(t, err)
+	})
+}
@@ -2,6 +2,8 @@ package openai
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
 
 	"go.uber.org/zap"
 )
@@ -29,3 +31,32 @@ func unmarshalArguments(logger *zap.Logger, arguments string) map[string]interfa
 	}
 	return args
 }
+
+// floatField reads an optional number from an integration config map, which
+// holds float64 when decoded from JSON and int or float64 from YAML. Numeric
+// strings are accepted too. A missing or empty value returns nil.
+func floatField(m map[string]any, key string) (*float64, error) {
+	var f float64
+	switch v := m[key].(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		f = v
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number, got %q", key, v)
+		}
+		f = parsed
+	default:
+		return nil, fmt.Errorf("%s must be a number, got %T", key, v)
+	}
+	return &f, nil
+}
@@ -0,0 +1,60 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Servflow/servflow/pkg/engine/integration"
+)
+
+// migrationsTable records which migrations have been applied.
+const migrationsTable = "servflow_migrations"
+
+var _ integration.Migrator = (*SQL)(nil)
+
+func (s *SQL) ensureMigrationsTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		version VARCHAR(255) PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`, migrationsTable))
+	return err
+}
+
+func (s *SQL) AppliedMigrations(ctx context.Context) (map[string]bool, error) {
+	if err := s.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	var versions []string
+	if err := s.db.SelectContext(ctx, &versions, fmt.Sprintf("SELECT version FROM %s", migrationsTable)); err != nil {
+		return nil, err
+	}
+	applied := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		applied[v] = true
+	}
+	return applied, nil
+}
+
+// ApplyMigration runs the migration's SQL and records its version in one
+// transaction. On postgres a failed script therefore leaves nothing behind;
+// mysql commits DDL implicitly, and needs multiStatements=true in the
+// connection string for scripts with more than one statement.
+func (s *SQL) ApplyMigration(ctx context.Context, m integration.Migration) error {
+	if err := s.ensureMigrationsTable(ctx); err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, m.Script); err != nil {
+		return err
+	}
+	query := s.db.Rebind(fmt.Sprintf("INSERT INTO %s (version) VALUES (?)", migrationsTable))
+	if _, err := tx.ExecContext(ctx, query, m.Version); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,66 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// readOnlyStatementPattern matches statements that start with a keyword that
+// only reads. The database still enforces it: QueryReadOnly runs inside a
+// read-only transaction, which also rejects writes hidden in a CTE.
+var readOnlyStatementPattern = regexp.MustCompile(`(?is)^\s*(\(\s*)*(select|with|values|table|show|explain|describe)\b`)
+
+// Query runs an arbitrary statement, such as a join or an aggregate the
+// Fetch options cannot express, and returns its rows. Values must be passed
+// as args bound to ? placeholders, which are rebound for the driver.
+func (s *SQL) Query(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	q := s.db.Rebind(query)
+	rows, err := s.queryx(ctx, "query", q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRows(rows)
+}
+
+// QueryReadOnly is Query for statements that must not write. It rejects
+// anything that does not start like a read and runs the statement in a
+// read-only transaction that is always rolled back.
+func (s *SQL) QueryReadOnly(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	if !readOnlyStatementPattern.MatchString(query) {
+		return nil, fmt.Errorf("statement is not read-only")
+	}
+
+	tx, err := s.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	q := annotate(ctx, s.db.Rebind(query))
+	began := time.Now()
+	rows, err := tx.QueryxContext(ctx, q, args...)
+	s.observe(ctx, "query", q, args, time.Since(began))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRows(rows)
+}
+
+func scanRows(rows *sqlx.Rows) ([]map[string]interface{}, error) {
+	resp := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		result := make(map[string]interface{})
+		if err := rows.MapScan(result); err != nil {
+			return nil, err
+		}
+		resp = append(resp, result)
+	}
+	return resp, rows.Err()
+}
@@ -0,0 +1,92 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyStatementPattern(t *testing.T) {
+	for query, want := range map[string]bool{
+		"SELECT * FROM users":                            true,
+		"  select count(*) from users":                   true,
+		"WITH t AS (SELECT 1) SELECT * FROM t":           true,
+		"(SELECT 1) UNION (SELECT 2)":                    true,
+		"EXPLAIN SELECT 1":                               true,
+		"INSERT INTO users (name) VALUES (?)":            false,
+		"update users set name = ?":                      false,
+		"DELETE FROM users":                              false,
+		"DROP TABLE users":                               false,
+		"selected_users":                                 false,
+		"-- SELECT\nDELETE FROM users":                   false,
+		"TRUNCATE users":                                 false,
+		"CREATE TABLE x AS SELECT * FROM users":          false,
+		"SELECTX":                                        false,
+		"\n\tSELECT id FROM users WHERE name = 'DELETE'": true,
+	} {
+		assert.Equal(t, want, readOnlyStatementPattern.MatchString(query), query)
+	}
+}
+
+func TestSQL_Query(t *testing.T) {
+	s, err := newWrapper(Config{Type: "postgres", ConnectionString: newDB(t)})
+	require.NoError(t, err)
+
+	setupTestDB(t, s, "users_query")
+	_, err = s.db.Exec(`CREATE TABLE orders_query (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		total INTEGER NOT NULL
+	)`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := s.db.Exec("DROP TABLE IF EXISTS orders_query")
+		assert.NoError(t, err)
+	})
+
+	_, err = s.db.Exec(`INSERT INTO users_query (id, name, email, password) VALUES
+		(1, 'Ada', 'ada@test.com', 'x'), (2, 'Grace', 'grace@test.com', 'x'), (3, 'Linus', 'linus@test.com', 'x')`)
+	require.NoError(t, err)
+	_, err = s.db.Exec(`INSERT INTO orders_query (user_id, total) VALUES (1, 10), (1, 25), (2, 5)`)
+	require.NoError(t, err)
+
+	join := `SELECT u.name, SUM(o.total) AS spent
+		FROM users_query u JOIN orders_query o ON o.user_id = u.id
+		WHERE o.total >= ?
+		GROUP BY u.name ORDER BY u.name`
+
+	for name, query := range map[string]func(context.Context, string, ...interface{}) ([]map[string]interface{}, error){
+		"query":           s.Query,
+		"read-only query": s.QueryReadOnly,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rows, err := query(context.Background(), join, 5)
+			require.NoError(t, err)
+			require.Len(t, rows, 2)
+			assert.Equal(t, "Ada", rows[0]["name"])
+			assert.EqualValues(t, 35, rows[0]["spent"])
+			assert.Equal(t, "Grace", rows[1]["name"])
+			assert.EqualValues(t, 5, rows[1]["spent"])
+		})
+	}
+
+	t.Run("read-only rejects writes", func(t *testing.T) {
+		_, err := s.QueryReadOnly(context.Background(), "DELETE FROM orders_query")
+		assert.EqualError(t, err, "statement is not read-only")
+
+		_, err = s.QueryReadOnly(context.Background(), "WITH gone AS (DELETE FROM orders_query RETURNING *) SELECT * FROM gone")
+		assert.Error(t, err)
+
+		var count int
+		require.NoError(t, s.db.QueryRow("SELECT COUNT(*) FROM orders_query").Scan(&count))
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("arguments are bound", func(t *testing.T) {
+		rows, err := s.Query(context.Background(), "SELECT name FROM users_query WHERE name = ?", "Ada' OR '1'='1")
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+}
@@ -0,0 +1,84 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/tracing"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// annotate prefixes query with a comment carrying the request id and trace
+// context of ctx, so the statement can be traced back from database logs.
+func annotate(ctx context.Context, query string) string {
+	if comment := tracing.SQLComment(ctx); comment != "" {
+		return comment + " " + query
+	}
+	return query
+}
+
+// exec runs a statement, logging it when it exceeds the slow-query threshold.
+func (s *SQL) exec(ctx context.Context, operation, query string, args ...interface{}) (sql.Result, error) {
+	query = annotate(ctx, query)
+	start := time.Now()
+	result, err := s.db.Exec(query, args...)
+	s.observe(ctx, operation, query, args, time.Since(start))
+	return result, err
+}
+
+// queryx runs a query, logging it when it exceeds the slow-query threshold.
+func (s *SQL) queryx(ctx context.Context, operation, query string, args ...interface{}) (*sqlx.Rows, error) {
+	query = annotate(ctx, query)
+	start := time.Now()
+	rows, err := s.db.Queryx(query, args...)
+	s.observe(ctx, operation, query, args, time.Since(start))
+	return rows, err
+}
+
+func (s *SQL) observe(ctx context.Context, operation, query string, args []interface{}, elapsed time.Duration) {
+	if !integration.IsSlowQuery(s.slowQueryThreshold, elapsed) {
+		return
+	}
+	fields := []zap.Field{zap.String("query", query), zap.Any("args", args)}
+	if s.explainSlowQueries {
+		plan, err := s.explain(query, args)
+		if err != nil {
+			fields = append(fields, zap.NamedError("explain_error", err))
+		} else {
+			fields = append(fields, zap.Strings("plan", plan))
+		}
+	}
+	integration.LogSlowQuery(ctx, "sql", operation, elapsed, fields...)
+}
+
+// explain returns the database's plan for query, one line per row. Plain
+// EXPLAIN only plans the statement, so this is safe for writes as well.
+func (s *SQL) explain(query string, args []interface{}) ([]string, error) {
+	rows, err := s.db.Queryx("EXPLAIN "+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		cols, err := rows.SliceScan()
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]string, 0, len(cols))
+		for _, c := range cols {
+			if b, ok := c.([]byte); ok {
+				c = string(b)
+			}
+			parts = append(parts, fmt.Sprintf("%v", c))
+		}
+		plan = append(plan, strings.Join(parts, " | "))
+	}
+	return plan, rows.Err()
+}
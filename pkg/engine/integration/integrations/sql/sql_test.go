@@ -10,12 +10,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Servflow/servflow/pkg/engine/integration"
 	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 var (
@@ -181,6 +186,7 @@ func Test_generateWhereClause(t *testing.T) {
 
 	testCases := []struct {
 		name           string
+		driver         string
 		filters        []filters.Filter
 		expected       string
 		expectedValues []interface{}
@@ -249,6 +255,102 @@ func Test_generateWhereClause(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "like and ilike",
+			filters: []filters.Filter{
+				{
+					Operation:  filters.Like,
+					Field:      "name",
+					Comparator: "Ada%",
+				},
+				{
+					Operation:  filters.ILike,
+					Field:      "email",
+					Comparator: "%@example.com",
+				},
+			},
+			expected:       "name LIKE ? AND email ILIKE ?",
+			expectedValues: []interface{}{"Ada%", "%@example.com"},
+		},
+		{
+			name:   "like and ilike on mysql",
+			driver: "mysql",
+			filters: []filters.Filter{
+				{
+					Operation:  filters.Like,
+					Field:      "name",
+					Comparator: "Ada%",
+				},
+				{
+					Operation:  filters.ILike,
+					Field:      "email",
+					Comparator: "%@Example.com",
+				},
+			},
+			expected:       "name LIKE ? AND LOWER(email) LIKE LOWER(?)",
+			expectedValues: []interface{}{"Ada%", "%@Example.com"},
+		},
+		{
+			name: "in expands with other filters",
+			filters: []filters.Filter{
+				{
+					Operation:  filters.In,
+					Field:      "status",
+					Comparator: []interface{}{"active", "pending"},
+				},
+				{
+					Operation:  filters.GreaterThan,
+					Field:      "age",
+					Comparator: 18,
+				},
+				{
+					Operation:  filters.NotIn,
+					Field:      "role",
+					Comparator: []interface{}{},
+				},
+			},
+			expected:       "status IN (?, ?) AND age > ? AND 1 = 1",
+			expectedValues: []interface{}{"active", "pending", 18},
+		},
+		{
+			name: "between",
+			filters: []filters.Filter{
+				{
+					Operation:  filters.Equals,
+					Field:      "name",
+					Comparator: "test",
+				},
+				{
+					Operation:  filters.Between,
+					Field:      "age",
+					Comparator: []interface{}{18, 65},
+				},
+			},
+			expected:       "name = ? AND age BETWEEN ? AND ?",
+			expectedValues: []interface{}{"test", 18, 65},
+		},
+		{
+			name: "between without two bounds",
+			filters: []filters.Filter{
+				{
+					Operation:  filters.Between,
+					Field:      "age",
+					Comparator: []interface{}{18},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "like with invalid pattern",
+			filters: []filters.Filter{
+				{
+					Operation:  filters.ILike,
+					Field:      "name",
+					Comparator: 12,
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -256,7 +358,11 @@ func Test_generateWhereClause(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			gotten, values, err := generateWhereClause(tc.filters...)
+			driver := tc.driver
+			if driver == "" {
+				driver = "postgres"
+			}
+			gotten, values, err := generateWhereClause(driver, tc.filters...)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("generateWhereClause() error = %v, wantErr %v", err, tc.wantErr)
 			}
@@ -459,6 +565,93 @@ func TestSQL_Fetch(t *testing.T) {
 				assert.Equal(t, "Old User", items[0]["name"])
 			},
 		},
+		{
+			name: "fetch sorted",
+			initialUsers: []testUser{
+				{"Bob", "shared@test.com", "password123"},
+				{"Carol", "carol@test.com", "password456"},
+				{"Alice", "shared@test.com", "password789"},
+			},
+			filters:   []filters.Filter{},
+			tableName: "users_sorted",
+			options: map[string]string{
+				"table": "users_sorted",
+				"sort":  "email:desc,name:asc",
+			},
+			expectedCount: 3,
+			checkFn: func(t *testing.T, items []map[string]interface{}) {
+				assert.Equal(t, "Alice", items[0]["name"])
+				assert.Equal(t, "Bob", items[1]["name"])
+				assert.Equal(t, "Carol", items[2]["name"])
+			},
+		},
+		{
+			name: "fetch paginated",
+			initialUsers: []testUser{
+				{"User 1", "u1@test.com", "password"},
+				{"User 2", "u2@test.com", "password"},
+				{"User 3", "u3@test.com", "password"},
+				{"User 4", "u4@test.com", "password"},
+				{"User 5", "u5@test.com", "password"},
+			},
+			filters:   []filters.Filter{},
+			tableName: "users_paginated",
+			options: map[string]string{
+				"table":  "users_paginated",
+				"sort":   "name:asc",
+				"limit":  "2",
+				"offset": "2",
+			},
+			expectedCount: 2,
+			checkFn: func(t *testing.T, items []map[string]interface{}) {
+				assert.Equal(t, "User 3", items[0]["name"])
+				assert.Equal(t, "User 4", items[1]["name"])
+			},
+		},
+		{
+			name:         "fetch with negative limit",
+			initialUsers: []testUser{},
+			filters:      []filters.Filter{},
+			tableName:    "users_bad_limit",
+			options: map[string]string{
+				"table": "users_bad_limit",
+				"limit": "-1",
+			},
+			wantErr: true,
+		},
+		{
+			name:         "fetch with non-numeric offset",
+			initialUsers: []testUser{},
+			filters:      []filters.Filter{},
+			tableName:    "users_bad_offset",
+			options: map[string]string{
+				"table":  "users_bad_offset",
+				"offset": "two",
+			},
+			wantErr: true,
+		},
+		{
+			name:         "fetch with invalid sort field",
+			initialUsers: []testUser{},
+			filters:      []filters.Filter{},
+			tableName:    "users_bad_sort",
+			options: map[string]string{
+				"table": "users_bad_sort",
+				"sort":  "name; DROP TABLE users_bad_sort",
+			},
+			wantErr: true,
+		},
+		{
+			name:         "fetch with unknown sort direction",
+			initialUsers: []testUser{},
+			filters:      []filters.Filter{},
+			tableName:    "users_bad_direction",
+			options: map[string]string{
+				"table": "users_bad_direction",
+				"sort":  "name:sideways",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -506,6 +699,92 @@ func TestSQL_Fetch(t *testing.T) {
 	}
 }
 
+func TestSQL_FetchPage(t *testing.T) {
+	s, err := newWrapper(Config{Type: "postgres", ConnectionString: newDB(t)})
+	require.NoError(t, err)
+
+	setupTestDB(t, s, "users_page_total")
+	for i := 1; i <= 5; i++ {
+		_, err := s.db.Exec("INSERT INTO users_page_total (name, email, password) VALUES ($1, $2, 'password')",
+			fmt.Sprintf("User %d", i), fmt.Sprintf("u%d@test.com", i))
+		require.NoError(t, err)
+	}
+
+	items, total, err := s.FetchPage(context.Background(), map[string]string{
+		"table": "users_page_total", "sort": "name:desc", "limit": "2",
+	}, filters.Filter{Field: "name", Operation: filters.NotEquals, Comparator: "User 5"})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "User 4", items[0]["name"])
+	assert.Equal(t, "User 3", items[1]["name"])
+	assert.Equal(t, int64(4), total)
+}
+
+func TestSQL_Count(t *testing.T) {
+	s, err := newWrapper(Config{Type: "postgres", ConnectionString: newDB(t)})
+	require.NoError(t, err)
+
+	setupTestDB(t, s, "users_count")
+	for i := 1; i <= 3; i++ {
+		_, err := s.db.Exec("INSERT INTO users_count (name, email, password) VALUES ($1, $2, 'password')",
+			fmt.Sprintf("User %d", i), fmt.Sprintf("u%d@test.com", i))
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name    string
+		options map[string]string
+		filters []filters.Filter
+		want    int64
+		wantErr string
+	}{
+		{
+			name:    "all rows",
+			options: map[string]string{"table": "users_count"},
+			want:    3,
+		},
+		{
+			name:    "with filter",
+			options: map[string]string{"table": "users_count"},
+			filters: []filters.Filter{{Field: "name", Operation: filters.NotEquals, Comparator: "User 1"}},
+			want:    2,
+		},
+		{
+			name:    "ignores page options",
+			options: map[string]string{"table": "users_count", "limit": "1", "offset": "1"},
+			want:    3,
+		},
+		{
+			name:    "no matches",
+			options: map[string]string{"table": "users_count"},
+			filters: []filters.Filter{{Field: "email", Comparator: "nobody@test.com"}},
+			want:    0,
+		},
+		{
+			name:    "missing table",
+			options: map[string]string{},
+			wantErr: "no table name provided",
+		},
+		{
+			name:    "invalid table",
+			options: map[string]string{"table": "users; DROP TABLE users"},
+			wantErr: "invalid table name",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.Count(context.Background(), tc.options, tc.filters...)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func TestSQL_Store(t *testing.T) {
 	// t.Parallel() - removed to ensure proper container handling
 
@@ -636,6 +915,105 @@ func TestSQL_Store(t *testing.T) {
 	}
 }
 
+func TestSQL_StoreUpsert(t *testing.T) {
+	s, err := newWrapper(Config{Type: "postgres", ConnectionString: newDB(t)})
+	require.NoError(t, err)
+
+	_, err = s.db.Exec(`CREATE TABLE users_upsert (
+		email VARCHAR(255) PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		visits INT
+	)`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := s.db.Exec("DROP TABLE IF EXISTS users_upsert")
+		assert.NoError(t, err)
+	})
+
+	opts := map[string]string{"table": "users_upsert", filters.UpsertOnOption: "email"}
+	require.NoError(t, s.Store(context.Background(), map[string]interface{}{"email": "a@test.com", "name": "Before", "visits": 1}, opts))
+	require.NoError(t, s.Store(context.Background(), map[string]interface{}{"email": "a@test.com", "name": "After", "visits": 2}, opts))
+
+	total, err := s.Count(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	var (
+		name   string
+		visits int
+	)
+	require.NoError(t, s.db.QueryRow("SELECT name, visits FROM users_upsert WHERE email = $1", "a@test.com").Scan(&name, &visits))
+	assert.Equal(t, "After", name)
+	assert.Equal(t, 2, visits)
+
+	t.Run("only keys leaves the row untouched", func(t *testing.T) {
+		require.NoError(t, s.Store(context.Background(), map[string]interface{}{"email": "a@test.com"}, opts))
+		require.NoError(t, s.db.QueryRow("SELECT name FROM users_upsert WHERE email = $1", "a@test.com").Scan(&name))
+		assert.Equal(t, "After", name)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		err := s.Store(context.Background(), map[string]interface{}{"name": "No email"}, opts)
+		assert.ErrorContains(t, err, "item has no email")
+	})
+
+	t.Run("invalid column", func(t *testing.T) {
+		err := s.Store(context.Background(), map[string]interface{}{"email": "b@test.com", "name; DROP TABLE users_upsert": "x"}, opts)
+		assert.ErrorContains(t, err, "invalid column name")
+	})
+}
+
+func TestSQL_StoreMany(t *testing.T) {
+	s, err := newWrapper(Config{Type: "postgres", ConnectionString: newDB(t)})
+	require.NoError(t, err)
+
+	_, err = s.db.Exec(`CREATE TABLE users_many (
+		id SERIAL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		email VARCHAR(255)
+	)`)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := s.db.Exec("DROP TABLE IF EXISTS users_many")
+		assert.NoError(t, err)
+	})
+
+	items := make([]map[string]interface{}, 100)
+	for i := range items {
+		items[i] = map[string]interface{}{"name": fmt.Sprintf("User %d", i)}
+		if i%2 == 0 {
+			items[i]["email"] = fmt.Sprintf("u%d@test.com", i)
+		}
+	}
+	opts := map[string]string{"table": "users_many"}
+	require.NoError(t, s.StoreMany(context.Background(), items, opts))
+
+	total, err := s.Count(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), total)
+
+	var withoutEmail int
+	require.NoError(t, s.db.QueryRow("SELECT COUNT(*) FROM users_many WHERE email IS NULL").Scan(&withoutEmail))
+	assert.Equal(t, 50, withoutEmail)
+
+	t.Run("failed row stores nothing", func(t *testing.T) {
+		err := s.StoreMany(context.Background(), []map[string]interface{}{
+			{"name": "kept?"},
+			{"email": "missing-name@test.com"},
+		}, opts)
+		require.Error(t, err)
+
+		total, err := s.Count(context.Background(), opts)
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), total)
+	})
+
+	t.Run("invalid column", func(t *testing.T) {
+		err := s.StoreMany(context.Background(), []map[string]interface{}{{"name; DROP TABLE users_many": "x"}}, opts)
+		assert.ErrorContains(t, err, "invalid column name")
+	})
+}
+
 func TestSQL_Update(t *testing.T) {
 	// t.Parallel() - removed to ensure proper container handling
 
@@ -861,6 +1239,120 @@ func TestSQL_Update(t *testing.T) {
 	}
 }
 
+func Test_generateSetClause(t *testing.T) {
+	t.Parallel()
+
+	statements, values, err := generateSetClause(map[string]interface{}{
+		"name": "n",
+		"$inc": map[string]interface{}{"views": 1, "score": -2.5},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name = ?", "score = score + ?", "views = views + ?"}, statements)
+	assert.Equal(t, []interface{}{"n", -2.5, 1}, values)
+
+	for name, fields := range map[string]map[string]interface{}{
+		"injected column":           {"name = 'x', admin": true},
+		"injected increment column": {"$inc": map[string]interface{}{"views = 0; --": 1}},
+		"non-numeric increment":     {"$inc": map[string]interface{}{"views": "1; DROP TABLE users"}},
+		"increment not an object":   {"$inc": 1},
+		"unknown operator":          {"$push": map[string]interface{}{"tags": "x"}},
+		"assigned and incremented":  {"views": 1, "$inc": map[string]interface{}{"views": 1}},
+	} {
+		_, _, err := generateSetClause(fields)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestSQL_UpdateIncrement(t *testing.T) {
+	sqlConnectionString := newDB(t)
+	s, err := newWrapper(Config{Type: "postgres", ConnectionString: sqlConnectionString})
+	require.NoError(t, err)
+
+	setupTestDB(t, s, "counters")
+	_, err = s.db.Exec("ALTER TABLE counters ADD COLUMN views INTEGER NOT NULL DEFAULT 0")
+	require.NoError(t, err)
+	_, err = s.db.Exec(`INSERT INTO counters (name, email, password) VALUES ($1, $2, $3)`, "page", "page@test.com", "x")
+	require.NoError(t, err)
+
+	// Concurrent increments must all land: a read-modify-write would lose
+	// updates here, an in-database expression does not.
+	const workers = 25
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Update(context.Background(),
+				map[string]interface{}{"$inc": map[string]interface{}{"views": 1}},
+				map[string]string{"table": "counters"},
+				filters.Filter{Field: "name", Operation: filters.Equals, Comparator: "page"})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	var views int
+	require.NoError(t, s.db.QueryRow("SELECT views FROM counters WHERE name = $1", "page").Scan(&views))
+	assert.Equal(t, workers, views)
+
+	// Increments combine with literal assignments in one statement.
+	_, err = s.Update(context.Background(),
+		map[string]interface{}{"email": "new@test.com", "$inc": map[string]interface{}{"views": -5}},
+		map[string]string{"table": "counters"},
+		filters.Filter{Field: "name", Operation: filters.Equals, Comparator: "page"})
+	require.NoError(t, err)
+
+	var email string
+	require.NoError(t, s.db.QueryRow("SELECT email, views FROM counters WHERE name = $1", "page").Scan(&email, &views))
+	assert.Equal(t, "new@test.com", email)
+	assert.Equal(t, workers-5, views)
+}
+
+func TestSQL_UpdateVersion(t *testing.T) {
+	sqlConnectionString := newDB(t)
+	s, err := newWrapper(Config{Type: "postgres", ConnectionString: sqlConnectionString})
+	require.NoError(t, err)
+
+	setupTestDB(t, s, "versioned")
+	_, err = s.db.Exec("ALTER TABLE versioned ADD COLUMN version INTEGER NOT NULL DEFAULT 1")
+	require.NoError(t, err)
+	_, err = s.db.Exec(`INSERT INTO versioned (name, email, password) VALUES ($1, $2, $3)`, "ada", "ada@test.com", "x")
+	require.NoError(t, err)
+
+	byName := filters.Filter{Field: "name", Operation: filters.Equals, Comparator: "ada"}
+	update := func(version string, fields map[string]interface{}) error {
+		_, err := s.Update(context.Background(), fields,
+			map[string]string{"table": "versioned", filters.VersionOption: version}, byName)
+		return err
+	}
+
+	require.NoError(t, update("1", map[string]interface{}{"email": "new@test.com"}))
+
+	// The first writer bumped the version, so a second writer still holding
+	// version 1 must not clobber its change.
+	err = update("1", map[string]interface{}{"email": "stale@test.com"})
+	assert.ErrorIs(t, err, filters.ErrVersionConflict)
+
+	var email string
+	var version int
+	require.NoError(t, s.db.QueryRow("SELECT email, version FROM versioned WHERE name = $1", "ada").Scan(&email, &version))
+	assert.Equal(t, "new@test.com", email)
+	assert.Equal(t, 2, version)
+
+	_, err = s.Update(context.Background(), map[string]interface{}{"email": "x"},
+		map[string]string{"table": "versioned", filters.VersionOption: "2"},
+		filters.Filter{Field: "name", Operation: filters.Equals, Comparator: "missing"})
+	assert.ErrorIs(t, err, filters.ErrNoMatch)
+
+	assert.Error(t, update("2", map[string]interface{}{"version": 10}))
+	assert.Error(t, update("not-a-number", map[string]interface{}{"email": "x"}))
+}
+
 func TestSQL_Delete(t *testing.T) {
 	// t.Parallel() - removed to ensure proper container handling
 
@@ -898,7 +1390,8 @@ func TestSQL_Delete(t *testing.T) {
 			filters:   []filters.Filter{},
 			tableName: "users",
 			options: map[string]string{
-				"table": "users",
+				"table":                       "users",
+				filters.AllowFullDeleteOption: "true",
 			},
 			wantErr: false,
 			checkFn: func(t *testing.T, s *SQL) {
@@ -1045,7 +1538,8 @@ func TestSQL_Delete(t *testing.T) {
 				{"User 1", "user1@test.com", "pass1"},
 			},
 			options: map[string]string{
-				"collection": "users_collection",
+				"collection":                  "users_collection",
+				filters.AllowFullDeleteOption: "true",
 			},
 			wantErr: false,
 			checkFn: func(t *testing.T, s *SQL) {
@@ -1055,6 +1549,51 @@ func TestSQL_Delete(t *testing.T) {
 				assert.Equal(t, 0, count)
 			},
 		},
+		{
+			name:      "unfiltered delete without confirmation",
+			tableName: "users_unconfirmed",
+			initialUsers: []testUser{
+				{"User 1", "user1@test.com", "pass1"},
+				{"User 2", "user2@test.com", "pass2"},
+			},
+			filters: []filters.Filter{},
+			options: map[string]string{
+				"table": "users_unconfirmed",
+			},
+			wantErr: true,
+			checkFn: func(t *testing.T, s *SQL) {
+				var count int
+				err := s.db.QueryRow("SELECT COUNT(*) FROM users_unconfirmed").Scan(&count)
+				assert.NoError(t, err)
+				assert.Equal(t, 2, count)
+			},
+		},
+		{
+			name:      "chunked delete",
+			tableName: "users_chunked",
+			initialUsers: []testUser{
+				{"User 1", "user1@test.com", "pass1"},
+				{"User 2", "user2@test.com", "pass2"},
+				{"User 3", "user3@test.com", "pass3"},
+				{"User 4", "user4@test.com", "pass4"},
+				{"User 5", "user5@test.com", "pass5"},
+				{"Keep", "keep@test.com", "pass6"},
+			},
+			filters: []filters.Filter{
+				{Field: "password", Operation: filters.NotEquals, Comparator: "pass6"},
+			},
+			options: map[string]string{
+				"table":                 "users_chunked",
+				filters.BatchSizeOption: "2",
+			},
+			wantErr: false,
+			checkFn: func(t *testing.T, s *SQL) {
+				var names []string
+				err := s.db.Select(&names, "SELECT name FROM users_chunked")
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"Keep"}, names)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1094,3 +1633,100 @@ func TestSQL_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestSQL_Migrations(t *testing.T) {
+	sqlConnectionString := newDB(t)
+	s, err := newWrapper(Config{Type: "postgres", ConnectionString: sqlConnectionString})
+	require.NoError(t, err)
+
+	migrations := []integration.Migration{
+		{Version: "0001_create_widgets", Script: `CREATE TABLE widgets (id SERIAL PRIMARY KEY, name TEXT NOT NULL);
+			INSERT INTO widgets (name) VALUES ('seed');`},
+		{Version: "0002_add_color", Script: `ALTER TABLE widgets ADD COLUMN color TEXT`},
+	}
+
+	ran, err := integration.ApplyMigrationsTo(context.Background(), s, migrations)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0001_create_widgets", "0002_add_color"}, ran)
+
+	// A restarted engine sees both recorded: re-running the non-idempotent
+	// scripts would fail on CREATE TABLE and duplicate the seed row.
+	restarted, err := newWrapper(Config{Type: "postgres", ConnectionString: sqlConnectionString})
+	require.NoError(t, err)
+	ran, err = integration.ApplyMigrationsTo(context.Background(), restarted, migrations)
+	require.NoError(t, err)
+	assert.Empty(t, ran)
+
+	var count int
+	require.NoError(t, s.db.QueryRow("SELECT COUNT(*) FROM widgets").Scan(&count))
+	assert.Equal(t, 1, count)
+
+	// A failing script is rolled back together with its version record.
+	_, err = integration.ApplyMigrationsTo(context.Background(), s, []integration.Migration{
+		{Version: "0003_broken", Script: `ALTER TABLE widgets ADD COLUMN size INT; SELECT * FROM missing_table`},
+	})
+	assert.Error(t, err)
+	applied, err := s.AppliedMigrations(context.Background())
+	require.NoError(t, err)
+	assert.False(t, applied["0003_broken"])
+}
+
+func TestSQL_SlowQueryLogging(t *testing.T) {
+	sqlConnectionString := newDB(t)
+
+	run := func(t *testing.T, threshold string) []observer.LoggedEntry {
+		s, err := newWrapper(Config{
+			Type:               "postgres",
+			ConnectionString:   sqlConnectionString,
+			SlowQueryThreshold: threshold,
+			ExplainSlowQueries: true,
+		})
+		require.NoError(t, err)
+		setupTestDB(t, s, "slow_users")
+
+		core, logs := observer.New(zap.DebugLevel)
+		ctx := logging.WithLogger(context.Background(), zap.New(core))
+		_, err = s.Fetch(ctx, map[string]string{"table": "slow_users"},
+			filters.Filter{Field: "name", Operation: filters.Equals, Comparator: "ada"})
+		require.NoError(t, err)
+		return logs.FilterMessage("slow query").All()
+	}
+
+	t.Run("slow query is logged with its plan", func(t *testing.T) {
+		entries := run(t, "1ns")
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "sql", fields["integration"])
+		assert.Equal(t, "fetch", fields["operation"])
+		assert.Contains(t, fields["query"], "SELECT * FROM slow_users WHERE name = $1")
+		plan, ok := fields["plan"].([]interface{})
+		require.True(t, ok, "expected an EXPLAIN plan, got %v", fields)
+		assert.Contains(t, fmt.Sprint(plan...), "Seq Scan on slow_users")
+	})
+
+	t.Run("fast query is not logged", func(t *testing.T) {
+		assert.Empty(t, run(t, "1h"))
+	})
+
+	t.Run("query carries the request id", func(t *testing.T) {
+		s, err := newWrapper(Config{Type: "postgres", ConnectionString: sqlConnectionString, SlowQueryThreshold: "1ns"})
+		require.NoError(t, err)
+		setupTestDB(t, s, "correlated_users")
+
+		core, logs := observer.New(zap.DebugLevel)
+		ctx := logging.WithLogger(context.Background(), zap.New(core))
+		ctx, rc := requestctx.Start(ctx, requestctx.Options{ID: "request_42"})
+		defer rc.Done()
+		_, err = s.Fetch(ctx, map[string]string{"table": "correlated_users"})
+		require.NoError(t, err)
+
+		entries := logs.FilterMessage("slow query").All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "/*request_id='request_42'*/ SELECT * FROM correlated_users ;", entries[0].ContextMap()["query"])
+	})
+
+	t.Run("invalid threshold", func(t *testing.T) {
+		_, err := newWrapper(Config{Type: "postgres", ConnectionString: sqlConnectionString, SlowQueryThreshold: "soon"})
+		assert.Error(t, err)
+	})
+}
@@ -104,3 +104,14 @@ func (m *mockIntegration) Name() string {
 func (m *mockIntegration) Init(config map[string]any) error {
 	return nil
 }
+
+func TestResolveID(t *testing.T) {
+	defer SetDefaultIntegration("")
+
+	assert.Equal(t, "", ResolveID(""), "no default configured")
+	assert.Equal(t, "orders", ResolveID("orders"))
+
+	SetDefaultIntegration("main")
+	assert.Equal(t, "main", ResolveID(""))
+	assert.Equal(t, "orders", ResolveID("orders"), "an explicit id wins")
+}
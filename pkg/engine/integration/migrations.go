@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// Migration is one schema change for an integration. Script is interpreted by
+// the integration: SQL statements for sql, an index definition for mongo.
+type Migration struct {
+	Version string
+	Script  string
+}
+
+// Migrator is implemented by integrations that can apply migrations. Applied
+// versions are tracked inside the integration itself (a migrations table or
+// collection), so a restart only applies what is new.
+type Migrator interface {
+	// AppliedMigrations returns the versions already applied.
+	AppliedMigrations(ctx context.Context) (map[string]bool, error)
+	// ApplyMigration runs m and records its version.
+	ApplyMigration(ctx context.Context, m Migration) error
+}
+
+// MigrationConfig points the runner at a folder of migrations for one
+// integration.
+type MigrationConfig struct {
+	Integration string `json:"integration" yaml:"integration"`
+	Dir         string `json:"dir" yaml:"dir"`
+}
+
+// LoadMigrations reads every file in dir as a migration, ordered by file name.
+// The version is the file name without its extension, so files are usually
+// prefixed with a sortable number: 0001_create_users.sql.
+func LoadMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations folder: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, Migration{
+			Version: strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())),
+			Script:  string(content),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %s", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// ApplyMigrations applies the migrations the integration has not recorded
+// yet, see ApplyMigrationsTo.
+func ApplyMigrations(ctx context.Context, integrationID string, migrations []Migration) ([]string, error) {
+	i, err := GetIntegration(ctx, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	migrator, ok := i.(Migrator)
+	if !ok {
+		return nil, fmt.Errorf("integration %s does not support migrations", integrationID)
+	}
+	return ApplyMigrationsTo(ctx, migrator, migrations)
+}
+
+// ApplyMigrationsTo applies, in order, every migration the migrator has not
+// recorded yet and returns the versions it applied. It stops at the first
+// failure so later migrations never run against a half-migrated schema.
+func ApplyMigrationsTo(ctx context.Context, migrator Migrator, migrations []Migration) ([]string, error) {
+	applied, err := migrator.AppliedMigrations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	var ran []string
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if err := migrator.ApplyMigration(ctx, m); err != nil {
+			return ran, fmt.Errorf("migration %s failed: %w", m.Version, err)
+		}
+		logging.FromContext(ctx).Info("applied migration", zap.String("version", m.Version))
+		ran = append(ran, m.Version)
+	}
+	return ran, nil
+}
+
+// RunMigrations loads and applies the migrations for each config in order.
+func RunMigrations(ctx context.Context, configs []MigrationConfig) error {
+	for _, cfg := range configs {
+		if cfg.Integration == "" || cfg.Dir == "" {
+			return errors.New("migration config requires an integration and a dir")
+		}
+		migrations, err := LoadMigrations(cfg.Dir)
+		if err != nil {
+			return err
+		}
+		if _, err := ApplyMigrations(ctx, cfg.Integration, migrations); err != nil {
+			return fmt.Errorf("integration %s: %w", cfg.Integration, err)
+		}
+	}
+	return nil
+}
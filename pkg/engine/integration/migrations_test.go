@@ -0,0 +1,123 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryMigrator keeps applied versions in memory; reusing one instance across
+// runs stands in for the migrations table surviving a restart.
+type memoryMigrator struct {
+	applied map[string]bool
+	scripts []string
+	failOn  string
+}
+
+func (m *memoryMigrator) Type() string { return "memory_migrator" }
+
+func (m *memoryMigrator) AppliedMigrations(ctx context.Context) (map[string]bool, error) {
+	applied := make(map[string]bool, len(m.applied))
+	for v := range m.applied {
+		applied[v] = true
+	}
+	return applied, nil
+}
+
+func (m *memoryMigrator) ApplyMigration(ctx context.Context, migration Migration) error {
+	if migration.Version == m.failOn {
+		return errors.New("boom")
+	}
+	m.applied[migration.Version] = true
+	m.scripts = append(m.scripts, migration.Script)
+	return nil
+}
+
+func writeMigrations(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestLoadMigrations(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"0002_add_email.sql":    "ALTER TABLE users ADD email TEXT;",
+		"0001_create_users.sql": "CREATE TABLE users (id INT);",
+		".DS_Store":             "junk",
+	})
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested"), 0755))
+
+	migrations, err := LoadMigrations(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: "0001_create_users", Script: "CREATE TABLE users (id INT);"},
+		{Version: "0002_add_email", Script: "ALTER TABLE users ADD email TEXT;"},
+	}, migrations)
+
+	_, err = LoadMigrations(writeMigrations(t, map[string]string{"0001.sql": "a", "0001.json": "b"}))
+	assert.ErrorContains(t, err, "duplicate migration version")
+
+	_, err = LoadMigrations(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestRunMigrations(t *testing.T) {
+	migrator := &memoryMigrator{applied: map[string]bool{}}
+	ReplaceIntegrationType("memory_migrator", func(config map[string]any) (Integration, error) {
+		return migrator, nil
+	})
+	require.NoError(t, InitializeIntegration("memory_migrator", "migrated-db", nil, false))
+
+	dir := writeMigrations(t, map[string]string{
+		"0001_create.sql": "create",
+		"0002_alter.sql":  "alter",
+	})
+	cfg := []MigrationConfig{{Integration: "migrated-db", Dir: dir}}
+
+	require.NoError(t, RunMigrations(context.Background(), cfg))
+	assert.Equal(t, []string{"create", "alter"}, migrator.scripts)
+
+	// A restart finds both recorded and applies nothing.
+	require.NoError(t, RunMigrations(context.Background(), cfg))
+	assert.Equal(t, []string{"create", "alter"}, migrator.scripts)
+
+	// Only the new migration runs once one is added.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0003_index.sql"), []byte("index"), 0644))
+	migrations, err := LoadMigrations(dir)
+	require.NoError(t, err)
+	ran, err := ApplyMigrations(context.Background(), "migrated-db", migrations)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0003_index"}, ran)
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		migrator.failOn = "0004_broken"
+		ran, err := ApplyMigrations(context.Background(), "migrated-db", []Migration{
+			{Version: "0004_broken", Script: "broken"},
+			{Version: "0005_after", Script: "after"},
+		})
+		assert.ErrorContains(t, err, "migration 0004_broken failed")
+		assert.Empty(t, ran)
+		assert.False(t, migrator.applied["0005_after"])
+	})
+
+	t.Run("integration without migration support", func(t *testing.T) {
+		ReplaceIntegrationType("plain", func(config map[string]any) (Integration, error) {
+			return &mockIntegration{}, nil
+		})
+		require.NoError(t, InitializeIntegration("plain", "plain-db", nil, false))
+		_, err := ApplyMigrations(context.Background(), "plain-db", nil)
+		assert.ErrorContains(t, err, "does not support migrations")
+	})
+
+	t.Run("incomplete config", func(t *testing.T) {
+		assert.Error(t, RunMigrations(context.Background(), []MigrationConfig{{Integration: "migrated-db"}}))
+	})
+}
@@ -0,0 +1,199 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/cache"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const defaultReadCacheTTL = time.Minute
+
+type fetcher interface {
+	Fetch(ctx context.Context, options map[string]string, filters ...filters.Filter) ([]map[string]interface{}, error)
+}
+
+// ReadCache serves the fetches of an integration from the shared cache,
+// keyed by options and filters. On a miss only one caller fetches from the
+// integration; concurrent callers for the same key wait for its result
+// instead of all hitting the integration at once.
+type ReadCache struct {
+	integrationID string
+	ttl           time.Duration
+	negativeTTL   time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]*fetchCall
+}
+
+// fetchCall is a fetch in progress; done is closed once raw or err is set.
+// Every caller decodes raw, as a cache hit does, so all of them get the same
+// types and no two share the rows of one result.
+type fetchCall struct {
+	done chan struct{}
+	raw  []byte
+	err  error
+}
+
+func NewReadCache(integrationID string, cfg apiconfig.ReadCacheConfig) (*ReadCache, error) {
+	c := &ReadCache{
+		integrationID: integrationID,
+		ttl:           defaultReadCacheTTL,
+		inFlight:      make(map[string]*fetchCall),
+	}
+	if cfg.TTL != "" {
+		d, err := time.ParseDuration(cfg.TTL)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid readCache ttl %q: must be a positive duration", cfg.TTL)
+		}
+		c.ttl = d
+	}
+	if cfg.NegativeTTL != "" {
+		d, err := time.ParseDuration(cfg.NegativeTTL)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid readCache negativeTTL %q", cfg.NegativeTTL)
+		}
+		c.negativeTTL = d
+	}
+	return c, nil
+}
+
+// Fetch returns the cached result of fetching with options and fs, fetching
+// from the integration on a miss.
+func (c *ReadCache) Fetch(ctx context.Context, options map[string]string, fs ...filters.Filter) ([]map[string]interface{}, error) {
+	key, err := c.key(options, fs)
+	if err != nil {
+		return nil, err
+	}
+	if items, ok := c.cached(ctx, key); ok {
+		return items, nil
+	}
+
+	c.mu.Lock()
+	call, ok := c.inFlight[key]
+	if !ok {
+		call = &fetchCall{done: make(chan struct{})}
+		c.inFlight[key] = call
+		// the fetch is shared, so it must outlive the caller that started it
+		go c.run(context.WithoutCancel(ctx), key, call, options, fs)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		return decodeRows(call.raw)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run fetches for call, caches the result and wakes its callers.
+func (c *ReadCache) run(ctx context.Context, key string, call *fetchCall, options map[string]string, fs []filters.Filter) {
+	items, err := c.fetch(ctx, options, fs)
+	if err == nil {
+		call.raw, err = json.Marshal(items)
+	}
+	if err == nil {
+		c.store(ctx, key, call.raw, len(items))
+	}
+	call.err = err
+	// removed only once stored, so a caller arriving in between does not
+	// fetch again
+	c.mu.Lock()
+	delete(c.inFlight, key)
+	c.mu.Unlock()
+	close(call.done)
+}
+
+func (c *ReadCache) fetch(ctx context.Context, options map[string]string, fs []filters.Filter) ([]map[string]interface{}, error) {
+	i, err := GetIntegration(ctx, c.integrationID)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := i.(fetcher)
+	if !ok {
+		return nil, fmt.Errorf("integration %s does not support fetch", c.integrationID)
+	}
+	return f.Fetch(ctx, options, fs...)
+}
+
+func (c *ReadCache) key(options map[string]string, fs []filters.Filter) (string, error) {
+	raw, err := json.Marshal(struct {
+		Options map[string]string `json:"options"`
+		Filters []filters.Filter  `json:"filters"`
+	}{options, fs})
+	if err != nil {
+		return "", fmt.Errorf("error building cache key: %w", err)
+	}
+	return "readcache:" + c.integrationID + ":" + string(raw), nil
+}
+
+func (c *ReadCache) cached(ctx context.Context, key string) ([]map[string]interface{}, bool) {
+	raw, ok, err := cache.Default().Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	items, err := decodeRows(raw)
+	if err != nil {
+		return nil, false
+	}
+	return items, true
+}
+
+// store caches the encoded result of count rows. A failure only costs a
+// later miss, so it is logged.
+func (c *ReadCache) store(ctx context.Context, key string, raw []byte, count int) {
+	ttl := c.ttl
+	if count == 0 {
+		if c.negativeTTL == 0 {
+			return
+		}
+		ttl = c.negativeTTL
+	}
+	if err := cache.Default().Set(ctx, key, raw, ttl); err != nil {
+		logging.FromContext(ctx).Warn("failed to cache fetch result", zap.String("integration", c.integrationID), zap.Error(err))
+	}
+}
+
+// decodeRows decodes cached rows, keeping numbers as json.Number so large
+// integers survive the round trip.
+func decodeRows(raw []byte) ([]map[string]interface{}, error) {
+	var items []map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&items); err != nil {
+		return nil, fmt.Errorf("error decoding cached fetch result: %w", err)
+	}
+	return items, nil
+}
+
+// EnableReadCache serves the fetches of integration id from a ReadCache,
+// replacing any it had before.
+func EnableReadCache(id string, cfg apiconfig.ReadCacheConfig) error {
+	c, err := NewReadCache(id, cfg)
+	if err != nil {
+		return err
+	}
+	integrationManager.readCaches.Store(id, c)
+	return nil
+}
+
+// GetReadCache returns the read cache of integration id, if it has one.
+func GetReadCache(id string) (*ReadCache, bool) {
+	c, ok := integrationManager.readCaches.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return c.(*ReadCache), true
+}
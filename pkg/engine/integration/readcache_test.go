@@ -0,0 +1,170 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/cache"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFetcher counts its fetches, holding each until release is closed
+// when it is set, and returns rows for any name but "missing".
+type countingFetcher struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (f *countingFetcher) Type() string { return "counting_fetcher" }
+
+func (f *countingFetcher) Fetch(ctx context.Context, _ map[string]string, fs ...filters.Filter) ([]map[string]interface{}, error) {
+	f.calls.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if fs[0].Comparator == "missing" {
+		return nil, nil
+	}
+	return []map[string]interface{}{{"name": fs[0].Comparator, "id": int64(9007199254740993)}}, nil
+}
+
+func setupReadCache(t *testing.T, cfg apiconfig.ReadCacheConfig) (*countingFetcher, *ReadCache) {
+	t.Helper()
+	cache.SetDefault(cache.NewMemory())
+	f := &countingFetcher{}
+	ReplaceIntegrationType("counting_fetcher", func(map[string]any) (Integration, error) {
+		return f, nil
+	})
+	require.NoError(t, InitializeIntegration("counting_fetcher", "counting", nil, false))
+	require.NoError(t, EnableReadCache("counting", cfg))
+	c, ok := GetReadCache("counting")
+	require.True(t, ok)
+	return f, c
+}
+
+func byName(name string) filters.Filter {
+	return filters.Filter{Field: "name", Operation: filters.Equals, Comparator: name}
+}
+
+func TestReadCache(t *testing.T) {
+	options := map[string]string{"collection": "users"}
+
+	t.Run("concurrent misses make one fetch", func(t *testing.T) {
+		f, c := setupReadCache(t, apiconfig.ReadCacheConfig{})
+		f.release = make(chan struct{})
+
+		const callers = 20
+		var (
+			wg      sync.WaitGroup
+			results [callers][]map[string]interface{}
+		)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				items, err := c.Fetch(context.Background(), options, byName("kofo"))
+				assert.NoError(t, err)
+				results[i] = items
+			}(i)
+		}
+		assert.Eventually(t, func() bool { return f.calls.Load() == 1 }, time.Second, time.Millisecond)
+		// let the other callers reach the fetch in flight before it finishes
+		time.Sleep(20 * time.Millisecond)
+		close(f.release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), f.calls.Load())
+		// the caller that fetched gets the same rows as the ones that waited
+		for _, items := range results {
+			assert.Equal(t, []map[string]interface{}{{"name": "kofo", "id": json.Number("9007199254740993")}}, items)
+		}
+
+		_, err := c.Fetch(context.Background(), options, byName("kofo"))
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), f.calls.Load(), "later fetches are served from the cache")
+
+		_, err = c.Fetch(context.Background(), options, byName("ada"))
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), f.calls.Load(), "other filters are cached separately")
+	})
+
+	t.Run("cancelled caller does not fail the fetch it started", func(t *testing.T) {
+		f, c := setupReadCache(t, apiconfig.ReadCacheConfig{})
+		f.release = make(chan struct{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error, 1)
+		go func() {
+			_, err := c.Fetch(ctx, options, byName("kofo"))
+			first <- err
+		}()
+		assert.Eventually(t, func() bool { return f.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+		second := make(chan []map[string]interface{}, 1)
+		go func() {
+			items, err := c.Fetch(context.Background(), options, byName("kofo"))
+			assert.NoError(t, err)
+			second <- items
+		}()
+		cancel()
+		assert.ErrorIs(t, <-first, context.Canceled)
+
+		close(f.release)
+		assert.Len(t, <-second, 1)
+		assert.Equal(t, int32(1), f.calls.Load())
+	})
+
+	t.Run("entries expire after the ttl", func(t *testing.T) {
+		f, c := setupReadCache(t, apiconfig.ReadCacheConfig{TTL: "50ms"})
+
+		for i := 0; i < 3; i++ {
+			_, err := c.Fetch(context.Background(), options, byName("kofo"))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), f.calls.Load())
+
+		time.Sleep(60 * time.Millisecond)
+		_, err := c.Fetch(context.Background(), options, byName("kofo"))
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), f.calls.Load())
+	})
+
+	t.Run("empty results are only cached with a negative ttl", func(t *testing.T) {
+		f, c := setupReadCache(t, apiconfig.ReadCacheConfig{})
+		for i := 0; i < 2; i++ {
+			items, err := c.Fetch(context.Background(), options, byName("missing"))
+			require.NoError(t, err)
+			assert.Empty(t, items)
+		}
+		assert.Equal(t, int32(2), f.calls.Load())
+
+		f, c = setupReadCache(t, apiconfig.ReadCacheConfig{NegativeTTL: "50ms"})
+		for i := 0; i < 2; i++ {
+			_, err := c.Fetch(context.Background(), options, byName("missing"))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), f.calls.Load())
+
+		time.Sleep(60 * time.Millisecond)
+		_, err := c.Fetch(context.Background(), options, byName("missing"))
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), f.calls.Load())
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewReadCache("counting", apiconfig.ReadCacheConfig{TTL: "0s"})
+		assert.Error(t, err)
+		_, err = NewReadCache("counting", apiconfig.ReadCacheConfig{NegativeTTL: "soon"})
+		assert.Error(t, err)
+	})
+}
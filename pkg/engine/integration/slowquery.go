@@ -0,0 +1,41 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// ParseSlowQueryThreshold parses the slowQueryThreshold option of database
+// integrations, a Go duration such as "500ms". Empty disables slow-query
+// logging.
+func ParseSlowQueryThreshold(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid slowQueryThreshold %q: must be a positive duration", s)
+	}
+	return d, nil
+}
+
+// IsSlowQuery reports whether an operation that took elapsed should be logged
+// under threshold. A zero threshold never logs.
+func IsSlowQuery(threshold, elapsed time.Duration) bool {
+	return threshold > 0 && elapsed >= threshold
+}
+
+// LogSlowQuery logs a slow operation at warn level with its elapsed time; the
+// integration adds what identifies the query (statement, collection, filter).
+func LogSlowQuery(ctx context.Context, integrationType, operation string, elapsed time.Duration, fields ...zap.Field) {
+	fields = append([]zap.Field{
+		zap.String("integration", integrationType),
+		zap.String("operation", operation),
+		zap.Duration("elapsed", elapsed),
+	}, fields...)
+	logging.WarnContext(ctx, "slow query", fields...)
+}
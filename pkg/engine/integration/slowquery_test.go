@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseSlowQueryThreshold(t *testing.T) {
+	d, err := ParseSlowQueryThreshold("")
+	require.NoError(t, err)
+	assert.Zero(t, d)
+
+	d, err = ParseSlowQueryThreshold("250ms")
+	require.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, d)
+
+	for _, s := range []string{"fast", "-1s", "0s"} {
+		_, err := ParseSlowQueryThreshold(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestIsSlowQuery(t *testing.T) {
+	assert.False(t, IsSlowQuery(0, time.Hour), "disabled")
+	assert.False(t, IsSlowQuery(time.Second, 999*time.Millisecond))
+	assert.True(t, IsSlowQuery(time.Second, time.Second))
+}
+
+func TestLogSlowQuery(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	ctx := logging.WithLogger(context.Background(), zap.New(core))
+
+	LogSlowQuery(ctx, "sql", "fetch", 2*time.Second, zap.String("query", "SELECT 1"))
+
+	entries := logs.FilterMessage("slow query").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, zap.WarnLevel, entries[0].Level)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "sql", fields["integration"])
+	assert.Equal(t, "fetch", fields["operation"])
+	assert.Equal(t, 2*time.Second, fields["elapsed"])
+	assert.Equal(t, "SELECT 1", fields["query"])
+}
@@ -0,0 +1,227 @@
+// Package openapi describes the HTTP endpoints of a set of API configs as an
+// OpenAPI 3 document. Paths and methods come from each config's http block,
+// path parameters from its listen path, and query and header parameters from
+// the param and header calls in its templates. Request and response bodies
+// are described only where the config declares a schema for them.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+)
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI string               `json:"openapi"`
+	Info    Info                 `json:"info"`
+	Paths   map[string]*PathItem `json:"paths"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem holds the operations of one path, keyed by lower-case method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required,omitempty"`
+	Schema   Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema Schema `json:"schema,omitempty"`
+}
+
+// Schema is a JSON schema, as declared in a config.
+type Schema map[string]interface{}
+
+var stringSchema = Schema{"type": "string"}
+
+// templateParam matches the param and header template functions called with
+// a literal name, e.g. {{ param "page" }}.
+var templateParam = regexp.MustCompile(`\b(param|header)\s+"([^"]+)"`)
+
+// Generate describes the HTTP endpoints of configs. MCP tool configs, which
+// have no HTTP endpoint, are left out.
+func Generate(configs []*apiconfig.APIConfig, info Info) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+	}
+	for _, conf := range configs {
+		if conf.IsMCPConfig() {
+			continue
+		}
+		path, pathParams := openAPIPath(conf.HttpConfig.ListenPath)
+		method := conf.HttpConfig.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(method)] = operation(conf, pathParams)
+	}
+	return doc
+}
+
+func operation(conf *apiconfig.APIConfig, pathParams []string) *Operation {
+	op := &Operation{
+		OperationID: conf.ID,
+		Summary:     conf.Name,
+		Responses:   responses(conf.Responses),
+	}
+	for _, name := range pathParams {
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: stringSchema})
+	}
+	op.Parameters = append(op.Parameters, templateParameters(conf)...)
+	if conf.HttpConfig.RequestSchema != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: conf.HttpConfig.RequestSchema}},
+		}
+	}
+	return op
+}
+
+// openAPIPath converts a listen path to an OpenAPI path, returning the names
+// of its parameters. A trailing wildcard, *name, becomes {name}.
+func openAPIPath(listenPath string) (string, []string) {
+	segments := strings.Split(strings.Trim(listenPath, "/"), "/")
+	var params []string
+	for i, seg := range segments {
+		switch {
+		case strings.HasPrefix(seg, "*"):
+			seg = "{" + strings.TrimPrefix(seg, "*") + "}"
+		case !strings.HasPrefix(seg, "{"):
+			continue
+		}
+		// {id:[0-9]+} carries a pattern that OpenAPI does not
+		name, _, _ := strings.Cut(strings.Trim(seg, "{}"), ":")
+		segments[i] = "{" + name + "}"
+		params = append(params, name)
+	}
+	return "/" + strings.Join(segments, "/"), params
+}
+
+// templateParameters finds the query and header parameters read by the
+// config's templates, sorted by location and name.
+func templateParameters(conf *apiconfig.APIConfig) []Parameter {
+	raw, err := json.Marshal(struct {
+		Actions      map[string]apiconfig.Action
+		Conditionals map[string]apiconfig.Conditional
+		Responses    map[string]apiconfig.ResponseConfig
+	}{conf.Actions, conf.Conditionals, conf.Responses})
+	if err != nil {
+		return nil
+	}
+	var tree interface{}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var params []Parameter
+	walkStrings(tree, func(s string) {
+		for _, m := range templateParam.FindAllStringSubmatch(s, -1) {
+			in := "query"
+			if m[1] == "header" {
+				in = "header"
+			}
+			if key := in + ":" + m[2]; !seen[key] {
+				seen[key] = true
+				params = append(params, Parameter{Name: m[2], In: in, Schema: stringSchema})
+			}
+		}
+	})
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].In != params[j].In {
+			return params[i].In > params[j].In
+		}
+		return params[i].Name < params[j].Name
+	})
+	return params
+}
+
+func walkStrings(v interface{}, fn func(string)) {
+	switch v := v.(type) {
+	case string:
+		fn(v)
+	case map[string]interface{}:
+		for _, child := range v {
+			walkStrings(child, fn)
+		}
+	case []interface{}:
+		for _, child := range v {
+			walkStrings(child, fn)
+		}
+	}
+}
+
+// responses describes the config's responses by status code. Responses
+// sharing a code are listed together.
+func responses(configs map[string]apiconfig.ResponseConfig) map[string]*Response {
+	out := make(map[string]*Response)
+	ids := make([]string, 0, len(configs))
+	for id := range configs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		cfg := configs[id]
+		code := "default"
+		if cfg.Code != 0 {
+			code = strconv.Itoa(cfg.Code)
+		}
+		description := cfg.Name
+		if description == "" {
+			description = id
+		}
+		if r, ok := out[code]; ok {
+			r.Description += ", " + description
+		} else {
+			out[code] = &Response{Description: description}
+		}
+		if cfg.Schema != nil {
+			out[code].Content = map[string]MediaType{"application/json": {Schema: cfg.Schema}}
+		}
+	}
+	if len(out) == 0 {
+		out["default"] = &Response{Description: "response"}
+	}
+	return out
+}
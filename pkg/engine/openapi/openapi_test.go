@@ -0,0 +1,115 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/schemavalidate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sampleConfigs = []*apiconfig.APIConfig{
+	{
+		ID:   "get_user",
+		Name: "Get user",
+		HttpConfig: apiconfig.HttpConfig{
+			ListenPath: "/users/{id:[0-9]+}",
+			Method:     "GET",
+			Next:       "action.fetch",
+		},
+		Actions: map[string]apiconfig.Action{
+			"fetch": {
+				Name: "fetch",
+				Type: "fetch",
+				Next: "conditional.found",
+				Config: map[string]interface{}{
+					"table":  "users",
+					"filter": `{{ urlparam "id" }}`,
+					"fields": `{{ param "fields" }}`,
+				},
+			},
+		},
+		Conditionals: map[string]apiconfig.Conditional{
+			"found": {
+				Name:       "found",
+				Expression: `{{ and .fetch (eq (header "X-Tenant") "acme") }}`,
+				OnTrue:     "response.ok",
+				OnFalse:    "response.missing",
+			},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"ok": {
+				Name:   "User",
+				Code:   200,
+				Object: apiconfig.ResponseObject{Value: "{{ .fetch }}"},
+				Schema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{"id": map[string]interface{}{"type": "integer"}}},
+			},
+			"missing": {Name: "Not found", Code: 404, Template: `{"error": "no user {{ param "fields" }}"}`},
+		},
+	},
+	{
+		ID: "create_user",
+		HttpConfig: apiconfig.HttpConfig{
+			ListenPath:    "users",
+			Method:        "POST",
+			Next:          "response.created",
+			RequestSchema: map[string]interface{}{"type": "object", "required": []interface{}{"email"}},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"created": {Name: "Created", Code: 201, Template: "{}"},
+			"exists":  {Name: "Exists", Code: 409, Template: "{}"},
+			"invalid": {Name: "Invalid", Code: 409, Template: "{}"},
+		},
+	},
+	{
+		ID:         "files",
+		HttpConfig: apiconfig.HttpConfig{ListenPath: "/files/*path", Next: "response.ok"},
+	},
+	{
+		ID:      "mcp_tool",
+		McpTool: apiconfig.MCPToolConfig{Enabled: true, Name: "tool"},
+	},
+}
+
+func TestGenerate(t *testing.T) {
+	doc := Generate(sampleConfigs, Info{Title: "Test", Version: "1.0.0"})
+
+	raw, err := json.Marshal(doc)
+	require.NoError(t, err)
+	schemaJSON, err := os.ReadFile("testdata/openapi-3.0-subset.schema.json")
+	require.NoError(t, err)
+	schemaErrs, err := schemavalidate.ValidateInstance(schemavalidate.MustCompileSchema("openapi.json", string(schemaJSON)), raw, func(p []string) string { return "" })
+	require.NoError(t, err)
+	assert.Empty(t, schemaErrs)
+
+	require.Len(t, doc.Paths, 3)
+
+	getUser := (*doc.Paths["/users/{id}"])["get"]
+	require.NotNil(t, getUser)
+	assert.Equal(t, "get_user", getUser.OperationID)
+	assert.Equal(t, "Get user", getUser.Summary)
+	assert.Equal(t, []Parameter{
+		{Name: "id", In: "path", Required: true, Schema: stringSchema},
+		{Name: "fields", In: "query", Schema: stringSchema},
+		{Name: "X-Tenant", In: "header", Schema: stringSchema},
+	}, getUser.Parameters)
+	assert.Nil(t, getUser.RequestBody)
+	assert.Equal(t, "User", getUser.Responses["200"].Description)
+	assert.Equal(t, "object", getUser.Responses["200"].Content["application/json"].Schema["type"])
+	assert.Equal(t, &Response{Description: "Not found"}, getUser.Responses["404"])
+
+	createUser := (*doc.Paths["/users"])["post"]
+	require.NotNil(t, createUser)
+	assert.Empty(t, createUser.Parameters)
+	require.NotNil(t, createUser.RequestBody)
+	assert.Equal(t, Schema{"type": "object", "required": []interface{}{"email"}}, createUser.RequestBody.Content["application/json"].Schema)
+	assert.Equal(t, "Exists, Invalid", createUser.Responses["409"].Description)
+
+	files := (*doc.Paths["/files/{path}"])["get"]
+	require.NotNil(t, files)
+	assert.Equal(t, []Parameter{{Name: "path", In: "path", Required: true, Schema: stringSchema}}, files.Parameters)
+	assert.Equal(t, map[string]*Response{"default": {Description: "response"}}, files.Responses)
+}
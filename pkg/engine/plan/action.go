@@ -30,6 +30,10 @@ type Action struct {
 	name       string
 	useReplica bool
 	dispatch   []string
+	fallback   *fallback
+	// failAll routes every error to fail, not only ErrFailure; it is set when
+	// fail is the planner's DefaultFail.
+	failAll bool
 }
 
 var (
@@ -38,6 +42,26 @@ var (
 	ErrFailure = errors.New("action failed")
 )
 
+const defaultFailContextKey contextKey = "planDefaultFailKey"
+
+// WithoutDefaultFail returns ctx in which the action id returns its errors
+// instead of routing them to the config's DefaultFail step, for callers such
+// as retry that handle the error themselves. A Fail the action sets itself
+// still applies.
+func WithoutDefaultFail(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, defaultFailContextKey, id)
+}
+
+// routesToFail reports whether err from the action id goes to its fail step.
+// failAll is set when that step is the config's DefaultFail.
+func routesToFail(ctx context.Context, id string, failAll bool, err error) bool {
+	if failAll {
+		skip, _ := ctx.Value(defaultFailContextKey).(string)
+		return skip != id
+	}
+	return errors.Is(err, ErrFailure)
+}
+
 func (a *Action) ID() string {
 	return a.id
 }
@@ -113,18 +137,36 @@ func (a *Action) execute(ctx context.Context) (*stepWrapper, error) {
 		span.SetAttributes(attribute.String(k, reqCtx.Scrub(v)))
 	}
 
+	var usedFallback bool
+	if err != nil && a.fallback != nil {
+		if fb, source, ok := a.fallback.recover(ctx, err); ok {
+			logger.Warn("action failed, continuing with fallback output",
+				zap.String("fallback_source", source), zap.Error(err))
+			span.SetAttributes(attribute.String("sf.fallback", source))
+			resp, err, usedFallback = fb, nil, true
+		}
+	}
+
 	if err != nil {
+		if errors.Is(err, ErrShortCircuit) {
+			logger.Debug("action short-circuited the flow", zap.Error(err))
+			return nil, err
+		}
 		// Executable errors may embed resolved config (URLs, connection
 		// strings with secrets) — scrub before anything records or stores them.
 		errMsg := reqCtx.Scrub(err.Error())
 		span.RecordError(errors.New(errMsg))
-		if errors.Is(err, ErrFailure) {
+		if routesToFail(ctx, a.id, a.failAll, err) {
+			if !errors.Is(err, ErrFailure) {
+				logger.Warn("action failed, routing to the default fail step", zap.String("fail_step", a.fail.id), zap.String("error", errMsg))
+			}
 			if err := requestctx.AddRequestVariables(ctx, map[string]interface{}{requestctx.ErrorTagStripped: errMsg}, ""); err != nil {
 				return nil, err
 			}
-			if err := requestctx.AddRequestVariables(ctx, map[string]interface{}{a.out: fmt.Sprintf("error: %v", errMsg)}, ""); err != nil {
+			if err := requestctx.AddActionOutput(ctx, a.out, fmt.Sprintf("error: %v", errMsg)); err != nil {
 				return nil, err
 			}
+			failActionRun(ctx)
 			return a.fail, nil
 		}
 		logger.Error("error executing action", zap.Error(err))
@@ -151,9 +193,12 @@ func (a *Action) execute(ctx context.Context) (*stepWrapper, error) {
 		reqCtx.AddActionFile(a.out, fileValue)
 		logger.Debug("stored action output as file", zap.String("action_output", a.out))
 	} else {
-		if err = requestctx.AddRequestVariables(ctx, map[string]interface{}{a.out: resp}, ""); err != nil {
+		if err = requestctx.AddActionOutput(ctx, a.out, resp); err != nil {
 			return nil, err
 		}
+		if !usedFallback {
+			a.fallback.remember(ctx, resp)
+		}
 	}
 
 	// Fire off dispatch chains in background
@@ -28,6 +28,10 @@ type ActionV2 struct {
 	name       string
 	useReplica bool
 	dispatch   []string
+	fallback   *fallback
+	// failAll routes every error to fail, not only ErrFailure; it is set when
+	// fail is the planner's DefaultFail.
+	failAll bool
 }
 
 func (a *ActionV2) ID() string {
@@ -77,17 +81,35 @@ func (a *ActionV2) execute(ctx context.Context) (*stepWrapper, error) {
 		span.SetAttributes(attribute.String(k, reqCtx.Scrub(v)))
 	}
 
+	var usedFallback bool
+	if err != nil && a.fallback != nil {
+		if fb, source, ok := a.fallback.recover(ctx, err); ok {
+			logger.Warn("action failed, continuing with fallback output",
+				zap.String("fallback_source", source), zap.Error(err))
+			span.SetAttributes(attribute.String("sf.fallback", source))
+			resp, err, usedFallback = fb, nil, true
+		}
+	}
+
 	if err != nil {
+		if errors.Is(err, ErrShortCircuit) {
+			logger.Debug("action short-circuited the flow", zap.Error(err))
+			return nil, err
+		}
 		errMsg := reqCtx.Scrub(err.Error())
 		span.RecordError(errors.New(errMsg))
 		span.SetStatus(codes.Error, errMsg)
-		if errors.Is(err, ErrFailure) {
+		if routesToFail(ctx, a.id, a.failAll, err) {
+			if !errors.Is(err, ErrFailure) {
+				logger.Warn("action failed, routing to the default fail step", zap.String("fail_step", a.fail.id), zap.String("error", errMsg))
+			}
 			if err := requestctx.AddRequestVariables(ctx, map[string]interface{}{requestctx.ErrorTagStripped: errMsg}, ""); err != nil {
 				return nil, err
 			}
-			if err := requestctx.AddRequestVariables(ctx, map[string]interface{}{a.id: fmt.Sprintf("error: %v", errMsg)}, ""); err != nil {
+			if err := requestctx.AddActionOutput(ctx, a.id, fmt.Sprintf("error: %v", errMsg)); err != nil {
 				return nil, err
 			}
+			failActionRun(ctx)
 			return a.fail, nil
 		}
 		logger.Error("error executing action", zap.Error(err))
@@ -112,9 +134,12 @@ func (a *ActionV2) execute(ctx context.Context) (*stepWrapper, error) {
 		reqCtx.AddActionFile(a.id, fileValue)
 		logger.Debug("stored action output as file", zap.String("action_output", a.id))
 	} else {
-		if err = requestctx.AddRequestVariables(ctx, map[string]interface{}{a.id: resp}, ""); err != nil {
+		if err = requestctx.AddActionOutput(ctx, a.id, resp); err != nil {
 			return nil, err
 		}
+		if !usedFallback {
+			a.fallback.remember(ctx, resp)
+		}
 	}
 
 	// Fire off dispatch chains in background
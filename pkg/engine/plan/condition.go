@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/Servflow/servflow/pkg/apiconfig"
@@ -19,6 +21,10 @@ import (
 const (
 	ConditionalTypeTemplate   = "template"
 	ConditionalTypeStructured = "structured"
+	// ConditionalTypeVariable branches on a variable that already holds a
+	// boolean, e.g. an action output: the expression is the bare reference
+	// (.variable_actions_check.allowed).
+	ConditionalTypeVariable = "variable"
 
 	FunctionEmail    = "email"
 	FunctionEmpty    = "empty"
@@ -30,12 +36,19 @@ const (
 	FunctionLe       = "le"
 	FunctionGt       = "gt"
 	FunctionGe       = "ge"
+	// FunctionFlag takes the feature flag name as the comparison and the
+	// bucketing key (e.g. a user id) as the content.
+	FunctionFlag = "flag"
 
 	TemplateFalse  = "{{ false }}"
 	TemplatePrefix = "{{"
 	TemplateSuffix = "}}"
 	TemplateOr     = "or"
 	TemplateAnd    = "and"
+
+	// defaultItemTitle names the field of a validating item without a title
+	// in its error messages.
+	defaultItemTitle = "field"
 )
 
 type ConditionStep struct {
@@ -44,6 +57,12 @@ type ConditionStep struct {
 	OnValid    *stepWrapper
 	OnInvalid  *stepWrapper
 	exprString string
+	// boolean requires the expression to resolve to a boolean, failing the
+	// step otherwise instead of treating anything but "true" as false.
+	boolean bool
+	// fieldPaths maps the title of each validating item of a structured
+	// condition to the path it checks, for logging failed validations.
+	fieldPaths map[string]string
 }
 
 func (c *ConditionStep) ID() string {
@@ -89,6 +108,7 @@ func (c *ConditionStep) execute(ctx context.Context) (*stepWrapper, error) {
 		return nil, fmt.Errorf("error creating template for condition %w template: %s", err, c.exprString)
 	}
 
+	failedBefore := len(reqCtx.ValidationErrors())
 	resp, err := requestctx.ExecuteTemplateFromContext(ctx, tmpl)
 	if err != nil {
 		logger.Error("error executing template",
@@ -103,8 +123,23 @@ func (c *ConditionStep) execute(ctx context.Context) (*stepWrapper, error) {
 		logger.Error("error adding validation error", zap.Error(err))
 		return nil, err
 	}
+	c.logValidationFailures(logger, reqCtx.ValidationErrors()[failedBefore:])
 
 	logger.Debug("condition evaluated to "+resp, zap.String("condition", c.exprString))
+	if c.boolean {
+		result, err := strconv.ParseBool(strings.TrimSpace(resp))
+		if err != nil {
+			err = fmt.Errorf("condition %s: %q is not a boolean", c.id, resp)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		span.SetAttributes(attribute.Bool("sf.result", result))
+		if result {
+			return c.OnValid, nil
+		}
+		return c.OnInvalid, nil
+	}
 	if strings.TrimSpace(resp) == "true" {
 		span.SetAttributes(attribute.Bool("sf.result", true))
 		return c.OnValid, nil
@@ -113,6 +148,43 @@ func (c *ConditionStep) execute(ctx context.Context) (*stepWrapper, error) {
 	return c.OnInvalid, nil
 }
 
+// logValidationFailures writes one entry per validation that failed while the
+// condition ran, so the common form errors of an endpoint can be analyzed.
+func (c *ConditionStep) logValidationFailures(logger *zap.Logger, failures []*requestctx.ValidationError) {
+	for _, ve := range failures {
+		fields := []zap.Field{
+			zap.String("field", ve.Field),
+			zap.String("function", ve.Function),
+			zap.String("message", ve.Error()),
+		}
+		if path, ok := c.fieldPaths[ve.Field]; ok {
+			fields = append(fields, zap.String("field_path", path))
+		}
+		logger.Info("validation failed", fields...)
+	}
+}
+
+// structureFieldPaths maps the title of every validating item in structure to
+// the path of its content; the first item wins when titles repeat.
+func structureFieldPaths(structure [][]apiconfig.ConditionItem) map[string]string {
+	paths := make(map[string]string)
+	for _, group := range structure {
+		for _, item := range group {
+			if spec, ok := conditionalFunctionSpecs[item.Function]; !ok || !spec.RequiresTitle {
+				continue
+			}
+			title := item.Title
+			if title == "" {
+				title = defaultItemTitle
+			}
+			if _, ok := paths[title]; !ok {
+				paths[title] = strings.TrimSpace(item.Content)
+			}
+		}
+	}
+	return paths
+}
+
 type ConditionalFunctionSpec struct {
 	Template           string
 	RequiresTitle      bool
@@ -170,6 +242,31 @@ var conditionalFunctionSpecs = map[string]ConditionalFunctionSpec{
 		RequiresTitle:      false,
 		RequiresComparison: true,
 	},
+	FunctionFlag: {
+		Template:           "flag (\"%s\") (%s)",
+		RequiresTitle:      false,
+		RequiresComparison: true,
+	},
+}
+
+// VariableToTemplate turns a variable reference such as
+// .variable_actions_check or variable_actions_check.allowed into the template
+// printing it. A reference already wrapped in {{ }} is returned as is.
+func VariableToTemplate(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", errors.New("variable condition requires a variable reference")
+	}
+	if strings.HasPrefix(ref, TemplatePrefix) {
+		return ref, nil
+	}
+	if strings.ContainsAny(ref, " ()|") {
+		return "", fmt.Errorf("variable condition expects a variable reference, got %q", ref)
+	}
+	if !strings.HasPrefix(ref, ".") {
+		ref = "." + ref
+	}
+	return fmt.Sprintf("%s %s %s", TemplatePrefix, ref, TemplateSuffix), nil
 }
 
 func ConvertStructureToTemplate(structure [][]apiconfig.ConditionItem) (string, error) {
@@ -219,21 +316,70 @@ func generateConditionItemTemplate(item apiconfig.ConditionItem) (string, error)
 	}
 
 	if spec.RequiresTitle && item.Title == "" {
-		item.Title = "field"
+		item.Title = defaultItemTitle
 	}
 
 	if spec.RequiresComparison && item.Comparison == "" {
 		return "", fmt.Errorf("function '%s' requires a comparison field", item.Function)
 	}
 
+	item.Content = indexPath(item.Content)
+	item.Comparison = indexPath(item.Comparison)
+
 	switch item.Function {
 	case FunctionEmail, FunctionEmpty, FunctionNotempty:
-		return fmt.Sprintf(spec.Template, item.Content, item.Title), nil
+		return fmt.Sprintf(spec.Template, item.Content, item.Title) + messageArg(item.Message), nil
 	case FunctionBcrypt:
-		return fmt.Sprintf(spec.Template, item.Content, item.Comparison, item.Title), nil
+		return fmt.Sprintf(spec.Template, item.Content, item.Comparison, item.Title) + messageArg(item.Message), nil
 	case FunctionEq, FunctionNe, FunctionLt, FunctionLe, FunctionGt, FunctionGe:
 		return fmt.Sprintf(spec.Template, item.Content, item.Comparison), nil
+	case FunctionFlag:
+		return fmt.Sprintf(spec.Template, item.Comparison, item.Content), nil
 	default:
 		return "", fmt.Errorf("unhandled function: %s", item.Function)
 	}
 }
+
+// fieldPathPattern matches a plain field path such as .items.0.price.
+var fieldPathPattern = regexp.MustCompile(`^\.[A-Za-z_]\w*(\.\w+)*$`)
+
+// indexPath rewrites a field path that steps into arrays by position, which
+// templates cannot express with dots, into an index expression:
+// .items.0.price becomes index .items 0 "price". Anything else is returned as
+// it is.
+func indexPath(expr string) string {
+	path := strings.TrimSpace(expr)
+	if !fieldPathPattern.MatchString(path) {
+		return expr
+	}
+	segments := strings.Split(path[1:], ".")
+	first := -1
+	for i, seg := range segments {
+		if _, err := strconv.Atoi(seg); err == nil {
+			first = i
+			break
+		}
+	}
+	if first < 1 {
+		return expr
+	}
+
+	args := []string{"index", "." + strings.Join(segments[:first], ".")}
+	for _, seg := range segments[first:] {
+		if _, err := strconv.Atoi(seg); err == nil {
+			args = append(args, seg)
+		} else {
+			args = append(args, strconv.Quote(seg))
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// messageArg renders a custom validation message as the trailing argument of a
+// validating template function, or nothing when the default should be used.
+func messageArg(message string) string {
+	if message == "" {
+		return ""
+	}
+	return " (" + strconv.Quote(message) + ")"
+}
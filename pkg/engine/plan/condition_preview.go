@@ -0,0 +1,105 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+)
+
+// ConditionPreview is the outcome of evaluating a structured condition
+// against sample variables, outside of any flow.
+type ConditionPreview struct {
+	Passed bool `json:"passed"`
+	// Errors are the validation errors the condition pushed into the error
+	// variable, empty when none failed.
+	Errors []string `json:"errors"`
+	// Template is the expression the structure was compiled to.
+	Template string `json:"template"`
+}
+
+// PreviewCondition compiles structure the way a conditional step would and
+// evaluates it in a fresh request whose variables are variables, so config
+// authors can check a condition before deploying it.
+func PreviewCondition(ctx context.Context, structure [][]apiconfig.ConditionItem, variables map[string]interface{}) (*ConditionPreview, error) {
+	for i, group := range structure {
+		for j, item := range group {
+			if err := checkPreviewItem(item); err != nil {
+				return nil, fmt.Errorf("structure[%d][%d]: %w", i, j, err)
+			}
+		}
+	}
+	expr, err := ConvertStructureToTemplate(structure)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, rc := requestctx.Start(ctx, requestctx.Options{ID: "condition_preview"})
+	defer rc.Done()
+	if err := requestctx.AddRequestVariables(ctx, variables, ""); err != nil {
+		return nil, err
+	}
+
+	passed := &stepWrapper{id: "passed"}
+	c := ConditionStep{id: "preview", exprString: expr, OnValid: passed, OnInvalid: &stepWrapper{id: "failed"}}
+	next, err := c.execute(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error evaluating condition: %w", err)
+	}
+
+	preview := &ConditionPreview{Passed: next == passed, Errors: []string{}, Template: expr}
+	if errs, _ := requestctx.GetRequestVariable(ctx, requestctx.ErrorTagStripped); errs != nil {
+		if msgs, ok := errs.([]string); ok {
+			preview.Errors = msgs
+		}
+	}
+	return preview, nil
+}
+
+// flagNamePattern matches the name of a feature flag.
+var flagNamePattern = regexp.MustCompile(`^[\w.-]+$`)
+
+// checkPreviewItem rejects items whose operands are anything but field paths
+// or literals. Previews come from outside any config, and operands are
+// spliced into the template unchanged, so without the check a caller could
+// evaluate arbitrary template functions such as secret.
+func checkPreviewItem(item apiconfig.ConditionItem) error {
+	if !isPlainOperand(item.Content) {
+		return fmt.Errorf("content %q must be a field path such as .user.email or a literal", item.Content)
+	}
+	switch {
+	case item.Function == FunctionFlag:
+		if !flagNamePattern.MatchString(item.Comparison) {
+			return fmt.Errorf("comparison %q must be a flag name", item.Comparison)
+		}
+	case item.Comparison != "" && !isPlainOperand(item.Comparison):
+		return fmt.Errorf("comparison %q must be a field path such as .user.email or a literal", item.Comparison)
+	}
+	// the title is quoted by the template as it is
+	if strconv.Quote(item.Title) != `"`+item.Title+`"` {
+		return fmt.Errorf("title %q must not contain quotes or escapes", item.Title)
+	}
+	return nil
+}
+
+// isPlainOperand reports whether s is a field path, a number, a boolean, nil
+// or a quoted string.
+func isPlainOperand(s string) bool {
+	s = strings.TrimSpace(s)
+	switch {
+	case fieldPathPattern.MatchString(s):
+		return true
+	case s == "true" || s == "false" || s == "nil":
+		return true
+	case strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "`"):
+		_, err := strconv.Unquote(s)
+		return err == nil
+	default:
+		_, err := strconv.ParseFloat(s, 64)
+		return err == nil
+	}
+}
@@ -5,9 +5,12 @@ import (
 
 	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
 	requestctx2 "github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -84,6 +87,190 @@ func TestConditionStep_Execute(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, []string{"email is not a valid email address"}, errVal)
 	})
+	t.Run("fail with custom messages", func(t *testing.T) {
+		expr, err := ConvertStructureToTemplate([][]apiconfig.ConditionItem{
+			{{Content: ".test", Function: FunctionEmail, Title: "email", Message: "Veuillez saisir une adresse e-mail valide"}},
+			{{Content: ".name", Function: FunctionNotempty, Title: "name"}},
+		})
+		require.NoError(t, err)
+		condition := ConditionStep{
+			OnValid:    &stepWrapper{id: "valid", step: validStep},
+			OnInvalid:  &stepWrapper{id: "invalid", step: invalidStep},
+			exprString: expr,
+		}
+
+		ctx := requestctx2.NewTestContext()
+		requestctx2.AddRequestVariables(ctx, map[string]interface{}{"test": "value", "name": ""}, "")
+		next, err := condition.execute(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, &stepWrapper{id: "invalid", step: invalidStep}, next)
+
+		errVal, err := requestctx2.GetRequestVariable(ctx, requestctx2.ErrorTagStripped)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Veuillez saisir une adresse e-mail valide", "name can not be empty"}, errVal)
+	})
+}
+
+func TestConditionStep_Variable(t *testing.T) {
+	validStep := &stepWrapper{id: "valid", step: &testStep{id: "valid"}}
+	invalidStep := &stepWrapper{id: "invalid", step: &testStep{id: "invalid"}}
+
+	testCases := []struct {
+		name     string
+		value    interface{}
+		expected *stepWrapper
+		errMsg   string
+	}{
+		{name: "true routes to onTrue", value: true, expected: validStep},
+		{name: "false routes to onFalse", value: false, expected: invalidStep},
+		{name: "boolean string is coerced", value: "TRUE", expected: validStep},
+		{name: "non-boolean value is an error", value: "maybe", errMsg: `"maybe" is not a boolean`},
+		{name: "missing value is an error", value: nil, errMsg: `"" is not a boolean`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := VariableToTemplate(".variable_actions_check.allowed")
+			require.NoError(t, err)
+			condition := ConditionStep{id: "canProceed", OnValid: validStep, OnInvalid: invalidStep, exprString: expr, boolean: true}
+
+			ctx := requestctx2.NewTestContext()
+			check := map[string]interface{}{}
+			if tc.value != nil {
+				check["allowed"] = tc.value
+			}
+			requestctx2.AddRequestVariables(ctx, map[string]interface{}{"check": check}, "")
+
+			next, err := condition.execute(ctx)
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, "condition canProceed: "+tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, next)
+		})
+	}
+}
+
+func TestConditionStep_LogsValidationFailures(t *testing.T) {
+	structure := [][]apiconfig.ConditionItem{
+		{{Content: ".body.email", Function: FunctionEmail, Title: "email"}},
+		{{Content: ".body.name", Function: FunctionNotempty, Title: "name", Message: "name is required"}},
+		{{Content: ".body.age", Comparison: "18", Function: FunctionGt}},
+	}
+	expr, err := ConvertStructureToTemplate(structure)
+	require.NoError(t, err)
+	condition := ConditionStep{
+		id:         "validate",
+		OnValid:    &stepWrapper{id: "valid", step: &testStep{id: "valid"}},
+		OnInvalid:  &stepWrapper{id: "invalid", step: &testStep{id: "invalid"}},
+		exprString: expr,
+		fieldPaths: structureFieldPaths(structure),
+	}
+
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := logging.WithLogger(requestctx2.NewTestContext(), zap.New(core))
+	require.NoError(t, requestctx2.SetRequestInputs(ctx, requestctx2.BodyNamespace, map[string]interface{}{
+		"email": "not-an-email",
+		"name":  "",
+		"age":   12,
+	}))
+
+	next, err := condition.execute(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "invalid", next.id)
+
+	entries := logs.FilterMessage("validation failed").All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, map[string]interface{}{
+		"conditional_id":   "validate",
+		"conditional_name": "",
+		"field":            "email",
+		"field_path":       ".body.email",
+		"function":         FunctionEmail,
+		"message":          "email is not a valid email address",
+	}, entries[0].ContextMap())
+	assert.Equal(t, map[string]interface{}{
+		"conditional_id":   "validate",
+		"conditional_name": "",
+		"field":            "name",
+		"field_path":       ".body.name",
+		"function":         FunctionNotempty,
+		"message":          "name is required",
+	}, entries[1].ContextMap())
+}
+
+func TestConditionStep_ArrayElement(t *testing.T) {
+	validStep := &stepWrapper{id: "valid", step: &testStep{id: "valid"}}
+	invalidStep := &stepWrapper{id: "invalid", step: &testStep{id: "invalid"}}
+
+	expr, err := ConvertStructureToTemplate([][]apiconfig.ConditionItem{{
+		{Content: ".items.1.price", Comparison: "50", Function: FunctionGe},
+		{Content: ".items.0.email", Function: FunctionEmail, Title: "first email"},
+	}})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		items    []interface{}
+		expected *stepWrapper
+	}{
+		{
+			name: "element fields pass",
+			items: []interface{}{
+				map[string]interface{}{"email": "ada@example.com", "price": 10},
+				map[string]interface{}{"price": 75},
+			},
+			expected: validStep,
+		},
+		{
+			name: "second element too cheap",
+			items: []interface{}{
+				map[string]interface{}{"email": "ada@example.com", "price": 10},
+				map[string]interface{}{"price": 20},
+			},
+			expected: invalidStep,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			condition := ConditionStep{OnValid: validStep, OnInvalid: invalidStep, exprString: expr}
+			ctx := requestctx2.NewTestContext()
+			require.NoError(t, requestctx2.AddRequestVariables(ctx, map[string]interface{}{"items": tc.items}, ""))
+
+			next, err := condition.execute(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, next)
+		})
+	}
+}
+
+func TestVariableToTemplate(t *testing.T) {
+	testCases := []struct {
+		ref      string
+		expected string
+		errMsg   string
+	}{
+		{ref: ".variable_actions_check", expected: "{{ .variable_actions_check }}"},
+		{ref: "variable_actions_check.allowed", expected: "{{ .variable_actions_check.allowed }}"},
+		{ref: "{{ .variable_actions_check }}", expected: "{{ .variable_actions_check }}"},
+		{ref: "", errMsg: "requires a variable reference"},
+		{ref: "eq .a .b", errMsg: "expects a variable reference"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ref, func(t *testing.T) {
+			got, err := VariableToTemplate(tc.ref)
+			if tc.errMsg != "" {
+				assert.ErrorContains(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
 }
 
 func TestConditionTemplateFunctions(t *testing.T) {
@@ -259,6 +446,27 @@ func TestGenerateConditionItemTemplate(t *testing.T) {
 			},
 			expected: "bcrypt (.password) (.storedHash) (\"Password\")",
 		},
+		{
+			name: "custom message",
+			item: apiconfig.ConditionItem{
+				Content:  ".email",
+				Function: FunctionEmail,
+				Title:    "Email",
+				Message:  `Saisissez une adresse "valide"`,
+			},
+			expected: `email (.email) ("Email") ("Saisissez une adresse \"valide\"")`,
+		},
+		{
+			name: "bcrypt custom message",
+			item: apiconfig.ConditionItem{
+				Content:    ".password",
+				Comparison: ".storedHash",
+				Function:   FunctionBcrypt,
+				Title:      "Password",
+				Message:    "Wrong password",
+			},
+			expected: `bcrypt (.password) (.storedHash) ("Password") ("Wrong password")`,
+		},
 		{
 			name: "bcrypt missing comparison",
 			item: apiconfig.ConditionItem{
@@ -339,6 +547,50 @@ func TestGenerateConditionItemTemplate(t *testing.T) {
 			},
 			expected: "ge (.quantity) (1)",
 		},
+		{
+			name: "array element field",
+			item: apiconfig.ConditionItem{
+				Content:    ".items.0.price",
+				Comparison: "100",
+				Function:   FunctionGt,
+			},
+			expected: `gt (index .items 0 "price") (100)`,
+		},
+		{
+			name: "nested array indexes",
+			item: apiconfig.ConditionItem{
+				Content:  ".order.lines.1.tags.0",
+				Function: FunctionNotempty,
+				Title:    "tag",
+			},
+			expected: `notempty (index .order.lines 1 "tags" 0) ("tag")`,
+		},
+		{
+			name: "array element on both sides",
+			item: apiconfig.ConditionItem{
+				Content:    ".items.0.sku",
+				Comparison: ".items.1.sku",
+				Function:   FunctionNe,
+			},
+			expected: `ne (index .items 0 "sku") (index .items 1 "sku")`,
+		},
+		{
+			name: "flag",
+			item: apiconfig.ConditionItem{
+				Content:    `param "user_id"`,
+				Comparison: "new_checkout",
+				Function:   FunctionFlag,
+			},
+			expected: `flag ("new_checkout") (param "user_id")`,
+		},
+		{
+			name: "flag missing name",
+			item: apiconfig.ConditionItem{
+				Content:  `param "user_id"`,
+				Function: FunctionFlag,
+			},
+			hasError: true,
+		},
 		{
 			name: "eq missing comparison",
 			item: apiconfig.ConditionItem{
@@ -0,0 +1,186 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// dependentStep wraps an action that declares dependsOn. Before the action
+// runs, every action it transitively depends on that has not run yet in this
+// request runs, dependencies first, counted against the step cap and between
+// the plan's hooks like any other action. A dependency runs on its own: its
+// next step is not followed. If one fails, the flow continues on that
+// dependency's fail branch instead.
+//
+// A dependency that is still running on another branch is waited for rather
+// than run again, and one that already failed in this request fails the
+// action.
+type dependentStep struct {
+	id   string
+	deps []string // bare action ids in execution order
+	step Step
+}
+
+func (d *dependentStep) execute(ctx context.Context) (*stepWrapper, error) {
+	p, ok := ctx.Value(ContextKey).(*Plan)
+	if !ok {
+		return nil, errors.New("plan not found")
+	}
+	reqCtx, ok := requestctx.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("invalid request context")
+	}
+
+	logger := logging.FromContext(ctx)
+	for _, dep := range d.deps {
+		run, claimed := reqCtx.ClaimActionRun(dep)
+		if !claimed {
+			succeeded, err := run.Wait(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if !succeeded {
+				return nil, fmt.Errorf("dependency %s of %s failed", dep, d.id)
+			}
+			continue
+		}
+
+		next, err := d.runDependency(withActionRun(ctx, run), p, dep)
+		if err != nil {
+			run.Fail()
+		}
+		run.Finish()
+		if err != nil {
+			return nil, err
+		}
+		if run.Failed() {
+			logger.Debug("dependency failed, following its fail branch", zap.String("dependency", dep))
+			failActionRun(ctx)
+			return next, nil
+		}
+	}
+	return d.step.execute(ctx)
+}
+
+// runDependency runs the action dep on its own and returns the step its
+// flow continues with.
+func (d *dependentStep) runDependency(ctx context.Context, p *Plan, dep string) (*stepWrapper, error) {
+	id := apiconfig.ActionConfigPrefix + dep
+	wr, ok := p.steps[id]
+	if !ok {
+		return nil, fmt.Errorf("dependency of %s not found: %s", d.id, dep)
+	}
+	step := wr.step
+	if inner, ok := step.(*dependentStep); ok {
+		// its own dependencies come earlier in d.deps
+		step = inner.step
+	}
+	if err := p.admitStep(ctx, id); err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("running dependency", zap.String("dependency", dep))
+	return p.runStep(ctx, &stepWrapper{id: id, step: step})
+}
+
+const actionRunContextKey contextKey = "planActionRunKey"
+
+// withActionRun returns ctx carrying run, the run of the action step about to
+// execute, so the action can record its own failure (see failActionRun).
+func withActionRun(ctx context.Context, run *requestctx.ActionRun) context.Context {
+	return context.WithValue(ctx, actionRunContextKey, run)
+}
+
+// failActionRun marks the run of the executing action as failed. Actions call
+// it when they route a failure to their fail step rather than returning it.
+func failActionRun(ctx context.Context) {
+	if run, ok := ctx.Value(actionRunContextKey).(*requestctx.ActionRun); ok {
+		run.Fail()
+	}
+}
+
+// successor is the step an action continues to when it succeeds.
+func successor(step Step) *stepWrapper {
+	switch s := step.(type) {
+	case *Action:
+		return s.next
+	case *ActionV2:
+		return s.next
+	case *dependentStep:
+		return successor(s.step)
+	case *guardedStep:
+		return successor(s.step)
+	default:
+		return nil
+	}
+}
+
+// dependencyOrder returns the actions id transitively depends on, each after
+// its own dependencies. Actions listed earlier in dependsOn come first among
+// siblings. A dependency cycle is returned as a *CycleError.
+func dependencyOrder(actions map[string]apiconfig.Action, id string) ([]string, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var (
+		order []string
+		path  []string
+	)
+
+	var visit func(cur string) error
+	visit = func(cur string) error {
+		canonical := apiconfig.ActionConfigPrefix + cur
+		switch state[cur] {
+		case visiting:
+			cyc := append(append([]string{}, path[indexOf(path, canonical):]...), canonical)
+			return &CycleError{Path: cyc}
+		case visited:
+			return nil
+		}
+		state[cur] = visiting
+		path = append(path, canonical)
+		for _, ref := range actions[cur].DependsOn {
+			dep, err := dependencyID(actions, ref)
+			if err != nil {
+				return fmt.Errorf("%s dependsOn: %w", canonical, err)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[cur] = visited
+		if cur != id {
+			order = append(order, cur)
+		}
+		return nil
+	}
+
+	if err := visit(id); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// dependencyID resolves a dependsOn entry to a bare action id.
+func dependencyID(actions map[string]apiconfig.Action, ref string) (string, error) {
+	kind, bareID, _, err := apiconfig.ParseStepRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if kind != apiconfig.StepKindAction {
+		return "", fmt.Errorf("can only depend on actions, got %q", ref)
+	}
+	if _, ok := actions[bareID]; !ok {
+		return "", fmt.Errorf("unknown action: %s", bareID)
+	}
+	return bareID, nil
+}
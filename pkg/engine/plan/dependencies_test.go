@@ -0,0 +1,228 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDependentStep_Execute(t *testing.T) {
+	// diamond: d depends on b and c, which both depend on a
+	diamond := map[string]apiconfig.Action{
+		"a": {Name: "a", Type: "a", Next: "response.done"},
+		"b": {Name: "b", Type: "b", Next: "response.done", DependsOn: []string{"action.a"}},
+		"c": {Name: "c", Type: "c", Next: "response.done", DependsOn: []string{"action.a"}},
+		"d": {Name: "d", Type: "d", Next: "response.done", Fail: "response.failed", DependsOn: []string{"action.b", "action.c"}},
+	}
+	responses := map[string]apiconfig.ResponseConfig{
+		"done":   {Name: "done", Code: 200, Type: "template", Template: "done"},
+		"failed": {Name: "failed", Code: 500, Type: "template", Template: "failed"},
+	}
+
+	newPlan := func(t *testing.T, actionCfgs map[string]apiconfig.Action, failing string, configure ...func(*PlannerConfig)) (*Plan, *[]string) {
+		ctrl := gomock.NewController(t)
+		registry := actions.NewRegistry()
+		var (
+			mu    sync.Mutex
+			order []string
+		)
+		for id := range actionCfgs {
+			exec := NewMockActionExecutable(ctrl)
+			exec.EXPECT().Config().Return("").AnyTimes()
+			exec.EXPECT().Type().Return("mock").AnyTimes()
+			exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+			exec.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, string) (interface{}, map[string]string, error) {
+				mu.Lock()
+				order = append(order, id)
+				mu.Unlock()
+				if id == failing {
+					return nil, nil, fmt.Errorf("%w: %s", ErrFailure, id)
+				}
+				return id, nil, nil
+			}).AnyTimes()
+			registry.ReplaceActionType(id, func(config json.RawMessage) (actions.ActionExecutable, error) {
+				return exec, nil
+			})
+		}
+
+		cfg := PlannerConfig{
+			Actions:        actionCfgs,
+			Responses:      responses,
+			CustomRegistry: registry,
+		}
+		for _, f := range configure {
+			f(&cfg)
+		}
+		p, err := NewPlannerV2(cfg, silentLogger()).Plan()
+		require.NoError(t, err)
+		return p, &order
+	}
+
+	t.Run("diamond runs every dependency once, in order", func(t *testing.T) {
+		p, order := newPlan(t, diamond, "")
+
+		resp, err := p.Execute(requestctx.NewTestContext(), "action.d")
+		require.NoError(t, err)
+		assert.Equal(t, "done", string(resp.(*sfhttp.SfResponse).Body))
+		assert.Equal(t, []string{"a", "b", "c", "d"}, *order)
+	})
+
+	t.Run("dependencies that already ran are skipped", func(t *testing.T) {
+		p, order := newPlan(t, diamond, "")
+		ctx := requestctx.NewTestContext()
+
+		_, err := p.Execute(ctx, "action.b")
+		require.NoError(t, err)
+		_, err = p.Execute(ctx, "action.d")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c", "d"}, *order)
+	})
+
+	t.Run("failed dependency follows its fail branch", func(t *testing.T) {
+		cfgs := map[string]apiconfig.Action{}
+		for id, act := range diamond {
+			cfgs[id] = act
+		}
+		b := cfgs["b"]
+		b.Fail = "response.failed"
+		cfgs["b"] = b
+		p, order := newPlan(t, cfgs, "b")
+
+		resp, err := p.Execute(requestctx.NewTestContext(), "action.d")
+		require.NoError(t, err)
+		assert.Equal(t, "failed", string(resp.(*sfhttp.SfResponse).Body))
+		assert.Equal(t, []string{"a", "b"}, *order)
+	})
+
+	t.Run("dependencies run between hooks", func(t *testing.T) {
+		var before []string
+		p, order := newPlan(t, diamond, "", func(cfg *PlannerConfig) {
+			cfg.Hooks.BeforeAction = func(ctx context.Context, stepID string) (context.Context, error) {
+				before = append(before, stepID)
+				return ctx, nil
+			}
+		})
+
+		_, err := p.Execute(requestctx.NewTestContext(), "action.d")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c", "d"}, *order)
+		assert.Equal(t, []string{"action.d", "action.a", "action.b", "action.c"}, before)
+	})
+
+	t.Run("dependencies count toward the step cap", func(t *testing.T) {
+		p, order := newPlan(t, diamond, "", func(cfg *PlannerConfig) {
+			cfg.MaxSteps = 3
+		})
+
+		_, err := p.Execute(requestctx.NewTestContext(), "action.d")
+		assert.ErrorIs(t, err, ErrMaxStepsExceeded)
+		assert.Equal(t, []string{"a", "b"}, *order)
+	})
+
+	t.Run("dependency running on another branch is waited for, not run again", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		p, order := newPlan(t, diamond, "", func(cfg *PlannerConfig) {
+			cfg.Hooks.BeforeAction = func(ctx context.Context, stepID string) (context.Context, error) {
+				if stepID == "action.a" {
+					close(started)
+					<-release
+				}
+				return ctx, nil
+			}
+		})
+		ctx := requestctx.NewTestContext()
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := p.Execute(ctx, "action.a")
+			assert.NoError(t, err)
+		}()
+		<-started
+		go func() {
+			defer wg.Done()
+			_, err := p.Execute(ctx, "action.b")
+			assert.NoError(t, err)
+		}()
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, []string{"a", "b"}, *order)
+	})
+
+	t.Run("dependency that failed without a fail branch is not satisfied", func(t *testing.T) {
+		p, order := newPlan(t, diamond, "a")
+		ctx := requestctx.NewTestContext()
+
+		_, err := p.Execute(ctx, "action.a")
+		require.NoError(t, err)
+		_, err = p.Execute(ctx, "action.b")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "dependency a of b failed")
+		assert.Equal(t, []string{"a"}, *order)
+	})
+}
+
+func TestPlannerV2_DependencyCycle(t *testing.T) {
+	cfgs := map[string]apiconfig.Action{
+		"a": {Name: "a", Next: "response.done", DependsOn: []string{"action.c"}},
+		"b": {Name: "b", Next: "response.done", DependsOn: []string{"action.a"}},
+		"c": {Name: "c", Next: "response.done", DependsOn: []string{"action.b"}},
+	}
+
+	ctrl := gomock.NewController(t)
+	exec := NewMockActionExecutable(ctrl)
+	exec.EXPECT().Config().Return("").AnyTimes()
+	registry := actions.NewRegistry()
+	registry.ReplaceActionType("", func(config json.RawMessage) (actions.ActionExecutable, error) {
+		return exec, nil
+	})
+
+	_, err := NewPlannerV2(PlannerConfig{
+		Actions:        cfgs,
+		Responses:      map[string]apiconfig.ResponseConfig{"done": {Name: "done", Code: 200}},
+		CustomRegistry: registry,
+	}, silentLogger()).Plan()
+	var cycle *CycleError
+	require.ErrorAs(t, err, &cycle)
+	assert.Len(t, cycle.Path, 4)
+	assert.Equal(t, cycle.Path[0], cycle.Path[3])
+}
+
+func TestDependencyOrder(t *testing.T) {
+	cfgs := map[string]apiconfig.Action{
+		"a": {Name: "a"},
+		"b": {Name: "b", DependsOn: []string{"action.a"}},
+		"c": {Name: "c", DependsOn: []string{"action.a"}},
+		"d": {Name: "d", DependsOn: []string{"action.c", "action.b"}},
+		"e": {Name: "e", DependsOn: []string{"response.done"}},
+		"f": {Name: "f", DependsOn: []string{"action.missing"}},
+	}
+
+	order, err := dependencyOrder(cfgs, "d")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c", "b"}, order)
+
+	order, err = dependencyOrder(cfgs, "a")
+	require.NoError(t, err)
+	assert.Empty(t, order)
+
+	_, err = dependencyOrder(cfgs, "e")
+	assert.ErrorContains(t, err, "can only depend on actions")
+
+	_, err = dependencyOrder(cfgs, "f")
+	assert.ErrorContains(t, err, "unknown action")
+}
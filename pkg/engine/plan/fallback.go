@@ -0,0 +1,126 @@
+package plan
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/cache"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// ErrFatal marks an action failure that must not be masked by a fallback,
+// e.g. a misconfiguration that no cached or default output can stand in for.
+// Executables wrap it: fmt.Errorf("%w: ...", plan.ErrFatal).
+var ErrFatal = errors.New("fatal action error")
+
+// IsFatal reports whether err must fail the flow even when the action has a
+// fallback: errors wrapping ErrFatal, short circuits, and cancellation of the
+// request itself.
+func IsFatal(ctx context.Context, err error) bool {
+	switch {
+	case errors.Is(err, ErrFatal), errors.Is(err, ErrShortCircuit):
+		return true
+	case ctx.Err() != nil:
+		return true
+	}
+	return false
+}
+
+// fallback substitutes an output for a failed action, see apiconfig.Fallback.
+type fallback struct {
+	value    interface{}
+	useCache bool
+	ttl      time.Duration
+	// keyPrefix scopes cached outputs to the action; keyTemplate is resolved
+	// per request so requests with different inputs never share an output.
+	keyPrefix   string
+	keyTemplate string
+}
+
+// newFallback builds the fallback for an action. actionConfig is the
+// action's unresolved config, used as the cache key template unless the
+// fallback sets its own CacheKey.
+func newFallback(configID, actionID string, actionConfig []byte, cfg *apiconfig.Fallback) (*fallback, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	f := &fallback{
+		value:       cfg.Value,
+		useCache:    cfg.Cache,
+		keyPrefix:   "fallback:" + configID + ":" + actionID + ":",
+		keyTemplate: cfg.CacheKey,
+	}
+	if f.keyTemplate == "" {
+		f.keyTemplate = string(actionConfig)
+	}
+	if cfg.CacheTTL != "" {
+		ttl, err := time.ParseDuration(cfg.CacheTTL)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid fallback cacheTTL %q for action %s", cfg.CacheTTL, actionID)
+		}
+		f.ttl = ttl
+	}
+	return f, nil
+}
+
+// remember stores a successful output for later fallbacks. Failing to cache
+// never fails the action.
+func (f *fallback) remember(ctx context.Context, resp interface{}) {
+	if f == nil || !f.useCache {
+		return
+	}
+	key, ok := f.cacheKey(ctx)
+	if !ok {
+		return
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	if err := cache.Default().Set(ctx, key, b, f.ttl); err != nil {
+		logging.FromContext(ctx).Warn("failed to cache action output for fallback", zap.Error(err))
+	}
+}
+
+// recover returns the output to use in place of a failed action. ok is false
+// when the failure is fatal and must propagate.
+func (f *fallback) recover(ctx context.Context, err error) (resp interface{}, source string, ok bool) {
+	if f == nil || IsFatal(ctx, err) {
+		return nil, "", false
+	}
+	if !f.useCache {
+		return f.value, "default", true
+	}
+	if key, keyOK := f.cacheKey(ctx); keyOK {
+		b, found, cacheErr := cache.Default().Get(ctx, key)
+		if cacheErr == nil && found {
+			var cached interface{}
+			if json.Unmarshal(b, &cached) == nil {
+				return cached, "cache", true
+			}
+		}
+	}
+	return f.value, "default", true
+}
+
+// cacheKey resolves the key template against the request and hashes it, so
+// secrets or large inputs in the resolved config never end up in the key.
+// ok is false when the template cannot be resolved, in which case the cache
+// is skipped rather than risk sharing an output between requests.
+func (f *fallback) cacheKey(ctx context.Context) (key string, ok bool) {
+	resolved, err := requestctx.ExecuteTemplateString(ctx, f.keyTemplate)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to resolve fallback cache key", zap.Error(err))
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(resolved))
+	return f.keyPrefix + hex.EncodeToString(sum[:]), true
+}
@@ -0,0 +1,209 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/cache"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// fallbackPlan plans a lookup action whose output is echoed by the response,
+// so a completed flow shows which output the action ended up with.
+func fallbackPlan(t *testing.T, exec actions.ActionExecutable, fb *apiconfig.Fallback) *Plan {
+	t.Helper()
+	return fallbackPlanWithConfig(t, exec, nil, fb)
+}
+
+func fallbackPlanWithConfig(t *testing.T, exec actions.ActionExecutable, config map[string]interface{}, fb *apiconfig.Fallback) *Plan {
+	t.Helper()
+	registry := actions.NewRegistry()
+	registry.ReplaceActionType("lookup", func(config json.RawMessage) (actions.ActionExecutable, error) {
+		return exec, nil
+	})
+	planner := NewPlannerV2(PlannerConfig{
+		ID: "pricing",
+		Actions: map[string]apiconfig.Action{
+			"lookup": {Name: "lookup", Type: "lookup", Config: config, Next: "response.ok", Fallback: fb},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"ok": {
+				Name: "ok",
+				Code: 200,
+				Object: apiconfig.ResponseObject{
+					Fields: map[string]apiconfig.ResponseObject{
+						"plan": {Value: "{{ .variable_actions_lookup.plan }}"},
+					},
+				},
+			},
+		},
+		CustomRegistry: registry,
+	}, logging.GetNewLogger())
+	p, err := planner.Plan()
+	require.NoError(t, err)
+	return p
+}
+
+func runFallbackPlan(t *testing.T, p *Plan) (string, error) {
+	t.Helper()
+	return runFallbackPlanWithVariables(t, p, nil)
+}
+
+func runFallbackPlanWithVariables(t *testing.T, p *Plan, vars map[string]interface{}) (string, error) {
+	t.Helper()
+	ctx := requestctx.NewTestContext()
+	require.NoError(t, requestctx.AddRequestVariables(ctx, vars, requestctx.BareVariablesPrefixStripped))
+	resp, err := p.Execute(ctx, apiconfig.ActionConfigPrefix+"lookup")
+	if err != nil {
+		return "", err
+	}
+	sfResp, ok := resp.(*sfhttp.SfResponse)
+	require.True(t, ok)
+	return string(sfResp.Body), nil
+}
+
+func TestAction_Fallback(t *testing.T) {
+	cache.SetDefault(cache.NewMemory())
+	defer cache.SetDefault(nil)
+
+	integrationDown := errors.New("dial tcp 10.0.0.5:5432: connection refused")
+
+	newExec := func(ctrl *gomock.Controller) *MockActionExecutable {
+		exec := NewMockActionExecutable(ctrl)
+		exec.EXPECT().Config().Return("").AnyTimes()
+		exec.EXPECT().Type().Return("lookup").AnyTimes()
+		exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+		return exec
+	}
+
+	t.Run("default value is used when the integration fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		exec := newExec(ctrl)
+		exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, integrationDown)
+
+		p := fallbackPlan(t, exec, &apiconfig.Fallback{Value: map[string]interface{}{"plan": "free"}})
+		body, err := runFallbackPlan(t, p)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"plan": "free"}`, body)
+	})
+
+	t.Run("non-fatal action failures fall back too", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		exec := newExec(ctrl)
+		exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, fmt.Errorf("%w: no rows", ErrFailure))
+
+		p := fallbackPlan(t, exec, &apiconfig.Fallback{Value: map[string]interface{}{"plan": "free"}})
+		body, err := runFallbackPlan(t, p)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"plan": "free"}`, body)
+	})
+
+	t.Run("last successful output is preferred when cached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		exec := newExec(ctrl)
+		gomock.InOrder(
+			exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(map[string]interface{}{"plan": "pro"}, nil, nil),
+			exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, integrationDown).Times(2),
+		)
+
+		p := fallbackPlan(t, exec, &apiconfig.Fallback{Cache: true, Value: map[string]interface{}{"plan": "free"}})
+		for i := 0; i < 3; i++ {
+			body, err := runFallbackPlan(t, p)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"plan": "pro"}`, body)
+		}
+	})
+
+	t.Run("cached outputs are not shared between requests with different inputs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		exec := newExec(ctrl)
+		gomock.InOrder(
+			exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(map[string]interface{}{"plan": "pro"}, nil, nil),
+			exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(map[string]interface{}{"plan": "team"}, nil, nil),
+			exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, integrationDown).Times(3),
+		)
+
+		p := fallbackPlanWithConfig(t, exec,
+			map[string]interface{}{"user": "{{ .variable_user }}"},
+			&apiconfig.Fallback{Cache: true, Value: map[string]interface{}{"plan": "free"}})
+		for _, user := range []string{"alice", "bob"} {
+			_, err := runFallbackPlanWithVariables(t, p, map[string]interface{}{"user": user})
+			require.NoError(t, err)
+		}
+
+		for user, want := range map[string]string{"alice": "pro", "bob": "team", "carol": "free"} {
+			body, err := runFallbackPlanWithVariables(t, p, map[string]interface{}{"user": user})
+			require.NoError(t, err)
+			assert.JSONEq(t, fmt.Sprintf(`{"plan": %q}`, want), body, user)
+		}
+	})
+
+	t.Run("cache key template decides which requests share an output", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		exec := newExec(ctrl)
+		gomock.InOrder(
+			exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(map[string]interface{}{"plan": "enterprise"}, nil, nil),
+			exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, integrationDown).Times(2),
+		)
+
+		p := fallbackPlanWithConfig(t, exec,
+			map[string]interface{}{"user": "{{ .variable_user }}"},
+			&apiconfig.Fallback{Cache: true, CacheKey: "{{ .variable_tenant }}", Value: map[string]interface{}{"plan": "free"}})
+		_, err := runFallbackPlanWithVariables(t, p, map[string]interface{}{"tenant": "acme", "user": "alice"})
+		require.NoError(t, err)
+
+		body, err := runFallbackPlanWithVariables(t, p, map[string]interface{}{"tenant": "acme", "user": "bob"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"plan": "enterprise"}`, body)
+
+		body, err = runFallbackPlanWithVariables(t, p, map[string]interface{}{"tenant": "globex", "user": "bob"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"plan": "free"}`, body)
+	})
+
+	t.Run("fatal failures are not masked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		exec := newExec(ctrl)
+		exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, fmt.Errorf("%w: table missing", ErrFatal))
+
+		p := fallbackPlan(t, exec, &apiconfig.Fallback{Value: map[string]interface{}{"plan": "free"}})
+		_, err := runFallbackPlan(t, p)
+		assert.ErrorIs(t, err, ErrFatal)
+	})
+
+	t.Run("failures without a fallback still fail the flow", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		exec := newExec(ctrl)
+		exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, integrationDown)
+
+		p := fallbackPlan(t, exec, nil)
+		_, err := runFallbackPlan(t, p)
+		assert.ErrorIs(t, err, integrationDown)
+	})
+
+	t.Run("invalid cache ttl fails planning", func(t *testing.T) {
+		_, err := newFallback("pricing", "lookup", nil, &apiconfig.Fallback{Cache: true, CacheTTL: "later"})
+		assert.Error(t, err)
+	})
+}
+
+func TestIsFatal(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, IsFatal(ctx, fmt.Errorf("wrapped: %w", ErrFatal)))
+	assert.True(t, IsFatal(ctx, ErrShortCircuit))
+	assert.False(t, IsFatal(ctx, errors.New("timeout talking to upstream")))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.True(t, IsFatal(canceled, errors.New("context canceled")))
+}
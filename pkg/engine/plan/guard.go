@@ -0,0 +1,38 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// guardedStep wraps an action that declares a when expression. The action
+// only runs when the expression renders "true"; otherwise it is skipped, no
+// output is recorded for it and the flow continues to its next step.
+type guardedStep struct {
+	id   string
+	expr string
+	step Step
+}
+
+func (g *guardedStep) execute(ctx context.Context) (*stepWrapper, error) {
+	tmpl, err := requestctx.CreateTextTemplate(ctx, g.expr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating template for guard of action %s: %w", g.id, err)
+	}
+	resp, err := requestctx.ExecuteTemplateFromContext(ctx, tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("error evaluating guard of action %s: %w", g.id, err)
+	}
+	if strings.TrimSpace(resp) == "true" {
+		return g.step.execute(ctx)
+	}
+
+	logging.FromContext(ctx).Debug("guard not met, skipping action",
+		zap.String("action_id", g.id), zap.String("guard", resp))
+	return successor(g.step), nil
+}
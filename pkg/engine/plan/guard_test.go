@@ -0,0 +1,87 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGuardedStep_Execute(t *testing.T) {
+	responses := map[string]apiconfig.ResponseConfig{
+		"done": {Name: "done", Code: 200, Type: "template", Template: "done"},
+	}
+
+	newPlan := func(t *testing.T, flag string) (*Plan, *[]string) {
+		ctrl := gomock.NewController(t)
+		registry := actions.NewRegistry()
+		var order []string
+		for _, id := range []string{"flag", "guarded", "after"} {
+			exec := NewMockActionExecutable(ctrl)
+			exec.EXPECT().Config().Return("").AnyTimes()
+			exec.EXPECT().Type().Return("mock").AnyTimes()
+			exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+			exec.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, string) (interface{}, map[string]string, error) {
+				order = append(order, id)
+				if id == "flag" {
+					return flag, nil, nil
+				}
+				return id, nil, nil
+			}).AnyTimes()
+			registry.ReplaceActionType(id, func(config json.RawMessage) (actions.ActionExecutable, error) {
+				return exec, nil
+			})
+		}
+
+		p, err := NewPlannerV2(PlannerConfig{
+			Actions: map[string]apiconfig.Action{
+				"flag": {Name: "flag", Type: "flag", Next: "action.guarded"},
+				"guarded": {
+					Name: "guarded",
+					Type: "guarded",
+					Next: "action.after",
+					When: `{{ eq .variable_actions_flag "yes" }}`,
+				},
+				"after": {Name: "after", Type: "after", Next: "response.done"},
+			},
+			Responses:      responses,
+			CustomRegistry: registry,
+		}, silentLogger()).Plan()
+		require.NoError(t, err)
+		return p, &order
+	}
+
+	t.Run("runs the action when the guard passes", func(t *testing.T) {
+		p, order := newPlan(t, "yes")
+		ctx := requestctx.NewTestContext()
+
+		resp, err := p.Execute(ctx, "action.flag")
+		require.NoError(t, err)
+		assert.Equal(t, "done", string(resp.(*sfhttp.SfResponse).Body))
+		assert.Equal(t, []string{"flag", "guarded", "after"}, *order)
+
+		reqCtx, _ := requestctx.FromContext(ctx)
+		assert.True(t, reqCtx.HasActionOutput("guarded"))
+	})
+
+	t.Run("skips the action but continues the flow when the guard fails", func(t *testing.T) {
+		p, order := newPlan(t, "no")
+		ctx := requestctx.NewTestContext()
+
+		resp, err := p.Execute(ctx, "action.flag")
+		require.NoError(t, err)
+		assert.Equal(t, "done", string(resp.(*sfhttp.SfResponse).Body))
+		assert.Equal(t, []string{"flag", "after"}, *order)
+
+		reqCtx, _ := requestctx.FromContext(ctx)
+		assert.False(t, reqCtx.HasActionOutput("guarded"))
+	})
+
+}
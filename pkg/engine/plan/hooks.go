@@ -0,0 +1,60 @@
+package plan
+
+import (
+	"context"
+	"strings"
+
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+)
+
+// ActionHooks run custom logic around every action a plan executes, e.g. to
+// record metrics, authorize or log, without changing the actions themselves.
+// Either hook may be nil.
+type ActionHooks struct {
+	// BeforeAction runs before the action with its step id, e.g.
+	// "action.fetch". The returned context is the one the action runs with, so
+	// the hook can annotate it. An error aborts the request without running
+	// the action; return ShortCircuit to answer with a response instead.
+	BeforeAction func(ctx context.Context, stepID string) (context.Context, error)
+	// AfterAction runs once the action finished, with its output and the error
+	// it failed with, if any. A non-nil return aborts the request with that
+	// error, which may again be a ShortCircuit.
+	AfterAction func(ctx context.Context, stepID string, result interface{}, err error) error
+}
+
+// isActionStep reports whether step runs an action, possibly wrapped.
+func isActionStep(step Step) bool {
+	switch step.(type) {
+	case *Action, *ActionV2, *dependentStep, *guardedStep:
+		return true
+	default:
+		return false
+	}
+}
+
+// executeWithHooks executes an action step between the plan's hooks.
+func (p *Plan) executeWithHooks(ctx context.Context, id string, step Step) (*stepWrapper, error) {
+	if p.hooks.BeforeAction != nil {
+		hookCtx, err := p.hooks.BeforeAction(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if hookCtx != nil {
+			ctx = hookCtx
+		}
+	}
+
+	next, err := step.execute(ctx)
+
+	if p.hooks.AfterAction != nil {
+		var result interface{}
+		if err == nil {
+			result, _ = requestctx.GetRequestVariable(ctx, strings.TrimPrefix(id, apiconfig.ActionConfigPrefix))
+		}
+		if hookErr := p.hooks.AfterAction(ctx, id, result, err); hookErr != nil {
+			return nil, hookErr
+		}
+	}
+	return next, err
+}
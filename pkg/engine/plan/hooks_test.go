@@ -0,0 +1,123 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPlan_ActionHooks(t *testing.T) {
+	newPlan := func(t *testing.T, hooks ActionHooks) (*Plan, map[string]*MockActionExecutable) {
+		ctrl := gomock.NewController(t)
+		registry := actions.NewRegistry()
+		execs := make(map[string]*MockActionExecutable)
+		for _, id := range []string{"first", "second"} {
+			exec := NewMockActionExecutable(ctrl)
+			exec.EXPECT().Config().Return("").AnyTimes()
+			exec.EXPECT().Type().Return("mock").AnyTimes()
+			exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+			execs[id] = exec
+			registry.ReplaceActionType(id, func(config json.RawMessage) (actions.ActionExecutable, error) {
+				return exec, nil
+			})
+		}
+
+		p, err := NewPlannerV2(PlannerConfig{
+			Actions: map[string]apiconfig.Action{
+				"first":  {Name: "first", Type: "first", Next: "action.second"},
+				"second": {Name: "second", Type: "second", Next: "response.done"},
+			},
+			Responses: map[string]apiconfig.ResponseConfig{
+				"done":   {Name: "done", Code: 200, Type: "template", Template: "done"},
+				"denied": {Name: "denied", Code: 403, Type: "template", Template: "denied"},
+			},
+			Hooks:          hooks,
+			CustomRegistry: registry,
+		}, silentLogger()).Plan()
+		require.NoError(t, err)
+		return p, execs
+	}
+
+	t.Run("hooks fire around every action in order", func(t *testing.T) {
+		var events []string
+		p, execs := newPlan(t, ActionHooks{
+			BeforeAction: func(ctx context.Context, stepID string) (context.Context, error) {
+				events = append(events, "before "+stepID)
+				return ctx, nil
+			},
+			AfterAction: func(ctx context.Context, stepID string, result interface{}, err error) error {
+				assert.NoError(t, err)
+				events = append(events, "after "+stepID+" "+result.(string))
+				return nil
+			},
+		})
+		execs["first"].EXPECT().Execute(gomock.Any(), gomock.Any()).Return("one", nil, nil)
+		execs["second"].EXPECT().Execute(gomock.Any(), gomock.Any()).Return("two", nil, nil)
+
+		resp, err := p.Execute(requestctx.NewTestContext(), "action.first")
+		require.NoError(t, err)
+		assert.Equal(t, "done", string(resp.(*sfhttp.SfResponse).Body))
+		assert.Equal(t, []string{
+			"before action.first",
+			"after action.first one",
+			"before action.second",
+			"after action.second two",
+		}, events)
+	})
+
+	t.Run("before hook aborts execution", func(t *testing.T) {
+		errDenied := errors.New("denied")
+		p, execs := newPlan(t, ActionHooks{
+			BeforeAction: func(ctx context.Context, stepID string) (context.Context, error) {
+				if stepID == "action.second" {
+					return nil, errDenied
+				}
+				return ctx, nil
+			},
+		})
+		execs["first"].EXPECT().Execute(gomock.Any(), gomock.Any()).Return("one", nil, nil)
+		execs["second"].EXPECT().Execute(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := p.Execute(requestctx.NewTestContext(), "action.first")
+		assert.ErrorIs(t, err, errDenied)
+	})
+
+	t.Run("before hook short-circuits to a response", func(t *testing.T) {
+		p, execs := newPlan(t, ActionHooks{
+			BeforeAction: func(ctx context.Context, stepID string) (context.Context, error) {
+				return nil, ShortCircuit("response.denied")
+			},
+		})
+		execs["first"].EXPECT().Execute(gomock.Any(), gomock.Any()).Times(0)
+
+		resp, err := p.Execute(requestctx.NewTestContext(), "action.first")
+		require.NoError(t, err)
+		assert.Equal(t, "denied", string(resp.(*sfhttp.SfResponse).Body))
+	})
+
+	t.Run("annotated context reaches the action", func(t *testing.T) {
+		type key struct{}
+		p, execs := newPlan(t, ActionHooks{
+			BeforeAction: func(ctx context.Context, stepID string) (context.Context, error) {
+				return context.WithValue(ctx, key{}, stepID), nil
+			},
+		})
+		execs["first"].EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ string) (interface{}, map[string]string, error) {
+			assert.Equal(t, "action.first", ctx.Value(key{}))
+			return "one", nil, nil
+		})
+		execs["second"].EXPECT().Execute(gomock.Any(), gomock.Any()).Return("two", nil, nil)
+
+		_, err := p.Execute(requestctx.NewTestContext(), "action.first")
+		require.NoError(t, err)
+	})
+}
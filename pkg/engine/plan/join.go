@@ -0,0 +1,57 @@
+package plan
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/Servflow/servflow/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Join is a convergence point for branches that were fanned out, typically by
+// a parallel action. Each branch that reaches the join checks whether every
+// upstream action in waitFor has produced its output; only the branch that
+// completes the set continues to next, every other branch ends at the join.
+type Join struct {
+	id      string
+	name    string
+	waitFor []string
+	next    *stepWrapper
+}
+
+func (j *Join) ID() string {
+	return j.id
+}
+
+func (j *Join) DisplayName() string {
+	if j.name != "" {
+		return j.name
+	}
+	return j.id
+}
+
+func (j *Join) execute(ctx context.Context) (*stepWrapper, error) {
+	var span trace.Span
+	ctx, span = tracing.StartJoin(ctx, j.id, j.DisplayName())
+	defer span.End()
+
+	reqCtx, ok := requestctx.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("invalid request context")
+	}
+
+	logger := logging.FromContext(ctx).With(zap.String("join_id", j.id))
+	if !reqCtx.ArriveAtJoin(j.id, j.waitFor) {
+		logger.Debug("join waiting on upstream steps, ending branch", zap.Strings("wait_for", j.waitFor))
+		span.SetAttributes(attribute.Bool("sf.result", false))
+		return nil, nil
+	}
+
+	logger.Debug("all upstream steps completed, continuing past join")
+	span.SetAttributes(attribute.Bool("sf.result", true))
+	return j.next, nil
+}
@@ -0,0 +1,123 @@
+package plan
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJoin_Execute(t *testing.T) {
+	cfg := apiconfig.APIConfig{
+		Actions: map[string]apiconfig.Action{
+			"branchA": {Name: "branchA", Type: "branchA", Next: "join.merge"},
+			"branchB": {Name: "branchB", Type: "branchB", Next: "join.merge"},
+		},
+		Joins: map[string]apiconfig.Join{
+			"merge": {
+				Name:    "merge",
+				WaitFor: []string{"action.branchA", "action.branchB"},
+				Next:    "response.done",
+			},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"done": {
+				Name:     "done",
+				Code:     200,
+				Type:     "template",
+				Template: `{{ .variable_actions_branchA }}-{{ .variable_actions_branchB }}`,
+			},
+		},
+	}
+
+	newPlan := func(t *testing.T) *Plan {
+		ctrl := gomock.NewController(t)
+		registry := actions.NewRegistry()
+		for id, out := range map[string]string{"branchA": "outA", "branchB": "outB"} {
+			exec := NewMockActionExecutable(ctrl)
+			exec.EXPECT().Config().Return("").AnyTimes()
+			exec.EXPECT().Type().Return("mock").AnyTimes()
+			exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+			exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(out, nil, nil).AnyTimes()
+			registry.ReplaceActionType(id, func(config json.RawMessage) (actions.ActionExecutable, error) {
+				return exec, nil
+			})
+		}
+
+		planner := NewPlannerV2(PlannerConfig{
+			Actions:        cfg.Actions,
+			Responses:      cfg.Responses,
+			Joins:          cfg.Joins,
+			CustomRegistry: registry,
+		}, logging.GetNewLogger())
+		p, err := planner.Plan()
+		require.NoError(t, err)
+		return p
+	}
+
+	t.Run("first branch ends at the join, last branch continues", func(t *testing.T) {
+		p := newPlan(t)
+		ctx := requestctx.NewTestContext()
+
+		resp, err := p.Execute(ctx, "action.branchA")
+		require.NoError(t, err)
+		assert.Nil(t, resp)
+
+		resp, err = p.Execute(ctx, "action.branchB")
+		require.NoError(t, err)
+		sfResp, ok := resp.(*sfhttp.SfResponse)
+		require.True(t, ok)
+		assert.Equal(t, "outA-outB", string(sfResp.Body))
+	})
+
+	t.Run("join continues only once", func(t *testing.T) {
+		p := newPlan(t)
+		ctx := requestctx.NewTestContext()
+
+		_, err := p.Execute(ctx, "action.branchA")
+		require.NoError(t, err)
+		resp, err := p.Execute(ctx, "action.branchB")
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+
+		resp, err = p.Execute(ctx, "join.merge")
+		require.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("concurrent branches continue exactly once", func(t *testing.T) {
+		p := newPlan(t)
+		ctx := requestctx.NewTestContext()
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			results []*sfhttp.SfResponse
+		)
+		for _, start := range []string{"action.branchA", "action.branchB"} {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				resp, err := p.Execute(ctx, id)
+				assert.NoError(t, err)
+				if resp != nil {
+					mu.Lock()
+					results = append(results, resp.(*sfhttp.SfResponse))
+					mu.Unlock()
+				}
+			}(start)
+		}
+		wg.Wait()
+
+		require.Len(t, results, 1)
+		assert.Equal(t, "outA-outB", string(results[0].Body))
+	})
+}
@@ -0,0 +1,78 @@
+package plan
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/Servflow/servflow/pkg/engine/actions"
+)
+
+// onceResults holds the outputs of once actions (see apiconfig.Action.Once)
+// for the life of the process. Entries are keyed by config and action id, so
+// a plan rebuilt on config reload shares them.
+var onceResults sync.Map
+
+// onceResult guards a once action's executable and keeps its output.
+type onceResult struct {
+	mu     sync.Mutex
+	done   bool
+	resp   interface{}
+	fields map[string]string
+}
+
+func onceResultFor(configID, actionID string) *onceResult {
+	r, _ := onceResults.LoadOrStore("once:"+configID+":"+actionID, &onceResult{})
+	return r.(*onceResult)
+}
+
+// do runs exec on the first call and returns its output on every call after
+// it. Concurrent callers wait for the run in progress. A failed run is not
+// kept, so the next call tries again; neither is a streamed output, which can
+// only be read once.
+func (o *onceResult) do(exec func() (interface{}, map[string]string, error)) (interface{}, map[string]string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.done {
+		return o.resp, o.fields, nil
+	}
+	resp, fields, err := exec()
+	if err != nil {
+		return resp, fields, err
+	}
+	if _, streamed := resp.(io.Reader); !streamed {
+		o.done, o.resp, o.fields = true, resp, fields
+	}
+	return resp, fields, nil
+}
+
+type onceExecutable struct {
+	actions.ActionExecutable
+	result *onceResult
+}
+
+func (o *onceExecutable) Execute(ctx context.Context, modifiedConfig string) (interface{}, map[string]string, error) {
+	return o.result.do(func() (interface{}, map[string]string, error) {
+		return o.ActionExecutable.Execute(ctx, modifiedConfig)
+	})
+}
+
+// SupportsReplica is false so that every run goes through the guard.
+func (o *onceExecutable) SupportsReplica() bool {
+	return false
+}
+
+type onceExecutableV2 struct {
+	actions.ActionExecutableV2
+	result *onceResult
+}
+
+func (o *onceExecutableV2) Execute(ctx context.Context) (interface{}, map[string]string, error) {
+	return o.result.do(func() (interface{}, map[string]string, error) {
+		return o.ActionExecutableV2.Execute(ctx)
+	})
+}
+
+func (o *onceExecutableV2) SupportsReplica() bool {
+	return false
+}
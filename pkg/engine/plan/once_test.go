@@ -0,0 +1,116 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAction_Once(t *testing.T) {
+	cfgs := map[string]apiconfig.Action{
+		"warm": {Name: "warm", Type: "warm", Next: "response.done", Fail: "response.failed", Once: true},
+	}
+	responses := map[string]apiconfig.ResponseConfig{
+		"done":   {Name: "done", Code: 200, Type: "template", Template: "{{ .warm }}"},
+		"failed": {Name: "failed", Code: 500, Type: "template", Template: "failed"},
+	}
+
+	// newPlan plans cfgs under configID, with the warm action running execute
+	newPlan := func(t *testing.T, configID string, execute func() (interface{}, map[string]string, error)) *Plan {
+		ctrl := gomock.NewController(t)
+		exec := NewMockActionExecutable(ctrl)
+		exec.EXPECT().Config().Return("").AnyTimes()
+		exec.EXPECT().Type().Return("mock").AnyTimes()
+		exec.EXPECT().SupportsReplica().Return(true).AnyTimes()
+		exec.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, string) (interface{}, map[string]string, error) {
+			return execute()
+		}).AnyTimes()
+		registry := actions.NewRegistry()
+		registry.ReplaceActionType("warm", func(config json.RawMessage) (actions.ActionExecutable, error) {
+			return exec, nil
+		})
+
+		p, err := NewPlannerV2(PlannerConfig{
+			ID:             configID,
+			Actions:        cfgs,
+			Responses:      responses,
+			CustomRegistry: registry,
+		}, silentLogger()).Plan()
+		require.NoError(t, err)
+		return p
+	}
+	body := func(t *testing.T, p *Plan) string {
+		resp, err := p.Execute(requestctx.NewTestContext(), "action.warm")
+		require.NoError(t, err)
+		return string(resp.(*sfhttp.SfResponse).Body)
+	}
+
+	t.Run("concurrent requests run the action once", func(t *testing.T) {
+		var runs atomic.Int32
+		p := newPlan(t, t.Name(), func() (interface{}, map[string]string, error) {
+			n := runs.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			return fmt.Sprintf("warmed %d", n), nil, nil
+		})
+
+		var wg sync.WaitGroup
+		bodies := make([]string, 20)
+		for i := range bodies {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				resp, err := p.Execute(requestctx.NewTestContext(), "action.warm")
+				if assert.NoError(t, err) {
+					bodies[i] = string(resp.(*sfhttp.SfResponse).Body)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), runs.Load())
+		for _, b := range bodies {
+			assert.Equal(t, "warmed 1", b)
+		}
+	})
+
+	t.Run("output survives a rebuilt plan", func(t *testing.T) {
+		var runs atomic.Int32
+		execute := func() (interface{}, map[string]string, error) {
+			runs.Add(1)
+			return "warmed", nil, nil
+		}
+		assert.Equal(t, "warmed", body(t, newPlan(t, t.Name(), execute)))
+		assert.Equal(t, "warmed", body(t, newPlan(t, t.Name(), execute)))
+		assert.Equal(t, int32(1), runs.Load())
+
+		body(t, newPlan(t, t.Name()+"-other", execute))
+		assert.Equal(t, int32(2), runs.Load(), "other configs have their own guard")
+	})
+
+	t.Run("failed run is retried", func(t *testing.T) {
+		var runs atomic.Int32
+		p := newPlan(t, t.Name(), func() (interface{}, map[string]string, error) {
+			if runs.Add(1) == 1 {
+				return nil, nil, fmt.Errorf("%w: index busy", ErrFailure)
+			}
+			return "warmed", nil, nil
+		})
+
+		assert.Equal(t, "failed", body(t, p))
+		assert.Equal(t, "warmed", body(t, p))
+		assert.Equal(t, "warmed", body(t, p))
+		assert.Equal(t, int32(2), runs.Load())
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"text/template"
 	"time"
 
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
 	"github.com/Servflow/servflow/pkg/engine/actions"
 	"github.com/Servflow/servflow/pkg/engine/requestctx"
 	"github.com/Servflow/servflow/pkg/engine/responses"
@@ -20,20 +21,26 @@ import (
 
 type contextKey string
 
-const (
-	ContextKey        contextKey = "planContextKey"
-	RequestContextKey contextKey = "planRequestContextKey"
-)
+const ContextKey contextKey = "planContextKey"
 
 type Plan struct {
 	steps           map[string]stepWrapper
 	actionNameToID  map[string]string
 	dispatchTimeout time.Duration
+	maxSteps        int64
 	workspace       requestctx.Workspace
+	hooks           ActionHooks
+	sideEffects     []string
 }
 
+// DefaultMaxSteps is the step cap used when PlannerConfig.MaxSteps is unset.
+const DefaultMaxSteps = 10000
+
 var (
 	ErrContextCanceled = errors.New("context canceled")
+	// ErrMaxStepsExceeded aborts a request that has executed more steps than
+	// the plan allows, e.g. a flow stuck looping through a sub-chain.
+	ErrMaxStepsExceeded = errors.New("maximum step count exceeded")
 )
 
 type stepWrapper struct {
@@ -41,16 +48,14 @@ type stepWrapper struct {
 	step Step
 }
 
+// WithRequest returns ctx carrying r; see requestctx.WithRequest.
 func WithRequest(ctx context.Context, r *http.Request) context.Context {
-	return context.WithValue(ctx, RequestContextKey, r)
+	return requestctx.WithRequest(ctx, r)
 }
 
+// RequestFromContext returns the request stored by WithRequest.
 func RequestFromContext(ctx context.Context) (*http.Request, error) {
-	r, ok := ctx.Value(RequestContextKey).(*http.Request)
-	if !ok {
-		return nil, errors.New("request context is missing")
-	}
-	return r, nil
+	return requestctx.RequestFromContext(ctx)
 }
 
 func ExecuteSingleAction(actionType string, config json.RawMessage) (any, map[string]string, error) {
@@ -63,24 +68,26 @@ func ExecuteSingleAction(actionType string, config json.RawMessage) (any, map[st
 }
 
 func (p *Plan) executeStep(ctx context.Context, step *stepWrapper) (responses.Result, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ErrContextCanceled
-	default:
+	if err := p.admitStep(ctx, step.id); err != nil {
+		return nil, err
 	}
-	logger := logging.FromContext(ctx).With(zap.String("step_id", step.id))
+	if s, ok := step.step.(*Response); ok {
+		return s.WriteResponse(ctx)
+	}
+
 	var (
 		next *stepWrapper
 		err  error
 	)
-
-	switch s := step.step.(type) {
-	case *Response:
-		return s.WriteResponse(ctx)
-	default:
-		logger.Debug("starting execution")
-		next, err = s.execute(logging.WithLogger(ctx, logger))
-		logger.Debug("finished execution")
+	if reqCtx, ok := requestctx.FromContext(ctx); ok && isActionStep(step.step) {
+		run := reqCtx.StartActionRun(strings.TrimPrefix(step.id, apiconfig.ActionConfigPrefix))
+		next, err = p.runStep(withActionRun(ctx, run), step)
+		if err != nil {
+			run.Fail()
+		}
+		run.Finish()
+	} else {
+		next, err = p.runStep(ctx, step)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error executing step: %w", err)
@@ -94,8 +101,42 @@ func (p *Plan) executeStep(ctx context.Context, step *stepWrapper) (responses.Re
 	return nil, nil
 }
 
+// admitStep checks that the request may execute another step: it has not
+// been cancelled and has not reached the plan's step cap.
+func (p *Plan) admitStep(ctx context.Context, id string) error {
+	select {
+	case <-ctx.Done():
+		return ErrContextCanceled
+	default:
+	}
+	if reqCtx, ok := requestctx.FromContext(ctx); ok && p.maxSteps > 0 {
+		if reqCtx.CountStep() > p.maxSteps {
+			return fmt.Errorf("%w: limit is %d, at step %s", ErrMaxStepsExceeded, p.maxSteps, id)
+		}
+	}
+	return nil
+}
+
+// runStep executes a single step other than a response, action steps between
+// the plan's hooks, and returns the step to continue with.
+func (p *Plan) runStep(ctx context.Context, step *stepWrapper) (*stepWrapper, error) {
+	logger := logging.FromContext(ctx).With(zap.String("step_id", step.id))
+	ctx = logging.WithLogger(ctx, logger)
+
+	logger.Debug("starting execution")
+	defer logger.Debug("finished execution")
+	if isActionStep(step.step) {
+		return p.executeWithHooks(ctx, step.id, step.step)
+	}
+	return step.step.execute(ctx)
+}
+
 func (p *Plan) Execute(ctx context.Context, id string) (responses.Result, error) {
 	id = strings.TrimLeft(id, "$")
+	// a sub-chain started from within this plan leaves short-circuits to the
+	// outermost Execute, so the response is written once for the whole flow
+	parent, _ := ctx.Value(ContextKey).(*Plan)
+	nested := parent == p
 	ctx = context.WithValue(ctx, ContextKey, p)
 
 	// Register the action template function and set workspace on the request context
@@ -113,7 +154,21 @@ func (p *Plan) Execute(ctx context.Context, id string) (responses.Result, error)
 		return nil, errors.New("step not found")
 	}
 
-	return p.executeStep(ctx, &step)
+	res, err := p.executeStep(ctx, &step)
+	if err != nil && !nested {
+		var sc *ShortCircuitError
+		if errors.As(err, &sc) {
+			return p.executeShortCircuit(ctx, sc)
+		}
+	}
+	return res, err
+}
+
+// SideEffectingActions returns the step ids, sorted, of the plan's actions
+// whose type is registered with side effects, see
+// actions.ActionRegistrationInfo.SideEffects.
+func (p *Plan) SideEffectingActions() []string {
+	return p.sideEffects
 }
 
 // actionFunc returns a template function that looks up action outputs by name or ID.
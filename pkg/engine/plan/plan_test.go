@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -438,3 +439,84 @@ func TestPlan_WorkspaceTemplateFunction(t *testing.T) {
 	_, err = plan.Execute(ctx, apiconfig.ActionConfigPrefix+"test_action")
 	require.NoError(t, err)
 }
+
+func TestPlan_MaxSteps(t *testing.T) {
+	// a chain of five actions ending in a response: six steps
+	cfgs := make(map[string]apiconfig.Action)
+	for i := 1; i <= 5; i++ {
+		next := fmt.Sprintf("action.a%d", i+1)
+		if i == 5 {
+			next = "response.done"
+		}
+		id := fmt.Sprintf("a%d", i)
+		cfgs[id] = apiconfig.Action{Name: id, Next: next}
+	}
+	responses := map[string]apiconfig.ResponseConfig{
+		"done": {Name: "done", Code: 200, Type: "template", Template: "done"},
+	}
+
+	newPlan := func(t *testing.T, maxSteps int) (*Plan, *int) {
+		ctrl := gomock.NewController(t)
+		executed := 0
+		exec := NewMockActionExecutable(ctrl)
+		exec.EXPECT().Config().Return("").AnyTimes()
+		exec.EXPECT().Type().Return("mock").AnyTimes()
+		exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+		exec.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, string) (interface{}, map[string]string, error) {
+			executed++
+			return "ok", nil, nil
+		}).AnyTimes()
+		registry := actions.NewRegistry()
+		registry.ReplaceActionType("", func(config json.RawMessage) (actions.ActionExecutable, error) {
+			return exec, nil
+		})
+
+		p, err := NewPlannerV2(PlannerConfig{
+			Actions:        cfgs,
+			Responses:      responses,
+			MaxSteps:       maxSteps,
+			CustomRegistry: registry,
+		}, logging.GetNewLogger()).Plan()
+		require.NoError(t, err)
+		return p, &executed
+	}
+
+	t.Run("flow over the cap aborts", func(t *testing.T) {
+		p, executed := newPlan(t, 3)
+
+		resp, err := p.Execute(requestctx2.NewTestContext(), "action.a1")
+		require.ErrorIs(t, err, ErrMaxStepsExceeded)
+		assert.Nil(t, resp)
+		assert.Equal(t, 3, *executed)
+	})
+
+	t.Run("flow within the cap completes", func(t *testing.T) {
+		p, executed := newPlan(t, 6)
+
+		resp, err := p.Execute(requestctx2.NewTestContext(), "action.a1")
+		require.NoError(t, err)
+		assert.Equal(t, "done", string(resp.(*sfhttp.SfResponse).Body))
+		assert.Equal(t, 5, *executed)
+	})
+
+	t.Run("cap spans every chain of the request", func(t *testing.T) {
+		p, executed := newPlan(t, 8)
+		ctx := requestctx2.NewTestContext()
+
+		_, err := p.Execute(ctx, "action.a1")
+		require.NoError(t, err)
+		_, err = p.Execute(ctx, "action.a4")
+		require.ErrorIs(t, err, ErrMaxStepsExceeded)
+		assert.Equal(t, 7, *executed)
+	})
+
+	t.Run("negative cap disables it", func(t *testing.T) {
+		p, _ := newPlan(t, -1)
+		ctx := requestctx2.NewTestContext()
+
+		for i := 0; i < 3; i++ {
+			_, err := p.Execute(ctx, "action.a1")
+			require.NoError(t, err)
+		}
+	})
+}
@@ -5,6 +5,7 @@ package plan
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
@@ -20,6 +21,11 @@ type ActionProvider interface {
 
 // PlannerConfig holds config values for the plan generation
 type PlannerConfig struct {
+	// ID identifies the config being planned. It scopes state kept across
+	// requests, such as cached fallback outputs, so actions with the same id in
+	// different configs do not share it.
+	ID string
+
 	// ResultTag is the key that holds the value copied from EndLookupKey
 	ResultTag string
 	// EndLookupKey is the key that should be looked up to get the value copied to
@@ -35,6 +41,12 @@ type PlannerConfig struct {
 	// If not set or set to 0, background actions will run without a timeout.
 	DispatchTimeout time.Duration
 
+	// MaxSteps caps the steps a single request may execute across every chain
+	// of the plan, including dispatched and nested ones. Once exceeded the
+	// request fails with ErrMaxStepsExceeded. 0 uses DefaultMaxSteps and a
+	// negative value disables the cap.
+	MaxSteps int
+
 	// Workspace is the file capability that workspace-aware actions and template
 	// functions use. It is resolved per config (from the owning agent's assigned
 	// workspace) and applied to each request's context before the plan runs. A
@@ -42,10 +54,24 @@ type PlannerConfig struct {
 	// requestctx.ErrNoWorkspace.
 	Workspace requestctx.Workspace
 
+	// ValidationResponse, when set, is written when a condition without an
+	// onFalse evaluates to false and leaves validation errors, e.g.
+	// DefaultValidationResponse. Nil ends the chain without a response.
+	ValidationResponse *apiconfig.ResponseConfig
+
+	// DefaultFail is the step an action without a Fail routes to when it
+	// fails with any error, instead of the request ending in a 500. The error
+	// is stored under requestctx.ErrorTagStripped, as for Fail.
+	DefaultFail string
+
+	// Hooks run around every action of the plan.
+	Hooks ActionHooks
+
 	CustomRegistry *actions.Registry
 	Actions        map[string]apiconfig.Action
 	Conditions     map[string]apiconfig.Conditional
 	Responses      map[string]apiconfig.ResponseConfig
+	Joins          map[string]apiconfig.Join
 	Integrations   map[string]apiconfig.IntegrationConfig
 }
 
@@ -54,6 +80,8 @@ type PlannerV2 struct {
 	finalSteps map[string]stepWrapper
 	registry   *actions.Registry
 	logger     *zap.Logger
+	// validationFailure is shared by every condition without an onFalse.
+	validationFailure *stepWrapper
 }
 
 func NewPlannerV2(config PlannerConfig, logger *zap.Logger) *PlannerV2 {
@@ -66,6 +94,16 @@ func NewPlannerV2(config PlannerConfig, logger *zap.Logger) *PlannerV2 {
 }
 
 func (p *PlannerV2) Plan() (*Plan, error) {
+	if err := validatePlanGraph(p.config); err != nil {
+		return nil, err
+	}
+	for _, w := range ambiguousVariables(&apiconfig.APIConfig{
+		Actions:      p.config.Actions,
+		Conditionals: p.config.Conditions,
+		Responses:    p.config.Responses,
+	}) {
+		p.logger.Warn("ambiguous template variable", zap.Error(w))
+	}
 	for id := range p.config.Integrations {
 		integ := p.config.Integrations[id]
 		if err := integration.InitializeIntegration(integ.Type, id, integ.Config, integ.LazyLoad); err != nil {
@@ -93,28 +131,54 @@ func (p *PlannerV2) Plan() (*Plan, error) {
 			return nil, err
 		}
 	}
+	for id := range p.config.Joins {
+		id = apiconfig.JoinConfigPrefix + id
+		err := p.generate(id)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	dispatchTimeout := p.config.DispatchTimeout
 	if dispatchTimeout == 0 {
 		dispatchTimeout = time.Minute
 	}
+	maxSteps := int64(p.config.MaxSteps)
+	if maxSteps == 0 {
+		maxSteps = DefaultMaxSteps
+	}
 
 	// Build action name to ID mapping
 	actionNameToID := make(map[string]string)
+	var sideEffects []string
 	for id, action := range p.config.Actions {
 		if action.Name != "" && action.Name != id {
 			actionNameToID[action.Name] = id
 		}
+		if p.hasSideEffects(action.Type) {
+			sideEffects = append(sideEffects, apiconfig.ActionConfigPrefix+id)
+		}
 	}
+	sort.Strings(sideEffects)
 
 	return &Plan{
 		steps:           p.finalSteps,
 		actionNameToID:  actionNameToID,
 		dispatchTimeout: dispatchTimeout,
+		maxSteps:        maxSteps,
 		workspace:       p.config.Workspace,
+		hooks:           p.config.Hooks,
+		sideEffects:     sideEffects,
 	}, nil
 }
 
+func (p *PlannerV2) hasSideEffects(actionType string) bool {
+	if p.registry != nil {
+		return p.registry.HasSideEffects(actionType)
+	}
+	return actions.HasSideEffects(actionType)
+}
+
 func (p *PlannerV2) generate(id string) error {
 	if _, ok := p.finalSteps[id]; ok {
 		return nil
@@ -154,6 +218,8 @@ func (p *PlannerV2) generateStep(id string) (*stepWrapper, error) {
 		step, err = p.generateConditionalStep(bareID)
 	case apiconfig.StepKindResponse:
 		step, err = p.generateResponseStep(bareID)
+	case apiconfig.StepKindJoin:
+		step, err = p.generateJoinStep(bareID)
 	}
 	if err != nil {
 		return nil, err
@@ -186,10 +252,28 @@ func (p *PlannerV2) generateActionStep(id string) (Step, error) {
 		isV2 = actions.IsV2Action(a.Type)
 	}
 
+	var step Step
 	if isV2 {
-		return p.generateActionStepV2(id, a, configJson)
+		step, err = p.generateActionStepV2(id, a, configJson)
+	} else {
+		step, err = p.generateActionStepV1(id, a, configJson)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(a.DependsOn) > 0 {
+		deps, err := dependencyOrder(p.config.Actions, id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dependencies for action %s: %w", id, err)
+		}
+		step = &dependentStep{id: id, deps: deps, step: step}
 	}
-	return p.generateActionStepV1(id, a, configJson)
+	// The guard is checked first so a skipped action runs no dependencies.
+	if a.When != "" {
+		step = &guardedStep{id: id, expr: a.When, step: step}
+	}
+	return step, nil
 }
 
 // generateActionStepV1 creates a V1 action step (template resolution in plan executor)
@@ -207,18 +291,18 @@ func (p *PlannerV2) generateActionStepV1(id string, a apiconfig.Action, configJs
 	if err != nil {
 		return nil, fmt.Errorf("error creating actions %s: %w", id, err)
 	}
+	if a.Once {
+		exec = &onceExecutable{ActionExecutable: exec, result: onceResultFor(p.config.ID, id)}
+	}
 
 	nextStep, err := p.generateStep(a.Next)
 	if err != nil {
 		return nil, err
 	}
 
-	var failStep *stepWrapper
-	if a.Fail != "" {
-		failStep, err = p.generateStep(a.Fail)
-		if err != nil {
-			return nil, err
-		}
+	failStep, failAll, err := p.generateFailStep(a)
+	if err != nil {
+		return nil, err
 	}
 
 	out := id
@@ -228,15 +312,22 @@ func (p *PlannerV2) generateActionStepV1(id string, a apiconfig.Action, configJs
 		name = id
 	}
 
+	fb, err := newFallback(p.config.ID, id, configJson, a.Fallback)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Action{
 		id:         id,
 		name:       name,
 		next:       nextStep,
 		fail:       failStep,
+		failAll:    failAll,
 		out:        out,
 		exec:       exec,
 		useReplica: a.UseReplica,
 		dispatch:   a.Dispatch,
+		fallback:   fb,
 	}, nil
 }
 
@@ -255,18 +346,18 @@ func (p *PlannerV2) generateActionStepV2(id string, a apiconfig.Action, configJs
 	if err != nil {
 		return nil, fmt.Errorf("error creating v2 action %s: %w", id, err)
 	}
+	if a.Once {
+		exec = &onceExecutableV2{ActionExecutableV2: exec, result: onceResultFor(p.config.ID, id)}
+	}
 
 	nextStep, err := p.generateStep(a.Next)
 	if err != nil {
 		return nil, err
 	}
 
-	var failStep *stepWrapper
-	if a.Fail != "" {
-		failStep, err = p.generateStep(a.Fail)
-		if err != nil {
-			return nil, err
-		}
+	failStep, failAll, err := p.generateFailStep(a)
+	if err != nil {
+		return nil, err
 	}
 
 	name := a.Name
@@ -274,17 +365,38 @@ func (p *PlannerV2) generateActionStepV2(id string, a apiconfig.Action, configJs
 		name = id
 	}
 
+	fb, err := newFallback(p.config.ID, id, configJson, a.Fallback)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ActionV2{
 		id:         id,
 		name:       name,
 		next:       nextStep,
 		fail:       failStep,
+		failAll:    failAll,
 		exec:       exec,
 		useReplica: a.UseReplica,
 		dispatch:   a.Dispatch,
+		fallback:   fb,
 	}, nil
 }
 
+// generateFailStep returns the step an action routes to when it fails: its
+// own Fail, else the config's DefaultFail. failAll is set for DefaultFail,
+// which also takes the errors that would otherwise abort the request.
+func (p *PlannerV2) generateFailStep(a apiconfig.Action) (step *stepWrapper, failAll bool, err error) {
+	switch {
+	case a.Fail != "":
+		step, err = p.generateStep(a.Fail)
+	case p.config.DefaultFail != "":
+		step, err = p.generateStep(p.config.DefaultFail)
+		failAll = true
+	}
+	return step, failAll, err
+}
+
 // generateConditionalStep creates a ConditionStep based on the given id.
 func (p *PlannerV2) generateConditionalStep(id string) (*ConditionStep, error) {
 	condition, ok := p.config.Conditions[id]
@@ -301,6 +413,11 @@ func (p *PlannerV2) generateConditionalStep(id string) (*ConditionStep, error) {
 	if err != nil {
 		return nil, err
 	}
+	if condition.OnFalse == "" {
+		if invalidStep, err = p.validationFailureStep(); err != nil {
+			return nil, fmt.Errorf("invalid validation response: %w", err)
+		}
+	}
 
 	if condition.Type == "" {
 		if len(condition.Structure) > 0 {
@@ -325,6 +442,11 @@ func (p *PlannerV2) generateConditionalStep(id string) (*ConditionStep, error) {
 			return nil, fmt.Errorf("template condition %s has empty expression", id)
 		}
 		exprString = condition.Expression
+	case ConditionalTypeVariable:
+		exprString, err = VariableToTemplate(condition.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid variable condition %s: %w", id, err)
+		}
 	default:
 		return nil, fmt.Errorf("unsupported condition type: %s", condition.Type)
 	}
@@ -334,13 +456,18 @@ func (p *PlannerV2) generateConditionalStep(id string) (*ConditionStep, error) {
 		name = id
 	}
 
-	return &ConditionStep{
+	step := &ConditionStep{
 		id:         id,
 		name:       name,
 		OnValid:    validStep,
 		OnInvalid:  invalidStep,
 		exprString: exprString,
-	}, nil
+		boolean:    condition.Type == ConditionalTypeVariable,
+	}
+	if condition.Type == ConditionalTypeStructured {
+		step.fieldPaths = structureFieldPaths(condition.Structure)
+	}
+	return step, nil
 }
 
 // generateResponseStep creates a Response step based on the given id.
@@ -357,3 +484,48 @@ func (p *PlannerV2) generateResponseStep(id string) (*Response, error) {
 
 	return newResponse(id, name, response)
 }
+
+// generateJoinStep creates a Join step based on the given id. Every waitFor
+// entry must reference an action, since only actions produce the outputs the
+// join waits on.
+func (p *PlannerV2) generateJoinStep(id string) (*Join, error) {
+	join, ok := p.config.Joins[id]
+	if !ok {
+		return nil, fmt.Errorf("join not found: %s", id)
+	}
+	if len(join.WaitFor) == 0 {
+		return nil, fmt.Errorf("join %s has no upstream steps to wait for", id)
+	}
+
+	waitFor := make([]string, 0, len(join.WaitFor))
+	for _, ref := range join.WaitFor {
+		kind, bareID, _, err := apiconfig.ParseStepRef(ref)
+		if err != nil {
+			return nil, fmt.Errorf("join %s: %w", id, err)
+		}
+		if kind != apiconfig.StepKindAction {
+			return nil, fmt.Errorf("join %s can only wait for actions, got %q", id, ref)
+		}
+		if _, ok := p.config.Actions[bareID]; !ok {
+			return nil, fmt.Errorf("join %s waits for unknown action: %s", id, bareID)
+		}
+		waitFor = append(waitFor, bareID)
+	}
+
+	nextStep, err := p.generateStep(join.Next)
+	if err != nil {
+		return nil, err
+	}
+
+	name := join.Name
+	if name == "" {
+		name = id
+	}
+
+	return &Join{
+		id:      id,
+		name:    name,
+		waitFor: waitFor,
+		next:    nextStep,
+	}, nil
+}
@@ -3,8 +3,10 @@ package plan
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 
+	sfhttp "github.com/Servflow/servflow/internal/http"
 	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
 	"github.com/Servflow/servflow/pkg/engine/actions"
 	"github.com/Servflow/servflow/pkg/engine/integration"
@@ -361,6 +363,13 @@ func TestPlannerV2_generateConditionalStep(t *testing.T) {
 				OnTrue:  "response.success",
 				OnFalse: "response.failure",
 			},
+			"cond3": {
+				Name:       "cond3",
+				Type:       ConditionalTypeVariable,
+				Expression: ".variable_actions_check.allowed",
+				OnTrue:     "response.success",
+				OnFalse:    "response.failure",
+			},
 		},
 		Responses: map[string]apiconfig.ResponseConfig{
 			"success": {
@@ -405,6 +414,12 @@ func TestPlannerV2_generateConditionalStep(t *testing.T) {
 	assert.Contains(t, structuredCondition.exprString, "eq")
 	assert.Contains(t, structuredCondition.exprString, "gt")
 
+	variableCondition, err := planner.generateConditionalStep("cond3")
+	require.NoError(t, err)
+	assert.Equal(t, "{{ .variable_actions_check.allowed }}", variableCondition.exprString)
+	assert.True(t, variableCondition.boolean)
+	assert.False(t, structuredCondition.boolean)
+
 	_, err = planner.generateConditionalStep("nonexistent")
 	assert.Error(t, err)
 }
@@ -441,6 +456,94 @@ func TestPlannerV2_generateResponseStep(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestPlannerV2_generateJoinStep(t *testing.T) {
+	config := &apiconfig.APIConfig{
+		Actions: map[string]apiconfig.Action{
+			"branchA": {Name: "branchA", Next: "join.merge"},
+			"branchB": {Name: "branchB", Next: "join.merge"},
+		},
+		Joins: map[string]apiconfig.Join{
+			"merge": {
+				Name:    "merge",
+				WaitFor: []string{"action.branchA", "$action.branchB"},
+				Next:    "response.success",
+			},
+			"empty": {
+				Name: "empty",
+			},
+			"notAction": {
+				Name:    "notAction",
+				WaitFor: []string{"response.success"},
+			},
+			"unknown": {
+				Name:    "unknown",
+				WaitFor: []string{"action.missing"},
+			},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"success": {
+				Name:     "success",
+				Code:     200,
+				Template: `{"status": "success"}`,
+				Type:     "template",
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExec := NewMockActionExecutable(ctrl)
+	mockExec.EXPECT().Config().Return("").AnyTimes()
+	mockRegistry := actions.NewRegistry()
+	mockRegistry.ReplaceActionType("", func(config json.RawMessage) (actions.ActionExecutable, error) {
+		return mockExec, nil
+	})
+
+	planner := NewPlannerV2(PlannerConfig{
+		Actions:        config.Actions,
+		Responses:      config.Responses,
+		Joins:          config.Joins,
+		CustomRegistry: mockRegistry,
+	}, silentLogger())
+
+	join, err := planner.generateJoinStep("merge")
+	require.NoError(t, err)
+	assert.Equal(t, "merge", join.id)
+	assert.Equal(t, []string{"branchA", "branchB"}, join.waitFor)
+	assert.IsType(t, &Response{}, join.next.step)
+
+	_, err = planner.generateJoinStep("empty")
+	assert.ErrorContains(t, err, "no upstream steps")
+
+	_, err = planner.generateJoinStep("notAction")
+	assert.ErrorContains(t, err, "can only wait for actions")
+
+	_, err = planner.generateJoinStep("unknown")
+	assert.ErrorContains(t, err, "unknown action")
+
+	_, err = planner.generateJoinStep("nonexistent")
+	assert.Error(t, err)
+
+	t.Run("branches converging on a join share the same step", func(t *testing.T) {
+		planner := NewPlannerV2(PlannerConfig{
+			Actions:        config.Actions,
+			Responses:      config.Responses,
+			Joins:          map[string]apiconfig.Join{"merge": config.Joins["merge"]},
+			CustomRegistry: mockRegistry,
+		}, silentLogger())
+		p, err := planner.Plan()
+		require.NoError(t, err)
+
+		a := p.steps["action.branchA"].step.(*Action)
+		b := p.steps["action.branchB"].step.(*Action)
+		require.NotNil(t, a.next)
+		require.NotNil(t, b.next)
+		assert.Equal(t, "join.merge", a.next.id)
+		assert.Same(t, a.next.step, b.next.step)
+	})
+}
+
 func TestPlannerV2_IntegrationsLazyLoaded(t *testing.T) {
 	integration.ReplaceIntegrationType("mock-planner-test", func(config map[string]any) (integration.Integration, error) {
 		return &mockPlannerIntegration{typeName: "mock-planner-test"}, nil
@@ -527,3 +630,126 @@ func TestPlannerV2_IntegrationsLazyLoaded(t *testing.T) {
 		assert.Contains(t, err.Error(), "not registered")
 	})
 }
+
+func TestPlannerV2_PlanValidatesGraph(t *testing.T) {
+	plan := func(t *testing.T, cfgs map[string]apiconfig.Action) error {
+		ctrl := gomock.NewController(t)
+		exec := NewMockActionExecutable(ctrl)
+		exec.EXPECT().Config().Return("").AnyTimes()
+		registry := actions.NewRegistry()
+		registry.ReplaceActionType("", func(config json.RawMessage) (actions.ActionExecutable, error) {
+			return exec, nil
+		})
+
+		_, err := NewPlannerV2(PlannerConfig{
+			Actions:        cfgs,
+			Responses:      map[string]apiconfig.ResponseConfig{"done": {Name: "done", Code: 200}},
+			CustomRegistry: registry,
+		}, silentLogger()).Plan()
+		return err
+	}
+
+	t.Run("dangling next", func(t *testing.T) {
+		err := plan(t, map[string]apiconfig.Action{
+			"a": {Name: "a", Next: "action.missing"},
+		})
+		var ref *InvalidReferenceError
+		require.ErrorAs(t, err, &ref)
+		assert.Equal(t, "action.a", ref.From)
+		assert.Equal(t, "action.missing", ref.To)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		err := plan(t, map[string]apiconfig.Action{
+			"a": {Name: "a", Next: "action.b"},
+			"b": {Name: "b", Next: "action.a", Fail: "response.done"},
+		})
+		var cycle *CycleError
+		require.ErrorAs(t, err, &cycle)
+		assert.ElementsMatch(t, []string{"action.a", "action.b"}, cycle.Path[:2])
+		assert.Equal(t, cycle.Path[0], cycle.Path[2])
+	})
+
+	t.Run("valid chain", func(t *testing.T) {
+		err := plan(t, map[string]apiconfig.Action{
+			"a": {Name: "a", Next: "action.b"},
+			"b": {Name: "b", Next: "response.done"},
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestPlannerV2_FailRouting(t *testing.T) {
+	execute := func(t *testing.T, action apiconfig.Action, defaultFail string, execErr error) (string, error) {
+		ctrl := gomock.NewController(t)
+		exec := NewMockActionExecutable(ctrl)
+		exec.EXPECT().Config().Return("").AnyTimes()
+		exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+		exec.EXPECT().Type().Return("mock").AnyTimes()
+		exec.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, execErr)
+		registry := actions.NewRegistry()
+		registry.ReplaceActionType("", func(config json.RawMessage) (actions.ActionExecutable, error) {
+			return exec, nil
+		})
+
+		p, err := NewPlannerV2(PlannerConfig{
+			Actions: map[string]apiconfig.Action{"call": action},
+			Responses: map[string]apiconfig.ResponseConfig{
+				"done":     {Name: "done", Code: 200, Template: "done"},
+				"explicit": {Name: "explicit", Code: 400, Template: "explicit: {{ .error }}"},
+				"fallback": {Name: "fallback", Code: 502, Template: "fallback: {{ .error }}"},
+			},
+			DefaultFail:    defaultFail,
+			CustomRegistry: registry,
+		}, silentLogger()).Plan()
+		require.NoError(t, err)
+
+		resp, err := p.Execute(requestctx.NewTestContext(), "action.call")
+		if err != nil {
+			return "", err
+		}
+		r := resp.(*sfhttp.SfResponse)
+		return fmt.Sprintf("%d %s", r.Code, r.Body), nil
+	}
+	failure := fmt.Errorf("%w: upstream said no", ErrFailure)
+	boom := errors.New("boom")
+
+	t.Run("explicit fail", func(t *testing.T) {
+		out, err := execute(t, apiconfig.Action{Next: "response.done", Fail: "response.explicit"}, "response.fallback", failure)
+		require.NoError(t, err)
+		assert.Equal(t, "400 explicit: action failed: upstream said no", out)
+	})
+
+	t.Run("explicit fail does not take other errors", func(t *testing.T) {
+		_, err := execute(t, apiconfig.Action{Next: "response.done", Fail: "response.explicit"}, "response.fallback", boom)
+		assert.ErrorIs(t, err, boom)
+	})
+
+	t.Run("default fail takes failures", func(t *testing.T) {
+		out, err := execute(t, apiconfig.Action{Next: "response.done"}, "response.fallback", failure)
+		require.NoError(t, err)
+		assert.Equal(t, "502 fallback: action failed: upstream said no", out)
+	})
+
+	t.Run("default fail takes any error", func(t *testing.T) {
+		out, err := execute(t, apiconfig.Action{Next: "response.done"}, "response.fallback", boom)
+		require.NoError(t, err)
+		assert.Equal(t, "502 fallback: boom", out)
+	})
+
+	t.Run("without default fail an error aborts", func(t *testing.T) {
+		_, err := execute(t, apiconfig.Action{Next: "response.done"}, "", boom)
+		assert.ErrorIs(t, err, boom)
+	})
+
+	t.Run("dangling default fail", func(t *testing.T) {
+		_, err := NewPlannerV2(PlannerConfig{
+			Actions:     map[string]apiconfig.Action{"call": {Next: "response.done"}},
+			Responses:   map[string]apiconfig.ResponseConfig{"done": {Name: "done", Code: 200}},
+			DefaultFail: "response.missing",
+		}, silentLogger()).Plan()
+		var ref *InvalidReferenceError
+		require.ErrorAs(t, err, &ref)
+		assert.Equal(t, "default fail", ref.From)
+	})
+}
@@ -0,0 +1,53 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/responses"
+)
+
+// ErrShortCircuit marks an action result that ends the whole flow early. It is
+// never returned on its own; actions return it through ShortCircuit so the plan
+// knows which response to jump to.
+var ErrShortCircuit = errors.New("flow short-circuited")
+
+// ShortCircuitError ends the flow and writes the named response step, skipping
+// every remaining step. It propagates through sub-chains (e.g. parallel
+// branches) up to the outermost Plan.Execute, which renders the response.
+type ShortCircuitError struct {
+	// Response is the response step reference, e.g. "response.cached".
+	Response string
+}
+
+func (e *ShortCircuitError) Error() string {
+	return fmt.Sprintf("%v to %s", ErrShortCircuit, e.Response)
+}
+
+func (e *ShortCircuitError) Unwrap() error {
+	return ErrShortCircuit
+}
+
+// ShortCircuit returns the error an action uses to end the flow with the given
+// response step.
+func ShortCircuit(response string) error {
+	return &ShortCircuitError{Response: response}
+}
+
+// executeShortCircuit renders the response a short-circuited flow jumped to.
+func (p *Plan) executeShortCircuit(ctx context.Context, sc *ShortCircuitError) (responses.Result, error) {
+	kind, _, _, err := apiconfig.ParseStepRef(sc.Response)
+	if err != nil {
+		return nil, err
+	}
+	if kind != apiconfig.StepKindResponse {
+		return nil, fmt.Errorf("short-circuit target %q is not a response", sc.Response)
+	}
+	step, ok := p.steps[apiconfig.CanonicalStepID(sc.Response)]
+	if !ok {
+		return nil, fmt.Errorf("short-circuit response not found: %s", sc.Response)
+	}
+	return p.executeStep(ctx, &step)
+}
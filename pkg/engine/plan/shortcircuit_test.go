@@ -0,0 +1,92 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/actions"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPlan_ShortCircuit(t *testing.T) {
+	cfg := apiconfig.APIConfig{
+		Actions: map[string]apiconfig.Action{
+			"lookup":  {Name: "lookup", Type: "lookup", Next: "action.compute"},
+			"compute": {Name: "compute", Type: "compute", Next: "response.ok"},
+			"outer":   {Name: "outer", Type: "outer", Next: "action.compute"},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"ok":     {Name: "ok", Code: 200, Type: "template", Template: "computed"},
+			"cached": {Name: "cached", Code: 200, Type: "template", Template: "cached"},
+		},
+	}
+
+	setup := func(t *testing.T) (*Plan, *MockActionExecutable, *MockActionExecutable, *MockActionExecutable) {
+		ctrl := gomock.NewController(t)
+		registry := actions.NewRegistry()
+		execs := map[string]*MockActionExecutable{}
+		for _, id := range []string{"lookup", "compute", "outer"} {
+			exec := NewMockActionExecutable(ctrl)
+			exec.EXPECT().Config().Return("").AnyTimes()
+			exec.EXPECT().Type().Return("mock").AnyTimes()
+			exec.EXPECT().SupportsReplica().Return(false).AnyTimes()
+			registry.ReplaceActionType(id, func(config json.RawMessage) (actions.ActionExecutable, error) {
+				return exec, nil
+			})
+			execs[id] = exec
+		}
+		planner := NewPlannerV2(PlannerConfig{
+			Actions:        cfg.Actions,
+			Responses:      cfg.Responses,
+			CustomRegistry: registry,
+		}, logging.GetNewLogger())
+		p, err := planner.Plan()
+		require.NoError(t, err)
+		return p, execs["lookup"], execs["compute"], execs["outer"]
+	}
+
+	t.Run("returns the designated response and skips remaining steps", func(t *testing.T) {
+		p, lookup, compute, _ := setup(t)
+		lookup.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, ShortCircuit("response.cached"))
+		compute.EXPECT().Execute(gomock.Any(), gomock.Any()).Times(0)
+
+		resp, err := p.Execute(requestctx.NewTestContext(), "action.lookup")
+		require.NoError(t, err)
+		sfResp, ok := resp.(*sfhttp.SfResponse)
+		require.True(t, ok)
+		assert.Equal(t, "cached", string(sfResp.Body))
+	})
+
+	t.Run("short-circuit in a sub-chain ends the outer flow", func(t *testing.T) {
+		p, lookup, compute, outer := setup(t)
+		lookup.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, ShortCircuit("response.cached"))
+		compute.EXPECT().Execute(gomock.Any(), gomock.Any()).Times(0)
+		outer.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ string) (interface{}, map[string]string, error) {
+				_, err := ExecuteFromContext(ctx, "action.lookup")
+				assert.ErrorIs(t, err, ErrShortCircuit)
+				return nil, nil, err
+			})
+
+		resp, err := p.Execute(requestctx.NewTestContext(), "action.outer")
+		require.NoError(t, err)
+		sfResp, ok := resp.(*sfhttp.SfResponse)
+		require.True(t, ok)
+		assert.Equal(t, "cached", string(sfResp.Body))
+	})
+
+	t.Run("unknown response is an error", func(t *testing.T) {
+		p, lookup, _, _ := setup(t)
+		lookup.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil, nil, ShortCircuit("response.missing"))
+
+		_, err := p.Execute(requestctx.NewTestContext(), "action.lookup")
+		assert.ErrorContains(t, err, "short-circuit response not found")
+	})
+}
@@ -0,0 +1,32 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrConnectTimeout marks a failure to connect to, or finish the handshake
+	// with, an upstream within the connect timeout. HTTP endpoints answer it
+	// with 408.
+	ErrConnectTimeout = errors.New("connect timeout")
+	// ErrTimeout marks work cut off by a total timeout, of an action or of the
+	// whole request. HTTP endpoints answer it with 504.
+	ErrTimeout = errors.New("timeout")
+)
+
+const connectTimeoutContextKey contextKey = "planConnectTimeoutKey"
+
+// WithConnectTimeout sets the connect timeout of outbound connections made
+// by actions that do not set their own.
+func WithConnectTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, connectTimeoutContextKey, d)
+}
+
+// ConnectTimeoutFromContext returns the connect timeout set with
+// WithConnectTimeout, or zero.
+func ConnectTimeoutFromContext(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(connectTimeoutContextKey).(time.Duration)
+	return d
+}
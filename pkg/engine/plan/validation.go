@@ -39,6 +39,8 @@ func ValidateWithEntries(a *apiconfig.APIConfig, extraRoots ...string) error {
 	collectSchemaErrors(a, &validationErrors)
 	collectActionErrors(a, &validationErrors)
 	collectResponseErrors(a, &validationErrors)
+	collectTemplateErrors(a, &validationErrors)
+	collectVariableWarnings(a, &validationErrors)
 	collectGraphErrors(a, &validationErrors, extraRoots)
 
 	if validationErrors.HasErrors() {
@@ -85,6 +87,11 @@ func (ve *ValidationErrors) Error() string {
 	return strings.Join(lines, "\n")
 }
 
+// Unwrap returns the fatal errors, so errors.As finds each of them.
+func (ve *ValidationErrors) Unwrap() []error {
+	return ve.errors
+}
+
 func (ve *ValidationErrors) Add(err error) {
 	ve.errors = append(ve.errors, err)
 }
@@ -126,6 +133,17 @@ func (ve *ValidationErrors) GetResponseConfigErrors() []*ResponseConfigError {
 	return responseErrors
 }
 
+func (ve *ValidationErrors) GetTemplateSyntaxErrors() []*TemplateSyntaxError {
+	var templateErrors []*TemplateSyntaxError
+	for _, err := range ve.errors {
+		var templateErr *TemplateSyntaxError
+		if errors.As(err, &templateErr) {
+			templateErrors = append(templateErrors, templateErr)
+		}
+	}
+	return templateErrors
+}
+
 func (ve *ValidationErrors) GetSchemaValidationErrors() []*schemavalidate.SchemaValidationError {
 	var schemaErrors []*schemavalidate.SchemaValidationError
 	for _, err := range ve.errors {
@@ -0,0 +1,74 @@
+package plan
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+)
+
+// validationFailureID is the step id of the response written by
+// validationFailure.
+const validationFailureID = "validation_failure"
+
+// DefaultValidationResponse renders the collected validation errors as a 422
+// {"errors": [{"field": ..., "message": ...}]} body.
+var DefaultValidationResponse = apiconfig.ResponseConfig{
+	Name: "Validation failed",
+	Code: http.StatusUnprocessableEntity,
+	Type: "validation_errors",
+}
+
+// validationFailure is the false branch of a condition that has no onFalse.
+// When the condition left validation errors it writes the validation failure
+// response, so every config answers failed validation the same way without
+// wiring a response for it; otherwise the chain ends as before.
+type validationFailure struct {
+	response *stepWrapper
+}
+
+func (v *validationFailure) execute(ctx context.Context) (*stepWrapper, error) {
+	if hasValidationErrors(ctx) {
+		return v.response, nil
+	}
+	return nil, nil
+}
+
+func hasValidationErrors(ctx context.Context) bool {
+	val, err := requestctx.GetRequestVariable(ctx, requestctx.ErrorTagStripped)
+	if err != nil {
+		return false
+	}
+	switch errs := val.(type) {
+	case []string:
+		return len(errs) > 0
+	case string:
+		return errs != ""
+	}
+	return false
+}
+
+// validationFailureStep returns the step that replaces an empty onFalse, or
+// nil when the config has no validation failure response.
+func (p *PlannerV2) validationFailureStep() (*stepWrapper, error) {
+	if p.config.ValidationResponse == nil {
+		return nil, nil
+	}
+	if p.validationFailure == nil {
+		resp := *p.config.ValidationResponse
+		name := resp.Name
+		if name == "" {
+			name = validationFailureID
+		}
+		response, err := newResponse(validationFailureID, name, resp)
+		if err != nil {
+			return nil, err
+		}
+		p.validationFailure = &stepWrapper{
+			id:   validationFailureID,
+			step: &validationFailure{response: &stepWrapper{id: apiconfig.ResponsesConfigPrefix + validationFailureID, step: response}},
+		}
+	}
+	return p.validationFailure, nil
+}
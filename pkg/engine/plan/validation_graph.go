@@ -1,6 +1,7 @@
 package plan
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -40,6 +41,37 @@ func (e *UnreachableStepError) Error() string {
 	return fmt.Sprintf("step %s is unreachable from any entry", e.ID)
 }
 
+// validatePlanGraph checks the steps of a planner config for references to
+// steps that do not exist and for cycles, before anything is generated. The
+// planner does not know the entry points of the config, so every step is
+// treated as one: a cycle anywhere is an error.
+func validatePlanGraph(cfg PlannerConfig) error {
+	a := &apiconfig.APIConfig{
+		Actions:      cfg.Actions,
+		Conditionals: cfg.Conditions,
+		Responses:    cfg.Responses,
+		Joins:        cfg.Joins,
+		DefaultFail:  cfg.DefaultFail,
+	}
+	var roots []string
+	for id := range cfg.Actions {
+		roots = append(roots, apiconfig.ActionConfigPrefix+id)
+	}
+	for id := range cfg.Conditions {
+		roots = append(roots, apiconfig.ConditionalConfigPrefix+id)
+	}
+	for id := range cfg.Joins {
+		roots = append(roots, apiconfig.JoinConfigPrefix+id)
+	}
+
+	var ve ValidationErrors
+	collectGraphErrors(a, &ve, roots)
+	if ve.HasErrors() {
+		return &ve
+	}
+	return nil
+}
+
 // collectGraphErrors builds the directed step graph and checks it for invalid
 // references, cycles, and unreachable steps. extraRoots are additional entry
 // points (e.g. a trigger's start step) on top of the http/mcp entries.
@@ -59,6 +91,9 @@ func collectGraphErrors(a *apiconfig.APIConfig, ve *ValidationErrors, extraRoots
 	for id := range a.Responses {
 		nodes[apiconfig.ResponsesConfigPrefix+id] = apiconfig.StepKindResponse
 	}
+	for id := range a.Joins {
+		nodes[apiconfig.JoinConfigPrefix+id] = apiconfig.StepKindJoin
+	}
 
 	// resolve a reference to a canonical node id. Records an InvalidReferenceError
 	// and returns ok=false when the reference is malformed or dangling. Terminal
@@ -102,6 +137,10 @@ func collectGraphErrors(a *apiconfig.APIConfig, ve *ValidationErrors, extraRoots
 	if a.IsMCPConfig() && a.McpTool.Start != "" {
 		addRoot("mcp entry", a.McpTool.Start)
 	}
+	// any failing action can route to the default fail step
+	if a.DefaultFail != "" {
+		addRoot("default fail", a.DefaultFail)
+	}
 	for _, r := range extraRoots {
 		if r != "" {
 			addRoot("trigger entry", r)
@@ -124,6 +163,34 @@ func collectGraphErrors(a *apiconfig.APIConfig, ve *ValidationErrors, extraRoots
 		addEdge(from, cond.OnTrue)
 		addEdge(from, cond.OnFalse)
 	}
+	// join edges. waitFor entries are reference-checked only: they name the
+	// upstream actions the join converges, not steps it flows into.
+	for id, join := range a.Joins {
+		from := apiconfig.JoinConfigPrefix + id
+		addEdge(from, join.Next)
+		for _, w := range join.WaitFor {
+			resolve(from+" waitFor", w)
+		}
+	}
+
+	// dependsOn entries are reference-checked here and checked for cycles
+	// below. They are not flow edges: a dependency runs without following its
+	// next step.
+	deps := make(map[string][]string)
+	for id, act := range a.Actions {
+		from := apiconfig.ActionConfigPrefix + id
+		for _, ref := range act.DependsOn {
+			c, ok := resolve(from+" dependsOn", ref)
+			if !ok {
+				continue
+			}
+			if nodes[c] != apiconfig.StepKindAction {
+				ve.Add(&InvalidReferenceError{From: from + " dependsOn", To: ref, Reason: "can only depend on actions"})
+				continue
+			}
+			deps[from] = append(deps[from], c)
+		}
+	}
 
 	// deterministic adjacency + roots for stable traversal and error messages
 	for k := range adj {
@@ -190,6 +257,34 @@ func collectGraphErrors(a *apiconfig.APIConfig, ve *ValidationErrors, extraRoots
 	for _, r := range roots {
 		dfs(r, true)
 	}
+	// the dependencies of a reachable action run before it, so they are
+	// reachable too
+	for changed := true; changed; {
+		changed = false
+		for _, from := range sortedKeys(deps) {
+			if color[from] == white {
+				continue
+			}
+			for _, dep := range deps[from] {
+				if color[dep] == white {
+					dfs(dep, true)
+					changed = true
+				}
+			}
+		}
+	}
+
+	// dependency cycles can never be ordered, reachable or not
+	for _, id := range sortedKeys(a.Actions) {
+		var cycle *CycleError
+		if _, err := dependencyOrder(a.Actions, id); errors.As(err, &cycle) {
+			key := "dependsOn:" + cycleKey(cycle.Path)
+			if !reported[key] {
+				reported[key] = true
+				ve.Add(cycle)
+			}
+		}
+	}
 
 	// orphans: anything still white is unreachable from any entry (warning)
 	var orphans []string
@@ -186,3 +186,76 @@ func TestGraph_ExtraRootResolves(t *testing.T) {
 		t.Fatalf("expected bad extra root to error, got %v", ve2.errors)
 	}
 }
+
+func TestGraph_JoinConvergence(t *testing.T) {
+	cfg := apiconfig.APIConfig{
+		HttpConfig: apiconfig.HttpConfig{Next: "action.a"},
+		Actions: map[string]apiconfig.Action{
+			"a": {Name: "a", Next: "join.j"},
+			"b": {Name: "b", Next: "join.j"},
+		},
+		Joins:     map[string]apiconfig.Join{"j": {Name: "j", WaitFor: []string{"action.a", "action.b"}, Next: "response.ok"}},
+		Responses: map[string]apiconfig.ResponseConfig{"ok": {Name: "ok", Code: 200}},
+	}
+	ve := runGraph(cfg, "action.b")
+	if ve.HasErrors() || len(ve.Warnings()) != 0 {
+		t.Fatalf("expected clean, got errors=%v warnings=%v", ve.errors, ve.warnings)
+	}
+}
+
+func TestGraph_JoinDanglingWaitFor(t *testing.T) {
+	cfg := apiconfig.APIConfig{
+		HttpConfig: apiconfig.HttpConfig{Next: "action.a"},
+		Actions:    map[string]apiconfig.Action{"a": {Name: "a", Next: "join.j"}},
+		Joins:      map[string]apiconfig.Join{"j": {Name: "j", WaitFor: []string{"action.a", "action.ghost"}}},
+	}
+	ve := runGraph(cfg)
+	if countErrs[*InvalidReferenceError](ve.errors) != 1 {
+		t.Fatalf("expected 1 InvalidReferenceError, got %v", ve.errors)
+	}
+}
+
+func TestGraph_DependsOnMarksReachable(t *testing.T) {
+	cfg := apiconfig.APIConfig{
+		HttpConfig: apiconfig.HttpConfig{Next: "action.d"},
+		Actions: map[string]apiconfig.Action{
+			"a": {Name: "a", Next: "response.ok"},
+			"b": {Name: "b", Next: "action.d", DependsOn: []string{"action.a"}},
+			"d": {Name: "d", Next: "response.ok", DependsOn: []string{"action.b"}},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{"ok": {Name: "ok", Code: 200}},
+	}
+	ve := runGraph(cfg)
+	if ve.HasErrors() || len(ve.Warnings()) != 0 {
+		t.Fatalf("expected clean, got errors=%v warnings=%v", ve.errors, ve.warnings)
+	}
+}
+
+func TestGraph_DependsOnCycle(t *testing.T) {
+	cfg := apiconfig.APIConfig{
+		HttpConfig: apiconfig.HttpConfig{Next: "action.a"},
+		Actions: map[string]apiconfig.Action{
+			"a": {Name: "a", Next: "response.ok", DependsOn: []string{"action.b"}},
+			"b": {Name: "b", Next: "response.ok", DependsOn: []string{"action.a"}},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{"ok": {Name: "ok", Code: 200}},
+	}
+	ve := runGraph(cfg)
+	if countErrs[*CycleError](ve.errors) != 1 {
+		t.Fatalf("expected 1 dependency cycle error, got %v", ve.errors)
+	}
+}
+
+func TestGraph_DependsOnInvalidReference(t *testing.T) {
+	cfg := apiconfig.APIConfig{
+		HttpConfig: apiconfig.HttpConfig{Next: "action.a"},
+		Actions: map[string]apiconfig.Action{
+			"a": {Name: "a", Next: "response.ok", DependsOn: []string{"action.ghost", "response.ok"}},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{"ok": {Name: "ok", Code: 200}},
+	}
+	ve := runGraph(cfg)
+	if countErrs[*InvalidReferenceError](ve.errors) != 2 {
+		t.Fatalf("expected 2 InvalidReferenceErrors, got %v", ve.errors)
+	}
+}
@@ -0,0 +1,137 @@
+package plan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+)
+
+// maxSnippetLength bounds the template excerpt quoted in a TemplateSyntaxError.
+const maxSnippetLength = 60
+
+// TemplateSyntaxError is a template in a step that does not parse, such as
+// {{ jsonraws .x }} or an unclosed action. Without it these only fail when a
+// request reaches the step.
+type TemplateSyntaxError struct {
+	// Step is the canonical id of the step, e.g. "response.ok".
+	Step string
+	// Field is the config field holding the template.
+	Field   string
+	Snippet string
+	Err     error
+}
+
+func (e *TemplateSyntaxError) Error() string {
+	return fmt.Sprintf("%s field %q: invalid template %q: %v", e.Step, e.Field, e.Snippet, e.Err)
+}
+
+func (e *TemplateSyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// collectTemplateErrors parses every response template, condition expression
+// and action config string holding a template. Steps and fields are visited in
+// sorted order so errors are reported deterministically.
+func collectTemplateErrors(a *apiconfig.APIConfig, ve *ValidationErrors) {
+	walkTemplates(a, func(step, field, text string) {
+		if err := requestctx.CheckTemplate(text); err != nil {
+			ve.Add(&TemplateSyntaxError{Step: step, Field: field, Snippet: snippet(text), Err: err})
+		}
+	}, func(step string, err error) {
+		ve.Add(&TemplateSyntaxError{Step: step, Field: "structure", Err: err})
+	})
+}
+
+// walkTemplates calls fn for every response template, condition expression,
+// action guard and action config string holding a template, in sorted order,
+// and structureErr for a structured condition that cannot be turned into one.
+func walkTemplates(a *apiconfig.APIConfig, fn func(step, field, text string), structureErr func(step string, err error)) {
+	check := func(step, field, text string) {
+		if strings.Contains(text, "{{") {
+			fn(step, field, text)
+		}
+	}
+
+	for _, id := range sortedKeys(a.Actions) {
+		check(apiconfig.ActionConfigPrefix+id, "when", a.Actions[id].When)
+		walkConfigStrings("", a.Actions[id].Config, func(field, text string) {
+			check(apiconfig.ActionConfigPrefix+id, field, text)
+		})
+	}
+
+	for _, id := range sortedKeys(a.Conditionals) {
+		step := apiconfig.ConditionalConfigPrefix + id
+		cond := a.Conditionals[id]
+		switch {
+		case cond.Type == ConditionalTypeStructured || (cond.Type == "" && len(cond.Structure) > 0):
+			expr, err := ConvertStructureToTemplate(cond.Structure)
+			if err != nil {
+				structureErr(step, err)
+				continue
+			}
+			check(step, "structure", expr)
+		case cond.Type == ConditionalTypeTemplate || cond.Type == "":
+			check(step, "expression", cond.Expression)
+		}
+	}
+
+	for _, id := range sortedKeys(a.Responses) {
+		step := apiconfig.ResponsesConfigPrefix + id
+		resp := a.Responses[id]
+		check(step, "template", resp.Template)
+		walkResponseObject("responseObject", &resp.Object, func(field, text string) {
+			check(step, field, text)
+		})
+	}
+}
+
+// walkConfigStrings calls fn for every string in an action config, naming it
+// by its dotted path (list elements by index).
+func walkConfigStrings(path string, v interface{}, fn func(field, text string)) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch t := v.(type) {
+	case string:
+		fn(path, t)
+	case map[string]interface{}:
+		for _, k := range sortedKeys(t) {
+			walkConfigStrings(join(k), t[k], fn)
+		}
+	case []interface{}:
+		for i, item := range t {
+			walkConfigStrings(join(fmt.Sprint(i)), item, fn)
+		}
+	}
+}
+
+func walkResponseObject(path string, o *apiconfig.ResponseObject, fn func(field, text string)) {
+	fn(path+".value", o.Value)
+	for _, k := range sortedKeys(o.Fields) {
+		f := o.Fields[k]
+		walkResponseObject(path+".fields."+k, &f, fn)
+	}
+}
+
+func snippet(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) > maxSnippetLength {
+		return text[:maxSnippetLength] + "..."
+	}
+	return text
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
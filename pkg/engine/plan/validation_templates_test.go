@@ -0,0 +1,125 @@
+package plan
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func templateErrors(t *testing.T, cfg apiconfig.APIConfig) []*TemplateSyntaxError {
+	t.Helper()
+	ve := &ValidationErrors{}
+	collectTemplateErrors(&cfg, ve)
+	return ve.GetTemplateSyntaxErrors()
+}
+
+func TestTemplateSyntax_Valid(t *testing.T) {
+	cfg := apiconfig.APIConfig{
+		Actions: map[string]apiconfig.Action{
+			"fetch": {Name: "fetch", Config: map[string]interface{}{
+				"url":     `https://api.example.com/users/{{ urlparam "id" }}`,
+				"headers": map[string]interface{}{"Authorization": `Bearer {{ secret \"token\" }}`},
+				"body":    `{"name": "{{ body "name" | upper }}", "tags": {{ jsonraw .variable_actions_tags }}}`,
+				"plain":   "no template here",
+			}},
+		},
+		Conditionals: map[string]apiconfig.Conditional{
+			"check": {Name: "check", Expression: `{{ and (eq (header "X-Role") "admin") (flag "beta" "") }}`},
+			"shape": {Name: "shape", Structure: [][]apiconfig.ConditionItem{{
+				{Content: ".items.0.email", Function: FunctionEmail, Title: "email"},
+			}}},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"ok": {Name: "ok", Code: 200, Object: apiconfig.ResponseObject{Fields: map[string]apiconfig.ResponseObject{
+				"user": {Value: "{{ .variable_actions_fetch }}"},
+			}}},
+		},
+	}
+	assert.Empty(t, templateErrors(t, cfg))
+}
+
+func TestTemplateSyntax_Errors(t *testing.T) {
+	cfg := apiconfig.APIConfig{
+		Actions: map[string]apiconfig.Action{
+			"call": {Name: "call", When: "{{ eq .a }", Config: map[string]interface{}{
+				"headers": map[string]interface{}{"X-Id": "{{ .id "},
+			}},
+		},
+		Conditionals: map[string]apiconfig.Conditional{
+			"broken": {Name: "broken", Expression: "{{ if eq .a 1 }}yes"},
+			"bad":    {Name: "bad", Structure: [][]apiconfig.ConditionItem{{{Content: ".a", Function: "nope"}}}},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"ok": {Name: "ok", Code: 200, Template: `{"user": {{ jsonraws .variable_actions_user }}}`},
+			"nested": {Name: "nested", Code: 200, Object: apiconfig.ResponseObject{Fields: map[string]apiconfig.ResponseObject{
+				"id": {Value: "{{ .id }"},
+			}}},
+		},
+	}
+
+	errs := templateErrors(t, cfg)
+	require.Len(t, errs, 6)
+
+	type location struct{ step, field string }
+	var got []location
+	for _, e := range errs {
+		got = append(got, location{e.Step, e.Field})
+	}
+	assert.Equal(t, []location{
+		{"action.call", "when"},
+		{"action.call", "headers.X-Id"},
+		{"conditional.bad", "structure"},
+		{"conditional.broken", "expression"},
+		{"response.nested", "responseObject.fields.id.value"},
+		{"response.ok", "template"},
+	}, got)
+
+	assert.ErrorContains(t, errs[5], `response.ok field "template": invalid template "{\"user\": {{ jsonraws .variable_actions_user }}}"`)
+	assert.ErrorContains(t, errs[5], `function "jsonraws" not defined`)
+	assert.ErrorContains(t, errs[2], "unsupported conditional function: nope")
+}
+
+func TestValidate_ReportsTemplateErrors(t *testing.T) {
+	cfg := apiconfig.APIConfig{
+		ID:         "templates",
+		HttpConfig: apiconfig.HttpConfig{ListenPath: "/t", Method: "GET", Next: "conditional.check"},
+		Conditionals: map[string]apiconfig.Conditional{
+			"check": {Name: "check", Expression: "{{ eq .a 1 ", OnTrue: "response.ok", OnFalse: "response.ok"},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"ok": {Name: "ok", Code: 200, Template: "{{ jsonraws .a }}"},
+		},
+	}
+
+	err := Validate(&cfg)
+	require.Error(t, err)
+	var ve *ValidationErrors
+	require.True(t, errors.As(err, &ve))
+	assert.Len(t, ve.GetTemplateSyntaxErrors(), 2)
+}
+
+func TestAmbiguousVariables(t *testing.T) {
+	cfg := apiconfig.APIConfig{
+		Actions: map[string]apiconfig.Action{
+			"query": {Name: "query", Config: map[string]interface{}{"url": "{{ .query.id }}"}},
+			"user":  {Name: "user", Config: map[string]interface{}{"url": "{{ .user }}"}},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"ok": {Name: "ok", Code: 200, Template: `{{ .action.query }} {{ .body.id }} {{ range .query }}{{ .x }}{{ end }}`},
+		},
+	}
+	ve := &ValidationErrors{}
+	collectVariableWarnings(&cfg, ve)
+	assert.False(t, ve.HasErrors())
+	require.Len(t, ve.Warnings(), 2)
+
+	var ambiguous *AmbiguousVariableError
+	require.True(t, errors.As(ve.Warnings()[0], &ambiguous))
+	assert.Equal(t, &AmbiguousVariableError{Step: "action.query", Field: "url", Name: "query"}, ambiguous)
+	require.True(t, errors.As(ve.Warnings()[1], &ambiguous))
+	assert.Equal(t, &AmbiguousVariableError{Step: "response.ok", Field: "template", Name: "query"}, ambiguous)
+	assert.Contains(t, ambiguous.Error(), ".action.query")
+}
@@ -0,0 +1,56 @@
+package plan
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+)
+
+// AmbiguousVariableError is a template reading the bare variable Name where
+// Name is both an action's id and one of requestctx.ReservedVariableNames,
+// e.g. {{ .query }} with an action named query: the bare name resolves to the
+// action's output and the namespace or built-in is shadowed. The action is
+// addressed unambiguously as {{ .action.<name> }}.
+type AmbiguousVariableError struct {
+	// Step is the canonical id of the step holding the template.
+	Step  string
+	Field string
+	Name  string
+}
+
+func (e *AmbiguousVariableError) Error() string {
+	return fmt.Sprintf("%s field %q: variable %q is both an action and a request variable, use .%s.%s for the action",
+		e.Step, e.Field, e.Name, requestctx.ActionNamespace, e.Name)
+}
+
+// ambiguousVariables finds the templates of a reading a bare variable that
+// names both an action and a reserved request variable. Templates that do not
+// parse are left to collectTemplateErrors.
+func ambiguousVariables(a *apiconfig.APIConfig) []*AmbiguousVariableError {
+	var found []*AmbiguousVariableError
+	walkTemplates(a, func(step, field, text string) {
+		fields, err := requestctx.TemplateRootFields(text)
+		if err != nil {
+			return
+		}
+		reported := make(map[string]bool)
+		for _, name := range fields {
+			if _, isAction := a.Actions[name]; !isAction || reported[name] {
+				continue
+			}
+			if slices.Contains(requestctx.ReservedVariableNames, name) {
+				reported[name] = true
+				found = append(found, &AmbiguousVariableError{Step: step, Field: field, Name: name})
+			}
+		}
+	}, func(string, error) {})
+	return found
+}
+
+func collectVariableWarnings(a *apiconfig.APIConfig, ve *ValidationErrors) {
+	for _, w := range ambiguousVariables(a) {
+		ve.AddWarning(w)
+	}
+}
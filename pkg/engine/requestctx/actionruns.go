@@ -0,0 +1,70 @@
+package requestctx
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ActionRun is one run of an action in a request, see StartActionRun.
+type ActionRun struct {
+	done   chan struct{}
+	failed atomic.Bool
+}
+
+// Fail records that the action failed, including when the flow continues on
+// its fail branch.
+func (r *ActionRun) Fail() {
+	r.failed.Store(true)
+}
+
+// Failed reports whether Fail was called.
+func (r *ActionRun) Failed() bool {
+	return r.failed.Load()
+}
+
+// Finish releases the run's waiters. It must be called exactly once, after
+// any Fail.
+func (r *ActionRun) Finish() {
+	close(r.done)
+}
+
+// Wait blocks until the run finishes and reports whether it succeeded. It
+// returns ctx's error if ctx is done first.
+func (r *ActionRun) Wait(ctx context.Context) (bool, error) {
+	select {
+	case <-r.done:
+		return !r.Failed(), nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// StartActionRun records a new run of the action with the bare id actionID,
+// replacing any earlier one. The caller must Finish it.
+func (rc *RequestContext) StartActionRun(actionID string) *ActionRun {
+	rc.Lock()
+	defer rc.Unlock()
+	return rc.startActionRun(actionID)
+}
+
+// ClaimActionRun returns the latest run of the action with the bare id
+// actionID, finished or still in progress on another branch. When the action
+// has not run in this request, it records a new run instead and reports
+// claimed; the caller must then run the action and Finish it.
+func (rc *RequestContext) ClaimActionRun(actionID string) (run *ActionRun, claimed bool) {
+	rc.Lock()
+	defer rc.Unlock()
+	if run, ok := rc.actionRuns[actionID]; ok {
+		return run, false
+	}
+	return rc.startActionRun(actionID), true
+}
+
+func (rc *RequestContext) startActionRun(actionID string) *ActionRun {
+	if rc.actionRuns == nil {
+		rc.actionRuns = make(map[string]*ActionRun)
+	}
+	run := &ActionRun{done: make(chan struct{})}
+	rc.actionRuns[actionID] = run
+	return run
+}
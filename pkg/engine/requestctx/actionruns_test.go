@@ -0,0 +1,56 @@
+package requestctx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestContext_ActionRuns(t *testing.T) {
+	t.Run("first claim runs the action, later claims get its run", func(t *testing.T) {
+		rc := NewRequestContext("")
+		run, claimed := rc.ClaimActionRun("fetch")
+		require.True(t, claimed)
+
+		again, claimed := rc.ClaimActionRun("fetch")
+		assert.False(t, claimed)
+		assert.Same(t, run, again)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := again.Wait(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded, "run is still in progress")
+
+		run.Finish()
+		succeeded, err := again.Wait(context.Background())
+		require.NoError(t, err)
+		assert.True(t, succeeded)
+	})
+
+	t.Run("failed run is reported to waiters", func(t *testing.T) {
+		rc := NewRequestContext("")
+		run := rc.StartActionRun("fetch")
+		run.Fail()
+		run.Finish()
+
+		existing, claimed := rc.ClaimActionRun("fetch")
+		assert.False(t, claimed)
+		succeeded, err := existing.Wait(context.Background())
+		require.NoError(t, err)
+		assert.False(t, succeeded)
+	})
+
+	t.Run("a new run replaces the earlier one", func(t *testing.T) {
+		rc := NewRequestContext("")
+		failed := rc.StartActionRun("fetch")
+		failed.Fail()
+		failed.Finish()
+		rc.StartActionRun("fetch").Finish()
+
+		run, _ := rc.ClaimActionRun("fetch")
+		assert.False(t, run.Failed())
+	})
+}
@@ -25,6 +25,17 @@ type RequestContext struct {
 	validationErrors []error
 	availableFiles   map[string]*FileValue
 	workspace        Workspace
+	// firedJoins records the join steps that have already continued in this
+	// request (see ArriveAtJoin). Lazily allocated; guarded by the mutex.
+	firedJoins map[string]bool
+	// inputs holds the request namespaces (see SetRequestInputs) and
+	// actionKeys the variables holding action outputs (see
+	// AddActionOutput). Lazily allocated; guarded by the mutex.
+	inputs     map[string]map[string]interface{}
+	actionKeys map[string]bool
+	// actionRuns holds the latest run of each action in this request (see
+	// StartActionRun). Lazily allocated; guarded by the mutex.
+	actionRuns map[string]*ActionRun
 
 	// tokenInput/tokenOutput accumulate LLM token usage across every model call
 	// in this request. Observability-only — not exposed to workflow templates.
@@ -32,6 +43,10 @@ type RequestContext struct {
 	tokenInput  atomic.Int64
 	tokenOutput atomic.Int64
 
+	// steps counts the plan steps executed for this request across every
+	// chain, so a plan can cap runaway flows (see CountStep).
+	steps atomic.Int64
+
 	// secrets is the placeholder→value table for this request's call tree.
 	// Shared by pointer with child workflow contexts via ShareSecretsWith.
 	// Own mutex; never nil (see NewRequestContext).
@@ -43,6 +58,12 @@ type RequestContext struct {
 	spanAttrs []attribute.KeyValue
 	// lc is the request completion latch (see lifecycle.go).
 	lc lifecycle
+
+	// rawBody is the request body kept by BufferRawBody; rawBodyBuffered
+	// tells an empty body from one that was never buffered. Guarded by the
+	// mutex.
+	rawBody         []byte
+	rawBodyBuffered bool
 }
 
 // AddTokenUsage adds LLM token usage to this request's running total. Safe for
@@ -57,8 +78,15 @@ func (rc *RequestContext) TokenUsage() (input, output int64) {
 	return rc.tokenInput.Load(), rc.tokenOutput.Load()
 }
 
+// CountStep records that another plan step is executing for this request and
+// returns the total so far, including it. Safe for concurrent callers.
+func (rc *RequestContext) CountStep() int64 {
+	return rc.steps.Add(1)
+}
+
 // AddValidationErrors gets the validation errors added by the various conditional template functions,
-// then adds the errors under the ErrorTagStripped key in the request variable for parsing
+// then adds the errors under the ErrorTagStripped key in the request variable for parsing, and the
+// fields they belong to under ErrorFieldsTagStripped.
 func AddValidationErrors(ctx context.Context) error {
 	reqCtx, err := FromContextOrError(ctx)
 	if err != nil {
@@ -66,10 +94,15 @@ func AddValidationErrors(ctx context.Context) error {
 	}
 
 	errMessages := make([]string, len(reqCtx.validationErrors))
+	errFields := make([]string, len(reqCtx.validationErrors))
 	for i, err := range reqCtx.validationErrors {
 		errMessages[i] = err.Error()
+		var ve *ValidationError
+		if errors.As(err, &ve) {
+			errFields[i] = ve.Field
+		}
 	}
-	reqCtx.addRequestVariables(map[string]interface{}{ErrorTagStripped: errMessages}, "")
+	reqCtx.addRequestVariables(map[string]interface{}{ErrorTagStripped: errMessages, ErrorFieldsTagStripped: errFields}, "")
 	return nil
 }
 
@@ -158,6 +191,8 @@ func GetRequestVariable(ctx context.Context, key string) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	agg.Lock()
+	defer agg.Unlock()
 	return agg.requestVariables[key], nil
 }
 
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"text/template"
+	"text/template/parse"
 )
 
 // TODO move to same package as requestctx
@@ -36,6 +38,9 @@ const (
 	// ErrorTagStripped is the request-variable key under which conditional
 	// validation errors are collected.
 	ErrorTagStripped = "error"
+	// ErrorFieldsTagStripped holds the field title of each entry in
+	// ErrorTagStripped, index for index.
+	ErrorFieldsTagStripped = "error_fields"
 )
 
 const (
@@ -103,19 +108,118 @@ func CreateTextTemplate(reqCtx context.Context, config string, funcMap template.
 }
 
 func ExecuteTemplateFromContext(ctx context.Context, tmpl *template.Template) (string, error) {
-	values, err := GetAllRequestVariables(ctx)
+	rc, err := FromContextOrError(ctx)
 	if err != nil {
 		return "", fmt.Errorf("error executing template:: %w", err)
 	}
 
 	var buff bytes.Buffer
-	if err := tmpl.Execute(&buff, values); err != nil {
+	if err := tmpl.Execute(&buff, rc.templateData()); err != nil {
 		return "", fmt.Errorf("error processing template: %w", err)
 	}
 
 	return strings.ReplaceAll(buff.String(), noValue, ""), nil
 }
 
+// requestFunctionNames are the template functions registered per request by
+// the entry handlers and the plan (see AddRequestTemplateFunctions). They are
+// unknown until a request starts, so CheckTemplate accepts them by name.
+var (
+	requestFunctionNamesMu sync.RWMutex
+	requestFunctionNames   = map[string]bool{
+		"header": true, "param": true, "body": true, "urlparam": true, "t": true,
+		"action": true, "tool_param": true,
+	}
+)
+
+// RegisterRequestFunctionNames declares further request-scoped template
+// functions so CheckTemplate accepts templates using them.
+func RegisterRequestFunctionNames(names ...string) {
+	requestFunctionNamesMu.Lock()
+	defer requestFunctionNamesMu.Unlock()
+	for _, name := range names {
+		requestFunctionNames[name] = true
+	}
+}
+
+// CheckTemplate parses config the way CreateTextTemplate would, without a
+// request, and returns the syntax error if it does not parse. Calls to
+// functions that are not defined are syntax errors too.
+func CheckTemplate(config string) error {
+	_, err := NewRequestContext("check").createTemplate(config, checkFuncMap())
+	return err
+}
+
+// checkFuncMap stubs the request-scoped functions for parsing a template
+// outside of a request.
+func checkFuncMap() template.FuncMap {
+	stub := func(...interface{}) string { return "" }
+	funcMap := template.FuncMap{}
+	requestFunctionNamesMu.RLock()
+	for name := range requestFunctionNames {
+		funcMap[name] = stub
+	}
+	requestFunctionNamesMu.RUnlock()
+	return funcMap
+}
+
+// TemplateRootFields returns the names the template config reads from the
+// root of the template data, e.g. "user" for {{ .user.name }}, in the order
+// they appear. Fields read inside range and with, where dot is rebound, are
+// not included.
+func TemplateRootFields(config string) ([]string, error) {
+	tmpl, err := NewRequestContext("check").createTemplate(config, checkFuncMap())
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.FieldNode:
+			fields = append(fields, n.Ident[0])
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		}
+	}
+	if tmpl.Tree != nil {
+		walk(tmpl.Tree.Root)
+	}
+	return fields, nil
+}
+
 // ExecuteTemplateString parses config as a template against the request context
 // and renders it in one step. It is the common case of CreateTextTemplate
 // followed by ExecuteTemplateFromContext; callers needing a custom funcMap or
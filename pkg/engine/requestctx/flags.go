@@ -0,0 +1,20 @@
+package requestctx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Servflow/servflow/pkg/engine/flags"
+)
+
+// tmplFlag is the `flag "name" key` template function. It reports whether the
+// feature flag is on for key, which is usually a user or tenant id so the same
+// caller always takes the same branch. The key may be omitted for flags that
+// are simply on or off.
+func tmplFlag(name string, key ...interface{}) (bool, error) {
+	var k string
+	if len(key) > 0 && key[0] != nil {
+		k = fmt.Sprint(key[0])
+	}
+	return flags.Evaluate(context.Background(), name, k)
+}
@@ -0,0 +1,49 @@
+package requestctx
+
+import (
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagFunction(t *testing.T) {
+	half := 50.0
+	flags.GetManager().SetSources(flags.Static{
+		"on":      {Enabled: true},
+		"off":     {Enabled: false},
+		"beta":    {Enabled: true, Allow: []string{"42"}},
+		"rollout": {Enabled: true, Percentage: &half},
+	})
+	defer flags.Reset()
+
+	tests := []struct {
+		tmpl     string
+		expected string
+	}{
+		{tmpl: `{{ flag "on" }}`, expected: "true"},
+		{tmpl: `{{ flag "off" "anyone" }}`, expected: "false"},
+		{tmpl: `{{ flag "unknown" "anyone" }}`, expected: "false"},
+		{tmpl: `{{ flag "beta" 42 }}`, expected: "true"},
+		{tmpl: `{{ flag "beta" "7" }}`, expected: "false"},
+		{tmpl: `{{ if flag "on" }}new{{ else }}old{{ end }}`, expected: "new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			out, err := resolveTestTemplate(t, tt.tmpl)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, out)
+		})
+	}
+
+	t.Run("rollout is stable per key", func(t *testing.T) {
+		first, err := resolveTestTemplate(t, `{{ flag "rollout" "user-1" }}`)
+		require.NoError(t, err)
+		for i := 0; i < 5; i++ {
+			out, err := resolveTestTemplate(t, `{{ flag "rollout" "user-1" }}`)
+			require.NoError(t, err)
+			assert.Equal(t, first, out)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package requestctx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Number-formatting template functions. Each accepts any Go integer or float,
+// json.Number, or a numeric string (request bodies often carry numbers as
+// strings); anything else is an error so the template fails instead of
+// rendering a misleading value.
+
+// toNumber returns val as its canonical decimal string and as a float64.
+func toNumber(fn string, val any) (string, float64, error) {
+	switch v := val.(type) {
+	case int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8:
+		s := fmt.Sprintf("%d", v)
+		f, _ := strconv.ParseFloat(s, 64)
+		return s, f, nil
+	case float64:
+		return floatNumber(fn, v)
+	case float32:
+		return floatNumber(fn, float64(v))
+	case json.Number:
+		return stringNumber(fn, v.String())
+	case string:
+		return stringNumber(fn, v)
+	default:
+		return "", 0, fmt.Errorf("%s: %T is not a number", fn, val)
+	}
+}
+
+func floatNumber(fn string, f float64) (string, float64, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", 0, fmt.Errorf("%s: %v is not a finite number", fn, f)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), f, nil
+}
+
+func stringNumber(fn, s string) (string, float64, error) {
+	s = strings.TrimSpace(s)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("%s: %q is not a number", fn, s)
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return strconv.FormatInt(i, 10), f, nil
+	}
+	return floatNumber(fn, f)
+}
+
+// tmplComma formats a number with thousands separators: 1234567.5 renders as
+// "1,234,567.5".
+func tmplComma(val any) (string, error) {
+	s, _, err := toNumber("comma", val)
+	if err != nil {
+		return "", err
+	}
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteByte('.')
+		b.WriteString(frac)
+	}
+	return b.String(), nil
+}
+
+var byteUnits = []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}
+
+// tmplByteSize formats a byte count using decimal (1000-based) units with one
+// decimal place: 1234567 renders as "1.2 MB", 512 as "512 B".
+func tmplByteSize(val any) (string, error) {
+	_, f, err := toNumber("bytesize", val)
+	if err != nil {
+		return "", err
+	}
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+	if f < 1000 {
+		return sign + strconv.FormatFloat(f, 'f', -1, 64) + " B", nil
+	}
+	unit := 0
+	for f >= 1000 && unit < len(byteUnits)-1 {
+		f /= 1000
+		unit++
+	}
+	// Rounding can carry into the next unit (999950 -> "1000.0 KB").
+	if math.Round(f*10)/10 >= 1000 && unit < len(byteUnits)-1 {
+		f /= 1000
+		unit++
+	}
+	return fmt.Sprintf("%s%s %s", sign, trimZeros(strconv.FormatFloat(f, 'f', 1, 64)), byteUnits[unit]), nil
+}
+
+// tmplPercent formats a ratio as a percentage: 0.256 renders as "25.6%".
+// Without a precision the result has at most two decimals with trailing zeros
+// dropped ("50%"); an explicit precision is applied exactly ("50.00%").
+func tmplPercent(val any, precision ...int) (string, error) {
+	_, f, err := toNumber("percent", val)
+	if err != nil {
+		return "", err
+	}
+	if len(precision) > 1 {
+		return "", fmt.Errorf("percent: expected at most one precision, got %d", len(precision))
+	}
+	if len(precision) == 1 {
+		if precision[0] < 0 {
+			return "", fmt.Errorf("percent: precision must not be negative")
+		}
+		return strconv.FormatFloat(f*100, 'f', precision[0], 64) + "%", nil
+	}
+	return trimZeros(strconv.FormatFloat(f*100, 'f', 2, 64)) + "%", nil
+}
+
+// trimZeros drops trailing fractional zeros and a dangling decimal point.
+func trimZeros(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
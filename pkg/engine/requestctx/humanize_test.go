@@ -0,0 +1,61 @@
+package requestctx
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHumanizeFunctions(t *testing.T) {
+	tests := []struct {
+		name          string
+		templateInput string
+		values        map[string]interface{}
+		expected      string
+		wantErr       bool
+	}{
+		{name: "comma int", templateInput: `{{ comma .n }}`, values: map[string]interface{}{"n": 1234567}, expected: "1,234,567"},
+		{name: "comma small int", templateInput: `{{ comma .n }}`, values: map[string]interface{}{"n": 999}, expected: "999"},
+		{name: "comma negative", templateInput: `{{ comma .n }}`, values: map[string]interface{}{"n": -1234}, expected: "-1,234"},
+		{name: "comma float", templateInput: `{{ comma .n }}`, values: map[string]interface{}{"n": 1234567.25}, expected: "1,234,567.25"},
+		{name: "comma whole float", templateInput: `{{ comma .n }}`, values: map[string]interface{}{"n": 1000000.0}, expected: "1,000,000"},
+		{name: "comma numeric string", templateInput: `{{ comma .n }}`, values: map[string]interface{}{"n": "12345"}, expected: "12,345"},
+		{name: "comma json number", templateInput: `{{ comma .n }}`, values: map[string]interface{}{"n": json.Number("9876543")}, expected: "9,876,543"},
+		{name: "comma non-numeric", templateInput: `{{ comma .n }}`, values: map[string]interface{}{"n": "abc"}, wantErr: true},
+		{name: "comma map", templateInput: `{{ comma .n }}`, values: map[string]interface{}{"n": map[string]interface{}{}}, wantErr: true},
+
+		{name: "bytesize bytes", templateInput: `{{ bytesize .n }}`, values: map[string]interface{}{"n": 512}, expected: "512 B"},
+		{name: "bytesize kilobytes", templateInput: `{{ bytesize .n }}`, values: map[string]interface{}{"n": 1500}, expected: "1.5 KB"},
+		{name: "bytesize megabytes", templateInput: `{{ bytesize .n }}`, values: map[string]interface{}{"n": 1234567}, expected: "1.2 MB"},
+		{name: "bytesize whole unit", templateInput: `{{ bytesize .n }}`, values: map[string]interface{}{"n": 2000000000.0}, expected: "2 GB"},
+		{name: "bytesize rounds into next unit", templateInput: `{{ bytesize .n }}`, values: map[string]interface{}{"n": 999999}, expected: "1 MB"},
+		{name: "bytesize non-numeric", templateInput: `{{ bytesize .n }}`, values: map[string]interface{}{"n": true}, wantErr: true},
+
+		{name: "percent ratio", templateInput: `{{ percent .n }}`, values: map[string]interface{}{"n": 0.256}, expected: "25.6%"},
+		{name: "percent whole", templateInput: `{{ percent .n }}`, values: map[string]interface{}{"n": 0.5}, expected: "50%"},
+		{name: "percent int", templateInput: `{{ percent .n }}`, values: map[string]interface{}{"n": 1}, expected: "100%"},
+		{name: "percent rounds to two decimals", templateInput: `{{ percent .n }}`, values: map[string]interface{}{"n": 0.123456}, expected: "12.35%"},
+		{name: "percent explicit precision", templateInput: `{{ percent .n 2 }}`, values: map[string]interface{}{"n": 0.5}, expected: "50.00%"},
+		{name: "percent non-numeric", templateInput: `{{ percent .n }}`, values: map[string]interface{}{"n": "half"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := CreateTextTemplate(NewTestContext(), tt.templateInput, nil)
+			require.NoError(t, err)
+
+			ctx := NewTestContext()
+			require.NoError(t, AddRequestVariables(ctx, tt.values, ""))
+
+			result, errExec := ExecuteTemplateFromContext(ctx, tmpl)
+			if tt.wantErr {
+				assert.Error(t, errExec)
+				return
+			}
+			assert.NoError(t, errExec)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package requestctx
+
+// ArriveAtJoin records a branch reaching the join identified by id and reports
+// whether that branch should continue past it. It returns true exactly once per
+// request: for the first arrival that finds the output of every action in
+// waitFor (bare action ids) available, either as a request variable or as an
+// action file. Earlier arrivals, and any arrival after the join has fired,
+// return false and their branch ends at the join.
+//
+// The readiness check and the claim happen under the request lock, so two
+// branches finishing at the same time can never both continue.
+func (rc *RequestContext) ArriveAtJoin(id string, waitFor []string) bool {
+	rc.Lock()
+	defer rc.Unlock()
+	if rc.firedJoins[id] {
+		return false
+	}
+	for _, actionID := range waitFor {
+		if !rc.hasActionOutput(actionID) {
+			return false
+		}
+	}
+	if rc.firedJoins == nil {
+		rc.firedJoins = make(map[string]bool)
+	}
+	rc.firedJoins[id] = true
+	return true
+}
+
+// HasActionOutput reports whether the action with the bare id actionID has
+// already run in this request, i.e. left an output variable or file.
+func (rc *RequestContext) HasActionOutput(actionID string) bool {
+	rc.Lock()
+	defer rc.Unlock()
+	return rc.hasActionOutput(actionID)
+}
+
+func (rc *RequestContext) hasActionOutput(actionID string) bool {
+	if _, ok := rc.requestVariables[actionID]; ok {
+		return true
+	}
+	_, ok := rc.availableFiles[fileKeyActionPrefix+actionID]
+	return ok
+}
@@ -0,0 +1,138 @@
+package requestctx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Request-variable namespaces. Bare variables ({{ .name }}) share one space:
+// action outputs, entry handler values and built-ins such as error all live
+// in it. A namespace addresses one source explicitly, e.g. {{ .query.id }}
+// next to {{ .body.id }}, or {{ .action.user }} for the output of the action
+// user whatever else is named user.
+const (
+	BodyNamespace   = "body"
+	QueryNamespace  = "query"
+	ParamsNamespace = "params"
+	ActionNamespace = "action"
+)
+
+// ReservedVariableNames are the names templates resolve to something other
+// than an action's output. An action sharing one of them is only reliably
+// addressed through ActionNamespace.
+var ReservedVariableNames = []string{
+	BodyNamespace, QueryNamespace, ParamsNamespace, ActionNamespace,
+	ErrorTagStripped, ErrorFieldsTagStripped,
+}
+
+// SetRequestInputs sets the values of a request namespace, such as the
+// decoded body under BodyNamespace.
+func SetRequestInputs(ctx context.Context, namespace string, values map[string]interface{}) error {
+	rc, err := FromContextOrError(ctx)
+	if err != nil {
+		return err
+	}
+	rc.Lock()
+	defer rc.Unlock()
+	if rc.inputs == nil {
+		rc.inputs = make(map[string]map[string]interface{})
+	}
+	rc.inputs[namespace] = values
+	return nil
+}
+
+// LoadRequestInputs sets the body, query and params namespaces from req. A
+// JSON object or form body fills the body namespace; params are the path
+// variables. Query and form values given once are strings, repeated ones
+// lists.
+func LoadRequestInputs(ctx context.Context, req *http.Request, params map[string]string) error {
+	pathParams := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		pathParams[k] = v
+	}
+	if err := SetRequestInputs(ctx, ParamsNamespace, pathParams); err != nil {
+		return err
+	}
+	if err := SetRequestInputs(ctx, QueryNamespace, formValues(req.URL.Query())); err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{}
+	switch mediaType, _, _ := strings.Cut(req.Header.Get("Content-Type"), ";"); strings.TrimSpace(mediaType) {
+	case "application/json":
+		var decoded map[string]interface{}
+		if err := json.Unmarshal([]byte(ReadAndRestoreBody(req)), &decoded); err == nil {
+			body = decoded
+		}
+	case "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(ReadAndRestoreBody(req)); err == nil {
+			body = formValues(values)
+		}
+	}
+	return SetRequestInputs(ctx, BodyNamespace, body)
+}
+
+func formValues(values url.Values) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if len(v) == 1 {
+			out[k] = v[0]
+			continue
+		}
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		out[k] = list
+	}
+	return out
+}
+
+// AddActionOutput stores the output of action id as the bare variable id and
+// in ActionNamespace.
+func AddActionOutput(ctx context.Context, id string, output interface{}) error {
+	rc, err := FromContextOrError(ctx)
+	if err != nil {
+		return err
+	}
+	rc.Lock()
+	defer rc.Unlock()
+	rc.requestVariables[id] = output
+	if rc.actionKeys == nil {
+		rc.actionKeys = make(map[string]bool)
+	}
+	rc.actionKeys[id] = true
+	return nil
+}
+
+// templateData returns a copy of the request variables with the namespaces
+// added. A bare variable named like a namespace keeps the name, as it did
+// before namespaces existed.
+func (rc *RequestContext) templateData() map[string]interface{} {
+	rc.Lock()
+	defer rc.Unlock()
+	data := make(map[string]interface{}, len(rc.requestVariables)+len(rc.inputs)+1)
+	for k, v := range rc.requestVariables {
+		data[k] = v
+	}
+	addNamespace := func(name string, values map[string]interface{}) {
+		if _, taken := data[name]; !taken {
+			data[name] = values
+		}
+	}
+	for name, values := range rc.inputs {
+		addNamespace(name, values)
+	}
+	actions := make(map[string]interface{}, len(rc.actionKeys))
+	for id := range rc.actionKeys {
+		// a key restored away by a sub-flow is gone from the variables too
+		if v, ok := rc.requestVariables[id]; ok {
+			actions[id] = v
+		}
+	}
+	addNamespace(ActionNamespace, actions)
+	return data
+}
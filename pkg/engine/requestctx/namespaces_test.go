@@ -0,0 +1,64 @@
+package requestctx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestNamespaces(t *testing.T) {
+	render := func(t *testing.T, req *http.Request, params map[string]string, tmpl string) string {
+		t.Helper()
+		ctx := NewTestContext()
+		require.NoError(t, LoadRequestInputs(ctx, req, params))
+		require.NoError(t, AddActionOutput(ctx, "id", "from action"))
+		out, err := ExecuteTemplateString(ctx, tmpl)
+		require.NoError(t, err)
+		return out
+	}
+
+	t.Run("same name in query and body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/users?id=from-query&tag=a&tag=b", strings.NewReader(`{"id": "from-body"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		out := render(t, req, map[string]string{"id": "from-path"},
+			"{{ .query.id }}|{{ .body.id }}|{{ .params.id }}|{{ .action.id }}|{{ .id }}|{{ index .query.tag 1 }}")
+		assert.Equal(t, "from-query|from-body|from-path|from action|from action|b", out)
+	})
+
+	t.Run("form body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/users?id=from-query", strings.NewReader("id=from-form"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		assert.Equal(t, "from-query|from-form", render(t, req, nil, "{{ .query.id }}|{{ .body.id }}"))
+	})
+
+	t.Run("body stays readable", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id": 1}`))
+		req.Header.Set("Content-Type", "application/json")
+		render(t, req, nil, "{{ .body.id }}")
+		assert.Equal(t, `{"id": 1}`, ReadAndRestoreBody(req))
+	})
+
+	t.Run("a bare variable keeps a namespace name", func(t *testing.T) {
+		ctx := NewTestContext()
+		require.NoError(t, LoadRequestInputs(ctx, httptest.NewRequest(http.MethodGet, "/?a=1", nil), nil))
+		require.NoError(t, AddActionOutput(ctx, "query", "action output"))
+		out, err := ExecuteTemplateString(ctx, "{{ .query }}|{{ .action.query }}")
+		require.NoError(t, err)
+		assert.Equal(t, "action output|action output", out)
+	})
+}
+
+func TestTemplateRootFields(t *testing.T) {
+	fields, err := TemplateRootFields(`{{ .user.name }} {{ if .query }}{{ jsonraw .variable_actions_fetch }}{{ end }}{{ range .items }}{{ .inner }}{{ end }}{{ $x := .x }}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user", "query", "fetch", "items", "x"}, fields)
+
+	_, err = TemplateRootFields("{{ .unclosed ")
+	assert.Error(t, err)
+}
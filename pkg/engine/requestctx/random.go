@@ -0,0 +1,43 @@
+package requestctx
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MaxRandomStringLength bounds RandomString so a template typo can't allocate
+// an arbitrarily large string.
+const MaxRandomStringLength = 4096
+
+// urlSafeAlphabet is the base64url alphabet: every character is safe in URLs,
+// headers and file names without escaping.
+const urlSafeAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// RandomString returns n cryptographically random characters drawn from the
+// URL-safe base64 alphabet. Each character carries 6 bits of entropy.
+func RandomString(n int) (string, error) {
+	if n <= 0 || n > MaxRandomStringLength {
+		return "", fmt.Errorf("random string length must be between 1 and %d, got %d", MaxRandomStringLength, n)
+	}
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	// 64 divides 256, so masking to 6 bits keeps the distribution uniform.
+	for i, b := range buf {
+		buf[i] = urlSafeAlphabet[b&63]
+	}
+	return string(buf), nil
+}
+
+// tmplUUID returns a random (version 4) UUID.
+func tmplUUID() string {
+	return uuid.NewString()
+}
+
+// tmplRandomString is the `randomstring n` template function.
+func tmplRandomString(n int) (string, error) {
+	return RandomString(n)
+}
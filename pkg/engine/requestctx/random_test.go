@@ -0,0 +1,53 @@
+package requestctx
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resolveTestTemplate(t *testing.T, in string) (string, error) {
+	t.Helper()
+	tmpl, err := CreateTextTemplate(NewTestContext(), in, nil)
+	require.NoError(t, err)
+	return ExecuteTemplateFromContext(NewTestContext(), tmpl)
+}
+
+func TestUUIDFunction(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first, err := resolveTestTemplate(t, `{{ uuid }}`)
+	require.NoError(t, err)
+	second, err := resolveTestTemplate(t, `{{ uuid }}`)
+	require.NoError(t, err)
+
+	assert.Regexp(t, uuidPattern, first)
+	assert.Regexp(t, uuidPattern, second)
+	assert.NotEqual(t, first, second)
+}
+
+func TestRandomStringFunction(t *testing.T) {
+	urlSafe := regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	for _, n := range []int{1, 16, 64} {
+		s, err := RandomString(n)
+		require.NoError(t, err)
+		assert.Len(t, s, n)
+		assert.Regexp(t, urlSafe, s)
+	}
+
+	first, err := resolveTestTemplate(t, `{{ randomstring 32 }}`)
+	require.NoError(t, err)
+	second, err := resolveTestTemplate(t, `{{ randomstring 32 }}`)
+	require.NoError(t, err)
+	assert.Len(t, first, 32)
+	assert.Regexp(t, urlSafe, first)
+	assert.NotEqual(t, first, second)
+
+	_, err = resolveTestTemplate(t, `{{ randomstring 0 }}`)
+	assert.Error(t, err)
+	_, err = RandomString(MaxRandomStringLength + 1)
+	assert.Error(t, err)
+}
@@ -2,10 +2,36 @@ package requestctx
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 )
 
+// DefaultMaxBodySize bounds the request body BufferRawBody keeps when no
+// limit is given.
+const DefaultMaxBodySize int64 = 32 << 20
+
+// ErrBodyTooLarge is returned by BufferRawBody for a body over the limit.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+var httpRequestKey = contextKey("httpRequest")
+
+// WithRequest returns ctx carrying r, the incoming HTTP request.
+func WithRequest(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, httpRequestKey, r)
+}
+
+// RequestFromContext returns the incoming HTTP request stored by WithRequest.
+func RequestFromContext(ctx context.Context) (*http.Request, error) {
+	r, ok := ctx.Value(httpRequestKey).(*http.Request)
+	if !ok {
+		return nil, errors.New("request context is missing")
+	}
+	return r, nil
+}
+
 // ReadAndRestoreBody reads the request body and restores it so it can be read again.
 // Returns the body as a string. Returns empty string if request is nil, body is nil, or on error.
 func ReadAndRestoreBody(req *http.Request) string {
@@ -19,3 +45,48 @@ func ReadAndRestoreBody(req *http.Request) string {
 	req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 	return string(bodyBytes)
 }
+
+// BufferRawBody reads the request body, of at most limit bytes, into the
+// request context so any number of actions can read it with GetRawBody. The
+// body of req is restored for readers that still use it. A limit of zero or
+// less uses DefaultMaxBodySize.
+func BufferRawBody(ctx context.Context, req *http.Request, limit int64) error {
+	rc, err := FromContextOrError(ctx)
+	if err != nil {
+		return err
+	}
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err = io.ReadAll(io.LimitReader(req.Body, limit+1))
+		if err != nil {
+			return fmt.Errorf("error reading request body: %w", err)
+		}
+		if int64(len(body)) > limit {
+			return fmt.Errorf("%w: limit is %d bytes", ErrBodyTooLarge, limit)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	rc.Lock()
+	defer rc.Unlock()
+	rc.rawBody = body
+	rc.rawBodyBuffered = true
+	return nil
+}
+
+// GetRawBody returns the request body buffered by BufferRawBody. ok is false
+// when the body was not buffered, e.g. outside an HTTP request. The returned
+// slice is shared and must not be modified.
+func GetRawBody(ctx context.Context) (body []byte, ok bool) {
+	rc, found := FromContext(ctx)
+	if !found {
+		return nil, false
+	}
+	rc.Lock()
+	defer rc.Unlock()
+	return rc.rawBody, rc.rawBodyBuffered
+}
@@ -78,3 +78,60 @@ func TestConditionalTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestConditionalTemplate_CustomMessage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		template string
+		values   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "email custom message",
+			template: `{{email .email "email" "Adresse e-mail invalide"}}`,
+			values:   map[string]interface{}{"email": "not-an-email"},
+			expected: "Adresse e-mail invalide",
+		},
+		{
+			name:     "notempty custom message",
+			template: `{{notempty .value "value" "Please fill in your name"}}`,
+			values:   map[string]interface{}{"value": ""},
+			expected: "Please fill in your name",
+		},
+		{
+			name:     "empty custom message",
+			template: `{{empty .value "value" "Leave this blank"}}`,
+			values:   map[string]interface{}{"value": "x"},
+			expected: "Leave this blank",
+		},
+		{
+			name:     "empty message falls back to default",
+			template: `{{email .email "email" ""}}`,
+			values:   map[string]interface{}{"email": "not-an-email"},
+			expected: "email is not a valid email address",
+		},
+		{
+			name:     "default message when unset",
+			template: `{{notempty .value "value"}}`,
+			values:   map[string]interface{}{"value": ""},
+			expected: "value can not be empty",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := NewTestContext()
+			require.NoError(t, AddRequestVariables(ctx, testCase.values, ""))
+
+			rCtx, ok := FromContext(ctx)
+			require.True(t, ok)
+
+			result, err := rCtx.Resolve(ctx, testCase.template)
+			require.NoError(t, err)
+			assert.Equal(t, "false", result)
+
+			require.Len(t, rCtx.validationErrors, 1)
+			assert.EqualError(t, rCtx.validationErrors[0], testCase.expected)
+		})
+	}
+}
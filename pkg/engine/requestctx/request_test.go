@@ -72,3 +72,57 @@ func TestReadAndRestoreBody_CanReadAgain(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, body, string(bodyBytes))
 }
+
+func TestBufferRawBody(t *testing.T) {
+	t.Run("body can be read repeatedly", func(t *testing.T) {
+		ctx := NewTestContext()
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("payload"))
+
+		require.NoError(t, BufferRawBody(ctx, req, 0))
+
+		first, ok := GetRawBody(ctx)
+		require.True(t, ok)
+		second, ok := GetRawBody(ctx)
+		require.True(t, ok)
+		assert.Equal(t, "payload", string(first))
+		assert.Equal(t, "payload", string(second))
+
+		restored, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "payload", string(restored))
+	})
+
+	t.Run("body at the limit is kept", func(t *testing.T) {
+		ctx := NewTestContext()
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("12345"))
+
+		require.NoError(t, BufferRawBody(ctx, req, 5))
+		body, _ := GetRawBody(ctx)
+		assert.Equal(t, "12345", string(body))
+	})
+
+	t.Run("body over the limit is rejected", func(t *testing.T) {
+		ctx := NewTestContext()
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("123456"))
+
+		err := BufferRawBody(ctx, req, 5)
+		assert.ErrorIs(t, err, ErrBodyTooLarge)
+		_, ok := GetRawBody(ctx)
+		assert.False(t, ok)
+	})
+
+	t.Run("empty body is buffered", func(t *testing.T) {
+		ctx := NewTestContext()
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+		require.NoError(t, BufferRawBody(ctx, req, 0))
+		body, ok := GetRawBody(ctx)
+		assert.True(t, ok)
+		assert.Empty(t, body)
+	})
+
+	t.Run("not buffered", func(t *testing.T) {
+		_, ok := GetRawBody(NewTestContext())
+		assert.False(t, ok)
+	})
+}
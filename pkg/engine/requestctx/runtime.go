@@ -0,0 +1,61 @@
+package requestctx
+
+import (
+	"os"
+	"sync"
+)
+
+// RuntimeConfig exposes details of the running deployment to templates
+// through the `meta` and `env` functions, e.g. to echo the version in a
+// debug response.
+type RuntimeConfig struct {
+	// Metadata is read by `meta "key"`, e.g. {"version": "1.4.2",
+	// "environment": "staging"}. "hostname" defaults to the machine's
+	// hostname when not set here.
+	Metadata map[string]string `yaml:"metadata"`
+	// Env lists the environment variables `env "NAME"` may read. Any other
+	// variable resolves to an empty string so templates cannot leak secrets
+	// held in the environment.
+	Env []string `yaml:"env"`
+}
+
+var (
+	runtimeMu  sync.RWMutex
+	runtimeCfg RuntimeConfig
+)
+
+// SetRuntime replaces the metadata and environment allowlist used by the
+// `meta` and `env` template functions.
+func SetRuntime(cfg RuntimeConfig) {
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+	runtimeCfg = cfg
+}
+
+// tmplMeta is the `meta "key"` template function.
+func tmplMeta(key string) string {
+	runtimeMu.RLock()
+	v, ok := runtimeCfg.Metadata[key]
+	runtimeMu.RUnlock()
+	if ok {
+		return v
+	}
+	if key == "hostname" {
+		host, _ := os.Hostname()
+		return host
+	}
+	return ""
+}
+
+// tmplEnv is the `env "NAME"` template function. Only allowlisted variables
+// are read.
+func tmplEnv(name string) string {
+	runtimeMu.RLock()
+	defer runtimeMu.RUnlock()
+	for _, allowed := range runtimeCfg.Env {
+		if allowed == name {
+			return os.Getenv(name)
+		}
+	}
+	return ""
+}
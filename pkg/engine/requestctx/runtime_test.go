@@ -0,0 +1,42 @@
+package requestctx
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeFunctions(t *testing.T) {
+	t.Setenv("SERVFLOW_TEST_REGION", "eu-west-1")
+	t.Setenv("SERVFLOW_TEST_DB_PASSWORD", "hunter2")
+	SetRuntime(RuntimeConfig{
+		Metadata: map[string]string{"version": "1.4.2", "environment": "staging"},
+		Env:      []string{"SERVFLOW_TEST_REGION"},
+	})
+	defer SetRuntime(RuntimeConfig{})
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	tests := []struct {
+		tmpl     string
+		expected string
+	}{
+		{tmpl: `{{ env "SERVFLOW_TEST_REGION" }}`, expected: "eu-west-1"},
+		{tmpl: `{{ env "SERVFLOW_TEST_DB_PASSWORD" }}`, expected: ""},
+		{tmpl: `{{ env "SERVFLOW_TEST_UNSET" }}`, expected: ""},
+		{tmpl: `{{ meta "version" }}`, expected: "1.4.2"},
+		{tmpl: `{{ meta "environment" }}`, expected: "staging"},
+		{tmpl: `{{ meta "hostname" }}`, expected: hostname},
+		{tmpl: `{{ meta "unknown" }}`, expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			out, err := resolveTestTemplate(t, tt.tmpl)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, out)
+		})
+	}
+}
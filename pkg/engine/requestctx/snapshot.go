@@ -0,0 +1,66 @@
+package requestctx
+
+// Snapshot is a copy of a request's variables and files taken by
+// RequestContext.Snapshot. Values are not deep-copied: a sub-flow should set
+// new values rather than modify maps or slices it read from the parent.
+type Snapshot struct {
+	variables map[string]interface{}
+	files     map[string]*FileValue
+}
+
+// Snapshot captures the request's variables and files so that the changes a
+// sub-flow makes to them can be undone with Restore.
+func (rc *RequestContext) Snapshot() *Snapshot {
+	rc.Lock()
+	defer rc.Unlock()
+	s := &Snapshot{
+		variables: make(map[string]interface{}, len(rc.requestVariables)),
+		files:     make(map[string]*FileValue, len(rc.availableFiles)),
+	}
+	for k, v := range rc.requestVariables {
+		s.variables[k] = v
+	}
+	for k, f := range rc.availableFiles {
+		s.files[k] = f
+	}
+	return s
+}
+
+// Restore resets the request's variables and files to s, discarding whatever
+// a sub-flow set since. The keys named in merge are the exception: their
+// current variable, or the action file of an action with that id, is kept so
+// the sub-flow results the parent needs carry over. A merge key the sub-flow
+// never set is restored like any other.
+//
+// The maps are reset in place, so references from Variables stay valid.
+func (rc *RequestContext) Restore(s *Snapshot, merge ...string) {
+	rc.Lock()
+	defer rc.Unlock()
+
+	kept := make(map[string]interface{}, len(merge))
+	keptFiles := make(map[string]*FileValue)
+	for _, k := range merge {
+		if v, ok := rc.requestVariables[k]; ok {
+			kept[k] = v
+		}
+		if f, ok := rc.availableFiles[fileKeyActionPrefix+k]; ok {
+			keptFiles[fileKeyActionPrefix+k] = f
+		}
+	}
+
+	clear(rc.requestVariables)
+	for k, v := range s.variables {
+		rc.requestVariables[k] = v
+	}
+	for k, v := range kept {
+		rc.requestVariables[k] = v
+	}
+
+	clear(rc.availableFiles)
+	for k, f := range s.files {
+		rc.availableFiles[k] = f
+	}
+	for k, f := range keptFiles {
+		rc.availableFiles[k] = f
+	}
+}
@@ -0,0 +1,61 @@
+package requestctx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestContext_SnapshotRestore(t *testing.T) {
+	newParent := func() *RequestContext {
+		rc := NewRequestContext("test")
+		rc.addRequestVariables(map[string]interface{}{"user": "ada", "count": 1}, "")
+		rc.AddActionFile("upload", &FileValue{Name: "parent.txt"})
+		return rc
+	}
+
+	t.Run("sub-flow variables do not leak", func(t *testing.T) {
+		rc := newParent()
+		vars := rc.Variables()
+
+		snap := rc.Snapshot()
+		rc.addRequestVariables(map[string]interface{}{"tmp": "scratch", "count": 2}, "")
+		rc.AddActionFile("upload", &FileValue{Name: "child.txt"})
+		rc.AddActionFile("report", &FileValue{Name: "report.txt"})
+		rc.Restore(snap)
+
+		assert.Equal(t, map[string]interface{}{"user": "ada", "count": 1}, rc.Variables())
+		assert.Equal(t, map[string]interface{}{"user": "ada", "count": 1}, vars, "maps are restored in place")
+		assert.Equal(t, "parent.txt", rc.availableFiles[fileKeyActionPrefix+"upload"].Name)
+		assert.False(t, rc.HasActionOutput("report"))
+	})
+
+	t.Run("merged keys carry over", func(t *testing.T) {
+		rc := newParent()
+
+		snap := rc.Snapshot()
+		rc.addRequestVariables(map[string]interface{}{"tmp": "scratch", "total": 42, "count": 2}, "")
+		rc.AddActionFile("report", &FileValue{Name: "report.txt"})
+		rc.Restore(snap, "total", "report", "missing")
+
+		assert.Equal(t, map[string]interface{}{"user": "ada", "count": 1, "total": 42}, rc.Variables())
+		require.True(t, rc.HasActionOutput("report"))
+		assert.Equal(t, "report.txt", rc.availableFiles[fileKeyActionPrefix+"report"].Name)
+		_, ok := rc.Variables()["missing"]
+		assert.False(t, ok)
+	})
+
+	t.Run("snapshot is not affected by later writes", func(t *testing.T) {
+		rc := newParent()
+
+		snap := rc.Snapshot()
+		rc.addRequestVariables(map[string]interface{}{"user": "grace"}, "")
+		assert.Equal(t, "ada", snap.variables["user"])
+
+		rc.Restore(snap)
+		rc.addRequestVariables(map[string]interface{}{"user": "linus"}, "")
+		rc.Restore(snap)
+		assert.Equal(t, "ada", rc.Variables()["user"])
+	})
+}
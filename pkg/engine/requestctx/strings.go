@@ -0,0 +1,75 @@
+package requestctx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// String template functions. They operate on strings only: nil (a missing
+// variable) is treated as "", and any other non-string value is an error
+// rather than being silently stringified — use tostring first to transform a
+// number or object deliberately. Returning a string, never the raw input, also
+// keeps the output visible to taint tracking (see taint.go).
+
+func stringArg(fn string, val any) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("%s: expected a string, got %T", fn, val)
+	}
+}
+
+// tmplUpper upper-cases a string.
+func tmplUpper(val any) (string, error) {
+	s, err := stringArg("upper", val)
+	return strings.ToUpper(s), err
+}
+
+// tmplLower lower-cases a string.
+func tmplLower(val any) (string, error) {
+	s, err := stringArg("lower", val)
+	return strings.ToLower(s), err
+}
+
+// tmplTitle capitalizes the first letter of every word and lower-cases the
+// rest: "hELLO world" renders as "Hello World".
+func tmplTitle(val any) (string, error) {
+	s, err := stringArg("title", val)
+	return cases.Title(language.Und).String(s), err
+}
+
+// tmplTrim removes leading and trailing whitespace.
+func tmplTrim(val any) (string, error) {
+	s, err := stringArg("trim", val)
+	return strings.TrimSpace(s), err
+}
+
+// tmplReplace replaces every occurrence of old with new. The subject comes
+// last so the function reads naturally in a pipeline:
+// {{ .phone | replace "-" "" }}.
+func tmplReplace(old, new string, val any) (string, error) {
+	s, err := stringArg("replace", val)
+	return strings.ReplaceAll(s, old, new), err
+}
+
+// tmplRegexReplace replaces every match of pattern with repl, which may refer
+// to capture groups as $1 or ${name}: {{ .date | regexreplace
+// "(\\d+)-(\\d+)-(\\d+)" "$3/$2/$1" }}. An invalid pattern fails the template.
+func tmplRegexReplace(pattern, repl string, val any) (string, error) {
+	s, err := stringArg("regexreplace", val)
+	if err != nil {
+		return "", err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("regexreplace: invalid pattern %q: %w", pattern, err)
+	}
+	return re.ReplaceAllString(s, repl), nil
+}
@@ -0,0 +1,52 @@
+package requestctx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringFunctions(t *testing.T) {
+	tests := []struct {
+		name          string
+		templateInput string
+		values        map[string]interface{}
+		expected      string
+		wantErr       bool
+	}{
+		{name: "upper", templateInput: `{{ upper .s }}`, values: map[string]interface{}{"s": "Hello, World"}, expected: "HELLO, WORLD"},
+		{name: "lower", templateInput: `{{ lower .s }}`, values: map[string]interface{}{"s": "Hello, World"}, expected: "hello, world"},
+		{name: "title", templateInput: `{{ title .s }}`, values: map[string]interface{}{"s": "hELLO wide world"}, expected: "Hello Wide World"},
+		{name: "trim", templateInput: `[{{ trim .s }}]`, values: map[string]interface{}{"s": "  \tpadded\n "}, expected: "[padded]"},
+		{name: "chained", templateInput: `{{ upper (trim .s) }}`, values: map[string]interface{}{"s": "  abc  "}, expected: "ABC"},
+		{name: "missing variable is empty", templateInput: `[{{ upper .missing }}]`, values: map[string]interface{}{}, expected: "[]"},
+		{name: "non-string errors", templateInput: `{{ upper .n }}`, values: map[string]interface{}{"n": 42}, wantErr: true},
+		{name: "replace literal", templateInput: `{{ replace "-" "" .s }}`, values: map[string]interface{}{"s": "555-123-4567"}, expected: "5551234567"},
+		{name: "replace in pipeline", templateInput: `{{ .s | replace " " "_" }}`, values: map[string]interface{}{"s": "a b c"}, expected: "a_b_c"},
+		{name: "replace is not a pattern", templateInput: `{{ replace "a+" "x" .s }}`, values: map[string]interface{}{"s": "aaa a+"}, expected: "aaa x"},
+		{name: "regexreplace", templateInput: `{{ regexreplace "[0-9]" "#" .s }}`, values: map[string]interface{}{"s": "card 4111"}, expected: "card ####"},
+		{name: "regexreplace capture groups", templateInput: `{{ .s | regexreplace "(\\d+)-(\\d+)-(\\d+)" "$3/$2/$1" }}`, values: map[string]interface{}{"s": "2024-05-17"}, expected: "17/05/2024"},
+		{name: "regexreplace named group", templateInput: `{{ regexreplace "(?P<user>\\w+)@\\w+\\.com" "${user}@***" .s }}`, values: map[string]interface{}{"s": "jane@example.com"}, expected: "jane@***"},
+		{name: "regexreplace invalid pattern", templateInput: `{{ regexreplace "([a-z" "x" .s }}`, values: map[string]interface{}{"s": "abc"}, wantErr: true},
+		{name: "non-string via tostring", templateInput: `{{ upper (tostring .n) }}`, values: map[string]interface{}{"n": true}, expected: "TRUE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := CreateTextTemplate(NewTestContext(), tt.templateInput, nil)
+			require.NoError(t, err)
+
+			ctx := NewTestContext()
+			require.NoError(t, AddRequestVariables(ctx, tt.values, ""))
+
+			result, errExec := ExecuteTemplateFromContext(ctx, tmpl)
+			if tt.wantErr {
+				assert.Error(t, errExec)
+				return
+			}
+			assert.NoError(t, errExec)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
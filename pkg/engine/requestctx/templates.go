@@ -4,8 +4,14 @@ import (
 	"bytes"
 	"context"
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
+	"reflect"
 	"strconv"
 	"strings"
 	"text/template"
@@ -69,17 +75,11 @@ func (rc *RequestContext) createTemplate(in string, funcMap template.FuncMap) (*
 	return template.New("input").Option("missingkey=zero").Funcs(funcMap).Parse(replaced)
 }
 
-// executeTemplate executes a template against the request variables
+// executeTemplate executes a template against the request variables and
+// namespaces
 func (rc *RequestContext) executeTemplate(tmpl *template.Template) (string, error) {
-	rc.Lock()
-	vars := make(map[string]interface{}, len(rc.requestVariables))
-	for k, v := range rc.requestVariables {
-		vars[k] = v
-	}
-	rc.Unlock()
-
 	var buff bytes.Buffer
-	if err := tmpl.Execute(&buff, vars); err != nil {
+	if err := tmpl.Execute(&buff, rc.templateData()); err != nil {
 		return "", fmt.Errorf("error processing template: %w", err)
 	}
 
@@ -93,11 +93,13 @@ func (rc *RequestContext) getFuncMap(funcMap template.FuncMap) template.FuncMap
 		"strip":        tmplStripText,
 		"jsonout":      jsonOut,
 		"pluck":        tmplPluck,
+		"inlist":       tmplInList,
 		"escape":       stringEscape,
 		"stringescape": stringEscape, // backward compatibility
 		"jsonraw":      jsonRaw,
 		"join":         tmplJoin,
 		"hash":         tmplHash,
+		"hashwith":     tmplHashWith,
 		"now":          now,
 		"secret":       rc.tmplFuncSecret,
 		"tostring":     tostring,
@@ -106,6 +108,20 @@ func (rc *RequestContext) getFuncMap(funcMap template.FuncMap) template.FuncMap
 		"notempty":     rc.tmplFuncNotEmpty,
 		"bcrypt":       rc.tmplFuncBcrypt,
 		"file":         rc.tmplFuncFile,
+		"comma":        tmplComma,
+		"bytesize":     tmplByteSize,
+		"percent":      tmplPercent,
+		"upper":        tmplUpper,
+		"lower":        tmplLower,
+		"title":        tmplTitle,
+		"trim":         tmplTrim,
+		"replace":      tmplReplace,
+		"regexreplace": tmplRegexReplace,
+		"uuid":         tmplUUID,
+		"randomstring": tmplRandomString,
+		"flag":         tmplFlag,
+		"meta":         tmplMeta,
+		"env":          tmplEnv,
 	}
 	// Add request-scoped functions (param, header, body, urlparam, etc.)
 	for k, v := range rc.requestFuncs {
@@ -200,27 +216,81 @@ func tmplPluck(item any, key string) any {
 	}
 }
 
-// tmplHash generates an MD5 hash of the input.
-func tmplHash(item any) string {
-	var data []byte
+// tmplInList reports whether value is in list, e.g. a fetch result. With a
+// key, list holds maps and value is looked for in their key field. Values are
+// compared by their string form, so 5, 5.0 and "5" all match.
+func tmplInList(value any, list any, key ...string) bool {
+	want := tostring(value)
+	rv := reflect.ValueOf(list)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return false
+	}
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i).Interface()
+		if len(key) > 0 {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			item, ok = m[key[0]]
+			if !ok {
+				continue
+			}
+		}
+		if tostring(item) == want {
+			return true
+		}
+	}
+	return false
+}
 
+// hashInput returns the bytes a hash function digests: strings as-is,
+// primitives in their %v form and everything else as JSON.
+func hashInput(item any) ([]byte, error) {
 	switch v := item.(type) {
 	case string:
-		data = []byte(v)
+		return []byte(v), nil
 	case int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8, float64, float32, bool:
-		data = []byte(fmt.Sprintf("%v", v))
+		return []byte(fmt.Sprintf("%v", v)), nil
 	default:
-		jsonData, err := json.Marshal(v)
-		if err != nil {
-			return ""
-		}
-		data = jsonData
+		return json.Marshal(v)
+	}
+}
+
+// tmplHash generates an MD5 hash of the input.
+func tmplHash(item any) string {
+	data, err := hashInput(item)
+	if err != nil {
+		return ""
 	}
 
 	hash := md5.Sum(data)
 	return fmt.Sprintf("%x", hash)
 }
 
+// tmplHashWith returns the hex digest of the input using the named algorithm
+// (sha256, sha1 or md5), e.g. for checksums and ETags. An unknown algorithm
+// fails the template.
+func tmplHashWith(algo string, item any) (string, error) {
+	var h hash.Hash
+	switch strings.ToLower(algo) {
+	case "sha256":
+		h = sha256.New()
+	case "sha1":
+		h = sha1.New()
+	case "md5":
+		h = md5.New()
+	default:
+		return "", fmt.Errorf("hashwith: unsupported algorithm %q", algo)
+	}
+	data, err := hashInput(item)
+	if err != nil {
+		return "", fmt.Errorf("hashwith: %w", err)
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // tostring converts any input to a string representation.
 // Strings are returned as-is, nil returns empty string,
 // primitives use fmt.Sprintf, and complex types are marshaled to JSON.
@@ -265,8 +335,13 @@ func tmplJoin(item any, sep string) any {
 	}
 }
 
+// ValidationError is a failed validation recorded by a conditional template
+// function, together with the title of the field it was about.
 type ValidationError struct {
-	err error
+	Field string
+	err   error
+	// Function is the template function that failed, e.g. email.
+	Function string
 }
 
 func (v *ValidationError) Error() string {
@@ -280,20 +355,40 @@ func (v *ValidationError) Unwrap() error {
 	return v.err
 }
 
-func (rc *RequestContext) tmplFuncEmail(email interface{}, title string) bool {
-	s, ok := email.(string)
-	if !ok {
-		rc.validationErrors = append(rc.validationErrors, fmt.Errorf("%s is not a valid email address", title))
-		return false
+// addValidationError records a failed validation. A non-empty custom message
+// replaces the default one, so configs can supply user-facing or localized
+// text per condition.
+func (rc *RequestContext) addValidationError(field, function string, def error, message []string) {
+	if len(message) > 0 && message[0] != "" {
+		def = errors.New(message[0])
+	}
+	rc.validationErrors = append(rc.validationErrors, &ValidationError{Field: field, err: def, Function: function})
+}
+
+// ValidationErrors returns the validations failed so far in this request, in
+// the order they failed.
+func (rc *RequestContext) ValidationErrors() []*ValidationError {
+	rc.Lock()
+	defer rc.Unlock()
+	out := make([]*ValidationError, 0, len(rc.validationErrors))
+	for _, err := range rc.validationErrors {
+		var ve *ValidationError
+		if errors.As(err, &ve) {
+			out = append(out, ve)
+		}
 	}
-	if govalidator.IsEmail(s) {
+	return out
+}
+
+func (rc *RequestContext) tmplFuncEmail(email interface{}, title string, message ...string) bool {
+	if s, ok := email.(string); ok && govalidator.IsEmail(s) {
 		return true
 	}
-	rc.validationErrors = append(rc.validationErrors, fmt.Errorf("%s is not a valid email address", title))
+	rc.addValidationError(title, "email", fmt.Errorf("%s is not a valid email address", title), message)
 	return false
 }
 
-func (rc *RequestContext) tmplFuncEmpty(item interface{}, title string) (bool, error) {
+func (rc *RequestContext) tmplFuncEmpty(item interface{}, title string, message ...string) (bool, error) {
 	if item == nil {
 		return true, nil
 	}
@@ -312,13 +407,13 @@ func (rc *RequestContext) tmplFuncEmpty(item interface{}, title string) (bool, e
 	}
 
 	if !pass {
-		rc.validationErrors = append(rc.validationErrors, fmt.Errorf("%s should be empty", title))
+		rc.addValidationError(title, "empty", fmt.Errorf("%s should be empty", title), message)
 		return false, nil
 	}
 	return true, nil
 }
 
-func (rc *RequestContext) tmplFuncNotEmpty(item interface{}, title string) bool {
+func (rc *RequestContext) tmplFuncNotEmpty(item interface{}, title string, message ...string) bool {
 	pass := false
 	if item != nil {
 		switch t := item.(type) {
@@ -335,17 +430,17 @@ func (rc *RequestContext) tmplFuncNotEmpty(item interface{}, title string) bool
 		}
 	}
 	if !pass {
-		rc.validationErrors = append(rc.validationErrors, fmt.Errorf("%s can not be empty", title))
+		rc.addValidationError(title, "notempty", fmt.Errorf("%s can not be empty", title), message)
 		return false
 	}
 	return true
 }
 
-func (rc *RequestContext) tmplFuncBcrypt(val, hashed, name string) bool {
+func (rc *RequestContext) tmplFuncBcrypt(val, hashed, name string, message ...string) bool {
 	hashed = strings.TrimSpace(hashed)
 	err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte(val))
 	if err != nil {
-		rc.validationErrors = append(rc.validationErrors, fmt.Errorf("%s does not match", name))
+		rc.addValidationError(name, "bcrypt", fmt.Errorf("%s does not match", name), message)
 		return false
 	}
 	return true
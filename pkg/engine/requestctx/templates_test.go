@@ -111,6 +111,30 @@ func TestTemplateFunctions(t *testing.T) {
 			expected: `Pluck: Pluck`,
 			wantErr:  false,
 		},
+		{
+			name:          "inlist finds a scalar",
+			templateInput: `{{ inlist .id .ids }} {{ inlist "7" .ids }}`,
+			values:        map[string]interface{}{"id": "5", "ids": []interface{}{float64(3), float64(5)}},
+			expected:      "true false",
+		},
+		{
+			name:          "inlist finds a keyed field of maps",
+			templateInput: `{{ inlist .id .users "id" }} {{ inlist 9 .users "id" }} {{ inlist .id .users "missing" }}`,
+			values: map[string]interface{}{
+				"id": 2,
+				"users": []map[string]interface{}{
+					{"id": "1", "name": "ada"},
+					{"id": "2", "name": "grace"},
+				},
+			},
+			expected: "true false false",
+		},
+		{
+			name:          "inlist in a condition",
+			templateInput: `{{ if inlist .id .ids }}allowed{{ else }}denied{{ end }}`,
+			values:        map[string]interface{}{"id": "b", "ids": []string{"a", "c"}},
+			expected:      "denied",
+		},
 		{
 			name:          "string escape direct usage",
 			templateInput: `Escaped: {{stringescape .input}}`,
@@ -399,4 +423,70 @@ func TestTemplateFunctions(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Hashwith function tests", func(t *testing.T) {
+		hashTests := []struct {
+			name          string
+			templateInput string
+			values        map[string]interface{}
+			expected      string
+			wantErr       bool
+		}{
+			{
+				name:          "sha256",
+				templateInput: `{{hashwith "sha256" .item}}`,
+				values:        map[string]interface{}{"item": "abc"},
+				expected:      "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+			},
+			{
+				name:          "sha1",
+				templateInput: `{{hashwith "sha1" .item}}`,
+				values:        map[string]interface{}{"item": "abc"},
+				expected:      "a9993e364706816aba3e25717850c26c9cd0d89d",
+			},
+			{
+				name:          "md5",
+				templateInput: `{{hashwith "md5" .item}}`,
+				values:        map[string]interface{}{"item": "abc"},
+				expected:      "900150983cd24fb0d6963f7d28e17f72",
+			},
+			{
+				name:          "md5 matches hash",
+				templateInput: `{{eq (hashwith "md5" .item) (hash .item)}}`,
+				values:        map[string]interface{}{"item": map[string]interface{}{"key": "value"}},
+				expected:      "true",
+			},
+			{
+				name:          "empty string sha256",
+				templateInput: `{{hashwith "SHA256" .item}}`,
+				values:        map[string]interface{}{"item": ""},
+				expected:      "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			},
+			{
+				name:          "unknown algorithm",
+				templateInput: `{{hashwith "crc32" .item}}`,
+				values:        map[string]interface{}{"item": "abc"},
+				wantErr:       true,
+			},
+		}
+
+		for _, tt := range hashTests {
+			t.Run(tt.name, func(t *testing.T) {
+				tmpl, err := CreateTextTemplate(NewTestContext(), tt.templateInput, nil)
+				require.NoError(t, err)
+
+				ctx := NewTestContext()
+				err = AddRequestVariables(ctx, tt.values, "")
+				require.NoError(t, err)
+
+				result, errExec := ExecuteTemplateFromContext(ctx, tmpl)
+				if tt.wantErr {
+					assert.Error(t, errExec)
+				} else {
+					assert.NoError(t, errExec)
+					assert.Equal(t, tt.expected, result)
+				}
+			})
+		}
+	})
 }
@@ -0,0 +1,188 @@
+package requestctx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrVariableType is returned by the typed accessors when a request variable
+// cannot be coerced to the requested type.
+var ErrVariableType = errors.New("incompatible variable type")
+
+// lookupVariable returns the raw request variable, or ErrMissingVariable when
+// the key is absent or holds nil.
+func lookupVariable(ctx context.Context, key string) (interface{}, error) {
+	rc, err := FromContextOrError(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rc.Lock()
+	val, ok := rc.requestVariables[key]
+	rc.Unlock()
+	if !ok || val == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMissingVariable, key)
+	}
+	return val, nil
+}
+
+func typeError(key string, val interface{}, want string) error {
+	return fmt.Errorf("%w: %s is %T, want %s", ErrVariableType, key, val, want)
+}
+
+// GetStringVariable returns the request variable as a string. Numbers and
+// booleans are formatted; maps, slices and other composite values are rejected
+// rather than silently serialized.
+func GetStringVariable(ctx context.Context, key string) (string, error) {
+	val, err := lookupVariable(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case json.Number:
+		return v.String(), nil
+	case float64, float32, int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8, bool:
+		return tostring(v), nil
+	default:
+		return "", typeError(key, val, "string")
+	}
+}
+
+// GetIntVariable returns the request variable as an int64. Whole floats (the
+// shape every JSON number decodes to, e.g. float64(42)) and numeric strings are
+// accepted; fractional values are an error rather than being truncated.
+func GetIntVariable(ctx context.Context, key string) (int64, error) {
+	val, err := lookupVariable(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	switch v := val.(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case uint:
+		return uintToInt(key, uint64(v))
+	case uint64:
+		return uintToInt(key, v)
+	case uint32:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint8:
+		return int64(v), nil
+	case float64:
+		return floatToInt(key, v)
+	case float32:
+		return floatToInt(key, float64(v))
+	case json.Number:
+		return parseIntString(key, v.String())
+	case string:
+		return parseIntString(key, v)
+	default:
+		return 0, typeError(key, val, "int")
+	}
+}
+
+func uintToInt(key string, v uint64) (int64, error) {
+	if v > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %s value %d overflows int64", ErrVariableType, key, v)
+	}
+	return int64(v), nil
+}
+
+func floatToInt(key string, f float64) (int64, error) {
+	if f != math.Trunc(f) || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, fmt.Errorf("%w: %s value %v is not a whole number", ErrVariableType, key, f)
+	}
+	if f >= math.MaxInt64 || f < math.MinInt64 {
+		return 0, fmt.Errorf("%w: %s value %v overflows int64", ErrVariableType, key, f)
+	}
+	return int64(f), nil
+}
+
+func parseIntString(key, s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s value %q is not a number", ErrVariableType, key, s)
+	}
+	return floatToInt(key, f)
+}
+
+// GetFloatVariable returns the request variable as a float64. Integers and
+// numeric strings are accepted.
+func GetFloatVariable(ctx context.Context, key string) (float64, error) {
+	val, err := lookupVariable(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	switch v := val.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8:
+		rv := reflect.ValueOf(v)
+		if rv.CanInt() {
+			return float64(rv.Int()), nil
+		}
+		return float64(rv.Uint()), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s value %q is not a number", ErrVariableType, key, v)
+		}
+		return f, nil
+	default:
+		return 0, typeError(key, val, "float")
+	}
+}
+
+// GetBoolVariable returns the request variable as a bool. Strings accepted by
+// strconv.ParseBool ("true", "1", "false", "0", ...) and the numbers 0 and 1
+// are coerced; anything else is an error.
+func GetBoolVariable(ctx context.Context, key string) (bool, error) {
+	val, err := lookupVariable(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	switch v := val.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return false, fmt.Errorf("%w: %s value %q is not a boolean", ErrVariableType, key, v)
+		}
+		return b, nil
+	case float64, float32, int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8, json.Number:
+		i, err := GetIntVariable(ctx, key)
+		if err != nil || (i != 0 && i != 1) {
+			return false, fmt.Errorf("%w: %s value %v is not a boolean", ErrVariableType, key, v)
+		}
+		return i == 1, nil
+	default:
+		return false, typeError(key, val, "bool")
+	}
+}
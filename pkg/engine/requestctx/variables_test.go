@@ -0,0 +1,155 @@
+package requestctx
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedVariableAccessors(t *testing.T) {
+	ctx := NewTestContext()
+	require.NoError(t, AddRequestVariables(ctx, map[string]interface{}{
+		"str":       "hello",
+		"numStr":    " 42 ",
+		"floatStr":  "3.5",
+		"wholeF":    float64(42),
+		"fracF":     42.5,
+		"int":       7,
+		"jsonNum":   json.Number("12"),
+		"boolTrue":  true,
+		"boolStr":   "false",
+		"one":       float64(1),
+		"two":       2,
+		"obj":       map[string]interface{}{"a": 1},
+		"list":      []interface{}{"a"},
+		"nilValue":  nil,
+		"bigFloat":  1e30,
+		"pow63":     math.Pow(2, 63),
+		"minFloat":  float64(math.MinInt64),
+		"wordBool":  "yes",
+		"emptyList": []string{},
+	}, ""))
+
+	t.Run("string", func(t *testing.T) {
+		tests := []struct {
+			key     string
+			want    string
+			wantErr error
+		}{
+			{key: "str", want: "hello"},
+			{key: "wholeF", want: "42"},
+			{key: "fracF", want: "42.5"},
+			{key: "int", want: "7"},
+			{key: "jsonNum", want: "12"},
+			{key: "boolTrue", want: "true"},
+			{key: "obj", wantErr: ErrVariableType},
+			{key: "list", wantErr: ErrVariableType},
+			{key: "nilValue", wantErr: ErrMissingVariable},
+			{key: "absent", wantErr: ErrMissingVariable},
+		}
+		for _, tt := range tests {
+			t.Run(tt.key, func(t *testing.T) {
+				got, err := GetStringVariable(ctx, tt.key)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		}
+	})
+
+	t.Run("int", func(t *testing.T) {
+		tests := []struct {
+			key     string
+			want    int64
+			wantErr error
+		}{
+			{key: "wholeF", want: 42},
+			{key: "int", want: 7},
+			{key: "numStr", want: 42},
+			{key: "jsonNum", want: 12},
+			{key: "fracF", wantErr: ErrVariableType},
+			{key: "floatStr", wantErr: ErrVariableType},
+			{key: "str", wantErr: ErrVariableType},
+			{key: "bigFloat", wantErr: ErrVariableType},
+			{key: "pow63", wantErr: ErrVariableType},
+			{key: "minFloat", want: math.MinInt64},
+			{key: "boolTrue", wantErr: ErrVariableType},
+			{key: "absent", wantErr: ErrMissingVariable},
+		}
+		for _, tt := range tests {
+			t.Run(tt.key, func(t *testing.T) {
+				got, err := GetIntVariable(ctx, tt.key)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		}
+	})
+
+	t.Run("float", func(t *testing.T) {
+		tests := []struct {
+			key     string
+			want    float64
+			wantErr error
+		}{
+			{key: "fracF", want: 42.5},
+			{key: "int", want: 7},
+			{key: "floatStr", want: 3.5},
+			{key: "jsonNum", want: 12},
+			{key: "str", wantErr: ErrVariableType},
+			{key: "emptyList", wantErr: ErrVariableType},
+		}
+		for _, tt := range tests {
+			t.Run(tt.key, func(t *testing.T) {
+				got, err := GetFloatVariable(ctx, tt.key)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		}
+	})
+
+	t.Run("bool", func(t *testing.T) {
+		tests := []struct {
+			key     string
+			want    bool
+			wantErr error
+		}{
+			{key: "boolTrue", want: true},
+			{key: "boolStr", want: false},
+			{key: "one", want: true},
+			{key: "two", wantErr: ErrVariableType},
+			{key: "wordBool", wantErr: ErrVariableType},
+			{key: "obj", wantErr: ErrVariableType},
+			{key: "absent", wantErr: ErrMissingVariable},
+		}
+		for _, tt := range tests {
+			t.Run(tt.key, func(t *testing.T) {
+				got, err := GetBoolVariable(ctx, tt.key)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		}
+	})
+
+	t.Run("no request context", func(t *testing.T) {
+		_, err := GetStringVariable(t.Context(), "str")
+		assert.ErrorIs(t, err, ErrNoContext)
+	})
+}
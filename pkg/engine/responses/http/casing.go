@@ -0,0 +1,152 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+)
+
+const (
+	keyCaseSnake  = "snake"
+	keyCaseCamel  = "camel"
+	keyCasePascal = "pascal"
+)
+
+// recaseKeys rewrites the object keys of a JSON body, at any depth, in
+// keyCase: "snake" (user_id), "camel" (userId) or "pascal" (UserId). Key
+// order is kept and the body is compacted. Bodies that are not JSON are
+// returned unchanged.
+func recaseKeys(body []byte, keyCase string) []byte {
+	if keyCase == "" || !json.Valid(body) {
+		return body
+	}
+	var buf bytes.Buffer
+	if err := rewriteKeys(&buf, body, func(key string) string { return convertCase(key, keyCase) }); err != nil {
+		return body
+	}
+	return buf.Bytes()
+}
+
+// rewriteKeys copies the JSON document in body to buf as compact JSON with
+// every object key passed through fn.
+func rewriteKeys(buf *bytes.Buffer, body []byte, fn func(string) string) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	// objects tracks the open containers, true for an object; expectKey is
+	// set when the next string in the innermost object is a key.
+	var objects []bool
+	expectKey := false
+	// first is set when the next value opens its container, so needs no comma.
+	first := false
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			buf.WriteByte(byte(d))
+			objects = objects[:len(objects)-1]
+			first = false
+			expectKey = len(objects) > 0 && objects[len(objects)-1]
+			continue
+		}
+
+		inObject := len(objects) > 0 && objects[len(objects)-1]
+		if !first && len(objects) > 0 && (expectKey || !inObject) {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		if expectKey {
+			key, _ := json.Marshal(fn(tok.(string)))
+			buf.Write(key)
+			buf.WriteByte(':')
+			expectKey = false
+			// the value follows the colon without a comma
+			first = true
+			continue
+		}
+
+		switch v := tok.(type) {
+		case json.Delim:
+			buf.WriteByte(byte(v))
+			objects = append(objects, v == '{')
+			expectKey = v == '{'
+			first = true
+			continue
+		case json.Number:
+			buf.WriteString(v.String())
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			buf.Write(encoded)
+		}
+		expectKey = inObject
+	}
+	if len(objects) != 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// convertCase joins the words of key in keyCase.
+func convertCase(key, keyCase string) string {
+	words := splitWords(key)
+	if len(words) == 0 {
+		return key
+	}
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if keyCase == keyCasePascal || (keyCase == keyCaseCamel && i > 0) {
+			r := []rune(w)
+			r[0] = unicode.ToUpper(r[0])
+			w = string(r)
+		}
+		words[i] = w
+	}
+	if keyCase == keyCaseSnake {
+		return strings.Join(words, "_")
+	}
+	return strings.Join(words, "")
+}
+
+// splitWords breaks key into words at underscores, hyphens and spaces and at
+// case changes, keeping acronyms together: "userID" gives [user ID] and
+// "HTTPServer" gives [HTTP Server].
+func splitWords(key string) []string {
+	var words []string
+	runes := []rune(key)
+	start := 0
+	flush := func(end int) {
+		if end > start {
+			words = append(words, string(runes[start:end]))
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush(i)
+			start = i + 1
+		case i > start && unicode.IsUpper(r):
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush(i)
+				start = i
+			}
+		}
+	}
+	flush(len(runes))
+	return words
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecaseKeys(t *testing.T) {
+	snake := `{"user_id":1,"first_name":"Ada","home_address":{"zip_code":"E1","geo_point":[1.5,2]},"past_orders":[{"order_id":7,"line_items":[]},{"order_id":8,"is_paid":true,"paid_at":null}]}`
+	camel := `{"userId":1,"firstName":"Ada","homeAddress":{"zipCode":"E1","geoPoint":[1.5,2]},"pastOrders":[{"orderId":7,"lineItems":[]},{"orderId":8,"isPaid":true,"paidAt":null}]}`
+	pascal := `{"UserId":1,"FirstName":"Ada","HomeAddress":{"ZipCode":"E1","GeoPoint":[1.5,2]},"PastOrders":[{"OrderId":7,"LineItems":[]},{"OrderId":8,"IsPaid":true,"PaidAt":null}]}`
+
+	assert.Equal(t, camel, string(recaseKeys([]byte(snake), keyCaseCamel)))
+	assert.Equal(t, pascal, string(recaseKeys([]byte(snake), keyCasePascal)))
+	assert.Equal(t, snake, string(recaseKeys([]byte(camel), keyCaseSnake)))
+	assert.Equal(t, snake, string(recaseKeys([]byte(pascal), keyCaseSnake)))
+
+	t.Run("keeps order and values", func(t *testing.T) {
+		in := "{\n  \"z_key\": \"first_name\",\n  \"a_key\": 12345678901234567890\n}"
+		assert.Equal(t, `{"zKey":"first_name","aKey":12345678901234567890}`, string(recaseKeys([]byte(in), keyCaseCamel)))
+	})
+	t.Run("non JSON and scalars", func(t *testing.T) {
+		assert.Equal(t, "user_id", string(recaseKeys([]byte("user_id"), keyCaseCamel)))
+		assert.Equal(t, `"user_id"`, string(recaseKeys([]byte(`"user_id"`), keyCaseCamel)))
+		assert.Equal(t, `[["a_b"],{"c_d":{}}]`, string(recaseKeys([]byte(`[ ["a_b"], {"c_d": {}} ]`), keyCaseSnake)))
+	})
+}
+
+func TestConvertCase(t *testing.T) {
+	tests := []struct {
+		key                  string
+		snake, camel, pascal string
+	}{
+		{"user_id", "user_id", "userId", "UserId"},
+		{"userID", "user_id", "userId", "UserId"},
+		{"HTTPServer", "http_server", "httpServer", "HttpServer"},
+		{"address2Line", "address2_line", "address2Line", "Address2Line"},
+		{"content-type", "content_type", "contentType", "ContentType"},
+		{"_id", "id", "id", "Id"},
+		{"name", "name", "name", "Name"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.snake, convertCase(tt.key, keyCaseSnake), tt.key)
+		assert.Equal(t, tt.camel, convertCase(tt.key, keyCaseCamel), tt.key)
+		assert.Equal(t, tt.pascal, convertCase(tt.key, keyCasePascal), tt.key)
+	}
+}
+
+func TestResponseKeyCase(t *testing.T) {
+	build := func(t *testing.T, cfg apiconfig.ResponseConfig) string {
+		t.Helper()
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{
+			"row": map[string]interface{}{"user_id": 1, "created_at": "today"},
+		}, ""))
+		builder, err := newBuilder(cfg)
+		require.NoError(t, err)
+		resp, err := builder.BuildResponse(ctx)
+		require.NoError(t, err)
+		return string(resp.(*sfhttp.SfResponse).Body)
+	}
+
+	t.Run("template", func(t *testing.T) {
+		body := build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Type: bodyTemplate, Template: `{"data": {{ jsonout .row }}}`, KeyCase: "camel"})
+		assert.JSONEq(t, `{"data": {"userId": 1, "createdAt": "today"}}`, body)
+	})
+	t.Run("object", func(t *testing.T) {
+		body := build(t, apiconfig.ResponseConfig{Code: http.StatusOK, KeyCase: "pascal", Object: apiconfig.ResponseObject{Fields: map[string]apiconfig.ResponseObject{
+			"user_row": {Value: "{{ .row }}"},
+		}}})
+		assert.JSONEq(t, `{"UserRow": {"UserId": 1, "CreatedAt": "today"}}`, body)
+	})
+	t.Run("unknown case", func(t *testing.T) {
+		_, err := newBuilder(apiconfig.ResponseConfig{Code: http.StatusOK, KeyCase: "kebab"})
+		assert.ErrorContains(t, err, "unknown response key case")
+	})
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/engine/responses"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// CSVBuilder renders a response object that resolves to a list of objects as
+// CSV: a header row of every key found in any object, sorted, then one row per
+// object. Missing keys give empty cells and nested values are JSON encoded.
+type CSVBuilder struct {
+	object *apiconfig.ResponseObject
+	code   int
+	// filename, when set, is sent in Content-Disposition.
+	filename string
+	// keyCase rewrites the column names, see convertCase.
+	keyCase string
+}
+
+func NewCSVBuilder(object *apiconfig.ResponseObject, code int) *CSVBuilder {
+	return &CSVBuilder{
+		object: object,
+		code:   code,
+	}
+}
+
+func (c *CSVBuilder) BuildResponse(ctx context.Context) (responses.Result, error) {
+	logger := logging.FromContext(ctx).With(zap.String("builder_type", bodyCSV))
+	ctx = logging.WithLogger(ctx, logger)
+
+	val, err := generateValue(ctx, c.object)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := csvRows(val)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("running csv response builder", zap.Int("rows", len(rows)))
+
+	body, err := c.encode(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error writing csv: %w", err)
+	}
+
+	response := &sfhttp.SfResponse{
+		Body: body,
+		Code: c.code,
+	}
+	response.SetHeader("Content-Type", "text/csv; charset=utf-8")
+	if c.filename != "" {
+		filename, err := requestctx.ExecuteTemplateString(ctx, c.filename)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering csv filename: %w", err)
+		}
+		response.SetHeader("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	return response, nil
+}
+
+// csvRows returns val as a list of objects; null is an empty list.
+func csvRows(val any) ([]map[string]any, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case []map[string]any:
+		return v, nil
+	case []any:
+		rows := make([]map[string]any, len(v))
+		for i, item := range v {
+			row, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("csv row %d is %T, want an object", i, item)
+			}
+			rows[i] = row
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("csv response needs a list of objects, got %T", val)
+	}
+}
+
+func (c *CSVBuilder) encode(rows []map[string]any) ([]byte, error) {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, row := range rows {
+		for key := range row {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column
+		if c.keyCase != "" {
+			header[i] = convertCase(column, c.keyCase)
+		}
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			cell, err := csvCell(row[column])
+			if err != nil {
+				return nil, err
+			}
+			record[i] = cell
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// csvCell writes strings as they are, null as an empty cell and any other
+// value as JSON.
+func csvCell(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVBuilder(t *testing.T) {
+	build := func(t *testing.T, cfg apiconfig.ResponseConfig) (*sfhttp.SfResponse, error) {
+		t.Helper()
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{"name": "kofo", "age": 30, "tags": []interface{}{"a", "b"}},
+				map[string]interface{}{"name": "ada, jr", "email": "ada@example.com", "address": map[string]interface{}{"city": "Lagos"}},
+			},
+			"report": "users",
+		}, ""))
+		cfg.Code = http.StatusOK
+		cfg.Type = bodyCSV
+		builder, err := newBuilder(cfg)
+		require.NoError(t, err)
+		resp, err := builder.BuildResponse(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return resp.(*sfhttp.SfResponse), nil
+	}
+
+	t.Run("header is the sorted union of keys", func(t *testing.T) {
+		resp, err := build(t, apiconfig.ResponseConfig{Object: apiconfig.ResponseObject{Value: "{{ .users }}"}})
+		require.NoError(t, err)
+		assert.Equal(t, "text/csv; charset=utf-8", resp.Headers.Get("Content-Type"))
+		assert.Empty(t, resp.Headers.Get("Content-Disposition"))
+		assert.Equal(t, "address,age,email,name,tags\n"+
+			`,30,,kofo,"[""a"",""b""]"`+"\n"+
+			`"{""city"":""Lagos""}",,ada@example.com,"ada, jr",`+"\n", string(resp.Body))
+	})
+
+	t.Run("filename and key case", func(t *testing.T) {
+		resp, err := build(t, apiconfig.ResponseConfig{
+			Object:   apiconfig.ResponseObject{Value: `[{"user_id": 1}]`},
+			Filename: "{{ .report }}.csv",
+			KeyCase:  keyCaseCamel,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `attachment; filename=users.csv`, resp.Headers.Get("Content-Disposition"))
+		assert.Equal(t, "userId\n1\n", string(resp.Body))
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		resp, err := build(t, apiconfig.ResponseConfig{Object: apiconfig.ResponseObject{Value: "[]"}})
+		require.NoError(t, err)
+		assert.Equal(t, "\n", string(resp.Body))
+	})
+
+	t.Run("not a list of objects", func(t *testing.T) {
+		_, err := build(t, apiconfig.ResponseConfig{Object: apiconfig.ResponseObject{Value: "{{ .report }}"}})
+		assert.Error(t, err)
+		_, err = build(t, apiconfig.ResponseConfig{Object: apiconfig.ResponseObject{Value: `[1, 2]`}})
+		assert.Error(t, err)
+	})
+}
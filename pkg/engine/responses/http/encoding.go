@@ -0,0 +1,173 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatYAML = "yaml"
+	formatXML  = "xml"
+
+	// xmlRootElement wraps an XML body; xmlItemElement holds each element of
+	// an array.
+	xmlRootElement = "response"
+	xmlItemElement = "item"
+)
+
+// encodingContentTypes maps each alternative encoding of an object body to
+// its Content-Type.
+var encodingContentTypes = map[string]string{
+	formatYAML: "application/yaml",
+	formatXML:  "application/xml",
+}
+
+// acceptEncodings maps the media types of an Accept header to the encoding
+// they ask for; "" is JSON.
+var acceptEncodings = map[string]string{
+	"application/json":   "",
+	"*/*":                "",
+	"application/yaml":   formatYAML,
+	"application/x-yaml": formatYAML,
+	"text/yaml":          formatYAML,
+	"application/xml":    formatXML,
+	"text/xml":           formatXML,
+}
+
+// objectEncoding returns the encoding of an object body: format when it is
+// yaml or xml, otherwise the first of JSON, YAML or XML listed in the
+// request's Accept header. "" means JSON.
+func objectEncoding(ctx context.Context, format string) string {
+	if _, ok := encodingContentTypes[format]; ok {
+		return format
+	}
+	for _, part := range strings.Split(requestHeader(ctx, "Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		if encoding, ok := acceptEncodings[mediaType]; ok {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// encodeJSON re-encodes the JSON document in body as encoding.
+func encodeJSON(body []byte, encoding string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var val any
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	switch encoding {
+	case formatYAML:
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(yamlNumbers(val)); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	case formatXML:
+		buf.WriteString(xml.Header)
+		enc := xml.NewEncoder(&buf)
+		if err := encodeXMLElement(enc, xmlRootElement, val); err != nil {
+			return nil, err
+		}
+		if err := enc.Flush(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown encoding: %s", encoding)
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlNumbers replaces the json.Numbers in val, which YAML would write as
+// strings, with number nodes holding the same digits.
+func yamlNumbers(val any) any {
+	switch v := val.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = yamlNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = yamlNumbers(item)
+		}
+	case json.Number:
+		tag := "!!int"
+		if _, err := v.Int64(); err != nil {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+	}
+	return val
+}
+
+// encodeXMLElement writes val as the element name: objects as one child
+// element per key in sorted order, arrays as one item element per entry and
+// null as an empty element.
+func encodeXMLElement(enc *xml.Encoder, name string, val any) error {
+	start := xml.StartElement{Name: xml.Name{Local: xmlName(name)}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch v := val.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := encodeXMLElement(enc, k, v[k]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := encodeXMLElement(enc, xmlItemElement, item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlName turns a JSON key into a valid XML element name, replacing the
+// characters a name cannot hold with underscores and prefixing one when the
+// key does not start with a letter or underscore.
+func xmlName(key string) string {
+	var b strings.Builder
+	if r, _ := utf8.DecodeRuneInString(key); !unicode.IsLetter(r) && r != '_' {
+		b.WriteByte('_')
+	}
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.' {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestResponseEncoding(t *testing.T) {
+	object := apiconfig.ResponseObject{Fields: map[string]apiconfig.ResponseObject{
+		"user": {Fields: map[string]apiconfig.ResponseObject{
+			"name": {Value: "{{ .name }}"},
+			"age":  {Value: "{{ .age }}"},
+		}},
+		"tags": {Value: "{{ .tags }}"},
+	}}
+
+	build := func(t *testing.T, cfg apiconfig.ResponseConfig, accept string) *sfhttp.SfResponse {
+		t.Helper()
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{
+			"name": "kofo",
+			"age":  30,
+			"tags": []interface{}{"a", "b"},
+		}, ""))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", accept)
+		ctx = requestctx.WithRequest(ctx, req)
+
+		builder, err := newBuilder(cfg)
+		require.NoError(t, err)
+		resp, err := builder.BuildResponse(ctx)
+		require.NoError(t, err)
+		return resp.(*sfhttp.SfResponse)
+	}
+
+	wantYAML := "tags:\n  - a\n  - b\nuser:\n  age: 30\n  name: kofo\n"
+	wantXML := xml.Header + "<response><tags><item>a</item><item>b</item></tags><user><age>30</age><name>kofo</name></user></response>"
+
+	t.Run("yaml format", func(t *testing.T) {
+		resp := build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Object: object, Format: "yaml"}, "")
+		assert.Equal(t, "application/yaml", resp.Headers.Get("Content-Type"))
+		assert.Equal(t, wantYAML, string(resp.Body))
+
+		var decoded map[string]any
+		require.NoError(t, yaml.Unmarshal(resp.Body, &decoded))
+		assert.Equal(t, map[string]any{
+			"user": map[string]any{"name": "kofo", "age": 30},
+			"tags": []any{"a", "b"},
+		}, decoded)
+	})
+
+	t.Run("xml format", func(t *testing.T) {
+		resp := build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Object: object, Format: "xml"}, "")
+		assert.Equal(t, "application/xml", resp.Headers.Get("Content-Type"))
+		assert.Equal(t, wantXML, string(resp.Body))
+	})
+
+	t.Run("accept header selects the encoding", func(t *testing.T) {
+		resp := build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Object: object}, "text/html, application/x-yaml;q=0.9")
+		assert.Equal(t, "application/yaml", resp.Headers.Get("Content-Type"))
+		assert.Equal(t, wantYAML, string(resp.Body))
+
+		resp = build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Object: object}, "text/xml")
+		assert.Equal(t, wantXML, string(resp.Body))
+
+		resp = build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Object: object}, "application/json, application/xml")
+		assert.Equal(t, "application/json", resp.Headers.Get("Content-Type"))
+		assert.JSONEq(t, `{"user":{"name":"kofo","age":30},"tags":["a","b"]}`, string(resp.Body))
+	})
+
+	t.Run("format takes precedence over accept", func(t *testing.T) {
+		resp := build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Object: object, Format: "xml"}, "application/yaml")
+		assert.Equal(t, wantXML, string(resp.Body))
+	})
+
+	t.Run("key case applies to every encoding", func(t *testing.T) {
+		cased := apiconfig.ResponseObject{Fields: map[string]apiconfig.ResponseObject{
+			"user_name": {Value: "{{ .name }}"},
+		}}
+		resp := build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Object: cased, Format: "xml", KeyCase: "camel"}, "")
+		assert.Equal(t, xml.Header+"<response><userName>kofo</userName></response>", string(resp.Body))
+	})
+
+	t.Run("template bodies cannot be re-encoded", func(t *testing.T) {
+		_, err := newBuilder(apiconfig.ResponseConfig{Code: http.StatusOK, Template: "hi", Format: "yaml"})
+		assert.Error(t, err)
+	})
+}
+
+func TestXMLName(t *testing.T) {
+	assert.Equal(t, "user_id", xmlName("user_id"))
+	assert.Equal(t, "_1st", xmlName("1st"))
+	assert.Equal(t, "a_b", xmlName("a b"))
+	assert.Equal(t, "__x", xmlName(" x"))
+	assert.Equal(t, "_", xmlName(""))
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+)
+
+const (
+	formatCompact = "compact"
+	formatPretty  = "pretty"
+
+	// FormatHeader lets a caller ask for a compact or pretty body, e.g. while
+	// debugging with curl. It takes precedence over the response's format.
+	FormatHeader = "X-Response-Format"
+)
+
+// formatResponse re-encodes the JSON body of resp in format, or in the
+// format asked for by the request's FormatHeader. Bodies that are not JSON
+// are left untouched.
+func formatResponse(ctx context.Context, resp *sfhttp.SfResponse, format string) *sfhttp.SfResponse {
+	if requested := requestedFormat(ctx); requested != "" {
+		format = requested
+	}
+	if format != "" {
+		resp.Body = formatJSON(resp.Body, format)
+	}
+	return resp
+}
+
+// formatJSON compacts or indents body, returning it unchanged when it is not
+// valid JSON.
+func formatJSON(body []byte, format string) []byte {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case formatCompact:
+		err = json.Compact(&buf, body)
+	case formatPretty:
+		err = json.Indent(&buf, bytes.TrimSpace(body), "", "  ")
+	default:
+		return body
+	}
+	if err != nil {
+		return body
+	}
+	return buf.Bytes()
+}
+
+// requestedFormat returns the format named in FormatHeader on the incoming
+// request, or "" when it is absent or not a known format.
+func requestedFormat(ctx context.Context) string {
+	switch v := strings.ToLower(strings.TrimSpace(requestHeader(ctx, FormatHeader))); v {
+	case formatCompact, formatPretty:
+		return v
+	default:
+		return ""
+	}
+}
+
+// requestHeader returns the header key of the incoming request, or "" outside
+// of a request.
+func requestHeader(ctx context.Context, key string) string {
+	req, err := requestctx.RequestFromContext(ctx)
+	if err != nil {
+		return ""
+	}
+	return req.Header.Get(key)
+}
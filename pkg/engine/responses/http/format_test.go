@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseFormat(t *testing.T) {
+	object := apiconfig.ResponseObject{Fields: map[string]apiconfig.ResponseObject{
+		"user": {Fields: map[string]apiconfig.ResponseObject{
+			"name": {Value: "{{ .name }}"},
+		}},
+	}}
+	compact := `{"user":{"name":"kofo"}}`
+	pretty := "{\n  \"user\": {\n    \"name\": \"kofo\"\n  }\n}"
+
+	build := func(t *testing.T, cfg apiconfig.ResponseConfig, requestHeader string) string {
+		t.Helper()
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"name": "kofo"}, ""))
+		if requestHeader != "" {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(FormatHeader, requestHeader)
+			ctx = requestctx.WithRequest(ctx, req)
+		}
+
+		builder, err := newBuilder(cfg)
+		require.NoError(t, err)
+		resp, err := builder.BuildResponse(ctx)
+		require.NoError(t, err)
+		body := string(resp.(*sfhttp.SfResponse).Body)
+		assert.JSONEq(t, compact, body)
+		return body
+	}
+
+	t.Run("object defaults to compact", func(t *testing.T) {
+		assert.Equal(t, compact, build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Object: object}, ""))
+	})
+	t.Run("object pretty", func(t *testing.T) {
+		assert.Equal(t, pretty, build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Object: object, Format: "pretty"}, ""))
+	})
+	t.Run("header overrides response format", func(t *testing.T) {
+		assert.Equal(t, pretty, build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Object: object, Format: "compact"}, "Pretty"))
+	})
+	t.Run("unknown header value is ignored", func(t *testing.T) {
+		assert.Equal(t, compact, build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Object: object}, "yaml"))
+	})
+
+	template := `{
+		"user": { "name": "{{ .name }}" }
+	}`
+	t.Run("template compact", func(t *testing.T) {
+		assert.Equal(t, compact, build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Type: bodyTemplate, Template: template, Format: "compact"}, ""))
+	})
+	t.Run("template pretty", func(t *testing.T) {
+		assert.Equal(t, pretty, build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Type: bodyTemplate, Template: template, Format: "pretty"}, ""))
+	})
+	t.Run("template left as rendered", func(t *testing.T) {
+		assert.Equal(t, "{\n\t\t\"user\": { \"name\": \"kofo\" }\n\t}", build(t, apiconfig.ResponseConfig{Code: http.StatusOK, Type: bodyTemplate, Template: template}, ""))
+	})
+
+	t.Run("non JSON body is untouched", func(t *testing.T) {
+		builder, err := newBuilder(apiconfig.ResponseConfig{Code: http.StatusOK, Type: bodyTemplate, Template: "plain {{ .name }}", Format: "pretty"})
+		require.NoError(t, err)
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"name": "kofo"}, ""))
+		resp, err := builder.BuildResponse(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "plain kofo", string(resp.(*sfhttp.SfResponse).Body))
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := newBuilder(apiconfig.ResponseConfig{Code: http.StatusOK, Object: object, Format: "toml"})
+		assert.EqualError(t, err, "unknown response format: toml")
+	})
+}
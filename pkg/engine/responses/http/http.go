@@ -1,10 +1,16 @@
 // Package http implements the built-in "http" response type: a status code plus
-// a body rendered either as a Go template or as a structured JSON object. It
-// registers itself with the responses registry at init.
+// a body rendered as a Go template, as a structured object in JSON, YAML or
+// XML, from the collected validation errors, as a multipart body of JSON
+// metadata and a file, as CSV, as server-sent events, or as an upstream
+// response captured by the http action. It registers itself with the responses
+// registry at init.
 package http
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/Servflow/servflow/pkg/apiconfig"
 	"github.com/Servflow/servflow/pkg/engine/responses"
@@ -13,6 +19,16 @@ import (
 const (
 	bodyTemplate = "template"
 	bodyObject   = "json_object"
+	// bodyValidationErrors renders the error variable as field errors.
+	bodyValidationErrors = "validation_errors"
+	// bodyMultipart combines JSON metadata and a file in one body.
+	bodyMultipart = "multipart"
+	// bodySSE renders a list as server-sent events.
+	bodySSE = "sse"
+	// bodyCSV renders a list of objects as CSV.
+	bodyCSV = "csv"
+	// bodyPassthrough writes a captured upstream response as it was.
+	bodyPassthrough = "passthrough"
 )
 
 func init() {
@@ -23,6 +39,9 @@ func init() {
 // preserving the historical behaviour: an empty type defaults to json_object
 // when an object is present, otherwise template.
 func newBuilder(cfg apiconfig.ResponseConfig) (responses.ResponseBuilder, error) {
+	if cfg.Type == bodyValidationErrors && cfg.Code == 0 {
+		cfg.Code = http.StatusBadRequest
+	}
 	if cfg.Code < 100 || cfg.Code > 999 {
 		return nil, fmt.Errorf("invalid response code: %d", cfg.Code)
 	}
@@ -36,12 +55,88 @@ func newBuilder(cfg apiconfig.ResponseConfig) (responses.ResponseBuilder, error)
 		}
 	}
 
+	switch cfg.Format {
+	case "", formatCompact, formatPretty:
+	case formatYAML, formatXML:
+		if bodyType != bodyObject && bodyType != bodyMultipart {
+			return nil, fmt.Errorf("response format %s needs a json_object body", cfg.Format)
+		}
+	default:
+		return nil, fmt.Errorf("unknown response format: %s", cfg.Format)
+	}
+
+	switch cfg.KeyCase {
+	case "", keyCaseSnake, keyCaseCamel, keyCasePascal:
+	default:
+		return nil, fmt.Errorf("unknown response key case: %s", cfg.KeyCase)
+	}
+
 	switch bodyType {
 	case bodyTemplate:
-		return NewTemplateBuilder(cfg.Code, cfg.Template), nil
+		b := NewTemplateBuilder(cfg.Code, cfg.Template)
+		b.format = cfg.Format
+		b.contentType = cfg.ContentType
+		b.keyCase = cfg.KeyCase
+		return b, nil
 	case bodyObject:
-		return NewObjectBuilder(&cfg.Object, cfg.Code), nil
+		b := NewObjectBuilder(&cfg.Object, cfg.Code)
+		b.format = cfg.Format
+		b.keyCase = cfg.KeyCase
+		return b, nil
+	case bodyValidationErrors:
+		b := NewValidationErrorsBuilder(cfg.Code)
+		b.format = cfg.Format
+		return b, nil
+	case bodyMultipart:
+		return newMultipartBuilder(cfg)
+	case bodySSE:
+		if cfg.SSE.Events == "" {
+			return nil, errors.New("sse response requires events")
+		}
+		if strings.ContainsAny(cfg.SSE.Event, "\r\n") || cfg.SSE.Retry < 0 {
+			return nil, errors.New("invalid sse response config")
+		}
+		return NewSSEBuilder(cfg.Code, cfg.SSE), nil
+	case bodyCSV:
+		b := NewCSVBuilder(&cfg.Object, cfg.Code)
+		b.filename = cfg.Filename
+		b.keyCase = cfg.KeyCase
+		return b, nil
+	case bodyPassthrough:
+		if cfg.Passthrough.Response == "" {
+			return nil, errors.New("passthrough response requires a response")
+		}
+		return NewPassthroughBuilder(cfg.Code, cfg.Passthrough), nil
 	default:
 		return nil, fmt.Errorf("unknown response body type: %s", bodyType)
 	}
 }
+
+// newMultipartBuilder builds the metadata part from the response object, or
+// the template when there is none, and takes the multipart subtype from the
+// content type.
+func newMultipartBuilder(cfg apiconfig.ResponseConfig) (responses.ResponseBuilder, error) {
+	if cfg.File.Type == "" || cfg.File.Identifier == "" {
+		return nil, errors.New("multipart response requires a file")
+	}
+	switch cfg.ContentType {
+	case "", multipartMixed, multipartRelated:
+	default:
+		return nil, fmt.Errorf("unknown multipart content type: %s", cfg.ContentType)
+	}
+
+	var metadata responses.ResponseBuilder
+	if cfg.Object.Value != "" || len(cfg.Object.Fields) > 0 {
+		b := NewObjectBuilder(&cfg.Object, cfg.Code)
+		b.format = cfg.Format
+		b.keyCase = cfg.KeyCase
+		metadata = b
+	} else {
+		b := NewTemplateBuilder(cfg.Code, cfg.Template)
+		b.format = cfg.Format
+		b.contentType = "application/json"
+		b.keyCase = cfg.KeyCase
+		metadata = b
+	}
+	return NewMultipartBuilder(cfg.Code, metadata, cfg.File, cfg.ContentType), nil
+}
@@ -16,6 +16,9 @@ import (
 type JSONObjectBuilder struct {
 	object *apiconfig.ResponseObject
 	code   int
+	format string
+	// keyCase rewrites the keys of the body, see recaseKeys.
+	keyCase string
 }
 
 func NewObjectBuilder(object *apiconfig.ResponseObject, code int) *JSONObjectBuilder {
@@ -42,26 +45,44 @@ func (o *JSONObjectBuilder) BuildResponse(ctx context.Context) (responses.Result
 	}
 
 	response := &sfhttp.SfResponse{
-		Body: jsonResp,
+		Body: recaseKeys(jsonResp, o.keyCase),
 		Code: o.code,
 	}
+	if encoding := objectEncoding(ctx, o.format); encoding != "" {
+		if response.Body, err = encodeJSON(response.Body, encoding); err != nil {
+			return nil, fmt.Errorf("error encoding response as %s: %w", encoding, err)
+		}
+		response.SetHeader("Content-Type", encodingContentTypes[encoding])
+		return response, nil
+	}
 	response.SetHeader("Content-Type", "application/json")
 
-	return response, nil
+	return formatResponse(ctx, response, o.format), nil
 }
 
 func generateValue(ctx context.Context, object *apiconfig.ResponseObject) (any, error) {
+	return generateObjectValue(ctx, object, false)
+}
+
+// generateObjectValue renders object; omitEmpty is inherited from the objects
+// it is nested in.
+func generateObjectValue(ctx context.Context, object *apiconfig.ResponseObject, omitEmpty bool) (any, error) {
+	omitEmpty = omitEmpty || object.OmitEmpty
 	if len(object.Fields) > 0 {
 		fields := make(map[string]any, len(object.Fields))
 		for i := range object.Fields {
 			f := object.Fields[i]
-			val, err := generateValue(ctx, &f)
+			val, err := generateObjectValue(ctx, &f, omitEmpty)
 			if err != nil {
 				return nil, err
 			}
-			if val != nil {
-				fields[i] = val
+			if val == nil || (omitEmpty && isEmptyValue(val)) {
+				continue
 			}
+			fields[i] = val
+		}
+		if omitEmpty && len(fields) == 0 {
+			return nil, nil
 		}
 		return fields, nil
 	} else {
@@ -69,6 +90,17 @@ func generateValue(ctx context.Context, object *apiconfig.ResponseObject) (any,
 	}
 }
 
+// isEmptyValue reports whether val is dropped from an omitEmpty object.
+func isEmptyValue(val any) bool {
+	switch v := val.(type) {
+	case string:
+		return v == ""
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
+
 func extractValue(ctx context.Context, value string) (any, error) {
 	if value == "" {
 		return nil, nil
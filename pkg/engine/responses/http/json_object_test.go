@@ -437,6 +437,58 @@ func TestObjectBuilder(t *testing.T) {
 			code:      http.StatusOK,
 			expectErr: false,
 		},
+		{
+			name: "omit_empty",
+			in: apiconfig.ResponseObject{
+				Fields: map[string]apiconfig.ResponseObject{
+					"name": {
+						Value: "{{ jsonraw .name }}",
+					},
+					"nickname": {
+						Value: "{{ jsonraw .nickname }}",
+					},
+					"profile": {
+						OmitEmpty: true,
+						Fields: map[string]apiconfig.ResponseObject{
+							"bio": {
+								Value: "{{ jsonraw .nickname }}",
+							},
+							"age": {
+								Value: "{{ jsonraw .age }}",
+							},
+							"links": {
+								Fields: map[string]apiconfig.ResponseObject{
+									"site": {
+										Value: "{{ jsonraw .missing }}",
+									},
+								},
+							},
+						},
+					},
+					"settings": {
+						OmitEmpty: true,
+						Fields: map[string]apiconfig.ResponseObject{
+							"theme": {
+								Value: "{{ jsonraw .nickname }}",
+							},
+						},
+					},
+				},
+			},
+			variables: map[string]interface{}{
+				"name":     "kofo okesola",
+				"nickname": "",
+				"age":      30,
+				"missing":  nil,
+			},
+			compareJson: `{
+				"name": "kofo okesola",
+				"nickname": "",
+				"profile": {"age": 30}
+			}`,
+			code:      http.StatusOK,
+			expectErr: false,
+		},
 		{
 			name: "complex_nested_structure",
 			in: apiconfig.ResponseObject{
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/engine/responses"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	multipartMixed   = "multipart/mixed"
+	multipartRelated = "multipart/related"
+)
+
+// MultipartBuilder renders a file together with JSON metadata in one body:
+// the metadata part first, built like a json_object or template response,
+// then the file part with its detected content type and filename.
+type MultipartBuilder struct {
+	code int
+	// metadata builds the JSON part.
+	metadata responses.ResponseBuilder
+	file     apiconfig.FileInput
+	// mediaType is multipart/mixed or multipart/related.
+	mediaType string
+}
+
+func NewMultipartBuilder(code int, metadata responses.ResponseBuilder, file apiconfig.FileInput, mediaType string) *MultipartBuilder {
+	if mediaType == "" {
+		mediaType = multipartMixed
+	}
+	return &MultipartBuilder{code: code, metadata: metadata, file: file, mediaType: mediaType}
+}
+
+func (m *MultipartBuilder) BuildResponse(ctx context.Context) (responses.Result, error) {
+	logger := logging.FromContext(ctx).With(zap.String("builder_type", bodyMultipart))
+	ctx = logging.WithLogger(ctx, logger)
+	logger.Debug("running multipart response builder", zap.String("file", m.file.Identifier))
+
+	result, err := m.metadata.BuildResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error building multipart metadata: %w", err)
+	}
+	metadata := result.(*sfhttp.SfResponse)
+
+	file, err := requestctx.GetFileFromContext(ctx, m.file)
+	if err != nil {
+		return nil, fmt.Errorf("error getting multipart file %s: %w", m.file.Identifier, err)
+	}
+	if file == nil {
+		return nil, fmt.Errorf("unknown multipart file type: %s", m.file.Type)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("error reading multipart file %s: %w", m.file.Identifier, err)
+	}
+	fileType, err := file.GetMimeType()
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := writePart(w, textproto.MIMEHeader{
+		"Content-Type": {metadata.Headers.Get("Content-Type")},
+	}, metadata.Body); err != nil {
+		return nil, err
+	}
+	if err := writePart(w, textproto.MIMEHeader{
+		"Content-Type":        {fileType},
+		"Content-Disposition": {mime.FormatMediaType("attachment", map[string]string{"filename": file.Name})},
+	}, content); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{"boundary": w.Boundary()}
+	if m.mediaType == multipartRelated {
+		// RFC 2387: type names the root part, here the metadata.
+		params["type"] = "application/json"
+	}
+	response := &sfhttp.SfResponse{
+		Body: body.Bytes(),
+		Code: m.code,
+	}
+	response.SetHeader("Content-Type", mime.FormatMediaType(m.mediaType, params))
+	return response, nil
+}
+
+func writePart(w *multipart.Writer, header textproto.MIMEHeader, content []byte) error {
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("error writing multipart body: %w", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		return fmt.Errorf("error writing multipart body: %w", err)
+	}
+	return nil
+}
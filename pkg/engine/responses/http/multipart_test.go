@@ -0,0 +1,78 @@
+package http
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartBuilder(t *testing.T) {
+	t.Run("related with object metadata", func(t *testing.T) {
+		ctx := requestctx.NewTestContext()
+		rc, err := requestctx.FromContextOrError(ctx)
+		require.NoError(t, err)
+		rc.AddActionFile("render", requestctx.NewFileValue(io.NopCloser(strings.NewReader("<html><body>hi</body></html>")), "page.html"))
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"pages": 1}, ""))
+
+		builder, err := newBuilder(apiconfig.ResponseConfig{
+			Code:        200,
+			Type:        bodyMultipart,
+			ContentType: multipartRelated,
+			Object:      apiconfig.ResponseObject{Fields: map[string]apiconfig.ResponseObject{"page_count": {Value: "{{ .pages }}"}}},
+			KeyCase:     keyCaseCamel,
+			File:        apiconfig.FileInput{Type: apiconfig.FileInputTypeAction, Identifier: "action.render"},
+		})
+		require.NoError(t, err)
+		resp, err := builder.BuildResponse(ctx)
+		require.NoError(t, err)
+
+		sfResp := resp.(*sfhttp.SfResponse)
+		mediaType, params, err := mime.ParseMediaType(sfResp.Headers.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, multipartRelated, mediaType)
+		assert.Equal(t, "application/json", params["type"])
+
+		parts := multipart.NewReader(strings.NewReader(string(sfResp.Body)), params["boundary"])
+		part, err := parts.NextPart()
+		require.NoError(t, err)
+		body, _ := io.ReadAll(part)
+		assert.JSONEq(t, `{"pageCount": 1}`, string(body))
+
+		part, err = parts.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "text/html; charset=utf-8", part.Header.Get("Content-Type"))
+		assert.Equal(t, "page.html", part.FileName())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		builder, err := newBuilder(apiconfig.ResponseConfig{
+			Code: 200,
+			Type: bodyMultipart,
+			File: apiconfig.FileInput{Type: apiconfig.FileInputTypeRequest, Identifier: "upload"},
+		})
+		require.NoError(t, err)
+		_, err = builder.BuildResponse(requestctx.NewTestContext())
+		assert.ErrorIs(t, err, requestctx.ErrFileNotFound)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := newBuilder(apiconfig.ResponseConfig{Code: 200, Type: bodyMultipart})
+		assert.EqualError(t, err, "multipart response requires a file")
+
+		_, err = newBuilder(apiconfig.ResponseConfig{
+			Code:        200,
+			Type:        bodyMultipart,
+			ContentType: "multipart/form-data",
+			File:        apiconfig.FileInput{Type: apiconfig.FileInputTypeRequest, Identifier: "upload"},
+		})
+		assert.EqualError(t, err, "unknown multipart content type: multipart/form-data")
+	})
+}
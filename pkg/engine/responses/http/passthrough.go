@@ -0,0 +1,118 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/responses"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// hopByHopHeaders describe one connection rather than the response, so they
+// are never passed through.
+var hopByHopHeaders = map[string]bool{
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+	"Content-Length":      true,
+}
+
+// PassthroughBuilder writes an upstream response captured by the http action,
+// an object of status, headers and body, back to the client: the status and
+// body unchanged, along with the selected headers.
+type PassthroughBuilder struct {
+	code int
+	cfg  apiconfig.PassthroughConfig
+}
+
+func NewPassthroughBuilder(code int, cfg apiconfig.PassthroughConfig) *PassthroughBuilder {
+	return &PassthroughBuilder{code: code, cfg: cfg}
+}
+
+func (p *PassthroughBuilder) BuildResponse(ctx context.Context) (responses.Result, error) {
+	logger := logging.FromContext(ctx).With(zap.String("builder_type", bodyPassthrough))
+	ctx = logging.WithLogger(ctx, logger)
+
+	val, err := extractValue(ctx, p.cfg.Response)
+	if err != nil {
+		return nil, err
+	}
+	captured, ok := val.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("passthrough response must be a captured http response, got %T", val)
+	}
+
+	response := &sfhttp.SfResponse{Code: p.code}
+	if status, ok := captured["status"]; ok && status != nil {
+		code, ok := status.(float64)
+		if !ok || code != float64(int(code)) || code < 100 || code > 999 {
+			return nil, fmt.Errorf("invalid passthrough status: %v", status)
+		}
+		response.Code = int(code)
+	}
+
+	switch body := captured["body"].(type) {
+	case nil:
+	case string:
+		response.Body = []byte(body)
+	default:
+		// a body already decoded by a template is sent as JSON again
+		if response.Body, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	headers, _ := captured["headers"].(map[string]any)
+	names := p.cfg.Headers
+	if len(names) == 0 {
+		names = []string{"Content-Type"}
+	}
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		if hopByHopHeaders[name] {
+			continue
+		}
+		for _, v := range headerValues(headers, name) {
+			if response.Headers == nil {
+				response.Headers = make(http.Header)
+			}
+			response.Headers.Add(name, v)
+		}
+	}
+
+	logger.Debug("running passthrough response builder", zap.Int("status", response.Code), zap.Int("body_size", len(response.Body)))
+	return response, nil
+}
+
+// headerValues returns the values of the header name, a string or a list of
+// them, matching the name without regard to case.
+func headerValues(headers map[string]any, name string) []string {
+	for k, v := range headers {
+		if http.CanonicalHeaderKey(k) != name {
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			return []string{v}
+		case []any:
+			values := make([]string, 0, len(v))
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				}
+			}
+			return values
+		}
+	}
+	return nil
+}
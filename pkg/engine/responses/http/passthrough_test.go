@@ -0,0 +1,75 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassthroughBuilder(t *testing.T) {
+	build := func(t *testing.T, captured interface{}, headers ...string) (*sfhttp.SfResponse, error) {
+		t.Helper()
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"upstream": captured}, ""))
+		builder, err := newBuilder(apiconfig.ResponseConfig{
+			Code:        http.StatusOK,
+			Type:        bodyPassthrough,
+			Passthrough: apiconfig.PassthroughConfig{Response: "{{ .upstream }}", Headers: headers},
+		})
+		require.NoError(t, err)
+		resp, err := builder.BuildResponse(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return resp.(*sfhttp.SfResponse), nil
+	}
+
+	captured := map[string]interface{}{
+		"status": 503,
+		"headers": map[string]interface{}{
+			"Content-Type":      []interface{}{"application/json; charset=utf-8"},
+			"Retry-After":       []interface{}{"30"},
+			"Transfer-Encoding": []interface{}{"chunked"},
+		},
+		"body": `{"error":  "down",  "n": 1.50}`,
+	}
+
+	t.Run("status and body are unchanged", func(t *testing.T) {
+		resp, err := build(t, captured)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+		assert.Equal(t, `{"error":  "down",  "n": 1.50}`, string(resp.Body))
+		assert.Equal(t, http.Header{"Content-Type": {"application/json; charset=utf-8"}}, resp.Headers)
+	})
+
+	t.Run("selected headers", func(t *testing.T) {
+		resp, err := build(t, captured, "retry-after", "Transfer-Encoding")
+		require.NoError(t, err)
+		assert.Equal(t, http.Header{"Retry-After": {"30"}}, resp.Headers)
+	})
+
+	t.Run("code is used without a status", func(t *testing.T) {
+		resp, err := build(t, map[string]interface{}{"body": "hi"})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "hi", string(resp.Body))
+	})
+
+	t.Run("not a captured response", func(t *testing.T) {
+		_, err := build(t, "plain text")
+		assert.Error(t, err)
+
+		_, err = build(t, map[string]interface{}{"status": 42})
+		assert.Error(t, err)
+	})
+
+	t.Run("requires a response", func(t *testing.T) {
+		_, err := newBuilder(apiconfig.ResponseConfig{Code: http.StatusOK, Type: bodyPassthrough})
+		assert.Error(t, err)
+	})
+}
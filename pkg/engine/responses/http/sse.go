@@ -0,0 +1,143 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/responses"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// LastEventIDHeader is sent by a reconnecting SSE client with the id of the
+// last event it received.
+const LastEventIDHeader = "Last-Event-ID"
+
+// SSEBuilder renders a list as a body of server-sent events, each with an
+// increasing id. When the request carries LastEventIDHeader only the events
+// after that id are written, so a client that reconnects resumes where it
+// left off instead of receiving the events it already has.
+type SSEBuilder struct {
+	code int
+	cfg  apiconfig.SSEConfig
+}
+
+func NewSSEBuilder(code int, cfg apiconfig.SSEConfig) *SSEBuilder {
+	return &SSEBuilder{code: code, cfg: cfg}
+}
+
+func (s *SSEBuilder) BuildResponse(ctx context.Context) (responses.Result, error) {
+	logger := logging.FromContext(ctx).With(zap.String("builder_type", bodySSE))
+	ctx = logging.WithLogger(ctx, logger)
+
+	val, err := extractValue(ctx, s.cfg.Events)
+	if err != nil {
+		return nil, err
+	}
+	var items []any
+	switch v := val.(type) {
+	case nil:
+	case []any:
+		items = v
+	default:
+		return nil, fmt.Errorf("sse events must be a list, got %T", val)
+	}
+
+	lastID, resuming := lastEventID(ctx)
+	logger.Debug("running sse response builder", zap.Int("events", len(items)), zap.Bool("resuming", resuming), zap.Int64("last_event_id", lastID))
+
+	var body bytes.Buffer
+	if s.cfg.Retry > 0 {
+		fmt.Fprintf(&body, "retry: %d\n\n", s.cfg.Retry)
+	}
+	var prevID int64
+	for i, item := range items {
+		id := int64(i + 1)
+		if s.cfg.IDField != "" {
+			if id, err = itemEventID(item, s.cfg.IDField); err != nil {
+				return nil, fmt.Errorf("sse event %d: %w", i, err)
+			}
+		}
+		if i > 0 && id <= prevID {
+			return nil, fmt.Errorf("sse event ids must increase: %d follows %d", id, prevID)
+		}
+		prevID = id
+		if resuming && id <= lastID {
+			continue
+		}
+		if err := writeEvent(&body, id, s.cfg.Event, item); err != nil {
+			return nil, err
+		}
+	}
+
+	response := &sfhttp.SfResponse{
+		Body: body.Bytes(),
+		Code: s.code,
+	}
+	response.SetHeader("Content-Type", "text/event-stream")
+	response.SetHeader("Cache-Control", "no-cache")
+	return response, nil
+}
+
+// lastEventID returns the id in the request's LastEventIDHeader. An absent or
+// malformed id is not a resume, so every event is sent.
+func lastEventID(ctx context.Context) (int64, bool) {
+	header := strings.TrimSpace(requestHeader(ctx, LastEventIDHeader))
+	if header == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(header, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+func itemEventID(item any, field string) (int64, error) {
+	obj, ok := item.(map[string]any)
+	if !ok {
+		return 0, fmt.Errorf("event is %T, want an object with field %s", item, field)
+	}
+	switch v := obj[field].(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), nil
+		}
+	case string:
+		if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return id, nil
+		}
+	case nil:
+		return 0, fmt.Errorf("missing id field %s", field)
+	}
+	return 0, fmt.Errorf("id field %s is not an integer: %v", field, obj[field])
+}
+
+// writeEvent writes one event. Strings are sent as they are and anything else
+// as JSON; each line of the data gets its own data field.
+func writeEvent(body *bytes.Buffer, id int64, event string, item any) error {
+	data, ok := item.(string)
+	if !ok {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		data = string(raw)
+	}
+
+	fmt.Fprintf(body, "id: %d\n", id)
+	if event != "" {
+		fmt.Fprintf(body, "event: %s\n", event)
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		fmt.Fprintf(body, "data: %s\n", line)
+	}
+	body.WriteString("\n")
+	return nil
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sseEvent struct {
+	id    int64
+	event string
+	data  string
+}
+
+func parseSSE(t *testing.T, body string) []sseEvent {
+	t.Helper()
+	var (
+		events []sseEvent
+		cur    sseEvent
+		data   []string
+	)
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			id, err := strconv.ParseInt(value, 10, 64)
+			require.NoError(t, err)
+			cur.id = id
+		case "event":
+			cur.event = value
+		case "data":
+			data = append(data, value)
+		case "":
+			if data != nil {
+				cur.data = strings.Join(data, "\n")
+				events = append(events, cur)
+			}
+			cur, data = sseEvent{}, nil
+		}
+	}
+	return events
+}
+
+func TestSSEBuilder(t *testing.T) {
+	build := func(t *testing.T, cfg apiconfig.SSEConfig, lastEventID string) *sfhttp.SfResponse {
+		t.Helper()
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{
+			"messages": []interface{}{"hello", "multi\nline", map[string]interface{}{"n": 3}},
+			"rows": []interface{}{
+				map[string]interface{}{"seq": 10, "text": "a"},
+				map[string]interface{}{"seq": 20, "text": "b"},
+				map[string]interface{}{"seq": 35, "text": "c"},
+			},
+		}, ""))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(LastEventIDHeader, lastEventID)
+		ctx = requestctx.WithRequest(ctx, req)
+
+		builder, err := newBuilder(apiconfig.ResponseConfig{Code: http.StatusOK, Type: bodySSE, SSE: cfg})
+		require.NoError(t, err)
+		resp, err := builder.BuildResponse(ctx)
+		require.NoError(t, err)
+		return resp.(*sfhttp.SfResponse)
+	}
+
+	t.Run("events get increasing ids", func(t *testing.T) {
+		resp := build(t, apiconfig.SSEConfig{Events: "{{ .messages }}", Event: "chat"}, "")
+		assert.Equal(t, "text/event-stream", resp.Headers.Get("Content-Type"))
+		assert.Equal(t, []sseEvent{
+			{id: 1, event: "chat", data: "hello"},
+			{id: 2, event: "chat", data: "multi\nline"},
+			{id: 3, event: "chat", data: `{"n":3}`},
+		}, parseSSE(t, string(resp.Body)))
+	})
+
+	t.Run("reconnect resumes after last event id", func(t *testing.T) {
+		resp := build(t, apiconfig.SSEConfig{Events: "{{ .messages }}"}, "1")
+		assert.Equal(t, []sseEvent{
+			{id: 2, data: "multi\nline"},
+			{id: 3, data: `{"n":3}`},
+		}, parseSSE(t, string(resp.Body)))
+
+		resp = build(t, apiconfig.SSEConfig{Events: "{{ .messages }}"}, "3")
+		assert.Empty(t, parseSSE(t, string(resp.Body)))
+	})
+
+	t.Run("ids from a field", func(t *testing.T) {
+		resp := build(t, apiconfig.SSEConfig{Events: "{{ .rows }}", IDField: "seq", Retry: 2000}, "20")
+		assert.True(t, strings.HasPrefix(string(resp.Body), "retry: 2000\n\n"))
+		assert.Equal(t, []sseEvent{
+			{id: 35, data: `{"seq":35,"text":"c"}`},
+		}, parseSSE(t, string(resp.Body)))
+	})
+
+	t.Run("malformed last event id sends everything", func(t *testing.T) {
+		resp := build(t, apiconfig.SSEConfig{Events: "{{ .messages }}"}, "abc")
+		assert.Len(t, parseSSE(t, string(resp.Body)), 3)
+	})
+
+	t.Run("ids that do not increase are rejected", func(t *testing.T) {
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{
+			"rows": []interface{}{map[string]interface{}{"seq": 2}, map[string]interface{}{"seq": 2}},
+		}, ""))
+		_, err := NewSSEBuilder(http.StatusOK, apiconfig.SSEConfig{Events: "{{ .rows }}", IDField: "seq"}).BuildResponse(ctx)
+		assert.ErrorContains(t, err, "must increase")
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := newBuilder(apiconfig.ResponseConfig{Code: http.StatusOK, Type: bodySSE})
+		assert.Error(t, err)
+		_, err = newBuilder(apiconfig.ResponseConfig{Code: http.StatusOK, Type: bodySSE, SSE: apiconfig.SSEConfig{Events: "{{ .messages }}", Event: "a\nb"}})
+		assert.Error(t, err)
+	})
+}
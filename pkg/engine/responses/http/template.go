@@ -1,8 +1,12 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 
 	sfhttp "github.com/Servflow/servflow/internal/http"
 	"github.com/Servflow/servflow/pkg/engine/requestctx"
@@ -14,6 +18,11 @@ import (
 type TemplateBuilder struct {
 	Code     int
 	template string
+	format   string
+	// contentType overrides the Content-Type inferred from the body.
+	contentType string
+	// keyCase rewrites the keys of a JSON body, see recaseKeys.
+	keyCase string
 }
 
 func NewTemplateBuilder(code int, template string) *TemplateBuilder {
@@ -36,6 +45,25 @@ func (J *TemplateBuilder) BuildResponse(ctx context.Context) (responses.Result,
 		Body: []byte(tmp),
 		Code: J.Code,
 	}
-	response.SetHeader("Content-Type", "application/json")
-	return response, nil
+	contentType := J.contentType
+	if contentType == "" {
+		contentType = detectContentType(response.Body)
+	}
+	response.SetHeader("Content-Type", contentType)
+	response.Body = recaseKeys(response.Body, J.keyCase)
+	return formatResponse(ctx, response, J.format), nil
+}
+
+// detectContentType infers the Content-Type of a rendered template: JSON when
+// the body parses as JSON, HTML when it looks like markup, otherwise plain
+// text.
+func detectContentType(body []byte) string {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && json.Valid(trimmed) {
+		return "application/json"
+	}
+	if ct := http.DetectContentType(trimmed); strings.HasPrefix(ct, "text/html") {
+		return ct
+	}
+	return "text/plain; charset=utf-8"
 }
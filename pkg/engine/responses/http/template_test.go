@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
 	"github.com/Servflow/servflow/pkg/engine/requestctx"
 
 	"github.com/stretchr/testify/assert"
@@ -74,3 +75,30 @@ func TestJSONResponseBuilder_BuildResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplateBuilder_ContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		template    string
+		contentType string
+		expected    string
+	}{
+		{name: "json object", template: `{"ok": true}`, expected: "application/json"},
+		{name: "json array", template: ` [1, 2] `, expected: "application/json"},
+		{name: "html", template: `<!DOCTYPE html><html><body>hi</body></html>`, expected: "text/html; charset=utf-8"},
+		{name: "html fragment", template: `<p>hi</p>`, expected: "text/html; charset=utf-8"},
+		{name: "plain text", template: `hello`, expected: "text/plain; charset=utf-8"},
+		{name: "broken json", template: `{"ok": }`, expected: "text/plain; charset=utf-8"},
+		{name: "explicit", template: `a,b`, contentType: "text/csv", expected: "text/csv"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			builder, err := newBuilder(apiconfig.ResponseConfig{Code: 200, Type: bodyTemplate, Template: tc.template, ContentType: tc.contentType})
+			require.NoError(t, err)
+
+			result, err := builder.BuildResponse(requestctx.NewTestContext())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result.(*sfhttp.SfResponse).Headers.Get("Content-Type"))
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/Servflow/servflow/pkg/engine/responses"
+	"github.com/Servflow/servflow/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// FieldError is one entry of a validation error response.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorsBuilder renders the errors collected in the error request
+// variable as {"errors": [{"field": ..., "message": ...}]}, the standard shape
+// for form errors. The code defaults to 400.
+type ValidationErrorsBuilder struct {
+	code   int
+	format string
+}
+
+func NewValidationErrorsBuilder(code int) *ValidationErrorsBuilder {
+	if code == 0 {
+		code = http.StatusBadRequest
+	}
+	return &ValidationErrorsBuilder{code: code}
+}
+
+func (v *ValidationErrorsBuilder) BuildResponse(ctx context.Context) (responses.Result, error) {
+	logger := logging.FromContext(ctx).With(zap.String("builder_type", bodyValidationErrors))
+
+	fieldErrors, err := collectFieldErrors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("running validation errors response builder", zap.Int("errors", len(fieldErrors)))
+
+	body, err := json.Marshal(map[string][]FieldError{"errors": fieldErrors})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &sfhttp.SfResponse{
+		Body: body,
+		Code: v.code,
+	}
+	response.SetHeader("Content-Type", "application/json")
+	return formatResponse(ctx, response, v.format), nil
+}
+
+// collectFieldErrors pairs the messages in the error variable with the fields
+// recorded alongside them. A single error string, as left by a failed action,
+// becomes one entry without a field.
+func collectFieldErrors(ctx context.Context) ([]FieldError, error) {
+	val, err := requestctx.GetRequestVariable(ctx, requestctx.ErrorTagStripped)
+	if err != nil {
+		return nil, fmt.Errorf("error reading validation errors: %w", err)
+	}
+
+	fieldErrors := make([]FieldError, 0)
+	switch msgs := val.(type) {
+	case string:
+		if msgs != "" {
+			fieldErrors = append(fieldErrors, FieldError{Message: msgs})
+		}
+	case []string:
+		fieldsVal, _ := requestctx.GetRequestVariable(ctx, requestctx.ErrorFieldsTagStripped)
+		fields, _ := fieldsVal.([]string)
+		for i, msg := range msgs {
+			fe := FieldError{Message: msg}
+			if len(fields) == len(msgs) {
+				fe.Field = fields[i]
+			}
+			fieldErrors = append(fieldErrors, fe)
+		}
+	}
+	return fieldErrors, nil
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	sfhttp "github.com/Servflow/servflow/internal/http"
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationErrorsBuilder(t *testing.T) {
+	t.Run("renders failed validations as field errors", func(t *testing.T) {
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{"email": "nope", "name": ""}, ""))
+		rc, err := requestctx.FromContextOrError(ctx)
+		require.NoError(t, err)
+
+		_, err = rc.Resolve(ctx, `{{ or (email .email "email") (notempty .name "name" "Please tell us your name") }}`)
+		require.NoError(t, err)
+		require.NoError(t, requestctx.AddValidationErrors(ctx))
+
+		builder, err := newBuilder(apiconfig.ResponseConfig{Type: bodyValidationErrors})
+		require.NoError(t, err)
+		resp, err := builder.BuildResponse(ctx)
+		require.NoError(t, err)
+
+		sfResp, ok := resp.(*sfhttp.SfResponse)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, sfResp.Code)
+		assert.Equal(t, "application/json", sfResp.Headers.Get("Content-Type"))
+		assert.JSONEq(t, `{"errors": [
+			{"field": "email", "message": "email is not a valid email address"},
+			{"field": "name", "message": "Please tell us your name"}
+		]}`, string(sfResp.Body))
+	})
+
+	t.Run("action error without field", func(t *testing.T) {
+		ctx := requestctx.NewTestContext()
+		require.NoError(t, requestctx.AddRequestVariables(ctx, map[string]interface{}{requestctx.ErrorTagStripped: "user already exists"}, ""))
+
+		resp, err := NewValidationErrorsBuilder(http.StatusConflict).BuildResponse(ctx)
+		require.NoError(t, err)
+		sfResp := resp.(*sfhttp.SfResponse)
+		assert.Equal(t, http.StatusConflict, sfResp.Code)
+		assert.JSONEq(t, `{"errors": [{"field": "", "message": "user already exists"}]}`, string(sfResp.Body))
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		resp, err := NewValidationErrorsBuilder(0).BuildResponse(requestctx.NewTestContext())
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"errors": []}`, string(resp.(*sfhttp.SfResponse).Body))
+	})
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+)
+
+// conditionPreviewPath serves previews of structured conditions next to the
+// other debug endpoints.
+const conditionPreviewPath = "/debug/conditions/preview"
+
+// ConditionPreviewConfig enables the condition preview endpoint. It is an
+// admin tool: previews evaluate templates with the full function set, so the
+// endpoint is only served when a token is configured.
+type ConditionPreviewConfig struct {
+	// Token must be sent as "Authorization: Bearer <token>".
+	Token string `yaml:"token"`
+}
+
+// conditionPreviewHandler returns the preview endpoint guarded by its token,
+// or nil when it is not enabled.
+func (e *Engine) conditionPreviewHandler() http.Handler {
+	if e.directConfigs == nil || e.directConfigs.EngineConfig == nil {
+		return nil
+	}
+	cfg := e.directConfigs.EngineConfig.ConditionPreview
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Token == "" {
+		e.logger.Warn("condition preview is configured without a token, not serving it")
+		return nil
+	}
+	want := []byte("Bearer " + cfg.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(strings.TrimSpace(r.Header.Get("Authorization")))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		handleConditionPreview(w, r)
+	})
+}
+
+// conditionPreviewRequest is the body posted to conditionPreviewPath:
+// the structure of a conditional step and the variables to evaluate it with.
+type conditionPreviewRequest struct {
+	Structure [][]apiconfig.ConditionItem `json:"structure"`
+	Variables map[string]interface{}      `json:"variables"`
+}
+
+// handleConditionPreview evaluates a structured condition against sample
+// data and returns a plan.ConditionPreview. Malformed bodies and conditions
+// that cannot be compiled or evaluated are reported with a 400.
+func handleConditionPreview(w http.ResponseWriter, r *http.Request) {
+	var req conditionPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writePreviewError(w, "invalid request body: "+err.Error())
+		return
+	}
+
+	preview, err := plan.PreviewCondition(r.Context(), req.Structure, req.Variables)
+	if err != nil {
+		writePreviewError(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(preview)
+}
+
+func writePreviewError(w http.ResponseWriter, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
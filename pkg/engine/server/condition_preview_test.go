@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionPreview(t *testing.T) {
+	runner := NewTestRunner(t, templateRoute("hello", "/hello", "hello")).
+		WithEngineConfig(&EngineConfig{ConditionPreview: &ConditionPreviewConfig{Token: "s3cret"}}).
+		Init()
+
+	preview := func(body string) *http.Request {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, conditionPreviewPath, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		return req
+	}
+	structure := `[[
+		{"content": ".email", "function": "email", "title": "email"},
+		{"content": ".items.0.qty", "comparison": "0.0", "function": "gt"}
+	], [
+		{"content": ".name", "function": "notempty", "title": "name", "message": "Name is required"}
+	]]`
+
+	runner.RunRequests(
+		TestRequest{
+			Name: "passing condition",
+			Request: preview(`{"structure": ` + structure + `, "variables": {
+				"email": "ada@example.com", "items": [{"qty": 2}], "name": ""}}`),
+			WantStatus: http.StatusOK,
+			AssertExtra: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"passed": true, "errors": [], "template": `+
+					`"{{ or (and (email (.email) (\"email\")) (gt (index .items 0 \"qty\") (0.0))) (notempty (.name) (\"name\") (\"Name is required\")) }}"}`,
+					w.Body.String())
+			},
+		},
+		TestRequest{
+			Name: "failing condition lists validation errors",
+			Request: preview(`{"structure": ` + structure + `, "variables": {
+				"email": "not-an-email", "items": [{"qty": 2}], "name": ""}}`),
+			WantStatus: http.StatusOK,
+			AssertExtra: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), `"passed":false`)
+				assert.Contains(t, w.Body.String(), `"errors":["email is not a valid email address","Name is required"]`)
+			},
+		},
+		TestRequest{
+			Name:       "unsupported function",
+			Request:    preview(`{"structure": [[{"content": ".a", "function": "nope"}]], "variables": {}}`),
+			WantStatus: http.StatusBadRequest,
+			WantJSON:   map[string]interface{}{"error": "error generating template for structure[0][0]: unsupported conditional function: nope"},
+		},
+		TestRequest{
+			Name:       "template functions in operands are rejected",
+			Request:    preview(`{"structure": [[{"content": "secret \"db_password\"", "function": "gt", "comparison": "\"m\""}]], "variables": {}}`),
+			WantStatus: http.StatusBadRequest,
+			AssertExtra: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "must be a field path")
+			},
+		},
+		TestRequest{
+			Name:       "pipelines in comparisons are rejected",
+			Request:    preview(`{"structure": [[{"content": ".a", "function": "eq", "comparison": ".a) (env \"HOME\""}]], "variables": {}}`),
+			WantStatus: http.StatusBadRequest,
+			AssertExtra: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), "must be a field path")
+			},
+		},
+		TestRequest{
+			Name: "missing token",
+			Request: httptest.NewRequestWithContext(context.Background(), http.MethodPost, conditionPreviewPath,
+				strings.NewReader(`{"structure": [], "variables": {}}`)),
+			WantStatus: http.StatusUnauthorized,
+		},
+		TestRequest{
+			Name:       "malformed body",
+			Request:    preview(`{"structure": `),
+			WantStatus: http.StatusBadRequest,
+		},
+		TestRequest{
+			Name:       "only POST",
+			Request:    httptest.NewRequestWithContext(context.Background(), http.MethodGet, conditionPreviewPath, nil),
+			WantStatus: http.StatusMethodNotAllowed,
+		},
+	)
+}
+
+func TestConditionPreview_DisabledByDefault(t *testing.T) {
+	runner := NewTestRunner(t, templateRoute("hello", "/hello", "hello")).Init()
+
+	runner.RunRequests(TestRequest{
+		Name: "not served",
+		Request: httptest.NewRequestWithContext(context.Background(), http.MethodPost, conditionPreviewPath,
+			strings.NewReader(`{"structure": [], "variables": {}}`)),
+		WantStatus: http.StatusNotFound,
+	})
+}
@@ -7,6 +7,11 @@ import (
 	"path/filepath"
 
 	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/cache"
+	"github.com/Servflow/servflow/pkg/engine/flags"
+	"github.com/Servflow/servflow/pkg/engine/i18n"
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
 )
@@ -56,6 +61,20 @@ func LoadAPIConfigsFromYAML(apisFolder string, shouldFail bool, logger *zap.Logg
 type engineConfigYAML struct {
 	Integrations map[string]apiconfig.IntegrationConfig `yaml:"integrations"`
 	Cors         CorsConfig                             `yaml:"cors"`
+	Audit        *integration.AuditConfig               `yaml:"audit"`
+	Migrations   []integration.MigrationConfig          `yaml:"migrations"`
+	Cache        *cache.Config                          `yaml:"cache"`
+	Flags        map[string]flags.Flag                  `yaml:"flags"`
+	FlagSource   *integration.FlagSourceConfig          `yaml:"flagSource"`
+	I18n         *i18n.Config                           `yaml:"i18n"`
+	Throttle     *ThrottleConfig                        `yaml:"throttle"`
+	Recording    *RecordingConfig                       `yaml:"recording"`
+	Runtime      *requestctx.RuntimeConfig              `yaml:"runtime"`
+	MaxSteps     int                                    `yaml:"maxSteps"`
+
+	ValidationResponse *apiconfig.ResponseConfig `yaml:"validationResponse"`
+	DefaultIntegration string                    `yaml:"defaultIntegration"`
+	ConditionPreview   *ConditionPreviewConfig   `yaml:"conditionPreview"`
 }
 
 // LoadEngineConfigFromYAML loads engine configuration from a YAML file, returning
@@ -80,7 +99,23 @@ func LoadEngineConfigFromYAML(engineConfigFile string, logger *zap.Logger) (*Eng
 
 	integrations := IntegrationConfigsFromMap(raw.Integrations)
 	logger.Debug("Successfully loaded engine config", zap.Int("integrations_count", len(integrations)))
-	return &EngineConfig{Cors: raw.Cors}, integrations, nil
+	return &EngineConfig{
+		Cors:       raw.Cors,
+		Audit:      raw.Audit,
+		Migrations: raw.Migrations,
+		Cache:      raw.Cache,
+		Flags:      raw.Flags,
+		FlagSource: raw.FlagSource,
+		I18n:       raw.I18n,
+		Throttle:   raw.Throttle,
+		Recording:  raw.Recording,
+		Runtime:    raw.Runtime,
+		MaxSteps:   raw.MaxSteps,
+
+		ValidationResponse: raw.ValidationResponse,
+		DefaultIntegration: raw.DefaultIntegration,
+		ConditionPreview:   raw.ConditionPreview,
+	}, integrations, nil
 }
 
 // IntegrationConfigsFromMap converts an id-keyed integration map into a slice,
@@ -4,8 +4,14 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/cache"
+	"github.com/Servflow/servflow/pkg/engine/flags"
+	"github.com/Servflow/servflow/pkg/engine/i18n"
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -164,6 +170,70 @@ integrations:
 		assert.Equal(t, "sql", db2.Type)
 	})
 
+	t.Run("engine config with optional engine features", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "engine.yaml")
+
+		engineYAML := `
+audit:
+  sink: auditdb
+  collection: writes
+  identity: "{{ .variable_actions_auth.email }}"
+  bufferSize: 50
+migrations:
+  - integration: maindb
+    dir: ./migrations/maindb
+cache:
+  type: redis
+  address: localhost:6379
+flags:
+  new_checkout:
+    enabled: true
+    percentage: 25
+    allow: [alice]
+flagSource:
+  integration: maindb
+i18n:
+  defaultLocale: en
+  dir: ./messages
+throttle:
+  maxConcurrent: 100
+  queueSize: 50
+  queueTimeout: 2s
+recording:
+  file: ./recordings.jsonl
+runtime:
+  metadata:
+    version: 1.4.2
+  env: [REGION]
+maxSteps: 500
+validationResponse:
+  name: invalid
+  code: 400
+  type: validation_errors
+`
+		err := os.WriteFile(tempFile, []byte(engineYAML), 0644)
+		require.NoError(t, err)
+
+		engineConfig, _, err := LoadEngineConfigFromYAML(tempFile, logger)
+		require.NoError(t, err)
+		require.NotNil(t, engineConfig.Audit)
+		assert.Equal(t, "auditdb", engineConfig.Audit.Sink)
+		assert.Equal(t, "writes", engineConfig.Audit.Collection)
+		assert.Equal(t, "{{ .variable_actions_auth.email }}", engineConfig.Audit.Identity)
+		assert.Equal(t, 50, engineConfig.Audit.BufferSize)
+		assert.Equal(t, []integration.MigrationConfig{{Integration: "maindb", Dir: "./migrations/maindb"}}, engineConfig.Migrations)
+		assert.Equal(t, &cache.Config{Type: cache.TypeRedis, Address: "localhost:6379"}, engineConfig.Cache)
+		pct := 25.0
+		assert.Equal(t, map[string]flags.Flag{"new_checkout": {Enabled: true, Percentage: &pct, Allow: []string{"alice"}}}, engineConfig.Flags)
+		assert.Equal(t, &integration.FlagSourceConfig{Integration: "maindb"}, engineConfig.FlagSource)
+		assert.Equal(t, &i18n.Config{DefaultLocale: "en", Dir: "./messages"}, engineConfig.I18n)
+		assert.Equal(t, &ThrottleConfig{MaxConcurrent: 100, QueueSize: 50, QueueTimeout: 2 * time.Second}, engineConfig.Throttle)
+		assert.Equal(t, &RecordingConfig{File: "./recordings.jsonl"}, engineConfig.Recording)
+		assert.Equal(t, &requestctx.RuntimeConfig{Metadata: map[string]string{"version": "1.4.2"}, Env: []string{"REGION"}}, engineConfig.Runtime)
+		assert.Equal(t, 500, engineConfig.MaxSteps)
+		assert.Equal(t, &apiconfig.ResponseConfig{Name: "invalid", Code: 400, Type: "validation_errors"}, engineConfig.ValidationResponse)
+	})
+
 	t.Run("invalid engine config file", func(t *testing.T) {
 		tempFile := filepath.Join(t.TempDir(), "invalid.yaml")
 
@@ -9,29 +9,45 @@ import (
 	"time"
 
 	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/cache"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/agent"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/authenticate"
+	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/count"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/delete_action"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/email"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/fetch"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/fetchvector"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/firestore"
+	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/generateid"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/get_key"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/hash"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/http"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/javascript"
-	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/jwt"
+	"github.com/Servflow/servflow/pkg/engine/actions/executables/jwt"
+	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/mergepatch"
+	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/mongoaggregate"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/mongoquery"
+	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/ndjsonimport"
+	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/paginateupstream"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/parallel"
+	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/polluntil"
+	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/rawquery"
+	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/retry"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/save"
+	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/shortcircuit"
+	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/signurl"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/static"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/store_key"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/storevector"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/stub"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/update"
 	_ "github.com/Servflow/servflow/pkg/engine/actions/executables/write"
+	_ "github.com/Servflow/servflow/pkg/engine/entryhandlers/fieldencryption"
+	_ "github.com/Servflow/servflow/pkg/engine/entryhandlers/webhook"
 	"github.com/Servflow/servflow/pkg/engine/requestctx"
 
+	"github.com/Servflow/servflow/pkg/engine/flags"
+	"github.com/Servflow/servflow/pkg/engine/i18n"
 	"github.com/Servflow/servflow/pkg/engine/integration"
 	_ "github.com/Servflow/servflow/pkg/engine/integration/integrations/claude"
 	_ "github.com/Servflow/servflow/pkg/engine/integration/integrations/mongo"
@@ -52,6 +68,42 @@ import (
 
 type EngineConfig struct {
 	Cors CorsConfig `yaml:"cors"`
+	// Audit, when set, records every integration write to an audit sink.
+	Audit *integration.AuditConfig `yaml:"audit"`
+	// Migrations are applied at Start, in order, to already registered
+	// integrations.
+	Migrations []integration.MigrationConfig `yaml:"migrations"`
+	// Cache configures the shared cache; when unset an in-memory cache is used.
+	Cache *cache.Config `yaml:"cache"`
+	// Flags declares feature flags evaluated by the `flag` template function.
+	// SERVFLOW_FLAG_* environment variables override them.
+	Flags map[string]flags.Flag `yaml:"flags"`
+	// FlagSource, when set, looks up flags not declared in Flags in an
+	// integration.
+	FlagSource *integration.FlagSourceConfig `yaml:"flagSource"`
+	// I18n is the message catalog used by the `t` template function.
+	I18n *i18n.Config `yaml:"i18n"`
+	// Throttle, when set, limits concurrent requests and sheds the excess.
+	Throttle *ThrottleConfig `yaml:"throttle"`
+	// Recording, when set, appends every served request and its response to
+	// a file for replay.
+	Recording *RecordingConfig `yaml:"recording"`
+	// Runtime is the deployment metadata and environment allowlist read by
+	// the `meta` and `env` template functions.
+	Runtime *requestctx.RuntimeConfig `yaml:"runtime"`
+	// MaxSteps caps the steps a single request may execute. 0 uses
+	// plan.DefaultMaxSteps and a negative value disables the cap.
+	MaxSteps int `yaml:"maxSteps"`
+	// ValidationResponse is written when a conditional without an onFalse
+	// fails with validation errors, unless the config sets its own. Defaults
+	// to plan.DefaultValidationResponse.
+	ValidationResponse *apiconfig.ResponseConfig `yaml:"validationResponse"`
+	// DefaultIntegration is the datasource integration used by actions such
+	// as fetch or authenticate that omit integrationID. An explicit id wins.
+	DefaultIntegration string `yaml:"defaultIntegration"`
+	// ConditionPreview, when set, serves the condition preview debug
+	// endpoint to callers presenting its token.
+	ConditionPreview *ConditionPreviewConfig `yaml:"conditionPreview"`
 }
 
 type CorsConfig struct {
@@ -140,12 +192,28 @@ func WithSecretStorage(storage secrets.SecretStorage) Option {
 	}
 }
 
+// WithCache sets the cache shared by engine features, overriding any cache in
+// the engine config. The caller keeps ownership: Stop does not close it.
+func WithCache(c cache.Cache) Option {
+	return func(e *Engine) {
+		e.sharedCache = c
+	}
+}
+
 func WithRequestHook(hook RequestHook) Option {
 	return func(e *Engine) {
 		e.requestHook = hook
 	}
 }
 
+// WithActionHooks installs hooks that run around every action of every
+// config the engine serves.
+func WithActionHooks(hooks plan.ActionHooks) Option {
+	return func(e *Engine) {
+		e.actionHooks = hooks
+	}
+}
+
 // WorkspaceProvider resolves the file capability for a given API config, from
 // the workspace assigned to the agent that owns the config. Returning (nil, nil)
 // means the config has no workspace and its file actions will fail with
@@ -208,7 +276,15 @@ type Engine struct {
 	timerMutex        sync.Mutex
 	tracerShutdown    func(context.Context) error
 	requestHook       RequestHook
+	actionHooks       plan.ActionHooks
 	backgroundManager *plan.BackgroundManager
+	auditor           *integration.Auditor
+	sharedCache       cache.Cache
+	ownsCache         bool
+	// throttle is set by Start before the routing table is published, so
+	// requests that see a table see the throttle too.
+	throttle          *throttler
+	recorder          *recorder
 	workspaceProvider WorkspaceProvider
 	configSpanAttrs   ConfigSpanAttributes
 	initErr           error
@@ -254,6 +330,16 @@ func (e *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "engine not started", http.StatusServiceUnavailable)
 		return
 	}
+	if e.throttle != nil {
+		if !e.throttle.acquire(r) {
+			e.logger.Warn("shedding request, engine at capacity",
+				zap.String("method", r.Method), zap.String("path", r.URL.Path))
+			e.throttle.shed(w)
+			return
+		}
+		// deferred so a panicking handler still gives its slot back
+		defer e.throttle.release()
+	}
 	routes.ServeHTTP(w, r)
 }
 
@@ -271,14 +357,88 @@ func (e *Engine) RegisterIntegrations(configs []apiconfig.IntegrationConfig) err
 	return integration.RegisterIntegrationsFromConfig(e.ctx, configs)
 }
 
-// Start prepares the engine to serve: it compiles the initial routing table and
-// starts lifecycle helpers (background manager, idle timer). It does not bind a
-// listener — serve the engine by passing it to an http.Server as its Handler.
+// Start prepares the engine to serve: it applies pending integration
+// migrations, compiles the initial routing table and starts lifecycle helpers
+// (background manager, idle timer). It does not bind a listener — serve the
+// engine by passing it to an http.Server as its Handler.
 func (e *Engine) Start() error {
 	e.ctx = logging.WithLogger(e.ctx, e.logger)
 
+	if cfg := e.directConfigs.EngineConfig; cfg != nil && len(cfg.Migrations) > 0 {
+		if err := integration.RunMigrations(e.ctx, cfg.Migrations); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+	}
+
+	if e.sharedCache == nil {
+		if cfg := e.directConfigs.EngineConfig; cfg != nil && cfg.Cache != nil {
+			c, err := cache.New(*cfg.Cache)
+			if err != nil {
+				return fmt.Errorf("failed to create cache: %w", err)
+			}
+			e.sharedCache, e.ownsCache = c, true
+		}
+	}
+	if e.sharedCache != nil {
+		cache.SetDefault(e.sharedCache)
+	}
+
+	if cfg := e.directConfigs.EngineConfig; cfg != nil {
+		sources := []flags.Source{flags.NewEnvSource(), flags.Static(cfg.Flags)}
+		if cfg.FlagSource != nil {
+			source, err := integration.NewFlagSource(*cfg.FlagSource)
+			if err != nil {
+				return fmt.Errorf("invalid flag source: %w", err)
+			}
+			sources = append(sources, source)
+		}
+		flags.GetManager().SetSources(sources...)
+	}
+
+	if cfg := e.directConfigs.EngineConfig; cfg != nil && cfg.I18n != nil {
+		catalog, err := i18n.New(*cfg.I18n)
+		if err != nil {
+			return fmt.Errorf("failed to load i18n messages: %w", err)
+		}
+		i18n.SetDefault(catalog)
+	}
+
+	if cfg := e.directConfigs.EngineConfig; cfg != nil && cfg.Runtime != nil {
+		requestctx.SetRuntime(*cfg.Runtime)
+	}
+
+	// set before any plan is compiled, as actions resolve it when built
+	if cfg := e.directConfigs.EngineConfig; cfg != nil {
+		integration.SetDefaultIntegration(cfg.DefaultIntegration)
+	}
+
 	e.backgroundManager = plan.NewBackgroundManager(e.ctx)
 
+	if cfg := e.directConfigs.EngineConfig; cfg != nil && cfg.Audit != nil {
+		auditor, err := integration.NewAuditor(*cfg.Audit)
+		if err != nil {
+			return fmt.Errorf("invalid audit config: %w", err)
+		}
+		e.auditor = auditor
+		integration.SetAuditor(auditor)
+	}
+
+	if cfg := e.directConfigs.EngineConfig; cfg != nil && cfg.Throttle != nil {
+		t, err := newThrottler(*cfg.Throttle)
+		if err != nil {
+			return fmt.Errorf("invalid throttle config: %w", err)
+		}
+		e.throttle = t
+	}
+
+	if cfg := e.directConfigs.EngineConfig; cfg != nil && cfg.Recording != nil {
+		r, err := newRecorder(*cfg.Recording)
+		if err != nil {
+			return fmt.Errorf("invalid recording config: %w", err)
+		}
+		e.recorder = r
+	}
+
 	e.routes.Store(e.createMuxHandler(e.directConfigs.APIConfigs))
 
 	e.initIdleTimer()
@@ -333,10 +493,33 @@ func (e *Engine) Stop() error {
 	// derived from it would expire immediately, skipping the graceful close.
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	// Flush the audit log before its sink integration is shut down.
+	if e.auditor != nil {
+		integration.SetAuditor(nil)
+		if err := e.auditor.Close(shutdownCtx); err != nil {
+			logging.ErrorContext(e.ctx, "failed to flush audit log", err)
+		}
+		e.auditor = nil
+	}
+	if e.recorder != nil {
+		if err := e.recorder.Close(); err != nil {
+			logging.ErrorContext(e.ctx, "failed to close recording file", err)
+		}
+		e.recorder = nil
+	}
 	if err := integration.GetManager().Shutdown(shutdownCtx); err != nil {
 		logging.ErrorContext(e.ctx, "failed to shutdown integrations", err)
 	}
 
+	jwt.ResetJWKS()
+
+	if e.ownsCache {
+		if err := e.sharedCache.Close(); err != nil {
+			logging.ErrorContext(e.ctx, "failed to close cache", err)
+		}
+		e.sharedCache, e.ownsCache = nil, false
+	}
+
 	cl, err := storage.GetClient()
 	if err != nil {
 		return err
@@ -396,6 +579,26 @@ func (e *Engine) resetIdleTimer() {
 	}
 }
 
+func (e *Engine) getMaxSteps() int {
+	if e.directConfigs != nil && e.directConfigs.EngineConfig != nil {
+		return e.directConfigs.EngineConfig.MaxSteps
+	}
+	return 0
+}
+
+// validationResponse returns the validation failure response for config: its
+// own, else the engine's, else plan.DefaultValidationResponse.
+func (e *Engine) validationResponse(config *apiconfig.APIConfig) *apiconfig.ResponseConfig {
+	if config.ValidationResponse != nil {
+		return config.ValidationResponse
+	}
+	if e.directConfigs != nil && e.directConfigs.EngineConfig != nil && e.directConfigs.EngineConfig.ValidationResponse != nil {
+		return e.directConfigs.EngineConfig.ValidationResponse
+	}
+	resp := plan.DefaultValidationResponse
+	return &resp
+}
+
 func (e *Engine) getCorsConfig() *CorsConfig {
 	if e.directConfigs != nil && e.directConfigs.EngineConfig != nil {
 		return &e.directConfigs.EngineConfig.Cors
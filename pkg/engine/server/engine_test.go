@@ -1,12 +1,23 @@
 package server
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/cache"
+	"github.com/Servflow/servflow/pkg/engine/i18n"
+	"github.com/Servflow/servflow/pkg/engine/openapi"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -323,6 +334,304 @@ func TestEngine_ServeHTTP(t *testing.T) {
 	assert.Equal(t, "hello", hello.Body.String())
 }
 
+func TestEngine_TemplateContentType(t *testing.T) {
+	api := stubConfig("page", "/page")
+	api.Responses["ok"] = apiconfig.ResponseConfig{
+		Name:     "ok",
+		Code:     200,
+		Type:     "template",
+		Template: `{{ if eq (param "as") "html" }}<html><body>hi</body></html>{{ else }}{"greeting": "hi"}{{ end }}`,
+	}
+	engine, err := New("test", WithDirectConfigs(&DirectConfigs{
+		APIConfigs:   []*apiconfig.APIConfig{api},
+		EngineConfig: &EngineConfig{},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, engine.Start())
+	defer engine.Stop()
+
+	for path, expected := range map[string]string{
+		"/page":         "application/json",
+		"/page?as=html": "text/html; charset=utf-8",
+	} {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, expected, w.Header().Get("Content-Type"), path)
+	}
+}
+
+func TestEngine_ValidationResponse(t *testing.T) {
+	signupConfig := func(id string) *apiconfig.APIConfig {
+		api := stubConfig(id, "/"+id)
+		api.HttpConfig.Next = "conditional.valid"
+		api.Conditionals = map[string]apiconfig.Conditional{
+			"valid": {Name: "valid", Expression: `{{ email (param "email") "email" }}`, OnTrue: "action.run"},
+		}
+		return api
+	}
+	serve := func(t *testing.T, engineConfig *EngineConfig, apis ...*apiconfig.APIConfig) func(path string) *httptest.ResponseRecorder {
+		engine, err := New("test", WithDirectConfigs(&DirectConfigs{APIConfigs: apis, EngineConfig: engineConfig}))
+		require.NoError(t, err)
+		require.NoError(t, engine.Start())
+		t.Cleanup(func() { engine.Stop() })
+		return func(path string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			return w
+		}
+	}
+
+	t.Run("default lists the errors with a 422", func(t *testing.T) {
+		get := serve(t, &EngineConfig{}, signupConfig("signup"))
+
+		w := get("/signup?email=nope")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"errors": [{"field": "email", "message": "email is not a valid email address"}]}`, w.Body.String())
+
+		w = get("/signup?email=ada@example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "signup", w.Body.String())
+	})
+
+	t.Run("engine and config overrides", func(t *testing.T) {
+		custom := signupConfig("custom")
+		custom.ValidationResponse = &apiconfig.ResponseConfig{Name: "custom", Code: http.StatusBadRequest, Type: "template", Template: `invalid: {{ index .error 0 }}`}
+		get := serve(t, &EngineConfig{ValidationResponse: &apiconfig.ResponseConfig{
+			Name: "engine", Code: http.StatusUnprocessableEntity, Type: "template", Template: `{"message": "check your input"}`,
+		}}, signupConfig("signup"), custom)
+
+		w := get("/signup?email=nope")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.JSONEq(t, `{"message": "check your input"}`, w.Body.String())
+
+		w = get("/custom?email=nope")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid: email is not a valid email address", w.Body.String())
+	})
+}
+
+func TestEngine_MultipartResponse(t *testing.T) {
+	api := stubConfig("upload", "/upload")
+	api.HttpConfig.Method = http.MethodPost
+	api.Responses["ok"] = apiconfig.ResponseConfig{
+		Name:     "ok",
+		Code:     http.StatusCreated,
+		Type:     "multipart",
+		Template: `{"owner": "{{ param "owner" }}"}`,
+		File:     apiconfig.FileInput{Type: apiconfig.FileInputTypeRequest, Identifier: "report"},
+	}
+	engine, err := New("test", WithDirectConfigs(&DirectConfigs{
+		APIConfigs:   []*apiconfig.APIConfig{api},
+		EngineConfig: &EngineConfig{},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, engine.Start())
+	defer engine.Stop()
+
+	var upload bytes.Buffer
+	form := multipart.NewWriter(&upload)
+	fw, err := form.CreateFormFile("report", "report.txt")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte("quarterly numbers"))
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload?owner=ada", &upload)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	mediaType, params, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	parts := multipart.NewReader(w.Body, params["boundary"])
+	metadata, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", metadata.Header.Get("Content-Type"))
+	body, err := io.ReadAll(metadata)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"owner": "ada"}`, string(body))
+
+	file, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", file.Header.Get("Content-Type"))
+	assert.Equal(t, "report.txt", file.FileName())
+	body, err = io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, "quarterly numbers", string(body))
+
+	_, err = parts.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestEngine_JWTAuth(t *testing.T) {
+	api := stubConfig("profile", "/profile")
+	api.HttpConfig.Next = "action.auth"
+	api.Actions["auth"] = apiconfig.Action{
+		Name:   "auth",
+		Type:   "jwtauth",
+		Next:   "response.ok",
+		Fail:   "response.denied",
+		Config: map[string]interface{}{"key": "s3cret", "issuer": "servflow"},
+	}
+	api.Responses["ok"] = apiconfig.ResponseConfig{Name: "ok", Code: http.StatusOK, Type: "template", Template: `{{ .variable_actions_auth.sub }}`}
+	api.Responses["denied"] = apiconfig.ResponseConfig{Name: "denied", Code: http.StatusUnauthorized, Type: "template", Template: "unauthorized"}
+	engine, err := New("test", WithDirectConfigs(&DirectConfigs{
+		APIConfigs:   []*apiconfig.APIConfig{api},
+		EngineConfig: &EngineConfig{},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, engine.Start())
+	defer engine.Stop()
+
+	sign := func(exp time.Time) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "user-42",
+			"iss": "servflow",
+			"exp": exp.Unix(),
+		}).SignedString([]byte("s3cret"))
+		require.NoError(t, err)
+		return token
+	}
+
+	tests := map[string]struct {
+		authorization string
+		code          int
+		body          string
+	}{
+		"valid bearer token": {authorization: "Bearer " + sign(time.Now().Add(time.Hour)), code: http.StatusOK, body: "user-42"},
+		"expired token":      {authorization: "Bearer " + sign(time.Now().Add(-time.Hour)), code: http.StatusUnauthorized, body: "unauthorized"},
+		"missing header":     {code: http.StatusUnauthorized, body: "unauthorized"},
+		"not a bearer token": {authorization: "Basic dXNlcjpwYXNz", code: http.StatusUnauthorized, body: "unauthorized"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+			assert.Equal(t, tc.code, w.Code)
+			assert.Equal(t, tc.body, w.Body.String())
+		})
+	}
+}
+
+func TestEngine_OpenAPI(t *testing.T) {
+	api := stubConfig("user", "/users/{id}")
+	api.Responses["ok"] = apiconfig.ResponseConfig{Name: "ok", Code: 200, Type: "template", Template: `{{ param "verbose" }}`}
+	engine, err := New("test", WithDirectConfigs(&DirectConfigs{
+		APIConfigs:   []*apiconfig.APIConfig{api, stubConfig("hello", "/hello")},
+		EngineConfig: &EngineConfig{},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, engine.Start())
+	defer engine.Stop()
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	require.Contains(t, doc.Paths, "/hello")
+	require.Contains(t, doc.Paths, "/users/{id}")
+	assert.Equal(t, []openapi.Parameter{
+		{Name: "id", In: "path", Required: true, Schema: openapi.Schema{"type": "string"}},
+		{Name: "verbose", In: "query", Schema: openapi.Schema{"type": "string"}},
+	}, (*doc.Paths["/users/{id}"])["get"].Parameters)
+}
+
+func TestEngine_Localization(t *testing.T) {
+	defer i18n.SetDefault(nil)
+
+	api := stubConfig("greet", "/greet")
+	api.Responses["ok"] = apiconfig.ResponseConfig{
+		Name:     "ok",
+		Code:     200,
+		Type:     "template",
+		Template: `{{ t "greeting" }}, {{ t "farewell" }}`,
+	}
+	engine, err := New("test", WithDirectConfigs(&DirectConfigs{
+		APIConfigs: []*apiconfig.APIConfig{api},
+		EngineConfig: &EngineConfig{I18n: &i18n.Config{
+			DefaultLocale: "en",
+			Messages: map[string]map[string]string{
+				"en": {"greeting": "Hello", "farewell": "Goodbye"},
+				"fr": {"greeting": "Bonjour", "farewell": "Au revoir"},
+				"de": {"greeting": "Hallo"},
+			},
+		}},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, engine.Start())
+	defer engine.Stop()
+
+	tests := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{acceptLanguage: "", expected: "Hello, Goodbye"},
+		{acceptLanguage: "fr", expected: "Bonjour, Au revoir"},
+		{acceptLanguage: "fr-CA,fr;q=0.9", expected: "Bonjour, Au revoir"},
+		{acceptLanguage: "es, de;q=0.8, fr;q=0.5", expected: "Hallo, Au revoir"},
+		{acceptLanguage: "ja", expected: "Hello, Goodbye"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/greet", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expected, w.Body.String())
+		})
+	}
+}
+
+func TestEngine_Cache(t *testing.T) {
+	defer cache.SetDefault(nil)
+
+	t.Run("injected cache becomes the shared default", func(t *testing.T) {
+		c := cache.NewMemory()
+		engine, err := New("test", WithCache(c))
+		require.NoError(t, err)
+		require.NoError(t, engine.Start())
+		defer engine.Stop()
+
+		assert.Same(t, c, cache.Default())
+	})
+
+	t.Run("cache from engine config", func(t *testing.T) {
+		engine, err := New("test", WithDirectConfigs(&DirectConfigs{
+			EngineConfig: &EngineConfig{Cache: &cache.Config{Type: cache.TypeMemory}},
+		}))
+		require.NoError(t, err)
+		require.NoError(t, engine.Start())
+		defer engine.Stop()
+
+		assert.Same(t, engine.sharedCache, cache.Default())
+	})
+
+	t.Run("invalid cache config fails start", func(t *testing.T) {
+		engine, err := New("test", WithDirectConfigs(&DirectConfigs{
+			EngineConfig: &EngineConfig{Cache: &cache.Config{Type: "unknown"}},
+		}))
+		require.NoError(t, err)
+		assert.ErrorContains(t, engine.Start(), "failed to create cache")
+	})
+}
+
 // ReloadConfigs must take effect for anyone serving the engine, with no
 // re-wiring: the engine's identity is the stable handler and reload swaps the
 // routing table inside it.
@@ -422,3 +731,88 @@ func TestEngine_GetCorsConfig(t *testing.T) {
 		assert.Empty(t, corsConfig.AllowedOrigins)
 	})
 }
+
+func TestEngine_Timeouts(t *testing.T) {
+	// stalled accepts connections but never answers the TLS handshake
+	stalled, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer stalled.Close()
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, c := range conns {
+				c.Close()
+			}
+		}()
+		for {
+			c, err := stalled.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, c)
+		}
+	}()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	upstreamConfig := func(id, url string, timeouts apiconfig.TimeoutsConfig) *apiconfig.APIConfig {
+		api := stubConfig(id, "/"+id)
+		api.HttpConfig.Timeouts = &timeouts
+		api.Actions["run"] = apiconfig.Action{
+			Name:   "run",
+			Type:   "http",
+			Next:   "response.ok",
+			Config: map[string]interface{}{"url": url, "method": "GET"},
+		}
+		return api
+	}
+	engine, err := New("test", WithDirectConfigs(&DirectConfigs{
+		APIConfigs: []*apiconfig.APIConfig{
+			upstreamConfig("connect", "https://"+stalled.Addr().String(), apiconfig.TimeoutsConfig{Connect: "50ms", Total: "5s"}),
+			upstreamConfig("total", slow.URL, apiconfig.TimeoutsConfig{Connect: "1s", Total: "50ms"}),
+		},
+		EngineConfig: &EngineConfig{},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, engine.Start())
+	defer engine.Stop()
+
+	for path, want := range map[string]int{
+		"/connect": http.StatusRequestTimeout,
+		"/total":   http.StatusGatewayTimeout,
+	} {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want, w.Code, path)
+	}
+}
+
+func TestEngine_RequestNamespaces(t *testing.T) {
+	api := stubConfig("namespaces", "/namespaces/{id}")
+	api.HttpConfig.Method = http.MethodPost
+	api.Responses["ok"] = apiconfig.ResponseConfig{
+		Name:     "ok",
+		Code:     http.StatusOK,
+		Type:     "template",
+		Template: "{{ .query.id }}|{{ .body.id }}|{{ .params.id }}|{{ .action.run.result }}",
+	}
+	engine, err := New("test", WithDirectConfigs(&DirectConfigs{
+		APIConfigs:   []*apiconfig.APIConfig{api},
+		EngineConfig: &EngineConfig{},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, engine.Start())
+	defer engine.Stop()
+
+	req := httptest.NewRequest(http.MethodPost, "/namespaces/7?id=q", strings.NewReader(`{"id": "b"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "q|b|7|ok", w.Body.String())
+}
@@ -2,15 +2,24 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 
 	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
 	"github.com/Servflow/servflow/pkg/engine/entryhandlers"
+	"github.com/Servflow/servflow/pkg/engine/entryhandlers/fieldencryption"
+	"github.com/Servflow/servflow/pkg/engine/entryhandlers/webhook"
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
 	"github.com/Servflow/servflow/pkg/engine/requestctx"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 // baseHTTPConfig returns a minimal HTTP workflow config: a stub action feeding a
@@ -216,3 +225,188 @@ func TestEntryHandler_ConfigTemplatesResolvedByEngine(t *testing.T) {
 
 	assert.Equal(t, "resolved-value", gotValue)
 }
+
+func TestEntryHandler_FieldEncryption(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	config := baseHTTPConfig("fieldenc-cfg", fieldencryption.HandlerType, map[string]interface{}{
+		"key":            base64.StdEncoding.EncodeToString(key),
+		"requestFields":  []interface{}{"ssn"},
+		"responseFields": []interface{}{"ssn"},
+	})
+	config.Responses["ok"] = apiconfig.ResponseConfig{
+		Name:     "ok",
+		Code:     200,
+		Type:     "template",
+		Template: `{"plain":"{{ .decrypted_ssn }}","ssn":"{{ .decrypted_ssn }}"}`,
+	}
+	runner := NewTestRunner(t, config).Init()
+
+	sealed, err := fieldencryption.Encrypt(key, "123-45-6789")
+	require.NoError(t, err)
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/hook",
+		strings.NewReader(`{"ssn":"`+sealed+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	runner.handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	// The flow saw the plaintext, and the designated response field left the
+	// server encrypted again.
+	assert.Equal(t, "123-45-6789", gjson.Get(w.Body.String(), "plain").String())
+	encrypted := gjson.Get(w.Body.String(), "ssn").String()
+	assert.NotEqual(t, "123-45-6789", encrypted)
+	plain, err := fieldencryption.Decrypt(key, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "123-45-6789", plain)
+}
+
+// TestEntryHandler_FieldEncryptionBodyNamespace checks the request inputs are
+// loaded after the entry handler ran, so .body and the raw body hold the
+// decrypted request rather than the ciphertext the client sent.
+func TestEntryHandler_FieldEncryptionBodyNamespace(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	config := baseHTTPConfig("fieldenc-body-cfg", fieldencryption.HandlerType, map[string]interface{}{
+		"key":           base64.StdEncoding.EncodeToString(key),
+		"requestFields": []interface{}{"ssn"},
+	})
+	config.HttpConfig.BufferBody = true
+	config.Actions["greet"] = apiconfig.Action{
+		Name:   "greet",
+		Type:   "javascript",
+		Next:   "response.ok",
+		Config: map[string]interface{}{"script": "function servflowRun(vars, body) { return JSON.parse(body).ssn; }"},
+	}
+	config.Responses["ok"] = apiconfig.ResponseConfig{
+		Name:     "ok",
+		Code:     200,
+		Type:     "template",
+		Template: `{"body":"{{ .body.ssn }}","raw":"{{ .greet }}"}`,
+	}
+	runner := NewTestRunner(t, config).Init()
+
+	sealed, err := fieldencryption.Encrypt(key, "123-45-6789")
+	require.NoError(t, err)
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/hook",
+		strings.NewReader(`{"ssn":"`+sealed+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	runner.handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"body":"123-45-6789","raw":"123-45-6789"}`, w.Body.String())
+}
+
+// eventsIntegration is an in-memory datasource holding recorded webhook
+// events.
+type eventsIntegration struct {
+	mu     sync.Mutex
+	events []map[string]interface{}
+}
+
+func (e *eventsIntegration) Type() string { return "webhook_events" }
+
+func (e *eventsIntegration) Fetch(_ context.Context, _ map[string]string, f ...filters.Filter) ([]map[string]interface{}, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []map[string]interface{}
+	for _, ev := range e.events {
+		if ev["event_id"] == f[0].Comparator {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func (e *eventsIntegration) Store(_ context.Context, item map[string]interface{}, _ map[string]string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, item)
+	return nil
+}
+
+func (e *eventsIntegration) Delete(_ context.Context, _ map[string]string, f ...filters.Filter) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = slices.DeleteFunc(e.events, func(ev map[string]interface{}) bool {
+		return ev["event_id"] == f[0].Comparator
+	})
+	return nil
+}
+
+func TestEntryHandler_Webhook(t *testing.T) {
+	events := &eventsIntegration{}
+	integration.ReplaceIntegrationType("webhook_events", func(map[string]any) (integration.Integration, error) {
+		return events, nil
+	})
+	require.NoError(t, integration.InitializeIntegration("webhook_events", "eventsdb", nil, false))
+
+	config := baseHTTPConfig("webhook-cfg", webhook.HandlerType, map[string]interface{}{
+		"secret":        "s3cret",
+		"integrationID": "eventsdb",
+	})
+	config.Responses["ok"] = apiconfig.ResponseConfig{
+		Name:     "ok",
+		Code:     200,
+		Type:     "template",
+		Template: `{"processed":"{{ .webhook_event_id }}"}`,
+	}
+	// Deliveries of anything but an opened action fail the flow.
+	config.HttpConfig.Next = "conditional.opened"
+	config.Conditionals = map[string]apiconfig.Conditional{
+		"opened": {Name: "opened", Expression: `{{ eq .body.action "opened" }}`, OnTrue: "action.greet", OnFalse: "response.failed"},
+	}
+	config.Responses["failed"] = apiconfig.ResponseConfig{
+		Name:     "failed",
+		Code:     500,
+		Type:     "template",
+		Template: `{"error":"failed"}`,
+	}
+	runner := NewTestRunner(t, config).Init()
+
+	deliverBody := func(eventID, signature, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/hook",
+			strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Event-ID", eventID)
+		req.Header.Set("X-Signature", signature)
+		w := httptest.NewRecorder()
+		runner.handler.ServeHTTP(w, req)
+		return w
+	}
+	deliver := func(eventID, signature string) *httptest.ResponseRecorder {
+		return deliverBody(eventID, signature, `{"action":"opened"}`)
+	}
+	signature := "sha256=" + webhook.Sign([]byte("s3cret"), []byte(`{"action":"opened"}`))
+
+	t.Run("first delivery is processed", func(t *testing.T) {
+		w := deliver("evt-1", signature)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.JSONEq(t, `{"processed":"evt-1"}`, w.Body.String())
+		assert.Len(t, events.events, 1)
+	})
+
+	t.Run("duplicate is acknowledged and not reprocessed", func(t *testing.T) {
+		w := deliver("evt-1", signature)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"duplicate"}`, w.Body.String())
+		assert.Len(t, events.events, 1)
+	})
+
+	t.Run("invalid signature is rejected", func(t *testing.T) {
+		w := deliver("evt-2", webhook.Sign([]byte("wrong"), []byte(`{"action":"opened"}`)))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Len(t, events.events, 1)
+	})
+
+	t.Run("failed delivery is released for its retry", func(t *testing.T) {
+		closed := `{"action":"closed"}`
+		w := deliverBody("evt-3", webhook.Sign([]byte("s3cret"), []byte(closed)), closed)
+		require.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+		assert.Len(t, events.events, 1)
+
+		w = deliver("evt-3", signature)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.JSONEq(t, `{"processed":"evt-3"}`, w.Body.String())
+		assert.Len(t, events.events, 2)
+	})
+}
@@ -15,6 +15,7 @@ import (
 	sfhttp "github.com/Servflow/servflow/internal/http"
 	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
 	"github.com/Servflow/servflow/pkg/engine/entryhandlers"
+	"github.com/Servflow/servflow/pkg/engine/i18n"
 	"github.com/Servflow/servflow/pkg/engine/plan"
 	"github.com/Servflow/servflow/pkg/engine/requestctx"
 	"github.com/Servflow/servflow/pkg/logging"
@@ -31,7 +32,18 @@ import (
 
 // NewAPIHandlerForConfig takes an apiconfig and a logger and returns an APIHandler with the appropriate
 // actions and datasource managers
-func (e *Engine) createBasicHandler(config *apiconfig.APIConfig) (http.Handler, error) {
+func (e *Engine) createBasicHandler(config *apiconfig.APIConfig, shadow *shadowTarget) (http.Handler, error) {
+	a, err := e.newAPIHandler(config)
+	if err != nil {
+		return nil, err
+	}
+	a.shadow = shadow
+	a.recorder = e.recorder
+	return a.CreateChain(config, e.getCorsConfig()), nil
+}
+
+// newAPIHandler plans config and returns the handler that serves it.
+func (e *Engine) newAPIHandler(config *apiconfig.APIConfig) (*APIHandler, error) {
 	logger := logging.FromContext(e.ctx)
 	logger.Debug("Loading API configuration", zap.String("api", config.ID), zap.String("path", config.HttpConfig.ListenPath), zap.String("method", config.HttpConfig.Method))
 
@@ -41,11 +53,18 @@ func (e *Engine) createBasicHandler(config *apiconfig.APIConfig) (http.Handler,
 	}
 
 	planner := plan.NewPlannerV2(plan.PlannerConfig{
+		ID:           config.ID,
 		Actions:      config.Actions,
 		Conditions:   config.Conditionals,
 		Responses:    config.Responses,
+		Joins:        config.Joins,
 		Integrations: config.Integrations,
 		Workspace:    ws,
+		MaxSteps:     e.getMaxSteps(),
+
+		ValidationResponse: e.validationResponse(config),
+		DefaultFail:        config.DefaultFail,
+		Hooks:              e.actionHooks,
 	}, logger)
 	p, err := planner.Plan()
 	if err != nil {
@@ -54,6 +73,11 @@ func (e *Engine) createBasicHandler(config *apiconfig.APIConfig) (http.Handler,
 
 	logger.Debug("Starting plan generation from", zap.String("start", config.HttpConfig.Next))
 
+	connectTimeout, totalTimeout, err := parseTimeouts(config.HttpConfig.Timeouts)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", config.ID, err)
+	}
+
 	a := &APIHandler{
 		apiPath:       config.HttpConfig.ListenPath,
 		apiName:       config.Name,
@@ -63,6 +87,11 @@ func (e *Engine) createBasicHandler(config *apiconfig.APIConfig) (http.Handler,
 		handlerType:   config.HttpConfig.Handler,
 		handlerConfig: config.HttpConfig.HandlerConfig,
 		baseLogger:    e.logger,
+
+		connectTimeout: connectTimeout,
+		totalTimeout:   totalTimeout,
+		bufferBody:     config.HttpConfig.BufferBody,
+		maxBodySize:    config.HttpConfig.MaxBodySize,
 	}
 
 	if e.configSpanAttrs != nil {
@@ -71,7 +100,7 @@ func (e *Engine) createBasicHandler(config *apiconfig.APIConfig) (http.Handler,
 		}
 	}
 
-	return a.CreateChain(config, e.getCorsConfig()), nil
+	return a, nil
 }
 
 type APIHandler struct {
@@ -93,6 +122,55 @@ type APIHandler struct {
 	// resolved when this handler was built and applied to every span of each
 	// request via requestctx.Start.
 	spanAttrs []attribute.KeyValue
+	// shadow, when set, receives a copy of every sampled request once this
+	// handler has responded (see shadow.go).
+	shadow *shadowTarget
+	// recorder, when set, records every request and its response (see
+	// recording.go).
+	recorder *recorder
+	// connectTimeout and totalTimeout are the endpoint's timeouts, zero when
+	// unset (see apiconfig.TimeoutsConfig).
+	connectTimeout time.Duration
+	totalTimeout   time.Duration
+	// bufferBody keeps the request body for GetRawBody, capped at
+	// maxBodySize (see requestctx.BufferRawBody).
+	bufferBody  bool
+	maxBodySize int64
+}
+
+func parseTimeouts(cfg *apiconfig.TimeoutsConfig) (connect, total time.Duration, err error) {
+	if cfg == nil {
+		return 0, 0, nil
+	}
+	parse := func(field, v string) (time.Duration, error) {
+		if v == "" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return 0, fmt.Errorf("invalid timeouts.%s %q: must be a positive duration", field, v)
+		}
+		return d, nil
+	}
+	if connect, err = parse("connect", cfg.Connect); err != nil {
+		return 0, 0, err
+	}
+	if total, err = parse("total", cfg.Total); err != nil {
+		return 0, 0, err
+	}
+	return connect, total, nil
+}
+
+// timeoutStatus returns the status answering a flow that failed with err
+// under ctx because of a timeout, or 0 for other failures.
+func timeoutStatus(ctx context.Context, err error) int {
+	switch {
+	case errors.Is(err, plan.ErrConnectTimeout):
+		return http.StatusRequestTimeout
+	case errors.Is(err, plan.ErrTimeout), errors.Is(context.Cause(ctx), plan.ErrTimeout):
+		return http.StatusGatewayTimeout
+	}
+	return 0
 }
 
 const mcpServerVersion = "0.1.0"
@@ -132,6 +210,10 @@ func requestTemplateFunctions(req *http.Request) template.FuncMap {
 			r := vars[key]
 			return r
 		},
+		"t": func(key string, args ...interface{}) string {
+			locales := i18n.ParseAcceptLanguage(req.Header.Get("Accept-Language"))
+			return i18n.Default().Translate(locales, key, args...)
+		},
 	}
 }
 
@@ -178,6 +260,25 @@ func (h *APIHandler) ServeHTTP(wr http.ResponseWriter, req *http.Request) {
 	if h.baseLogger == nil {
 		h.baseLogger = zap.NewNop()
 	}
+	if h.recorder != nil {
+		if body, ok := peekBody(req, maxRecordedBodySize); ok {
+			tee := newResponseTee(wr, maxRecordedBodySize)
+			wr = tee
+			defer func() {
+				if err := h.recorder.record(h.apiID, req, body, tee); err != nil {
+					h.baseLogger.Warn("failed to record request", zap.Error(err), zap.String("api", h.apiID))
+				}
+			}()
+		}
+	}
+	if h.shadow != nil {
+		if mirror := h.shadow.mirror(req); mirror != nil {
+			tee := newResponseTee(wr, maxShadowBodySize)
+			wr = tee
+			// deferred first so it runs last, once the response is complete
+			defer h.shadow.dispatch(req.Context(), mirror, tee, h.baseLogger.With(zap.String("api", h.apiID)))
+		}
+	}
 	ctx, rectx := requestctx.Start(req.Context(), requestctx.Options{
 		Logger: h.baseLogger.With(
 			zap.String("method", req.Method), zap.String("path", req.URL.Path)),
@@ -217,9 +318,40 @@ func (h *APIHandler) ServeHTTP(wr http.ResponseWriter, req *http.Request) {
 	// the terminal handler that any entry-handler middleware wraps.
 	planRunner := http.HandlerFunc(func(wr http.ResponseWriter, req *http.Request) {
 		ctx := req.Context()
+		// The body is read only now: entry handlers may replace it, e.g.
+		// field_encryption decrypts fields, and the flow must see their body.
+		if h.bufferBody {
+			if err := requestctx.BufferRawBody(ctx, req, h.maxBodySize); err != nil {
+				code := http.StatusBadRequest
+				if errors.Is(err, requestctx.ErrBodyTooLarge) {
+					code = http.StatusRequestEntityTooLarge
+				}
+				logger.Warn("rejecting request body", zap.Int("status", code), zap.Error(err))
+				tracing.SetHTTPStatus(span, code, err)
+				http.Error(wr, http.StatusText(code), code)
+				return
+			}
+		}
+		if err := requestctx.LoadRequestInputs(ctx, req, mux.Vars(req)); err != nil {
+			logger.Error("Error loading request inputs", zap.Error(err))
+		}
+		if h.connectTimeout > 0 {
+			ctx = plan.WithConnectTimeout(ctx, h.connectTimeout)
+		}
+		if h.totalTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeoutCause(ctx, h.totalTimeout, plan.ErrTimeout)
+			defer cancel()
+		}
 		result, err := h.p.Execute(ctx, h.planStart)
 		resp, ok := result.(*sfhttp.SfResponse)
 		if err != nil || !ok || resp == nil {
+			if code := timeoutStatus(ctx, err); code != 0 {
+				logger.Warn("request timed out", zap.Int("status", code), zap.Error(err))
+				tracing.SetHTTPStatus(span, code, err)
+				http.Error(wr, http.StatusText(code), code)
+				return
+			}
 			tracing.SetHTTPStatus(span, http.StatusInternalServerError, err)
 			switch {
 			case err != nil:
@@ -236,8 +368,8 @@ func (h *APIHandler) ServeHTTP(wr http.ResponseWriter, req *http.Request) {
 		}
 
 		tracing.SetHTTPStatus(span, resp.Code, nil)
-		for key := range resp.Headers {
-			wr.Header().Set(key, resp.Headers.Get(key))
+		for key, values := range resp.Headers {
+			wr.Header()[key] = values
 		}
 		wr.WriteHeader(resp.Code)
 		wr.Write(resp.Body)
@@ -2,11 +2,13 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -179,3 +181,60 @@ func TestRequestContext_BodySizeLimit(t *testing.T) {
 		t.Errorf("expected empty string for oversized body, got %q", result)
 	}
 }
+
+func TestAPIHandler_RawBodySharedByActions(t *testing.T) {
+	echo := "function servflowRun(vars, body) { return body; }"
+	config := &apiconfig.APIConfig{
+		ID: "rawbody-cfg",
+		HttpConfig: apiconfig.HttpConfig{
+			ListenPath:  "/ingest",
+			Method:      "POST",
+			Next:        "action.first",
+			BufferBody:  true,
+			MaxBodySize: 64,
+		},
+		Actions: map[string]apiconfig.Action{
+			"first":  {Name: "first", Type: "javascript", Next: "action.second", Config: map[string]interface{}{"script": echo}},
+			"second": {Name: "second", Type: "javascript", Next: "response.ok", Config: map[string]interface{}{"script": echo}},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"ok": {Name: "ok", Code: 200, Type: "template", Template: "{{ .variable_actions_first }}|{{ .variable_actions_second }}"},
+		},
+	}
+	runner := NewTestRunner(t, config).Init()
+
+	t.Run("every action reads the body", func(t *testing.T) {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/ingest", strings.NewReader("a,b,c"))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		runner.handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "a,b,c|a,b,c", w.Body.String())
+	})
+
+	t.Run("body over the limit is rejected", func(t *testing.T) {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/ingest", strings.NewReader(strings.Repeat("a", 65)))
+		w := httptest.NewRecorder()
+		runner.handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("body is not buffered or capped unless enabled", func(t *testing.T) {
+		streamed := *config
+		streamed.ID = "rawbody-streamed-cfg"
+		streamed.HttpConfig.ListenPath = "/stream"
+		streamed.HttpConfig.BufferBody = false
+		runner := NewTestRunner(t, &streamed).Init()
+
+		body := strings.Repeat("a", 65)
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/stream", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		runner.handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		// javascript falls back to reading and restoring req.Body
+		assert.Equal(t, body+"|"+body, w.Body.String())
+	})
+}
@@ -27,9 +27,14 @@ func (e *Engine) createMCPHandler(config *apiconfig.APIConfig) error {
 
 	//generate plan
 	planner := plan.NewPlannerV2(plan.PlannerConfig{
-		Actions:    config.Actions,
-		Conditions: config.Conditionals,
-		Workspace:  ws,
+		ID:          config.ID,
+		Actions:     config.Actions,
+		Conditions:  config.Conditionals,
+		Joins:       config.Joins,
+		Workspace:   ws,
+		MaxSteps:    e.getMaxSteps(),
+		DefaultFail: config.DefaultFail,
+		Hooks:       e.actionHooks,
 	}, logger)
 
 	p, err := planner.Plan()
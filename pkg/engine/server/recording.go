@@ -0,0 +1,195 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// maxRecordedBodySize caps the request and response bodies kept per
+// recording. Requests with larger bodies are not recorded.
+const maxRecordedBodySize = 1 << 20
+
+// RecordingConfig enables recording mode: every request served by an API
+// config, and the response it produced, is appended to File as one JSON
+// line. The file can be replayed with Replay to regression-test configs.
+// Recordings hold full headers and bodies, credentials included, so record
+// against test data only.
+type RecordingConfig struct {
+	File string `yaml:"file"`
+}
+
+// Recording is one recorded request/response pair.
+type Recording struct {
+	// Config is the id of the API config that served the request.
+	Config   string           `json:"config"`
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// recorder appends recordings to a file. It is shared by every API handler
+// of an engine.
+type recorder struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+func newRecorder(cfg RecordingConfig) (*recorder, error) {
+	if cfg.File == "" {
+		return nil, errors.New("recording requires a file")
+	}
+	f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording file: %w", err)
+	}
+	return &recorder{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (r *recorder) record(configID string, req *http.Request, body []byte, resp *responseTee) error {
+	rec := Recording{
+		Config: configID,
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Header: req.Header.Clone(),
+			Body:   string(body),
+		},
+		Response: RecordedResponse{
+			Status: resp.status(),
+			Header: resp.Header().Clone(),
+			Body:   resp.body.String(),
+		},
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(rec)
+}
+
+func (r *recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// LoadRecordings reads a file written in recording mode.
+func LoadRecordings(path string) ([]Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recordings: %w", err)
+	}
+	defer f.Close()
+
+	var recordings []Recording
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*maxRecordedBodySize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var rec Recording
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("invalid recording on line %d: %w", line, err)
+		}
+		recordings = append(recordings, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recordings: %w", err)
+	}
+	return recordings, nil
+}
+
+// ReplayMismatch describes a replayed request whose response differs from
+// the recorded one.
+type ReplayMismatch struct {
+	// Index is the position of the recording in the replayed list.
+	Index     int
+	Recording Recording
+	Status    int
+	Body      string
+}
+
+func (m ReplayMismatch) String() string {
+	return fmt.Sprintf("#%d %s %s: expected %d %s, got %d %s", m.Index, m.Recording.Request.Method, m.Recording.Request.URL,
+		m.Recording.Response.Status, m.Recording.Response.Body, m.Status, m.Body)
+}
+
+// Replay sends each recorded request to h and returns the responses that do
+// not match what was recorded. Statuses must be equal; bodies are compared
+// as JSON when both are JSON, after removing ignoreFields from both sides.
+// ignoreFields are gjson paths such as "id", "user.createdAt" or
+// "items.#.id", for values like timestamps and generated ids that differ on
+// every run.
+func Replay(h http.Handler, recordings []Recording, ignoreFields []string) []ReplayMismatch {
+	var mismatches []ReplayMismatch
+	for i, rec := range recordings {
+		req := httptest.NewRequest(rec.Request.Method, rec.Request.URL, strings.NewReader(rec.Request.Body))
+		for k, v := range rec.Request.Header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		got := w.Body.String()
+		if w.Code != rec.Response.Status ||
+			!sameBody([]byte(withoutFields(rec.Response.Body, ignoreFields)), []byte(withoutFields(got, ignoreFields))) {
+			mismatches = append(mismatches, ReplayMismatch{Index: i, Recording: rec, Status: w.Code, Body: got})
+		}
+	}
+	return mismatches
+}
+
+// withoutFields deletes the gjson paths in fields from a JSON body. Wildcard
+// array paths ("items.#.id") are expanded to each element. Non-JSON bodies
+// are returned unchanged.
+func withoutFields(body string, fields []string) string {
+	if len(fields) == 0 || !gjson.Valid(body) {
+		return body
+	}
+	for _, field := range fields {
+		for _, path := range expandPath(body, field) {
+			if out, err := sjson.Delete(body, path); err == nil {
+				body = out
+			}
+		}
+	}
+	return body
+}
+
+// expandPath turns a path with "#" array wildcards into the concrete paths
+// present in body, deepest index first so deleting one never shifts another.
+func expandPath(body, path string) []string {
+	head, rest, found := strings.Cut(path, ".#.")
+	if !found {
+		return []string{path}
+	}
+	n := int(gjson.Get(body, head+".#").Int())
+	var paths []string
+	for i := n - 1; i >= 0; i-- {
+		paths = append(paths, expandPath(body, fmt.Sprintf("%s.%d.%s", head, i, rest))...)
+	}
+	return paths
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usersIntegration is a stubbed datasource whose rows carry a fresh id and
+// timestamp on every fetch, like a real database insert would.
+type usersIntegration struct {
+	seq atomic.Int64
+}
+
+func (u *usersIntegration) Type() string { return "users" }
+
+func (u *usersIntegration) Fetch(_ context.Context, _ map[string]string, _ ...filters.Filter) ([]map[string]interface{}, error) {
+	return []map[string]interface{}{
+		{"id": fmt.Sprintf("u-%d", u.seq.Add(1)), "name": "ada", "fetchedAt": time.Now().Format(time.RFC3339Nano)},
+	}, nil
+}
+
+func usersConfig() *apiconfig.APIConfig {
+	return &apiconfig.APIConfig{
+		ID: "list-users",
+		HttpConfig: apiconfig.HttpConfig{
+			ListenPath: "/users",
+			Method:     http.MethodPost,
+			Next:       "action.list",
+		},
+		Actions: map[string]apiconfig.Action{
+			"list": {
+				Name:   "list",
+				Type:   "fetch",
+				Next:   "response.ok",
+				Config: map[string]interface{}{"integrationID": "usersdb", "table": "users"},
+			},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"ok": {
+				Name:     "ok",
+				Code:     200,
+				Type:     "template",
+				Template: `{"query": "{{ body "q" }}", "users": {{ jsonout .variable_actions_list }}}`,
+			},
+		},
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	integration.ReplaceIntegrationType("users", func(map[string]any) (integration.Integration, error) {
+		return &usersIntegration{}, nil
+	})
+	require.NoError(t, integration.InitializeIntegration("users", "usersdb", nil, false))
+
+	file := filepath.Join(t.TempDir(), "recordings.jsonl")
+	recording, err := New("test", WithDirectConfigs(&DirectConfigs{
+		APIConfigs:   []*apiconfig.APIConfig{usersConfig()},
+		EngineConfig: &EngineConfig{Recording: &RecordingConfig{File: file}},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, recording.Start())
+
+	for _, q := range []string{"first", "second"} {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"q": "`+q+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		recording.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	require.NoError(t, recording.Stop())
+
+	recs, err := LoadRecordings(file)
+	require.NoError(t, err)
+	require.Len(t, recs, 2)
+	assert.Equal(t, "list-users", recs[0].Config)
+	assert.Equal(t, http.MethodPost, recs[0].Request.Method)
+	assert.Equal(t, "/users", recs[0].Request.URL)
+	assert.JSONEq(t, `{"q": "first"}`, recs[0].Request.Body)
+	assert.Equal(t, http.StatusOK, recs[0].Response.Status)
+	assert.Contains(t, recs[0].Response.Body, `"query": "first"`)
+
+	require.NoError(t, integration.InitializeIntegration("users", "usersdb", nil, false))
+	replay, _ := shadowEngine(t, usersConfig())
+
+	t.Run("generated fields make responses differ", func(t *testing.T) {
+		mismatches := Replay(replay, recs, nil)
+		assert.Len(t, mismatches, 2)
+	})
+
+	t.Run("ignored fields are not compared", func(t *testing.T) {
+		mismatches := Replay(replay, recs, []string{"users.#.id", "users.#.fetchedAt"})
+		assert.Empty(t, mismatches)
+	})
+
+	t.Run("a changed config is reported", func(t *testing.T) {
+		changed := usersConfig()
+		changed.Responses["ok"] = apiconfig.ResponseConfig{Name: "ok", Code: 201, Type: "template", Template: `{"users": []}`}
+		engine, _ := shadowEngine(t, changed)
+
+		mismatches := Replay(engine, recs, []string{"users.#.id", "users.#.fetchedAt"})
+		require.Len(t, mismatches, 2)
+		assert.Equal(t, 1, mismatches[1].Index)
+		assert.Equal(t, http.StatusCreated, mismatches[1].Status)
+		assert.Contains(t, mismatches[1].String(), "expected 200")
+	})
+}
+
+func TestWithoutFields(t *testing.T) {
+	body := `{"id": 1, "items": [{"id": "a", "n": 1}, {"id": "b", "n": 2}], "meta": {"at": "now", "v": 1}}`
+	assert.JSONEq(t, `{"items": [{"n": 1}, {"n": 2}], "meta": {"v": 1}}`,
+		withoutFields(body, []string{"id", "items.#.id", "meta.at"}))
+	assert.Equal(t, "plain text", withoutFields("plain text", []string{"id"}))
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Segment kinds in ascending order of specificity. When several routes could
+// match the same request, the one with the more specific segment at the first
+// position where they differ wins: static beats a {param}, which beats a
+// trailing wildcard.
+const (
+	segmentWildcard = iota
+	segmentParam
+	segmentStatic
+)
+
+// routePattern converts a ListenPath into a gorilla/mux path template. A
+// trailing "*name" segment is a catch-all that captures the rest of the path,
+// slashes included, into the url param "name": "/files/*path" serves
+// "/files/a/b/c" with urlparam "path" set to "a/b/c". specificity holds the
+// kind of every segment and is used to order overlapping routes.
+func routePattern(listenPath string) (pattern string, specificity []int, err error) {
+	segments := strings.Split(strings.Trim(listenPath, "/"), "/")
+	specificity = make([]int, len(segments))
+	for i, seg := range segments {
+		switch {
+		case strings.HasPrefix(seg, "*"):
+			name := strings.TrimPrefix(seg, "*")
+			if i != len(segments)-1 {
+				return "", nil, fmt.Errorf("wildcard segment %q must be the last segment of %q", seg, listenPath)
+			}
+			if name == "" {
+				return "", nil, fmt.Errorf("wildcard segment in %q must be named, e.g. *path", listenPath)
+			}
+			segments[i] = "{" + name + ":.*}"
+			specificity[i] = segmentWildcard
+		case strings.HasPrefix(seg, "{"):
+			specificity[i] = segmentParam
+		default:
+			specificity[i] = segmentStatic
+		}
+	}
+	return "/" + strings.Join(segments, "/"), specificity, nil
+}
+
+// moreSpecific reports whether a route with specificity a must be tried
+// before one with specificity b. Routes are compared segment by segment; at
+// the first differing segment the more specific kind wins. If one route is a
+// prefix of the other, the longer one goes first so a shorter trailing
+// wildcard never shadows it.
+func moreSpecific(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return len(a) > len(b)
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutePattern(t *testing.T) {
+	tests := []struct {
+		name            string
+		listenPath      string
+		wantPattern     string
+		wantSpecificity []int
+		wantErr         bool
+	}{
+		{name: "static path", listenPath: "/users/me", wantPattern: "/users/me", wantSpecificity: []int{segmentStatic, segmentStatic}},
+		{name: "param path", listenPath: "/users/{id}", wantPattern: "/users/{id}", wantSpecificity: []int{segmentStatic, segmentParam}},
+		{name: "trailing wildcard", listenPath: "/files/*path", wantPattern: "/files/{path:.*}", wantSpecificity: []int{segmentStatic, segmentWildcard}},
+		{name: "root wildcard", listenPath: "/*rest", wantPattern: "/{rest:.*}", wantSpecificity: []int{segmentWildcard}},
+		{name: "wildcard not last", listenPath: "/files/*path/meta", wantErr: true},
+		{name: "unnamed wildcard", listenPath: "/files/*", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, specificity, err := routePattern(tt.listenPath)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPattern, pattern)
+			assert.Equal(t, tt.wantSpecificity, specificity)
+		})
+	}
+}
+
+func TestMoreSpecific(t *testing.T) {
+	paths := []string{"/*rest", "/users/{id}", "/users/*path", "/users/me", "/users/{id}/posts", "/users/me/posts"}
+	specs := make(map[string][]int, len(paths))
+	for _, p := range paths {
+		_, s, err := routePattern(p)
+		require.NoError(t, err)
+		specs[p] = s
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		return moreSpecific(specs[paths[i]], specs[paths[j]])
+	})
+	assert.Equal(t, []string{
+		"/users/me/posts",
+		"/users/me",
+		"/users/{id}/posts",
+		"/users/{id}",
+		"/users/*path",
+		"/*rest",
+	}, paths)
+}
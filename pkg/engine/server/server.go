@@ -1,11 +1,14 @@
 package server
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/pprof"
+	"sort"
 	"strings"
 
 	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/openapi"
 	"github.com/Servflow/servflow/pkg/engine/plan"
 	"github.com/Servflow/servflow/pkg/logging"
 	"github.com/mark3labs/mcp-go/server"
@@ -34,6 +37,28 @@ func (e *Engine) createMuxHandler(configs []*apiconfig.APIConfig) *mux.Router {
 	r.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
 	r.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
 	r.PathPrefix("/debug/pprof/").Handler(http.HandlerFunc(pprof.Index))
+	if h := e.conditionPreviewHandler(); h != nil {
+		r.Handle(conditionPreviewPath, h).Methods(http.MethodPost)
+	}
+	r.Handle(openAPIPath, openAPIHandler(configs)).Methods(http.MethodGet)
+
+	// routes are collected first and registered most specific first, since
+	// mux tries routes in registration order: for overlapping paths static
+	// segments beat {params}, which beat wildcards, whatever the config order.
+	// Between otherwise equal paths a host-bound route beats a hostless one.
+	type route struct {
+		pattern     string
+		host        string
+		method      string
+		handler     http.Handler
+		specificity []int
+	}
+	var routes []route
+
+	byID := make(map[string]*apiconfig.APIConfig, len(configs))
+	for _, conf := range configs {
+		byID[conf.ID] = conf
+	}
 
 	for _, conf := range configs {
 		listenPath := "/" + strings.Trim(conf.HttpConfig.ListenPath, "/")
@@ -50,7 +75,19 @@ func (e *Engine) createMuxHandler(configs []*apiconfig.APIConfig) *mux.Router {
 			continue
 		}
 
-		handler, err := e.createBasicHandler(conf)
+		pattern, specificity, err := routePattern(listenPath)
+		if err != nil {
+			logger.Error("invalid listen path", zap.Error(err), zap.String("api", conf.ID), zap.String("path", listenPath))
+			continue
+		}
+
+		shadow, err := e.newShadowTarget(conf, byID)
+		if err != nil {
+			// a broken shadow must not take the primary down with it
+			logger.Error("invalid shadow config, serving without shadow", zap.Error(err), zap.String("api", conf.ID))
+		}
+
+		handler, err := e.createBasicHandler(conf, shadow)
 		if err != nil {
 			logger.Error("Error creating APIHandler", zap.Error(err), zap.String("api", conf.ID), zap.String("path", listenPath))
 			continue
@@ -59,7 +96,24 @@ func (e *Engine) createMuxHandler(configs []*apiconfig.APIConfig) *mux.Router {
 		h := e.wrapMiddleware(handler)
 		logger.Info("registered handler", zap.String("config_id", conf.ID))
 
-		r.Handle(listenPath, h).Methods(method, http.MethodOptions)
+		routes = append(routes, route{pattern: pattern, host: conf.HttpConfig.Host, method: method, handler: h, specificity: specificity})
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if moreSpecific(a.specificity, b.specificity) {
+			return true
+		}
+		if moreSpecific(b.specificity, a.specificity) {
+			return false
+		}
+		return a.host != "" && b.host == ""
+	})
+	for _, rt := range routes {
+		mr := r.Handle(rt.pattern, rt.handler).Methods(rt.method, http.MethodOptions)
+		if rt.host != "" {
+			mr.Host(rt.host)
+		}
 	}
 
 	if e.mcpServer != nil {
@@ -70,6 +124,22 @@ func (e *Engine) createMuxHandler(configs []*apiconfig.APIConfig) *mux.Router {
 	return r
 }
 
+// openAPIPath serves the OpenAPI document of the loaded configs. It is
+// generated with the routing table, so it follows config reloads.
+const openAPIPath = "/openapi.json"
+
+func openAPIHandler(configs []*apiconfig.APIConfig) http.Handler {
+	spec, err := json.Marshal(openapi.Generate(configs, openapi.Info{Title: "Servflow API", Version: "1.0.0"}))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			http.Error(w, "error generating OpenAPI document", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	})
+}
+
 // wrapMiddleware installs the process-level concerns shared by every request:
 // idle-timer reset, the background manager, and the request hook. The
 // request-scoped facilities (request id, RequestContext, logger, span
@@ -30,10 +30,12 @@ type TestRequest struct {
 }
 
 type TestRunner struct {
-	t         *testing.T
-	ctrl      *gomock.Controller
-	apiConfig *apiconfig.APIConfig
-	handler   http.Handler
+	t            *testing.T
+	ctrl         *gomock.Controller
+	apiConfig    *apiconfig.APIConfig
+	extraConfigs []*apiconfig.APIConfig
+	engineConfig *EngineConfig
+	handler      http.Handler
 }
 
 func NewTestRunner(t *testing.T, config *apiconfig.APIConfig) *TestRunner {
@@ -54,6 +56,18 @@ func (r *TestRunner) WithMocks(setup func(*gomock.Controller)) *TestRunner {
 	return r
 }
 
+// WithConfigs registers additional configs alongside the runner's main config.
+func (r *TestRunner) WithConfigs(configs ...*apiconfig.APIConfig) *TestRunner {
+	r.extraConfigs = append(r.extraConfigs, configs...)
+	return r
+}
+
+// WithEngineConfig serves the configs with the given engine config.
+func (r *TestRunner) WithEngineConfig(cfg *EngineConfig) *TestRunner {
+	r.engineConfig = cfg
+	return r
+}
+
 func (r *TestRunner) WithDefaultMocks() *TestRunner {
 	mockProvider := plan2.NewMockActionProvider(r.ctrl)
 	mockExecutable := plan2.NewMockActionExecutable(r.ctrl)
@@ -72,7 +86,10 @@ func (r *TestRunner) Init() *TestRunner {
 	eng := Engine{
 		logger: devLogger,
 	}
-	r.handler = eng.createMuxHandler([]*apiconfig.APIConfig{r.apiConfig})
+	if r.engineConfig != nil {
+		eng.directConfigs = &DirectConfigs{EngineConfig: r.engineConfig}
+	}
+	r.handler = eng.createMuxHandler(append([]*apiconfig.APIConfig{r.apiConfig}, r.extraConfigs...))
 	return r
 }
 
@@ -382,3 +399,192 @@ func TestMultipartFormWithTemplatedAction(t *testing.T) {
 		WantBody:   "Field value: hello_world",
 	})
 }
+
+func TestShortCircuitResponse(t *testing.T) {
+	config := &apiconfig.APIConfig{
+		HttpConfig: apiconfig.HttpConfig{
+			ListenPath: "/items/{id}",
+			Method:     "GET",
+			Next:       "action.cache",
+		},
+		Actions: map[string]apiconfig.Action{
+			"cache": {
+				Name: "cache",
+				Type: "shortcircuit",
+				Config: map[string]interface{}{
+					"response": "response.cached",
+					"when":     `{{ eq (urlparam "id") "hit" }}`,
+				},
+				Next: "action.compute",
+			},
+			"compute": {
+				Name: "compute",
+				Type: "static",
+				Config: map[string]interface{}{
+					"return": "computed",
+				},
+				Next: "response.fresh",
+			},
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"cached": {
+				Name:     "cached",
+				Type:     "template",
+				Code:     200,
+				Template: "from cache",
+			},
+			"fresh": {
+				Name:     "fresh",
+				Type:     "template",
+				Code:     201,
+				Template: `{{ .variable_actions_compute }}`,
+			},
+		},
+	}
+
+	runner := NewTestRunner(t, config).Init()
+
+	runner.RunRequests(
+		TestRequest{
+			Name:       "cache hit returns early",
+			Request:    httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/items/hit", nil),
+			WantStatus: http.StatusOK,
+			WantBody:   "from cache",
+		},
+		TestRequest{
+			Name:       "cache miss runs the full flow",
+			Request:    httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/items/miss", nil),
+			WantStatus: http.StatusCreated,
+			WantBody:   "computed",
+		},
+	)
+}
+
+func templateRoute(id, listenPath, body string) *apiconfig.APIConfig {
+	return &apiconfig.APIConfig{
+		ID: id,
+		HttpConfig: apiconfig.HttpConfig{
+			ListenPath: listenPath,
+			Method:     http.MethodGet,
+			Next:       "response.ok",
+		},
+		Responses: map[string]apiconfig.ResponseConfig{
+			"ok": {
+				Name:     "ok",
+				Type:     "template",
+				Code:     200,
+				Template: body,
+			},
+		},
+	}
+}
+
+func TestWildcardRoute(t *testing.T) {
+	// the wildcard config is registered first to check it does not shadow the
+	// more specific route below it
+	runner := NewTestRunner(t, templateRoute("files", "/files/*path", `path={{ urlparam "path" }}`)).
+		WithConfigs(templateRoute("special", "/files/special", "special")).
+		Init()
+
+	runner.RunRequests(
+		TestRequest{
+			Name:       "captures the rest of the path",
+			Request:    httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/files/a/b/c", nil),
+			WantStatus: http.StatusOK,
+			WantBody:   "path=a/b/c",
+		},
+		TestRequest{
+			Name:       "single segment",
+			Request:    httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/files/readme.md", nil),
+			WantStatus: http.StatusOK,
+			WantBody:   "path=readme.md",
+		},
+		TestRequest{
+			Name:       "specific route is not shadowed",
+			Request:    httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/files/special", nil),
+			WantStatus: http.StatusOK,
+			WantBody:   "special",
+		},
+		TestRequest{
+			Name:       "outside the subtree",
+			Request:    httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/other/a", nil),
+			WantStatus: http.StatusNotFound,
+		},
+	)
+}
+
+func TestOverlappingRoutesMostSpecificWins(t *testing.T) {
+	// registered least specific first so the outcome cannot come from
+	// registration order
+	runner := NewTestRunner(t, templateRoute("catchall", "/users/*rest", "catchall")).
+		WithConfigs(
+			templateRoute("byID", "/users/{id}", `user {{ urlparam "id" }}`),
+			templateRoute("me", "/users/me", "me"),
+			templateRoute("byIDPosts", "/users/{id}/posts", `posts of {{ urlparam "id" }}`),
+			templateRoute("myPosts", "/users/me/posts", "my posts"),
+		).
+		Init()
+
+	runner.RunRequests(
+		TestRequest{
+			Name:     "static beats param",
+			Request:  httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/users/me", nil),
+			WantBody: "me",
+		},
+		TestRequest{
+			Name:     "param beats wildcard",
+			Request:  httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/users/42", nil),
+			WantBody: "user 42",
+		},
+		TestRequest{
+			Name:     "static nested beats param nested",
+			Request:  httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/users/me/posts", nil),
+			WantBody: "my posts",
+		},
+		TestRequest{
+			Name:     "param nested beats wildcard",
+			Request:  httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/users/42/posts", nil),
+			WantBody: "posts of 42",
+		},
+		TestRequest{
+			Name:     "wildcard catches the rest",
+			Request:  httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/users/42/posts/7", nil),
+			WantBody: "catchall",
+		},
+	)
+}
+
+func TestHostRouting(t *testing.T) {
+	tenantRoute := templateRoute("tenant", "/info", `tenant={{ urlparam "tenant" }}`)
+	tenantRoute.HttpConfig.Host = "{tenant}.example.com"
+	partnerRoute := templateRoute("partner", "/info", "partner")
+	partnerRoute.HttpConfig.Host = "api.partner.io"
+
+	runner := NewTestRunner(t, tenantRoute).WithConfigs(partnerRoute).Init()
+
+	runner.RunRequests(
+		TestRequest{
+			Name:       "captures the tenant subdomain",
+			Request:    httptest.NewRequestWithContext(context.Background(), http.MethodGet, "http://acme.example.com/info", nil),
+			WantStatus: http.StatusOK,
+			WantBody:   "tenant=acme",
+		},
+		TestRequest{
+			Name:       "other tenant",
+			Request:    httptest.NewRequestWithContext(context.Background(), http.MethodGet, "http://globex.example.com/info", nil),
+			WantStatus: http.StatusOK,
+			WantBody:   "tenant=globex",
+		},
+		TestRequest{
+			Name:       "static host",
+			Request:    httptest.NewRequestWithContext(context.Background(), http.MethodGet, "http://api.partner.io/info", nil),
+			WantStatus: http.StatusOK,
+			WantBody:   "partner",
+		},
+		TestRequest{
+			Name:       "unmatched host",
+			Request:    httptest.NewRequestWithContext(context.Background(), http.MethodGet, "http://unknown.net/info", nil),
+			WantStatus: http.StatusNotFound,
+		},
+	)
+}
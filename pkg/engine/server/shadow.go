@@ -0,0 +1,215 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	apiconfig "github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/plan"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxShadowBodySize caps the request body copied for the shadow and the
+	// response bodies compared afterwards. Larger requests are not shadowed.
+	maxShadowBodySize = 1 << 20
+	shadowTimeout     = 30 * time.Second
+)
+
+// shadowTarget runs a secondary config against copies of a primary config's
+// requests. The shadow runs after the primary has responded, on the
+// background manager, and its response is only compared and logged — it
+// never reaches the client.
+type shadowTarget struct {
+	configID   string
+	handler    http.Handler
+	percentage float64
+}
+
+// newShadowTarget builds the shadow for config, or returns nil when it has
+// none. configs are all configs being served, by id.
+func (e *Engine) newShadowTarget(config *apiconfig.APIConfig, configs map[string]*apiconfig.APIConfig) (*shadowTarget, error) {
+	sc := config.HttpConfig.Shadow
+	if sc == nil {
+		return nil, nil
+	}
+	if sc.Config == config.ID {
+		return nil, fmt.Errorf("config %s cannot shadow itself", config.ID)
+	}
+	if sc.Percentage < 0 || sc.Percentage > 100 {
+		return nil, fmt.Errorf("shadow percentage %v must be between 0 and 100", sc.Percentage)
+	}
+	target, ok := configs[sc.Config]
+	if !ok {
+		return nil, fmt.Errorf("shadow config %s not found", sc.Config)
+	}
+	h, err := e.newAPIHandler(target)
+	if err != nil {
+		return nil, fmt.Errorf("shadow config %s: %w", sc.Config, err)
+	}
+	if ids := h.p.SideEffectingActions(); len(ids) > 0 && !sc.AllowSideEffects {
+		return nil, fmt.Errorf("shadow config %s runs actions with side effects (%s), set allowSideEffects to shadow it", sc.Config, strings.Join(ids, ", "))
+	}
+	return &shadowTarget{configID: sc.Config, handler: h, percentage: sc.Percentage}, nil
+}
+
+// mirror returns a copy of req for the shadow, or nil when this request is not
+// sampled or is too large to copy. req's body is restored so the primary
+// reads it unchanged.
+func (s *shadowTarget) mirror(req *http.Request) *http.Request {
+	if s.percentage > 0 && rand.Float64()*100 >= s.percentage {
+		return nil
+	}
+	body, ok := peekBody(req, maxShadowBodySize)
+	if !ok {
+		return nil
+	}
+	// Detached from the client's cancellation: the shadow starts once the
+	// primary has responded and the client may already be gone.
+	m := req.Clone(context.WithoutCancel(req.Context()))
+	m.Body = io.NopCloser(bytes.NewReader(body))
+	m.ContentLength = int64(len(body))
+	return m
+}
+
+// peekBody returns a copy of req's body and restores it so the handler still
+// reads it unchanged. ok is false when the body is larger than limit or could
+// not be read.
+func peekBody(req *http.Request, limit int) (body []byte, ok bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true
+	}
+	b, err := io.ReadAll(io.LimitReader(req.Body, int64(limit)+1))
+	// restore what was read, followed by anything left unread
+	req.Body = readCloser{io.MultiReader(bytes.NewReader(b), req.Body), req.Body}
+	if err != nil || len(b) > limit {
+		return nil, false
+	}
+	return b, true
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// dispatch runs the shadow in the background and logs how its response
+// compares with the primary's. Shadow failures, panics included, are logged
+// and go no further.
+func (s *shadowTarget) dispatch(ctx context.Context, req *http.Request, primary *responseTee, logger *zap.Logger) {
+	logger = logger.With(zap.String("shadow_config", s.configID))
+	run := func(bgCtx context.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("shadow request panicked", zap.Any("panic", r))
+			}
+		}()
+		shadowCtx, cancel := context.WithTimeout(req.Context(), shadowTimeout)
+		defer cancel()
+		defer context.AfterFunc(bgCtx, cancel)()
+
+		rec := newResponseTee(nil, maxShadowBodySize)
+		start := time.Now()
+		s.handler.ServeHTTP(rec, req.WithContext(shadowCtx))
+
+		fields := []zap.Field{
+			zap.Int("primary_status", primary.status()),
+			zap.Int("shadow_status", rec.status()),
+			zap.Duration("shadow_duration", time.Since(start)),
+		}
+		if primary.status() != rec.status() || !sameBody(primary.body.Bytes(), rec.body.Bytes()) {
+			logger.Warn("shadow response differs from primary", fields...)
+			return
+		}
+		logger.Info("shadow response matches primary", fields...)
+	}
+
+	if bm := plan.BackgroundManagerFromContext(ctx); bm != nil {
+		bm.Dispatch(run)
+		return
+	}
+	go run(context.Background())
+}
+
+// sameBody compares response bodies, semantically when both are JSON so key
+// order and whitespace do not count as differences.
+func sameBody(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var ja, jb interface{}
+	if json.Unmarshal(a, &ja) != nil || json.Unmarshal(b, &jb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(ja, jb)
+}
+
+// responseTee records the status and the first limit bytes of a response,
+// passing everything through to w when w is set.
+type responseTee struct {
+	w      http.ResponseWriter
+	header http.Header
+	code   int
+	body   bytes.Buffer
+	limit  int
+}
+
+func newResponseTee(w http.ResponseWriter, limit int) *responseTee {
+	return &responseTee{w: w, header: http.Header{}, limit: limit}
+}
+
+func (t *responseTee) Header() http.Header {
+	if t.w != nil {
+		return t.w.Header()
+	}
+	return t.header
+}
+
+func (t *responseTee) WriteHeader(code int) {
+	if t.code == 0 {
+		t.code = code
+	}
+	if t.w != nil {
+		t.w.WriteHeader(code)
+	}
+}
+
+func (t *responseTee) Write(b []byte) (int, error) {
+	if t.code == 0 {
+		t.code = http.StatusOK
+	}
+	if room := t.limit - t.body.Len(); room > 0 {
+		t.body.Write(b[:min(room, len(b))])
+	}
+	if t.w != nil {
+		return t.w.Write(b)
+	}
+	return len(b), nil
+}
+
+// Flush keeps streaming responses streaming through the tee.
+func (t *responseTee) Flush() {
+	if f, ok := t.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (t *responseTee) Unwrap() http.ResponseWriter {
+	return t.w
+}
+
+func (t *responseTee) status() int {
+	if t.code == 0 {
+		return http.StatusOK
+	}
+	return t.code
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Servflow/servflow/pkg/apiconfig"
+	"github.com/Servflow/servflow/pkg/engine/integration"
+	"github.com/Servflow/servflow/pkg/engine/integration/integrations/filters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func templateConfig(id, listenPath, method, template string) *apiconfig.APIConfig {
+	c := stubConfig(id, listenPath)
+	c.HttpConfig.Method = method
+	c.Responses["ok"] = apiconfig.ResponseConfig{Name: "ok", Code: 200, Type: "template", Template: template}
+	return c
+}
+
+func shadowEngine(t *testing.T, configs ...*apiconfig.APIConfig) (*Engine, *observer.ObservedLogs) {
+	t.Helper()
+	core, logs := observer.New(zapcore.InfoLevel)
+	engine, err := New("test", WithLogger(core), WithDirectConfigs(&DirectConfigs{
+		APIConfigs:   configs,
+		EngineConfig: &EngineConfig{},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, engine.Start())
+	t.Cleanup(func() { engine.Stop() })
+	return engine, logs
+}
+
+func waitForShadow(t *testing.T, logs *observer.ObservedLogs) observer.LoggedEntry {
+	t.Helper()
+	var entry observer.LoggedEntry
+	require.Eventually(t, func() bool {
+		for _, e := range logs.All() {
+			if strings.HasPrefix(e.Message, "shadow response") || strings.HasPrefix(e.Message, "shadow request") {
+				entry = e
+				return true
+			}
+		}
+		return false
+	}, 2*time.Second, 5*time.Millisecond)
+	return entry
+}
+
+// ordersIntegration is a stubbed datasource that counts the records saved
+// into it.
+type ordersIntegration struct {
+	stored atomic.Int64
+}
+
+func (o *ordersIntegration) Type() string { return "orders" }
+
+func (o *ordersIntegration) Store(context.Context, map[string]interface{}, map[string]string) error {
+	o.stored.Add(1)
+	return nil
+}
+
+func (o *ordersIntegration) Update(context.Context, map[string]interface{}, map[string]string, ...filters.Filter) (string, error) {
+	return "", nil
+}
+
+func TestShadowTraffic(t *testing.T) {
+	t.Run("shadow runs with a copy of the request and its result is discarded", func(t *testing.T) {
+		primary := templateConfig("greet", "/greet", http.MethodPost, `hello {{ body "name" }}`)
+		primary.HttpConfig.Shadow = &apiconfig.ShadowConfig{Config: "greet-v2"}
+		shadow := templateConfig("greet-v2", "/v2/greet", http.MethodPost, `hi there {{ body "name" }}`)
+		engine, logs := shadowEngine(t, primary, shadow)
+
+		req := httptest.NewRequest(http.MethodPost, "/greet", strings.NewReader(`{"name": "ada"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "hello ada", w.Body.String())
+
+		entry := waitForShadow(t, logs)
+		assert.Equal(t, "shadow response differs from primary", entry.Message)
+		fields := entry.ContextMap()
+		assert.Equal(t, "greet", fields["api"])
+		assert.Equal(t, "greet-v2", fields["shadow_config"])
+		assert.EqualValues(t, http.StatusOK, fields["primary_status"])
+		assert.EqualValues(t, http.StatusOK, fields["shadow_status"])
+	})
+
+	t.Run("shadow reads the same body and matching responses are reported", func(t *testing.T) {
+		primary := templateConfig("echo", "/echo", http.MethodPost, `{"a": {{ param "a" }}, "b": "{{ body "b" }}"}`)
+		primary.HttpConfig.Shadow = &apiconfig.ShadowConfig{Config: "echo-v2"}
+		shadow := templateConfig("echo-v2", "/v2/echo", http.MethodPost, `{"b": "{{ body "b" }}",  "a": {{ param "a" }}}`)
+		engine, logs := shadowEngine(t, primary, shadow)
+
+		req := httptest.NewRequest(http.MethodPost, "/echo?a=1", strings.NewReader(`{"b": "x"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"a": 1, "b": "x"}`, w.Body.String())
+
+		entry := waitForShadow(t, logs)
+		assert.Equal(t, "shadow response matches primary", entry.Message)
+	})
+
+	t.Run("failing shadow does not affect the primary", func(t *testing.T) {
+		primary := templateConfig("stable", "/stable", http.MethodGet, "stable")
+		primary.HttpConfig.Shadow = &apiconfig.ShadowConfig{Config: "broken"}
+		broken := templateConfig("broken", "/broken", http.MethodGet, `{{ index .missing 3 }}`)
+		engine, logs := shadowEngine(t, primary, broken)
+
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stable", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "stable", w.Body.String())
+
+		entry := waitForShadow(t, logs)
+		assert.Equal(t, "shadow response differs from primary", entry.Message)
+		assert.EqualValues(t, http.StatusInternalServerError, entry.ContextMap()["shadow_status"])
+	})
+
+	t.Run("missing shadow config serves the primary alone", func(t *testing.T) {
+		primary := templateConfig("alone", "/alone", http.MethodGet, "alone")
+		primary.HttpConfig.Shadow = &apiconfig.ShadowConfig{Config: "missing"}
+		engine, logs := shadowEngine(t, primary)
+
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/alone", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "alone", w.Body.String())
+		assert.Equal(t, 1, logs.FilterMessage("invalid shadow config, serving without shadow").Len())
+	})
+
+	t.Run("shadow with side effects needs allowSideEffects", func(t *testing.T) {
+		orders := &ordersIntegration{}
+		integration.ReplaceIntegrationType("orders", func(map[string]any) (integration.Integration, error) {
+			return orders, nil
+		})
+		require.NoError(t, integration.InitializeIntegration("orders", "ordersdb", nil, false))
+
+		saves := func(id, listenPath string) *apiconfig.APIConfig {
+			c := templateConfig(id, listenPath, http.MethodGet, "v2")
+			c.HttpConfig.Next = "action.record"
+			c.Actions["record"] = apiconfig.Action{
+				Name:   "record",
+				Type:   "save",
+				Next:   "response.ok",
+				Config: map[string]interface{}{"integrationID": "ordersdb", "table": "orders", "fields": map[string]interface{}{"status": "new"}},
+			}
+			return c
+		}
+
+		primary := templateConfig("guarded", "/guarded", http.MethodGet, "v1")
+		primary.HttpConfig.Shadow = &apiconfig.ShadowConfig{Config: "guarded-v2"}
+		engine, logs := shadowEngine(t, primary, saves("guarded-v2", "/v2/guarded"))
+
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/guarded", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		invalid := logs.FilterMessage("invalid shadow config, serving without shadow").All()
+		require.Len(t, invalid, 1)
+		assert.Contains(t, invalid[0].ContextMap()["error"], "action.record")
+		assert.Zero(t, orders.stored.Load())
+
+		primary = templateConfig("allowed", "/allowed", http.MethodGet, "v1")
+		primary.HttpConfig.Shadow = &apiconfig.ShadowConfig{Config: "allowed-v2", AllowSideEffects: true}
+		engine, logs = shadowEngine(t, primary, saves("allowed-v2", "/v2/allowed"))
+
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/allowed", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		entry := waitForShadow(t, logs)
+		assert.Equal(t, "shadow response differs from primary", entry.Message)
+		assert.Equal(t, int64(1), orders.stored.Load())
+	})
+
+	t.Run("panicking shadow is contained", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+		s := &shadowTarget{configID: "panics", handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("boom")
+		})}
+		mirror := s.mirror(httptest.NewRequest(http.MethodGet, "/", nil))
+		require.NotNil(t, mirror)
+		s.dispatch(context.Background(), mirror, newResponseTee(nil, maxShadowBodySize), zap.New(core))
+
+		entry := waitForShadow(t, logs)
+		assert.Equal(t, "shadow request panicked", entry.Message)
+	})
+
+	t.Run("percentage samples requests", func(t *testing.T) {
+		never := &shadowTarget{percentage: 0.0000001}
+		always := &shadowTarget{}
+		sampled := 0
+		for i := 0; i < 100; i++ {
+			if never.mirror(httptest.NewRequest(http.MethodGet, "/", nil)) != nil {
+				sampled++
+			}
+			assert.NotNil(t, always.mirror(httptest.NewRequest(http.MethodGet, "/", nil)))
+		}
+		assert.Zero(t, sampled)
+	})
+}
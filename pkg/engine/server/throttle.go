@@ -0,0 +1,97 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultRetryAfter = time.Second
+
+// ThrottleConfig bounds the requests the engine serves at once. Requests over
+// MaxConcurrent wait in a queue of QueueSize; once the queue is full, or a
+// request has waited QueueTimeout, it is shed with a 503 and a Retry-After
+// header instead of piling more work onto an overloaded engine.
+type ThrottleConfig struct {
+	MaxConcurrent int `yaml:"maxConcurrent"`
+	// QueueSize is how many requests may wait for a slot. 0 sheds every
+	// request over the limit immediately.
+	QueueSize int `yaml:"queueSize"`
+	// QueueTimeout is how long a queued request waits for a slot before it is
+	// shed. 0 waits until the client gives up.
+	QueueTimeout time.Duration `yaml:"queueTimeout"`
+	// RetryAfter is advertised to shed clients. Defaults to 1s.
+	RetryAfter time.Duration `yaml:"retryAfter"`
+}
+
+// throttler is a counting semaphore with a bounded waiting room.
+type throttler struct {
+	slots        chan struct{}
+	queue        chan struct{}
+	queueTimeout time.Duration
+	retryAfter   string
+}
+
+func newThrottler(cfg ThrottleConfig) (*throttler, error) {
+	if cfg.MaxConcurrent <= 0 {
+		return nil, errors.New("throttle maxConcurrent must be positive")
+	}
+	if cfg.QueueSize < 0 || cfg.QueueTimeout < 0 || cfg.RetryAfter < 0 {
+		return nil, errors.New("throttle queueSize, queueTimeout and retryAfter must not be negative")
+	}
+	retryAfter := cfg.RetryAfter
+	if retryAfter == 0 {
+		retryAfter = defaultRetryAfter
+	}
+	// Retry-After is whole seconds; round up so clients never retry early.
+	seconds := int((retryAfter + time.Second - 1) / time.Second)
+	return &throttler{
+		slots:        make(chan struct{}, cfg.MaxConcurrent),
+		queue:        make(chan struct{}, cfg.QueueSize),
+		queueTimeout: cfg.QueueTimeout,
+		retryAfter:   strconv.Itoa(seconds),
+	}, nil
+}
+
+// acquire takes a slot, waiting in the queue if there is room. It reports
+// false when the request must be shed.
+func (t *throttler) acquire(req *http.Request) bool {
+	select {
+	case t.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	select {
+	case t.queue <- struct{}{}:
+	default:
+		return false
+	}
+	defer func() { <-t.queue }()
+
+	var timeout <-chan time.Time
+	if t.queueTimeout > 0 {
+		timer := time.NewTimer(t.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case t.slots <- struct{}{}:
+		return true
+	case <-timeout:
+		return false
+	case <-req.Context().Done():
+		return false
+	}
+}
+
+func (t *throttler) release() {
+	<-t.slots
+}
+
+// shed rejects a request the engine has no capacity for.
+func (t *throttler) shed(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", t.retryAfter)
+	http.Error(w, "server is at capacity, retry later", http.StatusServiceUnavailable)
+}
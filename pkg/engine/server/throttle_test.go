@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// throttledEngine starts an engine with cfg and routes /block to a handler
+// that holds its slot until release is closed, and /panic to one that panics.
+func throttledEngine(t *testing.T, cfg ThrottleConfig) (e *Engine, started chan struct{}, release chan struct{}) {
+	t.Helper()
+	engine, err := New("test", WithDirectConfigs(&DirectConfigs{
+		EngineConfig: &EngineConfig{Throttle: &cfg},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, engine.Start())
+	t.Cleanup(func() { engine.Stop() })
+
+	started = make(chan struct{}, 16)
+	release = make(chan struct{})
+	r := mux.NewRouter()
+	r.HandleFunc("/block", func(w http.ResponseWriter, req *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	r.HandleFunc("/panic", func(w http.ResponseWriter, req *http.Request) {
+		panic("handler failed")
+	})
+	r.HandleFunc("/ok", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	engine.routes.Store(r)
+	return engine, started, release
+}
+
+func serve(e *Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestEngine_Throttle(t *testing.T) {
+	t.Run("requests beyond the limit are shed", func(t *testing.T) {
+		engine, started, release := throttledEngine(t, ThrottleConfig{MaxConcurrent: 2, RetryAfter: 1500 * time.Millisecond})
+
+		var wg sync.WaitGroup
+		codes := make(chan int, 2)
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				codes <- serve(engine, "/block").Code
+			}()
+		}
+		<-started
+		<-started
+
+		shed := serve(engine, "/ok")
+		assert.Equal(t, http.StatusServiceUnavailable, shed.Code)
+		assert.Equal(t, "2", shed.Header().Get("Retry-After"))
+
+		close(release)
+		wg.Wait()
+		close(codes)
+		for code := range codes {
+			assert.Equal(t, http.StatusOK, code)
+		}
+
+		// capacity is restored once the slow requests finish
+		assert.Equal(t, http.StatusOK, serve(engine, "/ok").Code)
+		assert.Equal(t, http.StatusOK, serve(engine, "/ok").Code)
+	})
+
+	t.Run("queued requests wait for a slot", func(t *testing.T) {
+		engine, started, release := throttledEngine(t, ThrottleConfig{MaxConcurrent: 1, QueueSize: 1})
+
+		go serve(engine, "/block")
+		<-started
+
+		queued := make(chan int, 1)
+		go func() { queued <- serve(engine, "/ok").Code }()
+		require.Eventually(t, func() bool { return len(engine.throttle.queue) == 1 }, time.Second, time.Millisecond)
+
+		// the queue is full, so the next request is shed
+		assert.Equal(t, http.StatusServiceUnavailable, serve(engine, "/ok").Code)
+
+		close(release)
+		assert.Equal(t, http.StatusOK, <-queued)
+	})
+
+	t.Run("queued requests are shed after the queue timeout", func(t *testing.T) {
+		engine, started, release := throttledEngine(t, ThrottleConfig{MaxConcurrent: 1, QueueSize: 1, QueueTimeout: 20 * time.Millisecond})
+		defer close(release)
+
+		go serve(engine, "/block")
+		<-started
+
+		w := serve(engine, "/ok")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		assert.Empty(t, engine.throttle.queue)
+	})
+
+	t.Run("queued requests leave when the client goes away", func(t *testing.T) {
+		engine, started, release := throttledEngine(t, ThrottleConfig{MaxConcurrent: 1, QueueSize: 1})
+		defer close(release)
+
+		go serve(engine, "/block")
+		<-started
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil).WithContext(ctx))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("panicking handlers release their slot", func(t *testing.T) {
+		engine, _, _ := throttledEngine(t, ThrottleConfig{MaxConcurrent: 1})
+
+		for i := 0; i < 3; i++ {
+			assert.Panics(t, func() { serve(engine, "/panic") })
+		}
+		assert.Empty(t, engine.throttle.slots)
+		assert.Equal(t, http.StatusOK, serve(engine, "/ok").Code)
+	})
+
+	t.Run("invalid config fails start", func(t *testing.T) {
+		engine, err := New("test", WithDirectConfigs(&DirectConfigs{
+			EngineConfig: &EngineConfig{Throttle: &ThrottleConfig{}},
+		}))
+		require.NoError(t, err)
+		assert.ErrorContains(t, engine.Start(), "invalid throttle config")
+	})
+}
@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// RequestIDHeader carries the id of the request on outgoing HTTP calls so
+// downstream services can correlate their logs with ours.
+const RequestIDHeader = "X-Request-ID"
+
+// InjectHTTPHeaders sets RequestIDHeader and, when tracing is enabled, the
+// W3C trace context (traceparent) of ctx on an outgoing request's headers.
+// Headers already set, e.g. by the action's config, are kept.
+func InjectHTTPHeaders(ctx context.Context, h http.Header) {
+	if rc, ok := requestctx.FromContext(ctx); ok && h.Get(RequestIDHeader) == "" {
+		h.Set(RequestIDHeader, rc.ID())
+	}
+	if h.Get("traceparent") == "" {
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(h))
+	}
+}
+
+// SQLComment returns a comment carrying the request id and traceparent of
+// ctx in the sqlcommenter format, e.g. /*request_id='request_1'*/, for
+// prefixing to a statement. Values are URL-encoded, so they cannot end the
+// comment. It returns "" when ctx carries neither.
+func SQLComment(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	if rc, ok := requestctx.FromContext(ctx); ok {
+		carrier["request_id"] = rc.ID()
+	}
+	if len(carrier) == 0 {
+		return ""
+	}
+
+	keys := carrier.Keys()
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"='"+url.QueryEscape(carrier[k])+"'")
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
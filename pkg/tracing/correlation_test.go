@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Servflow/servflow/pkg/engine/requestctx"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func tracedContext(id string) context.Context {
+	ctx := requestctx.WithAggregationContext(context.Background(), requestctx.NewRequestContext(id))
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd, 0x84, 0x48, 0xeb, 0x21, 0x1c, 0x80, 0x31, 0x9c},
+		SpanID:     trace.SpanID{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31},
+		TraceFlags: trace.FlagsSampled,
+	})
+	return trace.ContextWithSpanContext(ctx, sc)
+}
+
+const testTraceparent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+func TestSQLComment(t *testing.T) {
+	assert.Empty(t, SQLComment(context.Background()))
+
+	ctx := requestctx.WithAggregationContext(context.Background(), requestctx.NewRequestContext("request_1"))
+	assert.Equal(t, "/*request_id='request_1'*/", SQLComment(ctx))
+
+	assert.Equal(t, "/*request_id='request_2',traceparent='"+testTraceparent+"'*/", SQLComment(tracedContext("request_2")))
+
+	t.Run("values cannot end the comment", func(t *testing.T) {
+		ctx := requestctx.WithAggregationContext(context.Background(), requestctx.NewRequestContext("x'*/ DROP TABLE users; --"))
+		assert.Equal(t, "/*request_id='x%27%2A%2F+DROP+TABLE+users%3B+--'*/", SQLComment(ctx))
+	})
+}
+
+func TestInjectHTTPHeaders(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	h := http.Header{}
+	InjectHTTPHeaders(tracedContext("request_3"), h)
+	assert.Equal(t, "request_3", h.Get(RequestIDHeader))
+	assert.Equal(t, testTraceparent, h.Get("traceparent"))
+
+	h = http.Header{}
+	h.Set(RequestIDHeader, "upstream")
+	InjectHTTPHeaders(tracedContext("request_3"), h)
+	assert.Equal(t, "upstream", h.Get(RequestIDHeader))
+
+	h = http.Header{}
+	InjectHTTPHeaders(context.Background(), h)
+	assert.Empty(t, h)
+}
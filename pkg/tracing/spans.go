@@ -60,7 +60,7 @@ const (
 	AttrName         = "sf.name"        // friendly per-instance label rendered by dashboards
 	AttrAgent        = "sf.agent"       // name of the agent that owns the workflow config, stamped by the host (pro)
 	AttrWorkflow     = "sf.workflow"    // stable workflow config id, carried on root entry spans for grouping/search
-	AttrStepType     = "sf.step.type"   // request | action | condition | response | trigger | scheduled
+	AttrStepType     = "sf.step.type"   // request | action | condition | join | response | trigger | scheduled
 	AttrActionType   = "sf.action_type" // concrete action type (http, callworkflow, parallel, ...)
 	AttrActionConfig = "sf.config"      // resolved action config (V1 wrapper sets it; V2 actions self-report via fields)
 	AttrID           = "sf.id"          // bare node id (no prefix)
@@ -156,6 +156,13 @@ func StartCondition(ctx context.Context, id, name string) (context.Context, trac
 		attribute.String(AttrID, id))
 }
 
+// StartJoin spans a plan join step.
+func StartJoin(ctx context.Context, id, name string) (context.Context, trace.Span) {
+	return start(ctx, "Join", name,
+		attribute.String(AttrStepType, "join"),
+		attribute.String(AttrID, id))
+}
+
 // StartResponse spans a plan response step.
 func StartResponse(ctx context.Context, id, name string) (context.Context, trace.Span) {
 	return start(ctx, "Response", name,